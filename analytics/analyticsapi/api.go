@@ -0,0 +1,255 @@
+package analyticsapi
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics"
+	"github.com/Abraxas-365/fuckturamelo/analytics/analyticssrv"
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/analytics/repository"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// AnalyticsAPI contains the complete API setup for the analytics domain
+type AnalyticsAPI struct {
+	service analyticssrv.AnalyticsService
+	repo    postgres.AnalyticsRepository
+}
+
+// Config contains configuration for the analytics API
+type Config struct {
+	DB *sqlx.DB
+}
+
+// New creates a new AnalyticsAPI instance
+func New(config Config) (*AnalyticsAPI, error) {
+	if config.DB == nil {
+		return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewAnalyticsRepository(config.DB)
+	svc := analyticssrv.NewAnalyticsService(repo)
+
+	return &AnalyticsAPI{
+		service: svc,
+		repo:    repo,
+	}, nil
+}
+
+// SetupRoutes registers all analytics routes with the given Fiber router
+// group, which is expected to carry the :orgId parameter
+func (api *AnalyticsAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/invoice-analytics/health", api.healthCheck)
+
+	// Analytics routes
+	router.Get("/invoice-analytics", api.getInvoiceAnalytics)
+
+	// Report routes
+	router.Get("/ap-aging", api.getAgingReport)
+	router.Get("/ap-aging/export", api.exportAgingReport)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *AnalyticsAPI) GetService() analyticssrv.AnalyticsService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *AnalyticsAPI) GetRepository() postgres.AnalyticsRepository {
+	return api.repo
+}
+
+// getInvoiceAnalytics handles GET /organizations/:orgId/invoice-analytics
+func (api *AnalyticsAPI) getInvoiceAnalytics(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	req, err := api.parseAnalyticsRequest(c)
+	if err != nil {
+		return err
+	}
+	req.OrganizationID = orgID
+
+	result, err := api.service.InvoiceAnalytics(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getAgingReport handles GET /organizations/:orgId/ap-aging
+func (api *AnalyticsAPI) getAgingReport(c *fiber.Ctx) error {
+	req, err := api.parseAgingRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.AgingReport(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// exportAgingReport handles GET /organizations/:orgId/ap-aging/export
+func (api *AnalyticsAPI) exportAgingReport(c *fiber.Ctx) error {
+	req, err := api.parseAgingRequest(c)
+	if err != nil {
+		return err
+	}
+
+	content, err := api.service.ExportAgingReport(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="ap-aging-`+req.AsOf.Format(invoicemodels.DateLayout)+`.csv"`)
+	return c.Status(fiber.StatusOK).Send(content)
+}
+
+// healthCheck provides a health check endpoint
+func (api *AnalyticsAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "analytics",
+	})
+}
+
+// Helper methods
+
+func (api *AnalyticsAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *AnalyticsAPI) parseAnalyticsRequest(c *fiber.Ctx) (*dto.InvoiceAnalyticsRequest, error) {
+	req := &dto.InvoiceAnalyticsRequest{}
+
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		for _, name := range strings.Split(groupBy, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				req.GroupBy = append(req.GroupBy, models.Dimension(name))
+			}
+		}
+	}
+
+	var err error
+	if req.ProjectID, err = api.parseUUIDQuery(c, "project_id"); err != nil {
+		return nil, err
+	}
+	if req.ProviderID, err = api.parseUUIDQuery(c, "provider_id"); err != nil {
+		return nil, err
+	}
+	if req.InvoiceTypeID, err = api.parseUUIDQuery(c, "invoice_type_id"); err != nil {
+		return nil, err
+	}
+	if req.DateFrom, err = api.parseDateQuery(c, "date_from"); err != nil {
+		return nil, err
+	}
+	if req.DateTo, err = api.parseDateQuery(c, "date_to"); err != nil {
+		return nil, err
+	}
+
+	if status := c.Query("status"); status != "" {
+		req.Status = &status
+	}
+	if currency := c.Query("currency_code"); currency != "" {
+		req.CurrencyCode = &currency
+	}
+
+	return req, nil
+}
+
+func (api *AnalyticsAPI) parseAgingRequest(c *fiber.Ctx) (*dto.AgingReportRequest, error) {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return nil, err
+	}
+
+	req := &dto.AgingReportRequest{OrganizationID: orgID}
+
+	if req.AsOf, err = api.parseDateQuery(c, "as_of"); err != nil {
+		return nil, err
+	}
+	if req.ProjectID, err = api.parseUUIDQuery(c, "project_id"); err != nil {
+		return nil, err
+	}
+	if req.ProviderID, err = api.parseUUIDQuery(c, "provider_id"); err != nil {
+		return nil, err
+	}
+
+	if currency := c.Query("currency_code"); currency != "" {
+		req.CurrencyCode = &currency
+	}
+	if statuses := c.Query("settled_statuses"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			req.SettledStatuses = append(req.SettledStatuses, strings.TrimSpace(status))
+		}
+	}
+
+	return req, nil
+}
+
+func (api *AnalyticsAPI) parseUUIDQuery(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &id, nil
+}
+
+func (api *AnalyticsAPI) parseDateQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(invoicemodels.DateLayout, value)
+	if err != nil {
+		return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+			WithDetail("error", "Invalid "+name+" format (expected YYYY-MM-DD)").
+			WithCause(err)
+	}
+
+	return &date, nil
+}
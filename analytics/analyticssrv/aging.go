@@ -0,0 +1,119 @@
+package analyticssrv
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics"
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// AgingReport buckets the organization's unpaid invoices by days overdue.
+// Totals are kept per currency; amounts in different currencies are never
+// added together.
+func (s *analyticsService) AgingReport(ctx context.Context, req *dto.AgingReportRequest) (*dto.AgingReportResponse, error) {
+	if req.AsOf == nil {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		req.AsOf = &today
+	}
+	if len(req.SettledStatuses) == 0 {
+		req.SettledStatuses = models.DefaultSettledStatuses
+	}
+	for _, status := range req.SettledStatuses {
+		if status == "" {
+			return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+				WithDetail("field", "settled_statuses").
+				WithDetail("reason", "statuses must not be empty")
+		}
+	}
+
+	rows, err := s.repo.AgingReport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Currency totals keep the order in which each currency first appears.
+	// Currency codes are three letters, so invoices without one are keyed
+	// by the empty string.
+	totals := []*dto.AgingCurrencyTotal{}
+	byCurrency := make(map[string]*dto.AgingCurrencyTotal)
+	for _, row := range rows {
+		key := stringValue(row.CurrencyCode)
+		total, ok := byCurrency[key]
+		if !ok {
+			total = &dto.AgingCurrencyTotal{CurrencyCode: row.CurrencyCode}
+			byCurrency[key] = total
+			totals = append(totals, total)
+		}
+		total.InvoiceCount += row.InvoiceCount
+		total.AgingAmounts = total.AgingAmounts.Add(row.AgingAmounts)
+	}
+
+	return &dto.AgingReportResponse{
+		OrganizationID:  req.OrganizationID,
+		AsOf:            req.AsOf.Format(invoicemodels.DateLayout),
+		SettledStatuses: req.SettledStatuses,
+		Rows:            rows,
+		Totals:          totals,
+	}, nil
+}
+
+// ExportAgingReport renders the aging report as CSV, one line per provider,
+// project and currency followed by one total line per currency. Invoices
+// without a currency are listed with an empty currency column.
+func (s *analyticsService) ExportAgingReport(ctx context.Context, req *dto.AgingReportRequest) ([]byte, error) {
+	report, err := s.AgingReport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []string{"provider", "project", "currency", "invoice_count"}
+	for _, bucket := range models.AgingBuckets() {
+		header = append(header, string(bucket))
+	}
+	header = append(header, "total")
+
+	records := [][]string{header}
+	for _, row := range report.Rows {
+		records = append(records, agingRecord(
+			stringValue(row.ProviderName), stringValue(row.ProjectName),
+			stringValue(row.CurrencyCode), row.InvoiceCount, row.AgingAmounts,
+		))
+	}
+	for _, total := range report.Totals {
+		records = append(records, agingRecord(
+			"TOTAL", "", stringValue(total.CurrencyCode), total.InvoiceCount, total.AgingAmounts,
+		))
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(records); err != nil {
+		return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsQueryFailed).
+			WithDetail("report", "ap_aging").
+			WithDetail("reason", "csv_export").
+			WithCause(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func agingRecord(provider, project, currency string, count int64, amounts models.AgingAmounts) []string {
+	record := []string{provider, project, currency, strconv.FormatInt(count, 10)}
+	for _, bucket := range models.AgingBuckets() {
+		record = append(record, amounts.Bucket(bucket).StringFixed(2))
+	}
+	return append(record, amounts.Total.StringFixed(2))
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
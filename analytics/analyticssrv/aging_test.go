@@ -0,0 +1,86 @@
+package analyticssrv
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+)
+
+// agingRepository serves fixed aging rows
+type agingRepository struct {
+	rows []*models.AgingRow
+}
+
+func (r *agingRepository) Aggregate(context.Context, *dto.InvoiceAnalyticsRequest, []models.Dimension) ([]*models.Bucket, error) {
+	return nil, nil
+}
+
+func (r *agingRepository) AgingReport(context.Context, *dto.AgingReportRequest) ([]*models.AgingRow, error) {
+	return r.rows, nil
+}
+
+func agingRow(provider string, currency *string, current, overdue string) *models.AgingRow {
+	amounts := models.AgingAmounts{
+		Current:   decimal.RequireFromString(current),
+		Days1To30: decimal.RequireFromString(overdue),
+	}
+	amounts.Total = amounts.Current.Add(amounts.Days1To30)
+	return &models.AgingRow{ProviderName: &provider, CurrencyCode: currency, InvoiceCount: 1, AgingAmounts: amounts}
+}
+
+func TestAgingReportTotalsInvoicesWithoutCurrency(t *testing.T) {
+	pen := "PEN"
+	repo := &agingRepository{rows: []*models.AgingRow{
+		agingRow("Alpha", &pen, "100", "0"),
+		agingRow("Beta", nil, "0", "40"),
+		agingRow("Gamma", &pen, "0", "25.5"),
+		agingRow("Delta", nil, "10", "0"),
+	}}
+	svc := NewAnalyticsService(repo)
+
+	asOf := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	report, err := svc.AgingReport(context.Background(), &dto.AgingReportRequest{OrganizationID: uuid.New(), AsOf: &asOf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Totals) != 2 {
+		t.Fatalf("got %d currency totals; want PEN and no currency", len(report.Totals))
+	}
+	if got := report.Totals[0]; got.CurrencyCode == nil || *got.CurrencyCode != "PEN" || !got.Total.Equal(decimal.RequireFromString("125.5")) {
+		t.Errorf("first total = %v %s; want PEN 125.5", got.CurrencyCode, got.Total)
+	}
+	if got := report.Totals[1]; got.CurrencyCode != nil || got.InvoiceCount != 2 || !got.Total.Equal(decimal.RequireFromString("50")) {
+		t.Errorf("second total = %v %d invoices %s; want no currency, 2 invoices, 50", got.CurrencyCode, got.InvoiceCount, got.Total)
+	}
+
+	csv, err := svc.ExportAgingReport(context.Background(), &dto.AgingReportRequest{OrganizationID: uuid.New(), AsOf: &asOf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(csv)), "\n")
+	want := []string{
+		"provider,project,currency,invoice_count,current,1-30,31-60,61-90,90+,total",
+		"Alpha,,PEN,1,100.00,0.00,0.00,0.00,0.00,100.00",
+		"Beta,,,1,0.00,40.00,0.00,0.00,0.00,40.00",
+		"Gamma,,PEN,1,0.00,25.50,0.00,0.00,0.00,25.50",
+		"Delta,,,1,10.00,0.00,0.00,0.00,0.00,10.00",
+		"TOTAL,,PEN,2,100.00,25.50,0.00,0.00,0.00,125.50",
+		"TOTAL,,,2,10.00,40.00,0.00,0.00,0.00,50.00",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("csv has %d lines; want %d:\n%s", len(lines), len(want), csv)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q; want %q", i, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package analyticssrv
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics"
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/analytics/repository"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// AnalyticsService defines the interface for invoice analytics business logic
+type AnalyticsService interface {
+	// InvoiceAnalytics returns invoice counts, sums and averages per currency,
+	// optionally split by the requested dimensions
+	InvoiceAnalytics(ctx context.Context, req *dto.InvoiceAnalyticsRequest) (*dto.InvoiceAnalyticsResponse, error)
+
+	// Accounts payable aging
+	AgingReport(ctx context.Context, req *dto.AgingReportRequest) (*dto.AgingReportResponse, error)
+	ExportAgingReport(ctx context.Context, req *dto.AgingReportRequest) ([]byte, error)
+}
+
+// analyticsService implements AnalyticsService
+type analyticsService struct {
+	repo postgres.AnalyticsRepository
+}
+
+// NewAnalyticsService creates a new analytics service
+func NewAnalyticsService(repo postgres.AnalyticsRepository) AnalyticsService {
+	return &analyticsService{
+		repo: repo,
+	}
+}
+
+// InvoiceAnalytics aggregates the organization's live invoices
+func (s *analyticsService) InvoiceAnalytics(ctx context.Context, req *dto.InvoiceAnalyticsRequest) (*dto.InvoiceAnalyticsResponse, error) {
+	if err := s.validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Dimensions are always applied in canonical order so the buckets sort
+	// the same way regardless of how group_by was written
+	dimensions := []models.Dimension{}
+	for _, dimension := range models.Dimensions() {
+		if slices.Contains(req.GroupBy, dimension) {
+			dimensions = append(dimensions, dimension)
+		}
+	}
+
+	buckets, err := s.repo.Aggregate(ctx, req, dimensions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Per-currency totals come straight from the buckets when nothing else
+	// splits them; otherwise averages have to be recomputed by the database
+	totals := buckets
+	if len(dimensions) > 0 {
+		totals, err = s.repo.Aggregate(ctx, req, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	currencies := make([]*dto.CurrencySummary, 0, len(totals))
+	for _, bucket := range totals {
+		currencies = append(currencies, &dto.CurrencySummary{
+			CurrencyCode: bucket.CurrencyCode,
+			InvoiceCount: bucket.InvoiceCount,
+			TotalAmount:  bucket.TotalAmount,
+			AvgAmount:    bucket.AvgAmount,
+		})
+	}
+
+	return &dto.InvoiceAnalyticsResponse{
+		OrganizationID: req.OrganizationID,
+		GroupBy:        dimensions,
+		DateFrom:       formatDate(req.DateFrom),
+		DateTo:         formatDate(req.DateTo),
+		Buckets:        buckets,
+		Currencies:     currencies,
+	}, nil
+}
+
+// Helper methods
+
+func (s *analyticsService) validateRequest(req *dto.InvoiceAnalyticsRequest) error {
+	for _, dimension := range req.GroupBy {
+		if _, ok := dimension.Column(); !ok {
+			return analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+				WithDetail("field", "group_by").
+				WithDetail("reason", "unsupported dimension "+string(dimension)).
+				WithDetail("supported", models.Dimensions())
+		}
+	}
+
+	if req.DateFrom != nil && req.DateTo != nil && req.DateFrom.After(*req.DateTo) {
+		return analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+			WithDetail("field", "date_from").
+			WithDetail("reason", "date_from must not be after date_to")
+	}
+
+	return nil
+}
+
+func formatDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format(invoicemodels.DateLayout)
+	return &formatted
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceAnalyticsRequest represents the query parameters of the invoice
+// analytics endpoint. Results are always split by currency; GroupBy adds
+// further dimensions.
+type InvoiceAnalyticsRequest struct {
+	OrganizationID uuid.UUID          `json:"-"`
+	GroupBy        []models.Dimension `query:"group_by"` // comma separated
+	ProjectID      *uuid.UUID         `query:"project_id"`
+	ProviderID     *uuid.UUID         `query:"provider_id"`
+	InvoiceTypeID  *uuid.UUID         `query:"invoice_type_id"`
+	Status         *string            `query:"status"`
+	CurrencyCode   *string            `query:"currency_code"`
+	DateFrom       *time.Time         `query:"date_from"`
+	DateTo         *time.Time         `query:"date_to"`
+}
+
+// CurrencySummary holds the measures of all matching invoices in a currency.
+// Invoices without a currency are summarized under a nil currency code.
+type CurrencySummary struct {
+	CurrencyCode *string          `json:"currency_code"`
+	InvoiceCount int64            `json:"invoice_count"`
+	TotalAmount  *decimal.Decimal `json:"total_amount"`
+	AvgAmount    *decimal.Decimal `json:"avg_amount"`
+}
+
+// InvoiceAnalyticsResponse represents the response of the invoice analytics endpoint
+type InvoiceAnalyticsResponse struct {
+	OrganizationID uuid.UUID          `json:"organization_id"`
+	GroupBy        []models.Dimension `json:"group_by"`
+	DateFrom       *string            `json:"date_from,omitempty"`
+	DateTo         *string            `json:"date_to,omitempty"`
+	Buckets        []*models.Bucket   `json:"buckets"`
+	Currencies     []*CurrencySummary `json:"currencies"`
+}
+
+// AgingReportRequest represents the query parameters of the accounts payable
+// aging report
+type AgingReportRequest struct {
+	OrganizationID  uuid.UUID  `json:"-"`
+	AsOf            *time.Time `query:"as_of"` // defaults to today
+	ProjectID       *uuid.UUID `query:"project_id"`
+	ProviderID      *uuid.UUID `query:"provider_id"`
+	CurrencyCode    *string    `query:"currency_code"`
+	SettledStatuses []string   `query:"settled_statuses"` // comma separated, defaults to models.DefaultSettledStatuses
+}
+
+// AgingCurrencyTotal holds the aging amounts of a currency across all rows.
+// Invoices without a currency are totalled under a nil currency code.
+type AgingCurrencyTotal struct {
+	CurrencyCode        *string `json:"currency_code"`
+	InvoiceCount        int64   `json:"invoice_count"`
+	models.AgingAmounts `json:"amounts"`
+}
+
+// AgingReportResponse represents the accounts payable aging report
+type AgingReportResponse struct {
+	OrganizationID  uuid.UUID             `json:"organization_id"`
+	AsOf            string                `json:"as_of"`
+	SettledStatuses []string              `json:"settled_statuses"`
+	Rows            []*models.AgingRow    `json:"rows"`
+	Totals          []*AgingCurrencyTotal `json:"totals"`
+}
@@ -0,0 +1,34 @@
+package analytics
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// AnalyticsErrors is the error registry for analytics domain
+var AnalyticsErrors = errx.NewRegistry("ANALYTICS")
+
+// Analytics error codes
+var (
+	// Validation errors
+	ErrAnalyticsValidationFailed = AnalyticsErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Analytics request validation failed",
+	)
+
+	// Query errors
+	ErrAnalyticsQueryFailed = AnalyticsErrors.Register(
+		"QUERY_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to compute invoice analytics",
+	)
+)
+
+// Helper functions for error checking
+func IsAnalyticsValidationFailed(err error) bool {
+	return errx.IsCode(err, ErrAnalyticsValidationFailed)
+}
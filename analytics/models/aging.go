@@ -0,0 +1,79 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultSettledStatuses are the statuses of the default invoice workflow that
+// no longer owe anything to the provider
+var DefaultSettledStatuses = []string{"paid", "void"}
+
+// AgingBucket is a range of days past the due date
+type AgingBucket string
+
+// Aging buckets, from not yet due to more than 90 days overdue
+const (
+	AgingCurrent AgingBucket = "current"
+	Aging1To30   AgingBucket = "1-30"
+	Aging31To60  AgingBucket = "31-60"
+	Aging61To90  AgingBucket = "61-90"
+	AgingOver90  AgingBucket = "90+"
+)
+
+// AgingBuckets returns the aging buckets in report order
+func AgingBuckets() []AgingBucket {
+	return []AgingBucket{AgingCurrent, Aging1To30, Aging31To60, Aging61To90, AgingOver90}
+}
+
+// AgingAmounts holds the outstanding amount in every aging bucket
+type AgingAmounts struct {
+	Current    decimal.Decimal `db:"current_amount" json:"current"`
+	Days1To30  decimal.Decimal `db:"days_1_30_amount" json:"days_1_30"`
+	Days31To60 decimal.Decimal `db:"days_31_60_amount" json:"days_31_60"`
+	Days61To90 decimal.Decimal `db:"days_61_90_amount" json:"days_61_90"`
+	Over90     decimal.Decimal `db:"over_90_amount" json:"over_90"`
+	Total      decimal.Decimal `db:"total_amount" json:"total"`
+}
+
+// Bucket returns the amount of the given aging bucket
+func (a AgingAmounts) Bucket(bucket AgingBucket) decimal.Decimal {
+	switch bucket {
+	case AgingCurrent:
+		return a.Current
+	case Aging1To30:
+		return a.Days1To30
+	case Aging31To60:
+		return a.Days31To60
+	case Aging61To90:
+		return a.Days61To90
+	case AgingOver90:
+		return a.Over90
+	}
+	return decimal.Zero
+}
+
+// Add returns the bucket-wise sum of both amounts
+func (a AgingAmounts) Add(other AgingAmounts) AgingAmounts {
+	return AgingAmounts{
+		Current:    a.Current.Add(other.Current),
+		Days1To30:  a.Days1To30.Add(other.Days1To30),
+		Days31To60: a.Days31To60.Add(other.Days31To60),
+		Days61To90: a.Days61To90.Add(other.Days61To90),
+		Over90:     a.Over90.Add(other.Over90),
+		Total:      a.Total.Add(other.Total),
+	}
+}
+
+// AgingRow holds the unpaid invoices of a provider and project in a currency.
+// Invoices without a due date are reported as current, and invoices without
+// a currency under a nil currency code.
+type AgingRow struct {
+	ProviderID   *uuid.UUID `db:"provider_id" json:"provider_id"`
+	ProviderName *string    `db:"provider_name" json:"provider_name"`
+	ProjectID    *uuid.UUID `db:"project_id" json:"project_id"`
+	ProjectName  *string    `db:"project_name" json:"project_name"`
+	CurrencyCode *string    `db:"currency_code" json:"currency_code"`
+	InvoiceCount int64      `db:"invoice_count" json:"invoice_count"`
+	AgingAmounts `json:"amounts"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Dimension is an attribute invoice analytics can be grouped by
+type Dimension string
+
+// Supported analytics dimensions
+const (
+	DimensionProject     Dimension = "project"
+	DimensionProvider    Dimension = "provider"
+	DimensionInvoiceType Dimension = "invoice_type"
+	DimensionStatus      Dimension = "status"
+	DimensionMonth       Dimension = "month"
+	DimensionKind        Dimension = "document_kind"
+)
+
+// dimensionColumns maps every dimension to its invoice_analytics_facts column
+var dimensionColumns = map[Dimension]string{
+	DimensionProject:     "project_id",
+	DimensionProvider:    "provider_id",
+	DimensionInvoiceType: "invoice_type_id",
+	DimensionStatus:      "status",
+	DimensionMonth:       "month",
+	DimensionKind:        "document_kind",
+}
+
+// Dimensions returns the supported dimensions in their canonical order
+func Dimensions() []Dimension {
+	return []Dimension{
+		DimensionProject,
+		DimensionProvider,
+		DimensionInvoiceType,
+		DimensionStatus,
+		DimensionMonth,
+		DimensionKind,
+	}
+}
+
+// Column returns the facts view column of the dimension, or false when the
+// dimension is not supported
+func (d Dimension) Column() (string, bool) {
+	column, ok := dimensionColumns[d]
+	return column, ok
+}
+
+// Bucket holds the measures of the invoices sharing a currency and the
+// values of the requested dimensions. Dimensions that were not requested are
+// left nil. The currency is nil for invoices without one, and amounts are
+// nil when none of the invoices has a total.
+type Bucket struct {
+	CurrencyCode  *string    `db:"currency_code" json:"currency_code"`
+	ProjectID     *uuid.UUID `db:"project_id" json:"project_id,omitempty"`
+	ProviderID    *uuid.UUID `db:"provider_id" json:"provider_id,omitempty"`
+	InvoiceTypeID *uuid.UUID `db:"invoice_type_id" json:"invoice_type_id,omitempty"`
+	Status        *string    `db:"status" json:"status,omitempty"`
+	Month         *string    `db:"month" json:"month,omitempty"`
+	DocumentKind  *string    `db:"document_kind" json:"document_kind,omitempty"`
+
+	InvoiceCount    int64            `db:"invoice_count" json:"invoice_count"`
+	TotalAmount     *decimal.Decimal `db:"total_amount" json:"total_amount"`
+	AvgAmount       *decimal.Decimal `db:"avg_amount" json:"avg_amount"`
+	EarliestInvoice *string          `db:"earliest_invoice" json:"earliest_invoice,omitempty"`
+	LatestInvoice   *string          `db:"latest_invoice" json:"latest_invoice,omitempty"`
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics"
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+)
+
+// AgingReport buckets the unpaid invoices by days past their due date as of
+// req.AsOf. Amounts are the outstanding amounts of invoice_balances, net of
+// credit and debit notes and payments; invoices without a due date count as
+// current.
+func (r *analyticsRepository) AgingReport(ctx context.Context, req *dto.AgingReportRequest) ([]*models.AgingRow, error) {
+	conditions := []string{
+		"i.organization_id = $1",
+		"i.is_deleted = false",
+		"i.document_kind = 'invoice'",
+		"NOT (COALESCE(i.status, '') = ANY($3))",
+	}
+	args := []any{req.OrganizationID, *req.AsOf, pq.Array(req.SettledStatuses)}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.ProjectID != nil {
+		add("i.project_id = $%d", *req.ProjectID)
+	}
+	if req.ProviderID != nil {
+		add("i.provider_id = $%d", *req.ProviderID)
+	}
+	if req.CurrencyCode != nil {
+		add("i.currency_code = $%d", *req.CurrencyCode)
+	}
+
+	query := fmt.Sprintf(`
+		WITH unpaid AS (
+			SELECT
+				i.provider_id,
+				i.project_id,
+				i.currency_code,
+				b.outstanding_amount AS amount,
+				COALESCE($2::date - i.due_date, 0) AS days_overdue
+			FROM invoices i
+			JOIN invoice_balances b ON b.invoice_id = i.id
+			WHERE %s
+		)
+		SELECT
+			u.provider_id,
+			pr.name AS provider_name,
+			u.project_id,
+			p.name AS project_name,
+			u.currency_code,
+			COUNT(*) AS invoice_count,
+			COALESCE(SUM(u.amount) FILTER (WHERE u.days_overdue <= 0), 0) AS current_amount,
+			COALESCE(SUM(u.amount) FILTER (WHERE u.days_overdue BETWEEN 1 AND 30), 0) AS days_1_30_amount,
+			COALESCE(SUM(u.amount) FILTER (WHERE u.days_overdue BETWEEN 31 AND 60), 0) AS days_31_60_amount,
+			COALESCE(SUM(u.amount) FILTER (WHERE u.days_overdue BETWEEN 61 AND 90), 0) AS days_61_90_amount,
+			COALESCE(SUM(u.amount) FILTER (WHERE u.days_overdue > 90), 0) AS over_90_amount,
+			SUM(u.amount) AS total_amount
+		FROM unpaid u
+		LEFT JOIN providers pr ON pr.id = u.provider_id
+		LEFT JOIN projects p ON p.id = u.project_id
+		WHERE u.amount > 0
+		GROUP BY u.provider_id, pr.name, u.project_id, p.name, u.currency_code
+		ORDER BY pr.name NULLS LAST, p.name NULLS LAST, u.currency_code`,
+		strings.Join(conditions, " AND "))
+
+	rows := []*models.AgingRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsQueryFailed).
+			WithDetail("organization_id", req.OrganizationID.String()).
+			WithDetail("report", "ap_aging").
+			WithCause(err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics"
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+)
+
+// analyticsRepository implements AnalyticsRepository over the
+// invoice_analytics_facts view
+type analyticsRepository struct {
+	db *sqlx.DB
+}
+
+// NewAnalyticsRepository creates a new analytics repository
+func NewAnalyticsRepository(db *sqlx.DB) AnalyticsRepository {
+	return &analyticsRepository{
+		db: db,
+	}
+}
+
+// Aggregate groups the invoices matching the request filters by currency
+// and the given dimensions, ordered by the dimension values
+func (r *analyticsRepository) Aggregate(ctx context.Context, req *dto.InvoiceAnalyticsRequest, dimensions []models.Dimension) ([]*models.Bucket, error) {
+	groupColumns := make([]string, 0, len(dimensions)+1)
+	for _, dimension := range dimensions {
+		column, ok := dimension.Column()
+		if !ok {
+			return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsValidationFailed).
+				WithDetail("field", "group_by").
+				WithDetail("reason", "unsupported dimension "+string(dimension))
+		}
+		groupColumns = append(groupColumns, column)
+	}
+	groupColumns = append(groupColumns, "currency_code")
+
+	conditions, args := buildFilters(req)
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s,
+			COUNT(*) AS invoice_count,
+			SUM(total_amount) AS total_amount,
+			ROUND(AVG(total_amount), 2) AS avg_amount,
+			to_char(MIN(invoice_date), 'YYYY-MM-DD') AS earliest_invoice,
+			to_char(MAX(invoice_date), 'YYYY-MM-DD') AS latest_invoice
+		FROM invoice_analytics_facts
+		WHERE %[2]s
+		GROUP BY %[1]s
+		ORDER BY %[1]s`,
+		strings.Join(groupColumns, ", "),
+		strings.Join(conditions, " AND "))
+
+	buckets := []*models.Bucket{}
+	if err := r.db.SelectContext(ctx, &buckets, query, args...); err != nil {
+		return nil, analytics.AnalyticsErrors.New(analytics.ErrAnalyticsQueryFailed).
+			WithDetail("organization_id", req.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	return buckets, nil
+}
+
+// buildFilters translates the request filters into WHERE conditions
+func buildFilters(req *dto.InvoiceAnalyticsRequest) ([]string, []any) {
+	conditions := []string{"organization_id = $1"}
+	args := []any{req.OrganizationID}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.ProjectID != nil {
+		add("project_id = $%d", *req.ProjectID)
+	}
+	if req.ProviderID != nil {
+		add("provider_id = $%d", *req.ProviderID)
+	}
+	if req.InvoiceTypeID != nil {
+		add("invoice_type_id = $%d", *req.InvoiceTypeID)
+	}
+	if req.Status != nil {
+		add("status = $%d", *req.Status)
+	}
+	if req.CurrencyCode != nil {
+		add("currency_code = $%d", *req.CurrencyCode)
+	}
+	if req.DateFrom != nil {
+		add("invoice_date >= $%d", *req.DateFrom)
+	}
+	if req.DateTo != nil {
+		add("invoice_date <= $%d", *req.DateTo)
+	}
+
+	return conditions, args
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+)
+
+// stubResult is the result set every query of a stubConnector returns
+type stubResult struct {
+	columns []string
+	rows    [][]driver.Value
+	queries []string
+}
+
+// stubConnector is a database/sql connector answering every query with a
+// fixed result set, so scans can be tested without a database
+type stubConnector struct{ result *stubResult }
+
+func (c stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn(c), nil }
+func (c stubConnector) Driver() driver.Driver                        { return nil }
+
+type stubConn stubConnector
+
+func (c stubConn) Prepare(query string) (driver.Stmt, error) {
+	c.result.queries = append(c.result.queries, query)
+	return stubStmt(c), nil
+}
+func (c stubConn) Close() error              { return nil }
+func (c stubConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type stubStmt stubConn
+
+func (s stubStmt) Close() error                               { return nil }
+func (s stubStmt) NumInput() int                              { return -1 }
+func (s stubStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &stubRows{result: s.result}, nil
+}
+
+type stubRows struct {
+	result *stubResult
+	next   int
+}
+
+func (r *stubRows) Columns() []string { return r.result.columns }
+func (r *stubRows) Close() error      { return nil }
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.next == len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func stubRepository(result *stubResult) *analyticsRepository {
+	db := sqlx.NewDb(sql.OpenDB(stubConnector{result: result}), "postgres")
+	return &analyticsRepository{db: db}
+}
+
+func TestAggregateScansInvoicesWithoutCurrency(t *testing.T) {
+	result := &stubResult{
+		columns: []string{"status", "currency_code", "invoice_count", "total_amount", "avg_amount", "earliest_invoice", "latest_invoice"},
+		rows: [][]driver.Value{
+			{"approved", "PEN", int64(2), "300.00", "150.00", "2024-01-05", "2024-01-20"},
+			{"approved", nil, int64(1), nil, nil, "2024-01-07", "2024-01-07"},
+		},
+	}
+	repo := stubRepository(result)
+
+	req := &dto.InvoiceAnalyticsRequest{OrganizationID: uuid.New()}
+	buckets, err := repo.Aggregate(context.Background(), req, []models.Dimension{models.DimensionStatus})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("got %d buckets; want 2", len(buckets))
+	}
+
+	if got := buckets[0].CurrencyCode; got == nil || *got != "PEN" {
+		t.Errorf("first bucket currency = %v; want PEN", got)
+	}
+	missing := buckets[1]
+	if missing.CurrencyCode != nil {
+		t.Errorf("second bucket currency = %q; want nil", *missing.CurrencyCode)
+	}
+	if missing.InvoiceCount != 1 || missing.TotalAmount != nil {
+		t.Errorf("second bucket = %d invoices totalling %v; want 1 without a total", missing.InvoiceCount, missing.TotalAmount)
+	}
+
+	if !strings.Contains(result.queries[0], "GROUP BY status, currency_code") {
+		t.Errorf("query does not group by status and currency:\n%s", result.queries[0])
+	}
+}
+
+func TestAgingReportScansInvoicesWithoutCurrency(t *testing.T) {
+	result := &stubResult{
+		columns: []string{
+			"provider_id", "provider_name", "project_id", "project_name", "currency_code", "invoice_count",
+			"current_amount", "days_1_30_amount", "days_31_60_amount", "days_61_90_amount", "over_90_amount", "total_amount",
+		},
+		rows: [][]driver.Value{
+			{nil, nil, nil, nil, nil, int64(1), "0", "80.00", "0", "0", "0", "80.00"},
+		},
+	}
+	repo := stubRepository(result)
+
+	req := &dto.AgingReportRequest{OrganizationID: uuid.New(), AsOf: new(time.Time), SettledStatuses: models.DefaultSettledStatuses}
+	rows, err := repo.AgingReport(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows; want 1", len(rows))
+	}
+	if rows[0].CurrencyCode != nil {
+		t.Errorf("currency = %q; want nil", *rows[0].CurrencyCode)
+	}
+	if !rows[0].Days1To30.Equal(decimal.RequireFromString("80")) {
+		t.Errorf("1-30 amount = %s; want 80", rows[0].Days1To30)
+	}
+}
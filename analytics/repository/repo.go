@@ -0,0 +1,19 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abraxas-365/fuckturamelo/analytics/dto"
+	"github.com/Abraxas-365/fuckturamelo/analytics/models"
+)
+
+// AnalyticsRepository defines the interface for invoice analytics queries
+type AnalyticsRepository interface {
+	// Aggregate groups the invoices matching the request filters by currency
+	// and the given dimensions
+	Aggregate(ctx context.Context, req *dto.InvoiceAnalyticsRequest, dimensions []models.Dimension) ([]*models.Bucket, error)
+
+	// AgingReport buckets the unpaid invoices by days past their due date,
+	// grouped by provider, project and currency
+	AgingReport(ctx context.Context, req *dto.AgingReportRequest) ([]*models.AgingRow, error)
+}
@@ -0,0 +1,365 @@
+package approvalsapi
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals"
+	"github.com/Abraxas-365/fuckturamelo/approvals/approvalsrv"
+	"github.com/Abraxas-365/fuckturamelo/approvals/dto"
+	postgres "github.com/Abraxas-365/fuckturamelo/approvals/repository"
+	invoicespg "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/invoicetypesrv"
+	typespostgres "github.com/Abraxas-365/fuckturamelo/invoicetypes/repository"
+)
+
+// ApprovalsAPI contains the complete API setup for the approvals domain
+type ApprovalsAPI struct {
+	service approvalsrv.ApprovalService
+	repo    postgres.ApprovalRepository
+}
+
+// Config contains configuration for the approvals API
+type Config struct {
+	DB *sqlx.DB
+}
+
+// New creates a new ApprovalsAPI instance
+func New(config Config) (*ApprovalsAPI, error) {
+	if config.DB == nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewApprovalRepository(config.DB)
+	typeSvc := invoicetypesrv.NewInvoiceTypeService(
+		typespostgres.NewInvoiceTypeRepository(config.DB),
+		typespostgres.NewSchemaVersionRepository(config.DB))
+	svc := approvalsrv.NewApprovalService(repo, invoicespg.NewInvoiceRepository(config.DB), typeSvc)
+
+	return &ApprovalsAPI{
+		service: svc,
+		repo:    repo,
+	}, nil
+}
+
+// SetupRoutes registers the invoice approval routes with the given Fiber
+// router group
+func (api *ApprovalsAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Query routes
+	router.Get("/pending", api.listPending)
+	router.Get("/invoice/:invoiceId", api.listApprovals)
+
+	// Approval routes
+	router.Post("/", api.submitInvoice)
+	router.Get("/:id", api.getApproval)
+	router.Post("/:id/decisions", api.decide)
+	router.Post("/:id/cancel", api.cancelApproval)
+}
+
+// SetupChainRoutes registers the approval chain routes with the given Fiber
+// router group
+func (api *ApprovalsAPI) SetupChainRoutes(router fiber.Router) {
+	router.Post("/", api.createChain)
+	router.Get("/", api.listChains)
+	router.Get("/:id", api.getChain)
+	router.Put("/:id", api.updateChain)
+	router.Delete("/:id", api.deleteChain)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *ApprovalsAPI) GetService() approvalsrv.ApprovalService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *ApprovalsAPI) GetRepository() postgres.ApprovalRepository {
+	return api.repo
+}
+
+// Approval handlers
+
+// submitInvoice handles POST /approvals
+func (api *ApprovalsAPI) submitInvoice(c *fiber.Ctx) error {
+	var req dto.SubmitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.SubmitInvoice(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getApproval handles GET /approvals/:id
+func (api *ApprovalsAPI) getApproval(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetApproval(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listApprovals handles GET /approvals/invoice/:invoiceId
+func (api *ApprovalsAPI) listApprovals(c *fiber.Ctx) error {
+	invoiceID, err := api.parseUUIDParam(c, "invoiceId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListApprovals(c.Context(), invoiceID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listPending handles GET /approvals/pending?user_id=&organization_id=
+func (api *ApprovalsAPI) listPending(c *fiber.Ctx) error {
+	userID, err := api.parseUUIDQuery(c, "user_id")
+	if err != nil {
+		return err
+	}
+	if userID == nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "User ID parameter is required")
+	}
+
+	orgID, err := api.parseUUIDQuery(c, "organization_id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListPending(c.Context(), &dto.PendingListRequest{
+		UserID:         *userID,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// decide handles POST /approvals/:id/decisions
+func (api *ApprovalsAPI) decide(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.DecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.Decide(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// cancelApproval handles POST /approvals/:id/cancel
+func (api *ApprovalsAPI) cancelApproval(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.CancelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.CancelApproval(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Chain handlers
+
+// createChain handles POST /approval-chains
+func (api *ApprovalsAPI) createChain(c *fiber.Ctx) error {
+	var req dto.CreateChainRequest
+	if err := c.BodyParser(&req); err != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.CreateChain(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listChains handles GET /approval-chains?organization_id=
+func (api *ApprovalsAPI) listChains(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDQuery(c, "organization_id")
+	if err != nil {
+		return err
+	}
+	if orgID == nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Organization ID parameter is required")
+	}
+
+	result, err := api.service.ListChains(c.Context(), *orgID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getChain handles GET /approval-chains/:id
+func (api *ApprovalsAPI) getChain(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetChain(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// updateChain handles PUT /approval-chains/:id
+func (api *ApprovalsAPI) updateChain(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateChainRequest
+	if err := c.BodyParser(&req); err != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.UpdateChain(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteChain handles DELETE /approval-chains/:id
+func (api *ApprovalsAPI) deleteChain(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteChain(c.Context(), id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// healthCheck provides a health check endpoint
+func (api *ApprovalsAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "approvals",
+	})
+}
+
+// Helper methods
+
+func (api *ApprovalsAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *ApprovalsAPI) parseUUIDQuery(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &id, nil
+}
@@ -0,0 +1,468 @@
+package approvalsrv
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals"
+	"github.com/Abraxas-365/fuckturamelo/approvals/dto"
+	"github.com/Abraxas-365/fuckturamelo/approvals/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/approvals/repository"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// ApprovalService defines the interface for invoice approval business logic
+type ApprovalService interface {
+	// Chain operations
+	CreateChain(ctx context.Context, req *dto.CreateChainRequest) (*models.Chain, error)
+	GetChain(ctx context.Context, id uuid.UUID) (*models.Chain, error)
+	ListChains(ctx context.Context, orgID uuid.UUID) (*dto.ChainListResponse, error)
+	UpdateChain(ctx context.Context, id uuid.UUID, req *dto.UpdateChainRequest) (*models.Chain, error)
+	DeleteChain(ctx context.Context, id uuid.UUID) error
+
+	// Approval operations
+	SubmitInvoice(ctx context.Context, req *dto.SubmitRequest) (*dto.ApprovalResponse, error)
+	GetApproval(ctx context.Context, id uuid.UUID) (*dto.ApprovalResponse, error)
+	ListApprovals(ctx context.Context, invoiceID uuid.UUID) (*dto.ApprovalListResponse, error)
+	Decide(ctx context.Context, id uuid.UUID, req *dto.DecisionRequest) (*dto.ApprovalResponse, error)
+	CancelApproval(ctx context.Context, id uuid.UUID, req *dto.CancelRequest) (*dto.ApprovalResponse, error)
+	ListPending(ctx context.Context, req *dto.PendingListRequest) (*dto.PendingListResponse, error)
+
+	// Invoice gate
+	CheckTransition(ctx context.Context, invoice *invoicemodels.Invoice, toStatus string) error
+	CheckEditable(ctx context.Context, invoice *invoicemodels.Invoice) error
+}
+
+// Invoices reads the invoices submitted for approval (implemented by
+// invoices/repository.InvoiceRepository)
+type Invoices interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error)
+}
+
+// StatusWorkflows exposes the invoice type workflows approvals move invoices
+// through (implemented by invoicetypesrv.InvoiceTypeService)
+type StatusWorkflows interface {
+	GetStatusWorkflow(ctx context.Context, invoiceTypeID uuid.UUID) (*typemodels.StatusWorkflow, error)
+}
+
+// approvalService implements ApprovalService
+type approvalService struct {
+	repo      postgres.ApprovalRepository
+	invoices  Invoices
+	workflows StatusWorkflows
+}
+
+// NewApprovalService creates a new approval service
+func NewApprovalService(repo postgres.ApprovalRepository, invoices Invoices, workflows StatusWorkflows) ApprovalService {
+	return &approvalService{
+		repo:      repo,
+		invoices:  invoices,
+		workflows: workflows,
+	}
+}
+
+// Chain operations
+
+// CreateChain creates an approval chain for a project, an invoice type, both
+// or the whole organization
+func (s *approvalService) CreateChain(ctx context.Context, req *dto.CreateChainRequest) (*models.Chain, error) {
+	fail := func(field, reason string) error {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("field", field).
+			WithDetail("reason", reason)
+	}
+
+	if req.OrganizationID == uuid.Nil {
+		return nil, fail("organization_id", "required")
+	}
+	if req.Name == "" {
+		return nil, fail("name", "required")
+	}
+	if err := req.Steps.Validate(); err != nil {
+		return nil, fail("steps", err.Error())
+	}
+
+	now := time.Now()
+	return s.repo.CreateChain(ctx, &models.Chain{
+		ID:             uuid.New(),
+		OrganizationID: req.OrganizationID,
+		Name:           req.Name,
+		Description:    req.Description,
+		ProjectID:      req.ProjectID,
+		InvoiceTypeID:  req.InvoiceTypeID,
+		Steps:          req.Steps,
+		IsActive:       true,
+		CreatedBy:      req.CreatedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+}
+
+// GetChain retrieves an approval chain by ID
+func (s *approvalService) GetChain(ctx context.Context, id uuid.UUID) (*models.Chain, error) {
+	return s.repo.GetChain(ctx, id)
+}
+
+// ListChains lists the approval chains of an organization
+func (s *approvalService) ListChains(ctx context.Context, orgID uuid.UUID) (*dto.ChainListResponse, error) {
+	chains, err := s.repo.ListChains(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ChainListResponse{Chains: chains}, nil
+}
+
+// UpdateChain changes the name, description, steps or activity of a chain
+func (s *approvalService) UpdateChain(ctx context.Context, id uuid.UUID, req *dto.UpdateChainRequest) (*models.Chain, error) {
+	fail := func(field, reason string) error {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("field", field).
+			WithDetail("reason", reason)
+	}
+
+	chain, err := s.repo.GetChain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, fail("name", "required")
+		}
+		chain.Name = *req.Name
+	}
+	if req.Description != nil {
+		chain.Description = req.Description
+	}
+	if req.Steps != nil {
+		if err := req.Steps.Validate(); err != nil {
+			return nil, fail("steps", err.Error())
+		}
+		chain.Steps = req.Steps
+	}
+	if req.IsActive != nil {
+		chain.IsActive = *req.IsActive
+	}
+
+	return s.repo.UpdateChain(ctx, chain)
+}
+
+// DeleteChain deletes an approval chain
+func (s *approvalService) DeleteChain(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteChain(ctx, id)
+}
+
+// Approval operations
+
+// SubmitInvoice starts the approval of an invoice through the chain that
+// applies to it, moving the invoice to submitted if it is not there yet.
+// Approvers are resolved from the organization's active memberships; the
+// requester never approves their own invoice.
+func (s *approvalService) SubmitInvoice(ctx context.Context, req *dto.SubmitRequest) (*dto.ApprovalResponse, error) {
+	fail := func(field, reason string) error {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("field", field).
+			WithDetail("reason", reason)
+	}
+
+	if req.InvoiceID == uuid.Nil {
+		return nil, fail("invoice_id", "required")
+	}
+	if req.RequestedBy == uuid.Nil {
+		return nil, fail("requested_by", "required")
+	}
+
+	invoice, err := s.getInvoice(ctx, req.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.workflows.GetStatusWorkflow(ctx, invoice.InvoiceTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := workflow.InitialStatus
+	if invoice.Status != nil {
+		from = *invoice.Status
+	}
+	if !workflow.CanTransition(models.SubmittedStatus, models.ApprovedStatus) ||
+		(from != models.SubmittedStatus && !workflow.CanTransition(from, models.SubmittedStatus)) {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrWorkflowNotSupported).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithDetail("status", from)
+	}
+
+	chain, steps, err := s.applicableChain(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+	if chain == nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrNoApplicableChain).
+			WithDetail("invoice_id", invoice.ID.String())
+	}
+
+	now := time.Now()
+	approval := &models.Approval{
+		ID:             uuid.New(),
+		InvoiceID:      invoice.ID,
+		OrganizationID: invoice.OrganizationID,
+		ChainID:        &chain.ID,
+		ChainName:      chain.Name,
+		InvoiceVersion: invoice.Version,
+		Amount:         invoiceAmount(invoice),
+		CurrencyCode:   invoice.CurrencyCode,
+		RequestedBy:    req.RequestedBy,
+		RequestedAt:    now,
+		Comment:        req.Comment,
+		UpdatedAt:      now,
+	}
+
+	for i, step := range steps {
+		members, err := s.repo.ResolveApprovers(ctx, invoice.OrganizationID, step.Approvers)
+		if err != nil {
+			return nil, err
+		}
+		members = slices.DeleteFunc(members, func(id uuid.UUID) bool {
+			return id == req.RequestedBy
+		})
+		if len(members) == 0 {
+			return nil, approvals.ApprovalsErrors.New(approvals.ErrNoApprovers).
+				WithDetail("chain_id", chain.ID.String()).
+				WithDetail("step", step.Name)
+		}
+
+		approvalStep := models.ApprovalStep{
+			ID:         uuid.New(),
+			ApprovalID: approval.ID,
+			Position:   i + 1,
+			Stage:      step.Stage,
+			Name:       step.Name,
+			Rule:       step.Rule,
+		}
+		for _, member := range members {
+			approvalStep.Assignees = append(approvalStep.Assignees, models.Assignee{
+				ID:     uuid.New(),
+				StepID: approvalStep.ID,
+				UserID: member,
+			})
+		}
+		approval.Steps = append(approval.Steps, approvalStep)
+	}
+	approval.Start()
+
+	var transition *invoicemodels.StatusTransition
+	if from != models.SubmittedStatus {
+		transition = &invoicemodels.StatusTransition{
+			InvoiceID:      invoice.ID,
+			OrganizationID: invoice.OrganizationID,
+			FromStatus:     invoice.Status,
+			ToStatus:       models.SubmittedStatus,
+			Comment:        req.Comment,
+			TransitionedBy: req.RequestedBy,
+			TransitionedAt: now,
+		}
+	}
+
+	created, submitted, err := s.repo.StartApproval(ctx, approval, invoice, transition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApprovalResponse{Approval: created, Invoice: submitted}, nil
+}
+
+// GetApproval retrieves an approval with its steps and decisions
+func (s *approvalService) GetApproval(ctx context.Context, id uuid.UUID) (*dto.ApprovalResponse, error) {
+	approval, err := s.repo.GetApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApprovalResponse{Approval: approval}, nil
+}
+
+// ListApprovals returns the approval history of an invoice
+func (s *approvalService) ListApprovals(ctx context.Context, invoiceID uuid.UUID) (*dto.ApprovalListResponse, error) {
+	if _, err := s.getInvoice(ctx, invoiceID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.ListApprovals(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApprovalListResponse{InvoiceID: invoiceID, Approvals: list}, nil
+}
+
+// Decide approves, rejects or requests changes on the steps of an approval
+// waiting for the user. Approving the last stage approves the invoice,
+// rejecting voids it and requesting changes returns it to its initial
+// status.
+func (s *approvalService) Decide(ctx context.Context, id uuid.UUID, req *dto.DecisionRequest) (*dto.ApprovalResponse, error) {
+	fail := func(field, reason string) error {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("field", field).
+			WithDetail("reason", reason)
+	}
+
+	if !req.Decision.Valid() {
+		return nil, fail("decision", "must be approve, reject or request_changes")
+	}
+	if req.UserID == uuid.Nil {
+		return nil, fail("user_id", "required")
+	}
+
+	workflow, err := s.approvalWorkflow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	approval, invoice, err := s.repo.Decide(ctx, id, req.UserID, req.Decision, req.Comment, *workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApprovalResponse{Approval: approval, Invoice: invoice}, nil
+}
+
+// CancelApproval lets the requester withdraw a pending approval
+func (s *approvalService) CancelApproval(ctx context.Context, id uuid.UUID, req *dto.CancelRequest) (*dto.ApprovalResponse, error) {
+	if req.CancelledBy == uuid.Nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("field", "cancelled_by").
+			WithDetail("reason", "required")
+	}
+
+	workflow, err := s.approvalWorkflow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	approval, invoice, err := s.repo.Cancel(ctx, id, req.CancelledBy, req.Comment, *workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApprovalResponse{Approval: approval, Invoice: invoice}, nil
+}
+
+// ListPending lists the approval steps waiting for a user's decision
+func (s *approvalService) ListPending(ctx context.Context, req *dto.PendingListRequest) (*dto.PendingListResponse, error) {
+	if req.UserID == uuid.Nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsValidationFailed).
+			WithDetail("field", "user_id").
+			WithDetail("reason", "required")
+	}
+
+	pending, err := s.repo.ListPending(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PendingListResponse{
+		UserID:    req.UserID,
+		Approvals: pending,
+		Total:     len(pending),
+	}, nil
+}
+
+// Invoice gate
+
+// CheckTransition keeps invoices under approval in their status and stops
+// invoices a chain applies to from being approved by hand
+func (s *approvalService) CheckTransition(ctx context.Context, invoice *invoicemodels.Invoice, toStatus string) error {
+	if err := s.CheckEditable(ctx, invoice); err != nil {
+		return err
+	}
+	if toStatus != models.ApprovedStatus {
+		return nil
+	}
+
+	chain, _, err := s.applicableChain(ctx, invoice)
+	if err != nil {
+		return err
+	}
+	if chain != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalRequired).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithDetail("chain_id", chain.ID.String())
+	}
+
+	return nil
+}
+
+// CheckEditable fails while the invoice has an approval in progress
+func (s *approvalService) CheckEditable(ctx context.Context, invoice *invoicemodels.Invoice) error {
+	pending, err := s.repo.GetPendingApproval(ctx, invoice.ID)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalPending).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithDetail("approval_id", pending.ID.String())
+	}
+
+	return nil
+}
+
+// Helper methods
+
+func (s *approvalService) getInvoice(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error) {
+	invoice, err := s.invoices.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.IsDeleted {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+			WithDetail("invoice_id", id.String())
+	}
+	return invoice, nil
+}
+
+// applicableChain returns the chain that applies to an invoice with the
+// steps its amount goes through, or nil when no step applies
+func (s *approvalService) applicableChain(ctx context.Context, invoice *invoicemodels.Invoice) (*models.Chain, models.Steps, error) {
+	chain, err := s.repo.FindChain(ctx, invoice)
+	if err != nil || chain == nil {
+		return nil, nil, err
+	}
+
+	steps := chain.Steps.ForAmount(invoiceAmount(invoice))
+	if len(steps) == 0 {
+		return nil, nil, nil
+	}
+	return chain, steps, nil
+}
+
+// approvalWorkflow returns the workflow of the invoice an approval is about
+func (s *approvalService) approvalWorkflow(ctx context.Context, id uuid.UUID) (*typemodels.StatusWorkflow, error) {
+	approval, err := s.repo.GetApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.invoices.GetByID(ctx, approval.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.workflows.GetStatusWorkflow(ctx, invoice.InvoiceTypeID)
+}
+
+// invoiceAmount is the total the thresholds of chain steps are compared with
+func invoiceAmount(invoice *invoicemodels.Invoice) decimal.Decimal {
+	if invoice.TotalAmount == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromFloat(*invoice.TotalAmount).Round(2)
+}
@@ -0,0 +1,95 @@
+package approvalsrv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals"
+	"github.com/Abraxas-365/fuckturamelo/approvals/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/approvals/repository"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// gateRepo answers the lookups of the invoice gate; other repository calls
+// panic
+type gateRepo struct {
+	postgres.ApprovalRepository
+	pending *models.Approval
+	chain   *models.Chain
+}
+
+func (r *gateRepo) GetPendingApproval(ctx context.Context, invoiceID uuid.UUID) (*models.Approval, error) {
+	return r.pending, nil
+}
+
+func (r *gateRepo) FindChain(ctx context.Context, invoice *invoicemodels.Invoice) (*models.Chain, error) {
+	return r.chain, nil
+}
+
+func TestCheckTransition(t *testing.T) {
+	threshold := decimal.RequireFromString("1000")
+	chain := &models.Chain{
+		ID:    uuid.New(),
+		Steps: models.Steps{{Name: "cfo", Stage: 1, Rule: models.RuleAny, MinAmount: &threshold}},
+	}
+	pending := &models.Approval{ID: uuid.New(), Status: models.ApprovalPending}
+	total := func(amount float64) *invoicemodels.Invoice {
+		return &invoicemodels.Invoice{ID: uuid.New(), TotalAmount: &amount}
+	}
+
+	tests := []struct {
+		name     string
+		repo     *gateRepo
+		invoice  *invoicemodels.Invoice
+		toStatus string
+		code     errx.Code // empty when the transition is allowed
+	}{
+		{"no chain", &gateRepo{}, total(5000), models.ApprovedStatus, ""},
+		{"under the threshold", &gateRepo{chain: chain}, total(999.99), models.ApprovedStatus, ""},
+		{"approved by hand", &gateRepo{chain: chain}, total(1000), models.ApprovedStatus, approvals.ErrApprovalRequired},
+		{"other status", &gateRepo{chain: chain}, total(1000), models.VoidStatus, ""},
+		{"under approval", &gateRepo{pending: pending}, total(10), models.VoidStatus, approvals.ErrApprovalPending},
+		{"under approval and chained", &gateRepo{pending: pending, chain: chain}, total(1000), models.ApprovedStatus, approvals.ErrApprovalPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewApprovalService(tt.repo, nil, nil)
+			err := service.CheckTransition(context.Background(), tt.invoice, tt.toStatus)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("CheckTransition: %v", err)
+				}
+				return
+			}
+			if !errx.IsCode(err, tt.code) {
+				t.Fatalf("err = %v; want %s", err, tt.code)
+			}
+			if got := err.(*errx.Error).Details["invoice_id"]; got != tt.invoice.ID.String() {
+				t.Errorf("invoice_id = %v; want %s", got, tt.invoice.ID)
+			}
+		})
+	}
+}
+
+func TestCheckEditable(t *testing.T) {
+	invoice := &invoicemodels.Invoice{ID: uuid.New()}
+	pending := &models.Approval{ID: uuid.New(), Status: models.ApprovalPending}
+
+	service := NewApprovalService(&gateRepo{}, nil, nil)
+	if err := service.CheckEditable(context.Background(), invoice); err != nil {
+		t.Errorf("CheckEditable without an approval: %v", err)
+	}
+
+	service = NewApprovalService(&gateRepo{pending: pending}, nil, nil)
+	err := service.CheckEditable(context.Background(), invoice)
+	if !errx.IsCode(err, approvals.ErrApprovalPending) {
+		t.Fatalf("err = %v; want %s", err, approvals.ErrApprovalPending)
+	}
+	if got := err.(*errx.Error).Details["approval_id"]; got != pending.ID.String() {
+		t.Errorf("approval_id = %v; want %s", got, pending.ID)
+	}
+}
@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals/models"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// CreateChainRequest represents the request payload for creating an
+// approval chain. Without project and invoice type the chain covers the
+// whole organization.
+type CreateChainRequest struct {
+	OrganizationID uuid.UUID    `json:"organization_id" validate:"required"`
+	Name           string       `json:"name" validate:"required,min=1,max=255"`
+	Description    *string      `json:"description,omitempty"`
+	ProjectID      *uuid.UUID   `json:"project_id,omitempty"`
+	InvoiceTypeID  *uuid.UUID   `json:"invoice_type_id,omitempty"`
+	Steps          models.Steps `json:"steps" validate:"required"`
+	CreatedBy      *uuid.UUID   `json:"created_by,omitempty"`
+}
+
+// UpdateChainRequest represents the request payload for updating an approval
+// chain. The scope of a chain cannot change; approvals in progress keep the
+// steps they started with.
+type UpdateChainRequest struct {
+	Name        *string      `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string      `json:"description,omitempty"`
+	Steps       models.Steps `json:"steps,omitempty"`
+	IsActive    *bool        `json:"is_active,omitempty"`
+}
+
+// ChainListResponse represents the response for listing approval chains
+type ChainListResponse struct {
+	Chains []models.Chain `json:"chains"`
+}
+
+// SubmitRequest submits an invoice for approval through the chain that
+// applies to it
+type SubmitRequest struct {
+	InvoiceID   uuid.UUID `json:"invoice_id" validate:"required"`
+	RequestedBy uuid.UUID `json:"requested_by" validate:"required"`
+	Comment     *string   `json:"comment,omitempty"`
+}
+
+// DecisionRequest approves, rejects or requests changes on the steps of an
+// approval waiting for the user
+type DecisionRequest struct {
+	Decision models.Decision `json:"decision" validate:"required,oneof=approve reject request_changes"`
+	UserID   uuid.UUID       `json:"user_id" validate:"required"`
+	Comment  *string         `json:"comment,omitempty"`
+}
+
+// CancelRequest withdraws a pending approval
+type CancelRequest struct {
+	CancelledBy uuid.UUID `json:"cancelled_by" validate:"required"`
+	Comment     *string   `json:"comment,omitempty"`
+}
+
+// ApprovalResponse returns an approval with the invoice when its status
+// changed
+type ApprovalResponse struct {
+	*models.Approval `json:",inline"`
+	Invoice          *invoicemodels.Invoice `json:"invoice,omitempty"`
+}
+
+// ApprovalListResponse represents the approval history of an invoice
+type ApprovalListResponse struct {
+	InvoiceID uuid.UUID          `json:"invoice_id"`
+	Approvals []*models.Approval `json:"approvals"`
+}
+
+// PendingListRequest represents query parameters for listing the approvals
+// waiting for a user
+type PendingListRequest struct {
+	UserID         uuid.UUID  `query:"user_id" validate:"required"`
+	OrganizationID *uuid.UUID `query:"organization_id"`
+}
+
+// PendingListResponse lists the steps waiting for a user's decision, oldest
+// request first
+type PendingListResponse struct {
+	UserID    uuid.UUID                `json:"user_id"`
+	Approvals []models.PendingApproval `json:"approvals"`
+	Total     int                      `json:"total"`
+}
@@ -0,0 +1,150 @@
+package approvals
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// ApprovalsErrors is the error registry for approvals domain
+var ApprovalsErrors = errx.NewRegistry("APPROVALS")
+
+// Approval error codes
+var (
+	// Chain errors
+	ErrChainNotFound = ApprovalsErrors.Register(
+		"CHAIN_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Approval chain not found",
+	)
+
+	ErrChainNameExists = ApprovalsErrors.Register(
+		"CHAIN_NAME_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Approval chain with this name already exists in the organization",
+	)
+
+	ErrChainScopeExists = ApprovalsErrors.Register(
+		"CHAIN_SCOPE_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"An active approval chain already covers this project and invoice type",
+	)
+
+	ErrChainFailed = ApprovalsErrors.Register(
+		"CHAIN_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save approval chain",
+	)
+
+	ErrNoApplicableChain = ApprovalsErrors.Register(
+		"NO_APPLICABLE_CHAIN",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"No approval chain applies to the invoice",
+	)
+
+	ErrNoApprovers = ApprovalsErrors.Register(
+		"NO_APPROVERS",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"No organization member can approve an approval step",
+	)
+
+	ErrWorkflowNotSupported = ApprovalsErrors.Register(
+		"WORKFLOW_NOT_SUPPORTED",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Invoice status workflow does not allow submitting the invoice for approval",
+	)
+
+	// Approval errors
+	ErrApprovalNotFound = ApprovalsErrors.Register(
+		"APPROVAL_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Invoice approval not found",
+	)
+
+	ErrApprovalFailed = ApprovalsErrors.Register(
+		"APPROVAL_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save invoice approval",
+	)
+
+	ErrApprovalPending = ApprovalsErrors.Register(
+		"APPROVAL_PENDING",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice has an approval in progress",
+	)
+
+	ErrApprovalRequired = ApprovalsErrors.Register(
+		"APPROVAL_REQUIRED",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice must be approved through its approval chain",
+	)
+
+	ErrApprovalClosed = ApprovalsErrors.Register(
+		"APPROVAL_CLOSED",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice approval has already been decided",
+	)
+
+	ErrNotApprover = ApprovalsErrors.Register(
+		"NOT_APPROVER",
+		errx.TypeAuthorization,
+		http.StatusForbidden,
+		"User has no decision to make on the invoice approval",
+	)
+
+	ErrInvoiceChanged = ApprovalsErrors.Register(
+		"INVOICE_CHANGED",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice changed while it was being submitted for approval",
+	)
+
+	// Query errors
+	ErrApprovalsListFailed = ApprovalsErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list approvals",
+	)
+
+	// Validation errors
+	ErrApprovalsValidationFailed = ApprovalsErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Approval validation failed",
+	)
+
+	// Reference errors
+	ErrApprovalsInvalidReference = ApprovalsErrors.Register(
+		"INVALID_REFERENCE",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Referenced project or invoice type does not exist",
+	)
+)
+
+// Helper functions for error checking
+func IsChainNotFound(err error) bool {
+	return errx.IsCode(err, ErrChainNotFound)
+}
+
+func IsApprovalNotFound(err error) bool {
+	return errx.IsCode(err, ErrApprovalNotFound)
+}
+
+func IsApprovalPending(err error) bool {
+	return errx.IsCode(err, ErrApprovalPending)
+}
@@ -0,0 +1,272 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// ApprovalStatus is where an invoice approval is in its lifecycle
+type ApprovalStatus string
+
+// Approval statuses. Only pending approvals take decisions.
+const (
+	ApprovalPending          ApprovalStatus = "pending"
+	ApprovalApproved         ApprovalStatus = "approved"
+	ApprovalRejected         ApprovalStatus = "rejected"
+	ApprovalChangesRequested ApprovalStatus = "changes_requested"
+	ApprovalCancelled        ApprovalStatus = "cancelled"
+)
+
+// InvoiceStatus returns the status a decided approval moves its invoice to:
+// approved invoices are approved, rejected ones voided, and invoices whose
+// approval was cancelled or sent back return to the initial status
+func (s ApprovalStatus) InvoiceStatus(workflow typemodels.StatusWorkflow) string {
+	switch s {
+	case ApprovalApproved:
+		return ApprovedStatus
+	case ApprovalRejected:
+		return VoidStatus
+	case ApprovalChangesRequested, ApprovalCancelled:
+		return workflow.InitialStatus
+	}
+	return ""
+}
+
+// StepStatus is where a step of an approval is. Steps of later stages wait
+// until every step of the current stage is approved.
+type StepStatus string
+
+// Approval step statuses
+const (
+	StepWaiting          StepStatus = "waiting"
+	StepPending          StepStatus = "pending"
+	StepApproved         StepStatus = "approved"
+	StepRejected         StepStatus = "rejected"
+	StepChangesRequested StepStatus = "changes_requested"
+	StepSkipped          StepStatus = "skipped"
+)
+
+// Decision is an approver's answer to a step
+type Decision string
+
+// Approval decisions
+const (
+	DecisionApprove        Decision = "approve"
+	DecisionReject         Decision = "reject"
+	DecisionRequestChanges Decision = "request_changes"
+)
+
+// Valid reports whether the decision is known
+func (d Decision) Valid() bool {
+	switch d {
+	case DecisionApprove, DecisionReject, DecisionRequestChanges:
+		return true
+	}
+	return false
+}
+
+// Approval is an invoice going through an approval chain
+type Approval struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	InvoiceID      uuid.UUID       `db:"invoice_id" json:"invoice_id"`
+	OrganizationID uuid.UUID       `db:"organization_id" json:"organization_id"`
+	ChainID        *uuid.UUID      `db:"chain_id" json:"chain_id"`
+	ChainName      string          `db:"chain_name" json:"chain_name"`
+	Status         ApprovalStatus  `db:"status" json:"status"`
+	CurrentStage   *int            `db:"current_stage" json:"current_stage"`
+	InvoiceVersion int             `db:"invoice_version" json:"invoice_version"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	CurrencyCode   *string         `db:"currency_code" json:"currency_code"`
+	RequestedBy    uuid.UUID       `db:"requested_by" json:"requested_by"`
+	RequestedAt    time.Time       `db:"requested_at" json:"requested_at"`
+	Comment        *string         `db:"comment" json:"comment,omitempty"`
+	CompletedAt    *time.Time      `db:"completed_at" json:"completed_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+
+	// Steps are stored in invoice_approval_steps
+	Steps []ApprovalStep `db:"-" json:"steps"`
+}
+
+// TableName returns the table name for the Approval model
+func (a Approval) TableName() string {
+	return "invoice_approvals"
+}
+
+// ApprovalStep is a chain step copied into an approval, with the members it
+// was resolved to
+type ApprovalStep struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	ApprovalID  uuid.UUID  `db:"approval_id" json:"approval_id"`
+	Position    int        `db:"position" json:"position"`
+	Stage       int        `db:"stage" json:"stage"`
+	Name        string     `db:"name" json:"name"`
+	Rule        StepRule   `db:"rule" json:"rule"`
+	Status      StepStatus `db:"status" json:"status"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
+
+	// Assignees are stored in invoice_approval_assignees
+	Assignees []Assignee `db:"-" json:"assignees"`
+}
+
+// TableName returns the table name for the ApprovalStep model
+func (s ApprovalStep) TableName() string {
+	return "invoice_approval_steps"
+}
+
+// Assignee is a member who may decide an approval step
+type Assignee struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	StepID    uuid.UUID  `db:"step_id" json:"step_id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	Decision  *Decision  `db:"decision" json:"decision"`
+	Comment   *string    `db:"comment" json:"comment,omitempty"`
+	DecidedAt *time.Time `db:"decided_at" json:"decided_at"`
+}
+
+// TableName returns the table name for the Assignee model
+func (a Assignee) TableName() string {
+	return "invoice_approval_assignees"
+}
+
+// Start puts the steps of the first stage up for decision
+func (a *Approval) Start() {
+	a.Status = ApprovalPending
+	for i := range a.Steps {
+		a.Steps[i].Status = StepWaiting
+	}
+	a.activateNextStage()
+}
+
+// Decide records the user's decision on every step of the current stage
+// waiting for it. Rejecting or requesting changes on any step decides the
+// whole approval; approvals move on to the next stage once every step of the
+// current one is approved. Decide reports false when the user has nothing to
+// decide.
+func (a *Approval) Decide(userID uuid.UUID, decision Decision, comment *string, now time.Time) bool {
+	if a.Status != ApprovalPending {
+		return false
+	}
+
+	decided := false
+	for i := range a.Steps {
+		step := &a.Steps[i]
+		if step.Status != StepPending {
+			continue
+		}
+		for j := range step.Assignees {
+			assignee := &step.Assignees[j]
+			if assignee.UserID != userID || assignee.Decision != nil {
+				continue
+			}
+			assignee.Decision = &decision
+			assignee.Comment = comment
+			assignee.DecidedAt = &now
+			decided = true
+
+			switch decision {
+			case DecisionReject:
+				step.complete(StepRejected, now)
+			case DecisionRequestChanges:
+				step.complete(StepChangesRequested, now)
+			case DecisionApprove:
+				if step.Rule == RuleAny || step.allApproved() {
+					step.complete(StepApproved, now)
+				}
+			}
+		}
+	}
+	if !decided {
+		return false
+	}
+
+	switch decision {
+	case DecisionReject:
+		a.finish(ApprovalRejected, now)
+	case DecisionRequestChanges:
+		a.finish(ApprovalChangesRequested, now)
+	case DecisionApprove:
+		for _, step := range a.Steps {
+			if step.Status == StepPending {
+				return true
+			}
+		}
+		if !a.activateNextStage() {
+			a.finish(ApprovalApproved, now)
+		}
+	}
+
+	return true
+}
+
+// Cancel withdraws a pending approval
+func (a *Approval) Cancel(now time.Time) {
+	a.finish(ApprovalCancelled, now)
+}
+
+// activateNextStage puts the waiting steps of the lowest stage up for
+// decision. It reports false when no step is waiting.
+func (a *Approval) activateNextStage() bool {
+	next := 0
+	for _, step := range a.Steps {
+		if step.Status == StepWaiting && (next == 0 || step.Stage < next) {
+			next = step.Stage
+		}
+	}
+	if next == 0 {
+		return false
+	}
+
+	for i := range a.Steps {
+		if a.Steps[i].Status == StepWaiting && a.Steps[i].Stage == next {
+			a.Steps[i].Status = StepPending
+		}
+	}
+	a.CurrentStage = &next
+	return true
+}
+
+// finish closes the approval and skips the steps that were not decided
+func (a *Approval) finish(status ApprovalStatus, now time.Time) {
+	a.Status = status
+	a.CurrentStage = nil
+	a.CompletedAt = &now
+	for i := range a.Steps {
+		if a.Steps[i].Status == StepWaiting || a.Steps[i].Status == StepPending {
+			a.Steps[i].Status = StepSkipped
+		}
+	}
+}
+
+func (s *ApprovalStep) complete(status StepStatus, now time.Time) {
+	s.Status = status
+	s.CompletedAt = &now
+}
+
+func (s *ApprovalStep) allApproved() bool {
+	for _, assignee := range s.Assignees {
+		if assignee.Decision == nil || *assignee.Decision != DecisionApprove {
+			return false
+		}
+	}
+	return true
+}
+
+// PendingApproval is a step waiting for a member's decision
+type PendingApproval struct {
+	ApprovalID     uuid.UUID       `db:"approval_id" json:"approval_id"`
+	InvoiceID      uuid.UUID       `db:"invoice_id" json:"invoice_id"`
+	OrganizationID uuid.UUID       `db:"organization_id" json:"organization_id"`
+	InvoiceNumber  *string         `db:"invoice_number" json:"invoice_number"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	CurrencyCode   *string         `db:"currency_code" json:"currency_code"`
+	ChainName      string          `db:"chain_name" json:"chain_name"`
+	StepID         uuid.UUID       `db:"step_id" json:"step_id"`
+	StepName       string          `db:"step_name" json:"step_name"`
+	Stage          int             `db:"stage" json:"stage"`
+	RequestedBy    uuid.UUID       `db:"requested_by" json:"requested_by"`
+	RequestedAt    time.Time       `db:"requested_at" json:"requested_at"`
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// newApproval starts an approval through the given steps
+func newApproval(steps ...ApprovalStep) *Approval {
+	approval := &Approval{ID: uuid.New(), Steps: steps}
+	approval.Start()
+	return approval
+}
+
+// step is an approval step assigned to users
+func step(name string, stage int, rule StepRule, users ...uuid.UUID) ApprovalStep {
+	s := ApprovalStep{ID: uuid.New(), Name: name, Stage: stage, Rule: rule}
+	for _, user := range users {
+		s.Assignees = append(s.Assignees, Assignee{ID: uuid.New(), StepID: s.ID, UserID: user})
+	}
+	return s
+}
+
+// statuses lists the status of each step of an approval
+func statuses(approval *Approval) []StepStatus {
+	var list []StepStatus
+	for _, s := range approval.Steps {
+		list = append(list, s.Status)
+	}
+	return list
+}
+
+func TestApprovalStart(t *testing.T) {
+	ana, ben, cid := uuid.New(), uuid.New(), uuid.New()
+
+	// Steps are listed out of stage order on purpose
+	approval := newApproval(
+		step("finance", 2, RuleAny, ben),
+		step("manager", 1, RuleAny, ana),
+		step("legal", 1, RuleAny, cid),
+	)
+
+	if approval.Status != ApprovalPending {
+		t.Errorf("status = %s; want %s", approval.Status, ApprovalPending)
+	}
+	if approval.CurrentStage == nil || *approval.CurrentStage != 1 {
+		t.Errorf("current stage = %v; want 1", approval.CurrentStage)
+	}
+	want := []StepStatus{StepWaiting, StepPending, StepPending}
+	if got := statuses(approval); !slices.Equal(got, want) {
+		t.Errorf("step statuses = %v; want %v", got, want)
+	}
+}
+
+func TestApprovalDecideStageOrder(t *testing.T) {
+	ana, ben, cid := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	approval := newApproval(
+		step("manager", 1, RuleAny, ana),
+		step("legal", 1, RuleAny, ben),
+		step("finance", 2, RuleAny, cid),
+	)
+
+	// The second stage cannot be decided before the first one is approved
+	if approval.Decide(cid, DecisionApprove, nil, now) {
+		t.Fatal("decided a step of a later stage")
+	}
+
+	if !approval.Decide(ana, DecisionApprove, nil, now) {
+		t.Fatal("manager could not decide")
+	}
+	if *approval.CurrentStage != 1 {
+		t.Errorf("current stage = %d after one of two parallel steps; want 1", *approval.CurrentStage)
+	}
+
+	if !approval.Decide(ben, DecisionApprove, nil, now) {
+		t.Fatal("legal could not decide")
+	}
+	if approval.CurrentStage == nil || *approval.CurrentStage != 2 {
+		t.Fatalf("current stage = %v after the first stage; want 2", approval.CurrentStage)
+	}
+	want := []StepStatus{StepApproved, StepApproved, StepPending}
+	if got := statuses(approval); !slices.Equal(got, want) {
+		t.Errorf("step statuses = %v; want %v", got, want)
+	}
+
+	if !approval.Decide(cid, DecisionApprove, nil, now) {
+		t.Fatal("finance could not decide")
+	}
+	if approval.Status != ApprovalApproved {
+		t.Errorf("status = %s; want %s", approval.Status, ApprovalApproved)
+	}
+	if approval.CurrentStage != nil || approval.CompletedAt == nil {
+		t.Errorf("current stage = %v, completed at = %v; want none and set", approval.CurrentStage, approval.CompletedAt)
+	}
+}
+
+func TestApprovalDecideRules(t *testing.T) {
+	ana, ben := uuid.New(), uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		rule StepRule
+		want StepStatus // after the first of two assignees approves
+	}{
+		{"any", RuleAny, StepApproved},
+		{"all", RuleAll, StepPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approval := newApproval(step("manager", 1, tt.rule, ana, ben))
+
+			if !approval.Decide(ana, DecisionApprove, nil, now) {
+				t.Fatal("first assignee could not decide")
+			}
+			if got := approval.Steps[0].Status; got != tt.want {
+				t.Fatalf("step status = %s; want %s", got, tt.want)
+			}
+			if tt.want == StepApproved {
+				if approval.Status != ApprovalApproved {
+					t.Errorf("status = %s; want %s", approval.Status, ApprovalApproved)
+				}
+				return
+			}
+
+			if approval.Decide(ana, DecisionApprove, nil, now) {
+				t.Error("the same assignee decided twice")
+			}
+			if !approval.Decide(ben, DecisionApprove, nil, now) {
+				t.Fatal("second assignee could not decide")
+			}
+			if approval.Status != ApprovalApproved {
+				t.Errorf("status = %s; want %s", approval.Status, ApprovalApproved)
+			}
+		})
+	}
+}
+
+func TestApprovalDecideCloses(t *testing.T) {
+	ana, ben, cid := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	comment := "wrong supplier"
+
+	tests := []struct {
+		decision Decision
+		status   ApprovalStatus
+		step     StepStatus
+	}{
+		{DecisionReject, ApprovalRejected, StepRejected},
+		{DecisionRequestChanges, ApprovalChangesRequested, StepChangesRequested},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.decision), func(t *testing.T) {
+			approval := newApproval(
+				step("manager", 1, RuleAll, ana, ben),
+				step("legal", 1, RuleAny, cid),
+				step("finance", 2, RuleAny, cid),
+			)
+
+			// One assignee of an all step decides it for everyone
+			if !approval.Decide(ana, tt.decision, &comment, now) {
+				t.Fatal("could not decide")
+			}
+			if approval.Status != tt.status {
+				t.Errorf("status = %s; want %s", approval.Status, tt.status)
+			}
+			want := []StepStatus{tt.step, StepSkipped, StepSkipped}
+			if got := statuses(approval); !slices.Equal(got, want) {
+				t.Errorf("step statuses = %v; want %v", got, want)
+			}
+			decided := approval.Steps[0].Assignees[0]
+			if decided.Decision == nil || *decided.Decision != tt.decision || decided.Comment != &comment {
+				t.Errorf("assignee decision = %v, comment = %v; want %s with the comment", decided.Decision, decided.Comment, tt.decision)
+			}
+
+			if approval.Decide(ben, DecisionApprove, nil, now) {
+				t.Error("decided a closed approval")
+			}
+		})
+	}
+}
+
+func TestApprovalDecideNotAssigned(t *testing.T) {
+	approval := newApproval(step("manager", 1, RuleAny, uuid.New()))
+
+	if approval.Decide(uuid.New(), DecisionApprove, nil, time.Now()) {
+		t.Error("a member who is not assigned decided")
+	}
+	if approval.Status != ApprovalPending || approval.Steps[0].Status != StepPending {
+		t.Errorf("status = %s, step = %s; want unchanged", approval.Status, approval.Steps[0].Status)
+	}
+}
+
+func TestApprovalCancel(t *testing.T) {
+	ana := uuid.New()
+	approval := newApproval(step("manager", 1, RuleAny, ana), step("finance", 2, RuleAny, ana))
+
+	approval.Cancel(time.Now())
+
+	if approval.Status != ApprovalCancelled {
+		t.Errorf("status = %s; want %s", approval.Status, ApprovalCancelled)
+	}
+	want := []StepStatus{StepSkipped, StepSkipped}
+	if got := statuses(approval); !slices.Equal(got, want) {
+		t.Errorf("step statuses = %v; want %v", got, want)
+	}
+	if approval.Decide(ana, DecisionApprove, nil, time.Now()) {
+		t.Error("decided a cancelled approval")
+	}
+}
+
+func TestApprovalStatusInvoiceStatus(t *testing.T) {
+	workflow := typemodels.DefaultStatusWorkflow()
+
+	tests := []struct {
+		status ApprovalStatus
+		want   string
+	}{
+		{ApprovalApproved, ApprovedStatus},
+		{ApprovalRejected, VoidStatus},
+		{ApprovalChangesRequested, "draft"},
+		{ApprovalCancelled, "draft"},
+		{ApprovalPending, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.status.InvoiceStatus(workflow); got != tt.want {
+			t.Errorf("%s.InvoiceStatus() = %q; want %q", tt.status, got, tt.want)
+		}
+	}
+}
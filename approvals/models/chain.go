@@ -0,0 +1,166 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Invoice statuses of the default workflow an approval moves between: the
+// invoice is submitted when the approval starts and approved when the last
+// stage is approved
+const (
+	SubmittedStatus = "submitted"
+	ApprovedStatus  = "approved"
+	VoidStatus      = "void"
+)
+
+// StepRule tells how many assignees of a step have to approve it
+type StepRule string
+
+// Step rules
+const (
+	RuleAny StepRule = "any"
+	RuleAll StepRule = "all"
+)
+
+// Valid reports whether the rule is known
+func (r StepRule) Valid() bool {
+	return r == RuleAny || r == RuleAll
+}
+
+// Approver names who may decide a step: a single member, every member with
+// a role, or every member whose role grants a permission. Exactly one field
+// is set.
+type Approver struct {
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Role       string     `json:"role,omitempty"`
+	Permission string     `json:"permission,omitempty"`
+}
+
+// Step is a step of an approval chain. Steps sharing a stage run in
+// parallel; stages run in ascending order. A step with a minimum amount is
+// only part of approvals of invoices totalling at least that amount.
+type Step struct {
+	Name      string           `json:"name"`
+	Stage     int              `json:"stage"`
+	Rule      StepRule         `json:"rule"`
+	MinAmount *decimal.Decimal `json:"min_amount,omitempty"`
+	Approvers []Approver       `json:"approvers"`
+}
+
+// Steps are the steps of an approval chain, stored as JSONB
+type Steps []Step
+
+// Validate checks the steps of a chain
+func (s Steps) Validate() error {
+	if len(s) == 0 {
+		return fmt.Errorf("steps must not be empty")
+	}
+
+	names := make(map[string]bool, len(s))
+	for i, step := range s {
+		if step.Name == "" {
+			return fmt.Errorf("step %d: name is required", i)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step %q is declared more than once", step.Name)
+		}
+		names[step.Name] = true
+
+		if step.Stage < 1 {
+			return fmt.Errorf("step %q: stage must be at least 1", step.Name)
+		}
+		if !step.Rule.Valid() {
+			return fmt.Errorf("step %q: rule must be any or all", step.Name)
+		}
+		if step.MinAmount != nil && step.MinAmount.IsNegative() {
+			return fmt.Errorf("step %q: min_amount must not be negative", step.Name)
+		}
+		if len(step.Approvers) == 0 {
+			return fmt.Errorf("step %q: approvers must not be empty", step.Name)
+		}
+		for _, approver := range step.Approvers {
+			set := 0
+			if approver.UserID != nil {
+				set++
+			}
+			if approver.Role != "" {
+				set++
+			}
+			if approver.Permission != "" {
+				set++
+			}
+			if set != 1 {
+				return fmt.Errorf("step %q: each approver needs exactly one of user_id, role or permission", step.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ForAmount returns the steps an invoice of the given amount goes through,
+// ordered by stage
+func (s Steps) ForAmount(amount decimal.Decimal) Steps {
+	var steps Steps
+	for _, step := range s {
+		if step.MinAmount == nil || amount.GreaterThanOrEqual(*step.MinAmount) {
+			steps = append(steps, step)
+		}
+	}
+
+	slices.SortStableFunc(steps, func(a, b Step) int {
+		return a.Stage - b.Stage
+	})
+	return steps
+}
+
+// Value implements the driver.Valuer interface for database storage
+func (s Steps) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (s *Steps) Scan(value any) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("cannot scan %T into Steps", value)
+	}
+}
+
+// Chain is the sequence of approval steps invoices of a project and/or
+// invoice type go through. A chain without project and invoice type covers
+// every invoice of the organization; the most specific active chain applies.
+type Chain struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organization_id"`
+	Name           string     `db:"name" json:"name"`
+	Description    *string    `db:"description" json:"description,omitempty"`
+	ProjectID      *uuid.UUID `db:"project_id" json:"project_id"`
+	InvoiceTypeID  *uuid.UUID `db:"invoice_type_id" json:"invoice_type_id"`
+	Steps          Steps      `db:"steps" json:"steps"`
+	IsActive       bool       `db:"is_active" json:"is_active"`
+	CreatedBy      *uuid.UUID `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the Chain model
+func (c Chain) TableName() string {
+	return "approval_chains"
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestStepsValidate(t *testing.T) {
+	user := uuid.New()
+	amount := func(s string) *decimal.Decimal {
+		d := decimal.RequireFromString(s)
+		return &d
+	}
+	valid := func(change func(*Step)) Steps {
+		s := Step{Name: "manager", Stage: 1, Rule: RuleAny, Approvers: []Approver{{Role: "manager"}}}
+		if change != nil {
+			change(&s)
+		}
+		return Steps{s}
+	}
+
+	tests := []struct {
+		name  string
+		steps Steps
+		want  string // part of the error, empty when valid
+	}{
+		{"valid", valid(nil), ""},
+		{"threshold", valid(func(s *Step) { s.MinAmount = amount("1000") }), ""},
+		{"user approver", valid(func(s *Step) { s.Approvers = []Approver{{UserID: &user}} }), ""},
+		{"empty", Steps{}, "steps must not be empty"},
+		{"no name", valid(func(s *Step) { s.Name = "" }), "name is required"},
+		{"duplicate name", append(valid(nil), valid(nil)...), "declared more than once"},
+		{"stage zero", valid(func(s *Step) { s.Stage = 0 }), "stage must be at least 1"},
+		{"unknown rule", valid(func(s *Step) { s.Rule = "most" }), "rule must be any or all"},
+		{"negative threshold", valid(func(s *Step) { s.MinAmount = amount("-1") }), "min_amount must not be negative"},
+		{"no approvers", valid(func(s *Step) { s.Approvers = nil }), "approvers must not be empty"},
+		{"approver with nothing", valid(func(s *Step) { s.Approvers = []Approver{{}} }), "exactly one of"},
+		{"approver with two", valid(func(s *Step) { s.Approvers = []Approver{{Role: "manager", Permission: "invoices:approve"}} }), "exactly one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.steps.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v; want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepsForAmount(t *testing.T) {
+	threshold := decimal.RequireFromString("1000")
+	steps := Steps{
+		{Name: "cfo", Stage: 3, MinAmount: &threshold},
+		{Name: "finance", Stage: 2},
+		{Name: "manager", Stage: 1},
+		{Name: "legal", Stage: 1},
+	}
+
+	tests := []struct {
+		amount string
+		want   []string
+	}{
+		{"999.99", []string{"manager", "legal", "finance"}},
+		{"1000", []string{"manager", "legal", "finance", "cfo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			var names []string
+			for _, s := range steps.ForAmount(decimal.RequireFromString(tt.amount)) {
+				names = append(names, s.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("steps = %v; want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals"
+	"github.com/Abraxas-365/fuckturamelo/approvals/dto"
+	"github.com/Abraxas-365/fuckturamelo/approvals/models"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	invoicespg "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// StartApproval stores a new approval with its steps and assignees. The
+// invoice is locked and must still be at the version the approval was built
+// from; the optional transition submits it in the same transaction.
+func (r *approvalRepository) StartApproval(ctx context.Context, approval *models.Approval, invoice *invoicemodels.Invoice, transition *invoicemodels.StatusTransition) (*models.Approval, *invoicemodels.Invoice, error) {
+	approvalError := func(err error) error {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, approvalError(err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.GetContext(ctx, &version,
+		`SELECT version FROM invoices WHERE id = $1 AND is_deleted = false FOR UPDATE`, invoice.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+				WithDetail("invoice_id", invoice.ID.String())
+		}
+		return nil, nil, approvalError(err)
+	}
+	if version != invoice.Version {
+		return nil, nil, approvals.ApprovalsErrors.New(approvals.ErrInvoiceChanged).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithDetail("expected_version", invoice.Version).
+			WithDetail("current_version", version)
+	}
+
+	var submitted *invoicemodels.Invoice
+	if transition != nil {
+		if submitted, err = invoicespg.ApplyTransition(ctx, tx, invoice, transition); err != nil {
+			return nil, nil, err
+		}
+		approval.InvoiceVersion = submitted.Version
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO invoice_approvals
+			(id, invoice_id, organization_id, chain_id, chain_name, status, current_stage, invoice_version,
+			 amount, currency_code, requested_by, requested_at, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		approval.ID, approval.InvoiceID, approval.OrganizationID, approval.ChainID, approval.ChainName,
+		approval.Status, approval.CurrentStage, approval.InvoiceVersion, approval.Amount,
+		approval.CurrencyCode, approval.RequestedBy, approval.RequestedAt, approval.Comment)
+	if err != nil {
+		if strings.Contains(err.Error(), "invoice_approvals_pending_unique") {
+			return nil, nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalPending).
+				WithDetail("invoice_id", invoice.ID.String()).
+				WithCause(err)
+		}
+		return nil, nil, approvalError(err)
+	}
+
+	for _, step := range approval.Steps {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO invoice_approval_steps (id, approval_id, position, stage, name, rule, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			step.ID, approval.ID, step.Position, step.Stage, step.Name, step.Rule, step.Status)
+		if err != nil {
+			return nil, nil, approvalError(err)
+		}
+
+		for _, assignee := range step.Assignees {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO invoice_approval_assignees (id, step_id, user_id)
+				VALUES ($1, $2, $3)`,
+				assignee.ID, step.ID, assignee.UserID)
+			if err != nil {
+				return nil, nil, approvalError(err)
+			}
+		}
+	}
+
+	result, err := selectApproval(ctx, tx, approval.ID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, approvalError(err)
+	}
+
+	return result, submitted, nil
+}
+
+// GetApproval retrieves an approval with its steps and assignees
+func (r *approvalRepository) GetApproval(ctx context.Context, id uuid.UUID) (*models.Approval, error) {
+	return selectApproval(ctx, r.db, id, false)
+}
+
+// GetPendingApproval returns the approval in progress for an invoice, or nil
+func (r *approvalRepository) GetPendingApproval(ctx context.Context, invoiceID uuid.UUID) (*models.Approval, error) {
+	var id uuid.UUID
+	err := r.db.GetContext(ctx, &id,
+		`SELECT id FROM invoice_approvals WHERE invoice_id = $1 AND status = 'pending'`, invoiceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return selectApproval(ctx, r.db, id, false)
+}
+
+// ListApprovals lists the approvals of an invoice, oldest first
+func (r *approvalRepository) ListApprovals(ctx context.Context, invoiceID uuid.UUID) ([]*models.Approval, error) {
+	var ids []uuid.UUID
+	err := r.db.SelectContext(ctx, &ids,
+		`SELECT id FROM invoice_approvals WHERE invoice_id = $1 ORDER BY requested_at, id`, invoiceID)
+	if err != nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	result := make([]*models.Approval, 0, len(ids))
+	for _, id := range ids {
+		approval, err := selectApproval(ctx, r.db, id, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, approval)
+	}
+
+	return result, nil
+}
+
+// ListPending lists the steps of the current stages waiting for a user's
+// decision
+func (r *approvalRepository) ListPending(ctx context.Context, req *dto.PendingListRequest) ([]models.PendingApproval, error) {
+	query := `
+		SELECT a.id AS approval_id, a.invoice_id, a.organization_id, i.invoice_number,
+			a.amount, a.currency_code, a.chain_name, s.id AS step_id, s.name AS step_name, s.stage,
+			a.requested_by, a.requested_at
+		FROM invoice_approval_assignees x
+		JOIN invoice_approval_steps s ON s.id = x.step_id AND s.status = 'pending'
+		JOIN invoice_approvals a ON a.id = s.approval_id AND a.status = 'pending'
+		JOIN invoices i ON i.id = a.invoice_id AND i.is_deleted = false
+		WHERE x.user_id = $1 AND x.decision IS NULL
+			AND ($2::uuid IS NULL OR a.organization_id = $2)
+		ORDER BY a.requested_at, a.id, s.position`
+
+	pending := []models.PendingApproval{}
+	if err := r.db.SelectContext(ctx, &pending, query, req.UserID, req.OrganizationID); err != nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("user_id", req.UserID.String()).
+			WithCause(err)
+	}
+
+	return pending, nil
+}
+
+// Decide records a user's decision on a pending approval. When it decides
+// the approval, the invoice moves to the matching status of its workflow in
+// the same transaction.
+func (r *approvalRepository) Decide(ctx context.Context, id, userID uuid.UUID, decision models.Decision, comment *string, workflow typemodels.StatusWorkflow) (*models.Approval, *invoicemodels.Invoice, error) {
+	return r.settle(ctx, id, userID, comment, workflow, func(approval *models.Approval, now time.Time) error {
+		if !approval.Decide(userID, decision, comment, now) {
+			return approvals.ApprovalsErrors.New(approvals.ErrNotApprover).
+				WithDetail("approval_id", id.String()).
+				WithDetail("user_id", userID.String())
+		}
+		return nil
+	})
+}
+
+// Cancel withdraws a pending approval on behalf of its requester and returns
+// the invoice to the initial status of its workflow
+func (r *approvalRepository) Cancel(ctx context.Context, id, cancelledBy uuid.UUID, comment *string, workflow typemodels.StatusWorkflow) (*models.Approval, *invoicemodels.Invoice, error) {
+	return r.settle(ctx, id, cancelledBy, comment, workflow, func(approval *models.Approval, now time.Time) error {
+		if approval.RequestedBy != cancelledBy {
+			return approvals.ApprovalsErrors.New(approvals.ErrNotApprover).
+				WithDetail("approval_id", id.String()).
+				WithDetail("user_id", cancelledBy.String()).
+				WithDetail("reason", "only the requester can cancel an approval")
+		}
+		approval.Cancel(now)
+		return nil
+	})
+}
+
+// settle locks a pending approval, applies change to it and stores the
+// result. Once the approval is decided the invoice is moved to the status
+// the outcome maps to; outcomes other than approval leave the invoice where
+// it is when its workflow does not allow the move.
+func (r *approvalRepository) settle(ctx context.Context, id, userID uuid.UUID, comment *string, workflow typemodels.StatusWorkflow, change func(*models.Approval, time.Time) error) (*models.Approval, *invoicemodels.Invoice, error) {
+	approvalError := func(err error) error {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalFailed).
+			WithDetail("approval_id", id.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, approvalError(err)
+	}
+	defer tx.Rollback()
+
+	approval, err := selectApproval(ctx, tx, id, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if approval.Status != models.ApprovalPending {
+		return nil, nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalClosed).
+			WithDetail("approval_id", id.String()).
+			WithDetail("status", string(approval.Status))
+	}
+
+	now := time.Now()
+	if err := change(approval, now); err != nil {
+		return nil, nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE invoice_approvals SET status = $2, current_stage = $3, completed_at = $4
+		WHERE id = $1`,
+		approval.ID, approval.Status, approval.CurrentStage, approval.CompletedAt)
+	if err != nil {
+		return nil, nil, approvalError(err)
+	}
+
+	for _, step := range approval.Steps {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE invoice_approval_steps SET status = $2, completed_at = $3 WHERE id = $1`,
+			step.ID, step.Status, step.CompletedAt)
+		if err != nil {
+			return nil, nil, approvalError(err)
+		}
+
+		for _, assignee := range step.Assignees {
+			if assignee.Decision == nil {
+				continue
+			}
+			_, err = tx.ExecContext(ctx, `
+				UPDATE invoice_approval_assignees SET decision = $2, comment = $3, decided_at = $4
+				WHERE id = $1 AND decision IS NULL`,
+				assignee.ID, assignee.Decision, assignee.Comment, assignee.DecidedAt)
+			if err != nil {
+				return nil, nil, approvalError(err)
+			}
+		}
+	}
+
+	var invoice *invoicemodels.Invoice
+	if approval.Status != models.ApprovalPending {
+		if invoice, err = moveInvoice(ctx, tx, approval, userID, comment, workflow, now); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, approvalError(err)
+	}
+
+	return approval, invoice, nil
+}
+
+// moveInvoice transitions the invoice of a decided approval. It returns nil
+// when the invoice keeps its status.
+func moveInvoice(ctx context.Context, tx *sqlx.Tx, approval *models.Approval, userID uuid.UUID, comment *string, workflow typemodels.StatusWorkflow, now time.Time) (*invoicemodels.Invoice, error) {
+	var invoice invoicemodels.Invoice
+	err := tx.GetContext(ctx, &invoice,
+		`SELECT * FROM invoices WHERE id = $1 AND is_deleted = false FOR UPDATE`, approval.InvoiceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+				WithDetail("invoice_id", approval.InvoiceID.String())
+		}
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalFailed).
+			WithDetail("approval_id", approval.ID.String()).
+			WithCause(err)
+	}
+
+	from := workflow.InitialStatus
+	if invoice.Status != nil {
+		from = *invoice.Status
+	}
+
+	to := approval.Status.InvoiceStatus(workflow)
+	if to == "" || to == from {
+		return nil, nil
+	}
+	if !workflow.CanTransition(from, to) {
+		if approval.Status != models.ApprovalApproved {
+			return nil, nil
+		}
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvalidStatusTransition).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithDetail("from_status", from).
+			WithDetail("to_status", to).
+			WithDetail("allowed_transitions", workflow.AllowedTransitions(from))
+	}
+
+	return invoicespg.ApplyTransition(ctx, tx, &invoice, &invoicemodels.StatusTransition{
+		InvoiceID:      invoice.ID,
+		OrganizationID: invoice.OrganizationID,
+		FromStatus:     invoice.Status,
+		ToStatus:       to,
+		Comment:        comment,
+		TransitionedBy: userID,
+		TransitionedAt: now,
+	})
+}
+
+// selectApproval reads an approval with its steps and assignees, locking the
+// approval row when forUpdate is set
+func selectApproval(ctx context.Context, q sqlx.QueryerContext, id uuid.UUID, forUpdate bool) (*models.Approval, error) {
+	query := `SELECT * FROM invoice_approvals WHERE id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var approval models.Approval
+	if err := sqlx.GetContext(ctx, q, &approval, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	listError := func(err error) error {
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	approval.Steps = []models.ApprovalStep{}
+	if err := sqlx.SelectContext(ctx, q, &approval.Steps,
+		`SELECT * FROM invoice_approval_steps WHERE approval_id = $1 ORDER BY position`, id); err != nil {
+		return nil, listError(err)
+	}
+
+	assignees := []models.Assignee{}
+	err := sqlx.SelectContext(ctx, q, &assignees, `
+		SELECT x.* FROM invoice_approval_assignees x
+		JOIN invoice_approval_steps s ON s.id = x.step_id
+		WHERE s.approval_id = $1
+		ORDER BY x.decided_at NULLS LAST, x.user_id`, id)
+	if err != nil {
+		return nil, listError(err)
+	}
+
+	byStep := make(map[uuid.UUID]int, len(approval.Steps))
+	for i := range approval.Steps {
+		approval.Steps[i].Assignees = []models.Assignee{}
+		byStep[approval.Steps[i].ID] = i
+	}
+	for _, assignee := range assignees {
+		if i, ok := byStep[assignee.StepID]; ok {
+			approval.Steps[i].Assignees = append(approval.Steps[i].Assignees, assignee)
+		}
+	}
+
+	return &approval, nil
+}
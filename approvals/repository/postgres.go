@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals"
+	"github.com/Abraxas-365/fuckturamelo/approvals/models"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// approvalRepository implements ApprovalRepository using sqlx
+type approvalRepository struct {
+	db *sqlx.DB
+}
+
+// NewApprovalRepository creates a new approval repository
+func NewApprovalRepository(db *sqlx.DB) ApprovalRepository {
+	return &approvalRepository{
+		db: db,
+	}
+}
+
+// CreateChain saves an approval chain
+func (r *approvalRepository) CreateChain(ctx context.Context, chain *models.Chain) (*models.Chain, error) {
+	var created models.Chain
+	err := r.db.GetContext(ctx, &created, `
+		INSERT INTO approval_chains
+			(id, organization_id, name, description, project_id, invoice_type_id, steps, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING *`,
+		chain.ID, chain.OrganizationID, chain.Name, chain.Description, chain.ProjectID,
+		chain.InvoiceTypeID, chain.Steps, chain.IsActive, chain.CreatedBy)
+	if err != nil {
+		return nil, chainError(err, chain)
+	}
+
+	return &created, nil
+}
+
+// GetChain retrieves an approval chain by ID
+func (r *approvalRepository) GetChain(ctx context.Context, id uuid.UUID) (*models.Chain, error) {
+	var chain models.Chain
+	if err := r.db.GetContext(ctx, &chain, `SELECT * FROM approval_chains WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, approvals.ApprovalsErrors.New(approvals.ErrChainNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	return &chain, nil
+}
+
+// ListChains lists the chains of an organization by name
+func (r *approvalRepository) ListChains(ctx context.Context, orgID uuid.UUID) ([]models.Chain, error) {
+	chains := []models.Chain{}
+	err := r.db.SelectContext(ctx, &chains,
+		`SELECT * FROM approval_chains WHERE organization_id = $1 ORDER BY name`, orgID)
+	if err != nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return chains, nil
+}
+
+// UpdateChain stores the name, description, steps and activity of a chain
+func (r *approvalRepository) UpdateChain(ctx context.Context, chain *models.Chain) (*models.Chain, error) {
+	var updated models.Chain
+	err := r.db.GetContext(ctx, &updated, `
+		UPDATE approval_chains
+		SET name = $2, description = $3, steps = $4, is_active = $5
+		WHERE id = $1
+		RETURNING *`,
+		chain.ID, chain.Name, chain.Description, chain.Steps, chain.IsActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, approvals.ApprovalsErrors.New(approvals.ErrChainNotFound).
+				WithDetail("id", chain.ID.String())
+		}
+		return nil, chainError(err, chain)
+	}
+
+	return &updated, nil
+}
+
+// DeleteChain removes a chain. Approvals that went through it keep the
+// chain name and steps they started with.
+func (r *approvalRepository) DeleteChain(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM approval_chains WHERE id = $1`, id)
+	if err != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrChainFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return approvals.ApprovalsErrors.New(approvals.ErrChainFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+	if affected == 0 {
+		return approvals.ApprovalsErrors.New(approvals.ErrChainNotFound).
+			WithDetail("id", id.String())
+	}
+
+	return nil
+}
+
+// FindChain returns the active chain that applies to an invoice, or nil.
+// A chain of the invoice's project wins over one of its invoice type, and
+// both win over an organization-wide chain.
+func (r *approvalRepository) FindChain(ctx context.Context, invoice *invoicemodels.Invoice) (*models.Chain, error) {
+	query := `
+		SELECT * FROM approval_chains
+		WHERE organization_id = $1 AND is_active
+			AND (project_id IS NULL OR project_id = $2)
+			AND (invoice_type_id IS NULL OR invoice_type_id = $3)
+		ORDER BY (project_id IS NOT NULL) DESC, (invoice_type_id IS NOT NULL) DESC
+		LIMIT 1`
+
+	var chain models.Chain
+	err := r.db.GetContext(ctx, &chain, query, invoice.OrganizationID, invoice.ProjectID, invoice.InvoiceTypeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+
+	return &chain, nil
+}
+
+// ResolveApprovers returns the active members of an organization named by
+// the approvers, directly or through their role and its permissions
+func (r *approvalRepository) ResolveApprovers(ctx context.Context, orgID uuid.UUID, approvers []models.Approver) ([]uuid.UUID, error) {
+	var userIDs, roles, permissions []string
+	for _, approver := range approvers {
+		switch {
+		case approver.UserID != nil:
+			userIDs = append(userIDs, approver.UserID.String())
+		case approver.Role != "":
+			roles = append(roles, approver.Role)
+		case approver.Permission != "":
+			permissions = append(permissions, approver.Permission)
+		}
+	}
+
+	// Memberships still use text identifiers
+	query := `
+		SELECT DISTINCT user_id FROM organization_memberships
+		WHERE organization_id = $1 AND is_active = true
+			AND (user_id = ANY($2) OR role_name = ANY($3) OR role_permissions && $4::text[])
+		ORDER BY user_id`
+
+	var members []string
+	err := r.db.SelectContext(ctx, &members, query,
+		orgID.String(), pq.Array(userIDs), pq.Array(roles), pq.Array(permissions))
+	if err != nil {
+		return nil, approvals.ApprovalsErrors.New(approvals.ErrApprovalsListFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	result := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
+// chainError maps constraint violations of approval_chains to domain errors
+func chainError(err error, chain *models.Chain) error {
+	switch {
+	case strings.Contains(err.Error(), "approval_chains_name_unique"):
+		return approvals.ApprovalsErrors.New(approvals.ErrChainNameExists).
+			WithDetail("name", chain.Name).
+			WithCause(err)
+	case strings.Contains(err.Error(), "approval_chains_scope_unique"):
+		return approvals.ApprovalsErrors.New(approvals.ErrChainScopeExists).
+			WithDetail("organization_id", chain.OrganizationID.String()).
+			WithCause(err)
+	case strings.Contains(err.Error(), "violates foreign key constraint"):
+		return approvals.ApprovalsErrors.New(approvals.ErrApprovalsInvalidReference).
+			WithCause(err)
+	}
+	return approvals.ApprovalsErrors.New(approvals.ErrChainFailed).
+		WithDetail("name", chain.Name).
+		WithCause(err)
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals/dto"
+	"github.com/Abraxas-365/fuckturamelo/approvals/models"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// ApprovalRepository defines the interface for approval chain and invoice
+// approval repository operations
+type ApprovalRepository interface {
+	// Chain operations
+	CreateChain(ctx context.Context, chain *models.Chain) (*models.Chain, error)
+	GetChain(ctx context.Context, id uuid.UUID) (*models.Chain, error)
+	ListChains(ctx context.Context, orgID uuid.UUID) ([]models.Chain, error)
+	UpdateChain(ctx context.Context, chain *models.Chain) (*models.Chain, error)
+	DeleteChain(ctx context.Context, id uuid.UUID) error
+	FindChain(ctx context.Context, invoice *invoicemodels.Invoice) (*models.Chain, error)
+	ResolveApprovers(ctx context.Context, orgID uuid.UUID, approvers []models.Approver) ([]uuid.UUID, error)
+
+	// Approval operations
+	StartApproval(ctx context.Context, approval *models.Approval, invoice *invoicemodels.Invoice, transition *invoicemodels.StatusTransition) (*models.Approval, *invoicemodels.Invoice, error)
+	GetApproval(ctx context.Context, id uuid.UUID) (*models.Approval, error)
+	GetPendingApproval(ctx context.Context, invoiceID uuid.UUID) (*models.Approval, error)
+	ListApprovals(ctx context.Context, invoiceID uuid.UUID) ([]*models.Approval, error)
+	ListPending(ctx context.Context, req *dto.PendingListRequest) ([]models.PendingApproval, error)
+	Decide(ctx context.Context, id, userID uuid.UUID, decision models.Decision, comment *string, workflow typemodels.StatusWorkflow) (*models.Approval, *invoicemodels.Invoice, error)
+	Cancel(ctx context.Context, id, cancelledBy uuid.UUID, comment *string, workflow typemodels.StatusWorkflow) (*models.Approval, *invoicemodels.Invoice, error)
+}
@@ -0,0 +1,303 @@
+package attachmentsapi
+
+import (
+	"io"
+	"mime"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/attachments"
+	"github.com/Abraxas-365/fuckturamelo/attachments/attachmentsrv"
+	"github.com/Abraxas-365/fuckturamelo/attachments/dto"
+	postgres "github.com/Abraxas-365/fuckturamelo/attachments/repository"
+	invoicespg "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	"github.com/Abraxas-365/fuckturamelo/storage"
+)
+
+// AttachmentsAPI contains the complete API setup for the attachments domain
+type AttachmentsAPI struct {
+	service attachmentsrv.AttachmentService
+	repo    postgres.AttachmentRepository
+	store   storage.Store
+}
+
+// Config contains configuration for the attachments API
+type Config struct {
+	DB *sqlx.DB
+
+	// Store keeps the attachment contents
+	Store storage.Store
+
+	// Options tunes size limits and download links (defaults in
+	// attachmentsrv)
+	Options attachmentsrv.Options
+}
+
+// New creates a new AttachmentsAPI instance
+func New(config Config) (*AttachmentsAPI, error) {
+	if config.DB == nil {
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+	if config.Store == nil {
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("error", "File storage is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewAttachmentRepository(config.DB)
+	svc := attachmentsrv.NewAttachmentService(repo, invoicespg.NewInvoiceRepository(config.DB), config.Store, config.Options)
+
+	return &AttachmentsAPI{
+		service: svc,
+		repo:    repo,
+		store:   config.Store,
+	}, nil
+}
+
+// SetupRoutes registers the attachment routes with the given Fiber router
+// group. Files of the local store are served from /files with the signature
+// of their download URL.
+func (api *AttachmentsAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Signed download routes
+	router.Get("/files/*", api.serveFile)
+}
+
+// SetupInvoiceRoutes registers the attachment routes of an invoice with the
+// invoices router group
+func (api *AttachmentsAPI) SetupInvoiceRoutes(router fiber.Router) {
+	router.Post("/:id/attachments", api.uploadAttachment)
+	router.Get("/:id/attachments", api.listAttachments)
+	router.Get("/:id/attachments/:attachmentId", api.getAttachment)
+	router.Get("/:id/attachments/:attachmentId/download", api.downloadAttachment)
+	router.Delete("/:id/attachments/:attachmentId", api.deleteAttachment)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *AttachmentsAPI) GetService() attachmentsrv.AttachmentService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *AttachmentsAPI) GetRepository() postgres.AttachmentRepository {
+	return api.repo
+}
+
+// Attachment handlers
+
+// uploadAttachment handles POST /invoices/:id/attachments as a multipart
+// upload with a "file" part and an optional uploaded_by field
+func (api *AttachmentsAPI) uploadAttachment(c *fiber.Ctx) error {
+	invoiceID, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var uploadedBy *uuid.UUID
+	if value := c.FormValue("uploaded_by"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+				WithDetail("error", "Invalid uploaded_by format").
+				WithCause(err)
+		}
+		uploadedBy = &id
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("error", "Missing attachment in multipart field: file").
+			WithCause(err)
+	}
+	if header.Size > api.service.MaxSize() {
+		return attachments.AttachmentsErrors.New(attachments.ErrFileTooLarge).
+			WithDetail("size_bytes", header.Size).
+			WithDetail("max_bytes", api.service.MaxSize())
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("error", "Attachment could not be read").
+			WithCause(err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, api.service.MaxSize()+1))
+	if err != nil {
+		return attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("error", "Attachment could not be read").
+			WithCause(err)
+	}
+
+	result, err := api.service.Upload(c.Context(), &dto.UploadRequest{
+		InvoiceID:   invoiceID,
+		FileName:    header.Filename,
+		ContentType: header.Header.Get(fiber.HeaderContentType),
+		Data:        data,
+		UploadedBy:  uploadedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listAttachments handles GET /invoices/:id/attachments
+func (api *AttachmentsAPI) listAttachments(c *fiber.Ctx) error {
+	invoiceID, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListAttachments(c.Context(), invoiceID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getAttachment handles GET /invoices/:id/attachments/:attachmentId
+func (api *AttachmentsAPI) getAttachment(c *fiber.Ctx) error {
+	invoiceID, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+	id, err := api.parseUUIDParam(c, "attachmentId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetAttachment(c.Context(), invoiceID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// downloadAttachment handles GET /invoices/:id/attachments/:attachmentId/download
+// by redirecting to a fresh signed URL
+func (api *AttachmentsAPI) downloadAttachment(c *fiber.Ctx) error {
+	invoiceID, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+	id, err := api.parseUUIDParam(c, "attachmentId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetAttachment(c.Context(), invoiceID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.Redirect(result.DownloadURL, fiber.StatusFound)
+}
+
+// deleteAttachment handles DELETE /invoices/:id/attachments/:attachmentId
+func (api *AttachmentsAPI) deleteAttachment(c *fiber.Ctx) error {
+	invoiceID, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+	id, err := api.parseUUIDParam(c, "attachmentId")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteAttachment(c.Context(), invoiceID, id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// serveFile handles GET /attachments/files/* for URLs signed by the local
+// store
+func (api *AttachmentsAPI) serveFile(c *fiber.Ctx) error {
+	local, ok := api.store.(*storage.LocalStore)
+	if !ok {
+		return storage.StorageErrors.New(storage.ErrObjectNotFound)
+	}
+
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return storage.StorageErrors.New(storage.ErrInvalidKey).
+			WithCause(err)
+	}
+
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return storage.StorageErrors.New(storage.ErrInvalidSignature).
+			WithCause(err)
+	}
+
+	download, err := local.Verify(key, query, time.Now())
+	if err != nil {
+		return err
+	}
+
+	file, err := local.Get(c.Context(), key)
+	if err != nil {
+		return err
+	}
+
+	if download.ContentType != "" {
+		c.Set(fiber.HeaderContentType, download.ContentType)
+	}
+	if download.FileName != "" {
+		c.Set(fiber.HeaderContentDisposition,
+			mime.FormatMediaType("attachment", map[string]string{"filename": download.FileName}))
+	}
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	return c.Status(fiber.StatusOK).SendStream(file)
+}
+
+// healthCheck provides a health check endpoint
+func (api *AttachmentsAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "attachments",
+	})
+}
+
+// Helper methods
+
+func (api *AttachmentsAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
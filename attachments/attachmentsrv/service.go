@@ -0,0 +1,340 @@
+package attachmentsrv
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/attachments"
+	"github.com/Abraxas-365/fuckturamelo/attachments/dto"
+	"github.com/Abraxas-365/fuckturamelo/attachments/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/attachments/repository"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/storage"
+)
+
+// Defaults used when the service is created with zero options
+const (
+	DefaultMaxSize   = 20 << 20
+	DefaultURLExpiry = 5 * time.Minute
+)
+
+// Options tunes attachment validation and download links
+type Options struct {
+	// MaxSize bounds the size of an attachment in bytes
+	MaxSize int64
+
+	// URLExpiry is how long signed download URLs stay valid
+	URLExpiry time.Duration
+}
+
+// AttachmentService defines the interface for invoice attachment business
+// logic
+type AttachmentService interface {
+	Upload(ctx context.Context, req *dto.UploadRequest) (*dto.AttachmentResponse, error)
+	GetAttachment(ctx context.Context, invoiceID, id uuid.UUID) (*dto.AttachmentResponse, error)
+	ListAttachments(ctx context.Context, invoiceID uuid.UUID) (*dto.AttachmentListResponse, error)
+	DeleteAttachment(ctx context.Context, invoiceID, id uuid.UUID) error
+	DeleteInvoiceAttachments(ctx context.Context, invoiceID uuid.UUID) error
+	ReleaseObjects(ctx context.Context, keys []string) error
+	MaxSize() int64
+}
+
+// Invoices reads the invoices files are attached to (implemented by
+// invoices/repository.InvoiceRepository)
+type Invoices interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error)
+}
+
+// attachmentService implements AttachmentService
+type attachmentService struct {
+	repo     postgres.AttachmentRepository
+	invoices Invoices
+	store    storage.Store
+	options  Options
+}
+
+// NewAttachmentService creates a new attachment service. Zero options use
+// DefaultMaxSize and DefaultURLExpiry.
+func NewAttachmentService(repo postgres.AttachmentRepository, invoices Invoices, store storage.Store, options Options) AttachmentService {
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultMaxSize
+	}
+	if options.URLExpiry <= 0 {
+		options.URLExpiry = DefaultURLExpiry
+	}
+
+	return &attachmentService{
+		repo:     repo,
+		invoices: invoices,
+		store:    store,
+		options:  options,
+	}
+}
+
+// Upload attaches a file to an invoice. The content type is detected from
+// the content and must be one of models.AllowedContentTypes; content the
+// organization already stored is not uploaded again.
+func (s *attachmentService) Upload(ctx context.Context, req *dto.UploadRequest) (*dto.AttachmentResponse, error) {
+	fileName := cleanFileName(req.FileName)
+	if fileName == "" {
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("field", "file_name").
+			WithDetail("reason", "required")
+	}
+	if len(req.Data) == 0 {
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+			WithDetail("field", "file").
+			WithDetail("reason", "empty")
+	}
+	if int64(len(req.Data)) > s.options.MaxSize {
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrFileTooLarge).
+			WithDetail("size_bytes", len(req.Data)).
+			WithDetail("max_bytes", s.options.MaxSize)
+	}
+
+	contentType, err := detectContentType(req.Data, fileName, req.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.getInvoice(ctx, req.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(req.Data)
+	attachment := &models.Attachment{
+		ID:             uuid.New(),
+		InvoiceID:      invoice.ID,
+		OrganizationID: invoice.OrganizationID,
+		FileName:       fileName,
+		ContentType:    contentType,
+		SizeBytes:      int64(len(req.Data)),
+		SHA256:         hex.EncodeToString(sum[:]),
+		UploadedBy:     req.UploadedBy,
+		CreatedAt:      time.Now(),
+	}
+	attachment.StorageKey = models.StorageKey(invoice.OrganizationID, attachment.SHA256)
+
+	deduplicated := false
+	created, err := s.repo.Create(ctx, attachment, func(ctx context.Context) error {
+		exists, err := s.store.Exists(ctx, attachment.StorageKey)
+		if err != nil {
+			return err
+		}
+		if exists {
+			deduplicated = true
+			return nil
+		}
+		return s.store.Put(ctx, attachment.StorageKey, bytes.NewReader(req.Data), attachment.SizeBytes, contentType)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.attachmentResponse(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+	response.Deduplicated = deduplicated
+
+	return response, nil
+}
+
+// GetAttachment returns an attachment of an invoice with a signed download
+// URL
+func (s *attachmentService) GetAttachment(ctx context.Context, invoiceID, id uuid.UUID) (*dto.AttachmentResponse, error) {
+	attachment, err := s.getAttachment(ctx, invoiceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.attachmentResponse(ctx, attachment)
+}
+
+// ListAttachments lists the attachments of an invoice
+func (s *attachmentService) ListAttachments(ctx context.Context, invoiceID uuid.UUID) (*dto.AttachmentListResponse, error) {
+	if _, err := s.getInvoice(ctx, invoiceID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.ListByInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AttachmentListResponse{InvoiceID: invoiceID, Attachments: list}, nil
+}
+
+// DeleteAttachment removes an attachment and, with its last reference, the
+// stored content
+func (s *attachmentService) DeleteAttachment(ctx context.Context, invoiceID, id uuid.UUID) error {
+	if _, err := s.getAttachment(ctx, invoiceID, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id, s.store.Delete)
+}
+
+// DeleteInvoiceAttachments removes every attachment of an invoice, deleted
+// or not, and the stored content they held the last reference to. Invoices
+// are permanently deleted only after their attachments.
+func (s *attachmentService) DeleteInvoiceAttachments(ctx context.Context, invoiceID uuid.UUID) error {
+	list, err := s.repo.ListByInvoice(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range list {
+		err := s.repo.Delete(ctx, attachment.ID, s.store.Delete)
+		if err != nil && !attachments.IsAttachmentNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleaseObjects removes the stored objects no attachment references
+// anymore, such as those of purged invoices. Objects still referenced are
+// kept.
+func (s *attachmentService) ReleaseObjects(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := s.repo.ReleaseObject(ctx, key, s.store.Delete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxSize returns the largest accepted attachment in bytes
+func (s *attachmentService) MaxSize() int64 {
+	return s.options.MaxSize
+}
+
+// Helper methods
+
+func (s *attachmentService) getInvoice(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error) {
+	invoice, err := s.invoices.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.IsDeleted {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+			WithDetail("invoice_id", id.String())
+	}
+	return invoice, nil
+}
+
+// getAttachment reads an attachment and checks it belongs to the invoice
+func (s *attachmentService) getAttachment(ctx context.Context, invoiceID, id uuid.UUID) (*models.Attachment, error) {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.InvoiceID != invoiceID {
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentNotFound).
+			WithDetail("id", id.String())
+	}
+	return attachment, nil
+}
+
+func (s *attachmentService) attachmentResponse(ctx context.Context, attachment *models.Attachment) (*dto.AttachmentResponse, error) {
+	expiresAt := time.Now().Add(s.options.URLExpiry)
+	url, err := s.store.SignedURL(ctx, attachment.StorageKey, s.options.URLExpiry, storage.Download{
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AttachmentResponse{
+		Attachment:  attachment,
+		DownloadURL: url,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// detectContentType sniffs the content type of a file. XML documents
+// without a declaration sniff as plain text, so text starting with an
+// element counts as XML when the file name or declared type says so. A
+// declared type other than application/octet-stream must agree.
+func detectContentType(data []byte, fileName, declared string) (string, error) {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+
+	detected := sniffed
+	switch sniffed {
+	case "text/xml":
+		detected = models.ContentTypeXML
+	case "text/plain":
+		if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("<")) &&
+			(strings.EqualFold(path.Ext(fileName), ".xml") || isXMLType(declared)) {
+			detected = models.ContentTypeXML
+		}
+	}
+
+	if !slices.Contains(models.AllowedContentTypes, detected) {
+		return "", attachments.AttachmentsErrors.New(attachments.ErrUnsupportedMediaType).
+			WithDetail("content_type", sniffed).
+			WithDetail("allowed", models.AllowedContentTypes)
+	}
+
+	if declared != "" {
+		declaredType, _, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return "", attachments.AttachmentsErrors.New(attachments.ErrUnsupportedMediaType).
+				WithDetail("declared_content_type", declared).
+				WithCause(err)
+		}
+		if declaredType != "application/octet-stream" && declaredType != detected &&
+			!(detected == models.ContentTypeXML && isXMLType(declaredType)) {
+			return "", attachments.AttachmentsErrors.New(attachments.ErrUnsupportedMediaType).
+				WithDetail("declared_content_type", declaredType).
+				WithDetail("content_type", detected).
+				WithDetail("reason", "declared type does not match the file content")
+		}
+	}
+
+	return detected, nil
+}
+
+func isXMLType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/xml" || mediaType == "text/xml"
+}
+
+// cleanFileName keeps the base name of an uploaded file without control
+// characters, limited to 255 bytes
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == "/" {
+		return ""
+	}
+
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
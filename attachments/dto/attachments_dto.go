@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/attachments/models"
+)
+
+// UploadRequest carries a file uploaded to an invoice. The declared content
+// type is checked against the file content.
+type UploadRequest struct {
+	InvoiceID   uuid.UUID  `json:"invoice_id" validate:"required"`
+	FileName    string     `json:"file_name" validate:"required"`
+	ContentType string     `json:"content_type,omitempty"`
+	Data        []byte     `json:"-"`
+	UploadedBy  *uuid.UUID `json:"uploaded_by,omitempty"`
+}
+
+// AttachmentResponse returns an attachment with a signed download URL
+type AttachmentResponse struct {
+	*models.Attachment `json:",inline"`
+	DownloadURL        string    `json:"download_url"`
+	ExpiresAt          time.Time `json:"download_url_expires_at"`
+	Deduplicated       bool      `json:"deduplicated,omitempty"`
+}
+
+// AttachmentListResponse lists the attachments of an invoice, oldest first
+type AttachmentListResponse struct {
+	InvoiceID   uuid.UUID           `json:"invoice_id"`
+	Attachments []models.Attachment `json:"attachments"`
+}
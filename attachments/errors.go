@@ -0,0 +1,82 @@
+package attachments
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// AttachmentsErrors is the error registry for attachments domain
+var AttachmentsErrors = errx.NewRegistry("ATTACHMENTS")
+
+// Attachment error codes
+var (
+	// Basic CRUD errors
+	ErrAttachmentNotFound = AttachmentsErrors.Register(
+		"ATTACHMENT_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Attachment not found",
+	)
+
+	ErrAttachmentExists = AttachmentsErrors.Register(
+		"ATTACHMENT_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"The same file is already attached to the invoice",
+	)
+
+	ErrAttachmentUploadFailed = AttachmentsErrors.Register(
+		"UPLOAD_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to store attachment",
+	)
+
+	ErrAttachmentDeleteFailed = AttachmentsErrors.Register(
+		"DELETE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to delete attachment",
+	)
+
+	// File errors
+	ErrFileTooLarge = AttachmentsErrors.Register(
+		"FILE_TOO_LARGE",
+		errx.TypeValidation,
+		http.StatusRequestEntityTooLarge,
+		"Attachment exceeds the maximum file size",
+	)
+
+	ErrUnsupportedMediaType = AttachmentsErrors.Register(
+		"UNSUPPORTED_MEDIA_TYPE",
+		errx.TypeValidation,
+		http.StatusUnsupportedMediaType,
+		"Attachment file type is not supported",
+	)
+
+	// Query errors
+	ErrAttachmentListFailed = AttachmentsErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list attachments",
+	)
+
+	// Validation errors
+	ErrAttachmentValidationFailed = AttachmentsErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Attachment validation failed",
+	)
+)
+
+// Helper functions for error checking
+func IsAttachmentNotFound(err error) bool {
+	return errx.IsCode(err, ErrAttachmentNotFound)
+}
+
+func IsAttachmentExists(err error) bool {
+	return errx.IsCode(err, ErrAttachmentExists)
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Content types attachments may have. Invoices arrive as PDFs and
+// electronic invoice XMLs, scans as images, and tax authority responses as
+// ZIP archives.
+const (
+	ContentTypePDF  = "application/pdf"
+	ContentTypeXML  = "application/xml"
+	ContentTypePNG  = "image/png"
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypeZIP  = "application/zip"
+)
+
+// AllowedContentTypes lists the content types accepted for attachments
+var AllowedContentTypes = []string{
+	ContentTypePDF,
+	ContentTypeXML,
+	ContentTypePNG,
+	ContentTypeJPEG,
+	ContentTypeZIP,
+}
+
+// Attachment is a file attached to an invoice
+type Attachment struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	InvoiceID      uuid.UUID  `db:"invoice_id" json:"invoice_id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organization_id"`
+	FileName       string     `db:"file_name" json:"file_name"`
+	ContentType    string     `db:"content_type" json:"content_type"`
+	SizeBytes      int64      `db:"size_bytes" json:"size_bytes"`
+	SHA256         string     `db:"sha256" json:"sha256"`
+	StorageKey     string     `db:"storage_key" json:"-"`
+	UploadedBy     *uuid.UUID `db:"uploaded_by" json:"uploaded_by"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the table name for the Attachment model
+func (a Attachment) TableName() string {
+	return "invoice_attachments"
+}
+
+// StorageKey is where the content with the given SHA-256 is stored for an
+// organization. Identical files of the organization share the object.
+func StorageKey(orgID uuid.UUID, sha256 string) string {
+	return "attachments/" + orgID.String() + "/" + sha256
+}
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/attachments"
+	"github.com/Abraxas-365/fuckturamelo/attachments/models"
+)
+
+// attachmentRepository implements AttachmentRepository using sqlx
+type attachmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *sqlx.DB) AttachmentRepository {
+	return &attachmentRepository{
+		db: db,
+	}
+}
+
+// Create stores the attachment's content through store and saves its
+// metadata. A file already attached to the invoice is rejected before
+// store runs.
+func (r *attachmentRepository) Create(ctx context.Context, attachment *models.Attachment, store func(ctx context.Context) error) (*models.Attachment, error) {
+	uploadError := func(err error) error {
+		return attachments.AttachmentsErrors.New(attachments.ErrAttachmentUploadFailed).
+			WithDetail("invoice_id", attachment.InvoiceID.String()).
+			WithCause(err)
+	}
+	existsError := func(existing uuid.UUID) error {
+		return attachments.AttachmentsErrors.New(attachments.ErrAttachmentExists).
+			WithDetail("invoice_id", attachment.InvoiceID.String()).
+			WithDetail("attachment_id", existing.String())
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	defer tx.Rollback()
+
+	if err := lockStorageKey(ctx, tx, attachment.StorageKey); err != nil {
+		return nil, uploadError(err)
+	}
+
+	var existing uuid.UUID
+	err = tx.GetContext(ctx, &existing,
+		`SELECT id FROM invoice_attachments WHERE invoice_id = $1 AND sha256 = $2`,
+		attachment.InvoiceID, attachment.SHA256)
+	if err == nil {
+		return nil, existsError(existing)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, uploadError(err)
+	}
+
+	if err := store(ctx); err != nil {
+		return nil, err
+	}
+
+	var created models.Attachment
+	err = tx.GetContext(ctx, &created, `
+		INSERT INTO invoice_attachments
+			(id, invoice_id, organization_id, file_name, content_type, size_bytes, sha256, storage_key,
+			 uploaded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING *`,
+		attachment.ID, attachment.InvoiceID, attachment.OrganizationID, attachment.FileName,
+		attachment.ContentType, attachment.SizeBytes, attachment.SHA256, attachment.StorageKey,
+		attachment.UploadedBy, attachment.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "invoice_attachments_file_unique") {
+			return nil, existsError(uuid.Nil)
+		}
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentValidationFailed).
+				WithDetail("invoice_id", attachment.InvoiceID.String()).
+				WithDetail("reason", "invoice or organization does not exist").
+				WithCause(err)
+		}
+		return nil, uploadError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, uploadError(err)
+	}
+
+	return &created, nil
+}
+
+// GetByID retrieves an attachment by ID
+func (r *attachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.GetContext(ctx, &attachment, `SELECT * FROM invoice_attachments WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	return &attachment, nil
+}
+
+// ListByInvoice lists the attachments of an invoice, oldest first
+func (r *attachmentRepository) ListByInvoice(ctx context.Context, invoiceID uuid.UUID) ([]models.Attachment, error) {
+	list := []models.Attachment{}
+	err := r.db.SelectContext(ctx, &list,
+		`SELECT * FROM invoice_attachments WHERE invoice_id = $1 ORDER BY created_at, id`, invoiceID)
+	if err != nil {
+		return nil, attachments.AttachmentsErrors.New(attachments.ErrAttachmentListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return list, nil
+}
+
+// Delete removes an attachment. The stored object is released in the same
+// transaction once its last reference is gone, so a failing blob store
+// keeps the attachment.
+func (r *attachmentRepository) Delete(ctx context.Context, id uuid.UUID, release func(ctx context.Context, key string) error) error {
+	deleteError := func(err error) error {
+		return attachments.AttachmentsErrors.New(attachments.ErrAttachmentDeleteFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return deleteError(err)
+	}
+	defer tx.Rollback()
+
+	var key string
+	err = tx.GetContext(ctx, &key,
+		`DELETE FROM invoice_attachments WHERE id = $1 RETURNING storage_key`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return attachments.AttachmentsErrors.New(attachments.ErrAttachmentNotFound).
+				WithDetail("id", id.String())
+		}
+		return deleteError(err)
+	}
+
+	if err := lockStorageKey(ctx, tx, key); err != nil {
+		return deleteError(err)
+	}
+
+	var references int
+	err = tx.GetContext(ctx, &references,
+		`SELECT COUNT(*) FROM invoice_attachments WHERE storage_key = $1`, key)
+	if err != nil {
+		return deleteError(err)
+	}
+	if references == 0 {
+		if err := release(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return deleteError(err)
+	}
+
+	return nil
+}
+
+// ReleaseObject runs release for a storage key no attachment references
+// anymore. An upload that stored the object again in the meantime keeps it.
+func (r *attachmentRepository) ReleaseObject(ctx context.Context, key string, release func(ctx context.Context, key string) error) error {
+	releaseError := func(err error) error {
+		return attachments.AttachmentsErrors.New(attachments.ErrAttachmentDeleteFailed).
+			WithDetail("storage_key", key).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return releaseError(err)
+	}
+	defer tx.Rollback()
+
+	if err := lockStorageKey(ctx, tx, key); err != nil {
+		return releaseError(err)
+	}
+
+	var references int
+	err = tx.GetContext(ctx, &references,
+		`SELECT COUNT(*) FROM invoice_attachments WHERE storage_key = $1`, key)
+	if err != nil {
+		return releaseError(err)
+	}
+	if references == 0 {
+		if err := release(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return releaseError(err)
+	}
+
+	return nil
+}
+
+// lockStorageKey serializes the transactions touching a stored object
+func lockStorageKey(ctx context.Context, tx *sqlx.Tx, key string) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key)
+	return err
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/attachments/models"
+)
+
+// AttachmentRepository defines the interface for attachment repository
+// operations. Blob store calls run while the storage key is locked, so an
+// upload reusing a stored object and the deletion of its last reference
+// cannot interleave.
+type AttachmentRepository interface {
+	// Create runs store and saves the attachment under the lock of its
+	// storage key
+	Create(ctx context.Context, attachment *models.Attachment, store func(ctx context.Context) error) (*models.Attachment, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Attachment, error)
+	ListByInvoice(ctx context.Context, invoiceID uuid.UUID) ([]models.Attachment, error)
+
+	// Delete removes the attachment and runs release when no attachment
+	// references its storage key anymore
+	Delete(ctx context.Context, id uuid.UUID, release func(ctx context.Context, key string) error) error
+
+	// ReleaseObject runs release when no attachment references the storage
+	// key, as happens once the invoices holding it are purged
+	ReleaseObject(ctx context.Context, key string, release func(ctx context.Context, key string) error) error
+}
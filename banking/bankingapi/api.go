@@ -0,0 +1,348 @@
+package bankingapi
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/banking"
+	"github.com/Abraxas-365/fuckturamelo/banking/bankingsrv"
+	"github.com/Abraxas-365/fuckturamelo/banking/dto"
+	"github.com/Abraxas-365/fuckturamelo/banking/matcher"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/banking/repository"
+	"github.com/Abraxas-365/fuckturamelo/banking/statement"
+)
+
+// BankingAPI contains the complete API setup for the banking domain
+type BankingAPI struct {
+	service bankingsrv.StatementService
+	repo    postgres.StatementRepository
+	batches bankingsrv.BatchService
+}
+
+// Config contains configuration for the banking API
+type Config struct {
+	DB *sqlx.DB
+
+	// Matcher tunes reconciliation suggestions (defaults to
+	// matcher.DefaultOptions)
+	Matcher matcher.Options
+}
+
+// New creates a new BankingAPI instance
+func New(config Config) (*BankingAPI, error) {
+	if config.DB == nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewStatementRepository(config.DB)
+	svc := bankingsrv.NewStatementService(repo, config.Matcher)
+	batches := bankingsrv.NewBatchService(postgres.NewBatchRepository(config.DB))
+
+	return &BankingAPI{
+		service: svc,
+		repo:    repo,
+		batches: batches,
+	}, nil
+}
+
+// SetupRoutes registers all bank statement routes with the given Fiber router
+// group
+func (api *BankingAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Review routes
+	router.Post("/suggestions/:suggestionId/review", api.reviewSuggestion)
+
+	// Statement routes
+	router.Post("/", api.importStatement)
+	router.Get("/", api.listStatements)
+	router.Get("/:id", api.getStatement)
+
+	// Reconciliation routes
+	router.Post("/:id/match", api.matchStatement)
+	router.Get("/:id/suggestions", api.listSuggestions)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *BankingAPI) GetService() bankingsrv.StatementService {
+	return api.service
+}
+
+// GetBatchService returns the payment batch service for dependency injection
+func (api *BankingAPI) GetBatchService() bankingsrv.BatchService {
+	return api.batches
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *BankingAPI) GetRepository() postgres.StatementRepository {
+	return api.repo
+}
+
+// Statement handlers
+
+// importStatement handles POST /bank-statements as a multipart upload with a
+// "file" part and organization_id, format and imported_by fields
+func (api *BankingAPI) importStatement(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDForm(c, "organization_id")
+	if err != nil {
+		return err
+	}
+	if orgID == nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Missing required field: organization_id")
+	}
+
+	importedBy, err := api.parseUUIDForm(c, "imported_by")
+	if err != nil {
+		return err
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Missing statement file in multipart field: file").
+			WithCause(err)
+	}
+	if header.Size > bankingsrv.MaxStatementSize {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Statement file is too large").
+			WithDetail("max_bytes", bankingsrv.MaxStatementSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Statement file could not be read").
+			WithCause(err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Statement file could not be read").
+			WithCause(err)
+	}
+
+	req := &dto.ImportStatementRequest{
+		OrganizationID: *orgID,
+		Format:         statement.Format(c.FormValue("format")),
+		FileName:       header.Filename,
+		Data:           data,
+		ImportedBy:     importedBy,
+	}
+
+	result, err := api.service.ImportStatement(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getStatement handles GET /bank-statements/:id
+func (api *BankingAPI) getStatement(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetStatement(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listStatements handles GET /bank-statements
+func (api *BankingAPI) listStatements(c *fiber.Ctx) error {
+	req, err := api.parseListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListStatements(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Reconciliation handlers
+
+// matchStatement handles POST /bank-statements/:id/match
+func (api *BankingAPI) matchStatement(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.MatchStatement(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listSuggestions handles GET /bank-statements/:id/suggestions
+func (api *BankingAPI) listSuggestions(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var status *models.SuggestionStatus
+	if value := c.Query("status"); value != "" {
+		s := models.SuggestionStatus(value)
+		status = &s
+	}
+
+	result, err := api.service.ListSuggestions(c.Context(), id, status)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// reviewSuggestion handles POST /bank-statements/suggestions/:suggestionId/review
+func (api *BankingAPI) reviewSuggestion(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "suggestionId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.ReviewSuggestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.ReviewSuggestion(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// healthCheck provides a health check endpoint
+func (api *BankingAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "banking",
+	})
+}
+
+// Helper methods
+
+func (api *BankingAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *BankingAPI) parseUUIDQuery(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &id, nil
+}
+
+func (api *BankingAPI) parseUUIDForm(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.FormValue(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &id, nil
+}
+
+func (api *BankingAPI) parseListRequest(c *fiber.Ctx) (*dto.StatementListRequest, error) {
+	req := &dto.StatementListRequest{}
+	var err error
+
+	// Parse filters
+	if req.OrganizationID, err = api.parseUUIDQuery(c, "organization_id"); err != nil {
+		return nil, err
+	}
+	if account := c.Query("account_identifier"); account != "" {
+		req.AccountIdentifier = &account
+	}
+
+	// Parse pagination
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	req.Page = page
+
+	pageSize := postgres.DefaultPageSize
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= postgres.MaxPageSize {
+			pageSize = ps
+		}
+	}
+	req.PageSize = pageSize
+
+	return req, nil
+}
@@ -0,0 +1,249 @@
+package bankingapi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Abraxas-365/fuckturamelo/banking"
+	"github.com/Abraxas-365/fuckturamelo/banking/dto"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/banking/repository"
+)
+
+// SetupPaymentBatchRoutes registers the outbound payment batch routes with
+// the given Fiber router group
+func (api *BankingAPI) SetupPaymentBatchRoutes(router fiber.Router) {
+	// Layout routes
+	router.Post("/layouts", api.createLayout)
+	router.Get("/layouts", api.listLayouts)
+	router.Get("/layouts/:layoutId", api.getLayout)
+	router.Delete("/layouts/:layoutId", api.deleteLayout)
+
+	// Batch routes
+	router.Post("/", api.createBatch)
+	router.Get("/", api.listBatches)
+	router.Get("/:id", api.getBatch)
+	router.Get("/:id/file", api.downloadBatchFile)
+	router.Post("/:id/status", api.updateBatchStatus)
+}
+
+// Batch handlers
+
+// createBatch handles POST /payment-batches
+func (api *BankingAPI) createBatch(c *fiber.Ctx) error {
+	var req dto.CreateBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.batches.CreateBatch(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getBatch handles GET /payment-batches/:id
+func (api *BankingAPI) getBatch(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.batches.GetBatch(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// downloadBatchFile handles GET /payment-batches/:id/file
+func (api *BankingAPI) downloadBatchFile(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	batch, err := api.batches.GetBatchFile(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	switch {
+	case batch.Format == models.BatchFormatPain001:
+		contentType = fiber.MIMEApplicationXMLCharsetUTF8
+	case strings.HasSuffix(batch.FileName, ".csv"):
+		contentType = "text/csv; charset=utf-8"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+batch.FileName+`"`)
+	c.Set(fiber.HeaderETag, `"`+batch.FileSHA256+`"`)
+
+	return c.Status(fiber.StatusOK).Send(batch.FileContent)
+}
+
+// listBatches handles GET /payment-batches
+func (api *BankingAPI) listBatches(c *fiber.Ctx) error {
+	req, err := api.parseBatchListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.batches.ListBatches(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// updateBatchStatus handles POST /payment-batches/:id/status
+func (api *BankingAPI) updateBatchStatus(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.BatchStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.batches.UpdateBatchStatus(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Layout handlers
+
+// createLayout handles POST /payment-batches/layouts
+func (api *BankingAPI) createLayout(c *fiber.Ctx) error {
+	var req dto.CreateLayoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.batches.CreateLayout(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listLayouts handles GET /payment-batches/layouts?organization_id=
+func (api *BankingAPI) listLayouts(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDQuery(c, "organization_id")
+	if err != nil {
+		return err
+	}
+	if orgID == nil {
+		return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("error", "Organization ID parameter is required")
+	}
+
+	result, err := api.batches.ListLayouts(c.Context(), *orgID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getLayout handles GET /payment-batches/layouts/:layoutId
+func (api *BankingAPI) getLayout(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "layoutId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.batches.GetLayout(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteLayout handles DELETE /payment-batches/layouts/:layoutId
+func (api *BankingAPI) deleteLayout(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "layoutId")
+	if err != nil {
+		return err
+	}
+
+	if err := api.batches.DeleteLayout(c.Context(), id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (api *BankingAPI) parseBatchListRequest(c *fiber.Ctx) (*dto.BatchListRequest, error) {
+	req := &dto.BatchListRequest{}
+	var err error
+
+	// Parse filters
+	if req.OrganizationID, err = api.parseUUIDQuery(c, "organization_id"); err != nil {
+		return nil, err
+	}
+	if status := c.Query("status"); status != "" {
+		s := models.BatchStatus(status)
+		req.Status = &s
+	}
+
+	// Parse pagination
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	req.Page = page
+
+	pageSize := postgres.DefaultPageSize
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= postgres.MaxPageSize {
+			pageSize = ps
+		}
+	}
+	req.PageSize = pageSize
+
+	return req, nil
+}
@@ -0,0 +1,415 @@
+package bankingsrv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/banking"
+	"github.com/Abraxas-365/fuckturamelo/banking/dto"
+	"github.com/Abraxas-365/fuckturamelo/banking/iban"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+	"github.com/Abraxas-365/fuckturamelo/banking/paymentfile"
+	postgres "github.com/Abraxas-365/fuckturamelo/banking/repository"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// MaxBatchItems bounds the number of invoices paid by one batch
+const MaxBatchItems = 1000
+
+// BatchService defines the interface for outbound payment batch business
+// logic
+type BatchService interface {
+	// Batch operations
+	CreateBatch(ctx context.Context, req *dto.CreateBatchRequest) (*dto.BatchResponse, error)
+	GetBatch(ctx context.Context, id uuid.UUID) (*dto.BatchResponse, error)
+	GetBatchFile(ctx context.Context, id uuid.UUID) (*models.PaymentBatch, error)
+	ListBatches(ctx context.Context, req *dto.BatchListRequest) (*dto.BatchListResponse, error)
+	UpdateBatchStatus(ctx context.Context, id uuid.UUID, req *dto.BatchStatusRequest) (*dto.BatchResponse, error)
+
+	// Layout operations
+	CreateLayout(ctx context.Context, req *dto.CreateLayoutRequest) (*models.BankFileLayout, error)
+	GetLayout(ctx context.Context, id uuid.UUID) (*models.BankFileLayout, error)
+	ListLayouts(ctx context.Context, orgID uuid.UUID) ([]models.BankFileLayout, error)
+	DeleteLayout(ctx context.Context, id uuid.UUID) error
+}
+
+// batchService implements BatchService
+type batchService struct {
+	repo postgres.BatchRepository
+}
+
+// NewBatchService creates a new payment batch service
+func NewBatchService(repo postgres.BatchRepository) BatchService {
+	return &batchService{
+		repo: repo,
+	}
+}
+
+// CreateBatch pays the outstanding amount of approved invoices into their
+// providers' default bank accounts. The file is generated and stored with
+// the batch, and the invoices stay scheduled until the batch is completed
+// or cancelled.
+func (s *batchService) CreateBatch(ctx context.Context, req *dto.CreateBatchRequest) (*dto.BatchResponse, error) {
+	executionDate, err := validateBatchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var layout *models.BankFileLayout
+	if req.Format == models.BatchFormatLayout {
+		if layout, err = s.repo.GetLayout(ctx, *req.LayoutID); err != nil {
+			return nil, err
+		}
+		if layout.OrganizationID != req.OrganizationID {
+			return nil, banking.BankingErrors.New(banking.ErrLayoutNotFound).
+				WithDetail("id", req.LayoutID.String())
+		}
+	}
+
+	payable, err := s.repo.PayableInvoices(ctx, req.InvoiceIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.PayableInvoice, len(payable))
+	for _, invoice := range payable {
+		byID[invoice.InvoiceID] = invoice
+	}
+
+	// Report every invoice that cannot be paid at once
+	var problems []map[string]string
+	for _, id := range req.InvoiceIDs {
+		reason := "invoice not found"
+		if invoice, ok := byID[id]; ok {
+			reason = invoice.Problem(req.OrganizationID, req.CurrencyCode)
+		}
+		if reason != "" {
+			problems = append(problems, map[string]string{"invoice_id": id.String(), "reason": reason})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, banking.BankingErrors.New(banking.ErrInvoiceNotPayable).
+			WithDetail("invoices", problems)
+	}
+
+	now := time.Now()
+	batch := newBatch(req, executionDate, now)
+	for i, id := range req.InvoiceIDs {
+		batch.Items = append(batch.Items, newBatchItem(batch, i+1, byID[id]))
+		batch.TotalAmount = batch.TotalAmount.Add(byID[id].OutstandingAmount)
+	}
+	batch.ItemCount = len(batch.Items)
+
+	if err := renderBatch(batch, layout, now); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateBatch(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return batchResponse(created), nil
+}
+
+// GetBatch retrieves a batch with its items
+func (s *batchService) GetBatch(ctx context.Context, id uuid.UUID) (*dto.BatchResponse, error) {
+	batch, err := s.repo.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return batchResponse(batch), nil
+}
+
+// GetBatchFile retrieves a batch with the content of its file
+func (s *batchService) GetBatchFile(ctx context.Context, id uuid.UUID) (*models.PaymentBatch, error) {
+	return s.repo.GetBatch(ctx, id)
+}
+
+// ListBatches lists batches with filtering and pagination
+func (s *batchService) ListBatches(ctx context.Context, req *dto.BatchListRequest) (*dto.BatchListResponse, error) {
+	if req.Status != nil && !req.Status.Valid() {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "status").
+			WithDetail("reason", "must be generated, sent, completed or cancelled")
+	}
+
+	return s.repo.ListBatches(ctx, req)
+}
+
+// UpdateBatchStatus marks a batch as sent to the bank, completed (recording
+// the payments of its invoices) or cancelled (releasing them)
+func (s *batchService) UpdateBatchStatus(ctx context.Context, id uuid.UUID, req *dto.BatchStatusRequest) (*dto.BatchResponse, error) {
+	if req.ChangedBy == uuid.Nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "changed_by").
+			WithDetail("reason", "required")
+	}
+	if !req.Status.Valid() || req.Status == models.BatchGenerated {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "status").
+			WithDetail("reason", "must be sent, completed or cancelled")
+	}
+
+	batch, err := s.repo.UpdateBatchStatus(ctx, id, req.Status, req.ChangedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return batchResponse(batch), nil
+}
+
+// CreateLayout validates and saves a bank file layout
+func (s *batchService) CreateLayout(ctx context.Context, req *dto.CreateLayoutRequest) (*models.BankFileLayout, error) {
+	if req.OrganizationID == uuid.Nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "organization_id").
+			WithDetail("reason", "required")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "name").
+			WithDetail("reason", "required")
+	}
+	if err := req.Definition.Validate(); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "definition").
+			WithDetail("reason", err.Error())
+	}
+
+	return s.repo.CreateLayout(ctx, &models.BankFileLayout{
+		ID:             uuid.New(),
+		OrganizationID: req.OrganizationID,
+		Name:           name,
+		Kind:           req.Definition.Kind,
+		Definition:     models.LayoutDefinition(req.Definition),
+		CreatedBy:      req.CreatedBy,
+	})
+}
+
+// GetLayout retrieves a bank file layout
+func (s *batchService) GetLayout(ctx context.Context, id uuid.UUID) (*models.BankFileLayout, error) {
+	return s.repo.GetLayout(ctx, id)
+}
+
+// ListLayouts lists the layouts of an organization
+func (s *batchService) ListLayouts(ctx context.Context, orgID uuid.UUID) ([]models.BankFileLayout, error) {
+	return s.repo.ListLayouts(ctx, orgID)
+}
+
+// DeleteLayout removes a layout that no batch was generated with
+func (s *batchService) DeleteLayout(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteLayout(ctx, id)
+}
+
+// Helper methods
+
+// validateBatchRequest checks the request and normalizes its currency and
+// account identifiers, returning the execution date
+func validateBatchRequest(req *dto.CreateBatchRequest) (time.Time, error) {
+	fail := func(field, reason string) (time.Time, error) {
+		return time.Time{}, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", field).
+			WithDetail("reason", reason)
+	}
+
+	if req.OrganizationID == uuid.Nil {
+		return fail("organization_id", "required")
+	}
+	if len(req.InvoiceIDs) == 0 {
+		return fail("invoice_ids", "required")
+	}
+	if len(req.InvoiceIDs) > MaxBatchItems {
+		return fail("invoice_ids", fmt.Sprintf("must not exceed %d invoices", MaxBatchItems))
+	}
+	seen := make(map[uuid.UUID]bool, len(req.InvoiceIDs))
+	for _, id := range req.InvoiceIDs {
+		if id == uuid.Nil || seen[id] {
+			return fail("invoice_ids", "must be distinct invoice ids")
+		}
+		seen[id] = true
+	}
+
+	if !req.Format.Valid() {
+		return fail("format", "must be pain001 or layout")
+	}
+	if req.Format == models.BatchFormatLayout && req.LayoutID == nil {
+		return fail("layout_id", "required for the layout format")
+	}
+	if req.Format == models.BatchFormatPain001 {
+		req.LayoutID = nil
+	}
+
+	if len(req.CurrencyCode) != 3 {
+		return fail("currency_code", "invalid_currency_code")
+	}
+	req.CurrencyCode = strings.ToUpper(req.CurrencyCode)
+
+	executionDate, err := time.Parse(invoicemodels.DateLayout, req.ExecutionDate)
+	if err != nil {
+		return fail("execution_date", "invalid_date")
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if executionDate.Before(today) {
+		return fail("execution_date", "must not be in the past")
+	}
+
+	req.DebtorName = strings.TrimSpace(req.DebtorName)
+	if req.DebtorName == "" {
+		return fail("debtor_name", "required")
+	}
+	if req.DebtorIBAN != nil && *req.DebtorIBAN != "" {
+		normalized := iban.Normalize(*req.DebtorIBAN)
+		if !iban.Valid(normalized) {
+			return fail("debtor_iban", "IBAN is malformed or its check digits do not match")
+		}
+		req.DebtorIBAN = &normalized
+	} else {
+		req.DebtorIBAN = nil
+	}
+	if req.DebtorAccountNumber != nil && strings.TrimSpace(*req.DebtorAccountNumber) == "" {
+		req.DebtorAccountNumber = nil
+	}
+	if req.DebtorIBAN == nil && req.DebtorAccountNumber == nil {
+		return fail("debtor_iban", "either debtor_iban or debtor_account_number is required")
+	}
+	if req.DebtorBIC != nil && *req.DebtorBIC != "" {
+		bic := iban.Normalize(*req.DebtorBIC)
+		if !iban.ValidBIC(bic) {
+			return fail("debtor_bic", "BIC must have 8 or 11 characters")
+		}
+		req.DebtorBIC = &bic
+	} else {
+		req.DebtorBIC = nil
+	}
+
+	return executionDate, nil
+}
+
+// newBatch creates the batch model. Its file name doubles as the message
+// identifier banks use to reject duplicate uploads.
+func newBatch(req *dto.CreateBatchRequest, executionDate, now time.Time) *models.PaymentBatch {
+	id := uuid.New()
+
+	return &models.PaymentBatch{
+		ID:                  id,
+		OrganizationID:      req.OrganizationID,
+		Format:              req.Format,
+		LayoutID:            req.LayoutID,
+		Status:              models.BatchGenerated,
+		CurrencyCode:        req.CurrencyCode,
+		ExecutionDate:       executionDate,
+		TotalAmount:         decimal.Zero,
+		DebtorName:          req.DebtorName,
+		DebtorIBAN:          req.DebtorIBAN,
+		DebtorAccountNumber: req.DebtorAccountNumber,
+		DebtorBIC:           req.DebtorBIC,
+		FileName:            fmt.Sprintf("PB%s-%s", now.UTC().Format("20060102"), id.String()[:8]),
+		CreatedBy:           req.CreatedBy,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+}
+
+// newBatchItem snapshots an invoice and its provider's default account
+func newBatchItem(batch *models.PaymentBatch, sequence int, invoice models.PayableInvoice) models.BatchItem {
+	creditorName := ""
+	if invoice.AccountHolder != nil {
+		creditorName = *invoice.AccountHolder
+	} else if invoice.ProviderName != nil {
+		creditorName = *invoice.ProviderName
+	}
+
+	reference := invoice.InvoiceID.String()
+	if invoice.InvoiceNumber != nil && *invoice.InvoiceNumber != "" {
+		reference = *invoice.InvoiceNumber
+	}
+	remittance := "Invoice " + reference
+
+	return models.BatchItem{
+		ID:                    uuid.New(),
+		BatchID:               batch.ID,
+		Sequence:              sequence,
+		InvoiceID:             invoice.InvoiceID,
+		InvoiceNumber:         invoice.InvoiceNumber,
+		ProviderID:            invoice.ProviderID,
+		BankAccountID:         invoice.BankAccountID,
+		EndToEndID:            fmt.Sprintf("%s-%d", batch.FileName, sequence),
+		Amount:                invoice.OutstandingAmount,
+		CurrencyCode:          batch.CurrencyCode,
+		CreditorName:          creditorName,
+		CreditorIBAN:          invoice.IBAN,
+		CreditorAccountNumber: invoice.AccountNumber,
+		CreditorBIC:           invoice.BIC,
+		RemittanceInfo:        &remittance,
+	}
+}
+
+// renderBatch writes the batch file and sets its name, content and checksum
+func renderBatch(batch *models.PaymentBatch, layout *models.BankFileLayout, now time.Time) error {
+	input := paymentfile.Batch{
+		MessageID:     batch.FileName,
+		CreatedAt:     now,
+		ExecutionDate: batch.ExecutionDate,
+		Currency:      batch.CurrencyCode,
+		Debtor:        batch.Debtor(),
+		Items:         make([]paymentfile.Item, len(batch.Items)),
+	}
+	for i, item := range batch.Items {
+		input.Items[i] = item.PaymentFileItem()
+	}
+
+	var (
+		content   []byte
+		extension string
+		err       error
+	)
+	if layout == nil {
+		content, err = paymentfile.Pain001(input)
+		extension = ".xml"
+	} else {
+		definition := layout.Definition.Layout()
+		content, err = definition.Render(input)
+		extension = ".txt"
+		if definition.Kind == paymentfile.KindCSV {
+			extension = ".csv"
+		}
+	}
+	if err != nil {
+		var fileErr *paymentfile.Error
+		if errors.As(err, &fileErr) {
+			e := banking.BankingErrors.New(banking.ErrInvalidBatchFile).
+				WithDetail("field", fileErr.Field).
+				WithDetail("reason", fileErr.Message)
+			if fileErr.Item > 0 {
+				e = e.WithDetail("invoice_id", batch.Items[fileErr.Item-1].InvoiceID.String())
+			}
+			return e
+		}
+		return banking.BankingErrors.New(banking.ErrInvalidBatchFile).
+			WithCause(err)
+	}
+
+	sum := sha256.Sum256(content)
+	batch.FileName += extension
+	batch.FileContent = content
+	batch.FileSHA256 = hex.EncodeToString(sum[:])
+
+	return nil
+}
+
+func batchResponse(batch *models.PaymentBatch) *dto.BatchResponse {
+	return &dto.BatchResponse{
+		PaymentBatch:       batch,
+		AllowedTransitions: batch.Status.AllowedTransitions(),
+	}
+}
@@ -0,0 +1,352 @@
+package bankingsrv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/banking"
+	"github.com/Abraxas-365/fuckturamelo/banking/dto"
+	"github.com/Abraxas-365/fuckturamelo/banking/matcher"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/banking/repository"
+	"github.com/Abraxas-365/fuckturamelo/banking/statement"
+)
+
+// MaxStatementSize bounds the size of an uploaded statement file
+const MaxStatementSize = 10 << 20
+
+// StatementService defines the interface for bank statement business logic
+type StatementService interface {
+	// Import operations
+	ImportStatement(ctx context.Context, req *dto.ImportStatementRequest) (*dto.ImportStatementResponse, error)
+
+	// Query operations
+	GetStatement(ctx context.Context, id uuid.UUID) (*dto.StatementResponse, error)
+	ListStatements(ctx context.Context, req *dto.StatementListRequest) (*dto.StatementListResponse, error)
+
+	// Reconciliation operations
+	MatchStatement(ctx context.Context, id uuid.UUID) (*dto.MatchResponse, error)
+	ListSuggestions(ctx context.Context, statementID uuid.UUID, status *models.SuggestionStatus) (*dto.SuggestionListResponse, error)
+	ReviewSuggestion(ctx context.Context, id uuid.UUID, req *dto.ReviewSuggestionRequest) (*dto.ReviewSuggestionResponse, error)
+}
+
+// statementService implements StatementService
+type statementService struct {
+	repo    postgres.StatementRepository
+	matcher matcher.Options
+}
+
+// NewStatementService creates a new bank statement service. Zero matcher
+// options fall back to matcher.DefaultOptions.
+func NewStatementService(repo postgres.StatementRepository, opts matcher.Options) StatementService {
+	if opts == (matcher.Options{}) {
+		opts = matcher.DefaultOptions()
+	}
+
+	return &statementService{
+		repo:    repo,
+		matcher: opts,
+	}
+}
+
+// ImportStatement parses a statement file, stores its statements and
+// proposes the invoices each debit line probably pays
+func (s *statementService) ImportStatement(ctx context.Context, req *dto.ImportStatementRequest) (*dto.ImportStatementResponse, error) {
+	if req.OrganizationID == uuid.Nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "organization_id").
+			WithDetail("reason", "required")
+	}
+	if len(req.Data) == 0 {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "file").
+			WithDetail("reason", "required")
+	}
+	if len(req.Data) > MaxStatementSize {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "file").
+			WithDetail("reason", "too_large").
+			WithDetail("max_bytes", MaxStatementSize)
+	}
+
+	format := req.Format
+	if format == "" {
+		detected, ok := statement.Detect(req.Data)
+		if !ok {
+			return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+				WithDetail("field", "format").
+				WithDetail("reason", "format could not be detected").
+				WithDetail("supported", statement.Formats())
+		}
+		format = detected
+	}
+	if !format.Valid() {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "format").
+			WithDetail("reason", "unsupported").
+			WithDetail("supported", statement.Formats())
+	}
+
+	parsed, err := statement.Parse(format, req.Data)
+	if err != nil {
+		var parseErr *statement.Error
+		if errors.As(err, &parseErr) {
+			return nil, banking.BankingErrors.New(banking.ErrStatementInvalid).
+				WithDetail("format", string(parseErr.Format)).
+				WithDetail("line", parseErr.Line).
+				WithDetail("reason", parseErr.Message)
+		}
+		return nil, banking.BankingErrors.New(banking.ErrStatementInvalid).
+			WithCause(err)
+	}
+
+	sum := sha256.Sum256(req.Data)
+	checksum := hex.EncodeToString(sum[:])
+
+	statements := make([]*models.Statement, len(parsed))
+	for i, stmt := range parsed {
+		statements[i] = newStatement(req, format, checksum, i, stmt)
+	}
+
+	imported, err := s.repo.Import(ctx, statements)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ImportStatementResponse{
+		Statements: make([]*dto.StatementResponse, 0, len(imported)),
+	}
+	for _, stmt := range imported {
+		match, err := s.match(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+		response.SuggestionsCreated += match.SuggestionsCreated
+
+		summary, err := s.repo.GetSummary(ctx, stmt.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// The lines are fetched with the statement; keep the import response small
+		stmt.Lines = nil
+		response.Statements = append(response.Statements, &dto.StatementResponse{
+			Statement: stmt,
+			Summary:   *summary,
+		})
+	}
+
+	return response, nil
+}
+
+// GetStatement retrieves a statement with its lines
+func (s *statementService) GetStatement(ctx context.Context, id uuid.UUID) (*dto.StatementResponse, error) {
+	stmt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repo.GetSummary(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.StatementResponse{
+		Statement: stmt,
+		Summary:   *summary,
+	}, nil
+}
+
+// ListStatements lists statements with filtering and pagination
+func (s *statementService) ListStatements(ctx context.Context, req *dto.StatementListRequest) (*dto.StatementListResponse, error) {
+	return s.repo.List(ctx, req)
+}
+
+// MatchStatement proposes invoices for the lines of a statement that are not
+// reconciled yet, typically after new invoices were registered
+func (s *statementService) MatchStatement(ctx context.Context, id uuid.UUID) (*dto.MatchResponse, error) {
+	stmt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.match(ctx, stmt)
+}
+
+// ListSuggestions lists the suggestions for the lines of a statement
+func (s *statementService) ListSuggestions(ctx context.Context, statementID uuid.UUID, status *models.SuggestionStatus) (*dto.SuggestionListResponse, error) {
+	if status != nil && !status.Valid() {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "status").
+			WithDetail("reason", "must be pending, accepted or rejected")
+	}
+
+	if _, err := s.repo.GetByID(ctx, statementID); err != nil {
+		return nil, err
+	}
+
+	suggestions, err := s.repo.ListSuggestions(ctx, statementID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SuggestionListResponse{
+		StatementID: statementID,
+		Suggestions: suggestions,
+	}, nil
+}
+
+// ReviewSuggestion accepts or rejects a suggestion. Accepting records the
+// statement line as a payment of the suggested invoice.
+func (s *statementService) ReviewSuggestion(ctx context.Context, id uuid.UUID, req *dto.ReviewSuggestionRequest) (*dto.ReviewSuggestionResponse, error) {
+	if req.ReviewedBy == uuid.Nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "reviewed_by").
+			WithDetail("reason", "required")
+	}
+
+	var (
+		suggestion *models.Suggestion
+		line       *models.StatementLine
+		err        error
+	)
+
+	switch req.Decision {
+	case dto.DecisionAccept:
+		suggestion, line, err = s.repo.AcceptSuggestion(ctx, id, req.ReviewedBy)
+	case dto.DecisionReject:
+		suggestion, line, err = s.repo.RejectSuggestion(ctx, id, req.ReviewedBy)
+	default:
+		return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+			WithDetail("field", "decision").
+			WithDetail("reason", "must be accept or reject")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReviewSuggestionResponse{
+		Suggestion: suggestion,
+		Line:       line,
+	}, nil
+}
+
+// Helper methods
+
+// match runs the matcher over the debit lines of a statement that have no
+// payment yet. Credits are money received and never settle provider invoices.
+func (s *statementService) match(ctx context.Context, stmt *models.Statement) (*dto.MatchResponse, error) {
+	response := &dto.MatchResponse{StatementID: stmt.ID}
+
+	var (
+		open       []models.StatementLine
+		currencies []string
+		latest     time.Time
+	)
+	seen := make(map[string]bool)
+	for _, line := range stmt.Lines {
+		if !line.IsPayment() || line.PaymentID != nil {
+			continue
+		}
+		open = append(open, line)
+		if !seen[line.CurrencyCode] {
+			seen[line.CurrencyCode] = true
+			currencies = append(currencies, line.CurrencyCode)
+		}
+		if line.BookingDate.After(latest) {
+			latest = line.BookingDate
+		}
+	}
+	response.LinesConsidered = len(open)
+	if len(open) == 0 {
+		return response, nil
+	}
+
+	issuedUntil := latest.AddDate(0, 0, s.matcher.DateWindowDays)
+	invoices, err := s.repo.OpenInvoices(ctx, stmt.OrganizationID, currencies, issuedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []models.Suggestion
+	for _, line := range open {
+		for _, match := range matcher.Match(line.MatcherLine(), invoices, s.matcher) {
+			suggestions = append(suggestions, models.NewSuggestion(line.ID, match))
+		}
+	}
+
+	if response.SuggestionsCreated, err = s.repo.SaveSuggestions(ctx, suggestions); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// newStatement converts a parsed statement into its model
+func newStatement(req *dto.ImportStatementRequest, format statement.Format, checksum string, index int, parsed statement.Statement) *models.Statement {
+	now := time.Now()
+
+	stmt := &models.Statement{
+		ID:                uuid.New(),
+		OrganizationID:    req.OrganizationID,
+		Format:            format,
+		AccountIdentifier: parsed.Account,
+		CurrencyCode:      parsed.Currency,
+		PeriodStart:       parsed.PeriodStart,
+		PeriodEnd:         parsed.PeriodEnd,
+		FileSHA256:        checksum,
+		FileIndex:         index,
+		ImportedBy:        req.ImportedBy,
+		ImportedAt:        now,
+		Lines:             make([]models.StatementLine, len(parsed.Entries)),
+	}
+	if parsed.Reference != "" {
+		stmt.StatementReference = &parsed.Reference
+	}
+	if req.FileName != "" {
+		stmt.FileName = &req.FileName
+	}
+	if parsed.Opening != nil {
+		stmt.OpeningBalance = &parsed.Opening.Amount
+		stmt.OpeningDate = &parsed.Opening.Date
+	}
+	if parsed.Closing != nil {
+		stmt.ClosingBalance = &parsed.Closing.Amount
+		stmt.ClosingDate = &parsed.Closing.Date
+	}
+
+	for i, entry := range parsed.Entries {
+		stmt.Lines[i] = models.StatementLine{
+			ID:                  uuid.New(),
+			OrganizationID:      req.OrganizationID,
+			LineNumber:          i + 1,
+			BookingDate:         entry.BookingDate,
+			ValueDate:           entry.ValueDate,
+			Amount:              entry.Amount,
+			Direction:           entry.Direction,
+			CurrencyCode:        entry.Currency,
+			BankReference:       optional(entry.BankReference),
+			EndToEndID:          optional(entry.EndToEndID),
+			CounterpartyName:    optional(entry.CounterpartyName),
+			CounterpartyAccount: optional(entry.CounterpartyAccount),
+			RemittanceInfo:      optional(entry.RemittanceInfo),
+			Status:              models.LineUnmatched,
+			CreatedAt:           now,
+			UpdatedAt:           now,
+		}
+	}
+
+	return stmt
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
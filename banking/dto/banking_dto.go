@@ -0,0 +1,141 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+	"github.com/Abraxas-365/fuckturamelo/banking/paymentfile"
+	"github.com/Abraxas-365/fuckturamelo/banking/statement"
+)
+
+// ImportStatementRequest carries an uploaded statement file. Format is
+// detected from the content when empty.
+type ImportStatementRequest struct {
+	OrganizationID uuid.UUID        `json:"organization_id" validate:"required"`
+	Format         statement.Format `json:"format,omitempty"`
+	FileName       string           `json:"file_name,omitempty"`
+	Data           []byte           `json:"-"`
+	ImportedBy     *uuid.UUID       `json:"imported_by,omitempty"`
+}
+
+// ImportStatementResponse lists the statements read from a file and how many
+// reconciliation suggestions were proposed for them
+type ImportStatementResponse struct {
+	Statements         []*StatementResponse `json:"statements"`
+	SuggestionsCreated int                  `json:"suggestions_created"`
+}
+
+// StatementResponse represents the response for a single statement
+type StatementResponse struct {
+	*models.Statement `json:",inline"`
+	Summary           models.StatementSummary `json:"summary"`
+}
+
+// StatementListRequest represents query parameters for listing statements
+type StatementListRequest struct {
+	OrganizationID    *uuid.UUID `query:"organization_id"`
+	AccountIdentifier *string    `query:"account_identifier"`
+	Page              int        `query:"page" validate:"min=1"`
+	PageSize          int        `query:"page_size" validate:"min=1,max=100"`
+}
+
+// StatementListResponse represents the response for listing statements
+type StatementListResponse struct {
+	Statements  []*StatementResponse `json:"statements"`
+	Total       int64                `json:"total"`
+	Page        int                  `json:"page"`
+	PageSize    int                  `json:"page_size"`
+	TotalPages  int                  `json:"total_pages"`
+	HasNext     bool                 `json:"has_next"`
+	HasPrevious bool                 `json:"has_previous"`
+}
+
+// MatchResponse reports a matching run over the open lines of a statement
+type MatchResponse struct {
+	StatementID        uuid.UUID `json:"statement_id"`
+	LinesConsidered    int       `json:"lines_considered"`
+	SuggestionsCreated int       `json:"suggestions_created"`
+}
+
+// SuggestionListResponse represents the response for listing suggestions
+type SuggestionListResponse struct {
+	StatementID uuid.UUID           `json:"statement_id"`
+	Suggestions []models.Suggestion `json:"suggestions"`
+}
+
+// Review decisions
+const (
+	DecisionAccept = "accept"
+	DecisionReject = "reject"
+)
+
+// ReviewSuggestionRequest accepts or rejects a suggestion. Accepting records
+// the statement line as a payment allocated to the suggested invoice.
+type ReviewSuggestionRequest struct {
+	Decision   string    `json:"decision" validate:"required,oneof=accept reject"`
+	ReviewedBy uuid.UUID `json:"reviewed_by" validate:"required"`
+}
+
+// ReviewSuggestionResponse returns the reviewed suggestion with its line
+type ReviewSuggestionResponse struct {
+	Suggestion *models.Suggestion    `json:"suggestion"`
+	Line       *models.StatementLine `json:"line"`
+}
+
+// CreateBatchRequest selects the invoices to pay and the file to write.
+// Each invoice is paid its full outstanding amount into the default bank
+// account of its provider.
+type CreateBatchRequest struct {
+	OrganizationID      uuid.UUID          `json:"organization_id" validate:"required"`
+	InvoiceIDs          []uuid.UUID        `json:"invoice_ids" validate:"required,min=1"`
+	Format              models.BatchFormat `json:"format" validate:"required,oneof=pain001 layout"`
+	LayoutID            *uuid.UUID         `json:"layout_id,omitempty"`
+	CurrencyCode        string             `json:"currency_code" validate:"required,len=3"`
+	ExecutionDate       string             `json:"execution_date" validate:"required"`
+	DebtorName          string             `json:"debtor_name" validate:"required,max=70"`
+	DebtorIBAN          *string            `json:"debtor_iban,omitempty" validate:"omitempty,max=42"`
+	DebtorAccountNumber *string            `json:"debtor_account_number,omitempty" validate:"omitempty,max=34"`
+	DebtorBIC           *string            `json:"debtor_bic,omitempty" validate:"omitempty,max=11"`
+	CreatedBy           *uuid.UUID         `json:"created_by,omitempty"`
+}
+
+// BatchListRequest represents query parameters for listing payment batches
+type BatchListRequest struct {
+	OrganizationID *uuid.UUID          `query:"organization_id"`
+	Status         *models.BatchStatus `query:"status"`
+	Page           int                 `query:"page" validate:"min=1"`
+	PageSize       int                 `query:"page_size" validate:"min=1,max=100"`
+}
+
+// BatchListResponse represents the response for listing payment batches
+type BatchListResponse struct {
+	Batches     []models.PaymentBatch `json:"batches"`
+	Total       int64                 `json:"total"`
+	Page        int                   `json:"page"`
+	PageSize    int                   `json:"page_size"`
+	TotalPages  int                   `json:"total_pages"`
+	HasNext     bool                  `json:"has_next"`
+	HasPrevious bool                  `json:"has_previous"`
+}
+
+// BatchStatusRequest moves a batch to sent, completed or cancelled.
+// Completing records a bank transfer payment for every item.
+type BatchStatusRequest struct {
+	Status    models.BatchStatus `json:"status" validate:"required,oneof=sent completed cancelled"`
+	ChangedBy uuid.UUID          `json:"changed_by" validate:"required"`
+}
+
+// BatchResponse returns a batch with its items and the statuses it can move
+// to
+type BatchResponse struct {
+	*models.PaymentBatch `json:",inline"`
+	AllowedTransitions   []models.BatchStatus `json:"allowed_transitions"`
+}
+
+// CreateLayoutRequest saves a CSV or fixed-width bank file layout
+type CreateLayoutRequest struct {
+	OrganizationID uuid.UUID          `json:"organization_id" validate:"required"`
+	Name           string             `json:"name" validate:"required,min=1,max=255"`
+	Definition     paymentfile.Layout `json:"definition"`
+	CreatedBy      *uuid.UUID         `json:"created_by,omitempty"`
+}
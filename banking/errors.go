@@ -0,0 +1,177 @@
+package banking
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// BankingErrors is the error registry for banking domain
+var BankingErrors = errx.NewRegistry("BANKING")
+
+// Banking error codes
+var (
+	// Statement errors
+	ErrStatementNotFound = BankingErrors.Register(
+		"STATEMENT_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Bank statement not found",
+	)
+
+	ErrStatementImportFailed = BankingErrors.Register(
+		"IMPORT_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to import bank statement",
+	)
+
+	ErrStatementAlreadyImported = BankingErrors.Register(
+		"ALREADY_IMPORTED",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Bank statement file was already imported",
+	)
+
+	ErrStatementInvalid = BankingErrors.Register(
+		"INVALID_STATEMENT",
+		errx.TypeValidation,
+		http.StatusUnprocessableEntity,
+		"Bank statement file could not be parsed",
+	)
+
+	// Query errors
+	ErrBankingListFailed = BankingErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list bank statements",
+	)
+
+	// Validation errors
+	ErrBankingValidationFailed = BankingErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Bank statement validation failed",
+	)
+
+	// Reconciliation errors
+	ErrMatchFailed = BankingErrors.Register(
+		"MATCH_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to match statement lines to invoices",
+	)
+
+	ErrSuggestionNotFound = BankingErrors.Register(
+		"SUGGESTION_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Reconciliation suggestion not found",
+	)
+
+	ErrSuggestionReviewed = BankingErrors.Register(
+		"SUGGESTION_REVIEWED",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Reconciliation suggestion was already reviewed",
+	)
+
+	ErrReconcileFailed = BankingErrors.Register(
+		"RECONCILE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to reconcile statement line",
+	)
+
+	// Payment batch errors
+	ErrBatchNotFound = BankingErrors.Register(
+		"BATCH_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Payment batch not found",
+	)
+
+	ErrBatchFailed = BankingErrors.Register(
+		"BATCH_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to store payment batch",
+	)
+
+	ErrInvoiceNotPayable = BankingErrors.Register(
+		"INVOICE_NOT_PAYABLE",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Invoice cannot be included in a payment batch",
+	)
+
+	ErrInvoiceChanged = BankingErrors.Register(
+		"INVOICE_CHANGED",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice changed while the payment batch was being generated",
+	)
+
+	ErrInvalidBatchFile = BankingErrors.Register(
+		"INVALID_BATCH_FILE",
+		errx.TypeValidation,
+		http.StatusUnprocessableEntity,
+		"Payment batch cannot be written in the requested format",
+	)
+
+	ErrBatchTransitionNotAllowed = BankingErrors.Register(
+		"BATCH_TRANSITION_NOT_ALLOWED",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Payment batch status transition is not allowed",
+	)
+
+	// Bank file layout errors
+	ErrLayoutNotFound = BankingErrors.Register(
+		"LAYOUT_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Bank file layout not found",
+	)
+
+	ErrLayoutNameExists = BankingErrors.Register(
+		"LAYOUT_NAME_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Bank file layout with this name already exists in the organization",
+	)
+
+	ErrLayoutInUse = BankingErrors.Register(
+		"LAYOUT_IN_USE",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Bank file layout is used by payment batches",
+	)
+)
+
+// Helper functions for error checking
+func IsStatementNotFound(err error) bool {
+	return errx.IsCode(err, ErrStatementNotFound)
+}
+
+func IsStatementAlreadyImported(err error) bool {
+	return errx.IsCode(err, ErrStatementAlreadyImported)
+}
+
+func IsSuggestionNotFound(err error) bool {
+	return errx.IsCode(err, ErrSuggestionNotFound)
+}
+
+func IsSuggestionReviewed(err error) bool {
+	return errx.IsCode(err, ErrSuggestionReviewed)
+}
+
+func IsBatchNotFound(err error) bool {
+	return errx.IsCode(err, ErrBatchNotFound)
+}
+
+func IsLayoutNotFound(err error) bool {
+	return errx.IsCode(err, ErrLayoutNotFound)
+}
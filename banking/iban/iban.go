@@ -0,0 +1,62 @@
+// Package iban normalizes and validates International Bank Account Numbers
+// (ISO 13616) and BICs (ISO 9362).
+package iban
+
+import (
+	"strings"
+)
+
+// Normalize removes spaces and upper-cases an IBAN or BIC as people write it
+// ("de89 3704 0044 0532 0130 00")
+func Normalize(value string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(value), ""))
+}
+
+// Valid reports whether a normalized IBAN is well formed and its check
+// digits verify: moving the first four characters to the end and reading
+// letters as 10–35 must leave a remainder of 1 modulo 97
+func Valid(value string) bool {
+	if len(value) < 15 || len(value) > 34 {
+		return false
+	}
+	for i, r := range value {
+		switch {
+		case i < 2 && (r < 'A' || r > 'Z'):
+			return false
+		case i >= 2 && i < 4 && (r < '0' || r > '9'):
+			return false
+		case !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9'):
+			return false
+		}
+	}
+
+	remainder := 0
+	for _, r := range value[4:] + value[:4] {
+		if r >= 'A' {
+			n := int(r-'A') + 10
+			remainder = (remainder*100 + n) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+// ValidBIC reports whether a normalized BIC has the 8 or 11 character
+// layout: bank code, country code, location code and optional branch code
+func ValidBIC(value string) bool {
+	if len(value) != 8 && len(value) != 11 {
+		return false
+	}
+	for i, r := range value {
+		letter := r >= 'A' && r <= 'Z'
+		digit := r >= '0' && r <= '9'
+		if i < 6 && !letter {
+			return false
+		}
+		if i >= 6 && !letter && !digit {
+			return false
+		}
+	}
+	return true
+}
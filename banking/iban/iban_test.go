@@ -0,0 +1,72 @@
+package iban
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct{ value, want string }{
+		{"de89 3704 0044 0532 0130 00", "DE89370400440532013000"},
+		{"  GB82 WEST\t1234 5698 7654 32 ", "GB82WEST12345698765432"},
+		{"cobadeffxxx", "COBADEFFXXX"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.value); got != tt.want {
+			t.Errorf("Normalize(%q) = %q; want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"DE89370400440532013000", true},
+		{"GB82WEST12345698765432", true},
+		{"ES9121000418450200051332", true},
+		{"FR1420041010050500013M02606", true},
+		{"NO9386011117947", true},                       // shortest in use
+		{"MT84MALT011000012345MTLCAST001S", true},       // letters in the account
+		{"DE88370400440532013000", false},               // check digits
+		{"DE89370400440532013001", false},               // account digit
+		{"GB82WEST12345698765423", false},               // transposed digits
+		{"de89370400440532013000", false},               // not normalized
+		{"DE89 3704 0044 0532 0130 00", false},          // not normalized
+		{"D189370400440532013000", false},               // country code
+		{"DEXX370400440532013000", false},               // check digits not numeric
+		{"DE8937040044053201300-", false},               // punctuation
+		{"NO938601111794", false},                       // too short
+		{"DE8937040044053201300000000000000000", false}, // too long
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.value); got != tt.want {
+			t.Errorf("Valid(%q) = %v; want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValidBIC(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"COBADEFF", true},
+		{"COBADEFFXXX", true},
+		{"BCONPEPL", true},
+		{"DEUTDE2H", true},    // digit in the location code
+		{"CAIXESBB001", true}, // branch code
+		{"COBADEF", false},
+		{"COBADEFFXX", false},
+		{"COB4DEFF", false}, // digit in the bank code
+		{"COBAD3FF", false}, // digit in the country code
+		{"cobadeff", false}, // not normalized
+		{"COBADEFF-XX", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidBIC(tt.value); got != tt.want {
+			t.Errorf("ValidBIC(%q) = %v; want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package matcher proposes which open invoices a bank statement line pays.
+// Each candidate invoice is scored on four signals and the best ones above a
+// threshold become suggestions for a person to accept or reject:
+//
+//	amount          the line amount equals the outstanding amount   40
+//	invoice_number  the invoice number appears in the line text     30
+//	provider_tax_id the provider's tax ID appears in the line text  20
+//	date_window     the line is booked close to the invoice dates   10
+package matcher
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Reason is a signal that links a statement line to an invoice
+type Reason string
+
+// Matching signals
+const (
+	ReasonAmount        Reason = "amount"
+	ReasonInvoiceNumber Reason = "invoice_number"
+	ReasonTaxID         Reason = "provider_tax_id"
+	ReasonDateWindow    Reason = "date_window"
+)
+
+// weights are the points each signal adds to a score out of 100
+var weights = map[Reason]int{
+	ReasonAmount:        40,
+	ReasonInvoiceNumber: 30,
+	ReasonTaxID:         20,
+	ReasonDateWindow:    10,
+}
+
+// Short references match too much unrelated text to count as a signal
+const (
+	minInvoiceNumberLength = 4
+	minTaxIDLength         = 6
+)
+
+// Line is the part of a statement line the matcher looks at. Text holds the
+// remittance information, counterparty name and references.
+type Line struct {
+	Amount   decimal.Decimal
+	Currency string
+	Date     time.Time
+	Text     string
+}
+
+// Invoice is an open invoice that a line may pay
+type Invoice struct {
+	ID            uuid.UUID
+	ProviderID    *uuid.UUID
+	InvoiceNumber string
+	ProviderTaxID string
+	Currency      string
+	Outstanding   decimal.Decimal
+	InvoiceDate   *time.Time
+	DueDate       *time.Time
+}
+
+// Options tune the matcher
+type Options struct {
+	// DateWindowDays is how far before the invoice date and after the due
+	// date a payment still counts as close
+	DateWindowDays int
+
+	// MinScore is the lowest score proposed
+	MinScore int
+
+	// MaxSuggestions caps the suggestions per line
+	MaxSuggestions int
+}
+
+// DefaultOptions returns the options used when none are configured: a
+// suggestion needs two strong signals, or the exact amount inside the window
+func DefaultOptions() Options {
+	return Options{
+		DateWindowDays: 30,
+		MinScore:       50,
+		MaxSuggestions: 3,
+	}
+}
+
+// Suggestion proposes that a line pays an invoice. Amount is what accepting
+// it applies: the line amount, capped at the outstanding amount.
+type Suggestion struct {
+	InvoiceID  uuid.UUID
+	ProviderID *uuid.UUID
+	Score      int
+	Amount     decimal.Decimal
+	Reasons    []Reason
+}
+
+// Match scores every invoice in the line's currency and returns the best
+// suggestions, highest score first. Ties go to the invoice whose outstanding
+// amount is closest to the line amount.
+func Match(line Line, invoices []Invoice, opts Options) []Suggestion {
+	if !line.Amount.IsPositive() {
+		return nil
+	}
+
+	text := compactText(line.Text)
+
+	type scored struct {
+		Suggestion
+		distance decimal.Decimal
+	}
+	var candidates []scored
+
+	for _, invoice := range invoices {
+		if !strings.EqualFold(invoice.Currency, line.Currency) || !invoice.Outstanding.IsPositive() {
+			continue
+		}
+
+		var reasons []Reason
+		if line.Amount.Equal(invoice.Outstanding) {
+			reasons = append(reasons, ReasonAmount)
+		}
+		if containsInvoiceNumber(text, invoice.InvoiceNumber) {
+			reasons = append(reasons, ReasonInvoiceNumber)
+		}
+		if taxID := compact(invoice.ProviderTaxID); len(taxID) >= minTaxIDLength && text.contains(taxID) {
+			reasons = append(reasons, ReasonTaxID)
+		}
+		if inWindow(line.Date, invoice, opts.DateWindowDays) {
+			reasons = append(reasons, ReasonDateWindow)
+		}
+
+		score := 0
+		for _, reason := range reasons {
+			score += weights[reason]
+		}
+		if score < opts.MinScore {
+			continue
+		}
+
+		candidates = append(candidates, scored{
+			Suggestion: Suggestion{
+				InvoiceID:  invoice.ID,
+				ProviderID: invoice.ProviderID,
+				Score:      score,
+				Amount:     decimal.Min(line.Amount, invoice.Outstanding),
+				Reasons:    reasons,
+			},
+			distance: line.Amount.Sub(invoice.Outstanding).Abs(),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.distance.Equal(b.distance) {
+			return a.distance.LessThan(b.distance)
+		}
+		return a.InvoiceID.String() < b.InvoiceID.String()
+	})
+
+	if opts.MaxSuggestions > 0 && len(candidates) > opts.MaxSuggestions {
+		candidates = candidates[:opts.MaxSuggestions]
+	}
+
+	suggestions := make([]Suggestion, len(candidates))
+	for i, c := range candidates {
+		suggestions[i] = c.Suggestion
+	}
+	return suggestions
+}
+
+// lineText is compacted line text that remembers which characters followed
+// whitespace, so references separated by spaces stay apart
+type lineText struct {
+	text string
+
+	// spaced[i] tells whether whitespace came before text[i]
+	spaced []bool
+}
+
+// compactText compacts line text like compact
+func compactText(value string) lineText {
+	var line lineText
+	var b strings.Builder
+	space := false
+	for _, r := range value {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			upper := string(unicode.ToUpper(r))
+			b.WriteString(upper)
+			for range len(upper) {
+				line.spaced = append(line.spaced, space)
+				space = false
+			}
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+	line.text = b.String()
+	return line
+}
+
+// containsInvoiceNumber looks for an invoice number in compacted text, also
+// in the short form banks often use: "F001-00000123" written as "F001-123"
+func containsInvoiceNumber(text lineText, number string) bool {
+	full := compact(number)
+	if len(full) < minInvoiceNumberLength {
+		return false
+	}
+	if text.contains(full) {
+		return true
+	}
+
+	series, sequence, found := strings.Cut(strings.ToUpper(strings.TrimSpace(number)), "-")
+	if !found {
+		return false
+	}
+	short := compact(series) + strings.TrimLeft(compact(sequence), "0")
+	return len(short) >= minInvoiceNumberLength && short != full && text.contains(short)
+}
+
+// contains looks for a compacted reference in the text. A reference starting
+// or ending in a digit must not continue a longer number, so "F001123" is
+// not found in "F0011234", but is in "F001123 20123456789".
+func (l lineText) contains(reference string) bool {
+	text := l.text
+	first := rune(reference[0])
+	last := rune(reference[len(reference)-1])
+
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], reference)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(reference)
+
+		before := start > 0 && !l.spaced[start] && unicode.IsDigit(first) && isDigit(text[start-1])
+		after := end < len(text) && !l.spaced[end] && unicode.IsDigit(last) && isDigit(text[end])
+		if !before && !after {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// inWindow reports whether a date falls between the invoice date and the due
+// date, widened by the window on both sides
+func inWindow(date time.Time, invoice Invoice, windowDays int) bool {
+	from := invoice.InvoiceDate
+	to := invoice.DueDate
+	if from == nil {
+		from = to
+	}
+	if to == nil {
+		to = from
+	}
+	if from == nil {
+		return false
+	}
+
+	window := time.Duration(windowDays) * 24 * time.Hour
+	return !date.Before(from.Add(-window)) && !date.After(to.Add(window))
+}
+
+// compact upper-cases text and drops everything but letters and digits, so
+// "f001 - 000123" and "F001-000123" compare equal
+func compact(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
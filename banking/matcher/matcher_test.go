@@ -0,0 +1,189 @@
+package matcher
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func date(value string) *time.Time {
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func amount(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func invoice(number, taxID, outstanding string) Invoice {
+	return Invoice{
+		ID:            uuid.New(),
+		InvoiceNumber: number,
+		ProviderTaxID: taxID,
+		Currency:      "PEN",
+		Outstanding:   amount(outstanding),
+		InvoiceDate:   date("2023-02-01"),
+		DueDate:       date("2023-02-28"),
+	}
+}
+
+func TestMatchExact(t *testing.T) {
+	paid := invoice("F001-00000045", "20111111111", "400.00")
+	other := invoice("F001-00000046", "20222222222", "400.00")
+
+	line := Line{
+		Amount:   amount("400"),
+		Currency: "pen",
+		Date:     *date("2023-02-10"),
+		Text:     "Proveedor Uno f001 - 00000045 RUC 20111111111",
+	}
+
+	suggestions := Match(line, []Invoice{other, paid}, DefaultOptions())
+	if len(suggestions) != 2 {
+		t.Fatalf("got %d suggestions; want the exact match and the same amount", len(suggestions))
+	}
+
+	best := suggestions[0]
+	if best.InvoiceID != paid.ID {
+		t.Fatalf("best suggestion is %s; want the invoice named in the text", best.InvoiceID)
+	}
+	if best.Score != 100 {
+		t.Errorf("score = %d; want 100", best.Score)
+	}
+	wantReasons := []Reason{ReasonAmount, ReasonInvoiceNumber, ReasonTaxID, ReasonDateWindow}
+	if !slices.Equal(best.Reasons, wantReasons) {
+		t.Errorf("reasons = %v; want %v", best.Reasons, wantReasons)
+	}
+	if !best.Amount.Equal(amount("400")) {
+		t.Errorf("amount = %s; want 400", best.Amount)
+	}
+
+	if suggestions[1].InvoiceID != other.ID || suggestions[1].Score != 50 {
+		t.Errorf("second suggestion = %s scoring %d; want the other invoice scoring 50", suggestions[1].InvoiceID, suggestions[1].Score)
+	}
+}
+
+func TestMatchPartial(t *testing.T) {
+	open := invoice("F001-00000123", "20123456789", "1000.00")
+
+	tests := []struct {
+		name       string
+		line       Line
+		wantScore  int
+		wantAmount string
+	}{
+		{
+			// Banks shorten numbers: F001-00000123 written as F001-123
+			name:       "part payment naming the short number",
+			line:       Line{Amount: amount("250.00"), Currency: "PEN", Date: *date("2023-02-15"), Text: "Pago parcial F001-123"},
+			wantScore:  40,
+			wantAmount: "250.00",
+		},
+		{
+			name:       "part payment naming number and provider",
+			line:       Line{Amount: amount("250.00"), Currency: "PEN", Date: *date("2023-02-15"), Text: "F001-123 RUC 20123456789"},
+			wantScore:  60,
+			wantAmount: "250.00",
+		},
+		{
+			// Overpayments only apply the outstanding amount
+			name:       "overpayment",
+			line:       Line{Amount: amount("1200.00"), Currency: "PEN", Date: *date("2023-02-15"), Text: "F001-00000123 20123456789"},
+			wantScore:  60,
+			wantAmount: "1000.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.MinScore = 40
+
+			suggestions := Match(tt.line, []Invoice{open}, opts)
+			if len(suggestions) != 1 {
+				t.Fatalf("got %d suggestions; want 1", len(suggestions))
+			}
+			if suggestions[0].Score != tt.wantScore {
+				t.Errorf("score = %d (%v); want %d", suggestions[0].Score, suggestions[0].Reasons, tt.wantScore)
+			}
+			if !suggestions[0].Amount.Equal(amount(tt.wantAmount)) {
+				t.Errorf("amount = %s; want %s", suggestions[0].Amount, tt.wantAmount)
+			}
+		})
+	}
+
+	// With the default threshold a reference alone is not enough
+	line := Line{Amount: amount("250.00"), Currency: "PEN", Date: *date("2023-06-15"), Text: "F001-123"}
+	if suggestions := Match(line, []Invoice{open}, DefaultOptions()); len(suggestions) != 0 {
+		t.Errorf("got %d suggestions for a reference outside the window; want none", len(suggestions))
+	}
+}
+
+func TestMatchAmbiguous(t *testing.T) {
+	// Same amount and dates, nothing in the text tells them apart
+	invoices := []Invoice{
+		invoice("F001-00000001", "20111111111", "300.00"),
+		invoice("F001-00000002", "20222222222", "300.00"),
+		invoice("F001-00000003", "20333333333", "300.00"),
+		invoice("F001-00000004", "20444444444", "300.00"),
+	}
+	line := Line{Amount: amount("300.00"), Currency: "PEN", Date: *date("2023-02-20"), Text: "TRANSFERENCIA"}
+
+	opts := DefaultOptions()
+	suggestions := Match(line, invoices, opts)
+	if len(suggestions) != opts.MaxSuggestions {
+		t.Fatalf("got %d suggestions; want them capped at %d", len(suggestions), opts.MaxSuggestions)
+	}
+	for i, s := range suggestions {
+		if s.Score != 50 {
+			t.Errorf("suggestion %d scores %d; want 50 for amount and date", i, s.Score)
+		}
+		if i > 0 && suggestions[i-1].InvoiceID.String() > s.InvoiceID.String() {
+			t.Errorf("ties are not ordered by invoice ID")
+		}
+	}
+
+	// The same tie is broken by the outstanding amount closest to the line
+	closer := invoice("F001-00000005", "20555555555", "310.00")
+	farther := invoice("F001-00000006", "20666666666", "400.00")
+	line = Line{Amount: amount("305.00"), Currency: "PEN", Date: *date("2023-02-20"), Text: "F001-5 F001-6"}
+
+	opts.MinScore = 40
+	suggestions = Match(line, []Invoice{farther, closer}, opts)
+	if len(suggestions) != 2 || suggestions[0].InvoiceID != closer.ID {
+		t.Errorf("got %v; want the closer amount first", suggestions)
+	}
+}
+
+func TestMatchExcludes(t *testing.T) {
+	dollars := invoice("F001-00000009", "20999999999", "100.00")
+	dollars.Currency = "USD"
+	paid := invoice("F001-00000010", "20101010101", "0")
+	longer := invoice("F001123", "", "100.00")
+
+	tests := []struct {
+		name     string
+		line     Line
+		invoices []Invoice
+	}{
+		{"other currency", Line{Amount: amount("100"), Currency: "PEN", Date: *date("2023-02-10"), Text: "F001-00000009"}, []Invoice{dollars}},
+		{"nothing outstanding", Line{Amount: amount("100"), Currency: "PEN", Date: *date("2023-02-10"), Text: "F001-00000010"}, []Invoice{paid}},
+		{"incoming refund", Line{Amount: amount("-100"), Currency: "PEN", Date: *date("2023-02-10")}, []Invoice{longer}},
+		// F001123 is not found inside the longer number F0011234
+		{"number inside a longer number", Line{Amount: amount("99"), Currency: "PEN", Date: *date("2023-02-10"), Text: "F0011234"}, []Invoice{longer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if suggestions := Match(tt.line, tt.invoices, DefaultOptions()); len(suggestions) != 0 {
+				t.Errorf("got %d suggestions; want none", len(suggestions))
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/banking/paymentfile"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// PayableStatus is the invoice status of the default workflow that allows an
+// invoice to be scheduled for payment
+const PayableStatus = "approved"
+
+// BatchFormat is the kind of file a payment batch is written as
+type BatchFormat string
+
+// Supported payment batch formats
+const (
+	BatchFormatPain001 BatchFormat = "pain001"
+	BatchFormatLayout  BatchFormat = "layout"
+)
+
+// Valid reports whether the format is supported
+func (f BatchFormat) Valid() bool {
+	return f == BatchFormatPain001 || f == BatchFormatLayout
+}
+
+// BatchStatus is where a payment batch is in its lifecycle
+type BatchStatus string
+
+// Payment batch statuses. Generated and sent batches keep their invoices
+// scheduled; completing a batch records its payments and cancelling it
+// releases the invoices.
+const (
+	BatchGenerated BatchStatus = "generated"
+	BatchSent      BatchStatus = "sent"
+	BatchCompleted BatchStatus = "completed"
+	BatchCancelled BatchStatus = "cancelled"
+)
+
+var batchTransitions = map[BatchStatus][]BatchStatus{
+	BatchGenerated: {BatchSent, BatchCompleted, BatchCancelled},
+	BatchSent:      {BatchCompleted, BatchCancelled},
+}
+
+// Valid reports whether the status is known
+func (s BatchStatus) Valid() bool {
+	switch s {
+	case BatchGenerated, BatchSent, BatchCompleted, BatchCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransition reports whether a batch can move to the given status
+func (s BatchStatus) CanTransition(to BatchStatus) bool {
+	return slices.Contains(batchTransitions[s], to)
+}
+
+// AllowedTransitions returns the statuses reachable from this one
+func (s BatchStatus) AllowedTransitions() []BatchStatus {
+	targets := batchTransitions[s]
+	if targets == nil {
+		return []BatchStatus{}
+	}
+	return targets
+}
+
+// PaymentBatch represents a generated outbound payment file
+type PaymentBatch struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	OrganizationID uuid.UUID       `db:"organization_id" json:"organization_id"`
+	Format         BatchFormat     `db:"format" json:"format"`
+	LayoutID       *uuid.UUID      `db:"layout_id" json:"layout_id,omitempty"`
+	Status         BatchStatus     `db:"status" json:"status"`
+	CurrencyCode   string          `db:"currency_code" json:"currency_code"`
+	ExecutionDate  time.Time       `db:"execution_date" json:"execution_date"`
+	ItemCount      int             `db:"item_count" json:"item_count"`
+	TotalAmount    decimal.Decimal `db:"total_amount" json:"total_amount"`
+
+	DebtorName          string  `db:"debtor_name" json:"debtor_name"`
+	DebtorIBAN          *string `db:"debtor_iban" json:"debtor_iban,omitempty"`
+	DebtorAccountNumber *string `db:"debtor_account_number" json:"debtor_account_number,omitempty"`
+	DebtorBIC           *string `db:"debtor_bic" json:"debtor_bic,omitempty"`
+
+	FileName    string `db:"file_name" json:"file_name"`
+	FileContent []byte `db:"file_content" json:"-"`
+	FileSHA256  string `db:"file_sha256" json:"file_sha256"`
+
+	CreatedBy       *uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	StatusChangedBy *uuid.UUID `db:"status_changed_by" json:"status_changed_by,omitempty"`
+	StatusChangedAt *time.Time `db:"status_changed_at" json:"status_changed_at,omitempty"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+
+	// Items are stored in payment_batch_items
+	Items []BatchItem `db:"-" json:"items,omitempty"`
+}
+
+// TableName returns the table name for the PaymentBatch model
+func (b PaymentBatch) TableName() string {
+	return "payment_batches"
+}
+
+// IsLive reports whether the batch still holds its invoices scheduled
+func (b PaymentBatch) IsLive() bool {
+	return b.Status == BatchGenerated || b.Status == BatchSent
+}
+
+// Debtor returns the account the batch is paid from
+func (b PaymentBatch) Debtor() paymentfile.Account {
+	return paymentfile.Account{
+		Name:          b.DebtorName,
+		IBAN:          deref(b.DebtorIBAN),
+		AccountNumber: deref(b.DebtorAccountNumber),
+		BIC:           deref(b.DebtorBIC),
+	}
+}
+
+// BatchItem is the credit transfer paying one invoice of a batch
+type BatchItem struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	BatchID       uuid.UUID  `db:"batch_id" json:"batch_id"`
+	Sequence      int        `db:"sequence" json:"sequence"`
+	InvoiceID     uuid.UUID  `db:"invoice_id" json:"invoice_id"`
+	InvoiceNumber *string    `db:"invoice_number" json:"invoice_number"`
+	ProviderID    *uuid.UUID `db:"provider_id" json:"provider_id"`
+	BankAccountID *uuid.UUID `db:"bank_account_id" json:"bank_account_id"`
+
+	EndToEndID            string          `db:"end_to_end_id" json:"end_to_end_id"`
+	Amount                decimal.Decimal `db:"amount" json:"amount"`
+	CurrencyCode          string          `db:"currency_code" json:"currency_code"`
+	CreditorName          string          `db:"creditor_name" json:"creditor_name"`
+	CreditorIBAN          *string         `db:"creditor_iban" json:"creditor_iban,omitempty"`
+	CreditorAccountNumber *string         `db:"creditor_account_number" json:"creditor_account_number,omitempty"`
+	CreditorBIC           *string         `db:"creditor_bic" json:"creditor_bic,omitempty"`
+	RemittanceInfo        *string         `db:"remittance_info" json:"remittance_info"`
+
+	PaymentID *uuid.UUID `db:"payment_id" json:"payment_id,omitempty"`
+}
+
+// TableName returns the table name for the BatchItem model
+func (i BatchItem) TableName() string {
+	return "payment_batch_items"
+}
+
+// PaymentFileItem converts the item into the input of the file writers
+func (i BatchItem) PaymentFileItem() paymentfile.Item {
+	return paymentfile.Item{
+		Sequence:   i.Sequence,
+		EndToEndID: i.EndToEndID,
+		Amount:     i.Amount,
+		Creditor: paymentfile.Account{
+			Name:          i.CreditorName,
+			IBAN:          deref(i.CreditorIBAN),
+			AccountNumber: deref(i.CreditorAccountNumber),
+			BIC:           deref(i.CreditorBIC),
+		},
+		InvoiceNumber: deref(i.InvoiceNumber),
+		Remittance:    deref(i.RemittanceInfo),
+	}
+}
+
+// PayableInvoice is an invoice read for scheduling: its state, what is still
+// owed on it and the provider's default bank account
+type PayableInvoice struct {
+	InvoiceID         uuid.UUID       `db:"invoice_id"`
+	OrganizationID    uuid.UUID       `db:"organization_id"`
+	ProviderID        *uuid.UUID      `db:"provider_id"`
+	ProviderName      *string         `db:"provider_name"`
+	InvoiceNumber     *string         `db:"invoice_number"`
+	DocumentKind      string          `db:"document_kind"`
+	Status            *string         `db:"status"`
+	IsDeleted         bool            `db:"is_deleted"`
+	CurrencyCode      *string         `db:"currency_code"`
+	OutstandingAmount decimal.Decimal `db:"outstanding_amount"`
+	PaymentBatchID    *uuid.UUID      `db:"payment_batch_id"`
+
+	BankAccountID       *uuid.UUID `db:"bank_account_id"`
+	AccountHolder       *string    `db:"account_holder"`
+	IBAN                *string    `db:"iban"`
+	AccountNumber       *string    `db:"account_number"`
+	BIC                 *string    `db:"bic"`
+	AccountCurrencyCode *string    `db:"account_currency_code"`
+}
+
+// Problem explains why the invoice cannot be scheduled in a batch of the
+// organization and currency, or returns an empty string when it can
+func (p PayableInvoice) Problem(orgID uuid.UUID, currency string) string {
+	switch {
+	case p.OrganizationID != orgID:
+		return "invoice belongs to another organization"
+	case p.IsDeleted:
+		return "invoice is deleted"
+	case p.DocumentKind != string(typemodels.KindInvoice):
+		return "only invoices can be paid, not " + p.DocumentKind + "s"
+	case p.Status == nil || *p.Status != PayableStatus:
+		return "invoice is not approved"
+	case p.CurrencyCode == nil || !strings.EqualFold(*p.CurrencyCode, currency):
+		return "invoice currency differs from the batch currency"
+	case !p.OutstandingAmount.IsPositive():
+		return "invoice has nothing outstanding"
+	case p.PaymentBatchID != nil:
+		return "invoice is already scheduled in payment batch " + p.PaymentBatchID.String()
+	case p.ProviderID == nil:
+		return "invoice has no provider"
+	case p.BankAccountID == nil:
+		return "provider has no default bank account"
+	case p.AccountCurrencyCode != nil && !strings.EqualFold(*p.AccountCurrencyCode, currency):
+		return "provider bank account is held in another currency"
+	}
+	return ""
+}
+
+// BankFileLayout is a saved CSV or fixed-width bank payment file layout
+type BankFileLayout struct {
+	ID             uuid.UUID        `db:"id" json:"id"`
+	OrganizationID uuid.UUID        `db:"organization_id" json:"organization_id"`
+	Name           string           `db:"name" json:"name"`
+	Kind           paymentfile.Kind `db:"kind" json:"kind"`
+	Definition     LayoutDefinition `db:"definition" json:"definition"`
+	CreatedBy      *uuid.UUID       `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time        `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the BankFileLayout model
+func (l BankFileLayout) TableName() string {
+	return "bank_file_layouts"
+}
+
+// LayoutDefinition stores a paymentfile.Layout as JSONB
+type LayoutDefinition paymentfile.Layout
+
+// Layout returns the definition as a renderable layout
+func (d LayoutDefinition) Layout() paymentfile.Layout {
+	return paymentfile.Layout(d)
+}
+
+// Value implements the driver.Valuer interface for database storage
+func (d LayoutDefinition) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (d *LayoutDefinition) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return fmt.Errorf("cannot scan %T into LayoutDefinition", value)
+	}
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/banking/matcher"
+	"github.com/Abraxas-365/fuckturamelo/banking/statement"
+)
+
+// Statement represents an imported bank statement
+type Statement struct {
+	ID                 uuid.UUID        `db:"id" json:"id"`
+	OrganizationID     uuid.UUID        `db:"organization_id" json:"organization_id"`
+	Format             statement.Format `db:"format" json:"format"`
+	AccountIdentifier  string           `db:"account_identifier" json:"account_identifier"`
+	CurrencyCode       string           `db:"currency_code" json:"currency_code"`
+	StatementReference *string          `db:"statement_reference" json:"statement_reference"`
+	OpeningBalance     *decimal.Decimal `db:"opening_balance" json:"opening_balance"`
+	OpeningDate        *time.Time       `db:"opening_date" json:"opening_date"`
+	ClosingBalance     *decimal.Decimal `db:"closing_balance" json:"closing_balance"`
+	ClosingDate        *time.Time       `db:"closing_date" json:"closing_date"`
+	PeriodStart        *time.Time       `db:"period_start" json:"period_start"`
+	PeriodEnd          *time.Time       `db:"period_end" json:"period_end"`
+
+	FileName   *string `db:"file_name" json:"file_name"`
+	FileSHA256 string  `db:"file_sha256" json:"file_sha256"`
+	FileIndex  int     `db:"file_index" json:"file_index"`
+
+	ImportedBy *uuid.UUID `db:"imported_by" json:"imported_by"`
+	ImportedAt time.Time  `db:"imported_at" json:"imported_at"`
+
+	// Lines are stored in bank_statement_lines
+	Lines []StatementLine `db:"-" json:"lines,omitempty"`
+}
+
+// TableName returns the table name for the Statement model
+func (s Statement) TableName() string {
+	return "bank_statements"
+}
+
+// StatementSummary counts the lines of a statement per reconciliation status
+type StatementSummary struct {
+	LineCount      int `db:"line_count" json:"line_count"`
+	UnmatchedCount int `db:"unmatched_count" json:"unmatched_count"`
+	SuggestedCount int `db:"suggested_count" json:"suggested_count"`
+	MatchedCount   int `db:"matched_count" json:"matched_count"`
+}
+
+// LineStatus is how far a statement line has been reconciled
+type LineStatus string
+
+// Statement line statuses
+const (
+	LineUnmatched LineStatus = "unmatched"
+	LineSuggested LineStatus = "suggested"
+	LineMatched   LineStatus = "matched"
+)
+
+// Valid reports whether the status is known
+func (s LineStatus) Valid() bool {
+	return s == LineUnmatched || s == LineSuggested || s == LineMatched
+}
+
+// StatementLine represents a booked movement of a statement. Amount is
+// positive; debits are money paid out of the account.
+type StatementLine struct {
+	ID                  uuid.UUID           `db:"id" json:"id"`
+	StatementID         uuid.UUID           `db:"statement_id" json:"statement_id"`
+	OrganizationID      uuid.UUID           `db:"organization_id" json:"organization_id"`
+	LineNumber          int                 `db:"line_number" json:"line_number"`
+	BookingDate         time.Time           `db:"booking_date" json:"booking_date"`
+	ValueDate           *time.Time          `db:"value_date" json:"value_date"`
+	Amount              decimal.Decimal     `db:"amount" json:"amount"`
+	Direction           statement.Direction `db:"direction" json:"direction"`
+	CurrencyCode        string              `db:"currency_code" json:"currency_code"`
+	BankReference       *string             `db:"bank_reference" json:"bank_reference"`
+	EndToEndID          *string             `db:"end_to_end_id" json:"end_to_end_id"`
+	CounterpartyName    *string             `db:"counterparty_name" json:"counterparty_name"`
+	CounterpartyAccount *string             `db:"counterparty_account" json:"counterparty_account"`
+	RemittanceInfo      *string             `db:"remittance_info" json:"remittance_info"`
+
+	Status    LineStatus `db:"status" json:"status"`
+	PaymentID *uuid.UUID `db:"payment_id" json:"payment_id"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the StatementLine model
+func (l StatementLine) TableName() string {
+	return "bank_statement_lines"
+}
+
+// IsPayment reports whether the line is money paid out, the only kind of
+// line that can settle provider invoices
+func (l StatementLine) IsPayment() bool {
+	return l.Direction == statement.Debit
+}
+
+// Reference is what identifies the transfer to the bank and the provider
+func (l StatementLine) Reference() *string {
+	if l.EndToEndID != nil {
+		return l.EndToEndID
+	}
+	return l.BankReference
+}
+
+// MatcherLine converts the line into the input of the matcher
+func (l StatementLine) MatcherLine() matcher.Line {
+	var parts []string
+	for _, part := range []*string{l.RemittanceInfo, l.CounterpartyName, l.EndToEndID, l.BankReference} {
+		if part != nil {
+			parts = append(parts, *part)
+		}
+	}
+
+	return matcher.Line{
+		Amount:   l.Amount,
+		Currency: l.CurrencyCode,
+		Date:     l.BookingDate,
+		Text:     strings.Join(parts, " "),
+	}
+}
+
+// SuggestionStatus is the review state of a suggestion
+type SuggestionStatus string
+
+// Suggestion statuses
+const (
+	SuggestionPending  SuggestionStatus = "pending"
+	SuggestionAccepted SuggestionStatus = "accepted"
+	SuggestionRejected SuggestionStatus = "rejected"
+)
+
+// Valid reports whether the status is known
+func (s SuggestionStatus) Valid() bool {
+	return s == SuggestionPending || s == SuggestionAccepted || s == SuggestionRejected
+}
+
+// Suggestion proposes that a statement line pays an invoice
+type Suggestion struct {
+	ID         uuid.UUID        `db:"id" json:"id"`
+	LineID     uuid.UUID        `db:"line_id" json:"line_id"`
+	InvoiceID  uuid.UUID        `db:"invoice_id" json:"invoice_id"`
+	ProviderID *uuid.UUID       `db:"provider_id" json:"provider_id"`
+	Score      int              `db:"score" json:"score"`
+	Amount     decimal.Decimal  `db:"amount" json:"amount"`
+	Reasons    pq.StringArray   `db:"reasons" json:"reasons"`
+	Status     SuggestionStatus `db:"status" json:"status"`
+	ReviewedBy *uuid.UUID       `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt *time.Time       `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt  time.Time        `db:"created_at" json:"created_at"`
+
+	// Read from the invoice for display
+	InvoiceNumber *string `db:"invoice_number" json:"invoice_number"`
+}
+
+// TableName returns the table name for the Suggestion model
+func (s Suggestion) TableName() string {
+	return "bank_reconciliation_suggestions"
+}
+
+// NewSuggestion records a matcher suggestion for a line
+func NewSuggestion(lineID uuid.UUID, s matcher.Suggestion) Suggestion {
+	reasons := make(pq.StringArray, len(s.Reasons))
+	for i, reason := range s.Reasons {
+		reasons[i] = string(reason)
+	}
+
+	return Suggestion{
+		ID:         uuid.New(),
+		LineID:     lineID,
+		InvoiceID:  s.InvoiceID,
+		ProviderID: s.ProviderID,
+		Score:      s.Score,
+		Amount:     s.Amount,
+		Reasons:    reasons,
+		Status:     SuggestionPending,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,159 @@
+// Package paymentfile renders outbound payment batches as ISO 20022 pain.001
+// credit transfer initiations or as the CSV and fixed-width layouts banks
+// accept for bulk payments. It has no database dependencies; the banking
+// service feeds it snapshots of the invoices being paid.
+package paymentfile
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Account identifies a party of a credit transfer. IBAN is preferred; the
+// account number is used by domestic schemes without IBANs.
+type Account struct {
+	Name          string
+	IBAN          string
+	AccountNumber string
+	BIC           string
+}
+
+// Identifier returns the IBAN, or the account number when there is none
+func (a Account) Identifier() string {
+	if a.IBAN != "" {
+		return a.IBAN
+	}
+	return a.AccountNumber
+}
+
+// Batch is a set of credit transfers paid from one account on one date
+type Batch struct {
+	// MessageID identifies the file at the bank (max 35 characters)
+	MessageID     string
+	CreatedAt     time.Time
+	ExecutionDate time.Time
+	Currency      string
+	Debtor        Account
+	Items         []Item
+}
+
+// Item is a single credit transfer paying one invoice
+type Item struct {
+	Sequence      int
+	EndToEndID    string
+	Amount        decimal.Decimal
+	Creditor      Account
+	InvoiceNumber string
+
+	// Remittance is the free text the creditor sees on their statement
+	Remittance string
+}
+
+// Total adds up the amounts of the batch items
+func (b Batch) Total() decimal.Decimal {
+	total := decimal.Zero
+	for _, item := range b.Items {
+		total = total.Add(item.Amount)
+	}
+	return total
+}
+
+// Error describes batch data that cannot be written to a payment file.
+// Item is the 1-based sequence of the offending item, or 0 for the batch.
+type Error struct {
+	Item    int    `json:"item,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Item > 0 {
+		return fmt.Sprintf("item %d %s: %s", e.Item, e.Field, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// validate checks what every file format needs: accounts, positive amounts
+// with at most two decimals and a message identifier
+func (b Batch) validate() error {
+	switch {
+	case b.MessageID == "":
+		return &Error{Field: "message_id", Message: "is required"}
+	case len(b.MessageID) > 35:
+		return &Error{Field: "message_id", Message: "must not exceed 35 characters"}
+	case len(b.Currency) != 3:
+		return &Error{Field: "currency", Message: "must be a 3-letter ISO 4217 code"}
+	case b.ExecutionDate.IsZero():
+		return &Error{Field: "execution_date", Message: "is required"}
+	case b.Debtor.Name == "":
+		return &Error{Field: "debtor_name", Message: "is required"}
+	case b.Debtor.Identifier() == "":
+		return &Error{Field: "debtor_account", Message: "is required"}
+	case len(b.Items) == 0:
+		return &Error{Field: "items", Message: "must not be empty"}
+	}
+
+	for _, item := range b.Items {
+		switch {
+		case !item.Amount.IsPositive():
+			return &Error{Item: item.Sequence, Field: "amount", Message: "must be greater than zero"}
+		case !item.Amount.Equal(item.Amount.Round(2)):
+			return &Error{Item: item.Sequence, Field: "amount", Message: "must not have more than 2 decimals"}
+		case item.EndToEndID == "":
+			return &Error{Item: item.Sequence, Field: "end_to_end_id", Message: "is required"}
+		case len(item.EndToEndID) > 35:
+			return &Error{Item: item.Sequence, Field: "end_to_end_id", Message: "must not exceed 35 characters"}
+		case item.Creditor.Name == "":
+			return &Error{Item: item.Sequence, Field: "creditor_name", Message: "is required"}
+		case item.Creditor.Identifier() == "":
+			return &Error{Item: item.Sequence, Field: "creditor_account", Message: "is required"}
+		}
+	}
+
+	return nil
+}
+
+// latinText maps the accented letters most common in names and references to
+// their unaccented forms
+var latinText = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c", "ß", "ss", "æ", "ae", "œ", "oe",
+	"Á", "A", "À", "A", "Â", "A", "Ä", "A", "Ã", "A", "Å", "A",
+	"É", "E", "È", "E", "Ê", "E", "Ë", "E",
+	"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"Ó", "O", "Ò", "O", "Ô", "O", "Ö", "O", "Õ", "O", "Ø", "O",
+	"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U",
+	"Ñ", "N", "Ç", "C", "Æ", "AE", "Œ", "OE",
+)
+
+// bankText restricts text to the SEPA character set (Latin letters, digits
+// and / - ? : ( ) . , ' + space), transliterating common accents, replacing
+// anything else with a space and truncating to max characters
+func bankText(value string, max int) string {
+	value = latinText.Replace(value)
+
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune("/-?:().,'+ ", r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	text := strings.Join(strings.Fields(b.String()), " ")
+	if len(text) > max {
+		text = strings.TrimSpace(text[:max])
+	}
+	return text
+}
@@ -0,0 +1,144 @@
+package paymentfile
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// update rewrites the golden files: go test ./banking/paymentfile -update
+var update = flag.Bool("update", false, "rewrite the golden payment files in testdata")
+
+// testBatch is a EUR batch paying two invoices, one creditor without BIC
+// and with text outside the SEPA character set
+func testBatch() Batch {
+	return Batch{
+		MessageID:     "BATCH-2024-0007",
+		CreatedAt:     time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("PET", -5*3600)),
+		ExecutionDate: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		Currency:      "EUR",
+		Debtor: Account{
+			Name: "ACME Ingeniería y Servicios S.L.",
+			IBAN: "ES9121000418450200051332",
+			BIC:  "CAIXESBBXXX",
+		},
+		Items: []Item{
+			{
+				Sequence:      1,
+				EndToEndID:    "INV-F001-123",
+				Amount:        decimal.RequireFromString("1657.90"),
+				Creditor:      Account{Name: "Müller & Söhne GmbH", IBAN: "DE89370400440532013000", BIC: "COBADEFFXXX"},
+				InvoiceNumber: "F001-123",
+				Remittance:    "Factura F001-123 — febrero",
+			},
+			{
+				Sequence:      2,
+				EndToEndID:    "INV-B002-9",
+				Amount:        decimal.RequireFromString("40"),
+				Creditor:      Account{Name: "Café Ñandú", IBAN: "FR1420041010050500013M02606"},
+				InvoiceNumber: "B002-9",
+				Remittance:    "B002-9",
+			},
+		},
+	}
+}
+
+// checkGolden compares output with testdata/name, rewriting it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v; run go test ./banking/paymentfile -update to create it", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("output differs from %s:\n%s", golden, got)
+	}
+}
+
+func TestBatchValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(b *Batch)
+		wantItem  int
+		wantField string
+	}{
+		{"message ID missing", func(b *Batch) { b.MessageID = "" }, 0, "message_id"},
+		{"message ID too long", func(b *Batch) { b.MessageID = strings.Repeat("X", 36) }, 0, "message_id"},
+		{"currency", func(b *Batch) { b.Currency = "EURO" }, 0, "currency"},
+		{"execution date", func(b *Batch) { b.ExecutionDate = time.Time{} }, 0, "execution_date"},
+		{"debtor name", func(b *Batch) { b.Debtor.Name = "" }, 0, "debtor_name"},
+		{"debtor account", func(b *Batch) { b.Debtor.IBAN = "" }, 0, "debtor_account"},
+		{"no items", func(b *Batch) { b.Items = nil }, 0, "items"},
+		{"zero amount", func(b *Batch) { b.Items[1].Amount = decimal.Zero }, 2, "amount"},
+		{"negative amount", func(b *Batch) { b.Items[0].Amount = decimal.RequireFromString("-1") }, 1, "amount"},
+		{"fractions of a cent", func(b *Batch) { b.Items[1].Amount = decimal.RequireFromString("40.005") }, 2, "amount"},
+		{"end-to-end ID missing", func(b *Batch) { b.Items[0].EndToEndID = "" }, 1, "end_to_end_id"},
+		{"end-to-end ID too long", func(b *Batch) { b.Items[0].EndToEndID = strings.Repeat("X", 36) }, 1, "end_to_end_id"},
+		{"creditor name", func(b *Batch) { b.Items[1].Creditor.Name = "" }, 2, "creditor_name"},
+		{"creditor account", func(b *Batch) { b.Items[1].Creditor.IBAN = "" }, 2, "creditor_account"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := testBatch()
+			tt.change(&batch)
+
+			var batchErr *Error
+			if err := batch.validate(); !errors.As(err, &batchErr) {
+				t.Fatalf("err = %v; want a batch error", err)
+			}
+			if batchErr.Item != tt.wantItem || batchErr.Field != tt.wantField {
+				t.Errorf("err = %v; want item %d field %s", batchErr, tt.wantItem, tt.wantField)
+			}
+		})
+	}
+
+	batch := testBatch()
+	batch.Debtor.IBAN = ""
+	batch.Debtor.AccountNumber = "0011-0123-0200123456"
+	if err := batch.validate(); err != nil {
+		t.Errorf("debtor with an account number: %v", err)
+	}
+}
+
+func TestBatchTotal(t *testing.T) {
+	if got := testBatch().Total(); !got.Equal(decimal.RequireFromString("1697.90")) {
+		t.Errorf("total = %s; want 1697.90", got)
+	}
+}
+
+func TestBankText(t *testing.T) {
+	tests := []struct {
+		value string
+		max   int
+		want  string
+	}{
+		{"Compañía Ñandú", 70, "Compania Nandu"},
+		{"Müller & Söhne GmbH", 70, "Muller Sohne GmbH"},
+		{"Straße Œuvre", 70, "Strasse OEuvre"},
+		{"Ref: F001-123 (2/3), 'ok'+?", 70, "Ref: F001-123 (2/3), 'ok'+?"},
+		{"  tabs\tand\nnewlines  ", 70, "tabs and newlines"},
+		{"日本 invoice", 70, "invoice"},
+		{"ABCDEF GHIJ", 7, "ABCDEF"},
+		{"", 10, ""},
+	}
+
+	for _, tt := range tests {
+		if got := bankText(tt.value, tt.max); got != tt.want {
+			t.Errorf("bankText(%q, %d) = %q; want %q", tt.value, tt.max, got, tt.want)
+		}
+	}
+}
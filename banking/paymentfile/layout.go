@@ -0,0 +1,341 @@
+package paymentfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Kind is how the records of a layout are laid out
+type Kind string
+
+// Supported layout kinds
+const (
+	KindCSV        Kind = "csv"
+	KindFixedWidth Kind = "fixed_width"
+)
+
+// Field sources. Batch sources can be used in every record; item sources
+// only in the per-item record.
+const (
+	SourceLiteral = "literal"
+
+	// Batch sources
+	SourceMessageID     = "message_id"
+	SourceCreatedDate   = "created_date"
+	SourceExecutionDate = "execution_date"
+	SourceCurrency      = "currency"
+	SourceDebtorName    = "debtor_name"
+	SourceDebtorIBAN    = "debtor_iban"
+	SourceDebtorAccount = "debtor_account"
+	SourceDebtorBIC     = "debtor_bic"
+	SourceItemCount     = "item_count"
+	SourceTotalAmount   = "total_amount"
+
+	// Item sources
+	SourceSequence        = "sequence"
+	SourceEndToEndID      = "end_to_end_id"
+	SourceAmount          = "amount"
+	SourceCreditorName    = "creditor_name"
+	SourceCreditorIBAN    = "creditor_iban"
+	SourceCreditorAccount = "creditor_account"
+	SourceCreditorBIC     = "creditor_bic"
+	SourceInvoiceNumber   = "invoice_number"
+	SourceRemittance      = "remittance"
+)
+
+var batchSources = []string{
+	SourceLiteral, SourceMessageID, SourceCreatedDate, SourceExecutionDate, SourceCurrency,
+	SourceDebtorName, SourceDebtorIBAN, SourceDebtorAccount, SourceDebtorBIC,
+	SourceItemCount, SourceTotalAmount,
+}
+
+var itemSources = []string{
+	SourceSequence, SourceEndToEndID, SourceAmount, SourceCreditorName, SourceCreditorIBAN,
+	SourceCreditorAccount, SourceCreditorBIC, SourceInvoiceNumber, SourceRemittance,
+}
+
+// numericSources are never truncated in fixed-width records; a value that
+// does not fit is an error rather than a wrong amount
+var numericSources = []string{SourceItemCount, SourceTotalAmount, SourceSequence, SourceAmount}
+
+// Field is a column of a record
+type Field struct {
+	Source string `json:"source"`
+
+	// Value is the text written by literal fields
+	Value string `json:"value,omitempty"`
+
+	// Title is the CSV header of the column
+	Title string `json:"title,omitempty"`
+
+	// Width, Align (left or right) and Pad (a single character) shape
+	// fixed-width columns; text is left aligned and padded with spaces by
+	// default
+	Width int    `json:"width,omitempty"`
+	Align string `json:"align,omitempty"`
+	Pad   string `json:"pad,omitempty"`
+}
+
+// Layout describes a bank's bulk payment file: an optional header record, a
+// record per item and an optional trailer record
+type Layout struct {
+	Kind Kind `json:"kind"`
+
+	// Delimiter separates CSV columns (default ",")
+	Delimiter string `json:"delimiter,omitempty"`
+
+	// Header writes a CSV header row from the field titles
+	Header bool `json:"header,omitempty"`
+
+	// DateFormat uses the YYYY, YY, MM and DD tokens (default YYYY-MM-DD)
+	DateFormat string `json:"date_format,omitempty"`
+
+	// DecimalSeparator of amounts (default "."); ImpliedDecimals writes
+	// amounts in cents without separator instead
+	DecimalSeparator string `json:"decimal_separator,omitempty"`
+	ImpliedDecimals  bool   `json:"implied_decimals,omitempty"`
+
+	// LineEnding is "lf" (default) or "crlf"
+	LineEnding string `json:"line_ending,omitempty"`
+
+	HeaderRecord  []Field `json:"header_record,omitempty"`
+	Fields        []Field `json:"fields"`
+	TrailerRecord []Field `json:"trailer_record,omitempty"`
+}
+
+var dateTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02")
+
+// Validate checks that the layout can render a file
+func (l Layout) Validate() error {
+	if l.Kind != KindCSV && l.Kind != KindFixedWidth {
+		return &Error{Field: "kind", Message: "must be csv or fixed_width"}
+	}
+	if l.Kind == KindCSV && l.Delimiter != "" {
+		r, size := utf8.DecodeRuneInString(l.Delimiter)
+		if size != len(l.Delimiter) || r == '"' || r == '\r' || r == '\n' {
+			return &Error{Field: "delimiter", Message: "must be a single character other than a quote or line break"}
+		}
+	}
+	if l.DecimalSeparator != "" && l.DecimalSeparator != "." && l.DecimalSeparator != "," {
+		return &Error{Field: "decimal_separator", Message: "must be . or ,"}
+	}
+	if l.LineEnding != "" && l.LineEnding != "lf" && l.LineEnding != "crlf" {
+		return &Error{Field: "line_ending", Message: "must be lf or crlf"}
+	}
+	if l.DateFormat != "" && !strings.Contains(l.DateFormat, "YY") &&
+		!strings.Contains(l.DateFormat, "MM") && !strings.Contains(l.DateFormat, "DD") {
+		return &Error{Field: "date_format", Message: "must contain YYYY, YY, MM or DD"}
+	}
+	if len(l.Fields) == 0 {
+		return &Error{Field: "fields", Message: "must not be empty"}
+	}
+
+	records := []struct {
+		name    string
+		fields  []Field
+		sources []string
+	}{
+		{"header_record", l.HeaderRecord, batchSources},
+		{"fields", l.Fields, append(slices.Clone(batchSources), itemSources...)},
+		{"trailer_record", l.TrailerRecord, batchSources},
+	}
+	for _, record := range records {
+		for i, field := range record.fields {
+			name := fmt.Sprintf("%s[%d]", record.name, i)
+			switch {
+			case !slices.Contains(record.sources, field.Source):
+				return &Error{Field: name + ".source", Message: fmt.Sprintf("unknown source %q", field.Source)}
+			case l.Kind == KindFixedWidth && field.Width <= 0:
+				return &Error{Field: name + ".width", Message: "must be greater than zero in fixed-width layouts"}
+			case field.Align != "" && field.Align != "left" && field.Align != "right":
+				return &Error{Field: name + ".align", Message: "must be left or right"}
+			case field.Pad != "" && utf8.RuneCountInString(field.Pad) != 1:
+				return &Error{Field: name + ".pad", Message: "must be a single character"}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Render writes the batch with the layout
+func (l Layout) Render(batch Batch) ([]byte, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(batch.Items)+3)
+
+	if l.Kind == KindCSV && l.Header {
+		titles := make([]string, len(l.Fields))
+		for i, field := range l.Fields {
+			titles[i] = field.Title
+		}
+		records = append(records, titles)
+	}
+	if len(l.HeaderRecord) > 0 {
+		record, err := l.record(l.HeaderRecord, batch, nil)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	for i := range batch.Items {
+		record, err := l.record(l.Fields, batch, &batch.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if len(l.TrailerRecord) > 0 {
+		record, err := l.record(l.TrailerRecord, batch, nil)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	var buf bytes.Buffer
+	if l.Kind == KindCSV {
+		w := csv.NewWriter(&buf)
+		if l.Delimiter != "" {
+			w.Comma, _ = utf8.DecodeRuneInString(l.Delimiter)
+		}
+		w.UseCRLF = l.LineEnding == "crlf"
+		if err := w.WriteAll(records); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	newline := "\n"
+	if l.LineEnding == "crlf" {
+		newline = "\r\n"
+	}
+	for _, record := range records {
+		buf.WriteString(strings.Join(record, ""))
+		buf.WriteString(newline)
+	}
+	return buf.Bytes(), nil
+}
+
+// record renders the fields of one record; item is nil for header and
+// trailer records
+func (l Layout) record(fields []Field, batch Batch, item *Item) ([]string, error) {
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		value := l.value(field, batch, item)
+		if l.Kind == KindFixedWidth {
+			fitted, err := fit(field, value)
+			if err != nil {
+				sequence := 0
+				if item != nil {
+					sequence = item.Sequence
+				}
+				return nil, &Error{Item: sequence, Field: field.Source, Message: err.Error()}
+			}
+			value = fitted
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (l Layout) value(field Field, batch Batch, item *Item) string {
+	switch field.Source {
+	case SourceLiteral:
+		return field.Value
+	case SourceMessageID:
+		return batch.MessageID
+	case SourceCreatedDate:
+		return l.date(batch.CreatedAt.UTC())
+	case SourceExecutionDate:
+		return l.date(batch.ExecutionDate)
+	case SourceCurrency:
+		return strings.ToUpper(batch.Currency)
+	case SourceDebtorName:
+		return bankText(batch.Debtor.Name, maxNameLength)
+	case SourceDebtorIBAN:
+		return batch.Debtor.IBAN
+	case SourceDebtorAccount:
+		return batch.Debtor.Identifier()
+	case SourceDebtorBIC:
+		return batch.Debtor.BIC
+	case SourceItemCount:
+		return strconv.Itoa(len(batch.Items))
+	case SourceTotalAmount:
+		return l.amount(batch.Total().StringFixed(2))
+	}
+
+	switch field.Source {
+	case SourceSequence:
+		return strconv.Itoa(item.Sequence)
+	case SourceEndToEndID:
+		return item.EndToEndID
+	case SourceAmount:
+		return l.amount(item.Amount.StringFixed(2))
+	case SourceCreditorName:
+		return bankText(item.Creditor.Name, maxNameLength)
+	case SourceCreditorIBAN:
+		return item.Creditor.IBAN
+	case SourceCreditorAccount:
+		return item.Creditor.Identifier()
+	case SourceCreditorBIC:
+		return item.Creditor.BIC
+	case SourceInvoiceNumber:
+		return item.InvoiceNumber
+	case SourceRemittance:
+		return bankText(item.Remittance, maxRemittanceLength)
+	}
+
+	return ""
+}
+
+// date formats a date with the layout's date format
+func (l Layout) date(t time.Time) string {
+	if l.DateFormat == "" {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(dateTokens.Replace(l.DateFormat))
+}
+
+// amount formats an amount with two decimals using the layout's separator
+func (l Layout) amount(fixed string) string {
+	if l.ImpliedDecimals {
+		return strings.Replace(fixed, ".", "", 1)
+	}
+	if l.DecimalSeparator == "," {
+		return strings.Replace(fixed, ".", ",", 1)
+	}
+	return fixed
+}
+
+// fit pads or truncates a value to the width of a fixed-width column
+func fit(field Field, value string) (string, error) {
+	length := utf8.RuneCountInString(value)
+	if length > field.Width {
+		if slices.Contains(numericSources, field.Source) {
+			return "", fmt.Errorf("value %s does not fit in %d characters", value, field.Width)
+		}
+		return string([]rune(value)[:field.Width]), nil
+	}
+
+	pad := " "
+	if field.Pad != "" {
+		pad = field.Pad
+	}
+	padding := strings.Repeat(pad, field.Width-length)
+	if field.Align == "right" {
+		return padding + value, nil
+	}
+	return value + padding, nil
+}
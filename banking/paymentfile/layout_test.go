@@ -0,0 +1,141 @@
+package paymentfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLayoutGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout Layout
+	}{
+		{
+			name: "layout.csv",
+			layout: Layout{
+				Kind:             KindCSV,
+				Delimiter:        ";",
+				Header:           true,
+				DateFormat:       "DD/MM/YYYY",
+				DecimalSeparator: ",",
+				LineEnding:       "crlf",
+				Fields: []Field{
+					{Source: SourceSequence, Title: "N"},
+					{Source: SourceExecutionDate, Title: "Fecha"},
+					{Source: SourceCreditorName, Title: "Beneficiario"},
+					{Source: SourceCreditorAccount, Title: "Cuenta"},
+					{Source: SourceAmount, Title: "Importe"},
+					{Source: SourceCurrency, Title: "Moneda"},
+					{Source: SourceRemittance, Title: "Concepto; referencia"},
+				},
+			},
+		},
+		{
+			name: "layout.txt",
+			layout: Layout{
+				Kind:            KindFixedWidth,
+				DateFormat:      "YYMMDD",
+				ImpliedDecimals: true,
+				HeaderRecord: []Field{
+					{Source: SourceLiteral, Value: "H", Width: 1},
+					{Source: SourceDebtorAccount, Width: 24},
+					{Source: SourceCreatedDate, Width: 6},
+					{Source: SourceItemCount, Width: 4, Align: "right", Pad: "0"},
+				},
+				Fields: []Field{
+					{Source: SourceLiteral, Value: "D", Width: 1},
+					{Source: SourceSequence, Width: 4, Align: "right", Pad: "0"},
+					{Source: SourceCreditorIBAN, Width: 34},
+					{Source: SourceCreditorBIC, Width: 11},
+					{Source: SourceCreditorName, Width: 12},
+					{Source: SourceAmount, Width: 12, Align: "right", Pad: "0"},
+					{Source: SourceInvoiceNumber, Width: 10},
+				},
+				TrailerRecord: []Field{
+					{Source: SourceLiteral, Value: "T", Width: 1},
+					{Source: SourceTotalAmount, Width: 15, Align: "right", Pad: "0"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.layout.Render(testBatch())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestLayoutNumbersDoNotFit(t *testing.T) {
+	layout := Layout{
+		Kind: KindFixedWidth,
+		Fields: []Field{
+			{Source: SourceCreditorName, Width: 3},
+			{Source: SourceAmount, Width: 7},
+		},
+	}
+	batch := testBatch()
+	batch.Items[1].Amount = decimal.RequireFromString("12345.67")
+
+	// Names are cut to the width; amounts that do not fit fail
+	_, err := layout.Render(batch)
+	var batchErr *Error
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err = %v; want a batch error", err)
+	}
+	if batchErr.Item != 2 || batchErr.Field != SourceAmount {
+		t.Errorf("err = %v; want item 2 amount", batchErr)
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	item := []Field{{Source: SourceAmount, Width: 10}}
+
+	tests := []struct {
+		name      string
+		layout    Layout
+		wantField string // empty when valid
+	}{
+		{"csv", Layout{Kind: KindCSV, Fields: item}, ""},
+		{"fixed width", Layout{Kind: KindFixedWidth, Fields: item}, ""},
+		{"unknown kind", Layout{Kind: "xlsx", Fields: item}, "kind"},
+		{"delimiter quote", Layout{Kind: KindCSV, Delimiter: `"`, Fields: item}, "delimiter"},
+		{"delimiter of two characters", Layout{Kind: KindCSV, Delimiter: ";;", Fields: item}, "delimiter"},
+		{"tab delimiter", Layout{Kind: KindCSV, Delimiter: "\t", Fields: item}, ""},
+		{"decimal separator", Layout{Kind: KindCSV, DecimalSeparator: "'", Fields: item}, "decimal_separator"},
+		{"line ending", Layout{Kind: KindCSV, LineEnding: "cr", Fields: item}, "line_ending"},
+		{"date format without tokens", Layout{Kind: KindCSV, DateFormat: "dd/mm/yyyy", Fields: item}, "date_format"},
+		{"no fields", Layout{Kind: KindCSV}, "fields"},
+		{"unknown source", Layout{Kind: KindCSV, Fields: []Field{{Source: "iban"}}}, "fields[0].source"},
+		{"item source in the header", Layout{Kind: KindCSV, Fields: item, HeaderRecord: []Field{{Source: SourceAmount}}}, "header_record[0].source"},
+		{"item source in the trailer", Layout{Kind: KindCSV, Fields: item, TrailerRecord: []Field{{Source: SourceLiteral}, {Source: SourceSequence}}}, "trailer_record[1].source"},
+		{"fixed width without width", Layout{Kind: KindFixedWidth, Fields: []Field{{Source: SourceAmount}}}, "fields[0].width"},
+		{"align", Layout{Kind: KindCSV, Fields: []Field{{Source: SourceAmount, Align: "center"}}}, "fields[0].align"},
+		{"pad", Layout{Kind: KindCSV, Fields: []Field{{Source: SourceAmount, Pad: "00"}}}, "fields[0].pad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.layout.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("err = %v; want nil", err)
+				}
+				return
+			}
+			var layoutErr *Error
+			if !errors.As(err, &layoutErr) {
+				t.Fatalf("err = %v; want a layout error", err)
+			}
+			if layoutErr.Field != tt.wantField {
+				t.Errorf("field = %s; want %s", layoutErr.Field, tt.wantField)
+			}
+		})
+	}
+}
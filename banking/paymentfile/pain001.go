@@ -0,0 +1,193 @@
+package paymentfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// Pain001Namespace is the ISO 20022 credit transfer initiation version
+// written by Pain001, the one accepted by every SEPA bank
+const Pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// Field limits of pain.001.001.03
+const (
+	maxNameLength       = 70
+	maxRemittanceLength = 140
+)
+
+type pain001Document struct {
+	XMLName  xml.Name        `xml:"Document"`
+	Xmlns    string          `xml:"xmlns,attr"`
+	Initiate pain001Initiate `xml:"CstmrCdtTrfInitn"`
+}
+
+type pain001Initiate struct {
+	GroupHeader pain001GroupHeader `xml:"GrpHdr"`
+	PaymentInfo pain001PaymentInfo `xml:"PmtInf"`
+}
+
+type pain001GroupHeader struct {
+	MessageID       string       `xml:"MsgId"`
+	CreatedAt       string       `xml:"CreDtTm"`
+	NumberOfTxs     int          `xml:"NbOfTxs"`
+	ControlSum      string       `xml:"CtrlSum"`
+	InitiatingParty pain001Party `xml:"InitgPty"`
+}
+
+type pain001PaymentInfo struct {
+	PaymentInfoID string              `xml:"PmtInfId"`
+	Method        string              `xml:"PmtMtd"`
+	BatchBooking  bool                `xml:"BtchBookg"`
+	NumberOfTxs   int                 `xml:"NbOfTxs"`
+	ControlSum    string              `xml:"CtrlSum"`
+	PaymentType   *pain001PaymentType `xml:"PmtTpInf,omitempty"`
+	ExecutionDate string              `xml:"ReqdExctnDt"`
+	Debtor        pain001Party        `xml:"Dbtr"`
+	DebtorAccount pain001Account      `xml:"DbtrAcct"`
+	DebtorAgent   pain001Agent        `xml:"DbtrAgt"`
+	ChargeBearer  string              `xml:"ChrgBr"`
+	Transfers     []pain001Transfer   `xml:"CdtTrfTxInf"`
+}
+
+type pain001PaymentType struct {
+	ServiceLevel string `xml:"SvcLvl>Cd"`
+}
+
+type pain001Party struct {
+	Name string `xml:"Nm"`
+}
+
+type pain001Account struct {
+	ID pain001Identification `xml:"Id"`
+}
+
+type pain001Agent struct {
+	Institution pain001Institution `xml:"FinInstnId"`
+}
+
+type pain001Identification struct {
+	IBAN  string        `xml:"IBAN,omitempty"`
+	Other *pain001Other `xml:"Othr,omitempty"`
+}
+
+type pain001Institution struct {
+	BIC   string        `xml:"BIC,omitempty"`
+	Other *pain001Other `xml:"Othr,omitempty"`
+}
+
+type pain001Other struct {
+	ID string `xml:"Id"`
+}
+
+type pain001Transfer struct {
+	EndToEndID      string         `xml:"PmtId>EndToEndId"`
+	Amount          pain001Amount  `xml:"Amt>InstdAmt"`
+	CreditorAgent   *pain001Agent  `xml:"CdtrAgt,omitempty"`
+	Creditor        pain001Party   `xml:"Cdtr"`
+	CreditorAccount pain001Account `xml:"CdtrAcct"`
+	Remittance      string         `xml:"RmtInf>Ustrd,omitempty"`
+}
+
+type pain001Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// Pain001 writes the batch as a pain.001.001.03 document with one payment
+// information block and one CdtTrfTxInf per item. EUR batches are flagged
+// as SEPA credit transfers, which requires IBANs on both sides; other
+// currencies may use domestic account numbers.
+func Pain001(batch Batch) ([]byte, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	sepa := strings.EqualFold(batch.Currency, "EUR")
+	if sepa && batch.Debtor.IBAN == "" {
+		return nil, &Error{Field: "debtor_iban", Message: "is required for SEPA credit transfers"}
+	}
+
+	total := batch.Total().StringFixed(2)
+	info := pain001PaymentInfo{
+		PaymentInfoID: batch.MessageID,
+		Method:        "TRF",
+		BatchBooking:  true,
+		NumberOfTxs:   len(batch.Items),
+		ControlSum:    total,
+		ExecutionDate: batch.ExecutionDate.Format("2006-01-02"),
+		Debtor:        pain001Party{Name: bankText(batch.Debtor.Name, maxNameLength)},
+		DebtorAccount: pain001AccountOf(batch.Debtor),
+		DebtorAgent:   pain001AgentOf(batch.Debtor),
+		ChargeBearer:  "SLEV",
+		Transfers:     make([]pain001Transfer, 0, len(batch.Items)),
+	}
+	if sepa {
+		info.PaymentType = &pain001PaymentType{ServiceLevel: "SEPA"}
+	} else {
+		info.ChargeBearer = "SHAR"
+	}
+
+	for _, item := range batch.Items {
+		if sepa && item.Creditor.IBAN == "" {
+			return nil, &Error{Item: item.Sequence, Field: "creditor_iban", Message: "is required for SEPA credit transfers"}
+		}
+
+		transfer := pain001Transfer{
+			EndToEndID: bankText(item.EndToEndID, 35),
+			Amount: pain001Amount{
+				Currency: strings.ToUpper(batch.Currency),
+				Value:    item.Amount.StringFixed(2),
+			},
+			Creditor:        pain001Party{Name: bankText(item.Creditor.Name, maxNameLength)},
+			CreditorAccount: pain001AccountOf(item.Creditor),
+			Remittance:      bankText(item.Remittance, maxRemittanceLength),
+		}
+		if item.Creditor.BIC != "" {
+			agent := pain001AgentOf(item.Creditor)
+			transfer.CreditorAgent = &agent
+		}
+		info.Transfers = append(info.Transfers, transfer)
+	}
+
+	doc := pain001Document{
+		Xmlns: Pain001Namespace,
+		Initiate: pain001Initiate{
+			GroupHeader: pain001GroupHeader{
+				MessageID:       batch.MessageID,
+				CreatedAt:       batch.CreatedAt.UTC().Format("2006-01-02T15:04:05"),
+				NumberOfTxs:     len(batch.Items),
+				ControlSum:      total,
+				InitiatingParty: pain001Party{Name: bankText(batch.Debtor.Name, maxNameLength)},
+			},
+			PaymentInfo: info,
+		},
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+func pain001AccountOf(account Account) pain001Account {
+	if account.IBAN != "" {
+		return pain001Account{ID: pain001Identification{IBAN: account.IBAN}}
+	}
+	return pain001Account{ID: pain001Identification{Other: &pain001Other{ID: account.AccountNumber}}}
+}
+
+// pain001AgentOf names the bank by BIC, or with NOTPROVIDED as the SEPA
+// rulebook asks when the BIC is not known
+func pain001AgentOf(account Account) pain001Agent {
+	if account.BIC != "" {
+		return pain001Agent{Institution: pain001Institution{BIC: account.BIC}}
+	}
+	return pain001Agent{Institution: pain001Institution{Other: &pain001Other{ID: "NOTPROVIDED"}}}
+}
@@ -0,0 +1,85 @@
+package paymentfile
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func TestPain001Golden(t *testing.T) {
+	tests := []struct {
+		name  string
+		batch func() Batch
+	}{
+		{"pain001_sepa.xml", testBatch},
+		{
+			// Domestic transfers in soles use account numbers and no
+			// SEPA service level
+			"pain001_domestic.xml",
+			func() Batch {
+				b := testBatch()
+				b.Currency = "pen"
+				b.Debtor = Account{Name: "ACME S.A.C.", AccountNumber: "191-1234567-0-12"}
+				b.Items[0].Creditor = Account{Name: "Proveedor Uno S.A.C.", AccountNumber: "00219100123456789012"}
+				b.Items[1].Creditor = Account{Name: "Proveedor Dos E.I.R.L.", AccountNumber: "0011-0123-0200123456", BIC: "BCONPEPL"}
+				return b
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Pain001(tt.batch())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc pain001Document
+			if err := xml.Unmarshal(got, &doc); err != nil {
+				t.Fatalf("output is not XML: %v", err)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestPain001SEPARequiresIBANs(t *testing.T) {
+	tests := []struct {
+		name      string
+		currency  string
+		change    func(b *Batch)
+		wantItem  int
+		wantField string // empty when the file is written
+	}{
+		{"debtor without IBAN", "EUR", func(b *Batch) { b.Debtor.IBAN, b.Debtor.AccountNumber = "", "191-1234567-0-12" }, 0, "debtor_iban"},
+		{"creditor without IBAN", "EUR", func(b *Batch) { b.Items[1].Creditor.IBAN, b.Items[1].Creditor.AccountNumber = "", "12345678" }, 2, "creditor_iban"},
+		{"lower case currency", "eur", func(b *Batch) { b.Items[0].Creditor.IBAN, b.Items[0].Creditor.AccountNumber = "", "12345678" }, 1, "creditor_iban"},
+		{"both sides with IBAN", "EUR", func(b *Batch) {}, 0, ""},
+		{"account numbers outside SEPA", "USD", func(b *Batch) {
+			b.Debtor.IBAN, b.Debtor.AccountNumber = "", "191-1234567-0-12"
+			b.Items[1].Creditor.IBAN, b.Items[1].Creditor.AccountNumber = "", "12345678"
+		}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := testBatch()
+			batch.Currency = tt.currency
+			tt.change(&batch)
+
+			_, err := Pain001(batch)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("err = %v; want nil", err)
+				}
+				return
+			}
+			var batchErr *Error
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("err = %v; want a batch error", err)
+			}
+			if batchErr.Item != tt.wantItem || batchErr.Field != tt.wantField {
+				t.Errorf("err = %v; want item %d field %s", batchErr, tt.wantItem, tt.wantField)
+			}
+		})
+	}
+}
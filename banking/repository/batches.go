@@ -0,0 +1,485 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/banking"
+	"github.com/Abraxas-365/fuckturamelo/banking/dto"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+	paymentmodels "github.com/Abraxas-365/fuckturamelo/payments/models"
+	paymentspg "github.com/Abraxas-365/fuckturamelo/payments/repository"
+)
+
+// batchRepository implements BatchRepository using sqlx
+type batchRepository struct {
+	db *sqlx.DB
+}
+
+// NewBatchRepository creates a new payment batch repository
+func NewBatchRepository(db *sqlx.DB) BatchRepository {
+	return &batchRepository{db: db}
+}
+
+// batchColumns are the payment_batches columns without the file content
+const batchColumns = `id, organization_id, format, layout_id, status, currency_code, execution_date,
+	item_count, total_amount, debtor_name, debtor_iban, debtor_account_number, debtor_bic,
+	file_name, file_sha256, created_by, created_at, status_changed_by, status_changed_at, updated_at`
+
+// payableQuery reads invoices with their balance and the default bank
+// account of their provider
+const payableQuery = `
+	SELECT i.id AS invoice_id, i.organization_id, i.provider_id, p.name AS provider_name,
+		i.invoice_number, i.document_kind, i.status, i.is_deleted, i.currency_code,
+		b.outstanding_amount, b.payment_batch_id,
+		a.id AS bank_account_id, a.account_holder, a.iban, a.account_number, a.bic,
+		a.currency_code AS account_currency_code
+	FROM invoices i
+	JOIN invoice_balances b ON b.invoice_id = i.id
+	LEFT JOIN providers p ON p.id = i.provider_id
+	LEFT JOIN provider_bank_accounts a ON a.provider_id = i.provider_id AND a.is_default
+	WHERE i.id = ANY($1)
+	ORDER BY i.id`
+
+// PayableInvoices reads the invoices a batch is about to pay
+func (r *batchRepository) PayableInvoices(ctx context.Context, invoiceIDs []uuid.UUID) ([]models.PayableInvoice, error) {
+	invoices := []models.PayableInvoice{}
+	if err := r.db.SelectContext(ctx, &invoices, payableQuery, pq.Array(invoiceIDs)); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithCause(err)
+	}
+	return invoices, nil
+}
+
+// CreateBatch stores a generated batch and its items. The invoices are
+// locked in id order and checked again, so an invoice that was paid,
+// adjusted or scheduled elsewhere since it was read fails the batch instead
+// of being paid twice.
+func (r *batchRepository) CreateBatch(ctx context.Context, batch *models.PaymentBatch) (*models.PaymentBatch, error) {
+	batchError := func(err error) error {
+		return banking.BankingErrors.New(banking.ErrBatchFailed).
+			WithDetail("organization_id", batch.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, batchError(err)
+	}
+	defer tx.Rollback()
+
+	invoiceIDs := make([]uuid.UUID, len(batch.Items))
+	for i, item := range batch.Items {
+		invoiceIDs[i] = item.InvoiceID
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`SELECT id FROM invoices WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(invoiceIDs)); err != nil {
+		return nil, batchError(err)
+	}
+
+	current := []models.PayableInvoice{}
+	if err := tx.SelectContext(ctx, &current, payableQuery, pq.Array(invoiceIDs)); err != nil {
+		return nil, batchError(err)
+	}
+	byID := make(map[uuid.UUID]models.PayableInvoice, len(current))
+	for _, invoice := range current {
+		byID[invoice.InvoiceID] = invoice
+	}
+
+	for _, item := range batch.Items {
+		invoice, ok := byID[item.InvoiceID]
+		if !ok {
+			return nil, banking.BankingErrors.New(banking.ErrInvoiceChanged).
+				WithDetail("invoice_id", item.InvoiceID.String()).
+				WithDetail("reason", "invoice not found")
+		}
+		reason := invoice.Problem(batch.OrganizationID, batch.CurrencyCode)
+		switch {
+		case reason != "":
+		case !invoice.OutstandingAmount.Equal(item.Amount):
+			reason = "outstanding amount changed"
+		case invoice.BankAccountID == nil || *invoice.BankAccountID != *item.BankAccountID:
+			reason = "provider default bank account changed"
+		}
+		if reason != "" {
+			return nil, banking.BankingErrors.New(banking.ErrInvoiceChanged).
+				WithDetail("invoice_id", item.InvoiceID.String()).
+				WithDetail("reason", reason)
+		}
+	}
+
+	err = tx.GetContext(ctx, batch, `
+		INSERT INTO payment_batches (
+			id, organization_id, format, layout_id, status, currency_code, execution_date,
+			item_count, total_amount, debtor_name, debtor_iban, debtor_account_number, debtor_bic,
+			file_name, file_content, file_sha256, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		RETURNING *`,
+		batch.ID, batch.OrganizationID, batch.Format, batch.LayoutID, batch.Status, batch.CurrencyCode,
+		batch.ExecutionDate, batch.ItemCount, batch.TotalAmount, batch.DebtorName, batch.DebtorIBAN,
+		batch.DebtorAccountNumber, batch.DebtorBIC, batch.FileName, batch.FileContent, batch.FileSHA256,
+		batch.CreatedBy, batch.CreatedAt, batch.UpdatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return nil, banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+				WithDetail("reason", "unknown organization or layout").
+				WithCause(err)
+		}
+		return nil, batchError(err)
+	}
+
+	stmt, err := tx.PreparexContext(ctx, `
+		INSERT INTO payment_batch_items (
+			id, batch_id, sequence, invoice_id, provider_id, bank_account_id, end_to_end_id,
+			amount, currency_code, creditor_name, creditor_iban, creditor_account_number,
+			creditor_bic, remittance_info
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`)
+	if err != nil {
+		return nil, batchError(err)
+	}
+	defer stmt.Close()
+
+	for _, item := range batch.Items {
+		_, err := stmt.ExecContext(ctx,
+			item.ID, batch.ID, item.Sequence, item.InvoiceID, item.ProviderID, item.BankAccountID,
+			item.EndToEndID, item.Amount, item.CurrencyCode, item.CreditorName, item.CreditorIBAN,
+			item.CreditorAccountNumber, item.CreditorBIC, item.RemittanceInfo,
+		)
+		if err != nil {
+			return nil, batchError(err)
+		}
+	}
+
+	items, err := selectBatchItems(ctx, tx, batch.ID)
+	if err != nil {
+		return nil, batchError(err)
+	}
+	batch.Items = items
+
+	if err := tx.Commit(); err != nil {
+		return nil, batchError(err)
+	}
+
+	return batch, nil
+}
+
+// GetBatch retrieves a batch with its items and file
+func (r *batchRepository) GetBatch(ctx context.Context, id uuid.UUID) (*models.PaymentBatch, error) {
+	var batch models.PaymentBatch
+	if err := r.db.GetContext(ctx, &batch, `SELECT * FROM payment_batches WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, banking.BankingErrors.New(banking.ErrBatchNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	items, err := selectBatchItems(ctx, r.db, id)
+	if err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+	batch.Items = items
+
+	return &batch, nil
+}
+
+// ListBatches lists batches, newest first, without their items and files
+func (r *batchRepository) ListBatches(ctx context.Context, req *dto.BatchListRequest) (*dto.BatchListResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+
+	conditions := []string{"1=1"}
+	args := []any{}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.OrganizationID != nil {
+		add("organization_id = $%d", *req.OrganizationID)
+	}
+	if req.Status != nil {
+		add("status = $%d", *req.Status)
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM payment_batches WHERE "+whereClause, args...); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithCause(err)
+	}
+
+	dataQuery := fmt.Sprintf(`
+		SELECT %s FROM payment_batches
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d`, batchColumns, whereClause, len(args)+1, len(args)+2)
+	args = append(args, req.PageSize, (req.Page-1)*req.PageSize)
+
+	batches := []models.PaymentBatch{}
+	if err := r.db.SelectContext(ctx, &batches, dataQuery, args...); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithCause(err)
+	}
+
+	// Calculate pagination metadata
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
+	}
+
+	return &dto.BatchListResponse{
+		Batches:     batches,
+		Total:       total,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		TotalPages:  totalPages,
+		HasNext:     req.Page < totalPages,
+		HasPrevious: req.Page > 1,
+	}, nil
+}
+
+// UpdateBatchStatus moves a batch along its lifecycle. Completing a batch
+// records one bank transfer payment per item in the same transaction; the
+// allocation is capped at what is still outstanding, so a credit note
+// issued after scheduling leaves the excess unallocated on the payment
+// instead of failing the completion.
+func (r *batchRepository) UpdateBatchStatus(ctx context.Context, id uuid.UUID, status models.BatchStatus, changedBy uuid.UUID) (*models.PaymentBatch, error) {
+	batchError := func(err error) error {
+		return banking.BankingErrors.New(banking.ErrBatchFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, batchError(err)
+	}
+	defer tx.Rollback()
+
+	var batch models.PaymentBatch
+	err = tx.GetContext(ctx, &batch,
+		`SELECT `+batchColumns+` FROM payment_batches WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, banking.BankingErrors.New(banking.ErrBatchNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, batchError(err)
+	}
+
+	if !batch.Status.CanTransition(status) {
+		return nil, banking.BankingErrors.New(banking.ErrBatchTransitionNotAllowed).
+			WithDetail("from", string(batch.Status)).
+			WithDetail("to", string(status)).
+			WithDetail("allowed", batch.Status.AllowedTransitions())
+	}
+
+	if status == models.BatchCompleted {
+		if err := recordBatchPayments(ctx, tx, &batch, changedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.GetContext(ctx, &batch, `
+		UPDATE payment_batches
+		SET status = $2, status_changed_by = $3, status_changed_at = NOW()
+		WHERE id = $1
+		RETURNING `+batchColumns, id, status, changedBy)
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	items, err := selectBatchItems(ctx, tx, id)
+	if err != nil {
+		return nil, batchError(err)
+	}
+	batch.Items = items
+
+	if err := tx.Commit(); err != nil {
+		return nil, batchError(err)
+	}
+
+	return &batch, nil
+}
+
+// recordBatchPayments records the payment of every item of a batch being
+// completed and links it to the item
+func recordBatchPayments(ctx context.Context, tx *sqlx.Tx, batch *models.PaymentBatch, changedBy uuid.UUID) error {
+	items, err := selectBatchItems(ctx, tx, batch.ID)
+	if err != nil {
+		return banking.BankingErrors.New(banking.ErrBatchFailed).
+			WithDetail("id", batch.ID.String()).
+			WithCause(err)
+	}
+
+	now := time.Now()
+	notes := fmt.Sprintf("Paid in payment batch %s", batch.FileName)
+
+	for _, item := range items {
+		var outstanding decimal.Decimal
+		err := tx.GetContext(ctx, &outstanding,
+			`SELECT outstanding_amount FROM invoice_balances WHERE invoice_id = $1`, item.InvoiceID)
+		if err != nil {
+			return banking.BankingErrors.New(banking.ErrBatchFailed).
+				WithDetail("invoice_id", item.InvoiceID.String()).
+				WithCause(err)
+		}
+
+		payment := &paymentmodels.Payment{
+			OrganizationID: batch.OrganizationID,
+			ProviderID:     item.ProviderID,
+			Method:         paymentmodels.MethodBankTransfer,
+			PaymentDate:    batch.ExecutionDate,
+			Amount:         item.Amount,
+			CurrencyCode:   item.CurrencyCode,
+			Reference:      &item.EndToEndID,
+			Notes:          &notes,
+			CreatedBy:      &changedBy,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if allocated := decimal.Min(item.Amount, outstanding); allocated.IsPositive() {
+			payment.Allocations = []paymentmodels.Allocation{{
+				InvoiceID: item.InvoiceID,
+				Amount:    allocated,
+				CreatedBy: &changedBy,
+			}}
+		}
+
+		recorded, err := paymentspg.RecordPayment(ctx, tx, payment)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE payment_batch_items SET payment_id = $2 WHERE id = $1`, item.ID, recorded.ID)
+		if err != nil {
+			return banking.BankingErrors.New(banking.ErrBatchFailed).
+				WithDetail("id", batch.ID.String()).
+				WithCause(err)
+		}
+	}
+
+	return nil
+}
+
+// selectBatchItems retrieves the items of a batch in file order
+func selectBatchItems(ctx context.Context, q sqlx.QueryerContext, batchID uuid.UUID) ([]models.BatchItem, error) {
+	items := []models.BatchItem{}
+	query := `
+		SELECT bi.*, i.invoice_number
+		FROM payment_batch_items bi
+		JOIN invoices i ON i.id = bi.invoice_id
+		WHERE bi.batch_id = $1
+		ORDER BY bi.sequence`
+	if err := sqlx.SelectContext(ctx, q, &items, query, batchID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CreateLayout saves a bank file layout
+func (r *batchRepository) CreateLayout(ctx context.Context, layout *models.BankFileLayout) (*models.BankFileLayout, error) {
+	var created models.BankFileLayout
+	err := r.db.GetContext(ctx, &created, `
+		INSERT INTO bank_file_layouts (id, organization_id, name, kind, definition, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *`,
+		layout.ID, layout.OrganizationID, layout.Name, layout.Kind, layout.Definition, layout.CreatedBy)
+	if err != nil {
+		if strings.Contains(err.Error(), "bank_file_layouts_name_unique") {
+			return nil, banking.BankingErrors.New(banking.ErrLayoutNameExists).
+				WithDetail("name", layout.Name).
+				WithCause(err)
+		}
+		return nil, banking.BankingErrors.New(banking.ErrBatchFailed).
+			WithDetail("name", layout.Name).
+			WithCause(err)
+	}
+
+	return &created, nil
+}
+
+// GetLayout retrieves a bank file layout
+func (r *batchRepository) GetLayout(ctx context.Context, id uuid.UUID) (*models.BankFileLayout, error) {
+	var layout models.BankFileLayout
+	if err := r.db.GetContext(ctx, &layout, `SELECT * FROM bank_file_layouts WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, banking.BankingErrors.New(banking.ErrLayoutNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	return &layout, nil
+}
+
+// ListLayouts lists the layouts of an organization by name
+func (r *batchRepository) ListLayouts(ctx context.Context, orgID uuid.UUID) ([]models.BankFileLayout, error) {
+	layouts := []models.BankFileLayout{}
+	err := r.db.SelectContext(ctx, &layouts,
+		`SELECT * FROM bank_file_layouts WHERE organization_id = $1 ORDER BY name`, orgID)
+	if err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return layouts, nil
+}
+
+// DeleteLayout removes a layout no batch was generated with
+func (r *batchRepository) DeleteLayout(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM bank_file_layouts WHERE id = $1`, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return banking.BankingErrors.New(banking.ErrLayoutInUse).
+				WithDetail("id", id.String()).
+				WithCause(err)
+		}
+		return banking.BankingErrors.New(banking.ErrBatchFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return banking.BankingErrors.New(banking.ErrBatchFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+	if affected == 0 {
+		return banking.BankingErrors.New(banking.ErrLayoutNotFound).
+			WithDetail("id", id.String())
+	}
+
+	return nil
+}
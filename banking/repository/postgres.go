@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/banking"
+	"github.com/Abraxas-365/fuckturamelo/banking/dto"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// summaryColumns counts the lines of statement s per reconciliation status
+const summaryColumns = `
+	COUNT(l.id) AS line_count,
+	COUNT(l.id) FILTER (WHERE l.status = 'unmatched') AS unmatched_count,
+	COUNT(l.id) FILTER (WHERE l.status = 'suggested') AS suggested_count,
+	COUNT(l.id) FILTER (WHERE l.status = 'matched') AS matched_count`
+
+// statementRepository implements StatementRepository with sqlx
+type statementRepository struct {
+	db *sqlx.DB
+}
+
+// NewStatementRepository creates a new bank statement repository
+func NewStatementRepository(db *sqlx.DB) StatementRepository {
+	return &statementRepository{
+		db: db,
+	}
+}
+
+// Import stores the statements of a file with their lines. All of them are
+// stored or none: a file already imported is rejected as a whole.
+func (r *statementRepository) Import(ctx context.Context, statements []*models.Statement) ([]*models.Statement, error) {
+	importError := func(err error) error {
+		if strings.Contains(err.Error(), "bank_statements_file_unique") {
+			return banking.BankingErrors.New(banking.ErrStatementAlreadyImported).
+				WithDetail("file_sha256", statements[0].FileSHA256).
+				WithCause(err)
+		}
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return banking.BankingErrors.New(banking.ErrBankingValidationFailed).
+				WithDetail("field", "organization_id").
+				WithDetail("reason", "unknown organization").
+				WithCause(err)
+		}
+		return banking.BankingErrors.New(banking.ErrStatementImportFailed).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, importError(err)
+	}
+	defer tx.Rollback()
+
+	statementQuery := `
+		INSERT INTO bank_statements
+			(id, organization_id, format, account_identifier, currency_code, statement_reference,
+			 opening_balance, opening_date, closing_balance, closing_date, period_start, period_end,
+			 file_name, file_sha256, file_index, imported_by, imported_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING *`
+
+	lineQuery := `
+		INSERT INTO bank_statement_lines
+			(id, statement_id, organization_id, line_number, booking_date, value_date, amount,
+			 direction, currency_code, bank_reference, end_to_end_id, counterparty_name,
+			 counterparty_account, remittance_info, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING *`
+
+	lineStmt, err := tx.PreparexContext(ctx, lineQuery)
+	if err != nil {
+		return nil, importError(err)
+	}
+	defer lineStmt.Close()
+
+	imported := make([]*models.Statement, 0, len(statements))
+	for _, stmt := range statements {
+		if stmt.ID == uuid.Nil {
+			stmt.ID = uuid.New()
+		}
+
+		var result models.Statement
+		err := tx.GetContext(ctx, &result, statementQuery,
+			stmt.ID, stmt.OrganizationID, stmt.Format, stmt.AccountIdentifier, stmt.CurrencyCode,
+			stmt.StatementReference, stmt.OpeningBalance, stmt.OpeningDate, stmt.ClosingBalance,
+			stmt.ClosingDate, stmt.PeriodStart, stmt.PeriodEnd, stmt.FileName, stmt.FileSHA256,
+			stmt.FileIndex, stmt.ImportedBy, stmt.ImportedAt)
+		if err != nil {
+			return nil, importError(err)
+		}
+
+		result.Lines = make([]models.StatementLine, 0, len(stmt.Lines))
+		for _, line := range stmt.Lines {
+			if line.ID == uuid.Nil {
+				line.ID = uuid.New()
+			}
+
+			var stored models.StatementLine
+			err := lineStmt.GetContext(ctx, &stored,
+				line.ID, result.ID, result.OrganizationID, line.LineNumber, line.BookingDate,
+				line.ValueDate, line.Amount, line.Direction, line.CurrencyCode, line.BankReference,
+				line.EndToEndID, line.CounterpartyName, line.CounterpartyAccount, line.RemittanceInfo,
+				models.LineUnmatched, line.CreatedAt, line.UpdatedAt)
+			if err != nil {
+				return nil, importError(err)
+			}
+			result.Lines = append(result.Lines, stored)
+		}
+
+		imported = append(imported, &result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, importError(err)
+	}
+
+	return imported, nil
+}
+
+// GetByID retrieves a statement with its lines
+func (r *statementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Statement, error) {
+	var result models.Statement
+	err := r.db.GetContext(ctx, &result, `SELECT * FROM bank_statements WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, banking.BankingErrors.New(banking.ErrStatementNotFound).
+				WithDetail("statement_id", id.String())
+		}
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("statement_id", id.String()).
+			WithCause(err)
+	}
+
+	if result.Lines, err = r.ListLines(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetSummary counts the lines of a statement per reconciliation status
+func (r *statementRepository) GetSummary(ctx context.Context, id uuid.UUID) (*models.StatementSummary, error) {
+	query := `SELECT ` + summaryColumns + ` FROM bank_statement_lines l WHERE l.statement_id = $1`
+
+	var summary models.StatementSummary
+	if err := r.db.GetContext(ctx, &summary, query, id); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("statement_id", id.String()).
+			WithCause(err)
+	}
+
+	return &summary, nil
+}
+
+// List lists statements with their line summaries, latest period first
+func (r *statementRepository) List(ctx context.Context, req *dto.StatementListRequest) (*dto.StatementListResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+
+	conditions := []string{"1=1"}
+	args := []any{}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.OrganizationID != nil {
+		add("s.organization_id = $%d", *req.OrganizationID)
+	}
+	if req.AccountIdentifier != nil {
+		add("s.account_identifier = $%d", *req.AccountIdentifier)
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM bank_statements s WHERE "+whereClause, args...); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithCause(err)
+	}
+
+	dataQuery := fmt.Sprintf(`
+		SELECT s.*, %s
+		FROM bank_statements s
+		LEFT JOIN bank_statement_lines l ON l.statement_id = s.id
+		WHERE %s
+		GROUP BY s.id
+		ORDER BY s.period_end DESC NULLS LAST, s.imported_at DESC, s.id
+		LIMIT $%d OFFSET $%d`, summaryColumns, whereClause, len(args)+1, len(args)+2)
+	args = append(args, req.PageSize, (req.Page-1)*req.PageSize)
+
+	var rows []struct {
+		models.Statement
+		models.StatementSummary
+	}
+	if err := r.db.SelectContext(ctx, &rows, dataQuery, args...); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithCause(err)
+	}
+
+	statements := make([]*dto.StatementResponse, len(rows))
+	for i := range rows {
+		statements[i] = &dto.StatementResponse{
+			Statement: &rows[i].Statement,
+			Summary:   rows[i].StatementSummary,
+		}
+	}
+
+	// Calculate pagination metadata
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
+	}
+
+	return &dto.StatementListResponse{
+		Statements:  statements,
+		Total:       total,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		TotalPages:  totalPages,
+		HasNext:     req.Page < totalPages,
+		HasPrevious: req.Page > 1,
+	}, nil
+}
+
+// ListLines returns the lines of a statement in file order
+func (r *statementRepository) ListLines(ctx context.Context, statementID uuid.UUID) ([]models.StatementLine, error) {
+	lines := []models.StatementLine{}
+	err := r.db.SelectContext(ctx, &lines,
+		`SELECT * FROM bank_statement_lines WHERE statement_id = $1 ORDER BY line_number`, statementID)
+	if err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("statement_id", statementID.String()).
+			WithCause(err)
+	}
+
+	return lines, nil
+}
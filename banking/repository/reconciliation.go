@@ -0,0 +1,342 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/banking"
+	"github.com/Abraxas-365/fuckturamelo/banking/matcher"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+	paymentmodels "github.com/Abraxas-365/fuckturamelo/payments/models"
+	paymentspg "github.com/Abraxas-365/fuckturamelo/payments/repository"
+)
+
+// openInvoice is an invoice row as the matcher needs it
+type openInvoice struct {
+	ID                uuid.UUID       `db:"id"`
+	ProviderID        *uuid.UUID      `db:"provider_id"`
+	InvoiceNumber     string          `db:"invoice_number"`
+	ProviderTaxID     string          `db:"provider_tax_id"`
+	CurrencyCode      string          `db:"currency_code"`
+	OutstandingAmount decimal.Decimal `db:"outstanding_amount"`
+	InvoiceDate       *time.Time      `db:"invoice_date"`
+	DueDate           *time.Time      `db:"due_date"`
+}
+
+// OpenInvoices lists the live regular invoices of an organization that still
+// have an amount outstanding in one of the given currencies
+func (r *statementRepository) OpenInvoices(ctx context.Context, orgID uuid.UUID, currencies []string, issuedUntil time.Time) ([]matcher.Invoice, error) {
+	query := `
+		SELECT i.id, i.provider_id, COALESCE(i.invoice_number, '') AS invoice_number,
+			COALESCE(p.tax_id, '') AS provider_tax_id, i.currency_code, b.outstanding_amount,
+			i.invoice_date, i.due_date
+		FROM invoices i
+		JOIN invoice_balances b ON b.invoice_id = i.id
+		LEFT JOIN providers p ON p.id = i.provider_id
+		WHERE i.organization_id = $1
+			AND i.is_deleted = false
+			AND i.document_kind = 'invoice'
+			AND i.status IS DISTINCT FROM 'void'
+			AND i.currency_code = ANY($2)
+			AND (i.invoice_date IS NULL OR i.invoice_date <= $3)
+			AND b.outstanding_amount > 0`
+
+	var rows []openInvoice
+	if err := r.db.SelectContext(ctx, &rows, query, orgID, pq.Array(currencies), issuedUntil); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrMatchFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	invoices := make([]matcher.Invoice, len(rows))
+	for i, row := range rows {
+		invoices[i] = matcher.Invoice{
+			ID:            row.ID,
+			ProviderID:    row.ProviderID,
+			InvoiceNumber: row.InvoiceNumber,
+			ProviderTaxID: row.ProviderTaxID,
+			Currency:      row.CurrencyCode,
+			Outstanding:   row.OutstandingAmount,
+			InvoiceDate:   row.InvoiceDate,
+			DueDate:       row.DueDate,
+		}
+	}
+
+	return invoices, nil
+}
+
+// SaveSuggestions stores new suggestions and marks their lines as suggested.
+// A line and invoice pair that was already proposed, including one that was
+// rejected, is not proposed again.
+func (r *statementRepository) SaveSuggestions(ctx context.Context, suggestions []models.Suggestion) (int, error) {
+	if len(suggestions) == 0 {
+		return 0, nil
+	}
+
+	saveError := func(err error) error {
+		return banking.BankingErrors.New(banking.ErrMatchFailed).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, saveError(err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO bank_reconciliation_suggestions
+			(id, line_id, invoice_id, provider_id, score, amount, reasons, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (line_id, invoice_id) DO NOTHING`
+
+	created := 0
+	lineIDs := []string{}
+	seen := make(map[uuid.UUID]bool)
+	for _, s := range suggestions {
+		result, err := tx.ExecContext(ctx, query,
+			s.ID, s.LineID, s.InvoiceID, s.ProviderID, s.Score, s.Amount, s.Reasons, s.Status, s.CreatedAt)
+		if err != nil {
+			return 0, saveError(err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, saveError(err)
+		}
+		created += int(rows)
+
+		if rows > 0 && !seen[s.LineID] {
+			seen[s.LineID] = true
+			lineIDs = append(lineIDs, s.LineID.String())
+		}
+	}
+
+	if len(lineIDs) > 0 {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE bank_statement_lines SET status = 'suggested'
+			WHERE id = ANY($1::uuid[]) AND status = 'unmatched'`, pq.Array(lineIDs))
+		if err != nil {
+			return 0, saveError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, saveError(err)
+	}
+
+	return created, nil
+}
+
+// ListSuggestions lists the suggestions for the lines of a statement, best
+// first per line
+func (r *statementRepository) ListSuggestions(ctx context.Context, statementID uuid.UUID, status *models.SuggestionStatus) ([]models.Suggestion, error) {
+	query := `
+		SELECT s.*, i.invoice_number
+		FROM bank_reconciliation_suggestions s
+		JOIN bank_statement_lines l ON l.id = s.line_id
+		JOIN invoices i ON i.id = s.invoice_id
+		WHERE l.statement_id = $1 AND ($2::text IS NULL OR s.status = $2)
+		ORDER BY l.line_number, s.score DESC, s.created_at`
+
+	suggestions := []models.Suggestion{}
+	if err := r.db.SelectContext(ctx, &suggestions, query, statementID, status); err != nil {
+		return nil, banking.BankingErrors.New(banking.ErrBankingListFailed).
+			WithDetail("statement_id", statementID.String()).
+			WithCause(err)
+	}
+
+	return suggestions, nil
+}
+
+// AcceptSuggestion reconciles a statement line with an invoice. The first
+// accepted suggestion of a line records the line as a bank transfer payment
+// allocated to the invoice; later ones allocate more of the same payment.
+// Everything happens in one transaction, so a failed allocation leaves the
+// suggestion pending.
+func (r *statementRepository) AcceptSuggestion(ctx context.Context, id, reviewedBy uuid.UUID) (*models.Suggestion, *models.StatementLine, error) {
+	reconcileError := func(err error) error {
+		return banking.BankingErrors.New(banking.ErrReconcileFailed).
+			WithDetail("suggestion_id", id.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, reconcileError(err)
+	}
+	defer tx.Rollback()
+
+	suggestion, line, err := lockPendingSuggestion(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allocations := []paymentmodels.Allocation{{
+		InvoiceID: suggestion.InvoiceID,
+		Amount:    suggestion.Amount,
+		CreatedBy: &reviewedBy,
+	}}
+
+	paymentID := line.PaymentID
+	if paymentID == nil {
+		now := time.Now()
+		notes := fmt.Sprintf("Reconciled from bank statement line %d", line.LineNumber)
+		payment, err := paymentspg.RecordPayment(ctx, tx, &paymentmodels.Payment{
+			OrganizationID: line.OrganizationID,
+			ProviderID:     suggestion.ProviderID,
+			Method:         paymentmodels.MethodBankTransfer,
+			PaymentDate:    line.BookingDate,
+			Amount:         line.Amount,
+			CurrencyCode:   line.CurrencyCode,
+			Reference:      line.Reference(),
+			Notes:          &notes,
+			CreatedBy:      &reviewedBy,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			Allocations:    allocations,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		paymentID = &payment.ID
+	} else if _, err := paymentspg.AddAllocations(ctx, tx, *paymentID, allocations); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE bank_statement_lines SET payment_id = $2 WHERE id = $1`, line.ID, *paymentID); err != nil {
+		return nil, nil, reconcileError(err)
+	}
+
+	reviewed, updatedLine, err := reviewSuggestion(ctx, tx, suggestion, models.SuggestionAccepted, reviewedBy)
+	if err != nil {
+		return nil, nil, reconcileError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, reconcileError(err)
+	}
+
+	return reviewed, updatedLine, nil
+}
+
+// RejectSuggestion dismisses a suggestion; the pair is not proposed again
+func (r *statementRepository) RejectSuggestion(ctx context.Context, id, reviewedBy uuid.UUID) (*models.Suggestion, *models.StatementLine, error) {
+	reconcileError := func(err error) error {
+		return banking.BankingErrors.New(banking.ErrReconcileFailed).
+			WithDetail("suggestion_id", id.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, reconcileError(err)
+	}
+	defer tx.Rollback()
+
+	suggestion, _, err := lockPendingSuggestion(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reviewed, line, err := reviewSuggestion(ctx, tx, suggestion, models.SuggestionRejected, reviewedBy)
+	if err != nil {
+		return nil, nil, reconcileError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, reconcileError(err)
+	}
+
+	return reviewed, line, nil
+}
+
+// lockPendingSuggestion locks a suggestion and then its line, failing when
+// the suggestion was already reviewed
+func lockPendingSuggestion(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (*models.Suggestion, *models.StatementLine, error) {
+	lockError := func(err error) error {
+		return banking.BankingErrors.New(banking.ErrReconcileFailed).
+			WithDetail("suggestion_id", id.String()).
+			WithCause(err)
+	}
+
+	query := `
+		SELECT s.*, i.invoice_number
+		FROM bank_reconciliation_suggestions s
+		JOIN invoices i ON i.id = s.invoice_id
+		WHERE s.id = $1
+		FOR UPDATE OF s`
+
+	var suggestion models.Suggestion
+	if err := tx.GetContext(ctx, &suggestion, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, banking.BankingErrors.New(banking.ErrSuggestionNotFound).
+				WithDetail("suggestion_id", id.String())
+		}
+		return nil, nil, lockError(err)
+	}
+
+	if suggestion.Status != models.SuggestionPending {
+		return nil, nil, banking.BankingErrors.New(banking.ErrSuggestionReviewed).
+			WithDetail("suggestion_id", id.String()).
+			WithDetail("status", string(suggestion.Status))
+	}
+
+	var line models.StatementLine
+	err := tx.GetContext(ctx, &line, `SELECT * FROM bank_statement_lines WHERE id = $1 FOR UPDATE`, suggestion.LineID)
+	if err != nil {
+		return nil, nil, lockError(err)
+	}
+
+	return &suggestion, &line, nil
+}
+
+// reviewSuggestion records the decision on a suggestion and derives the
+// status of its line: matched once paid, suggested while proposals are
+// pending, unmatched otherwise
+func reviewSuggestion(ctx context.Context, tx *sqlx.Tx, suggestion *models.Suggestion, status models.SuggestionStatus, reviewedBy uuid.UUID) (*models.Suggestion, *models.StatementLine, error) {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE bank_reconciliation_suggestions
+		SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE id = $1`, suggestion.ID, status, reviewedBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var line models.StatementLine
+	err = tx.GetContext(ctx, &line, `
+		UPDATE bank_statement_lines l
+		SET status = CASE
+			WHEN l.payment_id IS NOT NULL THEN 'matched'
+			WHEN EXISTS (
+				SELECT 1 FROM bank_reconciliation_suggestions s
+				WHERE s.line_id = l.id AND s.status = 'pending'
+			) THEN 'suggested'
+			ELSE 'unmatched'
+		END
+		WHERE l.id = $1
+		RETURNING *`, suggestion.LineID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reviewed models.Suggestion
+	err = tx.GetContext(ctx, &reviewed, `
+		SELECT s.*, i.invoice_number
+		FROM bank_reconciliation_suggestions s
+		JOIN invoices i ON i.id = s.invoice_id
+		WHERE s.id = $1`, suggestion.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &reviewed, &line, nil
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/banking/dto"
+	"github.com/Abraxas-365/fuckturamelo/banking/matcher"
+	"github.com/Abraxas-365/fuckturamelo/banking/models"
+)
+
+// StatementRepository defines the interface for bank statement repository
+// operations
+type StatementRepository interface {
+	// Statement operations
+	Import(ctx context.Context, statements []*models.Statement) ([]*models.Statement, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Statement, error)
+	GetSummary(ctx context.Context, id uuid.UUID) (*models.StatementSummary, error)
+	List(ctx context.Context, req *dto.StatementListRequest) (*dto.StatementListResponse, error)
+	ListLines(ctx context.Context, statementID uuid.UUID) ([]models.StatementLine, error)
+
+	// Reconciliation operations
+	OpenInvoices(ctx context.Context, orgID uuid.UUID, currencies []string, issuedUntil time.Time) ([]matcher.Invoice, error)
+	SaveSuggestions(ctx context.Context, suggestions []models.Suggestion) (int, error)
+	ListSuggestions(ctx context.Context, statementID uuid.UUID, status *models.SuggestionStatus) ([]models.Suggestion, error)
+	AcceptSuggestion(ctx context.Context, id, reviewedBy uuid.UUID) (*models.Suggestion, *models.StatementLine, error)
+	RejectSuggestion(ctx context.Context, id, reviewedBy uuid.UUID) (*models.Suggestion, *models.StatementLine, error)
+}
+
+// BatchRepository defines the interface for payment batch and bank file
+// layout repository operations
+type BatchRepository interface {
+	// Batch operations
+	PayableInvoices(ctx context.Context, invoiceIDs []uuid.UUID) ([]models.PayableInvoice, error)
+	CreateBatch(ctx context.Context, batch *models.PaymentBatch) (*models.PaymentBatch, error)
+	GetBatch(ctx context.Context, id uuid.UUID) (*models.PaymentBatch, error)
+	ListBatches(ctx context.Context, req *dto.BatchListRequest) (*dto.BatchListResponse, error)
+	UpdateBatchStatus(ctx context.Context, id uuid.UUID, status models.BatchStatus, changedBy uuid.UUID) (*models.PaymentBatch, error)
+
+	// Layout operations
+	CreateLayout(ctx context.Context, layout *models.BankFileLayout) (*models.BankFileLayout, error)
+	GetLayout(ctx context.Context, id uuid.UUID) (*models.BankFileLayout, error)
+	ListLayouts(ctx context.Context, orgID uuid.UUID) ([]models.BankFileLayout, error)
+	DeleteLayout(ctx context.Context, id uuid.UUID) error
+}
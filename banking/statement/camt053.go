@@ -0,0 +1,297 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CAMT.053 (ISO 20022 BankToCustomerStatement) elements. Tags carry no
+// namespace so every camt.053.001.xx version decodes.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID         string        `xml:"Id"`
+	ElectSeqNb string        `xml:"ElctrncSeqNb"`
+	From       string        `xml:"FrToDt>FrDtTm"`
+	To         string        `xml:"FrToDt>ToDtTm"`
+	IBAN       string        `xml:"Acct>Id>IBAN"`
+	OtherID    string        `xml:"Acct>Id>Othr>Id"`
+	Currency   string        `xml:"Acct>Ccy"`
+	Balances   []camtBalance `xml:"Bal"`
+	Entries    []camtEntry   `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtBalance struct {
+	Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      camtDate   `xml:"Dt"`
+}
+
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+type camtEntry struct {
+	Amount         camtAmount        `xml:"Amt"`
+	Indicator      string            `xml:"CdtDbtInd"`
+	Status         camtStatus        `xml:"Sts"`
+	BookingDate    camtDate          `xml:"BookgDt"`
+	ValueDate      camtDate          `xml:"ValDt"`
+	ServicerRef    string            `xml:"AcctSvcrRef"`
+	AdditionalInfo string            `xml:"AddtlNtryInf"`
+	Transactions   []camtTransaction `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTransaction struct {
+	EndToEndID     string     `xml:"Refs>EndToEndId"`
+	ServicerRef    string     `xml:"Refs>AcctSvcrRef"`
+	Amount         camtAmount `xml:"Amt"`
+	DetailAmount   camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	Indicator      string     `xml:"CdtDbtInd"`
+	CreditorName   string     `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty    string     `xml:"RltdPties>Cdtr>Pty>Nm"`
+	CreditorIBAN   string     `xml:"RltdPties>CdtrAcct>Id>IBAN"`
+	CreditorOther  string     `xml:"RltdPties>CdtrAcct>Id>Othr>Id"`
+	DebtorName     string     `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty      string     `xml:"RltdPties>Dbtr>Pty>Nm"`
+	DebtorIBAN     string     `xml:"RltdPties>DbtrAcct>Id>IBAN"`
+	DebtorOther    string     `xml:"RltdPties>DbtrAcct>Id>Othr>Id"`
+	Unstructured   []string   `xml:"RmtInf>Ustrd"`
+	CreditorRefs   []string   `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	DocumentNumber []string   `xml:"RmtInf>Strd>RfrdDocInf>Nb"`
+	AdditionalInfo string     `xml:"AddtlTxInf"`
+}
+
+// parseCAMT053 reads the booked entries of every Stmt of a camt.053 document.
+// Batch bookings whose transaction details carry their own amounts are split
+// into one entry per transaction.
+func parseCAMT053(data []byte) ([]Statement, error) {
+	var doc camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = latin1Charset
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, &Error{Format: FormatCAMT053, Message: "invalid XML: " + err.Error()}
+	}
+
+	statements := make([]Statement, 0, len(doc.Statements))
+	for i, raw := range doc.Statements {
+		fail := func(msg string) error {
+			return &Error{Format: FormatCAMT053, Message: fmt.Sprintf("statement %d: %s", i+1, msg)}
+		}
+
+		stmt := Statement{
+			Reference: firstNonEmpty(raw.ID, raw.ElectSeqNb),
+			Account:   firstNonEmpty(raw.IBAN, raw.OtherID),
+			Currency:  raw.Currency,
+		}
+
+		if raw.From != "" && raw.To != "" {
+			from, errFrom := parseISODate(raw.From)
+			to, errTo := parseISODate(raw.To)
+			if errFrom != nil || errTo != nil {
+				return nil, fail("invalid FrToDt")
+			}
+			stmt.PeriodStart, stmt.PeriodEnd = &from, &to
+		}
+
+		for _, bal := range raw.Balances {
+			balance, err := camtParseBalance(bal)
+			if err != nil {
+				return nil, fail(err.Error())
+			}
+			switch strings.TrimSpace(bal.Code) {
+			case "OPBD", "PRCD":
+				if stmt.Opening == nil {
+					stmt.Opening = balance
+				}
+			case "CLBD":
+				stmt.Closing = balance
+			}
+			if stmt.Currency == "" {
+				stmt.Currency = bal.Amount.Currency
+			}
+		}
+
+		for j, raw := range raw.Entries {
+			entries, err := camtParseEntry(raw)
+			if err != nil {
+				return nil, fail(fmt.Sprintf("entry %d: %s", j+1, err.Error()))
+			}
+			stmt.Entries = append(stmt.Entries, entries...)
+		}
+
+		statements = append(statements, stmt)
+	}
+
+	return statements, nil
+}
+
+func camtParseBalance(bal camtBalance) (*Balance, error) {
+	amount, err := parseAmount(bal.Amount.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance amount %q", bal.Amount.Value)
+	}
+	if strings.TrimSpace(bal.Indicator) == "DBIT" {
+		amount = amount.Neg()
+	}
+
+	date, err := camtParseDate(bal.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance date")
+	}
+
+	return &Balance{Amount: amount, Date: date}, nil
+}
+
+func camtParseEntry(raw camtEntry) ([]Entry, error) {
+	// Pending and informational entries have not moved money yet
+	status := firstNonEmpty(raw.Status.Code, raw.Status.Text)
+	if status != "" && status != "BOOK" {
+		return nil, nil
+	}
+
+	direction, err := camtDirection(raw.Indicator)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(raw.Amount.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount %q", raw.Amount.Value)
+	}
+
+	bookingDate, err := camtParseDate(raw.BookingDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid booking date")
+	}
+
+	base := Entry{
+		BookingDate:    bookingDate,
+		Amount:         amount,
+		Direction:      direction,
+		Currency:       raw.Amount.Currency,
+		BankReference:  strings.TrimSpace(raw.ServicerRef),
+		RemittanceInfo: raw.AdditionalInfo,
+	}
+	if raw.ValueDate.Date != "" || raw.ValueDate.DateTime != "" {
+		if valueDate, err := camtParseDate(raw.ValueDate); err == nil {
+			base.ValueDate = &valueDate
+		}
+	}
+
+	if len(raw.Transactions) == 0 {
+		return []Entry{base}, nil
+	}
+
+	split := len(raw.Transactions) > 1
+	for _, tx := range raw.Transactions {
+		if firstNonEmpty(tx.Amount.Value, tx.DetailAmount.Value) == "" {
+			split = false
+		}
+	}
+	if !split {
+		camtApplyTransaction(&base, raw.Transactions[0], base.Direction)
+		return []Entry{base}, nil
+	}
+
+	entries := make([]Entry, 0, len(raw.Transactions))
+	for _, tx := range raw.Transactions {
+		entry := base
+
+		txAmount := tx.Amount
+		if txAmount.Value == "" {
+			txAmount = tx.DetailAmount
+		}
+		if entry.Amount, err = parseAmount(txAmount.Value); err != nil {
+			return nil, fmt.Errorf("invalid transaction amount %q", txAmount.Value)
+		}
+		if txAmount.Currency != "" {
+			entry.Currency = txAmount.Currency
+		}
+		if tx.Indicator != "" {
+			if entry.Direction, err = camtDirection(tx.Indicator); err != nil {
+				return nil, err
+			}
+		}
+
+		camtApplyTransaction(&entry, tx, entry.Direction)
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// camtApplyTransaction copies references, the counterparty and the
+// remittance information of a transaction into an entry. The counterparty of
+// a debit is the creditor, the one of a credit the debtor.
+func camtApplyTransaction(entry *Entry, tx camtTransaction, direction Direction) {
+	entry.EndToEndID = strings.TrimSpace(tx.EndToEndID)
+	if entry.EndToEndID == "NOTPROVIDED" {
+		entry.EndToEndID = ""
+	}
+	entry.BankReference = firstNonEmpty(tx.ServicerRef, entry.BankReference)
+
+	if direction == Debit {
+		entry.CounterpartyName = firstNonEmpty(tx.CreditorName, tx.CreditorPty)
+		entry.CounterpartyAccount = firstNonEmpty(tx.CreditorIBAN, tx.CreditorOther)
+	} else {
+		entry.CounterpartyName = firstNonEmpty(tx.DebtorName, tx.DebtorPty)
+		entry.CounterpartyAccount = firstNonEmpty(tx.DebtorIBAN, tx.DebtorOther)
+	}
+
+	parts := append([]string{}, tx.Unstructured...)
+	parts = append(parts, tx.CreditorRefs...)
+	parts = append(parts, tx.DocumentNumber...)
+	parts = append(parts, tx.AdditionalInfo, entry.RemittanceInfo)
+	entry.RemittanceInfo = strings.Join(parts, " ")
+}
+
+func camtDirection(indicator string) (Direction, error) {
+	switch strings.TrimSpace(indicator) {
+	case "CRDT":
+		return Credit, nil
+	case "DBIT":
+		return Debit, nil
+	default:
+		return "", fmt.Errorf("invalid CdtDbtInd %q", indicator)
+	}
+}
+
+func camtParseDate(date camtDate) (time.Time, error) {
+	return parseISODate(firstNonEmpty(date.Date, date.DateTime))
+}
+
+// latin1Charset decodes the ISO-8859-1 and Windows-1252 encodings some banks
+// declare instead of UTF-8. Windows-1252 only differs in punctuation, which is
+// irrelevant to the references remittance text is searched for.
+func latin1Charset(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso8859-1", "latin1", "windows-1252", "cp1252":
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(decodeLatin1(data)), nil
+}
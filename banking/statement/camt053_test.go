@@ -0,0 +1,52 @@
+package statement
+
+import "testing"
+
+func TestParseCAMT053(t *testing.T) {
+	s := parseFixture(t, "camt053.xml", FormatCAMT053)
+
+	if s.Reference != "STMT-1" {
+		t.Errorf("reference = %q; want STMT-1", s.Reference)
+	}
+	if s.Account != "PE1234" {
+		t.Errorf("account = %q; want the IBAN PE1234", s.Account)
+	}
+	if s.Currency != "PEN" {
+		t.Errorf("currency = %q; want PEN", s.Currency)
+	}
+
+	// A DBIT closing balance is overdrawn
+	checkBalance(t, "opening", s.Opening, "500.00", "2023-02-01")
+	checkBalance(t, "closing", s.Closing, "-100.00", "2023-02-28")
+	checkPeriod(t, s, "2023-02-01", "2023-02-28")
+
+	// The batched entry splits into its transactions, which keep the
+	// entry's servicer reference; the pending entry is left out and
+	// NOTPROVIDED is no end-to-end ID
+	checkEntries(t, s.Entries, []wantEntry{
+		{
+			date:           "2023-02-10",
+			amount:         "400.00",
+			direction:      Debit,
+			bankReference:  "REF9",
+			endToEndID:     "E2E-1",
+			counterparty:   "Proveedor Uno",
+			remittanceInfo: "F001-00000045 RUC 20111111111",
+		},
+		{
+			date:           "2023-02-10",
+			amount:         "200.00",
+			direction:      Debit,
+			bankReference:  "REF9",
+			counterparty:   "Proveedor Dos",
+			remittanceInfo: "E001-7",
+		},
+	}, "PEN")
+
+	if got := s.Entries[0].CounterpartyAccount; got != "PE999" {
+		t.Errorf("counterparty account = %q; want PE999", got)
+	}
+	if s.Entries[0].ValueDate == nil {
+		t.Error("value date missing")
+	}
+}
@@ -0,0 +1,297 @@
+package statement
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// :TAG:content at the start of a line
+	mt940FieldPattern = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):(.*)$`)
+
+	// :61: value date, optional entry date, debit/credit mark, optional funds
+	// code, amount, transaction type, customer reference, optional bank
+	// reference and optional supplementary details on the next line
+	mt940LinePattern = regexp.MustCompile(
+		`^([0-9]{6})([0-9]{4})?(RC|RD|C|D)([A-Z])?([0-9]+,[0-9]*)([NFS][A-Z0-9]{3})([^\n]*?)(?://([^\n]*))?(?:\n((?s:.*)))?$`)
+
+	// :60F:, :62F: and friends: mark, date, currency and amount
+	mt940BalancePattern = regexp.MustCompile(`^(C|D)([0-9]{6})([A-Z]{3})([0-9]+,[0-9]*)`)
+
+	// Structured :86: subfields such as ?20 or ?32
+	mt940SubfieldPattern = regexp.MustCompile(`\?([0-9]{2})`)
+)
+
+// mt940Field is a tag of an MT940 message with its content, continuation
+// lines joined by newlines
+type mt940Field struct {
+	tag     string
+	content string
+	line    int
+}
+
+// parseMT940 reads the statements of an MT940 file. Messages may be wrapped
+// in SWIFT blocks ({1:...}{4:...-}) or be bare field sequences separated by
+// "-" lines, as most banks export them.
+func parseMT940(data []byte) ([]Statement, error) {
+	fields := mt940Fields(string(data))
+
+	var (
+		statements []Statement
+		current    *Statement
+		reference  string
+	)
+
+	flush := func() {
+		if current != nil {
+			statements = append(statements, *current)
+		}
+		current = nil
+	}
+
+	for _, field := range fields {
+		fail := func(msg string) error {
+			return &Error{Format: FormatMT940, Line: field.line, Message: fmt.Sprintf(":%s: %s", field.tag, msg)}
+		}
+
+		if field.tag == "20" {
+			flush()
+			reference = strings.TrimSpace(field.content)
+			current = &Statement{Reference: reference}
+			continue
+		}
+		if field.tag == "-" {
+			flush()
+			continue
+		}
+		if current == nil {
+			// Tolerate files that omit :20: before the account
+			current = &Statement{Reference: reference}
+		}
+
+		switch field.tag {
+		case "25":
+			current.Account = strings.TrimSpace(field.content)
+		case "28C", "28":
+			if number := strings.TrimSpace(field.content); number != "" {
+				current.Reference = number
+			}
+		case "60F", "60M":
+			balance, currency, err := mt940ParseBalance(field.content)
+			if err != nil {
+				return nil, fail(err.Error())
+			}
+			if current.Opening == nil {
+				current.Opening = balance
+			}
+			if current.Currency == "" {
+				current.Currency = currency
+			}
+		case "62F", "62M":
+			balance, currency, err := mt940ParseBalance(field.content)
+			if err != nil {
+				return nil, fail(err.Error())
+			}
+			current.Closing = balance
+			if current.Currency == "" {
+				current.Currency = currency
+			}
+		case "61":
+			entry, err := mt940ParseLine(field.content)
+			if err != nil {
+				return nil, fail(err.Error())
+			}
+			current.Entries = append(current.Entries, *entry)
+		case "86":
+			// Information to the account owner belongs to the preceding line
+			if n := len(current.Entries); n > 0 {
+				mt940ApplyInformation(&current.Entries[n-1], field.content)
+			}
+		}
+	}
+	flush()
+
+	// Drop empty shells left by block wrappers
+	result := statements[:0]
+	for _, stmt := range statements {
+		if stmt.Account != "" || len(stmt.Entries) > 0 {
+			result = append(result, stmt)
+		}
+	}
+
+	return result, nil
+}
+
+// mt940Fields splits a file into fields. A "-" field marks the end of a
+// message.
+func mt940Fields(text string) []mt940Field {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var fields []mt940Field
+	for i, line := range strings.Split(text, "\n") {
+		lineNumber := i + 1
+		trimmed := strings.TrimRight(line, " \t")
+
+		// Strip SWIFT block headers in front of the first field
+		if idx := strings.Index(trimmed, "{4:"); idx >= 0 {
+			trimmed = trimmed[idx+3:]
+		}
+
+		switch {
+		case trimmed == "-" || strings.HasPrefix(trimmed, "-}"):
+			fields = append(fields, mt940Field{tag: "-", line: lineNumber})
+		case strings.HasPrefix(trimmed, "{"):
+			continue
+		case mt940FieldPattern.MatchString(trimmed):
+			m := mt940FieldPattern.FindStringSubmatch(trimmed)
+			fields = append(fields, mt940Field{tag: m[1], content: m[2], line: lineNumber})
+		case len(fields) > 0 && fields[len(fields)-1].tag != "-" && trimmed != "":
+			fields[len(fields)-1].content += "\n" + trimmed
+		}
+	}
+
+	return fields
+}
+
+func mt940ParseBalance(content string) (*Balance, string, error) {
+	m := mt940BalancePattern.FindStringSubmatch(strings.TrimSpace(content))
+	if m == nil {
+		return nil, "", fmt.Errorf("invalid balance %q", content)
+	}
+
+	date, err := mt940ParseDate(m[2])
+	if err != nil {
+		return nil, "", err
+	}
+
+	amount, err := parseAmount(m[4])
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid amount %q", m[4])
+	}
+	if m[1] == "D" {
+		amount = amount.Neg()
+	}
+
+	return &Balance{Amount: amount, Date: date}, m[3], nil
+}
+
+func mt940ParseLine(content string) (*Entry, error) {
+	m := mt940LinePattern.FindStringSubmatch(content)
+	if m == nil {
+		return nil, fmt.Errorf("invalid statement line %q", strings.SplitN(content, "\n", 2)[0])
+	}
+
+	valueDate, err := mt940ParseDate(m[1])
+	if err != nil {
+		return nil, err
+	}
+
+	bookingDate := valueDate
+	if m[2] != "" {
+		if bookingDate, err = mt940EntryDate(valueDate, m[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	amount, err := parseAmount(m[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount %q", m[5])
+	}
+
+	// A reversed credit takes money out, a reversed debit brings it back
+	direction := Credit
+	if m[3] == "D" || m[3] == "RC" {
+		direction = Debit
+	}
+
+	entry := &Entry{
+		BookingDate:    bookingDate,
+		ValueDate:      &valueDate,
+		Amount:         amount,
+		Direction:      direction,
+		BankReference:  strings.TrimSpace(m[8]),
+		RemittanceInfo: strings.TrimSpace(m[9]),
+	}
+	if ref := strings.TrimSpace(m[7]); ref != "" && ref != "NONREF" {
+		entry.EndToEndID = ref
+	}
+
+	return entry, nil
+}
+
+// mt940ApplyInformation reads a :86: field. Structured content (a three
+// digit transaction code followed by ?NN subfields, as German banks send it)
+// yields the counterparty and remittance text; anything else is remittance
+// text as is.
+func mt940ApplyInformation(entry *Entry, content string) {
+	text := strings.ReplaceAll(content, "\n", "")
+	if len(text) < 4 || !isDigits(text[:3]) || text[3] != '?' {
+		entry.RemittanceInfo = strings.TrimSpace(entry.RemittanceInfo + " " + strings.ReplaceAll(content, "\n", " "))
+		return
+	}
+
+	var remittance, name []string
+	locations := mt940SubfieldPattern.FindAllStringSubmatchIndex(text, -1)
+	for i, loc := range locations {
+		end := len(text)
+		if i+1 < len(locations) {
+			end = locations[i+1][0]
+		}
+		code, _ := strconv.Atoi(text[loc[2]:loc[3]])
+		value := text[loc[1]:end]
+
+		switch {
+		case code >= 20 && code <= 29, code >= 60 && code <= 63:
+			remittance = append(remittance, value)
+		case code == 31:
+			entry.CounterpartyAccount = strings.TrimSpace(value)
+		case code == 32 || code == 33:
+			name = append(name, value)
+		}
+	}
+
+	entry.RemittanceInfo = strings.TrimSpace(entry.RemittanceInfo + " " + strings.Join(remittance, ""))
+	entry.CounterpartyName = strings.Join(name, "")
+}
+
+// mt940ParseDate reads a YYMMDD date; years below 80 are in this century
+func mt940ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse("060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return dateOnly(date), nil
+}
+
+// mt940EntryDate completes an MMDD entry date with the year of the value
+// date, moving across the turn of the year when the two straddle it
+func mt940EntryDate(valueDate time.Time, mmdd string) (time.Time, error) {
+	month, _ := strconv.Atoi(mmdd[:2])
+	day, _ := strconv.Atoi(mmdd[2:])
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, fmt.Errorf("invalid entry date %q", mmdd)
+	}
+
+	year := valueDate.Year()
+	switch {
+	case month == 12 && valueDate.Month() == time.January:
+		year--
+	case month == 1 && valueDate.Month() == time.December:
+		year++
+	}
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,61 @@
+package statement
+
+import "testing"
+
+func TestParseMT940(t *testing.T) {
+	s := parseFixture(t, "mt940.sta", FormatMT940)
+
+	if s.Reference != "00001/001" {
+		t.Errorf("reference = %q; want the statement number 00001/001", s.Reference)
+	}
+	if s.Account != "10020030/1234567" {
+		t.Errorf("account = %q; want 10020030/1234567", s.Account)
+	}
+	if s.Currency != "EUR" {
+		t.Errorf("currency = %q; want EUR", s.Currency)
+	}
+
+	checkBalance(t, "opening", s.Opening, "1000.00", "2023-01-31")
+	checkBalance(t, "closing", s.Closing, "899.75", "2023-02-02")
+
+	// Without a stated period, the period spans the booked entries
+	checkPeriod(t, s, "2023-02-01", "2023-02-02")
+
+	// The structured :86: subfields give the counterparty and remittance
+	// text, joined with the supplementary details of :61:. NONREF is no
+	// customer reference; "50," has no decimals.
+	checkEntries(t, s.Entries, []wantEntry{
+		{
+			date:           "2023-02-01",
+			amount:         "150.25",
+			direction:      Debit,
+			bankReference:  "BR-1",
+			counterparty:   "ACME S.A.C.",
+			remittanceInfo: "SUPPL SVWZ+Rechnung F001-000123 RUC 20123456789",
+		},
+		{
+			date:           "2023-02-02",
+			amount:         "50",
+			direction:      Credit,
+			endToEndID:     "REF2",
+			remittanceInfo: "Refund from supplier",
+		},
+	}, "EUR")
+
+	if got := s.Entries[0].CounterpartyAccount; got != "DE89370400440532013000" {
+		t.Errorf("counterparty account = %q; want DE89370400440532013000", got)
+	}
+}
+
+func TestParseMT940Latin1(t *testing.T) {
+	data := []byte(":20:STMT\r\n:25:ACC-1\r\n:28C:1\r\n:60F:C230131EUR0,00\r\n" +
+		":61:2302010201C10,00NTRFNONREF\r\n:86:M\xfcller\r\n:62F:C230201EUR10,00\r\n-")
+
+	statements, err := Parse(FormatMT940, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := statements[0].Entries[0].RemittanceInfo; got != "Müller" {
+		t.Errorf("remittance info = %q; want Müller decoded from Latin-1", got)
+	}
+}
@@ -0,0 +1,244 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ofxNode is an element of an OFX document. OFX 1.x is SGML where leaf
+// elements have no closing tag; OFX 2.x is XML. Both read into the same tree.
+type ofxNode struct {
+	name     string
+	value    string
+	children []*ofxNode
+}
+
+// child returns the first descendant found by following the path of names
+func (n *ofxNode) child(path ...string) *ofxNode {
+	node := n
+	for _, name := range path {
+		var next *ofxNode
+		for _, c := range node.children {
+			if c.name == name {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// text returns the value at the end of a path, or "" when it is missing
+func (n *ofxNode) text(path ...string) string {
+	if node := n.child(path...); node != nil {
+		return node.value
+	}
+	return ""
+}
+
+// findAll returns every descendant with one of the given names, in document
+// order, without descending into matches
+func (n *ofxNode) findAll(names ...string) []*ofxNode {
+	var found []*ofxNode
+	for _, c := range n.children {
+		if slices.Contains(names, c.name) {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, c.findAll(names...)...)
+	}
+	return found
+}
+
+// parseOFX reads the bank and credit card statements of an OFX file
+func parseOFX(data []byte) ([]Statement, error) {
+	root, err := ofxParseTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []Statement
+	for _, response := range root.findAll("STMTTRNRS", "CCSTMTTRNRS") {
+		rs := response.child("STMTRS")
+		if rs == nil {
+			rs = response.child("CCSTMTRS")
+		}
+		if rs == nil {
+			continue
+		}
+
+		stmt := Statement{
+			Account:  firstNonEmpty(rs.text("BANKACCTFROM", "ACCTID"), rs.text("CCACCTFROM", "ACCTID")),
+			Currency: rs.text("CURDEF"),
+		}
+		if trnuid := response.text("TRNUID"); trnuid != "0" {
+			stmt.Reference = trnuid
+		}
+		if bankID := rs.text("BANKACCTFROM", "BANKID"); bankID != "" && stmt.Account != "" {
+			stmt.Account = bankID + "/" + stmt.Account
+		}
+
+		if list := rs.child("BANKTRANLIST"); list != nil {
+			start, errStart := ofxParseDate(list.text("DTSTART"))
+			end, errEnd := ofxParseDate(list.text("DTEND"))
+			if errStart == nil && errEnd == nil {
+				stmt.PeriodStart, stmt.PeriodEnd = &start, &end
+			}
+
+			for i, trn := range list.findAll("STMTTRN") {
+				entry, err := ofxParseTransaction(trn)
+				if err != nil {
+					return nil, &Error{Format: FormatOFX, Message: fmt.Sprintf("transaction %d: %s", i+1, err.Error())}
+				}
+				stmt.Entries = append(stmt.Entries, *entry)
+			}
+		}
+
+		if ledger := rs.child("LEDGERBAL"); ledger != nil {
+			amount, err := parseAmount(ledger.text("BALAMT"))
+			if err != nil {
+				return nil, &Error{Format: FormatOFX, Message: "invalid LEDGERBAL amount"}
+			}
+			date, err := ofxParseDate(ledger.text("DTASOF"))
+			if err != nil {
+				return nil, &Error{Format: FormatOFX, Message: "invalid LEDGERBAL date"}
+			}
+			stmt.Closing = &Balance{Amount: amount, Date: date}
+		}
+
+		statements = append(statements, stmt)
+	}
+
+	return statements, nil
+}
+
+func ofxParseTransaction(trn *ofxNode) (*Entry, error) {
+	date, err := ofxParseDate(trn.text("DTPOSTED"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Amounts are signed: negative transactions debit the account
+	amount, err := parseAmount(trn.text("TRNAMT"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRNAMT %q", trn.text("TRNAMT"))
+	}
+	direction := Credit
+	if amount.IsNegative() {
+		direction = Debit
+		amount = amount.Neg()
+	}
+
+	entry := &Entry{
+		BookingDate:      date,
+		Amount:           amount,
+		Direction:        direction,
+		Currency:         firstNonEmpty(trn.text("CURRENCY", "CURSYM"), trn.text("ORIGCURRENCY", "CURSYM")),
+		BankReference:    trn.text("FITID"),
+		EndToEndID:       firstNonEmpty(trn.text("REFNUM"), trn.text("CHECKNUM")),
+		CounterpartyName: firstNonEmpty(trn.text("NAME"), trn.text("PAYEE", "NAME")),
+		RemittanceInfo:   trn.text("MEMO"),
+	}
+	if userDate, err := ofxParseDate(trn.text("DTUSER")); err == nil {
+		entry.ValueDate = &userDate
+	}
+	if account := trn.child("BANKACCTTO"); account != nil {
+		entry.CounterpartyAccount = account.text("ACCTID")
+	}
+
+	return entry, nil
+}
+
+// ofxParseTree tokenizes an OFX document into a tree. An element followed by
+// text is a leaf, whether or not a closing tag follows; any other element is
+// an aggregate closed by its end tag. Unbalanced end tags close every
+// aggregate up to the matching one.
+func ofxParseTree(data []byte) (*ofxNode, error) {
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX>"))
+	if start < 0 {
+		return nil, &Error{Format: FormatOFX, Message: "missing <OFX> element"}
+	}
+	doc := string(data[start:])
+
+	root := &ofxNode{name: "ROOT"}
+	stack := []*ofxNode{root}
+	var pending *ofxNode
+
+	for len(doc) > 0 {
+		open := strings.IndexByte(doc, '<')
+		if open < 0 {
+			break
+		}
+		closeIdx := strings.IndexByte(doc[open:], '>')
+		if closeIdx < 0 {
+			return nil, &Error{Format: FormatOFX, Message: "unterminated tag"}
+		}
+		tag := strings.TrimSpace(doc[open+1 : open+closeIdx])
+		doc = doc[open+closeIdx+1:]
+
+		text := doc
+		if next := strings.IndexByte(doc, '<'); next >= 0 {
+			text = doc[:next]
+		}
+		text = strings.TrimSpace(text)
+
+		switch {
+		case tag == "" || tag[0] == '?' || tag[0] == '!':
+			continue
+
+		case tag[0] == '/':
+			name := strings.ToUpper(strings.TrimSpace(tag[1:]))
+			if pending != nil && pending.name == name {
+				// </NAME> right after <NAME>: a leaf, empty or explicitly closed
+				if top := stack[len(stack)-1]; top == pending {
+					stack = stack[:len(stack)-1]
+				}
+				pending = nil
+				continue
+			}
+			pending = nil
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+
+		default:
+			name := strings.ToUpper(strings.Fields(tag)[0])
+			node := &ofxNode{name: name}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			pending = node
+
+			if text != "" {
+				node.value = html.UnescapeString(text)
+			} else {
+				stack = append(stack, node)
+			}
+		}
+	}
+
+	return root, nil
+}
+
+// ofxParseDate reads the date part of YYYYMMDD[HHMMSS[.XXX]][[offset:TZ]]
+func ofxParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
@@ -0,0 +1,41 @@
+package statement
+
+import "testing"
+
+func TestParseOFX(t *testing.T) {
+	s := parseFixture(t, "statement.ofx", FormatOFX)
+
+	if s.Reference != "1001" {
+		t.Errorf("reference = %q; want the transaction UID 1001", s.Reference)
+	}
+	if s.Account != "121000248/987654321" {
+		t.Errorf("account = %q; want bank and account IDs 121000248/987654321", s.Account)
+	}
+	if s.Currency != "USD" {
+		t.Errorf("currency = %q; want USD", s.Currency)
+	}
+
+	// OFX states only the ledger balance
+	checkBalance(t, "opening", s.Opening, "", "")
+	checkBalance(t, "closing", s.Closing, "1000.50", "2023-02-28")
+	checkPeriod(t, s, "2023-02-01", "2023-02-28")
+
+	// The sign of TRNAMT gives the direction; entities are decoded in the
+	// SGML elements
+	checkEntries(t, s.Entries, []wantEntry{
+		{
+			date:           "2023-02-10",
+			amount:         "250.00",
+			direction:      Debit,
+			bankReference:  "T1",
+			counterparty:   "ACME & CO",
+			remittanceInfo: "INV F001-123",
+		},
+		{
+			date:          "2023-02-11",
+			amount:        "10.50",
+			direction:     Credit,
+			bankReference: "T2",
+		},
+	}, "USD")
+}
@@ -0,0 +1,242 @@
+// Package statement parses bank statements in the CAMT.053, MT940 and OFX
+// formats into one representation. Amounts are decimals and always positive;
+// Direction tells money that came in from money that went out.
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// Format is the file format of a bank statement
+type Format string
+
+// Supported statement formats
+const (
+	FormatCAMT053 Format = "camt053"
+	FormatMT940   Format = "mt940"
+	FormatOFX     Format = "ofx"
+)
+
+// Formats returns the supported statement formats
+func Formats() []Format {
+	return []Format{FormatCAMT053, FormatMT940, FormatOFX}
+}
+
+// Valid reports whether the format is supported
+func (f Format) Valid() bool {
+	return slices.Contains(Formats(), f)
+}
+
+// Direction tells whether an entry credited or debited the account
+type Direction string
+
+// Entry directions
+const (
+	Credit Direction = "credit"
+	Debit  Direction = "debit"
+)
+
+// Balance is an account balance on a date; negative amounts are overdrawn
+type Balance struct {
+	Amount decimal.Decimal `json:"amount"`
+	Date   time.Time       `json:"date"`
+}
+
+// Statement is one account statement of a file. A file may hold several.
+type Statement struct {
+	Reference   string
+	Account     string
+	Currency    string
+	Opening     *Balance
+	Closing     *Balance
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	Entries     []Entry
+}
+
+// Entry is a booked movement of a statement
+type Entry struct {
+	BookingDate         time.Time
+	ValueDate           *time.Time
+	Amount              decimal.Decimal
+	Direction           Direction
+	Currency            string
+	BankReference       string
+	EndToEndID          string
+	CounterpartyName    string
+	CounterpartyAccount string
+	RemittanceInfo      string
+}
+
+// Error describes why a statement file could not be parsed. Line is 0 when
+// the position is unknown.
+type Error struct {
+	Format  Format `json:"format"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s line %d: %s", e.Format, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Format, e.Message)
+}
+
+// Detect guesses the format of a statement file from its content
+func Detect(data []byte) (Format, bool) {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	upper := bytes.ToUpper(head)
+
+	switch {
+	case bytes.Contains(head, []byte("BkToCstmrStmt")) || bytes.Contains(head, []byte("camt.053")):
+		return FormatCAMT053, true
+	case bytes.Contains(upper, []byte("OFXHEADER")) || bytes.Contains(upper, []byte("<OFX>")):
+		return FormatOFX, true
+	case bytes.Contains(head, []byte(":20:")) && (bytes.Contains(head, []byte(":60F:")) || bytes.Contains(head, []byte(":61:"))):
+		return FormatMT940, true
+	}
+
+	return "", false
+}
+
+// Parse reads every statement of a file in the given format
+func Parse(format Format, data []byte) ([]Statement, error) {
+	var (
+		statements []Statement
+		err        error
+	)
+
+	// Text formats are ASCII by standard, but banks put Latin-1 umlauts and
+	// accents in names and remittance text
+	if format != FormatCAMT053 && !utf8.Valid(data) {
+		data = decodeLatin1(data)
+	}
+
+	switch format {
+	case FormatCAMT053:
+		statements, err = parseCAMT053(data)
+	case FormatMT940:
+		statements, err = parseMT940(data)
+	case FormatOFX:
+		statements, err = parseOFX(data)
+	default:
+		return nil, &Error{Format: format, Message: "unsupported format"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(statements) == 0 {
+		return nil, &Error{Format: format, Message: "file contains no statements"}
+	}
+
+	for i := range statements {
+		if err := statements[i].finish(format); err != nil {
+			return nil, err
+		}
+	}
+
+	return statements, nil
+}
+
+// finish fills in what a format leaves implicit and checks what every
+// statement needs
+func (s *Statement) finish(format Format) error {
+	s.Account = strings.TrimSpace(s.Account)
+	s.Currency = strings.ToUpper(strings.TrimSpace(s.Currency))
+
+	if s.Account == "" {
+		return &Error{Format: format, Message: "statement has no account identifier"}
+	}
+
+	for i := range s.Entries {
+		entry := &s.Entries[i]
+		if entry.Currency == "" {
+			entry.Currency = s.Currency
+		}
+		if entry.Currency == "" {
+			return &Error{Format: format, Message: fmt.Sprintf("entry %d has no currency", i+1)}
+		}
+		if s.Currency == "" {
+			s.Currency = entry.Currency
+		}
+
+		entry.RemittanceInfo = collapseSpaces(entry.RemittanceInfo)
+		entry.CounterpartyName = collapseSpaces(entry.CounterpartyName)
+
+		// Periods not stated by the file span the booked entries
+		date := entry.BookingDate
+		if s.PeriodStart == nil || date.Before(*s.PeriodStart) {
+			s.PeriodStart = &date
+		}
+		if s.PeriodEnd == nil || date.After(*s.PeriodEnd) {
+			s.PeriodEnd = &date
+		}
+	}
+
+	if s.Currency == "" {
+		return &Error{Format: format, Message: "statement has no currency"}
+	}
+
+	return nil
+}
+
+// Helper functions
+
+// decodeLatin1 converts ISO-8859-1 text to UTF-8
+func decodeLatin1(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return []byte(string(runes))
+}
+
+// dateOnly truncates a time to its calendar date in UTC
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseISODate reads the date part of an ISO 8601 date or date-time
+func parseISODate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 10 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return time.Parse("2006-01-02", value[:10])
+}
+
+// parseAmount reads an unsigned amount that may use a decimal comma
+func parseAmount(value string) (decimal.Decimal, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, ",") && !strings.Contains(value, ".") {
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	// MT940 allows a trailing separator without decimals ("100,")
+	value = strings.TrimSuffix(value, ".")
+	return decimal.NewFromString(value)
+}
+
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if v := strings.TrimSpace(value); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package statement
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// wantEntry is what a test checks of a parsed entry
+type wantEntry struct {
+	date           string
+	amount         string
+	direction      Direction
+	bankReference  string
+	endToEndID     string
+	counterparty   string
+	remittanceInfo string
+}
+
+// parseFixture detects and parses a file of testdata, which must hold one
+// statement
+func parseFixture(t *testing.T, name string, format Format) Statement {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detected, ok := Detect(data)
+	if !ok || detected != format {
+		t.Fatalf("Detect(%s) = %q, %v; want %q", name, detected, ok, format)
+	}
+
+	statements, err := Parse(format, data)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", name, err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("Parse(%s) returned %d statements; want 1", name, len(statements))
+	}
+	return statements[0]
+}
+
+func checkBalance(t *testing.T, name string, got *Balance, amount, date string) {
+	t.Helper()
+
+	if amount == "" {
+		if got != nil {
+			t.Errorf("%s balance = %s on %s; want none", name, got.Amount, got.Date.Format(time.DateOnly))
+		}
+		return
+	}
+	if got == nil {
+		t.Fatalf("%s balance missing; want %s on %s", name, amount, date)
+	}
+	if !got.Amount.Equal(decimal.RequireFromString(amount)) {
+		t.Errorf("%s balance amount = %s; want %s", name, got.Amount, amount)
+	}
+	if got.Date.Format(time.DateOnly) != date {
+		t.Errorf("%s balance date = %s; want %s", name, got.Date.Format(time.DateOnly), date)
+	}
+}
+
+func checkEntries(t *testing.T, got []Entry, want []wantEntry, currency string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries; want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		e := got[i]
+		if e.BookingDate.Format(time.DateOnly) != w.date {
+			t.Errorf("entry %d booking date = %s; want %s", i, e.BookingDate.Format(time.DateOnly), w.date)
+		}
+		if !e.Amount.Equal(decimal.RequireFromString(w.amount)) {
+			t.Errorf("entry %d amount = %s; want %s", i, e.Amount, w.amount)
+		}
+		if !e.Amount.IsPositive() {
+			t.Errorf("entry %d amount = %s; amounts must be positive", i, e.Amount)
+		}
+		if e.Direction != w.direction {
+			t.Errorf("entry %d direction = %s; want %s", i, e.Direction, w.direction)
+		}
+		if e.Currency != currency {
+			t.Errorf("entry %d currency = %q; want %q", i, e.Currency, currency)
+		}
+		if e.BankReference != w.bankReference {
+			t.Errorf("entry %d bank reference = %q; want %q", i, e.BankReference, w.bankReference)
+		}
+		if e.EndToEndID != w.endToEndID {
+			t.Errorf("entry %d end-to-end ID = %q; want %q", i, e.EndToEndID, w.endToEndID)
+		}
+		if e.CounterpartyName != w.counterparty {
+			t.Errorf("entry %d counterparty = %q; want %q", i, e.CounterpartyName, w.counterparty)
+		}
+		if e.RemittanceInfo != w.remittanceInfo {
+			t.Errorf("entry %d remittance info = %q; want %q", i, e.RemittanceInfo, w.remittanceInfo)
+		}
+	}
+}
+
+func checkPeriod(t *testing.T, s Statement, start, end string) {
+	t.Helper()
+
+	if s.PeriodStart == nil || s.PeriodStart.Format(time.DateOnly) != start {
+		t.Errorf("period start = %v; want %s", s.PeriodStart, start)
+	}
+	if s.PeriodEnd == nil || s.PeriodEnd.Format(time.DateOnly) != end {
+		t.Errorf("period end = %v; want %s", s.PeriodEnd, end)
+	}
+}
+
+func TestDetectUnknown(t *testing.T) {
+	if format, ok := Detect([]byte("date,amount\n2023-02-01,10.00\n")); ok {
+		t.Errorf("Detect(CSV) = %q; want no format", format)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+	}{
+		{"unsupported format", Format("csv"), "date,amount"},
+		{"CAMT.053 without statements", FormatCAMT053, `<Document><BkToCstmrStmt></BkToCstmrStmt></Document>`},
+		{"MT940 without statements", FormatMT940, "nothing here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.format, []byte(tt.data))
+			if _, ok := err.(*Error); !ok {
+				t.Fatalf("Parse = %v; want a *statement.Error", err)
+			}
+		})
+	}
+}
@@ -8,7 +8,20 @@ import (
 	"time"
 
 	"github.com/Abraxas-365/craftable/errx/errxfiber"
+	"github.com/Abraxas-365/fuckturamelo/analytics/analyticsapi"
+	"github.com/Abraxas-365/fuckturamelo/approvals/approvalsapi"
+	"github.com/Abraxas-365/fuckturamelo/attachments/attachmentsapi"
+	"github.com/Abraxas-365/fuckturamelo/banking/bankingapi"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/einvoiceapi"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/einvoicesrv"
+	"github.com/Abraxas-365/fuckturamelo/invoices/invoicesapi"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/invoicetypesapi"
+	"github.com/Abraxas-365/fuckturamelo/numbering/numberingapi"
+	"github.com/Abraxas-365/fuckturamelo/payments/paymentsapi"
+	"github.com/Abraxas-365/fuckturamelo/printing/printingapi"
 	"github.com/Abraxas-365/fuckturamelo/providers/providersapi"
+	"github.com/Abraxas-365/fuckturamelo/recurring/recurringapi"
+	"github.com/Abraxas-365/fuckturamelo/storage"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -34,15 +47,20 @@ func main() {
 	// Initialize Fiber app with errx error handler
 	app := fiber.New(fiber.Config{
 		ErrorHandler: errxfiber.FiberErrorHandler(),
+		// Attachment uploads go up to 20MB plus the multipart overhead
+		BodyLimit: 25 << 20,
 	})
 
 	// Add middleware
 	app.Use(recover.New())
 	app.Use(logger.New())
-	app.Use(cors.New())
+	app.Use(cors.New(cors.Config{
+		// Browsers need the ETag to send it back in If-Match
+		ExposeHeaders: fiber.HeaderETag,
+	}))
 
 	// Setup API routes
-	setupRoutes(app, db)
+	setupRoutes(app, db, config)
 
 	// Global health check
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -72,7 +90,7 @@ func main() {
 }
 
 // setupRoutes configures all API routes
-func setupRoutes(app *fiber.App, db *sqlx.DB) {
+func setupRoutes(app *fiber.App, db *sqlx.DB, config *AppConfig) {
 	// API v1 group
 	api := app.Group("/api/v1")
 
@@ -86,10 +104,160 @@ func setupRoutes(app *fiber.App, db *sqlx.DB) {
 	providersGroup := api.Group("/providers")
 	providersAPI.SetupRoutes(providersGroup)
 
-	// You can add more domains here in the same way:
-	// invoicesAPI, err := invoicesapi.New(invoicesapi.Config{DB: db})
-	// invoicesGroup := api.Group("/invoices")
-	// invoicesAPI.SetupRoutes(invoicesGroup)
+	// Initialize attachment storage and Attachments API
+	store, err := storage.New(config.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
+
+	attachmentsAPI, err := attachmentsapi.New(attachmentsapi.Config{DB: db, Store: store})
+	if err != nil {
+		log.Fatalf("Failed to initialize attachments API: %v", err)
+	}
+
+	// Initialize Invoices API and setup routes
+	invoicesAPI, err := invoicesapi.New(invoicesapi.Config{DB: db, Attachments: attachmentsAPI.GetService()})
+	if err != nil {
+		log.Fatalf("Failed to initialize invoices API: %v", err)
+	}
+
+	// Setup invoices routes under /api/v1/invoices
+	invoicesGroup := api.Group("/invoices")
+	invoicesAPI.SetupRoutes(invoicesGroup)
+
+	// Initialize Invoice Types API and setup routes
+	invoiceTypesAPI, err := invoicetypesapi.New(invoicetypesapi.Config{DB: db})
+	if err != nil {
+		log.Fatalf("Failed to initialize invoice types API: %v", err)
+	}
+
+	// Setup invoice types routes under /api/v1/invoice-types
+	invoiceTypesGroup := api.Group("/invoice-types")
+	invoiceTypesAPI.SetupRoutes(invoiceTypesGroup)
+
+	// Initialize Numbering API and setup routes
+	numberingAPI, err := numberingapi.New(numberingapi.Config{DB: db})
+	if err != nil {
+		log.Fatalf("Failed to initialize numbering API: %v", err)
+	}
+
+	// Setup numbering series routes under /api/v1/numbering-series
+	numberingGroup := api.Group("/numbering-series")
+	numberingAPI.SetupRoutes(numberingGroup)
+
+	// Initialize Payments API and setup routes
+	paymentsAPI, err := paymentsapi.New(paymentsapi.Config{DB: db})
+	if err != nil {
+		log.Fatalf("Failed to initialize payments API: %v", err)
+	}
+
+	// Setup payments routes under /api/v1/payments
+	paymentsGroup := api.Group("/payments")
+	paymentsAPI.SetupRoutes(paymentsGroup)
+
+	// Initialize Banking API and setup routes
+	bankingAPI, err := bankingapi.New(bankingapi.Config{DB: db})
+	if err != nil {
+		log.Fatalf("Failed to initialize banking API: %v", err)
+	}
+
+	// Setup bank statement routes under /api/v1/bank-statements
+	bankStatementsGroup := api.Group("/bank-statements")
+	bankingAPI.SetupRoutes(bankStatementsGroup)
+
+	// Setup outbound payment batch routes under /api/v1/payment-batches
+	paymentBatchesGroup := api.Group("/payment-batches")
+	bankingAPI.SetupPaymentBatchRoutes(paymentBatchesGroup)
+
+	// Initialize Approvals API and setup routes
+	approvalsAPI, err := approvalsapi.New(approvalsapi.Config{DB: db})
+	if err != nil {
+		log.Fatalf("Failed to initialize approvals API: %v", err)
+	}
+
+	// Setup approval chain routes under /api/v1/approval-chains
+	approvalChainsGroup := api.Group("/approval-chains")
+	approvalsAPI.SetupChainRoutes(approvalChainsGroup)
+
+	// Setup invoice approval routes under /api/v1/approvals
+	approvalsGroup := api.Group("/approvals")
+	approvalsAPI.SetupRoutes(approvalsGroup)
+
+	// Setup invoice attachment routes under /api/v1/invoices/:id/attachments
+	attachmentsAPI.SetupInvoiceRoutes(invoicesGroup)
+
+	// Setup signed download routes under /api/v1/attachments
+	attachmentsGroup := api.Group("/attachments")
+	attachmentsAPI.SetupRoutes(attachmentsGroup)
+
+	// Initialize Recurring Invoices API and setup routes
+	recurringAPI, err := recurringapi.New(recurringapi.Config{DB: db, Invoices: invoicesAPI.GetService()})
+	if err != nil {
+		log.Fatalf("Failed to initialize recurring invoices API: %v", err)
+	}
+
+	// Setup recurring invoice template routes under /api/v1/recurring-invoices
+	recurringGroup := api.Group("/recurring-invoices")
+	recurringAPI.SetupRoutes(recurringGroup)
+
+	// Materialize due recurring invoices until the server shuts down
+	scheduler := recurringAPI.GetScheduler()
+	scheduler.Start()
+	app.Hooks().OnShutdown(func() error {
+		scheduler.Stop()
+		return nil
+	})
+
+	// Initialize Analytics API and setup routes
+	analyticsAPI, err := analyticsapi.New(analyticsapi.Config{DB: db})
+	if err != nil {
+		log.Fatalf("Failed to initialize analytics API: %v", err)
+	}
+
+	// Setup analytics routes under /api/v1/organizations/:orgId
+	organizationGroup := api.Group("/organizations/:orgId")
+	analyticsAPI.SetupRoutes(organizationGroup)
+
+	// Initialize Electronic Invoicing API and setup routes
+	einvoiceAPI, err := einvoiceapi.New(einvoiceapi.Config{
+		DB:           db,
+		Attachments:  attachmentsAPI.GetService(),
+		Invoices:     invoicesAPI.GetService(),
+		InvoiceTypes: invoiceTypesAPI.GetService(),
+		Providers:    providersAPI.GetService(),
+		Options:      config.EInvoice,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize electronic invoicing API: %v", err)
+	}
+
+	// Setup UBL export, SUNAT submission and import routes under
+	// /api/v1/invoices/:id
+	einvoiceAPI.SetupInvoiceRoutes(invoicesGroup)
+
+	// Setup tax profile, signing certificate, SUNAT credential and
+	// received document import routes under /api/v1/organizations/:orgId
+	einvoiceAPI.SetupOrganizationRoutes(organizationGroup)
+
+	// Retry pending SUNAT submissions until the server shuts down
+	submitter := einvoiceAPI.GetSubmitter()
+	submitter.Start()
+	app.Hooks().OnShutdown(func() error {
+		submitter.Stop()
+		return nil
+	})
+
+	// Initialize Printing API and setup routes
+	printingAPI, err := printingapi.New(printingapi.Config{DB: db})
+	if err != nil {
+		log.Fatalf("Failed to initialize printing API: %v", err)
+	}
+
+	// Setup PDF rendering routes under /api/v1/invoices/:id
+	printingAPI.SetupInvoiceRoutes(invoicesGroup)
+
+	// Setup PDF template routes under /api/v1/organizations/:orgId
+	printingAPI.SetupOrganizationRoutes(organizationGroup)
 }
 
 // loadConfig and initDatabase functions (same as before)
@@ -110,4 +278,6 @@ type AppConfig struct {
 	Database struct {
 		URL string `json:"url"`
 	} `json:"database"`
+	Storage  storage.Config      `json:"storage"`
+	EInvoice einvoicesrv.Options `json:"einvoice"`
 }
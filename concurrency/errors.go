@@ -0,0 +1,39 @@
+package concurrency
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// ConcurrencyErrors is the error registry for optimistic concurrency checks
+var ConcurrencyErrors = errx.NewRegistry("CONCURRENCY")
+
+// Concurrency error codes
+var (
+	ErrPreconditionFailed = ConcurrencyErrors.Register(
+		"PRECONDITION_FAILED",
+		errx.TypeConflict,
+		http.StatusPreconditionFailed,
+		"Resource was modified since it was read; reload it and retry",
+	)
+
+	ErrPreconditionRequired = ConcurrencyErrors.Register(
+		"PRECONDITION_REQUIRED",
+		errx.TypeValidation,
+		http.StatusPreconditionRequired,
+		"If-Match header with the resource ETag is required",
+	)
+
+	ErrInvalidPrecondition = ConcurrencyErrors.Register(
+		"INVALID_PRECONDITION",
+		errx.TypeBadRequest,
+		http.StatusBadRequest,
+		"If-Match header is malformed",
+	)
+)
+
+// Helper functions for error checking
+func IsPreconditionFailed(err error) bool {
+	return errx.IsCode(err, ErrPreconditionFailed)
+}
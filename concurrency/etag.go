@@ -0,0 +1,79 @@
+// Package concurrency implements optimistic concurrency control with HTTP
+// entity tags: responses carry an ETag derived from a row version and writes
+// send it back in If-Match to prove they start from the latest state.
+package concurrency
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Precondition is a parsed If-Match header. The zero value matches anything.
+type Precondition struct {
+	tags []string
+	any  bool
+}
+
+// VersionETag builds the entity tag of a row versioned by a counter
+func VersionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// TimestampETag builds the entity tag of a row versioned by its updated_at
+// column. Microsecond precision matches PostgreSQL timestamps.
+func TimestampETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// ParseIfMatch parses an If-Match header value. An empty header yields a nil
+// precondition so callers can tell "not sent" apart from "*".
+func ParseIfMatch(header string) (*Precondition, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil, nil
+	}
+	if header == "*" {
+		return &Precondition{any: true}, nil
+	}
+
+	precondition := &Precondition{}
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(part)
+		// Weak validators compare equal to strong ones for our purposes
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' || strings.Contains(tag[1:len(tag)-1], `"`) {
+			return nil, ConcurrencyErrors.New(ErrInvalidPrecondition).
+				WithDetail("if_match", header)
+		}
+		precondition.tags = append(precondition.tags, tag)
+	}
+
+	return precondition, nil
+}
+
+// Check fails with ErrPreconditionFailed when the current entity tag is not
+// one of the tags the client sent. A nil precondition always passes.
+func (p *Precondition) Check(current string) error {
+	if p == nil || p.any {
+		return nil
+	}
+
+	for _, tag := range p.tags {
+		if tag == current {
+			return nil
+		}
+	}
+
+	return ConcurrencyErrors.New(ErrPreconditionFailed).
+		WithDetail("if_match", strings.Join(p.tags, ", ")).
+		WithDetail("current_etag", current)
+}
+
+// Require fails with ErrPreconditionRequired when no If-Match header was sent
+func Require(p *Precondition) error {
+	if p == nil {
+		return ConcurrencyErrors.New(ErrPreconditionRequired)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package concurrency
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	current := VersionETag(3)
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+		matches bool // whether the precondition passes for version 3
+	}{
+		{"strong tag", `"3"`, false, true},
+		{"other version", `"2"`, false, false},
+		{"weak tag", `W/"3"`, false, true},
+		{"weak other version", `W/"2"`, false, false},
+		{"any", `*`, false, true},
+		{"any with spaces", `  *  `, false, true},
+		{"list holding the current tag", `"1", "2", "3"`, false, true},
+		{"list of weak and strong tags", `W/"1",W/"3"`, false, true},
+		{"list without the current tag", `"1", "2"`, false, false},
+		{"unquoted", `3`, true, false},
+		{"unterminated", `"3`, true, false},
+		{"lone quote", `"`, true, false},
+		{"empty list entry", `"3",`, true, false},
+		{"quote inside", `"3"4"`, true, false},
+		{"weak prefix alone", `W/`, true, false},
+		{"lowercase weak prefix", `w/"3"`, true, false},
+		{"any in a list", `*, "3"`, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			precondition, err := ParseIfMatch(tt.header)
+			if tt.wantErr {
+				if !errx.IsCode(err, ErrInvalidPrecondition) {
+					t.Fatalf("err = %v; want %s", err, ErrInvalidPrecondition)
+				}
+				if status := err.(*errx.Error).HTTPStatus; status != http.StatusBadRequest {
+					t.Errorf("status = %d; want %d", status, http.StatusBadRequest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if precondition == nil {
+				t.Fatal("precondition = nil; want one for a header that was sent")
+			}
+
+			err = precondition.Check(current)
+			if tt.matches {
+				if err != nil {
+					t.Errorf("check = %v; want a match", err)
+				}
+				return
+			}
+			if !IsPreconditionFailed(err) {
+				t.Fatalf("check = %v; want %s", err, ErrPreconditionFailed)
+			}
+			if status := err.(*errx.Error).HTTPStatus; status != http.StatusPreconditionFailed {
+				t.Errorf("status = %d; want %d", status, http.StatusPreconditionFailed)
+			}
+			if got := err.(*errx.Error).Details["current_etag"]; got != current {
+				t.Errorf("current_etag = %v; want %s", got, current)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	for _, header := range []string{"", "   "} {
+		precondition, err := ParseIfMatch(header)
+		if err != nil || precondition != nil {
+			t.Fatalf("ParseIfMatch(%q) = %v, %v; want no precondition", header, precondition, err)
+		}
+		// A missing header passes Check, so writes that need one call Require
+		if err := precondition.Check(VersionETag(1)); err != nil {
+			t.Errorf("check without a header = %v; want nil", err)
+		}
+		err = Require(precondition)
+		if !errx.IsCode(err, ErrPreconditionRequired) {
+			t.Fatalf("require without a header = %v; want %s", err, ErrPreconditionRequired)
+		}
+		if status := err.(*errx.Error).HTTPStatus; status != http.StatusPreconditionRequired {
+			t.Errorf("status = %d; want %d", status, http.StatusPreconditionRequired)
+		}
+	}
+
+	for _, header := range []string{`"1"`, `*`} {
+		precondition, err := ParseIfMatch(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Require(precondition); err != nil {
+			t.Errorf("require with %s = %v; want nil", header, err)
+		}
+	}
+}
+
+func TestETags(t *testing.T) {
+	if got := VersionETag(12); got != `"12"` {
+		t.Errorf("VersionETag(12) = %s; want \"12\"", got)
+	}
+
+	updatedAt := time.Date(2024, 3, 1, 10, 30, 0, 123456789, time.UTC)
+	tag := TimestampETag(updatedAt)
+	// PostgreSQL keeps microseconds, so the nanoseconds read back from the
+	// row must not change the tag
+	if again := TimestampETag(updatedAt.Truncate(time.Microsecond)); again != tag {
+		t.Errorf("tag after truncating to microseconds = %s; want %s", again, tag)
+	}
+	if later := TimestampETag(updatedAt.Add(time.Microsecond)); later == tag {
+		t.Errorf("tag a microsecond later = %s; want another tag", later)
+	}
+
+	precondition, err := ParseIfMatch(tag)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", tag, err)
+	}
+	if err := precondition.Check(tag); err != nil {
+		t.Errorf("check of the tag itself = %v; want a match", err)
+	}
+}
@@ -0,0 +1,136 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	invoicedto "github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	providerdto "github.com/Abraxas-365/fuckturamelo/providers/dto"
+	providermodels "github.com/Abraxas-365/fuckturamelo/providers/models"
+)
+
+// SaveTaxProfileRequest creates or replaces the tax profile of an
+// organization
+type SaveTaxProfileRequest struct {
+	TaxID             string  `json:"tax_id" validate:"required"`
+	TaxIDType         string  `json:"tax_id_type,omitempty"`
+	LegalName         string  `json:"legal_name" validate:"required"`
+	TradeName         *string `json:"trade_name,omitempty"`
+	AddressLine       *string `json:"address_line,omitempty"`
+	Ubigeo            *string `json:"ubigeo,omitempty"`
+	District          *string `json:"district,omitempty"`
+	Province          *string `json:"province,omitempty"`
+	Department        *string `json:"department,omitempty"`
+	CountryCode       string  `json:"country_code,omitempty"`
+	EstablishmentCode string  `json:"establishment_code,omitempty"`
+}
+
+// Document is a rendered electronic document
+type Document struct {
+	// FileName is the SUNAT file name, e.g. 20123456789-01-F001-123.xml
+	FileName string
+	Content  []byte
+}
+
+// UploadCertificateRequest carries a PKCS#12 signing certificate
+type UploadCertificateRequest struct {
+	Data       []byte     `json:"-"`
+	Password   string     `json:"-"`
+	UploadedBy *uuid.UUID `json:"uploaded_by,omitempty"`
+}
+
+// SaveSunatCredentialsRequest sets the SOL credentials an organization
+// submits electronic documents with
+type SaveSunatCredentialsRequest struct {
+	// Username is the RUC followed by the SOL user, e.g. 20123456789MODDATOS
+	Username  string     `json:"username" validate:"required"`
+	Password  string     `json:"password" validate:"required"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+}
+
+// SubmitInvoiceRequest sends an invoice to SUNAT
+type SubmitInvoiceRequest struct {
+	SubmittedBy *uuid.UUID `json:"submitted_by,omitempty"`
+}
+
+// ImportDocumentRequest imports an electronic document received from a
+// provider as an invoice of the given type. Without ProviderID the issuer
+// is matched by tax ID; CreateProvider creates the proposed provider when
+// none matches. InvoiceData is merged over the fields read from the
+// document, for fields the invoice type needs that documents do not carry.
+type ImportDocumentRequest struct {
+	OrganizationID uuid.UUID      `json:"-"`
+	InvoiceTypeID  uuid.UUID      `json:"invoice_type_id" validate:"required"`
+	ProjectID      *uuid.UUID     `json:"project_id,omitempty"`
+	ProviderID     *uuid.UUID     `json:"provider_id,omitempty"`
+	CreateProvider bool           `json:"create_provider,omitempty"`
+	InvoiceData    map[string]any `json:"invoice_data,omitempty"`
+	ImportedBy     *uuid.UUID     `json:"imported_by,omitempty"`
+	FileName       string         `json:"-"`
+	Data           []byte         `json:"-"`
+}
+
+// ImportPreview is what importing a document would do
+type ImportPreview struct {
+	Document  ImportedDocument  `json:"document"`
+	Signature ImportedSignature `json:"signature"`
+
+	// Provider is the provider the document is imported for; otherwise
+	// ProposedProvider is the one create_provider would create
+	Provider         *providermodels.Provider           `json:"provider,omitempty"`
+	ProposedProvider *providerdto.CreateProviderRequest `json:"proposed_provider,omitempty"`
+
+	// Invoice is the invoice that would be created
+	Invoice *invoicedto.CreateInvoiceRequest `json:"invoice"`
+
+	// Warnings are differences that do not stop the import
+	Warnings []string `json:"warnings"`
+}
+
+// ImportedDocument summarizes a received document
+type ImportedDocument struct {
+	Format           models.ImportFormat `json:"format"`
+	DocumentTypeCode string              `json:"document_type_code"`
+	DocumentID       string              `json:"document_id"`
+	IssueDate        string              `json:"issue_date"`
+	DueDate          *string             `json:"due_date,omitempty"`
+	Currency         string              `json:"currency"`
+	Issuer           ImportedParty       `json:"issuer"`
+	Customer         ImportedParty       `json:"customer"`
+
+	// DeclaredTotal is the payable amount in the document; ComputedTotal
+	// is the one computed from its lines
+	DeclaredTotal decimal.Decimal `json:"declared_total"`
+	ComputedTotal decimal.Decimal `json:"computed_total"`
+}
+
+// ImportedParty is the issuer or customer of a received document
+type ImportedParty struct {
+	TaxID            string `json:"tax_id"`
+	IdentityType     string `json:"identity_type"`
+	RegistrationName string `json:"registration_name"`
+	TradeName        string `json:"trade_name,omitempty"`
+}
+
+// ImportedSignature describes the verified signature of a received
+// document. Trusted tells whether the certificate chains to a trusted
+// certification authority; otherwise only its subject names the issuer.
+type ImportedSignature struct {
+	Algorithm    string    `json:"algorithm"`
+	Trusted      bool      `json:"trusted"`
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+}
+
+// ImportResult is an imported document and the invoice it created
+type ImportResult struct {
+	Import          *models.Import              `json:"import"`
+	Invoice         *invoicedto.InvoiceResponse `json:"invoice"`
+	ProviderCreated bool                        `json:"provider_created"`
+}
@@ -0,0 +1,592 @@
+package einvoiceapi
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/dto"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/einvoicesrv"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/einvoice/repository"
+	invoicespg "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	providerspg "github.com/Abraxas-365/fuckturamelo/providers/repository"
+	"github.com/Abraxas-365/fuckturamelo/sunat"
+	"github.com/Abraxas-365/fuckturamelo/xmlsig"
+)
+
+// maxCertificateSize bounds uploaded PKCS#12 bundles
+const maxCertificateSize = 1 << 20
+
+// EInvoiceAPI contains the complete API setup for the electronic invoicing
+// domain
+type EInvoiceAPI struct {
+	service   einvoicesrv.EInvoiceService
+	repo      postgres.EInvoiceRepository
+	submitter *einvoicesrv.Submitter
+}
+
+// Config contains configuration for the electronic invoicing API
+type Config struct {
+	DB *sqlx.DB
+
+	// Attachments keeps the CDRs SUNAT answers submissions with and the
+	// XML of imported documents
+	Attachments einvoicesrv.Attachments
+
+	// Invoices, InvoiceTypes and Providers create the invoices and
+	// providers of imported documents
+	Invoices     einvoicesrv.InvoiceCreator
+	InvoiceTypes einvoicesrv.InvoiceTypes
+	Providers    einvoicesrv.ProviderCreator
+
+	// Options holds the certificate master key, without which signing and
+	// submission are disabled, and the SUNAT web service
+	Options einvoicesrv.Options
+
+	// Submitter tunes the retries of submissions; zero values take
+	// defaults
+	Submitter einvoicesrv.SubmitterOptions
+}
+
+// New creates a new EInvoiceAPI instance
+func New(config Config) (*EInvoiceAPI, error) {
+	if config.DB == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+	if config.Attachments == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Attachment service is required")
+	}
+	if config.Invoices == nil || config.InvoiceTypes == nil || config.Providers == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Invoice, invoice type and provider services are required")
+	}
+
+	var keystore *xmlsig.Keystore
+	if config.Options.CertificateKey != "" {
+		key, err := base64.StdEncoding.DecodeString(config.Options.CertificateKey)
+		if err != nil {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "Certificate key must be base64 encoded").
+				WithCause(err)
+		}
+		if keystore, err = xmlsig.NewKeystore(key); err != nil {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "Certificate key must be 32 bytes").
+				WithCause(err)
+		}
+	}
+
+	var roots *x509.CertPool
+	if config.Options.TrustedRootsFile != "" {
+		bundle, err := os.ReadFile(config.Options.TrustedRootsFile)
+		if err != nil {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "Trusted roots file cannot be read").
+				WithCause(err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(bundle) {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "Trusted roots file holds no PEM certificate")
+		}
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewEInvoiceRepository(config.DB)
+	providerRepo := providerspg.NewProviderRepository(config.DB)
+	submitter := einvoicesrv.NewSubmitter(repo, sunat.NewClient(config.Options.SUNAT), config.Attachments, keystore, config.Submitter)
+	importer := einvoicesrv.NewImporter(repo, config.Invoices, config.InvoiceTypes, providerRepo, config.Providers, config.Attachments, roots)
+	svc := einvoicesrv.NewEInvoiceService(repo,
+		invoicespg.NewInvoiceRepository(config.DB),
+		providerRepo,
+		keystore, submitter, importer, config.Options)
+
+	return &EInvoiceAPI{
+		service:   svc,
+		repo:      repo,
+		submitter: submitter,
+	}, nil
+}
+
+// SetupInvoiceRoutes registers the electronic document routes of an invoice
+// with the invoices router group
+func (api *EInvoiceAPI) SetupInvoiceRoutes(router fiber.Router) {
+	router.Get("/:id/ubl", api.getUBL)
+
+	// SUNAT submission routes
+	router.Post("/:id/submit", api.submitInvoice)
+	router.Get("/:id/submissions", api.listSubmissions)
+
+	// Import of documents received from providers
+	router.Get("/:id/import", api.getInvoiceImport)
+}
+
+// SetupOrganizationRoutes registers the tax profile routes with the given
+// Fiber router group, which is expected to carry the :orgId parameter
+func (api *EInvoiceAPI) SetupOrganizationRoutes(router fiber.Router) {
+	// Tax profile routes
+	router.Get("/tax-profile", api.getTaxProfile)
+	router.Put("/tax-profile", api.saveTaxProfile)
+
+	// Signing certificate routes
+	router.Get("/certificate", api.getCertificate)
+	router.Put("/certificate", api.uploadCertificate)
+	router.Delete("/certificate", api.deleteCertificate)
+
+	// SUNAT credential routes
+	router.Get("/sunat-credentials", api.getSunatCredentials)
+	router.Put("/sunat-credentials", api.saveSunatCredentials)
+	router.Delete("/sunat-credentials", api.deleteSunatCredentials)
+
+	// Received document import routes
+	router.Post("/invoice-imports/preview", api.previewImport)
+	router.Post("/invoice-imports", api.importDocument)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *EInvoiceAPI) GetService() einvoicesrv.EInvoiceService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *EInvoiceAPI) GetRepository() postgres.EInvoiceRepository {
+	return api.repo
+}
+
+// GetSubmitter returns the submitter that retries pending submissions
+func (api *EInvoiceAPI) GetSubmitter() *einvoicesrv.Submitter {
+	return api.submitter
+}
+
+// getUBL handles GET /invoices/:id/ubl?signed=true; signed documents carry
+// the organization's signature
+func (api *EInvoiceAPI) getUBL(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	build := api.service.BuildUBL
+	if c.QueryBool("signed") {
+		build = api.service.SignUBL
+	}
+	document, err := build(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+document.FileName+`"`)
+	return c.Status(fiber.StatusOK).Send(document.Content)
+}
+
+// submitInvoice handles POST /invoices/:id/submit. The submission is
+// returned as left by the first attempt: 201 once SUNAT answered, 202 while
+// it is pending another attempt.
+func (api *EInvoiceAPI) submitInvoice(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.SubmitInvoiceRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "Invalid JSON in request body").
+				WithCause(err)
+		}
+	}
+
+	result, err := api.service.SubmitInvoice(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	status := fiber.StatusCreated
+	if result.Status == models.SubmissionPending {
+		status = fiber.StatusAccepted
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listSubmissions handles GET /invoices/:id/submissions
+func (api *EInvoiceAPI) listSubmissions(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListSubmissions(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getTaxProfile handles GET /organizations/:orgId/tax-profile
+func (api *EInvoiceAPI) getTaxProfile(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetTaxProfile(c.Context(), orgID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// saveTaxProfile handles PUT /organizations/:orgId/tax-profile
+func (api *EInvoiceAPI) saveTaxProfile(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.SaveTaxProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.SaveTaxProfile(c.Context(), orgID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getCertificate handles GET /organizations/:orgId/certificate
+func (api *EInvoiceAPI) getCertificate(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetCertificate(c.Context(), orgID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// uploadCertificate handles PUT /organizations/:orgId/certificate as a
+// multipart upload with a "file" part holding the PKCS#12 bundle, its
+// password and an optional uploaded_by field
+func (api *EInvoiceAPI) uploadCertificate(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	req := dto.UploadCertificateRequest{Password: c.FormValue("password")}
+	if value := c.FormValue("uploaded_by"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "Invalid uploaded_by format").
+				WithCause(err)
+		}
+		req.UploadedBy = &id
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Missing certificate in multipart field: file").
+			WithCause(err)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Certificate could not be read").
+			WithCause(err)
+	}
+	defer file.Close()
+
+	if req.Data, err = io.ReadAll(io.LimitReader(file, maxCertificateSize)); err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Certificate could not be read").
+			WithCause(err)
+	}
+
+	result, err := api.service.UploadCertificate(c.Context(), orgID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteCertificate handles DELETE /organizations/:orgId/certificate
+func (api *EInvoiceAPI) deleteCertificate(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteCertificate(c.Context(), orgID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// getSunatCredentials handles GET /organizations/:orgId/sunat-credentials;
+// the password is never returned
+func (api *EInvoiceAPI) getSunatCredentials(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetSunatCredentials(c.Context(), orgID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// saveSunatCredentials handles PUT /organizations/:orgId/sunat-credentials
+func (api *EInvoiceAPI) saveSunatCredentials(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.SaveSunatCredentialsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.SaveSunatCredentials(c.Context(), orgID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteSunatCredentials handles DELETE
+// /organizations/:orgId/sunat-credentials
+func (api *EInvoiceAPI) deleteSunatCredentials(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteSunatCredentials(c.Context(), orgID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// previewImport handles POST /organizations/:orgId/invoice-imports/preview
+// with the same multipart form as importDocument; nothing is written
+func (api *EInvoiceAPI) previewImport(c *fiber.Ctx) error {
+	req, err := api.parseImportRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.PreviewImport(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// importDocument handles POST /organizations/:orgId/invoice-imports as a
+// multipart upload with a "file" part holding the XML, the invoice_type_id
+// and optional project_id, provider_id, create_provider, invoice_data (a
+// JSON object) and imported_by fields
+func (api *EInvoiceAPI) importDocument(c *fiber.Ctx) error {
+	req, err := api.parseImportRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ImportDocument(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getInvoiceImport handles GET /invoices/:id/import
+func (api *EInvoiceAPI) getInvoiceImport(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetInvoiceImport(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Helper methods
+
+func (api *EInvoiceAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *EInvoiceAPI) parseUUIDForm(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.FormValue(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &id, nil
+}
+
+// parseImportRequest reads the multipart form of document imports
+func (api *EInvoiceAPI) parseImportRequest(c *fiber.Ctx) (*dto.ImportDocumentRequest, error) {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return nil, err
+	}
+	req := &dto.ImportDocumentRequest{OrganizationID: orgID}
+
+	invoiceTypeID, err := api.parseUUIDForm(c, "invoice_type_id")
+	if err != nil {
+		return nil, err
+	}
+	if invoiceTypeID == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Missing required field: invoice_type_id")
+	}
+	req.InvoiceTypeID = *invoiceTypeID
+
+	if req.ProjectID, err = api.parseUUIDForm(c, "project_id"); err != nil {
+		return nil, err
+	}
+	if req.ProviderID, err = api.parseUUIDForm(c, "provider_id"); err != nil {
+		return nil, err
+	}
+	if req.ImportedBy, err = api.parseUUIDForm(c, "imported_by"); err != nil {
+		return nil, err
+	}
+	if value := c.FormValue("create_provider"); value != "" {
+		if req.CreateProvider, err = strconv.ParseBool(value); err != nil {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "Invalid create_provider format").
+				WithCause(err)
+		}
+	}
+	if value := c.FormValue("invoice_data"); value != "" {
+		if err := json.Unmarshal([]byte(value), &req.InvoiceData); err != nil {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("error", "invoice_data must be a JSON object").
+				WithCause(err)
+		}
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Missing document in multipart field: file").
+			WithCause(err)
+	}
+	if header.Size > einvoicesrv.MaxImportSize {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Document is too large").
+			WithDetail("max_bytes", einvoicesrv.MaxImportSize)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Document could not be read").
+			WithCause(err)
+	}
+	defer file.Close()
+
+	if req.Data, err = io.ReadAll(file); err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Document could not be read").
+			WithCause(err)
+	}
+	req.FileName = header.Filename
+
+	return req, nil
+}
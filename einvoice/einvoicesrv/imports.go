@@ -0,0 +1,669 @@
+package einvoicesrv
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	attachmentdto "github.com/Abraxas-365/fuckturamelo/attachments/dto"
+	attachmentmodels "github.com/Abraxas-365/fuckturamelo/attachments/models"
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/dto"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/einvoice/repository"
+	invoicedto "github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/invoices/totals"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+	"github.com/Abraxas-365/fuckturamelo/providers"
+	providerdto "github.com/Abraxas-365/fuckturamelo/providers/dto"
+	providermodels "github.com/Abraxas-365/fuckturamelo/providers/models"
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+	"github.com/Abraxas-365/fuckturamelo/xmlsig"
+)
+
+// MaxImportSize bounds the documents accepted for import
+const MaxImportSize = 5 << 20
+
+// namespaceCFDI4 is the namespace of Mexican CFDI 4.0 documents
+const namespaceCFDI4 = "http://www.sat.gob.mx/cfd/4"
+
+// InvoiceCreator creates the invoices of imported documents (implemented
+// by invoicesrv.InvoiceService)
+type InvoiceCreator interface {
+	CreateInvoice(ctx context.Context, req *invoicedto.CreateInvoiceRequest) (*invoicedto.InvoiceResponse, error)
+	DiscardInvoice(ctx context.Context, id uuid.UUID) error
+}
+
+// InvoiceTypes tells what invoices of a type look like (implemented by
+// invoicetypesrv.InvoiceTypeService)
+type InvoiceTypes interface {
+	GetSchema(ctx context.Context, id uuid.UUID) (*schema.Schema, error)
+	GetDocumentKind(ctx context.Context, id uuid.UUID) (typemodels.DocumentKind, error)
+}
+
+// ProviderMatcher finds the issuers of imported documents among the
+// providers (implemented by providers/repository.ProviderRepository)
+type ProviderMatcher interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*providermodels.Provider, error)
+	GetByTaxIDAndOrganization(ctx context.Context, taxID string, orgID uuid.UUID) (*providermodels.Provider, error)
+}
+
+// ProviderCreator creates the providers proposed for issuers without one
+// (implemented by service.ProviderService)
+type ProviderCreator interface {
+	CreateProvider(ctx context.Context, req *providerdto.CreateProviderRequest) (*providerdto.ProviderResponse, error)
+}
+
+// Importer turns electronic documents issued by providers into invoices of
+// the organization they were issued to
+type Importer struct {
+	repo        postgres.EInvoiceRepository
+	invoices    InvoiceCreator
+	types       InvoiceTypes
+	providers   ProviderMatcher
+	creator     ProviderCreator
+	attachments Attachments
+	roots       *x509.CertPool
+}
+
+// NewImporter creates an importer. Signers whose certificate does not
+// chain to one of roots are imported as untrusted, with a warning; a nil
+// pool trusts no signer.
+func NewImporter(repo postgres.EInvoiceRepository, invoices InvoiceCreator, types InvoiceTypes, providers ProviderMatcher, creator ProviderCreator, attachments Attachments, roots *x509.CertPool) *Importer {
+	return &Importer{
+		repo:        repo,
+		invoices:    invoices,
+		types:       types,
+		providers:   providers,
+		creator:     creator,
+		attachments: attachments,
+		roots:       roots,
+	}
+}
+
+// PreviewImport checks a document and returns the invoice its import would
+// create
+func (s *einvoiceService) PreviewImport(ctx context.Context, req *dto.ImportDocumentRequest) (*dto.ImportPreview, error) {
+	return s.importer.Preview(ctx, req)
+}
+
+// ImportDocument imports a document received from a provider
+func (s *einvoiceService) ImportDocument(ctx context.Context, req *dto.ImportDocumentRequest) (*dto.ImportResult, error) {
+	return s.importer.Import(ctx, req)
+}
+
+// GetInvoiceImport returns the import an invoice was created by
+func (s *einvoiceService) GetInvoiceImport(ctx context.Context, invoiceID uuid.UUID) (*models.Import, error) {
+	return s.repo.GetImportByInvoice(ctx, invoiceID)
+}
+
+// pendingImport is a verified document ready to become an invoice
+type pendingImport struct {
+	received *ubl.Received
+	verified *xmlsig.Verified
+	trusted  bool
+	provider *providermodels.Provider
+	preview  *dto.ImportPreview
+	warnings []string
+}
+
+// Preview reads, verifies and checks a document and returns the invoice
+// its import would create, without writing anything
+func (i *Importer) Preview(ctx context.Context, req *dto.ImportDocumentRequest) (*dto.ImportPreview, error) {
+	pending, err := i.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return pending.preview, nil
+}
+
+// Import creates the invoice of a document, creating its provider first if
+// asked to, and keeps the original XML as an attachment of the invoice. The
+// import stands if the XML cannot be attached; the failure is kept as a
+// warning. An invoice whose import cannot be recorded is discarded with
+// its attachment, so the document can be imported again; a created
+// provider is kept.
+func (i *Importer) Import(ctx context.Context, req *dto.ImportDocumentRequest) (*dto.ImportResult, error) {
+	pending, err := i.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	preview := pending.preview
+	received := pending.received
+
+	result := &dto.ImportResult{}
+	if pending.provider == nil {
+		if !req.CreateProvider {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrProviderRequired).
+				WithDetail("tax_id", received.Supplier.TaxID).
+				WithDetail("proposed_provider", preview.ProposedProvider)
+		}
+		created, err := i.creator.CreateProvider(ctx, preview.ProposedProvider)
+		if err != nil {
+			return nil, err
+		}
+		preview.Invoice.ProviderID = &created.ID
+		result.ProviderCreated = true
+	}
+
+	invoice, err := i.invoices.CreateInvoice(ctx, preview.Invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := preview.Warnings
+	var attachmentID *uuid.UUID
+	fileName := req.FileName
+	if fileName == "" {
+		fileName = received.Filename() + ".xml"
+	}
+	attachment, err := i.attachments.Upload(ctx, &attachmentdto.UploadRequest{
+		InvoiceID:   invoice.ID,
+		FileName:    fileName,
+		ContentType: attachmentmodels.ContentTypeXML,
+		Data:        req.Data,
+		UploadedBy:  req.ImportedBy,
+	})
+	if err != nil {
+		log.Printf("einvoice import of %s: attaching XML: %v", received.Filename(), err)
+		warnings = append(warnings, fmt.Sprintf("the original XML could not be attached: %v", err))
+	} else {
+		attachmentID = &attachment.ID
+	}
+
+	signer := pending.verified.Certificate
+	imp, err := i.repo.CreateImport(ctx, &models.Import{
+		ID:                 uuid.New(),
+		OrganizationID:     req.OrganizationID,
+		InvoiceID:          invoice.ID,
+		ProviderID:         preview.Invoice.ProviderID,
+		AttachmentID:       attachmentID,
+		Format:             models.ImportFormatUBL,
+		IssuerTaxID:        received.Supplier.TaxID,
+		DocumentTypeCode:   received.DocumentTypeCode,
+		DocumentID:         received.ID,
+		IssueDate:          received.IssueDate,
+		SignerSubject:      signer.Subject.String(),
+		SignerSerialNumber: signer.SerialNumber.String(),
+		SignatureAlgorithm: pending.verified.Algorithm,
+		SignerTrusted:      pending.trusted,
+		Warnings:           warnings,
+		ImportedBy:         req.ImportedBy,
+		CreatedAt:          time.Now(),
+	})
+	if err != nil {
+		// Without its import the invoice would be a copy nobody can trace
+		// back to the document, holding the document's number
+		if discardErr := i.invoices.DiscardInvoice(context.WithoutCancel(ctx), invoice.ID); discardErr != nil {
+			log.Printf("einvoice import of %s: discarding invoice %s: %v", received.Filename(), invoice.ID, discardErr)
+		}
+		return nil, err
+	}
+
+	result.Import = imp
+	result.Invoice = invoice
+	return result, nil
+}
+
+// prepare reads and verifies the document, matches its issuer and builds
+// the invoice request. Anything that would stop the import fails here,
+// except an issuer without provider, which gets a proposed provider.
+func (i *Importer) prepare(ctx context.Context, req *dto.ImportDocumentRequest) (*pendingImport, error) {
+	if len(req.Data) == 0 {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "Document is empty")
+	}
+	if req.InvoiceTypeID == uuid.Nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("field", "invoice_type_id").
+			WithDetail("reason", "required")
+	}
+
+	received, err := ubl.Unmarshal(req.Data)
+	if err != nil {
+		unsupported := einvoice.EInvoiceErrors.New(einvoice.ErrUnsupportedDocument).WithCause(err)
+		if rootElement(req.Data).Space == namespaceCFDI4 {
+			return nil, unsupported.
+				WithDetail("format", "CFDI 4.0").
+				WithDetail("reason", "CFDI documents cannot be imported yet")
+		}
+		return nil, unsupported.WithDetail("reason", err.Error())
+	}
+
+	// Providers still sign with SHA-1; such documents are imported with a
+	// warning rather than refused
+	verified, err := xmlsig.Verify(req.Data, xmlsig.VerifyOptions{AllowSHA1: true})
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSignatureInvalid).
+			WithDetail("reason", err.Error()).
+			WithCause(err)
+	}
+
+	pending := &pendingImport{received: received, verified: verified}
+	for _, algorithm := range verified.WeakAlgorithms {
+		pending.warn("the document is signed with the weak SHA-1 algorithm %s", algorithm)
+	}
+	issuer := received.Supplier.TaxID
+
+	existing, err := i.repo.GetImportByDocument(ctx, req.OrganizationID, issuer, received.DocumentTypeCode, received.ID)
+	switch {
+	case err == nil:
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrImportExists).
+			WithDetail("document_id", received.ID).
+			WithDetail("invoice_id", existing.InvoiceID.String())
+	case !einvoice.IsImportNotFound(err):
+		return nil, err
+	}
+
+	// The document must be addressed to this organization
+	profile, err := i.repo.GetTaxProfile(ctx, req.OrganizationID)
+	switch {
+	case err == nil:
+		if received.Customer.TaxID != profile.TaxID {
+			return nil, mismatch("the document is issued to another customer").
+				WithDetail("customer_tax_id", received.Customer.TaxID).
+				WithDetail("organization_tax_id", profile.TaxID)
+		}
+	case einvoice.IsTaxProfileNotFound(err):
+		pending.warn("the organization has no tax profile; the customer %s of the document was not checked", received.Customer.TaxID)
+	default:
+		return nil, err
+	}
+
+	kind, err := i.types.GetDocumentKind(ctx, req.InvoiceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if expected := documentKinds[received.Type]; kind != expected {
+		return nil, mismatch("the invoice type does not hold this kind of document").
+			WithDetail("document_kind", expected).
+			WithDetail("invoice_type_kind", kind)
+	}
+
+	amounts, err := importTotals(received)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrUnsupportedDocument).
+			WithDetail("reason", err.Error()).
+			WithCause(err)
+	}
+	if !received.PayableAmount.IsZero() && !received.PayableAmount.Equal(amounts.Total) {
+		// Taxes other than IGV and IVAP, charges and rounding differences
+		// would leave the invoice with another total than the one billed
+		return nil, mismatch("the payable amount differs from the total of the lines").
+			WithDetail("declared", received.PayableAmount.StringFixed(totals.Scale)).
+			WithDetail("computed", amounts.Total.StringFixed(totals.Scale))
+	}
+
+	if err := checkSigner(received, verified); err != nil {
+		return nil, err
+	}
+	pending.trusted = i.trustedSigner(received, verified)
+	if !pending.trusted {
+		pending.warn("the signing certificate does not chain to a trusted certification authority; anyone can issue one naming %s", received.Supplier.TaxID)
+	}
+
+	if err := i.matchProvider(ctx, req, pending); err != nil {
+		return nil, err
+	}
+
+	invoice, err := i.buildInvoice(ctx, req, pending, amounts)
+	if err != nil {
+		return nil, err
+	}
+
+	pending.preview = &dto.ImportPreview{
+		Document:  importedDocument(received, amounts.Total),
+		Signature: importedSignature(verified, pending.trusted),
+		Provider:  pending.provider,
+		Invoice:   invoice,
+		Warnings:  pending.warnings,
+	}
+	if pending.provider == nil {
+		pending.preview.ProposedProvider = proposedProvider(req.OrganizationID, received.Supplier)
+	}
+	if pending.preview.Warnings == nil {
+		pending.preview.Warnings = []string{}
+	}
+
+	return pending, nil
+}
+
+// documentKinds maps UBL documents to the invoice types that hold them
+var documentKinds = map[ubl.DocumentType]typemodels.DocumentKind{
+	ubl.TypeInvoice:    typemodels.KindInvoice,
+	ubl.TypeCreditNote: typemodels.KindCreditNote,
+	ubl.TypeDebitNote:  typemodels.KindDebitNote,
+}
+
+// checkSigner rejects signing certificates that do not name the issuer's
+// tax ID or were not valid when the document was issued. Whether the
+// certificate chains to a trusted root is left to trustedSigner.
+func checkSigner(received *ubl.Received, verified *xmlsig.Verified) error {
+	certificate := verified.Certificate
+	subject := certificate.Subject.String()
+
+	if !strings.Contains(subject, received.Supplier.TaxID) {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrSignatureInvalid).
+			WithDetail("reason", "the signing certificate does not name the issuer's tax ID").
+			WithDetail("signer_subject", subject).
+			WithDetail("issuer_tax_id", received.Supplier.TaxID)
+	}
+
+	issued := issuedAt(received)
+	// Issue dates are local to Peru and may lack a time, so validity is
+	// compared with a day of slack
+	if issued.After(certificate.NotAfter) || issued.AddDate(0, 0, 1).Before(certificate.NotBefore) {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrSignatureInvalid).
+			WithDetail("reason", "the signing certificate was not valid when the document was issued").
+			WithDetail("issue_date", received.IssueDate.Format(invoicemodels.DateLayout)).
+			WithDetail("not_before", certificate.NotBefore.Format(time.RFC3339)).
+			WithDetail("not_after", certificate.NotAfter.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// trustedSigner reports whether the signing certificate chains to one of
+// the trusted roots, through the other certificates of the signature, as
+// of the document's issue date
+func (i *Importer) trustedSigner(received *ubl.Received, verified *xmlsig.Verified) bool {
+	if i.roots == nil {
+		return false
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range verified.Intermediates {
+		intermediates.AddCert(certificate)
+	}
+	_, err := verified.Certificate.Verify(x509.VerifyOptions{
+		Roots:         i.roots,
+		Intermediates: intermediates,
+		CurrentTime:   issuedAt(received),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil
+}
+
+// issuedAt is the issue date of a document with its time, if any
+func issuedAt(received *ubl.Received) time.Time {
+	issued := received.IssueDate
+	if received.IssueTime != nil {
+		issued = issued.Add(time.Duration(received.IssueTime.Hour())*time.Hour +
+			time.Duration(received.IssueTime.Minute())*time.Minute)
+	}
+	return issued
+}
+
+// matchProvider finds the provider the document is imported for: the one
+// requested, which must have the issuer's tax ID if it has one, or the
+// organization's provider with the issuer's tax ID
+func (i *Importer) matchProvider(ctx context.Context, req *dto.ImportDocumentRequest, pending *pendingImport) error {
+	issuer := pending.received.Supplier.TaxID
+
+	if req.ProviderID != nil {
+		provider, err := i.providers.GetByID(ctx, *req.ProviderID)
+		if err != nil {
+			return err
+		}
+		if provider.OrganizationID != req.OrganizationID {
+			return mismatch("the provider belongs to another organization").
+				WithDetail("provider_id", provider.ID.String())
+		}
+		if provider.TaxID == nil || *provider.TaxID == "" {
+			pending.warn("provider %q has no tax ID to compare with the issuer's %s", provider.Name, issuer)
+		} else if *provider.TaxID != issuer {
+			return mismatch("the provider has another tax ID than the issuer").
+				WithDetail("provider_tax_id", *provider.TaxID).
+				WithDetail("issuer_tax_id", issuer)
+		}
+		pending.provider = provider
+		return nil
+	}
+
+	provider, err := i.providers.GetByTaxIDAndOrganization(ctx, issuer, req.OrganizationID)
+	if err != nil {
+		if providers.IsProviderNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !provider.IsActive {
+		pending.warn("provider %q is inactive", provider.Name)
+	}
+	pending.provider = provider
+	return nil
+}
+
+// buildInvoice maps the document onto a create request for the invoice
+// type. invoice_number is the SUNAT file name of the document, unique
+// across issuers, so documents with the same series and number from two
+// providers do not collide. Read fields the type's schema does not allow
+// are left out, then the requested fields are merged over them.
+func (i *Importer) buildInvoice(ctx context.Context, req *dto.ImportDocumentRequest, pending *pendingImport, amounts *totals.Result) (*invoicedto.CreateInvoiceRequest, error) {
+	received := pending.received
+
+	data := invoicemodels.InvoiceData{
+		invoicemodels.FieldInvoiceNumber: received.Filename(),
+		invoicemodels.FieldInvoiceDate:   received.IssueDate.Format(invoicemodels.DateLayout),
+		invoicemodels.FieldCurrencyCode:  received.Currency,
+		invoicemodels.FieldTotalAmount:   amounts.Total.InexactFloat64(),
+	}
+	if received.DueDate != nil {
+		data[invoicemodels.FieldDueDate] = received.DueDate.Format(invoicemodels.DateLayout)
+	}
+	if received.OperationType != "" {
+		data[FieldOperationType] = received.OperationType
+	}
+
+	typeSchema, err := i.types.GetSchema(ctx, req.InvoiceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, fieldErr := range typeSchema.Validate(map[string]any(data)) {
+		if fieldErr.Rule == "additionalProperties" {
+			delete(data, strings.TrimPrefix(fieldErr.Path, "/"))
+		}
+	}
+	for key, value := range req.InvoiceData {
+		data[key] = value
+	}
+
+	// The invoice service sets the workflow's initial status
+	for _, fieldErr := range typeSchema.Validate(map[string]any(data)) {
+		if fieldErr.Path == "/"+invoicemodels.FieldStatus {
+			continue
+		}
+		pending.warn("invoice_data%s: %s", fieldErr.Path, fieldErr.Message)
+	}
+
+	invoice := &invoicedto.CreateInvoiceRequest{
+		InvoiceTypeID:  req.InvoiceTypeID,
+		OrganizationID: req.OrganizationID,
+		ProjectID:      req.ProjectID,
+		InvoiceData:    data,
+		CreatedBy:      req.ImportedBy,
+	}
+	if pending.provider != nil {
+		invoice.ProviderID = &pending.provider.ID
+	}
+
+	for _, line := range received.Lines {
+		item := invoicedto.LineItemRequest{
+			Description:    line.Description,
+			Quantity:       line.Quantity,
+			UnitPrice:      line.UnitPrice,
+			DiscountAmount: line.Discount,
+			TaxCode:        optional(string(line.TaxCategory)),
+			TaxRate:        line.TaxRate,
+		}
+		item.ItemCode = optional(line.ItemCode)
+		item.UnitCode = optional(line.UnitCode)
+		invoice.LineItems = append(invoice.LineItems, item)
+	}
+
+	if received.Type != ubl.TypeInvoice {
+		if err := i.resolveOriginal(ctx, req.OrganizationID, received, invoice); err != nil {
+			return nil, err
+		}
+	}
+
+	return invoice, nil
+}
+
+// resolveOriginal points a note at the invoice it adjusts, which must have
+// been imported from the same issuer
+func (i *Importer) resolveOriginal(ctx context.Context, orgID uuid.UUID, received *ubl.Received, invoice *invoicedto.CreateInvoiceRequest) error {
+	if received.Reference == nil || received.Discrepancy == nil || received.Discrepancy.Code == "" {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrUnsupportedDocument).
+			WithDetail("reason", "notes must reference the document they adjust and give a reason code")
+	}
+
+	reference := received.Reference
+	typeCode := reference.DocumentTypeCode
+	if typeCode == "" {
+		// Facturas and boletas are told apart by the series
+		typeCode = ubl.DocumentFactura
+		if strings.HasPrefix(reference.ID, "B") {
+			typeCode = ubl.DocumentBoleta
+		}
+	}
+
+	original, err := i.repo.GetImportByDocument(ctx, orgID, received.Supplier.TaxID, typeCode, reference.ID)
+	if err != nil {
+		if einvoice.IsImportNotFound(err) {
+			return mismatch("the document the note adjusts has not been imported").
+				WithDetail("reference_id", reference.ID).
+				WithDetail("reference_type_code", typeCode)
+		}
+		return err
+	}
+
+	invoice.OriginalInvoiceID = &original.InvoiceID
+	invoice.ReasonCode = &received.Discrepancy.Code
+	if received.Discrepancy.Description != "" {
+		invoice.Reason = &received.Discrepancy.Description
+	}
+	return nil
+}
+
+// warn records a difference that does not stop the import
+func (p *pendingImport) warn(format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// importTotals computes the amounts of the document's lines the way the
+// invoice will
+func importTotals(received *ubl.Received) (*totals.Result, error) {
+	lines := make([]totals.Line, len(received.Lines))
+	for i, line := range received.Lines {
+		lines[i] = totals.Line{
+			Quantity:       line.Quantity,
+			UnitPrice:      line.UnitPrice,
+			DiscountAmount: line.Discount,
+			TaxCode:        string(line.TaxCategory),
+			TaxRate:        line.TaxRate,
+		}
+	}
+	return totals.Calculate(lines)
+}
+
+// proposedProvider is the provider create_provider creates for an issuer
+func proposedProvider(orgID uuid.UUID, issuer ubl.Party) *providerdto.CreateProviderRequest {
+	name := issuer.RegistrationName
+	if name == "" {
+		name = issuer.TradeName
+	}
+	if name == "" {
+		name = issuer.TaxID
+	}
+	taxID := issuer.TaxID
+
+	metadata := map[string]any{MetadataTaxIDType: issuer.IdentityType}
+	if issuer.Address != nil && issuer.Address.Line != "" {
+		metadata[MetadataAddress] = issuer.Address.Line
+	}
+
+	return &providerdto.CreateProviderRequest{
+		OrganizationID: orgID,
+		Name:           name,
+		TaxID:          &taxID,
+		Metadata:       metadata,
+	}
+}
+
+func importedDocument(received *ubl.Received, computed decimal.Decimal) dto.ImportedDocument {
+	document := dto.ImportedDocument{
+		Format:           models.ImportFormatUBL,
+		DocumentTypeCode: received.DocumentTypeCode,
+		DocumentID:       received.ID,
+		IssueDate:        received.IssueDate.Format(invoicemodels.DateLayout),
+		Currency:         received.Currency,
+		Issuer:           importedParty(received.Supplier),
+		Customer:         importedParty(received.Customer),
+		DeclaredTotal:    received.PayableAmount,
+		ComputedTotal:    computed,
+	}
+	if received.DueDate != nil {
+		due := received.DueDate.Format(invoicemodels.DateLayout)
+		document.DueDate = &due
+	}
+	return document
+}
+
+func importedParty(party ubl.Party) dto.ImportedParty {
+	return dto.ImportedParty{
+		TaxID:            party.TaxID,
+		IdentityType:     party.IdentityType,
+		RegistrationName: party.RegistrationName,
+		TradeName:        party.TradeName,
+	}
+}
+
+func importedSignature(verified *xmlsig.Verified, trusted bool) dto.ImportedSignature {
+	certificate := verified.Certificate
+	return dto.ImportedSignature{
+		Algorithm:    verified.Algorithm,
+		Trusted:      trusted,
+		Subject:      certificate.Subject.String(),
+		Issuer:       certificate.Issuer.String(),
+		SerialNumber: certificate.SerialNumber.String(),
+		NotBefore:    certificate.NotBefore,
+		NotAfter:     certificate.NotAfter,
+	}
+}
+
+// rootElement returns the name of the document's root element, if it is
+// XML at all
+func rootElement(data []byte) xml.Name {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name
+		}
+	}
+}
+
+func mismatch(reason string) *errx.Error {
+	return einvoice.EInvoiceErrors.New(einvoice.ErrDocumentMismatch).WithDetail("reason", reason)
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,601 @@
+package einvoicesrv
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/dto"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/einvoice/repository"
+	invoicedto "github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+	"github.com/Abraxas-365/fuckturamelo/providers"
+	providerdto "github.com/Abraxas-365/fuckturamelo/providers/dto"
+	providermodels "github.com/Abraxas-365/fuckturamelo/providers/models"
+	"github.com/Abraxas-365/fuckturamelo/xmlsig"
+)
+
+const (
+	testIssuerTaxID   = "20123456789"
+	testCustomerTaxID = "20987654321"
+)
+
+// importRepo keeps the organization's tax profile and its imports; the
+// other methods are not used by the importer
+type importRepo struct {
+	postgres.EInvoiceRepository
+
+	profile   *models.TaxProfile
+	imports   []*models.Import
+	createErr error
+}
+
+func (r *importRepo) GetTaxProfile(ctx context.Context, orgID uuid.UUID) (*models.TaxProfile, error) {
+	if r.profile == nil || r.profile.OrganizationID != orgID {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrTaxProfileNotFound)
+	}
+	return r.profile, nil
+}
+
+func (r *importRepo) GetImportByDocument(ctx context.Context, orgID uuid.UUID, issuerTaxID, documentTypeCode, documentID string) (*models.Import, error) {
+	for _, imp := range r.imports {
+		if imp.OrganizationID == orgID && imp.IssuerTaxID == issuerTaxID && imp.DocumentTypeCode == documentTypeCode && imp.DocumentID == documentID {
+			return imp, nil
+		}
+	}
+	return nil, einvoice.EInvoiceErrors.New(einvoice.ErrImportNotFound)
+}
+
+func (r *importRepo) CreateImport(ctx context.Context, imp *models.Import) (*models.Import, error) {
+	if r.createErr != nil {
+		return nil, r.createErr
+	}
+	r.imports = append(r.imports, imp)
+	return imp, nil
+}
+
+// importInvoices keeps the created and discarded invoices
+type importInvoices struct {
+	created   []*invoicedto.CreateInvoiceRequest
+	discarded []uuid.UUID
+}
+
+func (s *importInvoices) CreateInvoice(ctx context.Context, req *invoicedto.CreateInvoiceRequest) (*invoicedto.InvoiceResponse, error) {
+	s.created = append(s.created, req)
+	return &invoicedto.InvoiceResponse{Invoice: &invoicemodels.Invoice{
+		ID:             uuid.New(),
+		OrganizationID: req.OrganizationID,
+		InvoiceTypeID:  req.InvoiceTypeID,
+		ProviderID:     req.ProviderID,
+	}}, nil
+}
+
+func (s *importInvoices) DiscardInvoice(ctx context.Context, id uuid.UUID) error {
+	s.discarded = append(s.discarded, id)
+	return nil
+}
+
+// importTypes describes every invoice type as holding kind, with a schema
+// that accepts any field
+type importTypes struct {
+	kind typemodels.DocumentKind
+}
+
+func (s *importTypes) GetSchema(ctx context.Context, id uuid.UUID) (*schema.Schema, error) {
+	return schema.Compile(map[string]any{"type": "object"})
+}
+
+func (s *importTypes) GetDocumentKind(ctx context.Context, id uuid.UUID) (typemodels.DocumentKind, error) {
+	return s.kind, nil
+}
+
+// importProviders keeps the organization's providers
+type importProviders struct {
+	providers []*providermodels.Provider
+	created   []*providerdto.CreateProviderRequest
+}
+
+func (s *importProviders) GetByID(ctx context.Context, id uuid.UUID) (*providermodels.Provider, error) {
+	for _, provider := range s.providers {
+		if provider.ID == id {
+			return provider, nil
+		}
+	}
+	return nil, providers.ProvidersErrors.New(providers.ErrProviderNotFound)
+}
+
+func (s *importProviders) GetByTaxIDAndOrganization(ctx context.Context, taxID string, orgID uuid.UUID) (*providermodels.Provider, error) {
+	for _, provider := range s.providers {
+		if provider.OrganizationID == orgID && provider.TaxID != nil && *provider.TaxID == taxID {
+			return provider, nil
+		}
+	}
+	return nil, providers.ProvidersErrors.New(providers.ErrProviderNotFound)
+}
+
+func (s *importProviders) CreateProvider(ctx context.Context, req *providerdto.CreateProviderRequest) (*providerdto.ProviderResponse, error) {
+	s.created = append(s.created, req)
+	return &providerdto.ProviderResponse{ID: uuid.New(), OrganizationID: req.OrganizationID, Name: req.Name, TaxID: req.TaxID}, nil
+}
+
+// testCA issues signing certificates under a root the importer trusts
+type testCA struct {
+	root *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA", Country: []string{"PE"}},
+		NotBefore:             time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{root: root, key: key}
+}
+
+// issue returns a signing certificate for subject valid between the years
+// given, issued by the CA or, if ca is nil, self-signed
+func (ca *testCA) issue(t *testing.T, subject string, fromYear, untilYear int) *xmlsig.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: subject, Country: []string{"PE"}},
+		NotBefore:    time.Date(fromYear, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(untilYear, 1, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	parent, parentKey := template, crypto.Signer(key)
+	if ca != nil {
+		parent, parentKey = ca.root, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &xmlsig.Certificate{Key: key, Leaf: leaf}
+}
+
+// signFixture signs a document of testdata after replacing old/new pairs
+// in it
+func signFixture(t *testing.T, name string, certificate *xmlsig.Certificate, replace ...string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(replace); i += 2 {
+		if !bytes.Contains(data, []byte(replace[i])) {
+			t.Fatalf("%s does not hold %q", name, replace[i])
+		}
+		data = bytes.ReplaceAll(data, []byte(replace[i]), []byte(replace[i+1]))
+	}
+	signed, err := xmlsig.Sign(data, certificate, xmlsig.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// importStubs are the importer's collaborators
+type importStubs struct {
+	orgID       uuid.UUID
+	repo        *importRepo
+	invoices    *importInvoices
+	types       *importTypes
+	providers   *importProviders
+	attachments *cdrAttachments
+}
+
+// provider adds a provider with the given tax ID to the organization
+func (s *importStubs) provider(taxID string, active bool) *providermodels.Provider {
+	provider := &providermodels.Provider{
+		ID:             uuid.New(),
+		OrganizationID: s.orgID,
+		Name:           "Provider " + taxID,
+		IsActive:       active,
+	}
+	if taxID != "" {
+		provider.TaxID = &taxID
+	}
+	s.providers.providers = append(s.providers.providers, provider)
+	return provider
+}
+
+func newTestImporter(roots *x509.CertPool) (*Importer, *importStubs) {
+	orgID := uuid.New()
+	stubs := &importStubs{
+		orgID:       orgID,
+		repo:        &importRepo{profile: &models.TaxProfile{OrganizationID: orgID, TaxID: testCustomerTaxID}},
+		invoices:    &importInvoices{},
+		types:       &importTypes{kind: typemodels.KindInvoice},
+		providers:   &importProviders{},
+		attachments: &cdrAttachments{},
+	}
+	importer := NewImporter(stubs.repo, stubs.invoices, stubs.types, stubs.providers, stubs.providers, stubs.attachments, roots)
+	return importer, stubs
+}
+
+func TestImport(t *testing.T) {
+	ca := newTestCA(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.root)
+	signers := map[string]*xmlsig.Certificate{
+		"trusted":     ca.issue(t, testIssuerTaxID+" ACME S.A.C.", 2023, 2027),
+		"self-signed": (*testCA)(nil).issue(t, testIssuerTaxID+" ACME S.A.C.", 2023, 2027),
+		"other":       ca.issue(t, "20111111111 OTRA S.A.C.", 2023, 2027),
+		"expired":     ca.issue(t, testIssuerTaxID+" ACME S.A.C.", 2021, 2024),
+	}
+
+	tests := []struct {
+		name    string
+		fixture string
+		replace []string // old, new pairs applied to the fixture
+		signer  string   // defaults to trusted
+		setup   func(s *importStubs, req *dto.ImportDocumentRequest)
+		wantErr errx.Code
+		check   func(t *testing.T, s *importStubs, result *dto.ImportResult)
+	}{
+		{
+			name:    "invoice of a known provider",
+			fixture: "invoice.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.provider(testIssuerTaxID, true)
+			},
+			check: func(t *testing.T, s *importStubs, result *dto.ImportResult) {
+				invoice := s.invoices.created[0]
+				if got := invoice.InvoiceData[invoicemodels.FieldInvoiceNumber]; got != "20123456789-01-F001-123" {
+					t.Errorf("invoice number = %v; want the document's file name", got)
+				}
+				if got := invoice.InvoiceData[invoicemodels.FieldTotalAmount]; got != 118.0 {
+					t.Errorf("total = %v; want 118", got)
+				}
+				if *invoice.ProviderID != s.providers.providers[0].ID {
+					t.Errorf("provider = %s; want the one with the issuer's tax ID", *invoice.ProviderID)
+				}
+				if len(invoice.LineItems) != 1 || invoice.LineItems[0].Description != "Cartucho de tinta negra" {
+					t.Errorf("line items = %+v; want the document's line", invoice.LineItems)
+				}
+				if result.ProviderCreated {
+					t.Error("provider created for a known issuer")
+				}
+				imp := result.Import
+				if !imp.SignerTrusted || imp.DocumentID != "F001-123" || imp.IssuerTaxID != testIssuerTaxID {
+					t.Errorf("import = %+v; want the trusted F001-123 of %s", imp, testIssuerTaxID)
+				}
+				if len(imp.Warnings) != 0 {
+					t.Errorf("warnings = %v; want none", imp.Warnings)
+				}
+				if imp.AttachmentID == nil || len(s.attachments.uploads) != 1 {
+					t.Error("original XML not attached")
+				}
+			},
+		},
+		{
+			name:    "signer not chained to a trusted root",
+			fixture: "invoice.xml",
+			signer:  "self-signed",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.provider(testIssuerTaxID, true)
+			},
+			check: func(t *testing.T, s *importStubs, result *dto.ImportResult) {
+				if result.Import.SignerTrusted {
+					t.Error("self-signed signer recorded as trusted")
+				}
+				if len(result.Import.Warnings) != 1 || !strings.Contains(result.Import.Warnings[0], "trusted certification authority") {
+					t.Errorf("warnings = %v; want the untrusted signer", result.Import.Warnings)
+				}
+			},
+		},
+		{
+			name:    "signer names another tax ID",
+			fixture: "invoice.xml",
+			signer:  "other",
+			wantErr: einvoice.ErrSignatureInvalid,
+		},
+		{
+			name:    "signer expired before the issue date",
+			fixture: "invoice.xml",
+			signer:  "expired",
+			wantErr: einvoice.ErrSignatureInvalid,
+		},
+		{
+			name:    "declared total differs from the lines",
+			fixture: "invoice.xml",
+			replace: []string{`<cbc:PayableAmount currencyID="PEN">118.00</cbc:PayableAmount>`, `<cbc:PayableAmount currencyID="PEN">120.00</cbc:PayableAmount>`},
+			wantErr: einvoice.ErrDocumentMismatch,
+		},
+		{
+			name:    "issued to another customer",
+			fixture: "invoice.xml",
+			replace: []string{testCustomerTaxID, "20555555555"},
+			wantErr: einvoice.ErrDocumentMismatch,
+		},
+		{
+			name:    "already imported",
+			fixture: "invoice.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.repo.imports = append(s.repo.imports, &models.Import{
+					OrganizationID:   s.orgID,
+					InvoiceID:        uuid.New(),
+					IssuerTaxID:      testIssuerTaxID,
+					DocumentTypeCode: "01",
+					DocumentID:       "F001-123",
+				})
+			},
+			wantErr: einvoice.ErrImportExists,
+		},
+		{
+			name:    "invoice type of another kind",
+			fixture: "invoice.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.types.kind = typemodels.KindCreditNote
+			},
+			wantErr: einvoice.ErrDocumentMismatch,
+		},
+		{
+			name:    "issuer without provider",
+			fixture: "invoice.xml",
+			wantErr: einvoice.ErrProviderRequired,
+		},
+		{
+			name:    "issuer without provider, created",
+			fixture: "invoice.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				req.CreateProvider = true
+			},
+			check: func(t *testing.T, s *importStubs, result *dto.ImportResult) {
+				if !result.ProviderCreated || len(s.providers.created) != 1 {
+					t.Fatal("proposed provider not created")
+				}
+				proposed := s.providers.created[0]
+				if proposed.Name != "ACME S.A.C." || *proposed.TaxID != testIssuerTaxID || proposed.OrganizationID != s.orgID {
+					t.Errorf("proposed provider = %+v; want ACME S.A.C. with the issuer's tax ID", proposed)
+				}
+				if proposed.Metadata[MetadataAddress] != "AV. LARCO 123, MIRAFLORES" {
+					t.Errorf("proposed metadata = %v; want the issuer's address", proposed.Metadata)
+				}
+				if result.Invoice.ProviderID == nil || *result.Invoice.ProviderID != *result.Import.ProviderID {
+					t.Error("invoice not imported for the created provider")
+				}
+			},
+		},
+		{
+			name:    "requested provider with another tax ID",
+			fixture: "invoice.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				req.ProviderID = &s.provider("20111111111", true).ID
+			},
+			wantErr: einvoice.ErrDocumentMismatch,
+		},
+		{
+			name:    "requested provider without tax ID",
+			fixture: "invoice.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				req.ProviderID = &s.provider("", true).ID
+			},
+			check: func(t *testing.T, s *importStubs, result *dto.ImportResult) {
+				if len(result.Import.Warnings) != 1 || !strings.Contains(result.Import.Warnings[0], "has no tax ID") {
+					t.Errorf("warnings = %v; want the provider without tax ID", result.Import.Warnings)
+				}
+			},
+		},
+		{
+			name:    "inactive provider",
+			fixture: "invoice.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.provider(testIssuerTaxID, false)
+			},
+			check: func(t *testing.T, s *importStubs, result *dto.ImportResult) {
+				if len(result.Import.Warnings) != 1 || !strings.Contains(result.Import.Warnings[0], "inactive") {
+					t.Errorf("warnings = %v; want the inactive provider", result.Import.Warnings)
+				}
+			},
+		},
+		{
+			name:    "credit note of an imported invoice",
+			fixture: "credit-note.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.types.kind = typemodels.KindCreditNote
+				s.provider(testIssuerTaxID, true)
+				s.repo.imports = append(s.repo.imports, &models.Import{
+					OrganizationID:   s.orgID,
+					InvoiceID:        uuid.New(),
+					IssuerTaxID:      testIssuerTaxID,
+					DocumentTypeCode: "01",
+					DocumentID:       "F001-123",
+				})
+			},
+			check: func(t *testing.T, s *importStubs, result *dto.ImportResult) {
+				note := s.invoices.created[0]
+				if note.OriginalInvoiceID == nil || *note.OriginalInvoiceID != s.repo.imports[0].InvoiceID {
+					t.Errorf("original invoice = %v; want the invoice F001-123 was imported as", note.OriginalInvoiceID)
+				}
+				if note.ReasonCode == nil || *note.ReasonCode != "07" {
+					t.Errorf("reason code = %v; want 07", note.ReasonCode)
+				}
+				if note.Reason == nil || *note.Reason != "Devolucion de un cartucho" {
+					t.Errorf("reason = %v; want the discrepancy's description", note.Reason)
+				}
+			},
+		},
+		{
+			name:    "credit note of an invoice not imported",
+			fixture: "credit-note.xml",
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.types.kind = typemodels.KindCreditNote
+				s.provider(testIssuerTaxID, true)
+			},
+			wantErr: einvoice.ErrDocumentMismatch,
+		},
+		{
+			name:    "credit note referencing a boleta by series",
+			fixture: "credit-note.xml",
+			replace: []string{"<cbc:DocumentTypeCode>01</cbc:DocumentTypeCode>", "", "F001-123", "B001-9"},
+			setup: func(s *importStubs, req *dto.ImportDocumentRequest) {
+				s.types.kind = typemodels.KindCreditNote
+				s.provider(testIssuerTaxID, true)
+				s.repo.imports = append(s.repo.imports, &models.Import{
+					OrganizationID:   s.orgID,
+					InvoiceID:        uuid.New(),
+					IssuerTaxID:      testIssuerTaxID,
+					DocumentTypeCode: "03",
+					DocumentID:       "B001-9",
+				})
+			},
+			check: func(t *testing.T, s *importStubs, result *dto.ImportResult) {
+				note := s.invoices.created[0]
+				if note.OriginalInvoiceID == nil || *note.OriginalInvoiceID != s.repo.imports[0].InvoiceID {
+					t.Errorf("original invoice = %v; want the boleta B001-9", note.OriginalInvoiceID)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, stubs := newTestImporter(roots)
+			signer := tt.signer
+			if signer == "" {
+				signer = "trusted"
+			}
+			req := &dto.ImportDocumentRequest{
+				OrganizationID: stubs.orgID,
+				InvoiceTypeID:  uuid.New(),
+				Data:           signFixture(t, tt.fixture, signers[signer], tt.replace...),
+			}
+			if tt.setup != nil {
+				tt.setup(stubs, req)
+			}
+
+			result, err := importer.Import(context.Background(), req)
+			if tt.wantErr != "" {
+				if !errx.IsCode(err, tt.wantErr) {
+					t.Fatalf("err = %v; want %s", err, tt.wantErr)
+				}
+				if len(stubs.invoices.created) != 0 {
+					t.Error("invoice created for a refused document")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stubs.invoices.created) != 1 || result.Invoice == nil || result.Import == nil {
+				t.Fatal("invoice or import not created")
+			}
+			if result.Import.InvoiceID != result.Invoice.ID {
+				t.Errorf("import of invoice %s; want %s", result.Import.InvoiceID, result.Invoice.ID)
+			}
+			if tt.check != nil {
+				tt.check(t, stubs, result)
+			}
+		})
+	}
+}
+
+func TestImportTampered(t *testing.T) {
+	ca := newTestCA(t)
+	importer, stubs := newTestImporter(nil)
+	stubs.provider(testIssuerTaxID, true)
+
+	signed := signFixture(t, "invoice.xml", ca.issue(t, testIssuerTaxID+" ACME S.A.C.", 2023, 2027))
+	_, err := importer.Import(context.Background(), &dto.ImportDocumentRequest{
+		OrganizationID: stubs.orgID,
+		InvoiceTypeID:  uuid.New(),
+		Data:           bytes.Replace(signed, []byte("50.00</cbc:PriceAmount>"), []byte("40.00</cbc:PriceAmount>"), 1),
+	})
+	if !errx.IsCode(err, einvoice.ErrSignatureInvalid) {
+		t.Errorf("err = %v; want %s", err, einvoice.ErrSignatureInvalid)
+	}
+}
+
+func TestImportWithoutRoots(t *testing.T) {
+	ca := newTestCA(t)
+	importer, stubs := newTestImporter(nil)
+	stubs.provider(testIssuerTaxID, true)
+
+	preview, err := importer.Preview(context.Background(), &dto.ImportDocumentRequest{
+		OrganizationID: stubs.orgID,
+		InvoiceTypeID:  uuid.New(),
+		Data:           signFixture(t, "invoice.xml", ca.issue(t, testIssuerTaxID+" ACME S.A.C.", 2023, 2027)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Signature.Trusted || len(preview.Warnings) != 1 {
+		t.Errorf("signature trusted = %v with warnings %v; want untrusted with a warning", preview.Signature.Trusted, preview.Warnings)
+	}
+	if len(stubs.invoices.created) != 0 || len(stubs.repo.imports) != 0 {
+		t.Error("preview wrote an invoice or an import")
+	}
+}
+
+func TestImportDiscardsInvoice(t *testing.T) {
+	ca := newTestCA(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.root)
+	importer, stubs := newTestImporter(roots)
+	stubs.provider(testIssuerTaxID, true)
+	stubs.repo.createErr = errors.New("connection reset")
+
+	_, err := importer.Import(context.Background(), &dto.ImportDocumentRequest{
+		OrganizationID: stubs.orgID,
+		InvoiceTypeID:  uuid.New(),
+		Data:           signFixture(t, "invoice.xml", ca.issue(t, testIssuerTaxID+" ACME S.A.C.", 2023, 2027)),
+	})
+	if !errors.Is(err, stubs.repo.createErr) {
+		t.Fatalf("err = %v; want the failure recording the import", err)
+	}
+	if len(stubs.invoices.created) != 1 {
+		t.Fatalf("created %d invoices; want 1", len(stubs.invoices.created))
+	}
+	if len(stubs.invoices.discarded) != 1 {
+		t.Fatalf("discarded %d invoices; want the created one", len(stubs.invoices.discarded))
+	}
+}
@@ -0,0 +1,486 @@
+package einvoicesrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/dto"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/einvoice/repository"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	providermodels "github.com/Abraxas-365/fuckturamelo/providers/models"
+	"github.com/Abraxas-365/fuckturamelo/sunat"
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+	"github.com/Abraxas-365/fuckturamelo/xmlsig"
+)
+
+// Keys read from the JSON payloads of invoices and providers
+const (
+	// FieldOperationType is the SUNAT catalog 51 operation type in
+	// invoice_data; defaults to internal sale
+	FieldOperationType = "operation_type"
+
+	// Provider metadata describing the provider as a customer
+	MetadataTaxIDType = "tax_id_type"
+	MetadataAddress   = "address"
+)
+
+// EInvoiceService defines the interface for electronic invoicing business
+// logic
+type EInvoiceService interface {
+	GetTaxProfile(ctx context.Context, orgID uuid.UUID) (*models.TaxProfile, error)
+	SaveTaxProfile(ctx context.Context, orgID uuid.UUID, req *dto.SaveTaxProfileRequest) (*models.TaxProfile, error)
+
+	// BuildUBL renders an invoice as UBL 2.1 XML issued by its organization
+	// to its provider
+	BuildUBL(ctx context.Context, invoiceID uuid.UUID) (*dto.Document, error)
+
+	// SignUBL renders an invoice like BuildUBL and signs it with the
+	// organization's certificate
+	SignUBL(ctx context.Context, invoiceID uuid.UUID) (*dto.Document, error)
+
+	GetCertificate(ctx context.Context, orgID uuid.UUID) (*models.Certificate, error)
+	UploadCertificate(ctx context.Context, orgID uuid.UUID, req *dto.UploadCertificateRequest) (*models.Certificate, error)
+	DeleteCertificate(ctx context.Context, orgID uuid.UUID) error
+
+	GetSunatCredentials(ctx context.Context, orgID uuid.UUID) (*models.SunatCredentials, error)
+	SaveSunatCredentials(ctx context.Context, orgID uuid.UUID, req *dto.SaveSunatCredentialsRequest) (*models.SunatCredentials, error)
+	DeleteSunatCredentials(ctx context.Context, orgID uuid.UUID) error
+
+	// SubmitInvoice signs an invoice and sends it to SUNAT. The submission
+	// is returned after the first attempt; if SUNAT could not be reached
+	// it stays pending and the submitter tries again later.
+	SubmitInvoice(ctx context.Context, invoiceID uuid.UUID, req *dto.SubmitInvoiceRequest) (*models.Submission, error)
+	ListSubmissions(ctx context.Context, invoiceID uuid.UUID) ([]models.Submission, error)
+
+	// PreviewImport checks a document received from a provider and returns
+	// the invoice ImportDocument would create from it
+	PreviewImport(ctx context.Context, req *dto.ImportDocumentRequest) (*dto.ImportPreview, error)
+
+	// ImportDocument verifies a document received from a provider and
+	// creates its invoice, keeping the XML as an attachment
+	ImportDocument(ctx context.Context, req *dto.ImportDocumentRequest) (*dto.ImportResult, error)
+	GetInvoiceImport(ctx context.Context, invoiceID uuid.UUID) (*models.Import, error)
+}
+
+// Options tunes signing and submission
+type Options struct {
+	// CertificateKey is the base64 encoded 32-byte master key signing
+	// certificates and SOL passwords are sealed with. Signing and
+	// submission are disabled without it.
+	CertificateKey string `json:"certificate_key"`
+
+	// XAdES adds XAdES-BES signed properties to signatures
+	XAdES bool `json:"xades"`
+
+	// SUNAT is the web service signed documents are submitted to
+	SUNAT sunat.Config `json:"sunat"`
+
+	// TrustedRootsFile is a PEM bundle of the certification authorities
+	// the signers of imported documents are checked against. Without it
+	// every signer is imported as untrusted.
+	TrustedRootsFile string `json:"trusted_roots_file"`
+}
+
+// Invoices reads the invoices being exported (implemented by
+// invoices/repository.InvoiceRepository)
+type Invoices interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error)
+}
+
+// Providers reads the customers of exported invoices (implemented by
+// providers/repository.ProviderRepository)
+type Providers interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*providermodels.Provider, error)
+}
+
+// einvoiceService implements EInvoiceService
+type einvoiceService struct {
+	repo      postgres.EInvoiceRepository
+	invoices  Invoices
+	providers Providers
+	keystore  *xmlsig.Keystore
+	submitter *Submitter
+	importer  *Importer
+	options   Options
+}
+
+// NewEInvoiceService creates a new electronic invoicing service. A nil
+// keystore disables certificates, signing and submission.
+func NewEInvoiceService(repo postgres.EInvoiceRepository, invoices Invoices, providers Providers, keystore *xmlsig.Keystore, submitter *Submitter, importer *Importer, options Options) EInvoiceService {
+	return &einvoiceService{
+		repo:      repo,
+		invoices:  invoices,
+		providers: providers,
+		keystore:  keystore,
+		submitter: submitter,
+		importer:  importer,
+		options:   options,
+	}
+}
+
+// GetTaxProfile returns the tax profile of an organization
+func (s *einvoiceService) GetTaxProfile(ctx context.Context, orgID uuid.UUID) (*models.TaxProfile, error) {
+	return s.repo.GetTaxProfile(ctx, orgID)
+}
+
+// SaveTaxProfile validates and stores the tax profile of an organization
+func (s *einvoiceService) SaveTaxProfile(ctx context.Context, orgID uuid.UUID, req *dto.SaveTaxProfileRequest) (*models.TaxProfile, error) {
+	profile := &models.TaxProfile{
+		OrganizationID:    orgID,
+		TaxID:             strings.TrimSpace(req.TaxID),
+		TaxIDType:         req.TaxIDType,
+		LegalName:         strings.TrimSpace(req.LegalName),
+		TradeName:         req.TradeName,
+		AddressLine:       req.AddressLine,
+		Ubigeo:            req.Ubigeo,
+		District:          req.District,
+		Province:          req.Province,
+		Department:        req.Department,
+		CountryCode:       strings.ToUpper(req.CountryCode),
+		EstablishmentCode: req.EstablishmentCode,
+	}
+	if profile.TaxIDType == "" {
+		profile.TaxIDType = ubl.IdentityRUC
+	}
+	if profile.CountryCode == "" {
+		profile.CountryCode = "PE"
+	}
+	if profile.EstablishmentCode == "" {
+		profile.EstablishmentCode = "0000"
+	}
+
+	if profile.TaxID == "" || profile.LegalName == "" {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "tax_id and legal_name are required")
+	}
+	if _, ok := ubl.IdentityTypes[profile.TaxIDType]; !ok {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("field", "tax_id_type").
+			WithDetail("reason", "not a SUNAT catalog 06 code")
+	}
+	if profile.TaxIDType == ubl.IdentityRUC && ubl.IdentityType(profile.TaxID) != ubl.IdentityRUC {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("field", "tax_id").
+			WithDetail("reason", "a RUC has 11 digits")
+	}
+
+	return s.repo.SaveTaxProfile(ctx, profile)
+}
+
+// BuildUBL maps an invoice onto a UBL document and renders it
+func (s *einvoiceService) BuildUBL(ctx context.Context, invoiceID uuid.UUID) (*dto.Document, error) {
+	invoice, err := s.getInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.render(ctx, invoice)
+}
+
+// SignUBL renders an invoice and embeds the organization's signature
+func (s *einvoiceService) SignUBL(ctx context.Context, invoiceID uuid.UUID) (*dto.Document, error) {
+	if s.keystore == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSigningNotConfigured)
+	}
+
+	invoice, err := s.getInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sign(ctx, invoice)
+}
+
+// GetCertificate returns the signing certificate of an organization
+func (s *einvoiceService) GetCertificate(ctx context.Context, orgID uuid.UUID) (*models.Certificate, error) {
+	return s.repo.GetCertificate(ctx, orgID)
+}
+
+// UploadCertificate seals a PKCS#12 bundle for the organization, replacing
+// its previous certificate. The bundle must open with the password and
+// hold a certificate that has not expired.
+func (s *einvoiceService) UploadCertificate(ctx context.Context, orgID uuid.UUID, req *dto.UploadCertificateRequest) (*models.Certificate, error) {
+	if s.keystore == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSigningNotConfigured)
+	}
+	if len(req.Data) == 0 {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("field", "file").
+			WithDetail("reason", "empty")
+	}
+
+	sealed, certificate, err := s.keystore.Seal(orgID.String(), req.Data, req.Password)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrInvalidCertificate).
+			WithDetail("reason", err.Error())
+	}
+	if time.Now().After(certificate.Leaf.NotAfter) {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrCertificateExpired).
+			WithDetail("not_after", certificate.Leaf.NotAfter)
+	}
+
+	return s.repo.SaveCertificate(ctx, &models.Certificate{
+		OrganizationID:    orgID,
+		SealedPKCS12:      sealed,
+		Subject:           certificate.Leaf.Subject.String(),
+		Issuer:            certificate.Leaf.Issuer.String(),
+		SerialNumber:      certificate.Leaf.SerialNumber.String(),
+		FingerprintSHA256: certificate.Fingerprint(),
+		NotBefore:         certificate.Leaf.NotBefore,
+		NotAfter:          certificate.Leaf.NotAfter,
+		UploadedBy:        req.UploadedBy,
+	})
+}
+
+// DeleteCertificate removes the signing certificate of an organization
+func (s *einvoiceService) DeleteCertificate(ctx context.Context, orgID uuid.UUID) error {
+	return s.repo.DeleteCertificate(ctx, orgID)
+}
+
+// signingCertificate opens the organization's certificate and checks it is
+// valid now
+func (s *einvoiceService) signingCertificate(ctx context.Context, orgID uuid.UUID) (*xmlsig.Certificate, error) {
+	stored, err := s.repo.GetCertificate(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !stored.ValidAt(time.Now()) {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrCertificateExpired).
+			WithDetail("organization_id", orgID.String()).
+			WithDetail("not_before", stored.NotBefore).
+			WithDetail("not_after", stored.NotAfter)
+	}
+
+	certificate, err := s.keystore.Open(orgID.String(), stored.SealedPKCS12)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSigningFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+	return certificate, nil
+}
+
+// sign renders an invoice and signs it with the organization's certificate
+func (s *einvoiceService) sign(ctx context.Context, invoice *invoicemodels.Invoice) (*dto.Document, error) {
+	certificate, err := s.signingCertificate(ctx, invoice.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := s.render(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := xmlsig.Sign(document.Content, certificate, xmlsig.Options{XAdES: s.options.XAdES})
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSigningFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+
+	document.Content = signed
+	return document, nil
+}
+
+// render maps an invoice onto a UBL document and renders it
+func (s *einvoiceService) render(ctx context.Context, invoice *invoicemodels.Invoice) (*dto.Document, error) {
+	doc, err := s.document(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ubl.Marshal(doc)
+	if err != nil {
+		return nil, notExportable(invoice.ID, err.Error())
+	}
+
+	return &dto.Document{
+		FileName: doc.Filename() + ".xml",
+		Content:  content,
+	}, nil
+}
+
+// document maps an invoice onto a UBL document. The organization's tax
+// profile is the supplier and the invoice's provider the customer.
+func (s *einvoiceService) document(ctx context.Context, invoice *invoicemodels.Invoice) (*ubl.Document, error) {
+	invoiceID := invoice.ID
+	switch {
+	case invoice.InvoiceNumber == nil:
+		return nil, notExportable(invoiceID, "invoice has no number")
+	case invoice.InvoiceDate == nil:
+		return nil, notExportable(invoiceID, "invoice has no date")
+	case invoice.CurrencyCode == nil:
+		return nil, notExportable(invoiceID, "invoice has no currency")
+	case invoice.ProviderID == nil:
+		return nil, notExportable(invoiceID, "invoice has no provider to issue it to")
+	case len(invoice.LineItems) == 0:
+		return nil, notExportable(invoiceID, "invoice has no line items")
+	}
+
+	profile, err := s.repo.GetTaxProfile(ctx, invoice.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.providers.GetByID(ctx, *invoice.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := customerParty(provider)
+	if err != nil {
+		return nil, notExportable(invoiceID, err.Error())
+	}
+
+	doc := &ubl.Document{
+		Type:             ubl.TypeInvoice,
+		DocumentTypeCode: documentTypeCode(*invoice.InvoiceNumber),
+		ID:               *invoice.InvoiceNumber,
+		IssueDate:        *invoice.InvoiceDate,
+		DueDate:          invoice.DueDate,
+		Currency:         *invoice.CurrencyCode,
+		Supplier:         profile.Party(),
+		Customer:         customer,
+	}
+	if operationType, ok := invoice.InvoiceData[FieldOperationType].(string); ok {
+		doc.OperationType = operationType
+	}
+
+	for i, item := range invoice.LineItems {
+		line, err := documentLine(item)
+		if err != nil {
+			return nil, notExportable(invoiceID, fmt.Sprintf("line_items[%d]: %v", i, err))
+		}
+		doc.Lines = append(doc.Lines, line)
+	}
+
+	if invoice.DocumentKind.IsAdjustment() {
+		if err := s.addReference(ctx, doc, invoice); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// addReference turns the document into a credit or debit note of the
+// invoice it adjusts
+func (s *einvoiceService) addReference(ctx context.Context, doc *ubl.Document, invoice *invoicemodels.Invoice) error {
+	doc.Type, doc.DocumentTypeCode = ubl.TypeCreditNote, ubl.DocumentCreditNote
+	if invoice.DocumentKind == typemodels.KindDebitNote {
+		doc.Type, doc.DocumentTypeCode = ubl.TypeDebitNote, ubl.DocumentDebitNote
+	}
+	doc.DueDate = nil
+
+	if invoice.OriginalInvoiceID == nil {
+		return notExportable(invoice.ID, "note does not reference the invoice it adjusts")
+	}
+	original, err := s.invoices.GetByID(ctx, *invoice.OriginalInvoiceID)
+	if err != nil {
+		return err
+	}
+	if original.InvoiceNumber == nil {
+		return notExportable(invoice.ID, "adjusted invoice has no number")
+	}
+
+	doc.Reference = &ubl.Reference{
+		ID:               *original.InvoiceNumber,
+		DocumentTypeCode: documentTypeCode(*original.InvoiceNumber),
+	}
+	doc.Discrepancy = &ubl.Discrepancy{}
+	if invoice.AdjustmentReasonCode != nil {
+		doc.Discrepancy.Code = *invoice.AdjustmentReasonCode
+	}
+	if invoice.AdjustmentReason != nil {
+		doc.Discrepancy.Description = *invoice.AdjustmentReason
+	}
+	return nil
+}
+
+// Helper methods
+
+func (s *einvoiceService) getInvoice(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error) {
+	invoice, err := s.invoices.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.IsDeleted {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+			WithDetail("invoice_id", id.String())
+	}
+	return invoice, nil
+}
+
+// customerParty describes a provider as the customer of a document. The
+// identity type and address may be given in the provider's metadata.
+func customerParty(provider *providermodels.Provider) (ubl.Party, error) {
+	if provider.TaxID == nil || *provider.TaxID == "" {
+		return ubl.Party{}, errors.New("provider has no tax ID")
+	}
+
+	party := ubl.Party{
+		IdentityType:     ubl.IdentityType(*provider.TaxID),
+		TaxID:            *provider.TaxID,
+		RegistrationName: provider.Name,
+	}
+	if identityType, ok := provider.Metadata[MetadataTaxIDType].(string); ok && identityType != "" {
+		party.IdentityType = identityType
+	}
+	if address, ok := provider.Metadata[MetadataAddress].(string); ok && address != "" {
+		party.Address = &ubl.Address{Line: address}
+	}
+	return party, nil
+}
+
+// documentLine maps a line item onto a document line. Discounts are taken
+// from the stored amounts so percent discounts render as amounts.
+func documentLine(item invoicemodels.LineItem) (ubl.Line, error) {
+	line := ubl.Line{
+		Description: item.Description,
+		Quantity:    item.Quantity,
+		UnitPrice:   item.UnitPrice,
+		Discount:    item.GrossAmount.Sub(item.NetAmount),
+		TaxRate:     item.TaxRate,
+	}
+	if item.ItemCode != nil {
+		line.ItemCode = *item.ItemCode
+	}
+	if item.UnitCode != nil {
+		line.UnitCode = *item.UnitCode
+	}
+
+	taxCode := ""
+	if item.TaxCode != nil {
+		taxCode = *item.TaxCode
+	}
+	category, ok := ubl.ParseTaxCategory(taxCode, item.TaxRate)
+	if !ok {
+		return ubl.Line{}, fmt.Errorf("tax code %q has no SUNAT tax category", taxCode)
+	}
+	line.TaxCategory = category
+
+	return line, nil
+}
+
+// documentTypeCode tells boletas, whose series start with B, from facturas
+func documentTypeCode(number string) string {
+	if strings.HasPrefix(number, "B") {
+		return ubl.DocumentBoleta
+	}
+	return ubl.DocumentFactura
+}
+
+func notExportable(invoiceID uuid.UUID, reason string) error {
+	return einvoice.EInvoiceErrors.New(einvoice.ErrDocumentNotExportable).
+		WithDetail("invoice_id", invoiceID.String()).
+		WithDetail("reason", reason)
+}
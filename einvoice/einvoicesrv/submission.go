@@ -0,0 +1,127 @@
+package einvoicesrv
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/dto"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	"github.com/Abraxas-365/fuckturamelo/sunat"
+)
+
+// GetSunatCredentials returns the SOL credentials of an organization
+func (s *einvoiceService) GetSunatCredentials(ctx context.Context, orgID uuid.UUID) (*models.SunatCredentials, error) {
+	return s.repo.GetSunatCredentials(ctx, orgID)
+}
+
+// SaveSunatCredentials seals the SOL password for the organization,
+// replacing its previous credentials. The username must start with the RUC
+// of the organization's tax profile, if it has one.
+func (s *einvoiceService) SaveSunatCredentials(ctx context.Context, orgID uuid.UUID, req *dto.SaveSunatCredentialsRequest) (*models.SunatCredentials, error) {
+	if s.keystore == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSigningNotConfigured)
+	}
+
+	username := strings.TrimSpace(req.Username)
+	if username == "" || req.Password == "" {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("error", "username and password are required")
+	}
+
+	profile, err := s.repo.GetTaxProfile(ctx, orgID)
+	switch {
+	case err == nil:
+		if !strings.HasPrefix(username, profile.TaxID) {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("field", "username").
+				WithDetail("reason", "must start with the RUC of the tax profile").
+				WithDetail("tax_id", profile.TaxID)
+		}
+	case !einvoice.IsTaxProfileNotFound(err):
+		return nil, err
+	}
+
+	sealed, err := s.keystore.SealSecret(orgID.String(), []byte(req.Password))
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSunatCredentialsSaveFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return s.repo.SaveSunatCredentials(ctx, &models.SunatCredentials{
+		OrganizationID: orgID,
+		Username:       username,
+		SealedPassword: sealed,
+		UpdatedBy:      req.UpdatedBy,
+	})
+}
+
+// DeleteSunatCredentials removes the SOL credentials of an organization
+func (s *einvoiceService) DeleteSunatCredentials(ctx context.Context, orgID uuid.UUID) error {
+	return s.repo.DeleteSunatCredentials(ctx, orgID)
+}
+
+// SubmitInvoice signs and zips an invoice, records a pending submission
+// leased to this call and makes the first attempt
+func (s *einvoiceService) SubmitInvoice(ctx context.Context, invoiceID uuid.UUID, req *dto.SubmitInvoiceRequest) (*models.Submission, error) {
+	if s.keystore == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSigningNotConfigured)
+	}
+
+	invoice, err := s.getInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without credentials the submission could only fail
+	if _, err := s.repo.GetSunatCredentials(ctx, invoice.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	document, err := s.sign(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+	archiveName, archive, err := sunat.Pack(document.FileName, document.Content)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrDocumentBuildFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	now := time.Now()
+	lockedUntil := now.Add(s.submitter.options.Lease)
+	submission, err := s.repo.CreateSubmission(ctx, &models.Submission{
+		ID:             uuid.New(),
+		InvoiceID:      invoice.ID,
+		OrganizationID: invoice.OrganizationID,
+		FileName:       archiveName,
+		Archive:        archive,
+		Status:         models.SubmissionPending,
+		NextAttemptAt:  &now,
+		LockedUntil:    &lockedUntil,
+		SubmittedBy:    req.SubmittedBy,
+		CreatedAt:      now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.submitter.attempt(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// ListSubmissions returns the submissions of an invoice, oldest first
+func (s *einvoiceService) ListSubmissions(ctx context.Context, invoiceID uuid.UUID) ([]models.Submission, error) {
+	if _, err := s.getInvoice(ctx, invoiceID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListSubmissions(ctx, invoiceID)
+}
@@ -0,0 +1,279 @@
+package einvoicesrv
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Abraxas-365/craftable/errx"
+
+	attachmentdto "github.com/Abraxas-365/fuckturamelo/attachments/dto"
+	attachmentmodels "github.com/Abraxas-365/fuckturamelo/attachments/models"
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/einvoice/repository"
+	"github.com/Abraxas-365/fuckturamelo/sunat"
+	"github.com/Abraxas-365/fuckturamelo/xmlsig"
+)
+
+// Submitter defaults
+const (
+	DefaultSubmitPollInterval = 30 * time.Second
+	DefaultSubmitLease        = 5 * time.Minute
+	DefaultSubmitBatchSize    = 20
+	DefaultMaxAttempts        = 8
+	DefaultInitialBackoff     = time.Minute
+	DefaultMaxBackoff         = 6 * time.Hour
+)
+
+// SubmitterOptions configures the submission of documents to SUNAT
+type SubmitterOptions struct {
+	// PollInterval is the time between looks for submissions due for
+	// another attempt
+	PollInterval time.Duration
+
+	// Lease is how long a claimed submission is reserved for this process;
+	// the submissions of a crashed process are picked up once it expires
+	Lease time.Duration
+
+	// BatchSize is the number of submissions claimed per poll
+	BatchSize int
+
+	// MaxAttempts is the number of times a document is sent before its
+	// submission fails
+	MaxAttempts int
+
+	// InitialBackoff is the wait after the first failed attempt; it
+	// doubles after every attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Sender sends zipped documents to SUNAT (implemented by sunat.Client)
+type Sender interface {
+	SendBill(ctx context.Context, credentials sunat.Credentials, archiveName string, archive []byte) (*sunat.Response, error)
+}
+
+// Attachments keeps the CDRs of submissions (implemented by
+// attachmentsrv.AttachmentService)
+type Attachments interface {
+	Upload(ctx context.Context, req *attachmentdto.UploadRequest) (*attachmentdto.AttachmentResponse, error)
+}
+
+// Submitter sends pending submissions to SUNAT and records the answers. A
+// submission is first sent when it is created; attempts that fail because
+// SUNAT or the network are unavailable are retried in a background
+// goroutine with exponential backoff until MaxAttempts.
+//
+// Every attempt sends the same zipped document, so an answer lost on the way
+// back does not get a second, differently signed document registered.
+type Submitter struct {
+	repo        postgres.EInvoiceRepository
+	sender      Sender
+	attachments Attachments
+	keystore    *xmlsig.Keystore
+	options     SubmitterOptions
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSubmitter creates a submitter; zero options take the defaults. The
+// keystore opens the organizations' SOL passwords.
+func NewSubmitter(repo postgres.EInvoiceRepository, sender Sender, attachments Attachments, keystore *xmlsig.Keystore, options SubmitterOptions) *Submitter {
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultSubmitPollInterval
+	}
+	if options.Lease <= 0 {
+		options.Lease = DefaultSubmitLease
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultSubmitBatchSize
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = DefaultInitialBackoff
+	}
+	if options.MaxBackoff < options.InitialBackoff {
+		options.MaxBackoff = max(DefaultMaxBackoff, options.InitialBackoff)
+	}
+
+	return &Submitter{
+		repo:        repo,
+		sender:      sender,
+		attachments: attachments,
+		keystore:    keystore,
+		options:     options,
+	}
+}
+
+// Start polls for submissions due for another attempt until Stop is called
+func (s *Submitter) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.options.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.poll(ctx, time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for the current poll to finish
+func (s *Submitter) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// poll claims a batch of due submissions and sends them
+func (s *Submitter) poll(ctx context.Context, now time.Time) {
+	submissions, err := s.repo.ClaimDueSubmissions(ctx, now, s.options.Lease, s.options.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("einvoice submitter: %v", err)
+		}
+		return
+	}
+
+	for i := range submissions {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.attempt(ctx, &submissions[i]); err != nil {
+			log.Printf("einvoice submission %s: %v", submissions[i].ID, err)
+		}
+	}
+}
+
+// attempt sends a claimed submission, records the outcome and releases the
+// lease. SUNAT's answer ends the submission: a CDR gives accepted, observed
+// or rejected and is kept as an invoice attachment, and a rejection fault
+// gives rejected. Transient failures schedule another attempt until the
+// attempts run out; other failures end it as failed.
+func (s *Submitter) attempt(ctx context.Context, submission *models.Submission) error {
+	submission.Attempts++
+	submission.LastError = nil
+
+	response, err := s.send(ctx, submission)
+	now := time.Now()
+
+	var fault *sunat.Fault
+	switch {
+	case err == nil:
+		s.answer(ctx, submission, response)
+		submission.Status = models.SubmissionStatus(response.CDR.Status())
+	case errors.As(err, &fault) && fault.Rejected():
+		submission.Status = models.SubmissionRejected
+		submission.ResponseCode = &fault.Code
+		submission.ResponseDescription = &fault.Message
+	case transient(err) && submission.Attempts < s.options.MaxAttempts:
+		next := now.Add(s.backoff(submission.Attempts))
+		submission.NextAttemptAt = &next
+		submission.LastError = errorText(err)
+	default:
+		submission.Status = models.SubmissionFailed
+		submission.LastError = errorText(err)
+		if fault != nil && fault.Code != "" {
+			submission.ResponseCode = &fault.Code
+			submission.ResponseDescription = &fault.Message
+		}
+	}
+	if submission.Status != models.SubmissionPending {
+		submission.NextAttemptAt = nil
+		submission.CompletedAt = &now
+	}
+
+	return s.repo.RecordAttempt(context.WithoutCancel(ctx), submission)
+}
+
+// send opens the organization's SOL credentials and sends the document
+func (s *Submitter) send(ctx context.Context, submission *models.Submission) (*sunat.Response, error) {
+	if s.keystore == nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSigningNotConfigured)
+	}
+
+	stored, err := s.repo.GetSunatCredentials(ctx, submission.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	password, err := s.keystore.OpenSecret(submission.OrganizationID.String(), stored.SealedPassword)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("organization_id", submission.OrganizationID.String()).
+			WithDetail("reason", "SOL password does not open with the master key").
+			WithCause(err)
+	}
+
+	credentials := sunat.Credentials{Username: stored.Username, Password: string(password)}
+	return s.sender.SendBill(ctx, credentials, submission.FileName, submission.Archive)
+}
+
+// answer copies the CDR onto the submission and attaches it to the
+// invoice. The answer stands even if the CDR cannot be attached; the
+// failure is kept as the submission's last error.
+func (s *Submitter) answer(ctx context.Context, submission *models.Submission, response *sunat.Response) {
+	cdr := response.CDR
+	submission.ResponseCode = &cdr.ResponseCode
+	submission.ResponseDescription = &cdr.Description
+	submission.Observations = cdr.Notes
+
+	attachment, err := s.attachments.Upload(context.WithoutCancel(ctx), &attachmentdto.UploadRequest{
+		InvoiceID:   submission.InvoiceID,
+		FileName:    response.ArchiveName,
+		ContentType: attachmentmodels.ContentTypeZIP,
+		Data:        response.Archive,
+		UploadedBy:  submission.SubmittedBy,
+	})
+	if err != nil {
+		log.Printf("einvoice submission %s: attaching CDR: %v", submission.ID, err)
+		submission.LastError = errorText(err)
+		return
+	}
+	submission.CDRAttachmentID = &attachment.ID
+}
+
+// backoff is the wait before the attempt after the given number of attempts
+func (s *Submitter) backoff(attempts int) time.Duration {
+	wait := s.options.InitialBackoff
+	for i := 1; i < attempts && wait < s.options.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, s.options.MaxBackoff)
+}
+
+// transient reports whether an attempt may succeed if made again, as
+// opposed to the submission being unable to go through
+func transient(err error) bool {
+	var e *errx.Error
+	if errors.As(err, &e) {
+		switch e.Type {
+		case errx.TypeInternal, errx.TypeSystem, errx.TypeTimeout, errx.TypeUnavailable, errx.TypeExternal:
+			return true
+		}
+		return false
+	}
+	return sunat.Transient(err)
+}
+
+func errorText(err error) *string {
+	text := err.Error()
+	return &text
+}
@@ -0,0 +1,300 @@
+package einvoicesrv
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	attachmentdto "github.com/Abraxas-365/fuckturamelo/attachments/dto"
+	attachmentmodels "github.com/Abraxas-365/fuckturamelo/attachments/models"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/einvoice/repository"
+	"github.com/Abraxas-365/fuckturamelo/sunat"
+	"github.com/Abraxas-365/fuckturamelo/xmlsig"
+)
+
+// submitterRepo keeps the SOL credentials and the recorded attempts; the
+// other methods are not used by the submitter
+type submitterRepo struct {
+	postgres.EInvoiceRepository
+
+	credentials *models.SunatCredentials
+	recorded    []models.Submission
+}
+
+func (r *submitterRepo) GetSunatCredentials(ctx context.Context, orgID uuid.UUID) (*models.SunatCredentials, error) {
+	return r.credentials, nil
+}
+
+func (r *submitterRepo) RecordAttempt(ctx context.Context, submission *models.Submission) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	r.recorded = append(r.recorded, *submission)
+	return nil
+}
+
+// cdrAttachments keeps the uploaded CDRs
+type cdrAttachments struct {
+	uploads []*attachmentdto.UploadRequest
+}
+
+func (a *cdrAttachments) Upload(ctx context.Context, req *attachmentdto.UploadRequest) (*attachmentdto.AttachmentResponse, error) {
+	a.uploads = append(a.uploads, req)
+	return &attachmentdto.AttachmentResponse{Attachment: &attachmentmodels.Attachment{
+		ID:        uuid.New(),
+		InvoiceID: req.InvoiceID,
+		FileName:  req.FileName,
+	}}, nil
+}
+
+var testSubmitterOptions = SubmitterOptions{
+	MaxAttempts:    3,
+	InitialBackoff: time.Minute,
+	MaxBackoff:     3 * time.Minute,
+}
+
+// newTestSubmitter returns a submitter sending to a billService stub, and a
+// submission claimed for it
+func newTestSubmitter(t *testing.T, respond http.HandlerFunc) (*Submitter, *submitterRepo, *cdrAttachments, *models.Submission) {
+	t.Helper()
+
+	server := httptest.NewServer(respond)
+	t.Cleanup(server.Close)
+
+	keystore, err := xmlsig.NewKeystore(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgID := uuid.New()
+	sealed, err := keystore.SealSecret(orgID.String(), []byte("moddatos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archiveName, archive, err := sunat.Pack("20123456789-01-F001-123.xml", []byte("<Invoice/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &submitterRepo{credentials: &models.SunatCredentials{
+		OrganizationID: orgID,
+		Username:       "20123456789MODDATOS",
+		SealedPassword: sealed,
+	}}
+	attachments := &cdrAttachments{}
+	submitter := NewSubmitter(repo, sunat.NewClient(sunat.Config{Endpoint: server.URL}), attachments, keystore, testSubmitterOptions)
+	submission := &models.Submission{
+		ID:             uuid.New(),
+		InvoiceID:      uuid.New(),
+		OrganizationID: orgID,
+		FileName:       archiveName,
+		Archive:        archive,
+		Status:         models.SubmissionPending,
+	}
+	return submitter, repo, attachments, submission
+}
+
+// answerCDR answers sendBill with a zipped CDR
+func answerCDR(t *testing.T, responseCode string, notes ...string) http.HandlerFunc {
+	var cdr strings.Builder
+	cdr.WriteString(`<ar:ApplicationResponse xmlns:ar="urn:oasis:names:specification:ubl:schema:xsd:ApplicationResponse-2" xmlns:cac="urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2" xmlns:cbc="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"><cbc:ID>1675260000000</cbc:ID>`)
+	for _, note := range notes {
+		fmt.Fprintf(&cdr, "<cbc:Note>%s</cbc:Note>", note)
+	}
+	fmt.Fprintf(&cdr, `<cac:DocumentResponse><cac:Response><cbc:ReferenceID>F001-123</cbc:ReferenceID><cbc:ResponseCode>%s</cbc:ResponseCode><cbc:Description>Respuesta %s</cbc:Description></cac:Response></cac:DocumentResponse></ar:ApplicationResponse>`, responseCode, responseCode)
+
+	archive, err := sunat.Zip("R-20123456789-01-F001-123.xml", []byte(cdr.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/"><soap-env:Body><br:sendBillResponse xmlns:br="http://service.sunat.gob.pe"><applicationResponse>%s</applicationResponse></br:sendBillResponse></soap-env:Body></soap-env:Envelope>`,
+			base64.StdEncoding.EncodeToString(archive))
+	}
+}
+
+// answerFault answers sendBill with a SOAP fault
+func answerFault(faultCode, faultString string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/"><soap-env:Body><soap-env:Fault><faultcode>%s</faultcode><faultstring>%s</faultstring></soap-env:Fault></soap-env:Body></soap-env:Envelope>`, faultCode, faultString)
+	}
+}
+
+func TestAttemptAnswered(t *testing.T) {
+	tests := []struct {
+		name         string
+		respond      http.HandlerFunc
+		wantStatus   models.SubmissionStatus
+		wantCode     string
+		wantCDR      bool
+		wantObserved int
+	}{
+		{"accepted", answerCDR(t, "0"), models.SubmissionAccepted, "0", true, 0},
+		{"observed", answerCDR(t, "0", "4252 - El dato ingresado como atributo @listName es incorrecto."), models.SubmissionObserved, "0", true, 1},
+		{"rejected in the CDR", answerCDR(t, "2800"), models.SubmissionRejected, "2800", true, 0},
+		{"rejection fault", answerFault("soap-env:Client.2335", "El documento electronico ingresado ha sido alterado"), models.SubmissionRejected, "2335", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submitter, repo, attachments, submission := newTestSubmitter(t, tt.respond)
+
+			if err := submitter.attempt(context.Background(), submission); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.recorded) != 1 {
+				t.Fatalf("recorded %d attempts; want 1", len(repo.recorded))
+			}
+			recorded := repo.recorded[0]
+			if recorded.Status != tt.wantStatus {
+				t.Errorf("status = %s; want %s", recorded.Status, tt.wantStatus)
+			}
+			if recorded.Attempts != 1 {
+				t.Errorf("attempts = %d; want 1", recorded.Attempts)
+			}
+			if recorded.ResponseCode == nil || *recorded.ResponseCode != tt.wantCode {
+				t.Errorf("response code = %v; want %s", recorded.ResponseCode, tt.wantCode)
+			}
+			if len(recorded.Observations) != tt.wantObserved {
+				t.Errorf("got %d observations; want %d", len(recorded.Observations), tt.wantObserved)
+			}
+			if recorded.CompletedAt == nil || recorded.NextAttemptAt != nil {
+				t.Error("answered submission is not completed")
+			}
+
+			if tt.wantCDR {
+				if len(attachments.uploads) != 1 || recorded.CDRAttachmentID == nil {
+					t.Fatal("CDR not attached to the invoice")
+				}
+				upload := attachments.uploads[0]
+				if upload.InvoiceID != submission.InvoiceID || upload.FileName != "R-"+submission.FileName {
+					t.Errorf("CDR attached as %s to %s", upload.FileName, upload.InvoiceID)
+				}
+			} else if len(attachments.uploads) != 0 {
+				t.Error("fault attached as a CDR")
+			}
+		})
+	}
+}
+
+func TestAttemptRetries(t *testing.T) {
+	submitter, repo, _, submission := newTestSubmitter(t, answerFault("soap-env:Server", "0109"))
+
+	// Each transient failure doubles the wait, up to MaxBackoff; the last
+	// attempt ends the submission
+	wantWaits := []time.Duration{time.Minute, 2 * time.Minute}
+	for i, wait := range wantWaits {
+		before := time.Now()
+		if err := submitter.attempt(context.Background(), submission); err != nil {
+			t.Fatal(err)
+		}
+		after := time.Now()
+
+		recorded := repo.recorded[i]
+		if recorded.Status != models.SubmissionPending {
+			t.Fatalf("attempt %d: status = %s; want pending", i+1, recorded.Status)
+		}
+		if recorded.NextAttemptAt == nil || recorded.NextAttemptAt.Before(before.Add(wait)) || recorded.NextAttemptAt.After(after.Add(wait)) {
+			t.Errorf("attempt %d: next attempt at %v; want in %s", i+1, recorded.NextAttemptAt, wait)
+		}
+		if recorded.LastError == nil || !strings.Contains(*recorded.LastError, "0109") {
+			t.Errorf("attempt %d: last error = %v; want the fault", i+1, recorded.LastError)
+		}
+		if recorded.CompletedAt != nil {
+			t.Errorf("attempt %d: pending submission completed", i+1)
+		}
+	}
+
+	if err := submitter.attempt(context.Background(), submission); err != nil {
+		t.Fatal(err)
+	}
+	last := repo.recorded[len(repo.recorded)-1]
+	if last.Attempts != testSubmitterOptions.MaxAttempts || last.Status != models.SubmissionFailed {
+		t.Errorf("attempt %d: status = %s; want failed after %d attempts", last.Attempts, last.Status, testSubmitterOptions.MaxAttempts)
+	}
+	if last.NextAttemptAt != nil || last.CompletedAt == nil {
+		t.Error("failed submission is scheduled again")
+	}
+	if last.ResponseCode == nil || *last.ResponseCode != "0109" {
+		t.Errorf("response code = %v; want the fault's 0109", last.ResponseCode)
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	// The stub holds the request until the attempt gives up; the server
+	// notices the client leaving once the body is read
+	submitter, repo, _, submission := newTestSubmitter(t, func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := submitter.attempt(ctx, submission); err != nil {
+		t.Fatalf("recording the attempt after the timeout: %v", err)
+	}
+
+	if len(repo.recorded) != 1 {
+		t.Fatalf("recorded %d attempts; want 1", len(repo.recorded))
+	}
+	recorded := repo.recorded[0]
+	if recorded.Status != models.SubmissionPending || recorded.NextAttemptAt == nil {
+		t.Errorf("status = %s, next attempt %v; want another attempt scheduled", recorded.Status, recorded.NextAttemptAt)
+	}
+}
+
+func TestAttemptFails(t *testing.T) {
+	// Wrong SOL credentials do not go away by sending again
+	submitter, repo, _, submission := newTestSubmitter(t, answerFault("soap-env:Client.0102", "Usuario o contrasena incorrectos"))
+
+	if err := submitter.attempt(context.Background(), submission); err != nil {
+		t.Fatal(err)
+	}
+	recorded := repo.recorded[0]
+	if recorded.Status != models.SubmissionFailed || recorded.Attempts != 1 {
+		t.Errorf("status = %s after %d attempts; want failed after the first", recorded.Status, recorded.Attempts)
+	}
+	if recorded.ResponseCode == nil || *recorded.ResponseCode != "0102" {
+		t.Errorf("response code = %v; want 0102", recorded.ResponseCode)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	submitter := NewSubmitter(nil, nil, nil, nil, SubmitterOptions{
+		InitialBackoff: time.Minute,
+		MaxBackoff:     6 * time.Minute,
+	})
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 6 * time.Minute},
+		{50, 6 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := submitter.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff after %d attempts = %s; want %s", tt.attempts, got, tt.want)
+		}
+	}
+
+	// A maximum below the initial wait falls back to the default
+	defaults := NewSubmitter(nil, nil, nil, nil, SubmitterOptions{InitialBackoff: time.Hour, MaxBackoff: time.Minute})
+	if defaults.options.MaxBackoff != DefaultMaxBackoff || defaults.options.MaxAttempts != DefaultMaxAttempts {
+		t.Errorf("options = %+v; want the default maximum backoff and attempts", defaults.options)
+	}
+}
@@ -0,0 +1,211 @@
+package einvoice
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// EInvoiceErrors is the error registry for electronic invoicing domain
+var EInvoiceErrors = errx.NewRegistry("EINVOICE")
+
+// Electronic invoicing error codes
+var (
+	// Tax profile errors
+	ErrTaxProfileNotFound = EInvoiceErrors.Register(
+		"TAX_PROFILE_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Organization has no tax profile",
+	)
+
+	ErrTaxProfileSaveFailed = EInvoiceErrors.Register(
+		"TAX_PROFILE_SAVE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save tax profile",
+	)
+
+	// Certificate errors
+	ErrCertificateNotFound = EInvoiceErrors.Register(
+		"CERTIFICATE_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Organization has no signing certificate",
+	)
+
+	ErrInvalidCertificate = EInvoiceErrors.Register(
+		"INVALID_CERTIFICATE",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Certificate cannot be used for signing",
+	)
+
+	ErrCertificateExpired = EInvoiceErrors.Register(
+		"CERTIFICATE_EXPIRED",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Signing certificate is not valid at this time",
+	)
+
+	ErrCertificateSaveFailed = EInvoiceErrors.Register(
+		"CERTIFICATE_SAVE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save signing certificate",
+	)
+
+	ErrSigningNotConfigured = EInvoiceErrors.Register(
+		"SIGNING_NOT_CONFIGURED",
+		errx.TypeUnavailable,
+		http.StatusServiceUnavailable,
+		"Signing is not configured: no certificate master key",
+	)
+
+	ErrSigningFailed = EInvoiceErrors.Register(
+		"SIGNING_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to sign electronic document",
+	)
+
+	// SUNAT credential errors
+	ErrSunatCredentialsNotFound = EInvoiceErrors.Register(
+		"SUNAT_CREDENTIALS_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Organization has no SUNAT credentials",
+	)
+
+	ErrSunatCredentialsSaveFailed = EInvoiceErrors.Register(
+		"SUNAT_CREDENTIALS_SAVE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save SUNAT credentials",
+	)
+
+	// Submission errors
+	ErrSubmissionExists = EInvoiceErrors.Register(
+		"SUBMISSION_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice is already being submitted or was accepted",
+	)
+
+	ErrSubmissionSaveFailed = EInvoiceErrors.Register(
+		"SUBMISSION_SAVE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save submission",
+	)
+
+	// Import errors
+	ErrUnsupportedDocument = EInvoiceErrors.Register(
+		"UNSUPPORTED_DOCUMENT",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Document is not a supported electronic invoice",
+	)
+
+	ErrSignatureInvalid = EInvoiceErrors.Register(
+		"SIGNATURE_INVALID",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Document signature is missing or does not verify",
+	)
+
+	ErrProviderRequired = EInvoiceErrors.Register(
+		"PROVIDER_REQUIRED",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"No provider of the organization has the issuer's tax ID",
+	)
+
+	ErrDocumentMismatch = EInvoiceErrors.Register(
+		"DOCUMENT_MISMATCH",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Document does not match the organization, provider or invoice type",
+	)
+
+	ErrImportNotFound = EInvoiceErrors.Register(
+		"IMPORT_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Import not found",
+	)
+
+	ErrImportExists = EInvoiceErrors.Register(
+		"IMPORT_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Document was already imported",
+	)
+
+	ErrImportSaveFailed = EInvoiceErrors.Register(
+		"IMPORT_SAVE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save import",
+	)
+
+	// Document errors
+	ErrDocumentNotExportable = EInvoiceErrors.Register(
+		"DOCUMENT_NOT_EXPORTABLE",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Invoice cannot be issued as an electronic document",
+	)
+
+	ErrDocumentBuildFailed = EInvoiceErrors.Register(
+		"DOCUMENT_BUILD_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to build electronic document",
+	)
+
+	// Query errors
+	ErrEInvoiceLoadFailed = EInvoiceErrors.Register(
+		"LOAD_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to load electronic invoicing data",
+	)
+
+	// Validation errors
+	ErrEInvoiceValidationFailed = EInvoiceErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Electronic invoicing validation failed",
+	)
+)
+
+// Helper functions for error checking
+func IsTaxProfileNotFound(err error) bool {
+	return errx.IsCode(err, ErrTaxProfileNotFound)
+}
+
+func IsCertificateNotFound(err error) bool {
+	return errx.IsCode(err, ErrCertificateNotFound)
+}
+
+func IsSunatCredentialsNotFound(err error) bool {
+	return errx.IsCode(err, ErrSunatCredentialsNotFound)
+}
+
+func IsSubmissionExists(err error) bool {
+	return errx.IsCode(err, ErrSubmissionExists)
+}
+
+func IsImportNotFound(err error) bool {
+	return errx.IsCode(err, ErrImportNotFound)
+}
+
+func IsImportExists(err error) bool {
+	return errx.IsCode(err, ErrImportExists)
+}
+
+func IsDocumentNotExportable(err error) bool {
+	return errx.IsCode(err, ErrDocumentNotExportable)
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Certificate is an organization's signing certificate. The sealed bundle
+// never leaves the service.
+type Certificate struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	SealedPKCS12   []byte    `db:"sealed_pkcs12" json:"-"`
+
+	// Certificate details
+	Subject           string    `db:"subject" json:"subject"`
+	Issuer            string    `db:"issuer" json:"issuer"`
+	SerialNumber      string    `db:"serial_number" json:"serial_number"`
+	FingerprintSHA256 string    `db:"fingerprint_sha256" json:"fingerprint_sha256"`
+	NotBefore         time.Time `db:"not_before" json:"not_before"`
+	NotAfter          time.Time `db:"not_after" json:"not_after"`
+
+	UploadedBy *uuid.UUID `db:"uploaded_by" json:"uploaded_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the Certificate model
+func (c Certificate) TableName() string {
+	return "organization_certificates"
+}
+
+// ValidAt reports whether the certificate is valid at t
+func (c *Certificate) ValidAt(t time.Time) bool {
+	return !t.Before(c.NotBefore) && !t.After(c.NotAfter)
+}
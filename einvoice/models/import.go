@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ImportFormat is the format of an imported document
+type ImportFormat string
+
+// Import formats
+const (
+	ImportFormatUBL ImportFormat = "ubl"
+)
+
+// Import is an electronic document issued by a provider and imported as an
+// invoice of the organization
+type Import struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organization_id"`
+	InvoiceID      uuid.UUID  `db:"invoice_id" json:"invoice_id"`
+	ProviderID     *uuid.UUID `db:"provider_id" json:"provider_id"`
+	AttachmentID   *uuid.UUID `db:"attachment_id" json:"attachment_id,omitempty"`
+
+	// Document imported
+	Format           ImportFormat `db:"format" json:"format"`
+	IssuerTaxID      string       `db:"issuer_tax_id" json:"issuer_tax_id"`
+	DocumentTypeCode string       `db:"document_type_code" json:"document_type_code"`
+	DocumentID       string       `db:"document_id" json:"document_id"`
+	IssueDate        time.Time    `db:"issue_date" json:"issue_date"`
+
+	// Signature
+	SignerSubject      string `db:"signer_subject" json:"signer_subject"`
+	SignerSerialNumber string `db:"signer_serial_number" json:"signer_serial_number"`
+	SignatureAlgorithm string `db:"signature_algorithm" json:"signature_algorithm"`
+
+	// SignerTrusted tells whether the signing certificate chained to a
+	// trusted certification authority when the document was imported
+	SignerTrusted bool `db:"signer_trusted" json:"signer_trusted"`
+
+	Warnings pq.StringArray `db:"warnings" json:"warnings"`
+
+	ImportedBy *uuid.UUID `db:"imported_by" json:"imported_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the table name for the Import model
+func (i Import) TableName() string {
+	return "einvoice_imports"
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SubmissionStatus is where a submission to SUNAT is
+type SubmissionStatus string
+
+// Submission statuses. Accepted, observed and rejected come from SUNAT's
+// CDR or faults; failed submissions ran out of attempts or could not be
+// sent at all. The invoice's tax_authority_status follows its latest
+// submission.
+const (
+	SubmissionPending  SubmissionStatus = "pending"
+	SubmissionAccepted SubmissionStatus = "accepted"
+	SubmissionObserved SubmissionStatus = "observed"
+	SubmissionRejected SubmissionStatus = "rejected"
+	SubmissionFailed   SubmissionStatus = "failed"
+)
+
+// Submission is an electronic document sent to SUNAT. The zipped document
+// never leaves the service.
+type Submission struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	InvoiceID      uuid.UUID `db:"invoice_id" json:"invoice_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+
+	// Document sent
+	FileName string `db:"file_name" json:"file_name"`
+	Archive  []byte `db:"archive" json:"-"`
+
+	// Progress
+	Status        SubmissionStatus `db:"status" json:"status"`
+	Attempts      int              `db:"attempts" json:"attempts"`
+	NextAttemptAt *time.Time       `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LockedUntil   *time.Time       `db:"locked_until" json:"-"`
+	LastError     *string          `db:"last_error" json:"last_error,omitempty"`
+
+	// SUNAT's answer
+	ResponseCode        *string        `db:"response_code" json:"response_code,omitempty"`
+	ResponseDescription *string        `db:"response_description" json:"response_description,omitempty"`
+	Observations        pq.StringArray `db:"observations" json:"observations"`
+	CDRAttachmentID     *uuid.UUID     `db:"cdr_attachment_id" json:"cdr_attachment_id,omitempty"`
+
+	SubmittedBy *uuid.UUID `db:"submitted_by" json:"submitted_by,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// TableName returns the table name for the Submission model
+func (s Submission) TableName() string {
+	return "einvoice_submissions"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SunatCredentials are the SOL credentials an organization submits
+// electronic documents with. The sealed password never leaves the service.
+type SunatCredentials struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+
+	// Username is the RUC followed by the SOL user
+	Username       string `db:"username" json:"username"`
+	SealedPassword []byte `db:"sealed_password" json:"-"`
+
+	UpdatedBy *uuid.UUID `db:"updated_by" json:"updated_by,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the SunatCredentials model
+func (c SunatCredentials) TableName() string {
+	return "organization_sunat_credentials"
+}
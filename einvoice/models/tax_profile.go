@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+)
+
+// TaxProfile is the tax identity an organization issues electronic invoices
+// under
+type TaxProfile struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+
+	// Identity
+	TaxID     string  `db:"tax_id" json:"tax_id"`
+	TaxIDType string  `db:"tax_id_type" json:"tax_id_type"`
+	LegalName string  `db:"legal_name" json:"legal_name"`
+	TradeName *string `db:"trade_name" json:"trade_name,omitempty"`
+
+	// Registered address of the issuing establishment
+	AddressLine       *string `db:"address_line" json:"address_line,omitempty"`
+	Ubigeo            *string `db:"ubigeo" json:"ubigeo,omitempty"`
+	District          *string `db:"district" json:"district,omitempty"`
+	Province          *string `db:"province" json:"province,omitempty"`
+	Department        *string `db:"department" json:"department,omitempty"`
+	CountryCode       string  `db:"country_code" json:"country_code"`
+	EstablishmentCode string  `db:"establishment_code" json:"establishment_code"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the TaxProfile model
+func (p TaxProfile) TableName() string {
+	return "organization_tax_profiles"
+}
+
+// Party returns the profile as the issuing party of a document
+func (p *TaxProfile) Party() ubl.Party {
+	return ubl.Party{
+		IdentityType:     p.TaxIDType,
+		TaxID:            p.TaxID,
+		RegistrationName: p.LegalName,
+		TradeName:        value(p.TradeName),
+		Address: &ubl.Address{
+			Ubigeo:            value(p.Ubigeo),
+			EstablishmentCode: p.EstablishmentCode,
+			Line:              value(p.AddressLine),
+			District:          value(p.District),
+			Province:          value(p.Province),
+			Department:        value(p.Department),
+			CountryCode:       p.CountryCode,
+		},
+	}
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
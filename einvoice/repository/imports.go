@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+)
+
+// CreateImport records an imported document
+func (r *einvoiceRepository) CreateImport(ctx context.Context, imp *models.Import) (*models.Import, error) {
+	var created models.Import
+	err := r.db.GetContext(ctx, &created, `
+		INSERT INTO einvoice_imports
+			(id, organization_id, invoice_id, provider_id, attachment_id, format, issuer_tax_id,
+			 document_type_code, document_id, issue_date, signer_subject, signer_serial_number,
+			 signature_algorithm, signer_trusted, warnings, imported_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING *`,
+		imp.ID, imp.OrganizationID, imp.InvoiceID, imp.ProviderID, imp.AttachmentID, imp.Format,
+		imp.IssuerTaxID, imp.DocumentTypeCode, imp.DocumentID, imp.IssueDate, imp.SignerSubject,
+		imp.SignerSerialNumber, imp.SignatureAlgorithm, imp.SignerTrusted, imp.Warnings, imp.ImportedBy, imp.CreatedAt)
+	if err != nil {
+		return nil, importError(err, imp)
+	}
+
+	return &created, nil
+}
+
+// GetImportByInvoice retrieves the import an invoice was created by
+func (r *einvoiceRepository) GetImportByInvoice(ctx context.Context, invoiceID uuid.UUID) (*models.Import, error) {
+	var imp models.Import
+	err := r.db.GetContext(ctx, &imp,
+		`SELECT * FROM einvoice_imports WHERE invoice_id = $1`, invoiceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrImportNotFound).
+				WithDetail("invoice_id", invoiceID.String())
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceLoadFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return &imp, nil
+}
+
+// GetImportByDocument retrieves the import of a document by its issuer,
+// type and number
+func (r *einvoiceRepository) GetImportByDocument(ctx context.Context, orgID uuid.UUID, issuerTaxID, documentTypeCode, documentID string) (*models.Import, error) {
+	var imp models.Import
+	err := r.db.GetContext(ctx, &imp, `
+		SELECT * FROM einvoice_imports
+		WHERE organization_id = $1 AND issuer_tax_id = $2
+			AND document_type_code = $3 AND document_id = $4`,
+		orgID, issuerTaxID, documentTypeCode, documentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrImportNotFound).
+				WithDetail("issuer_tax_id", issuerTaxID).
+				WithDetail("document_type_code", documentTypeCode).
+				WithDetail("document_id", documentID)
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceLoadFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return &imp, nil
+}
+
+// importError maps constraint violations of import writes
+func importError(err error, imp *models.Import) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "einvoice_imports_document_unique"):
+		return einvoice.EInvoiceErrors.New(einvoice.ErrImportExists).
+			WithDetail("issuer_tax_id", imp.IssuerTaxID).
+			WithDetail("document_type_code", imp.DocumentTypeCode).
+			WithDetail("document_id", imp.DocumentID).
+			WithCause(err)
+	case strings.Contains(msg, "violates foreign key constraint"):
+		return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("invoice_id", imp.InvoiceID.String()).
+			WithDetail("reason", "invoice, provider or organization does not exist").
+			WithCause(err)
+	}
+	return einvoice.EInvoiceErrors.New(einvoice.ErrImportSaveFailed).
+		WithDetail("document_id", imp.DocumentID).
+		WithCause(err)
+}
@@ -0,0 +1,243 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+)
+
+// einvoiceRepository implements EInvoiceRepository using sqlx
+type einvoiceRepository struct {
+	db *sqlx.DB
+}
+
+// NewEInvoiceRepository creates a new electronic invoicing repository
+func NewEInvoiceRepository(db *sqlx.DB) EInvoiceRepository {
+	return &einvoiceRepository{
+		db: db,
+	}
+}
+
+// GetTaxProfile retrieves the tax profile of an organization
+func (r *einvoiceRepository) GetTaxProfile(ctx context.Context, orgID uuid.UUID) (*models.TaxProfile, error) {
+	var profile models.TaxProfile
+	err := r.db.GetContext(ctx, &profile,
+		`SELECT * FROM organization_tax_profiles WHERE organization_id = $1`, orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrTaxProfileNotFound).
+				WithDetail("organization_id", orgID.String())
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceLoadFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return &profile, nil
+}
+
+// SaveTaxProfile inserts the tax profile or replaces the existing one
+func (r *einvoiceRepository) SaveTaxProfile(ctx context.Context, profile *models.TaxProfile) (*models.TaxProfile, error) {
+	var saved models.TaxProfile
+	err := r.db.GetContext(ctx, &saved, `
+		INSERT INTO organization_tax_profiles
+			(organization_id, tax_id, tax_id_type, legal_name, trade_name, address_line, ubigeo,
+			 district, province, department, country_code, establishment_code)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			tax_id = EXCLUDED.tax_id,
+			tax_id_type = EXCLUDED.tax_id_type,
+			legal_name = EXCLUDED.legal_name,
+			trade_name = EXCLUDED.trade_name,
+			address_line = EXCLUDED.address_line,
+			ubigeo = EXCLUDED.ubigeo,
+			district = EXCLUDED.district,
+			province = EXCLUDED.province,
+			department = EXCLUDED.department,
+			country_code = EXCLUDED.country_code,
+			establishment_code = EXCLUDED.establishment_code
+		RETURNING *`,
+		profile.OrganizationID, profile.TaxID, profile.TaxIDType, profile.LegalName, profile.TradeName,
+		profile.AddressLine, profile.Ubigeo, profile.District, profile.Province, profile.Department,
+		profile.CountryCode, profile.EstablishmentCode)
+	if err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("organization_id", profile.OrganizationID.String()).
+				WithDetail("reason", "organization does not exist").
+				WithCause(err)
+		}
+		if strings.Contains(err.Error(), "violates check constraint") {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("organization_id", profile.OrganizationID.String()).
+				WithCause(err)
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrTaxProfileSaveFailed).
+			WithDetail("organization_id", profile.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	return &saved, nil
+}
+
+// GetCertificate retrieves the signing certificate of an organization
+func (r *einvoiceRepository) GetCertificate(ctx context.Context, orgID uuid.UUID) (*models.Certificate, error) {
+	var certificate models.Certificate
+	err := r.db.GetContext(ctx, &certificate,
+		`SELECT * FROM organization_certificates WHERE organization_id = $1`, orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrCertificateNotFound).
+				WithDetail("organization_id", orgID.String())
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceLoadFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return &certificate, nil
+}
+
+// SaveCertificate inserts the signing certificate or replaces the existing
+// one
+func (r *einvoiceRepository) SaveCertificate(ctx context.Context, certificate *models.Certificate) (*models.Certificate, error) {
+	var saved models.Certificate
+	err := r.db.GetContext(ctx, &saved, `
+		INSERT INTO organization_certificates
+			(organization_id, sealed_pkcs12, subject, issuer, serial_number, fingerprint_sha256,
+			 not_before, not_after, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			sealed_pkcs12 = EXCLUDED.sealed_pkcs12,
+			subject = EXCLUDED.subject,
+			issuer = EXCLUDED.issuer,
+			serial_number = EXCLUDED.serial_number,
+			fingerprint_sha256 = EXCLUDED.fingerprint_sha256,
+			not_before = EXCLUDED.not_before,
+			not_after = EXCLUDED.not_after,
+			uploaded_by = EXCLUDED.uploaded_by
+		RETURNING *`,
+		certificate.OrganizationID, certificate.SealedPKCS12, certificate.Subject, certificate.Issuer,
+		certificate.SerialNumber, certificate.FingerprintSHA256, certificate.NotBefore, certificate.NotAfter,
+		certificate.UploadedBy)
+	if err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("organization_id", certificate.OrganizationID.String()).
+				WithDetail("reason", "organization does not exist").
+				WithCause(err)
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrCertificateSaveFailed).
+			WithDetail("organization_id", certificate.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	return &saved, nil
+}
+
+// DeleteCertificate removes the signing certificate of an organization
+func (r *einvoiceRepository) DeleteCertificate(ctx context.Context, orgID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM organization_certificates WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrCertificateSaveFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrCertificateSaveFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+	if rows == 0 {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrCertificateNotFound).
+			WithDetail("organization_id", orgID.String())
+	}
+
+	return nil
+}
+
+// GetSunatCredentials retrieves the SOL credentials of an organization
+func (r *einvoiceRepository) GetSunatCredentials(ctx context.Context, orgID uuid.UUID) (*models.SunatCredentials, error) {
+	var credentials models.SunatCredentials
+	err := r.db.GetContext(ctx, &credentials,
+		`SELECT * FROM organization_sunat_credentials WHERE organization_id = $1`, orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSunatCredentialsNotFound).
+				WithDetail("organization_id", orgID.String())
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceLoadFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return &credentials, nil
+}
+
+// SaveSunatCredentials inserts the SOL credentials or replaces the existing
+// ones
+func (r *einvoiceRepository) SaveSunatCredentials(ctx context.Context, credentials *models.SunatCredentials) (*models.SunatCredentials, error) {
+	var saved models.SunatCredentials
+	err := r.db.GetContext(ctx, &saved, `
+		INSERT INTO organization_sunat_credentials (organization_id, username, sealed_password, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			username = EXCLUDED.username,
+			sealed_password = EXCLUDED.sealed_password,
+			updated_by = EXCLUDED.updated_by
+		RETURNING *`,
+		credentials.OrganizationID, credentials.Username, credentials.SealedPassword, credentials.UpdatedBy)
+	if err != nil {
+		switch msg := err.Error(); {
+		case strings.Contains(msg, "violates foreign key constraint"):
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("organization_id", credentials.OrganizationID.String()).
+				WithDetail("reason", "organization does not exist").
+				WithCause(err)
+		case strings.Contains(msg, "organization_sunat_credentials_username_format"):
+			return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+				WithDetail("field", "username").
+				WithDetail("reason", "must be the RUC followed by the SOL user").
+				WithCause(err)
+		}
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrSunatCredentialsSaveFailed).
+			WithDetail("organization_id", credentials.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	return &saved, nil
+}
+
+// DeleteSunatCredentials removes the SOL credentials of an organization
+func (r *einvoiceRepository) DeleteSunatCredentials(ctx context.Context, orgID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM organization_sunat_credentials WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrSunatCredentialsSaveFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrSunatCredentialsSaveFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+	if rows == 0 {
+		return einvoice.EInvoiceErrors.New(einvoice.ErrSunatCredentialsNotFound).
+			WithDetail("organization_id", orgID.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+)
+
+// EInvoiceRepository defines the interface for electronic invoicing
+// repository operations
+type EInvoiceRepository interface {
+	GetTaxProfile(ctx context.Context, orgID uuid.UUID) (*models.TaxProfile, error)
+
+	// SaveTaxProfile creates or replaces the tax profile of the organization
+	SaveTaxProfile(ctx context.Context, profile *models.TaxProfile) (*models.TaxProfile, error)
+
+	GetCertificate(ctx context.Context, orgID uuid.UUID) (*models.Certificate, error)
+
+	// SaveCertificate creates or replaces the signing certificate of the
+	// organization
+	SaveCertificate(ctx context.Context, certificate *models.Certificate) (*models.Certificate, error)
+	DeleteCertificate(ctx context.Context, orgID uuid.UUID) error
+
+	GetSunatCredentials(ctx context.Context, orgID uuid.UUID) (*models.SunatCredentials, error)
+
+	// SaveSunatCredentials creates or replaces the SOL credentials of the
+	// organization
+	SaveSunatCredentials(ctx context.Context, credentials *models.SunatCredentials) (*models.SunatCredentials, error)
+	DeleteSunatCredentials(ctx context.Context, orgID uuid.UUID) error
+
+	// Submissions to SUNAT; writes also set the invoice's
+	// tax_authority_status
+	CreateSubmission(ctx context.Context, submission *models.Submission) (*models.Submission, error)
+	ListSubmissions(ctx context.Context, invoiceID uuid.UUID) ([]models.Submission, error)
+	ClaimDueSubmissions(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Submission, error)
+	RecordAttempt(ctx context.Context, submission *models.Submission) error
+
+	// Documents received from providers
+	CreateImport(ctx context.Context, imp *models.Import) (*models.Import, error)
+	GetImportByInvoice(ctx context.Context, invoiceID uuid.UUID) (*models.Import, error)
+	GetImportByDocument(ctx context.Context, orgID uuid.UUID, issuerTaxID, documentTypeCode, documentID string) (*models.Import, error)
+}
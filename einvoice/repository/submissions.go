@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/models"
+)
+
+// CreateSubmission records a new submission and marks its invoice pending
+func (r *einvoiceRepository) CreateSubmission(ctx context.Context, submission *models.Submission) (*models.Submission, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, submissionError(err, submission)
+	}
+	defer tx.Rollback()
+
+	var created models.Submission
+	err = tx.GetContext(ctx, &created, `
+		INSERT INTO einvoice_submissions
+			(id, invoice_id, organization_id, file_name, archive, status, attempts,
+			 next_attempt_at, locked_until, submitted_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		RETURNING *`,
+		submission.ID, submission.InvoiceID, submission.OrganizationID, submission.FileName,
+		submission.Archive, submission.Status, submission.Attempts, submission.NextAttemptAt,
+		submission.LockedUntil, submission.SubmittedBy, submission.CreatedAt)
+	if err != nil {
+		return nil, submissionError(err, submission)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE invoices SET tax_authority_status = $2 WHERE id = $1`,
+		created.InvoiceID, created.Status); err != nil {
+		return nil, submissionError(err, submission)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, submissionError(err, submission)
+	}
+
+	return &created, nil
+}
+
+// ListSubmissions returns the submissions of an invoice, oldest first
+func (r *einvoiceRepository) ListSubmissions(ctx context.Context, invoiceID uuid.UUID) ([]models.Submission, error) {
+	submissions := []models.Submission{}
+	err := r.db.SelectContext(ctx, &submissions, `
+		SELECT * FROM einvoice_submissions
+		WHERE invoice_id = $1
+		ORDER BY created_at, id`, invoiceID)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceLoadFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return submissions, nil
+}
+
+// ClaimDueSubmissions leases up to limit pending submissions whose next
+// attempt is due, so concurrent submitters never send the same document at
+// once. Leases of crashed submitters expire after the lease duration.
+func (r *einvoiceRepository) ClaimDueSubmissions(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Submission, error) {
+	submissions := []models.Submission{}
+	err := r.db.SelectContext(ctx, &submissions, `
+		UPDATE einvoice_submissions
+		SET locked_until = $2
+		WHERE id IN (
+			SELECT id FROM einvoice_submissions
+			WHERE status = 'pending' AND next_attempt_at <= $1
+				AND (locked_until IS NULL OR locked_until <= $1)
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceLoadFailed).
+			WithDetail("reason", "claim_due_submissions").
+			WithCause(err)
+	}
+
+	return submissions, nil
+}
+
+// RecordAttempt stores the outcome of an attempt, releases the lease and
+// sets the invoice's tax authority status to the submission's status
+func (r *einvoiceRepository) RecordAttempt(ctx context.Context, submission *models.Submission) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return submissionError(err, submission)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE einvoice_submissions SET
+			status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			locked_until = NULL,
+			last_error = $5,
+			response_code = $6,
+			response_description = $7,
+			observations = $8,
+			cdr_attachment_id = $9,
+			completed_at = $10
+		WHERE id = $1`,
+		submission.ID, submission.Status, submission.Attempts, submission.NextAttemptAt,
+		submission.LastError, submission.ResponseCode, submission.ResponseDescription,
+		submission.Observations, submission.CDRAttachmentID, submission.CompletedAt)
+	if err != nil {
+		return submissionError(err, submission)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE invoices SET tax_authority_status = $2 WHERE id = $1`,
+		submission.InvoiceID, submission.Status); err != nil {
+		return submissionError(err, submission)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return submissionError(err, submission)
+	}
+
+	return nil
+}
+
+// submissionError maps constraint violations of submission writes
+func submissionError(err error, submission *models.Submission) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "idx_einvoice_submissions_open"):
+		return einvoice.EInvoiceErrors.New(einvoice.ErrSubmissionExists).
+			WithDetail("invoice_id", submission.InvoiceID.String()).
+			WithCause(err)
+	case strings.Contains(msg, "violates foreign key constraint"):
+		return einvoice.EInvoiceErrors.New(einvoice.ErrEInvoiceValidationFailed).
+			WithDetail("invoice_id", submission.InvoiceID.String()).
+			WithDetail("reason", "invoice or organization does not exist").
+			WithCause(err)
+	}
+	return einvoice.EInvoiceErrors.New(einvoice.ErrSubmissionSaveFailed).
+		WithDetail("invoice_id", submission.InvoiceID.String()).
+		WithCause(err)
+}
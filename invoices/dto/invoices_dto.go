@@ -0,0 +1,184 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices/jsondiff"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/jsonfilter"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CreateInvoiceRequest represents the request payload for creating an invoice
+type CreateInvoiceRequest struct {
+	ID                uuid.UUID          `json:"-"` // set by callers that need a known ID, such as recurring templates
+	InvoiceTypeID     uuid.UUID          `json:"invoice_type_id" validate:"required"`
+	OrganizationID    uuid.UUID          `json:"organization_id" validate:"required"`
+	ProjectID         *uuid.UUID         `json:"project_id,omitempty"`
+	ProviderID        *uuid.UUID         `json:"provider_id,omitempty"`
+	InvoiceData       models.InvoiceData `json:"invoice_data" validate:"required"`
+	LineItems         []LineItemRequest  `json:"line_items,omitempty"`
+	SeriesID          *uuid.UUID         `json:"series_id,omitempty"`           // assigns invoice_number from the series
+	OriginalInvoiceID *uuid.UUID         `json:"original_invoice_id,omitempty"` // required for credit and debit notes
+	ReasonCode        *string            `json:"reason_code,omitempty"`         // required for credit and debit notes
+	Reason            *string            `json:"reason,omitempty"`
+	CreatedBy         *uuid.UUID         `json:"created_by,omitempty"`
+}
+
+// UpdateInvoiceRequest represents the request payload for updating an invoice.
+// LineItems replaces all lines when present; an empty list removes them.
+type UpdateInvoiceRequest struct {
+	ProjectID   *uuid.UUID         `json:"project_id"`
+	ProviderID  *uuid.UUID         `json:"provider_id"`
+	InvoiceData models.InvoiceData `json:"invoice_data"`
+	LineItems   []LineItemRequest  `json:"line_items"`
+	UpdatedBy   *uuid.UUID         `json:"updated_by,omitempty"` // recorded as the author of the new revision
+}
+
+// LineItemRequest represents an invoice line in create and update requests.
+// Amounts accept JSON numbers or strings; percentages are in percent.
+type LineItemRequest struct {
+	ItemCode        *string         `json:"item_code,omitempty"`
+	Description     string          `json:"description" validate:"required"`
+	Quantity        decimal.Decimal `json:"quantity" validate:"required"`
+	UnitCode        *string         `json:"unit_code,omitempty"`
+	UnitPrice       decimal.Decimal `json:"unit_price"`
+	DiscountPercent decimal.Decimal `json:"discount_percent"`
+	DiscountAmount  decimal.Decimal `json:"discount_amount"`
+	TaxCode         *string         `json:"tax_code,omitempty"`
+	TaxRate         decimal.Decimal `json:"tax_rate"`
+}
+
+// Visibility of soft deleted invoices in listings
+const (
+	DeletedExclude = "exclude"
+	DeletedInclude = "include"
+	DeletedOnly    = "only"
+)
+
+// InvoiceListRequest represents query parameters for listing invoices.
+// Soft deleted invoices are only listed when Deleted asks for them. Filter
+// is a jsonfilter expression over invoice_data, checked against the schema
+// of InvoiceTypeID and compiled into DataFilter by the service.
+type InvoiceListRequest struct {
+	OrganizationID *uuid.UUID `query:"organization_id"`
+	ProjectID      *uuid.UUID `query:"project_id"`
+	ProviderID     *uuid.UUID `query:"provider_id"`
+	InvoiceTypeID  *uuid.UUID `query:"invoice_type_id"`
+	Status         *string    `query:"status"`
+	CurrencyCode   *string    `query:"currency_code"`
+	DateFrom       *time.Time `query:"date_from"`
+	DateTo         *time.Time `query:"date_to"`
+	DueFrom        *time.Time `query:"due_from"`
+	DueTo          *time.Time `query:"due_to"`
+	MinAmount      *float64   `query:"min_amount"`
+	MaxAmount      *float64   `query:"max_amount"`
+	Filter         string     `query:"filter"`
+	Deleted        string     `query:"deleted" validate:"omitempty,oneof=exclude include only"`
+	Page           int        `query:"page" validate:"min=1"`
+	PageSize       int        `query:"page_size" validate:"min=1,max=100"`
+	SortBy         string     `query:"sort_by"`
+	SortOrder      string     `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+
+	DataFilter *jsonfilter.Filter `query:"-"`
+}
+
+// InvoiceSearchRequest represents a full-text search over invoices. Query
+// uses web search syntax ("quoted phrases", or, -excluded) and the list
+// filters narrow the matches; results are ordered by rank.
+type InvoiceSearchRequest struct {
+	InvoiceListRequest
+	Query    string `query:"q" validate:"required"`
+	Language string `query:"language" validate:"omitempty,oneof=es en"`
+}
+
+// InvoiceResponse represents the response for a single invoice
+type InvoiceResponse struct {
+	*models.Invoice `json:",inline"`
+}
+
+// InvoiceListResponse represents the response for listing invoices
+type InvoiceListResponse struct {
+	Invoices    []*models.Invoice `json:"invoices"`
+	Total       int64             `json:"total"`
+	Page        int               `json:"page"`
+	PageSize    int               `json:"page_size"`
+	TotalPages  int               `json:"total_pages"`
+	HasNext     bool              `json:"has_next"`
+	HasPrevious bool              `json:"has_previous"`
+}
+
+// InvoiceSearchResponse represents the response for an invoice search
+type InvoiceSearchResponse struct {
+	Query       string                 `json:"query"`
+	Language    string                 `json:"language"`
+	Results     []*models.SearchResult `json:"results"`
+	Total       int64                  `json:"total"`
+	Page        int                    `json:"page"`
+	PageSize    int                    `json:"page_size"`
+	TotalPages  int                    `json:"total_pages"`
+	HasNext     bool                   `json:"has_next"`
+	HasPrevious bool                   `json:"has_previous"`
+}
+
+// TransitionRequest represents the request payload for changing an invoice's status
+type TransitionRequest struct {
+	ToStatus       string    `json:"to_status" validate:"required"`
+	Comment        *string   `json:"comment,omitempty"`
+	TransitionedBy uuid.UUID `json:"transitioned_by" validate:"required"`
+}
+
+// TransitionResponse represents the outcome of a status transition
+type TransitionResponse struct {
+	Invoice            *models.Invoice          `json:"invoice"`
+	Transition         *models.StatusTransition `json:"transition"`
+	AllowedTransitions []string                 `json:"allowed_transitions"`
+}
+
+// TransitionListResponse represents the status history of an invoice
+type TransitionListResponse struct {
+	Status             *string                    `json:"status"`
+	AllowedTransitions []string                   `json:"allowed_transitions"`
+	Transitions        []*models.StatusTransition `json:"transitions"`
+}
+
+// PurgeRequest represents a hard purge of soft deleted invoices of an
+// organization. DeletedBefore may only narrow the retention window.
+type PurgeRequest struct {
+	OrganizationID uuid.UUID  `json:"organization_id" validate:"required"`
+	DeletedBefore  *time.Time `json:"deleted_before,omitempty"`
+	PurgedBy       uuid.UUID  `json:"purged_by" validate:"required"`
+}
+
+// PurgeResponse represents the outcome of a hard purge
+type PurgeResponse struct {
+	OrganizationID uuid.UUID   `json:"organization_id"`
+	Cutoff         time.Time   `json:"cutoff"`
+	PurgedCount    int         `json:"purged_count"`
+	PurgedIDs      []uuid.UUID `json:"purged_ids"`
+}
+
+// RevisionListResponse represents the change history of an invoice
+type RevisionListResponse struct {
+	InvoiceID      uuid.UUID          `json:"invoice_id"`
+	CurrentVersion int                `json:"current_version"`
+	Revisions      []*models.Revision `json:"revisions"`
+}
+
+// RevisionDiffResponse represents the changes between two versions of an invoice
+type RevisionDiffResponse struct {
+	InvoiceID   uuid.UUID         `json:"invoice_id"`
+	FromVersion int               `json:"from_version"`
+	ToVersion   int               `json:"to_version"`
+	From        *models.Revision  `json:"from"`
+	To          *models.Revision  `json:"to"`
+	Changes     []jsondiff.Change `json:"changes"`
+}
+
+// BalanceResponse represents what remains owed on an invoice and the credit
+// and debit notes that adjust it
+type BalanceResponse struct {
+	*models.Balance `json:",inline"`
+	Adjustments     []*models.Invoice `json:"adjustments"`
+}
@@ -0,0 +1,214 @@
+package invoices
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// InvoicesErrors is the error registry for invoices domain
+var InvoicesErrors = errx.NewRegistry("INVOICES")
+
+// Invoice error codes
+var (
+	// Basic CRUD errors
+	ErrInvoiceNotFound = InvoicesErrors.Register(
+		"NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Invoice not found",
+	)
+
+	ErrInvoiceNumberExists = InvoicesErrors.Register(
+		"NUMBER_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice with this number already exists in the organization",
+	)
+
+	ErrInvoiceCreateFailed = InvoicesErrors.Register(
+		"CREATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to create invoice",
+	)
+
+	ErrInvoiceUpdateFailed = InvoicesErrors.Register(
+		"UPDATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to update invoice",
+	)
+
+	ErrInvoiceDeleteFailed = InvoicesErrors.Register(
+		"DELETE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to delete invoice",
+	)
+
+	// Soft delete lifecycle errors
+	ErrInvoiceNotDeleted = InvoicesErrors.Register(
+		"NOT_DELETED",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice is not deleted",
+	)
+
+	ErrPurgeForbidden = InvoicesErrors.Register(
+		"PURGE_FORBIDDEN",
+		errx.TypeAuthorization,
+		http.StatusForbidden,
+		"Only organization admins can purge invoices",
+	)
+
+	ErrInvoiceSubmitted = InvoicesErrors.Register(
+		"SUBMITTED_TO_TAX_AUTHORITY",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice was submitted to the tax authority and must be kept; issue a credit note instead",
+	)
+
+	ErrInvoicePurgeFailed = InvoicesErrors.Register(
+		"PURGE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to purge invoices",
+	)
+
+	// Query errors
+	ErrInvoiceListFailed = InvoicesErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list invoices",
+	)
+
+	// Validation errors
+	ErrInvoiceValidationFailed = InvoicesErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invoice validation failed",
+	)
+
+	// Line item and totals errors
+	ErrInvalidLineItem = InvoicesErrors.Register(
+		"INVALID_LINE_ITEM",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invoice line item is invalid",
+	)
+
+	ErrTotalsMismatch = InvoicesErrors.Register(
+		"TOTALS_MISMATCH",
+		errx.TypeValidation,
+		http.StatusUnprocessableEntity,
+		"Declared invoice totals do not match the totals computed from the line items",
+	)
+
+	// Numbering errors
+	ErrInvoiceNumbered = InvoicesErrors.Register(
+		"NUMBERED_BY_SERIES",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice number was assigned by a numbering series and cannot be set or changed",
+	)
+
+	// Status lifecycle errors
+	ErrInvalidStatus = InvoicesErrors.Register(
+		"INVALID_STATUS",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invoice status is not allowed here by the invoice type workflow",
+	)
+
+	ErrInvalidStatusTransition = InvoicesErrors.Register(
+		"INVALID_STATUS_TRANSITION",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice status transition is not allowed by the invoice type workflow",
+	)
+
+	// Adjustment note errors
+	ErrCreditExceedsBalance = InvoicesErrors.Register(
+		"CREDIT_EXCEEDS_BALANCE",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Credit notes would exceed the outstanding balance of the original invoice",
+	)
+
+	ErrInvoiceHasAdjustments = InvoicesErrors.Register(
+		"HAS_ADJUSTMENTS",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice is adjusted by live credit or debit notes",
+	)
+
+	ErrInvoiceHasPayments = InvoicesErrors.Register(
+		"HAS_PAYMENTS",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice is settled in part by payments; void or unallocate them first",
+	)
+
+	ErrInvoicePaymentScheduled = InvoicesErrors.Register(
+		"PAYMENT_SCHEDULED",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice is scheduled in a payment batch; cancel the batch first",
+	)
+
+	// Revision history errors
+	ErrRevisionNotFound = InvoicesErrors.Register(
+		"REVISION_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Invoice revision not found",
+	)
+
+	// Reference errors
+	ErrInvoiceInvalidReference = InvoicesErrors.Register(
+		"INVALID_REFERENCE",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invoice references an unknown invoice type, organization, project or provider",
+	)
+)
+
+// Helper functions for error checking
+func IsInvoiceNotFound(err error) bool {
+	return errx.IsCode(err, ErrInvoiceNotFound)
+}
+
+func IsInvoiceNumberExists(err error) bool {
+	return errx.IsCode(err, ErrInvoiceNumberExists)
+}
+
+func IsInvoiceValidationFailed(err error) bool {
+	return errx.IsCode(err, ErrInvoiceValidationFailed)
+}
+
+func IsInvoiceNotDeleted(err error) bool {
+	return errx.IsCode(err, ErrInvoiceNotDeleted)
+}
+
+func IsInvoiceSubmitted(err error) bool {
+	return errx.IsCode(err, ErrInvoiceSubmitted)
+}
+
+func IsTotalsMismatch(err error) bool {
+	return errx.IsCode(err, ErrTotalsMismatch)
+}
+
+func IsCreditExceedsBalance(err error) bool {
+	return errx.IsCode(err, ErrCreditExceedsBalance)
+}
+
+func IsRevisionNotFound(err error) bool {
+	return errx.IsCode(err, ErrRevisionNotFound)
+}
+
+func IsInvalidStatusTransition(err error) bool {
+	return errx.IsCode(err, ErrInvalidStatusTransition)
+}
@@ -0,0 +1,667 @@
+package invoicesapi
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/approvals/approvalsrv"
+	approvalspg "github.com/Abraxas-365/fuckturamelo/approvals/repository"
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/invoicesrv"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/invoicetypesrv"
+	typespostgres "github.com/Abraxas-365/fuckturamelo/invoicetypes/repository"
+	"github.com/Abraxas-365/fuckturamelo/numbering/numberingsrv"
+	numberingpg "github.com/Abraxas-365/fuckturamelo/numbering/repository"
+)
+
+// InvoicesAPI contains the complete API setup for the invoices domain
+type InvoicesAPI struct {
+	service invoicesrv.InvoiceService
+	repo    postgres.InvoiceRepository
+}
+
+// Config contains configuration for the invoices API
+type Config struct {
+	DB *sqlx.DB
+
+	// Attachments removes the files of purged invoices
+	Attachments invoicesrv.Attachments
+
+	// PurgeRetention is how long soft deleted invoices are kept before they
+	// may be purged (defaults to invoicesrv.DefaultPurgeRetention)
+	PurgeRetention time.Duration
+}
+
+// New creates a new InvoicesAPI instance
+func New(config Config) (*InvoicesAPI, error) {
+	if config.DB == nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+	if config.Attachments == nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Attachment service is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewInvoiceRepository(config.DB)
+	typeRepo := typespostgres.NewInvoiceTypeRepository(config.DB)
+	typeVersions := typespostgres.NewSchemaVersionRepository(config.DB)
+	typeSvc := invoicetypesrv.NewInvoiceTypeService(typeRepo, typeVersions)
+	seriesSvc := numberingsrv.NewSeriesService(numberingpg.NewSeriesRepository(config.DB))
+	approvalSvc := approvalsrv.NewApprovalService(approvalspg.NewApprovalRepository(config.DB), repo, typeSvc)
+	svc := invoicesrv.NewInvoiceService(repo, typeSvc, seriesSvc, approvalSvc, config.Attachments, config.PurgeRetention)
+
+	return &InvoicesAPI{
+		service: svc,
+		repo:    repo,
+	}, nil
+}
+
+// SetupRoutes registers all invoice routes with the given Fiber router group
+func (api *InvoicesAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Query routes
+	router.Get("/search", api.searchInvoices)
+	router.Get("/organization/:orgId", api.getInvoicesByOrganization)
+
+	// Retention routes
+	router.Post("/purge", api.purgeInvoices)
+
+	// Basic CRUD routes
+	router.Post("/", api.createInvoice)
+	router.Get("/", api.listInvoices)
+	router.Get("/:id", api.getInvoice)
+	router.Put("/:id", api.updateInvoice)
+	router.Patch("/:id", api.patchInvoice)
+	router.Delete("/:id", api.deleteInvoice)
+	router.Post("/:id/restore", api.restoreInvoice)
+
+	// Status lifecycle routes
+	router.Post("/:id/transitions", api.transitionInvoice)
+	router.Get("/:id/transitions", api.listTransitions)
+
+	// Credit and debit note routes
+	router.Get("/:id/balance", api.getBalance)
+
+	// Revision history routes
+	router.Get("/:id/revisions", api.listRevisions)
+	router.Get("/:id/revisions/diff", api.diffRevisions)
+	router.Get("/:id/revisions/:version", api.getRevision)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *InvoicesAPI) GetService() invoicesrv.InvoiceService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *InvoicesAPI) GetRepository() postgres.InvoiceRepository {
+	return api.repo
+}
+
+// Basic CRUD handlers
+
+// createInvoice handles POST /invoices
+func (api *InvoicesAPI) createInvoice(c *fiber.Ctx) error {
+	var req dto.CreateInvoiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.CreateInvoice(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+	api.setETag(c, result.Invoice)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getInvoice handles GET /invoices/:id
+func (api *InvoicesAPI) getInvoice(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetInvoice(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	api.setETag(c, result.Invoice)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// updateInvoice handles PUT /invoices/:id
+func (api *InvoicesAPI) updateInvoice(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	precondition, err := api.requireIfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateInvoiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.UpdateInvoice(c.Context(), id, &req, precondition)
+	if err != nil {
+		return err
+	}
+	api.setETag(c, result.Invoice)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// patchInvoice handles PATCH /invoices/:id
+func (api *InvoicesAPI) patchInvoice(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	precondition, err := api.requireIfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateInvoiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.PatchInvoice(c.Context(), id, &req, precondition)
+	if err != nil {
+		return err
+	}
+	api.setETag(c, result.Invoice)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteInvoice handles DELETE /invoices/:id
+func (api *InvoicesAPI) deleteInvoice(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	err = api.service.DeleteInvoice(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// restoreInvoice handles POST /invoices/:id/restore
+func (api *InvoicesAPI) restoreInvoice(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.RestoreInvoice(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	api.setETag(c, result.Invoice)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// purgeInvoices handles POST /invoices/purge
+func (api *InvoicesAPI) purgeInvoices(c *fiber.Ctx) error {
+	var req dto.PurgeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.PurgeInvoices(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listInvoices handles GET /invoices
+func (api *InvoicesAPI) listInvoices(c *fiber.Ctx) error {
+	req, err := api.parseListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListInvoices(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// searchInvoices handles GET /invoices/search?q=...&language=es|en, taking
+// the same filters and pagination as GET /invoices
+func (api *InvoicesAPI) searchInvoices(c *fiber.Ctx) error {
+	listReq, err := api.parseListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	req := &dto.InvoiceSearchRequest{
+		InvoiceListRequest: *listReq,
+		Query:              c.Query("q"),
+		Language:           c.Query("language"),
+	}
+
+	result, err := api.service.SearchInvoices(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Status lifecycle handlers
+
+// transitionInvoice handles POST /invoices/:id/transitions
+func (api *InvoicesAPI) transitionInvoice(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.TransitionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.TransitionInvoice(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+	api.setETag(c, result.Invoice)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listTransitions handles GET /invoices/:id/transitions
+func (api *InvoicesAPI) listTransitions(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListTransitions(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Credit and debit note handlers
+
+// getBalance handles GET /invoices/:id/balance
+func (api *InvoicesAPI) getBalance(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetBalance(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Revision history handlers
+
+// listRevisions handles GET /invoices/:id/revisions
+func (api *InvoicesAPI) listRevisions(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListRevisions(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getRevision handles GET /invoices/:id/revisions/:version
+func (api *InvoicesAPI) getRevision(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	version, err := api.parseVersion(c.Params("version"), "version")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetRevision(c.Context(), id, version)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// diffRevisions handles GET /invoices/:id/revisions/diff?from=1&to=2
+func (api *InvoicesAPI) diffRevisions(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	from, err := api.parseVersion(c.Query("from"), "from")
+	if err != nil {
+		return err
+	}
+	to, err := api.parseVersion(c.Query("to"), "to")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.DiffRevisions(c.Context(), id, from, to)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Query handlers
+
+// getInvoicesByOrganization handles GET /invoices/organization/:orgId
+func (api *InvoicesAPI) getInvoicesByOrganization(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	req, err := api.parseListRequest(c)
+	if err != nil {
+		return err
+	}
+	req.OrganizationID = &orgID
+
+	result, err := api.service.ListInvoices(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// healthCheck provides a health check endpoint
+func (api *InvoicesAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "invoices",
+	})
+}
+
+// Helper methods
+
+// setETag exposes the invoice version as the response entity tag
+func (api *InvoicesAPI) setETag(c *fiber.Ctx, invoice *models.Invoice) {
+	c.Set(fiber.HeaderETag, concurrency.VersionETag(invoice.Version))
+}
+
+// requireIfMatch parses the mandatory If-Match header of invoice writes
+func (api *InvoicesAPI) requireIfMatch(c *fiber.Ctx) (*concurrency.Precondition, error) {
+	precondition, err := concurrency.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return nil, err
+	}
+	if err := concurrency.Require(precondition); err != nil {
+		return nil, err
+	}
+	return precondition, nil
+}
+
+func (api *InvoicesAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *InvoicesAPI) parseVersion(value, name string) (int, error) {
+	if value == "" {
+		return 0, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Missing required parameter: "+name)
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid "+name+" format (expected a version number)").
+			WithCause(err)
+	}
+
+	return version, nil
+}
+
+func (api *InvoicesAPI) parseUUIDQuery(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &id, nil
+}
+
+func (api *InvoicesAPI) parseDateQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid "+name+" format (expected YYYY-MM-DD)").
+			WithCause(err)
+	}
+
+	return &date, nil
+}
+
+func (api *InvoicesAPI) parseAmountQuery(c *fiber.Ctx, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &amount, nil
+}
+
+func (api *InvoicesAPI) parseListRequest(c *fiber.Ctx) (*dto.InvoiceListRequest, error) {
+	req := &dto.InvoiceListRequest{}
+	var err error
+
+	// Parse reference filters
+	if req.OrganizationID, err = api.parseUUIDQuery(c, "organization_id"); err != nil {
+		return nil, err
+	}
+	if req.ProjectID, err = api.parseUUIDQuery(c, "project_id"); err != nil {
+		return nil, err
+	}
+	if req.ProviderID, err = api.parseUUIDQuery(c, "provider_id"); err != nil {
+		return nil, err
+	}
+	if req.InvoiceTypeID, err = api.parseUUIDQuery(c, "invoice_type_id"); err != nil {
+		return nil, err
+	}
+
+	// Parse status and currency
+	if status := c.Query("status"); status != "" {
+		req.Status = &status
+	}
+	if currency := c.Query("currency_code"); currency != "" {
+		req.CurrencyCode = &currency
+	}
+
+	// Parse date ranges
+	if req.DateFrom, err = api.parseDateQuery(c, "date_from"); err != nil {
+		return nil, err
+	}
+	if req.DateTo, err = api.parseDateQuery(c, "date_to"); err != nil {
+		return nil, err
+	}
+	if req.DueFrom, err = api.parseDateQuery(c, "due_from"); err != nil {
+		return nil, err
+	}
+	if req.DueTo, err = api.parseDateQuery(c, "due_to"); err != nil {
+		return nil, err
+	}
+
+	// Parse amount range
+	if req.MinAmount, err = api.parseAmountQuery(c, "min_amount"); err != nil {
+		return nil, err
+	}
+	if req.MaxAmount, err = api.parseAmountQuery(c, "max_amount"); err != nil {
+		return nil, err
+	}
+
+	// Parse the invoice_data filter expression (checked by the service)
+	req.Filter = c.Query("filter")
+
+	// Parse deleted invoice visibility
+	switch deleted := c.Query("deleted", dto.DeletedExclude); deleted {
+	case dto.DeletedExclude, dto.DeletedInclude, dto.DeletedOnly:
+		req.Deleted = deleted
+	default:
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("error", "Invalid deleted filter").
+			WithDetail("expected", []string{dto.DeletedExclude, dto.DeletedInclude, dto.DeletedOnly})
+	}
+
+	// Parse pagination
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	req.Page = page
+
+	pageSize := 20
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
+			pageSize = ps
+		}
+	}
+	req.PageSize = pageSize
+
+	// Parse sorting
+	req.SortBy = c.Query("sort_by", "created_at")
+	req.SortOrder = c.Query("sort_order", "desc")
+
+	return req, nil
+}
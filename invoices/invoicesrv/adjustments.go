@@ -0,0 +1,161 @@
+package invoicesrv
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// maxReasonCodeLength bounds adjustment reason codes (SUNAT uses two digits)
+const maxReasonCodeLength = 10
+
+// GetBalance returns the outstanding amount of an invoice together with the
+// credit and debit notes that adjust it
+func (s *invoiceService) GetBalance(ctx context.Context, id uuid.UUID) (*dto.BalanceResponse, error) {
+	existing, err := s.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.DocumentKind.IsAdjustment() {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("invoice_id", id.String()).
+			WithDetail("reason", "credit and debit notes have no balance of their own").
+			WithDetail("original_invoice_id", existing.OriginalInvoiceID)
+	}
+
+	balance, err := s.repo.GetBalance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments, err := s.repo.ListAdjustments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.BalanceResponse{
+		Balance:     balance,
+		Adjustments: adjustments,
+	}, nil
+}
+
+// applyDocumentKind marks a new invoice with the document kind of its type.
+// Credit and debit notes must reference a live invoice of the same
+// organization and currency and say why they were issued; their currency
+// defaults to the original's.
+func (s *invoiceService) applyDocumentKind(ctx context.Context, invoice *models.Invoice, req *dto.CreateInvoiceRequest) error {
+	kind, err := s.types.GetDocumentKind(ctx, req.InvoiceTypeID)
+	if err != nil {
+		return err
+	}
+	invoice.DocumentKind = kind
+
+	if !kind.IsAdjustment() {
+		if req.OriginalInvoiceID != nil || req.ReasonCode != nil {
+			return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+				WithDetail("field", "original_invoice_id").
+				WithDetail("reason", "only credit and debit notes reference an original invoice")
+		}
+		return nil
+	}
+
+	if req.OriginalInvoiceID == nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "original_invoice_id").
+			WithDetail("reason", "required").
+			WithDetail("document_kind", kind)
+	}
+	if req.ReasonCode == nil || *req.ReasonCode == "" {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "reason_code").
+			WithDetail("reason", "required").
+			WithDetail("document_kind", kind)
+	}
+	if len(*req.ReasonCode) > maxReasonCodeLength {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "reason_code").
+			WithDetail("reason", "too_long").
+			WithDetail("max_length", maxReasonCodeLength)
+	}
+
+	original, err := s.repo.GetByID(ctx, *req.OriginalInvoiceID)
+	if err != nil {
+		if invoices.IsInvoiceNotFound(err) {
+			return invoices.InvoicesErrors.New(invoices.ErrInvoiceInvalidReference).
+				WithDetail("field", "original_invoice_id").
+				WithDetail("original_invoice_id", req.OriginalInvoiceID.String())
+		}
+		return err
+	}
+
+	invalidOriginal := func(reason string) error {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "original_invoice_id").
+			WithDetail("original_invoice_id", original.ID.String()).
+			WithDetail("reason", reason)
+	}
+
+	switch {
+	case original.IsDeleted:
+		return invalidOriginal("original invoice is deleted")
+	case original.OrganizationID != req.OrganizationID:
+		return invalidOriginal("original invoice belongs to another organization")
+	case original.DocumentKind != typemodels.KindInvoice:
+		return invalidOriginal("notes can only adjust regular invoices")
+	case original.Status != nil && *original.Status == models.VoidStatus:
+		return invalidOriginal("original invoice is void")
+	}
+
+	if original.CurrencyCode != nil {
+		if raw, ok := invoice.InvoiceData[models.FieldCurrencyCode]; !ok || raw == nil {
+			invoice.InvoiceData[models.FieldCurrencyCode] = *original.CurrencyCode
+		}
+	}
+
+	invoice.OriginalInvoiceID = &original.ID
+	invoice.AdjustmentReasonCode = req.ReasonCode
+	invoice.AdjustmentReason = req.Reason
+
+	return checkAdjustmentData(invoice, original)
+}
+
+// checkAdjustment re-checks a note against its original after an update
+func (s *invoiceService) checkAdjustment(ctx context.Context, invoice *models.Invoice) error {
+	if invoice.OriginalInvoiceID == nil {
+		return nil
+	}
+
+	original, err := s.repo.GetByID(ctx, *invoice.OriginalInvoiceID)
+	if err != nil {
+		return err
+	}
+
+	return checkAdjustmentData(invoice, original)
+}
+
+// checkAdjustmentData requires notes to share the original's currency and to
+// carry a positive total. Whether credits fit in the original's balance is
+// checked by the repository while the original is locked.
+func checkAdjustmentData(note, original *models.Invoice) error {
+	currency, _ := note.InvoiceData[models.FieldCurrencyCode].(string)
+	if original.CurrencyCode != nil && currency != *original.CurrencyCode {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "invoice_data."+models.FieldCurrencyCode).
+			WithDetail("reason", "must match the currency of the original invoice").
+			WithDetail("expected", *original.CurrencyCode)
+	}
+
+	amount, ok := numericValue(note.InvoiceData[models.FieldTotalAmount])
+	if !ok || amount <= 0 {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "invoice_data."+models.FieldTotalAmount).
+			WithDetail("reason", "credit and debit notes need a positive total")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package invoicesrv
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/jsondiff"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// ListRevisions returns every version of an invoice, oldest first. The
+// history of soft deleted invoices stays available until they are purged.
+func (s *invoiceService) ListRevisions(ctx context.Context, id uuid.UUID) (*dto.RevisionListResponse, error) {
+	invoice, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repo.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RevisionListResponse{
+		InvoiceID:      id,
+		CurrentVersion: invoice.Version,
+		Revisions:      revisions,
+	}, nil
+}
+
+// GetRevision returns an invoice as it was at the given version
+func (s *invoiceService) GetRevision(ctx context.Context, id uuid.UUID, version int) (*models.Revision, error) {
+	if err := validateRevisionVersion("version", version); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetRevision(ctx, id, version)
+}
+
+// DiffRevisions returns the changes between two versions of an invoice.
+// Versions may be given in either order; changes always read from
+// fromVersion to toVersion.
+func (s *invoiceService) DiffRevisions(ctx context.Context, id uuid.UUID, fromVersion, toVersion int) (*dto.RevisionDiffResponse, error) {
+	if err := validateRevisionVersion("from", fromVersion); err != nil {
+		return nil, err
+	}
+	if err := validateRevisionVersion("to", toVersion); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	from, err := s.repo.GetRevision(ctx, id, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.repo.GetRevision(ctx, id, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RevisionDiffResponse{
+		InvoiceID:   id,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		From:        from,
+		To:          to,
+		Changes:     jsondiff.Diff(from.Snapshot(), to.Snapshot()),
+	}, nil
+}
+
+func validateRevisionVersion(field string, version int) error {
+	if version < 1 {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", field).
+			WithDetail("reason", "must be a version number of at least 1")
+	}
+	return nil
+}
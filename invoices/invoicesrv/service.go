@@ -0,0 +1,890 @@
+package invoicesrv
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+	"github.com/Abraxas-365/fuckturamelo/jsonfilter"
+	"github.com/Abraxas-365/fuckturamelo/organization"
+	"github.com/google/uuid"
+)
+
+// InvoiceService defines the interface for invoice business logic
+type InvoiceService interface {
+	// Basic CRUD operations
+	CreateInvoice(ctx context.Context, req *dto.CreateInvoiceRequest) (*dto.InvoiceResponse, error)
+	GetInvoice(ctx context.Context, id uuid.UUID) (*dto.InvoiceResponse, error)
+	UpdateInvoice(ctx context.Context, id uuid.UUID, req *dto.UpdateInvoiceRequest, precondition *concurrency.Precondition) (*dto.InvoiceResponse, error)
+	PatchInvoice(ctx context.Context, id uuid.UUID, req *dto.UpdateInvoiceRequest, precondition *concurrency.Precondition) (*dto.InvoiceResponse, error)
+	DeleteInvoice(ctx context.Context, id uuid.UUID) error
+	DiscardInvoice(ctx context.Context, id uuid.UUID) error
+
+	// Soft delete lifecycle
+	RestoreInvoice(ctx context.Context, id uuid.UUID) (*dto.InvoiceResponse, error)
+	PurgeInvoices(ctx context.Context, req *dto.PurgeRequest) (*dto.PurgeResponse, error)
+
+	// Query operations
+	ListInvoices(ctx context.Context, req *dto.InvoiceListRequest) (*dto.InvoiceListResponse, error)
+	SearchInvoices(ctx context.Context, req *dto.InvoiceSearchRequest) (*dto.InvoiceSearchResponse, error)
+
+	// Status lifecycle
+	TransitionInvoice(ctx context.Context, id uuid.UUID, req *dto.TransitionRequest) (*dto.TransitionResponse, error)
+	ListTransitions(ctx context.Context, id uuid.UUID) (*dto.TransitionListResponse, error)
+
+	// Credit and debit notes
+	GetBalance(ctx context.Context, id uuid.UUID) (*dto.BalanceResponse, error)
+
+	// Revision history
+	ListRevisions(ctx context.Context, id uuid.UUID) (*dto.RevisionListResponse, error)
+	GetRevision(ctx context.Context, id uuid.UUID, version int) (*models.Revision, error)
+	DiffRevisions(ctx context.Context, id uuid.UUID, fromVersion, toVersion int) (*dto.RevisionDiffResponse, error)
+}
+
+// InvoiceTypeRegistry exposes the invoice type rules the invoice service
+// enforces (implemented by invoicetypesrv.InvoiceTypeService)
+type InvoiceTypeRegistry interface {
+	// ValidateInvoice validates a payload against the current schema of the
+	// invoice type and returns that schema's version
+	ValidateInvoice(ctx context.Context, invoiceTypeID, orgID uuid.UUID, projectID *uuid.UUID, data map[string]any) (string, error)
+
+	// GetStatusWorkflow returns the status lifecycle of the invoice type
+	GetStatusWorkflow(ctx context.Context, invoiceTypeID uuid.UUID) (*typemodels.StatusWorkflow, error)
+
+	// GetDocumentKind tells whether invoices of the type are credit or debit notes
+	GetDocumentKind(ctx context.Context, invoiceTypeID uuid.UUID) (typemodels.DocumentKind, error)
+
+	// GetSchema returns the current compiled schema of the invoice type
+	GetSchema(ctx context.Context, invoiceTypeID uuid.UUID) (*schema.Schema, error)
+}
+
+// NumberingSeries previews the numbers of numbering series (implemented by
+// numberingsrv.SeriesService). The number itself is assigned by the
+// repository inside the create transaction.
+type NumberingSeries interface {
+	PreviewNumber(ctx context.Context, id, orgID uuid.UUID) (string, error)
+}
+
+// ApprovalGate enforces approval chains on invoice changes (implemented by
+// approvalsrv.ApprovalService)
+type ApprovalGate interface {
+	// CheckTransition fails when the invoice has to go through its approval
+	// chain instead of being moved to the status by hand
+	CheckTransition(ctx context.Context, invoice *models.Invoice, toStatus string) error
+
+	// CheckEditable fails while the invoice has an approval in progress
+	CheckEditable(ctx context.Context, invoice *models.Invoice) error
+}
+
+// Attachments removes the files of invoices that are permanently deleted
+// (implemented by attachmentsrv.AttachmentService). Their rows would
+// otherwise cascade away with the invoice and leave the content stored.
+type Attachments interface {
+	DeleteInvoiceAttachments(ctx context.Context, invoiceID uuid.UUID) error
+
+	// ReleaseObjects removes the stored objects of purged invoices that no
+	// attachment references anymore
+	ReleaseObjects(ctx context.Context, keys []string) error
+}
+
+// DefaultPurgeRetention is how long soft deleted invoices are kept before
+// they may be purged, unless configured otherwise
+const DefaultPurgeRetention = 90 * 24 * time.Hour
+
+// MaxSearchQueryLength bounds the length of full-text search queries in
+// characters
+const MaxSearchQueryLength = 256
+
+// invoiceService implements InvoiceService
+type invoiceService struct {
+	repo           postgres.InvoiceRepository
+	types          InvoiceTypeRegistry
+	series         NumberingSeries
+	approvals      ApprovalGate
+	attachments    Attachments
+	purgeRetention time.Duration
+}
+
+// NewInvoiceService creates a new invoice service. A zero purgeRetention
+// uses DefaultPurgeRetention; a nil approval gate enforces no approvals.
+func NewInvoiceService(repo postgres.InvoiceRepository, types InvoiceTypeRegistry, series NumberingSeries, approvals ApprovalGate, attachments Attachments, purgeRetention time.Duration) InvoiceService {
+	if purgeRetention <= 0 {
+		purgeRetention = DefaultPurgeRetention
+	}
+
+	return &invoiceService{
+		repo:           repo,
+		types:          types,
+		series:         series,
+		approvals:      approvals,
+		attachments:    attachments,
+		purgeRetention: purgeRetention,
+	}
+}
+
+// CreateInvoice creates a new invoice
+func (s *invoiceService) CreateInvoice(ctx context.Context, req *dto.CreateInvoiceRequest) (*dto.InvoiceResponse, error) {
+	// Validate request
+	if err := s.validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// New invoices always start in the workflow's initial status
+	workflow, err := s.types.GetStatusWorkflow(ctx, req.InvoiceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyInitialStatus(req.InvoiceData, workflow); err != nil {
+		return nil, err
+	}
+
+	// Series numbers are previewed so the schema validates the real format;
+	// the repository assigns the final number atomically
+	if req.SeriesID != nil {
+		if raw, ok := req.InvoiceData[models.FieldInvoiceNumber]; ok && raw != nil {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNumbered).
+				WithDetail("field", "invoice_data."+models.FieldInvoiceNumber).
+				WithDetail("series_id", req.SeriesID.String())
+		}
+		preview, err := s.series.PreviewNumber(ctx, *req.SeriesID, req.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		req.InvoiceData[models.FieldInvoiceNumber] = preview
+	}
+
+	lineItems, err := lineItemsFromRequest(req.LineItems)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create invoice model
+	invoice := &models.Invoice{
+		ID:             req.ID,
+		InvoiceData:    req.InvoiceData,
+		InvoiceTypeID:  req.InvoiceTypeID,
+		OrganizationID: req.OrganizationID,
+		ProjectID:      req.ProjectID,
+		ProviderID:     req.ProviderID,
+		SeriesID:       req.SeriesID,
+		Version:        1,
+		CreatedBy:      req.CreatedBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		LineItems:      lineItems,
+	}
+
+	// Derive totals from the line items before the schema sees the payload
+	if err := applyTotals(invoice, req.InvoiceData); err != nil {
+		return nil, err
+	}
+
+	// Credit and debit notes are tied to the invoice they adjust
+	if err := s.applyDocumentKind(ctx, invoice, req); err != nil {
+		return nil, err
+	}
+
+	// Validate payload against the invoice type schema
+	invoice.SchemaVersion, err = s.types.ValidateInvoice(ctx, req.InvoiceTypeID, req.OrganizationID, req.ProjectID, invoice.InvoiceData)
+	if err != nil {
+		return nil, err
+	}
+
+	createdInvoice, err := s.repo.Create(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceResponse{Invoice: createdInvoice}, nil
+}
+
+// GetInvoice retrieves an invoice by ID
+func (s *invoiceService) GetInvoice(ctx context.Context, id uuid.UUID) (*dto.InvoiceResponse, error) {
+	invoice, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.IsDeleted {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+			WithDetail("invoice_id", id.String())
+	}
+
+	return &dto.InvoiceResponse{Invoice: invoice}, nil
+}
+
+// UpdateInvoice updates an existing invoice, replacing invoice_data when given.
+// The precondition must match the invoice's current version.
+func (s *invoiceService) UpdateInvoice(ctx context.Context, id uuid.UUID, req *dto.UpdateInvoiceRequest, precondition *concurrency.Precondition) (*dto.InvoiceResponse, error) {
+	// Get existing invoice
+	existingInvoice, err := s.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := precondition.Check(concurrency.VersionETag(existingInvoice.Version)); err != nil {
+		return nil, err
+	}
+
+	return s.applyUpdate(ctx, existingInvoice.Invoice, req, req.InvoiceData)
+}
+
+// PatchInvoice updates an existing invoice, merging invoice_data into the
+// stored payload as a JSON merge patch (RFC 7386): null removes a field
+func (s *invoiceService) PatchInvoice(ctx context.Context, id uuid.UUID, req *dto.UpdateInvoiceRequest, precondition *concurrency.Precondition) (*dto.InvoiceResponse, error) {
+	existingInvoice, err := s.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := precondition.Check(concurrency.VersionETag(existingInvoice.Version)); err != nil {
+		return nil, err
+	}
+
+	// Only totals present in the patch itself count as declared
+	patch := req.InvoiceData
+	if patch != nil {
+		req.InvoiceData = mergePatch(existingInvoice.InvoiceData, patch)
+	}
+
+	return s.applyUpdate(ctx, existingInvoice.Invoice, req, patch)
+}
+
+// applyUpdate validates and stores the changes of an update request. Totals
+// in declared are checked against the amounts computed from the line items.
+func (s *invoiceService) applyUpdate(ctx context.Context, existing *models.Invoice, req *dto.UpdateInvoiceRequest, declared models.InvoiceData) (*dto.InvoiceResponse, error) {
+	// Invoices under approval keep the content they were submitted with
+	if s.approvals != nil {
+		if err := s.approvals.CheckEditable(ctx, existing); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply updates
+	updatedInvoice := *existing
+	if req.ProjectID != nil {
+		updatedInvoice.ProjectID = req.ProjectID
+	}
+	if req.ProviderID != nil {
+		updatedInvoice.ProviderID = req.ProviderID
+	}
+	if req.InvoiceData != nil {
+		if err := validateInvoiceData(req.InvoiceData); err != nil {
+			return nil, err
+		}
+		if err := preserveStatus(req.InvoiceData, existing.Status); err != nil {
+			return nil, err
+		}
+		if err := preserveSeriesNumber(req.InvoiceData, existing); err != nil {
+			return nil, err
+		}
+		updatedInvoice.InvoiceData = req.InvoiceData
+	}
+	if req.LineItems != nil {
+		lineItems, err := lineItemsFromRequest(req.LineItems)
+		if err != nil {
+			return nil, err
+		}
+		updatedInvoice.LineItems = lineItems
+	}
+
+	// Recompute totals whenever the payload or the lines change
+	if req.InvoiceData != nil || req.LineItems != nil {
+		if err := applyTotals(&updatedInvoice, declared); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.InvoiceData != nil || req.LineItems != nil {
+		if err := s.checkAdjustment(ctx, &updatedInvoice); err != nil {
+			return nil, err
+		}
+	}
+
+	// Re-validate whenever the payload, its totals or the project scope change
+	if req.InvoiceData != nil || req.LineItems != nil || req.ProjectID != nil {
+		schemaVersion, err := s.types.ValidateInvoice(ctx, updatedInvoice.InvoiceTypeID, updatedInvoice.OrganizationID,
+			updatedInvoice.ProjectID, updatedInvoice.InvoiceData)
+		if err != nil {
+			return nil, err
+		}
+		updatedInvoice.SchemaVersion = schemaVersion
+	}
+
+	// Every write bumps the version so the ETag changes even when only
+	// references are updated (the sync trigger covers invoice_data changes)
+	updatedInvoice.Version = existing.Version + 1
+	updatedInvoice.UpdatedBy = req.UpdatedBy
+	updatedInvoice.UpdatedAt = time.Now()
+
+	result, err := s.repo.Update(ctx, existing.ID, &updatedInvoice, existing.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceResponse{Invoice: result}, nil
+}
+
+// DeleteInvoice soft deletes an invoice. It keeps its number and can be
+// restored until it is purged. Invoices submitted to the tax authority are
+// legal records and cannot be deleted.
+func (s *invoiceService) DeleteInvoice(ctx context.Context, id uuid.UUID) error {
+	// Check if invoice exists
+	existing, err := s.GetInvoice(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkNotSubmitted(existing.Invoice); err != nil {
+		return err
+	}
+
+	// Notes, payments and live payment batches must be removed before the
+	// invoice they settle
+	if !existing.DocumentKind.IsAdjustment() {
+		balance, err := s.repo.GetBalance(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkUnsettled(balance); err != nil {
+			return err
+		}
+	}
+
+	if s.approvals != nil {
+		if err := s.approvals.CheckEditable(ctx, existing.Invoice); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// DiscardInvoice permanently removes an invoice and its attachments. It
+// undoes CreateInvoice for callers whose writes following the creation
+// failed, before anything else refers to the invoice; a number assigned by
+// a numbering series is lost with it.
+func (s *invoiceService) DiscardInvoice(ctx context.Context, id uuid.UUID) error {
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkNotSubmitted(existing); err != nil {
+		return err
+	}
+
+	if err := s.attachments.DeleteInvoiceAttachments(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.HardDelete(ctx, id)
+}
+
+// RestoreInvoice brings back a soft deleted invoice
+func (s *invoiceService) RestoreInvoice(ctx context.Context, id uuid.UUID) (*dto.InvoiceResponse, error) {
+	restored, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		if invoices.IsInvoiceNotDeleted(err) {
+			// Tell a missing invoice apart from one that is not deleted
+			if _, getErr := s.repo.GetByID(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+		}
+		return nil, err
+	}
+
+	return &dto.InvoiceResponse{Invoice: restored}, nil
+}
+
+// PurgeInvoices permanently removes the organization's invoices that have
+// been soft deleted for longer than the retention window, with their
+// attachments. Invoices submitted to the tax authority are never purged,
+// nor are invoices that payments, payment batches or remaining notes refer
+// to. Only organization admins may purge.
+func (s *invoiceService) PurgeInvoices(ctx context.Context, req *dto.PurgeRequest) (*dto.PurgeResponse, error) {
+	if req.OrganizationID == uuid.Nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "organization_id").
+			WithDetail("reason", "required")
+	}
+	if req.PurgedBy == uuid.Nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "purged_by").
+			WithDetail("reason", "required")
+	}
+
+	isAdmin, err := s.repo.HasOrganizationRole(ctx, req.OrganizationID, req.PurgedBy, organization.RoleOrgAdmin.Name())
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrPurgeForbidden).
+			WithDetail("organization_id", req.OrganizationID.String()).
+			WithDetail("user_id", req.PurgedBy.String())
+	}
+
+	// The retention window can only be widened by the caller
+	cutoff := time.Now().Add(-s.purgeRetention)
+	if req.DeletedBefore != nil {
+		if req.DeletedBefore.After(cutoff) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+				WithDetail("field", "deleted_before").
+				WithDetail("reason", "inside_retention_window").
+				WithDetail("latest_allowed", cutoff.Format(time.RFC3339))
+		}
+		cutoff = *req.DeletedBefore
+	}
+
+	ids, keys, err := s.repo.Purge(ctx, req.OrganizationID, cutoff, req.PurgedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// The purge is committed; objects left behind by a failing blob store
+	// only take space
+	if err := s.attachments.ReleaseObjects(ctx, keys); err != nil {
+		log.Printf("purging invoices of organization %s: releasing attachments: %v", req.OrganizationID, err)
+	}
+
+	return &dto.PurgeResponse{
+		OrganizationID: req.OrganizationID,
+		Cutoff:         cutoff,
+		PurgedCount:    len(ids),
+		PurgedIDs:      ids,
+	}, nil
+}
+
+// ListInvoices lists invoices with pagination and filtering
+func (s *invoiceService) ListInvoices(ctx context.Context, req *dto.InvoiceListRequest) (*dto.InvoiceListResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+	if req.SortBy == "" {
+		req.SortBy = "created_at"
+	}
+	if req.SortOrder == "" {
+		req.SortOrder = "desc"
+	}
+
+	if err := checkListFilters(req); err != nil {
+		return nil, err
+	}
+	if err := s.compileDataFilter(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx, req)
+}
+
+// SearchInvoices runs a full-text search over invoice numbers, provider
+// names, line descriptions and notes, narrowed by the list filters
+func (s *invoiceService) SearchInvoices(ctx context.Context, req *dto.InvoiceSearchRequest) (*dto.InvoiceSearchResponse, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "q").
+			WithDetail("reason", "required")
+	}
+	if utf8.RuneCountInString(req.Query) > MaxSearchQueryLength {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "q").
+			WithDetail("reason", "too_long").
+			WithDetail("max_length", MaxSearchQueryLength)
+	}
+
+	if req.Language == "" {
+		req.Language = models.SearchLanguageSpanish
+	}
+	if !slices.Contains(models.SearchLanguages, req.Language) {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "language").
+			WithDetail("expected", models.SearchLanguages)
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+	if err := checkListFilters(&req.InvoiceListRequest); err != nil {
+		return nil, err
+	}
+	if err := s.compileDataFilter(ctx, &req.InvoiceListRequest); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Search(ctx, req)
+}
+
+// compileDataFilter parses the filter expression of a listing and checks its
+// fields against the schema of the filtered invoice type
+func (s *invoiceService) compileDataFilter(ctx context.Context, req *dto.InvoiceListRequest) error {
+	req.DataFilter = nil
+	if strings.TrimSpace(req.Filter) == "" {
+		return nil
+	}
+	if req.InvoiceTypeID == nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "filter").
+			WithDetail("reason", "invoice_type_id is required to filter on invoice_data fields")
+	}
+
+	filter, err := jsonfilter.Parse(req.Filter)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := s.types.GetSchema(ctx, *req.InvoiceTypeID)
+	if err != nil {
+		return err
+	}
+	if err := filter.Check(jsonfilter.SchemaFields(compiled)); err != nil {
+		return err
+	}
+
+	req.DataFilter = filter
+	return nil
+}
+
+// checkListFilters rejects ranges whose bounds are the wrong way round
+func checkListFilters(req *dto.InvoiceListRequest) error {
+	if req.DateFrom != nil && req.DateTo != nil && req.DateFrom.After(*req.DateTo) {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "date_from").
+			WithDetail("reason", "after_date_to")
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "min_amount").
+			WithDetail("reason", "greater_than_max_amount")
+	}
+	return nil
+}
+
+// TransitionInvoice moves an invoice to another status if the invoice type
+// workflow allows it, recording who made the change
+func (s *invoiceService) TransitionInvoice(ctx context.Context, id uuid.UUID, req *dto.TransitionRequest) (*dto.TransitionResponse, error) {
+	if req.ToStatus == "" {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "to_status").
+			WithDetail("reason", "required")
+	}
+	if req.TransitionedBy == uuid.Nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "transitioned_by").
+			WithDetail("reason", "required")
+	}
+
+	existing, err := s.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.types.GetStatusWorkflow(ctx, existing.InvoiceTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invoices stored before the workflow existed count as being in the initial status
+	from := workflow.InitialStatus
+	if existing.Status != nil {
+		from = *existing.Status
+	}
+
+	if !workflow.HasStatus(req.ToStatus) {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvalidStatus).
+			WithDetail("status", req.ToStatus).
+			WithDetail("statuses", workflow.Statuses)
+	}
+	if !workflow.CanTransition(from, req.ToStatus) {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvalidStatusTransition).
+			WithDetail("invoice_id", id.String()).
+			WithDetail("from_status", from).
+			WithDetail("to_status", req.ToStatus).
+			WithDetail("allowed_transitions", workflow.AllowedTransitions(from))
+	}
+
+	if s.approvals != nil {
+		if err := s.approvals.CheckTransition(ctx, existing.Invoice, req.ToStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	transition := &models.StatusTransition{
+		InvoiceID:      id,
+		OrganizationID: existing.OrganizationID,
+		FromStatus:     existing.Status,
+		ToStatus:       req.ToStatus,
+		Comment:        req.Comment,
+		TransitionedBy: req.TransitionedBy,
+		TransitionedAt: time.Now(),
+	}
+
+	updated, err := s.repo.Transition(ctx, existing.Invoice, transition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TransitionResponse{
+		Invoice:            updated,
+		Transition:         transition,
+		AllowedTransitions: workflow.AllowedTransitions(req.ToStatus),
+	}, nil
+}
+
+// ListTransitions returns the status history of an invoice
+func (s *invoiceService) ListTransitions(ctx context.Context, id uuid.UUID) (*dto.TransitionListResponse, error) {
+	existing, err := s.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.types.GetStatusWorkflow(ctx, existing.InvoiceTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions, err := s.repo.ListTransitions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current := workflow.InitialStatus
+	if existing.Status != nil {
+		current = *existing.Status
+	}
+
+	return &dto.TransitionListResponse{
+		Status:             existing.Status,
+		AllowedTransitions: workflow.AllowedTransitions(current),
+		Transitions:        transitions,
+	}, nil
+}
+
+// Validation helpers
+
+func (s *invoiceService) validateCreateRequest(req *dto.CreateInvoiceRequest) error {
+	if req.OrganizationID == uuid.Nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "organization_id").
+			WithDetail("reason", "required")
+	}
+
+	if req.InvoiceTypeID == uuid.Nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "invoice_type_id").
+			WithDetail("reason", "required")
+	}
+
+	if len(req.InvoiceData) == 0 {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "invoice_data").
+			WithDetail("reason", "required")
+	}
+
+	return validateInvoiceData(req.InvoiceData)
+}
+
+// checkUnsettled fails when notes, payments or a live payment batch still
+// settle the invoice
+func checkUnsettled(balance *models.Balance) error {
+	id := balance.InvoiceID.String()
+	switch {
+	case balance.HasAdjustments():
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceHasAdjustments).
+			WithDetail("invoice_id", id).
+			WithDetail("credit_note_count", balance.CreditNoteCount).
+			WithDetail("debit_note_count", balance.DebitNoteCount)
+	case balance.HasPayments():
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceHasPayments).
+			WithDetail("invoice_id", id).
+			WithDetail("payment_count", balance.PaymentCount)
+	case balance.IsScheduled():
+		return invoices.InvoicesErrors.New(invoices.ErrInvoicePaymentScheduled).
+			WithDetail("invoice_id", id).
+			WithDetail("payment_batch_id", balance.PaymentBatchID.String())
+	}
+
+	return nil
+}
+
+// checkNotSubmitted fails for invoices submitted to the tax authority,
+// whose submissions and CDRs must be kept
+func checkNotSubmitted(invoice *models.Invoice) error {
+	if invoice.TaxAuthorityStatus == nil {
+		return nil
+	}
+	return invoices.InvoicesErrors.New(invoices.ErrInvoiceSubmitted).
+		WithDetail("invoice_id", invoice.ID.String()).
+		WithDetail("tax_authority_status", *invoice.TaxAuthorityStatus)
+}
+
+// validateInvoiceData checks the well-known fields that the database extracts
+// from invoice_data, so that malformed values fail with a validation error
+// instead of a cast error inside the sync trigger
+func validateInvoiceData(data models.InvoiceData) error {
+	var invoiceDate, dueDate time.Time
+
+	for _, field := range []string{models.FieldInvoiceDate, models.FieldDueDate} {
+		raw, ok := data[field]
+		if !ok || raw == nil {
+			continue
+		}
+		str, isString := raw.(string)
+		parsed, err := time.Parse(models.DateLayout, str)
+		if !isString || err != nil {
+			return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+				WithDetail("field", "invoice_data."+field).
+				WithDetail("reason", "invalid_date").
+				WithDetail("expected", models.DateLayout)
+		}
+		if field == models.FieldInvoiceDate {
+			invoiceDate = parsed
+		} else {
+			dueDate = parsed
+		}
+	}
+
+	if !invoiceDate.IsZero() && !dueDate.IsZero() && dueDate.Before(invoiceDate) {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("field", "invoice_data."+models.FieldDueDate).
+			WithDetail("reason", "before_invoice_date")
+	}
+
+	if raw, ok := data[models.FieldTotalAmount]; ok && raw != nil {
+		amount, ok := numericValue(raw)
+		if !ok {
+			return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+				WithDetail("field", "invoice_data."+models.FieldTotalAmount).
+				WithDetail("reason", "not_a_number")
+		}
+		if amount < 0 {
+			return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+				WithDetail("field", "invoice_data."+models.FieldTotalAmount).
+				WithDetail("reason", "negative")
+		}
+	}
+
+	if raw, ok := data[models.FieldCurrencyCode]; ok && raw != nil {
+		code, isString := raw.(string)
+		if !isString || len(code) != 3 {
+			return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+				WithDetail("field", "invoice_data."+models.FieldCurrencyCode).
+				WithDetail("reason", "invalid_currency_code").
+				WithDetail("expected", "ISO 4217 three-letter code")
+		}
+	}
+
+	return nil
+}
+
+// applyInitialStatus sets the workflow's initial status on a new invoice
+// payload, rejecting payloads that try to start in any other status
+func applyInitialStatus(data models.InvoiceData, workflow *typemodels.StatusWorkflow) error {
+	raw, ok := data[models.FieldStatus]
+	if !ok || raw == nil {
+		data[models.FieldStatus] = workflow.InitialStatus
+		return nil
+	}
+
+	if status, isString := raw.(string); !isString || status != workflow.InitialStatus {
+		return invoices.InvoicesErrors.New(invoices.ErrInvalidStatus).
+			WithDetail("field", "invoice_data."+models.FieldStatus).
+			WithDetail("status", raw).
+			WithDetail("expected", workflow.InitialStatus)
+	}
+
+	return nil
+}
+
+// preserveStatus keeps the current status in a replacement payload; status
+// changes have to go through TransitionInvoice
+func preserveStatus(data models.InvoiceData, current *string) error {
+	raw, ok := data[models.FieldStatus]
+	if !ok || raw == nil {
+		if current != nil {
+			data[models.FieldStatus] = *current
+		}
+		return nil
+	}
+
+	if status, isString := raw.(string); !isString || current == nil || status != *current {
+		return invoices.InvoicesErrors.New(invoices.ErrInvalidStatus).
+			WithDetail("field", "invoice_data."+models.FieldStatus).
+			WithDetail("status", raw).
+			WithDetail("reason", "use_transitions_endpoint")
+	}
+
+	return nil
+}
+
+// preserveSeriesNumber keeps the number a series assigned in a replacement
+// payload; it cannot be changed afterwards
+func preserveSeriesNumber(data models.InvoiceData, existing *models.Invoice) error {
+	if existing.SeriesID == nil || existing.InvoiceNumber == nil {
+		return nil
+	}
+
+	raw, ok := data[models.FieldInvoiceNumber]
+	if !ok || raw == nil {
+		data[models.FieldInvoiceNumber] = *existing.InvoiceNumber
+		return nil
+	}
+
+	if number, isString := raw.(string); !isString || number != *existing.InvoiceNumber {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceNumbered).
+			WithDetail("field", "invoice_data."+models.FieldInvoiceNumber).
+			WithDetail("invoice_number", *existing.InvoiceNumber)
+	}
+
+	return nil
+}
+
+// mergePatch applies a JSON merge patch to a copy of target
+func mergePatch(target map[string]any, patch map[string]any) map[string]any {
+	result := make(map[string]any, len(target)+len(patch))
+	for key, value := range target {
+		result[key] = value
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		patchObject, isObject := value.(map[string]any)
+		if !isObject {
+			result[key] = value
+			continue
+		}
+		targetObject, _ := result[key].(map[string]any)
+		result[key] = mergePatch(targetObject, patchObject)
+	}
+
+	return result
+}
+
+// numericValue converts a JSON number or numeric string into a float64
+func numericValue(raw any) (float64, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,47 @@
+package invoicesrv
+
+import (
+	"testing"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+func TestCheckUnsettled(t *testing.T) {
+	batchID := uuid.New()
+
+	tests := []struct {
+		name    string
+		balance models.Balance
+		code    errx.Code // empty when the invoice can be deleted
+	}{
+		{"unsettled", models.Balance{}, ""},
+		{"credit note", models.Balance{CreditNoteCount: 1}, invoices.ErrInvoiceHasAdjustments},
+		{"debit note", models.Balance{DebitNoteCount: 2}, invoices.ErrInvoiceHasAdjustments},
+		{"payment", models.Balance{PaymentCount: 1}, invoices.ErrInvoiceHasPayments},
+		{"payment batch", models.Balance{PaymentBatchID: &batchID}, invoices.ErrInvoicePaymentScheduled},
+		{"notes before payments", models.Balance{CreditNoteCount: 1, PaymentCount: 1, PaymentBatchID: &batchID}, invoices.ErrInvoiceHasAdjustments},
+		{"payments before batches", models.Balance{PaymentCount: 1, PaymentBatchID: &batchID}, invoices.ErrInvoiceHasPayments},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.balance.InvoiceID = uuid.New()
+			err := checkUnsettled(&tt.balance)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("checkUnsettled: %v", err)
+				}
+				return
+			}
+			if !errx.IsCode(err, tt.code) {
+				t.Fatalf("err = %v; want %s", err, tt.code)
+			}
+			if got := err.(*errx.Error).Details["invoice_id"]; got != tt.balance.InvoiceID.String() {
+				t.Errorf("invoice_id = %v; want %s", got, tt.balance.InvoiceID)
+			}
+		})
+	}
+}
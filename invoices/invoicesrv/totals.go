@@ -0,0 +1,135 @@
+package invoicesrv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/invoices/totals"
+)
+
+// maxLineItems bounds the number of lines a single invoice may carry
+const maxLineItems = 1000
+
+// lineItemsFromRequest converts request lines into line item models
+func lineItemsFromRequest(reqs []dto.LineItemRequest) ([]models.LineItem, error) {
+	if len(reqs) > maxLineItems {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvalidLineItem).
+			WithDetail("field", "line_items").
+			WithDetail("reason", "too_many_lines").
+			WithDetail("max", maxLineItems)
+	}
+
+	items := make([]models.LineItem, 0, len(reqs))
+	for i, req := range reqs {
+		if req.Description == "" {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvalidLineItem).
+				WithDetail("field", fmt.Sprintf("line_items[%d].description", i)).
+				WithDetail("reason", "required")
+		}
+
+		items = append(items, models.LineItem{
+			Position:        i + 1,
+			ItemCode:        req.ItemCode,
+			Description:     req.Description,
+			Quantity:        req.Quantity,
+			UnitCode:        req.UnitCode,
+			UnitPrice:       req.UnitPrice,
+			DiscountPercent: req.DiscountPercent,
+			DiscountAmount:  req.DiscountAmount,
+			TaxCode:         req.TaxCode,
+			TaxRate:         req.TaxRate,
+		})
+	}
+
+	return items, nil
+}
+
+// applyTotals computes the amounts of the invoice's line items, rejects
+// declared totals that disagree with them and writes the computed total into
+// invoice_data so the database extracts it into total_amount. Invoices
+// without lines keep their declared total_amount.
+func applyTotals(invoice *models.Invoice, declared models.InvoiceData) error {
+	if len(invoice.LineItems) == 0 {
+		invoice.SubtotalAmount = nil
+		invoice.DiscountAmount = nil
+		invoice.TaxAmount = nil
+		invoice.TaxBreakdown = nil
+		return nil
+	}
+
+	lines := make([]totals.Line, len(invoice.LineItems))
+	for i, item := range invoice.LineItems {
+		lines[i] = item.TotalsLine()
+	}
+
+	result, err := totals.Calculate(lines)
+	if err != nil {
+		var lineErr *totals.Error
+		if errors.As(err, &lineErr) {
+			return invoices.InvoicesErrors.New(invoices.ErrInvalidLineItem).
+				WithDetail("field", fmt.Sprintf("line_items[%d].%s", lineErr.Index, lineErr.Field)).
+				WithDetail("reason", lineErr.Message)
+		}
+		return err
+	}
+
+	checks := []struct {
+		field    string
+		computed decimal.Decimal
+	}{
+		{models.FieldSubtotal, result.Subtotal},
+		{models.FieldTaxTotal, result.TaxTotal},
+		{models.FieldTotalAmount, result.Total},
+	}
+	for _, check := range checks {
+		field, computed := check.field, check.computed
+		raw, ok := declared[field]
+		if !ok || raw == nil {
+			continue
+		}
+		amount, ok := decimalValue(raw)
+		if !ok || !amount.Equal(computed) {
+			return invoices.InvoicesErrors.New(invoices.ErrTotalsMismatch).
+				WithDetail("field", "invoice_data."+field).
+				WithDetail("declared", raw).
+				WithDetail("computed", computed.StringFixed(totals.Scale))
+		}
+	}
+
+	for i, amounts := range result.Lines {
+		item := &invoice.LineItems[i]
+		item.Position = i + 1
+		item.GrossAmount = amounts.Gross
+		item.NetAmount = amounts.Net
+		item.TaxAmount = amounts.Tax
+		item.LineTotal = amounts.Total
+	}
+
+	invoice.SubtotalAmount = &result.Subtotal
+	invoice.DiscountAmount = &result.DiscountTotal
+	invoice.TaxAmount = &result.TaxTotal
+	invoice.TaxBreakdown = result.Taxes
+
+	// Stored as a JSON number; DECIMAL(15,2) values are exact in a float64
+	invoice.InvoiceData[models.FieldTotalAmount] = result.Total.InexactFloat64()
+
+	return nil
+}
+
+// decimalValue converts a JSON number or numeric string into a decimal
+func decimalValue(raw any) (decimal.Decimal, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return decimal.NewFromFloat(v), true
+	case string:
+		d, err := decimal.NewFromString(v)
+		return d, err == nil
+	default:
+		return decimal.Zero, false
+	}
+}
@@ -0,0 +1,106 @@
+// Package jsondiff compares decoded JSON documents and reports the changes
+// between them as a flat list addressed by JSON Pointer (RFC 6901). Objects
+// are compared key by key and arrays element by element, so the result reads
+// like the operations of a JSON Patch (RFC 6902) without moves or copies.
+package jsondiff
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of change
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Change describes a value that differs between two documents. From is nil
+// for additions and To is nil for removals. The whole document has the
+// empty path.
+type Change struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	From any    `json:"from"`
+	To   any    `json:"to"`
+}
+
+// Diff returns the changes that turn from into to, depth first: object keys
+// in lexical order and array elements in index order, so /items/2 comes
+// before /items/10. Both values are expected to be decoded with
+// encoding/json (maps, slices, strings, float64, bools and nil).
+func Diff(from, to any) []Change {
+	changes := []Change{}
+	diff("", from, to, &changes)
+	return changes
+}
+
+func diff(path string, from, to any, changes *[]Change) {
+	switch f := from.(type) {
+	case map[string]any:
+		if t, ok := to.(map[string]any); ok {
+			diffObjects(path, f, t, changes)
+			return
+		}
+	case []any:
+		if t, ok := to.([]any); ok {
+			diffArrays(path, f, t, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Op: OpReplace, Path: path, From: from, To: to})
+	}
+}
+
+func diffObjects(path string, from, to map[string]any, changes *[]Change) {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := path + "/" + escape(key)
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+
+		switch {
+		case !inFrom:
+			*changes = append(*changes, Change{Op: OpAdd, Path: child, To: toValue})
+		case !inTo:
+			*changes = append(*changes, Change{Op: OpRemove, Path: child, From: fromValue})
+		default:
+			diff(child, fromValue, toValue, changes)
+		}
+	}
+}
+
+func diffArrays(path string, from, to []any, changes *[]Change) {
+	for i := 0; i < len(from) || i < len(to); i++ {
+		child := path + "/" + strconv.Itoa(i)
+
+		switch {
+		case i >= len(from):
+			*changes = append(*changes, Change{Op: OpAdd, Path: child, To: to[i]})
+		case i >= len(to):
+			*changes = append(*changes, Change{Op: OpRemove, Path: child, From: from[i]})
+		default:
+			diff(child, from[i], to[i], changes)
+		}
+	}
+}
+
+// escape encodes a key as a JSON Pointer reference token
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
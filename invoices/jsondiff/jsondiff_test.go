@@ -0,0 +1,146 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, document string) any {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []Change
+	}{
+		{
+			name: "equal documents",
+			from: `{"a": 1, "b": [1, {"c": null}]}`,
+			to:   `{"b": [1, {"c": null}], "a": 1}`,
+			want: []Change{},
+		},
+		{
+			name: "key added",
+			from: `{"a": 1}`,
+			to:   `{"a": 1, "b": {"c": true}}`,
+			want: []Change{{Op: OpAdd, Path: "/b", To: map[string]any{"c": true}}},
+		},
+		{
+			name: "key removed",
+			from: `{"a": 1, "b": "x"}`,
+			to:   `{"a": 1}`,
+			want: []Change{{Op: OpRemove, Path: "/b", From: "x"}},
+		},
+		{
+			name: "value replaced",
+			from: `{"a": 1, "b": "x"}`,
+			to:   `{"a": 2, "b": "x"}`,
+			want: []Change{{Op: OpReplace, Path: "/a", From: 1.0, To: 2.0}},
+		},
+		{
+			name: "null replaced",
+			from: `{"a": null}`,
+			to:   `{"a": 0}`,
+			want: []Change{{Op: OpReplace, Path: "/a", From: nil, To: 0.0}},
+		},
+		{
+			name: "nested keys in lexical order",
+			from: `{"z": {"b": 1, "a": 1}, "m": 1}`,
+			to:   `{"z": {"b": 2, "a": 2, "c": 2}, "m": 1}`,
+			want: []Change{
+				{Op: OpReplace, Path: "/z/a", From: 1.0, To: 2.0},
+				{Op: OpReplace, Path: "/z/b", From: 1.0, To: 2.0},
+				{Op: OpAdd, Path: "/z/c", To: 2.0},
+			},
+		},
+		{
+			name: "array grows",
+			from: `{"items": [1, 2]}`,
+			to:   `{"items": [1, 2, 3, 4]}`,
+			want: []Change{
+				{Op: OpAdd, Path: "/items/2", To: 3.0},
+				{Op: OpAdd, Path: "/items/3", To: 4.0},
+			},
+		},
+		{
+			name: "array shrinks",
+			from: `{"items": [1, 2, 3]}`,
+			to:   `{"items": [1]}`,
+			want: []Change{
+				{Op: OpRemove, Path: "/items/1", From: 2.0},
+				{Op: OpRemove, Path: "/items/2", From: 3.0},
+			},
+		},
+		{
+			name: "array elements compared by index",
+			from: `[{"qty": 1}, {"qty": 2}]`,
+			to:   `[{"qty": 1}, {"qty": 5}]`,
+			want: []Change{{Op: OpReplace, Path: "/1/qty", From: 2.0, To: 5.0}},
+		},
+		{
+			name: "indices in numeric order",
+			from: `[0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]`,
+			to:   `[0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1]`,
+			want: []Change{
+				{Op: OpReplace, Path: "/2", From: 0.0, To: 1.0},
+				{Op: OpReplace, Path: "/10", From: 0.0, To: 1.0},
+			},
+		},
+		{
+			name: "object becomes a scalar",
+			from: `{"a": {"b": 1}}`,
+			to:   `{"a": "b"}`,
+			want: []Change{{Op: OpReplace, Path: "/a", From: map[string]any{"b": 1.0}, To: "b"}},
+		},
+		{
+			name: "scalar becomes an object",
+			from: `{"a": 1}`,
+			to:   `{"a": {"b": 1}}`,
+			want: []Change{{Op: OpReplace, Path: "/a", From: 1.0, To: map[string]any{"b": 1.0}}},
+		},
+		{
+			name: "array becomes an object",
+			from: `{"a": [1]}`,
+			to:   `{"a": {"0": 1}}`,
+			want: []Change{{Op: OpReplace, Path: "/a", From: []any{1.0}, To: map[string]any{"0": 1.0}}},
+		},
+		{
+			name: "whole document replaced",
+			from: `[1]`,
+			to:   `"x"`,
+			want: []Change{{Op: OpReplace, Path: "", From: []any{1.0}, To: "x"}},
+		},
+		{
+			name: "keys with ~ and / escaped",
+			from: `{"a/b": 1, "m~n": {"~/": 1}}`,
+			to:   `{"a/b": 2, "m~n": {"~/": 2}}`,
+			want: []Change{
+				{Op: OpReplace, Path: "/a~1b", From: 1.0, To: 2.0},
+				{Op: OpReplace, Path: "/m~0n/~0~1", From: 1.0, To: 2.0},
+			},
+		},
+		{
+			name: "empty key",
+			from: `{"": 1}`,
+			to:   `{}`,
+			want: []Change{{Op: OpRemove, Path: "/", From: 1.0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(decode(t, tt.from), decode(t, tt.to))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// VoidStatus is the status of the default workflow for cancelled documents.
+// Voided credit and debit notes no longer adjust their original invoice.
+const VoidStatus = "void"
+
+// Payment states derived from an invoice's balance
+const (
+	PaymentStateUnpaid        = "unpaid"
+	PaymentStatePartiallyPaid = "partially_paid"
+	PaymentStatePaid          = "paid"
+)
+
+// Balance is what remains owed on an invoice after its live credit and debit
+// notes and the payments allocated to it: total − credited + debited − paid.
+// It is read from the invoice_balances view.
+type Balance struct {
+	InvoiceID         uuid.UUID       `db:"invoice_id" json:"invoice_id"`
+	CurrencyCode      *string         `db:"currency_code" json:"currency_code"`
+	TotalAmount       decimal.Decimal `db:"total_amount" json:"total_amount"`
+	CreditedAmount    decimal.Decimal `db:"credited_amount" json:"credited_amount"`
+	DebitedAmount     decimal.Decimal `db:"debited_amount" json:"debited_amount"`
+	CreditNoteCount   int             `db:"credit_note_count" json:"credit_note_count"`
+	DebitNoteCount    int             `db:"debit_note_count" json:"debit_note_count"`
+	PaidAmount        decimal.Decimal `db:"paid_amount" json:"paid_amount"`
+	PaymentCount      int             `db:"payment_count" json:"payment_count"`
+	OutstandingAmount decimal.Decimal `db:"outstanding_amount" json:"outstanding_amount"`
+	PaymentState      string          `db:"payment_state" json:"payment_state"`
+	ScheduledAmount   decimal.Decimal `db:"scheduled_amount" json:"scheduled_amount"`
+	PaymentBatchID    *uuid.UUID      `db:"payment_batch_id" json:"payment_batch_id,omitempty"`
+}
+
+// HasAdjustments reports whether any live note adjusts the invoice
+func (b Balance) HasAdjustments() bool {
+	return b.CreditNoteCount > 0 || b.DebitNoteCount > 0
+}
+
+// HasPayments reports whether payments that are not voided settle part of
+// the invoice
+func (b Balance) HasPayments() bool {
+	return b.PaymentCount > 0
+}
+
+// IsScheduled reports whether the invoice is part of a payment batch that was
+// generated or sent to the bank but not yet completed
+func (b Balance) IsScheduled() bool {
+	return b.PaymentBatchID != nil
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// Well-known keys inside invoice_data that the database extracts into
+// dedicated columns (see sync_invoice_fields in migration 002)
+const (
+	FieldInvoiceNumber = "invoice_number"
+	FieldInvoiceDate   = "invoice_date"
+	FieldDueDate       = "due_date"
+	FieldTotalAmount   = "total_amount"
+	FieldCurrencyCode  = "currency_code"
+	FieldStatus        = "status"
+)
+
+// DateLayout is the layout used for date fields inside invoice_data
+const DateLayout = "2006-01-02"
+
+// Invoice represents an invoice in the system
+type Invoice struct {
+	ID             uuid.UUID   `db:"id" json:"id"`
+	InvoiceData    InvoiceData `db:"invoice_data" json:"invoice_data"`
+	InvoiceTypeID  uuid.UUID   `db:"invoice_type_id" json:"invoice_type_id"`
+	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
+	ProjectID      *uuid.UUID  `db:"project_id" json:"project_id"`
+	ProviderID     *uuid.UUID  `db:"provider_id" json:"provider_id"`
+
+	// Fields extracted from invoice_data by the database
+	InvoiceNumber *string    `db:"invoice_number" json:"invoice_number"`
+	InvoiceDate   *time.Time `db:"invoice_date" json:"invoice_date"`
+	DueDate       *time.Time `db:"due_date" json:"due_date"`
+	TotalAmount   *float64   `db:"total_amount" json:"total_amount"`
+	CurrencyCode  *string    `db:"currency_code" json:"currency_code"`
+	Status        *string    `db:"status" json:"status"`
+
+	// Numbering series that assigned invoice_number, if any
+	SeriesID     *uuid.UUID `db:"series_id" json:"series_id"`
+	SeriesNumber *int64     `db:"series_number" json:"series_number"`
+
+	// Credit and debit notes reference the invoice they adjust
+	DocumentKind         typemodels.DocumentKind `db:"document_kind" json:"document_kind"`
+	OriginalInvoiceID    *uuid.UUID              `db:"original_invoice_id" json:"original_invoice_id,omitempty"`
+	AdjustmentReasonCode *string                 `db:"adjustment_reason_code" json:"adjustment_reason_code,omitempty"`
+	AdjustmentReason     *string                 `db:"adjustment_reason" json:"adjustment_reason,omitempty"`
+
+	// Outcome of the latest submission to the tax authority, maintained by
+	// the electronic invoicing domain
+	TaxAuthorityStatus *string `db:"tax_authority_status" json:"tax_authority_status,omitempty"`
+
+	// Schema version of the invoice type that invoice_data conforms to
+	SchemaVersion string `db:"schema_version" json:"schema_version"`
+
+	// Amounts computed from line items; nil for invoices without lines
+	SubtotalAmount *decimal.Decimal `db:"subtotal_amount" json:"subtotal_amount"`
+	DiscountAmount *decimal.Decimal `db:"discount_amount" json:"discount_amount"`
+	TaxAmount      *decimal.Decimal `db:"tax_amount" json:"tax_amount"`
+	TaxBreakdown   TaxBreakdown     `db:"tax_breakdown" json:"tax_breakdown"`
+
+	Version   int        `db:"version" json:"version"`
+	IsDeleted bool       `db:"is_deleted" json:"is_deleted"`
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by"`
+	UpdatedBy *uuid.UUID `db:"updated_by" json:"updated_by"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
+
+	// Line items are stored in invoice_line_items
+	LineItems []LineItem `db:"-" json:"line_items,omitempty"`
+}
+
+// InvoiceData represents the complete invoice payload stored as JSONB
+type InvoiceData map[string]any
+
+// Value implements the driver.Valuer interface for database storage
+func (d InvoiceData) Value() (driver.Value, error) {
+	if d == nil {
+		return "{}", nil
+	}
+	return json.Marshal(d)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (d *InvoiceData) Scan(value any) error {
+	if value == nil {
+		*d = make(InvoiceData)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return fmt.Errorf("cannot scan %T into InvoiceData", value)
+	}
+}
+
+// TableName returns the table name for the Invoice model
+func (i Invoice) TableName() string {
+	return "invoices"
+}
+
+// Validate performs basic validation on the Invoice model
+func (i *Invoice) Validate() error {
+	if i.OrganizationID == uuid.Nil {
+		return fmt.Errorf("organization_id is required")
+	}
+	if i.InvoiceTypeID == uuid.Nil {
+		return fmt.Errorf("invoice_type_id is required")
+	}
+	if len(i.InvoiceData) == 0 {
+		return fmt.Errorf("invoice_data is required")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices/totals"
+)
+
+// Well-known keys inside invoice_data holding declared totals. When an
+// invoice has line items they must agree with the computed amounts.
+const (
+	FieldSubtotal = "subtotal"
+	FieldTaxTotal = "tax_total"
+)
+
+// LineItem represents a line of an invoice. Gross, net, tax and total
+// amounts are computed by the totals engine and stored for reporting.
+type LineItem struct {
+	ID              uuid.UUID       `db:"id" json:"id"`
+	InvoiceID       uuid.UUID       `db:"invoice_id" json:"invoice_id"`
+	Position        int             `db:"position" json:"position"`
+	ItemCode        *string         `db:"item_code" json:"item_code,omitempty"`
+	Description     string          `db:"description" json:"description"`
+	Quantity        decimal.Decimal `db:"quantity" json:"quantity"`
+	UnitCode        *string         `db:"unit_code" json:"unit_code,omitempty"`
+	UnitPrice       decimal.Decimal `db:"unit_price" json:"unit_price"`
+	DiscountPercent decimal.Decimal `db:"discount_percent" json:"discount_percent"`
+	DiscountAmount  decimal.Decimal `db:"discount_amount" json:"discount_amount"`
+	TaxCode         *string         `db:"tax_code" json:"tax_code,omitempty"`
+	TaxRate         decimal.Decimal `db:"tax_rate" json:"tax_rate"`
+
+	// Computed amounts
+	GrossAmount decimal.Decimal `db:"gross_amount" json:"gross_amount"`
+	NetAmount   decimal.Decimal `db:"net_amount" json:"net_amount"`
+	TaxAmount   decimal.Decimal `db:"tax_amount" json:"tax_amount"`
+	LineTotal   decimal.Decimal `db:"line_total" json:"line_total"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the table name for the LineItem model
+func (l LineItem) TableName() string {
+	return "invoice_line_items"
+}
+
+// TotalsLine converts the line item into the input of the totals engine
+func (l LineItem) TotalsLine() totals.Line {
+	line := totals.Line{
+		Quantity:        l.Quantity,
+		UnitPrice:       l.UnitPrice,
+		DiscountPercent: l.DiscountPercent,
+		DiscountAmount:  l.DiscountAmount,
+		TaxRate:         l.TaxRate,
+	}
+	if l.TaxCode != nil {
+		line.TaxCode = *l.TaxCode
+	}
+	return line
+}
+
+// TaxBreakdown lists the taxable and tax amounts per tax code and rate
+type TaxBreakdown []totals.TaxSubtotal
+
+// Value implements the driver.Valuer interface for database storage
+func (b TaxBreakdown) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return json.Marshal(b)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (b *TaxBreakdown) Scan(value any) error {
+	if value == nil {
+		*b = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	default:
+		return fmt.Errorf("cannot scan %T into TaxBreakdown", value)
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revision is the stored state of an invoice at one of its versions.
+// ChangedBy is nil for changes made by the system, such as schema migrations.
+type Revision struct {
+	ID             uuid.UUID   `db:"id" json:"id"`
+	InvoiceID      uuid.UUID   `db:"invoice_id" json:"invoice_id"`
+	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
+	Version        int         `db:"version" json:"version"`
+	InvoiceData    InvoiceData `db:"invoice_data" json:"invoice_data"`
+	ProjectID      *uuid.UUID  `db:"project_id" json:"project_id"`
+	ProviderID     *uuid.UUID  `db:"provider_id" json:"provider_id"`
+	SchemaVersion  string      `db:"schema_version" json:"schema_version"`
+	ChangedBy      *uuid.UUID  `db:"changed_by" json:"changed_by"`
+	ChangedAt      time.Time   `db:"changed_at" json:"changed_at"`
+}
+
+// TableName returns the table name for the Revision model
+func (r Revision) TableName() string {
+	return "invoice_revisions"
+}
+
+// Snapshot returns the versioned state as a JSON-like document so that two
+// revisions can be compared with jsondiff
+func (r Revision) Snapshot() map[string]any {
+	optionalID := func(id *uuid.UUID) any {
+		if id == nil {
+			return nil
+		}
+		return id.String()
+	}
+
+	return map[string]any{
+		"invoice_data":   map[string]any(r.InvoiceData),
+		"project_id":     optionalID(r.ProjectID),
+		"provider_id":    optionalID(r.ProviderID),
+		"schema_version": r.SchemaVersion,
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+// Languages invoices can be searched in, each backed by the Postgres text
+// search configuration of the same language (see migration 016)
+const (
+	SearchLanguageSpanish = "es"
+	SearchLanguageEnglish = "en"
+)
+
+// SearchLanguages lists the supported search languages
+var SearchLanguages = []string{SearchLanguageSpanish, SearchLanguageEnglish}
+
+// SearchResult is an invoice matching a full-text search. Snippet holds the
+// matching fragments of the searched text as HTML: the text is escaped and
+// the matches are wrapped in <mark> tags.
+type SearchResult struct {
+	Invoice
+	Rank    float64 `db:"rank" json:"rank"`
+	Snippet string  `db:"snippet" json:"snippet"`
+}
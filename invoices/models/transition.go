@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusTransition records a change of an invoice's status
+type StatusTransition struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	InvoiceID      uuid.UUID `db:"invoice_id" json:"invoice_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	FromStatus     *string   `db:"from_status" json:"from_status"`
+	ToStatus       string    `db:"to_status" json:"to_status"`
+	Comment        *string   `db:"comment" json:"comment,omitempty"`
+	TransitionedBy uuid.UUID `db:"transitioned_by" json:"transitioned_by"`
+	TransitionedAt time.Time `db:"transitioned_at" json:"transitioned_at"`
+}
+
+// TableName returns the table name for the StatusTransition model
+func (t StatusTransition) TableName() string {
+	return "invoice_status_transitions"
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// GetBalance computes the outstanding amount of an invoice after its live
+// credit and debit notes
+func (r *invoiceRepository) GetBalance(ctx context.Context, invoiceID uuid.UUID) (*models.Balance, error) {
+	balance, err := selectBalance(ctx, r.db, invoiceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+				WithDetail("invoice_id", invoiceID.String())
+		}
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return balance, nil
+}
+
+// ListAdjustments retrieves the live credit and debit notes of an invoice,
+// oldest first
+func (r *invoiceRepository) ListAdjustments(ctx context.Context, invoiceID uuid.UUID) ([]*models.Invoice, error) {
+	query := `
+		SELECT * FROM invoices
+		WHERE original_invoice_id = $1 AND is_deleted = false
+		ORDER BY created_at, id`
+
+	notes := []*models.Invoice{}
+	if err := r.db.SelectContext(ctx, &notes, query, invoiceID); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return notes, nil
+}
+
+// selectBalance reads the outstanding amount of an invoice from the
+// invoice_balances view
+func selectBalance(ctx context.Context, q sqlx.QueryerContext, invoiceID uuid.UUID) (*models.Balance, error) {
+	query := `
+		SELECT invoice_id, currency_code, total_amount, credited_amount, debited_amount,
+			credit_note_count, debit_note_count, paid_amount, payment_count,
+			outstanding_amount, payment_state, scheduled_amount, payment_batch_id
+		FROM invoice_balances
+		WHERE invoice_id = $1`
+
+	var balance models.Balance
+	if err := sqlx.GetContext(ctx, q, &balance, query, invoiceID); err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// lockOriginal serializes writes of the notes adjusting an invoice so that
+// concurrent credit notes cannot together exceed its balance
+func lockOriginal(ctx context.Context, tx *sqlx.Tx, originalID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.GetContext(ctx, &id, `SELECT id FROM invoices WHERE id = $1 FOR UPDATE`, originalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invoices.InvoicesErrors.New(invoices.ErrInvoiceInvalidReference).
+				WithDetail("field", "original_invoice_id").
+				WithDetail("original_invoice_id", originalID.String())
+		}
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", originalID.String()).
+			WithCause(err)
+	}
+
+	return nil
+}
+
+// checkBalance fails when the notes written in the transaction leave the
+// invoice with a negative outstanding amount, counting what was already paid
+func checkBalance(ctx context.Context, tx *sqlx.Tx, invoiceID uuid.UUID) error {
+	balance, err := selectBalance(ctx, tx, invoiceID)
+	if err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return checkCredit(balance)
+}
+
+// checkCredit fails when credit notes and payments together exceed what the
+// invoice and its debit notes are worth
+func checkCredit(balance *models.Balance) error {
+	if !balance.OutstandingAmount.IsNegative() {
+		return nil
+	}
+
+	return invoices.InvoicesErrors.New(invoices.ErrCreditExceedsBalance).
+		WithDetail("invoice_id", balance.InvoiceID.String()).
+		WithDetail("total_amount", balance.TotalAmount.String()).
+		WithDetail("credited_amount", balance.CreditedAmount.String()).
+		WithDetail("debited_amount", balance.DebitedAmount.String()).
+		WithDetail("paid_amount", balance.PaidAmount.String()).
+		WithDetail("exceeded_by", balance.OutstandingAmount.Neg().String())
+}
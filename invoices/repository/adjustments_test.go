@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+func TestCheckCredit(t *testing.T) {
+	// balance mirrors the invoice_balances view: total − credited + debited − paid
+	balance := func(total, credited, debited, paid string) *models.Balance {
+		b := &models.Balance{
+			InvoiceID:      uuid.New(),
+			TotalAmount:    decimal.RequireFromString(total),
+			CreditedAmount: decimal.RequireFromString(credited),
+			DebitedAmount:  decimal.RequireFromString(debited),
+			PaidAmount:     decimal.RequireFromString(paid),
+		}
+		b.OutstandingAmount = b.TotalAmount.Sub(b.CreditedAmount).Add(b.DebitedAmount).Sub(b.PaidAmount)
+		return b
+	}
+
+	tests := []struct {
+		name       string
+		balance    *models.Balance
+		exceededBy string // empty when the credit fits
+	}{
+		{"no notes", balance("100", "0", "0", "0"), ""},
+		{"partial credit", balance("100", "40", "0", "0"), ""},
+		{"full credit", balance("100", "100", "0", "0"), ""},
+		{"credit over total", balance("100", "100.01", "0", "0"), "0.01"},
+		{"debit raises the cap", balance("100", "130", "30", "0"), ""},
+		{"debit not enough", balance("100", "150", "30", "0"), "20"},
+		{"paid then credited in full", balance("100", "60", "0", "40"), ""},
+		{"paid then credited over", balance("100", "80", "0", "40"), "20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCredit(tt.balance)
+			if tt.exceededBy == "" {
+				if err != nil {
+					t.Fatalf("checkCredit: %v", err)
+				}
+				return
+			}
+			if !errx.IsCode(err, invoices.ErrCreditExceedsBalance) {
+				t.Fatalf("err = %v; want %s", err, invoices.ErrCreditExceedsBalance)
+			}
+			details := err.(*errx.Error).Details
+			if got := details["exceeded_by"]; got != tt.exceededBy {
+				t.Errorf("exceeded_by = %v; want %s", got, tt.exceededBy)
+			}
+			if got := details["invoice_id"]; got != tt.balance.InvoiceID.String() {
+				t.Errorf("invoice_id = %v; want %s", got, tt.balance.InvoiceID)
+			}
+		})
+	}
+}
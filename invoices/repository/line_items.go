@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// insertLineItems stores the line items of an invoice, numbering them in order
+func insertLineItems(ctx context.Context, tx *sqlx.Tx, invoiceID uuid.UUID, items []models.LineItem) ([]models.LineItem, error) {
+	query := `
+		INSERT INTO invoice_line_items
+			(id, invoice_id, position, item_code, description, quantity, unit_code, unit_price,
+			 discount_percent, discount_amount, tax_code, tax_rate,
+			 gross_amount, net_amount, tax_amount, line_total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING *`
+
+	stored := make([]models.LineItem, 0, len(items))
+	for i, item := range items {
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+
+		var result models.LineItem
+		err := tx.GetContext(ctx, &result, query,
+			item.ID, invoiceID, i+1, item.ItemCode, item.Description, item.Quantity, item.UnitCode, item.UnitPrice,
+			item.DiscountPercent, item.DiscountAmount, item.TaxCode, item.TaxRate,
+			item.GrossAmount, item.NetAmount, item.TaxAmount, item.LineTotal)
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, result)
+	}
+
+	return stored, nil
+}
+
+// selectLineItems loads the line items of an invoice in position order
+func selectLineItems(ctx context.Context, q sqlx.QueryerContext, invoiceID uuid.UUID) ([]models.LineItem, error) {
+	query := `SELECT * FROM invoice_line_items WHERE invoice_id = $1 ORDER BY position`
+
+	var items []models.LineItem
+	if err := sqlx.SelectContext(ctx, q, &items, query, invoiceID); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
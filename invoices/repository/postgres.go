@@ -0,0 +1,689 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/craftable/storex"
+	"github.com/Abraxas-365/craftable/storex/providers/storexpostgres"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+	numberingpg "github.com/Abraxas-365/fuckturamelo/numbering/repository"
+)
+
+const (
+	DefaultPageSize  = 20
+	MaxPageSize      = 1000
+	DefaultSortField = "created_at"
+)
+
+// sortableFields lists the columns that may be used in ORDER BY clauses
+var sortableFields = map[string]bool{
+	"created_at":     true,
+	"updated_at":     true,
+	"invoice_date":   true,
+	"due_date":       true,
+	"invoice_number": true,
+	"total_amount":   true,
+	"status":         true,
+}
+
+// invoiceRepository implements InvoiceRepository using storex
+type invoiceRepository struct {
+	repo *storexpostgres.PgRepository[models.Invoice]
+	db   *sqlx.DB
+}
+
+// NewInvoiceRepository creates a new invoice repository
+func NewInvoiceRepository(db *sqlx.DB) InvoiceRepository {
+	repo := storexpostgres.NewPgRepository[models.Invoice](db, "invoices", "id")
+
+	return &invoiceRepository{
+		repo: repo,
+		db:   db,
+	}
+}
+
+// Create creates a new invoice together with its line items, taking its
+// number from the invoice's numbering series when it has one
+func (r *invoiceRepository) Create(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error) {
+	if invoice.ID == uuid.Nil {
+		invoice.ID = uuid.New()
+	}
+
+	createError := func(err error) error {
+		if mapped := mapConstraintError(err, invoice); mapped != nil {
+			return mapped
+		}
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceCreateFailed).
+			WithDetail("organization_id", invoice.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, createError(err)
+	}
+	defer tx.Rollback()
+
+	// Take the series number in this transaction so a failed insert gives it back
+	if invoice.SeriesID != nil {
+		assignment, err := numberingpg.AssignNext(ctx, tx, *invoice.SeriesID, invoice.OrganizationID, string(invoice.DocumentKind))
+		if err != nil {
+			return nil, err
+		}
+		invoice.InvoiceData[models.FieldInvoiceNumber] = assignment.Number
+		invoice.SeriesNumber = &assignment.Sequence
+	}
+
+	if invoice.OriginalInvoiceID != nil {
+		if err := lockOriginal(ctx, tx, *invoice.OriginalInvoiceID); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		INSERT INTO invoices
+			(id, invoice_data, invoice_type_id, organization_id, project_id, provider_id, series_id, series_number,
+			 document_kind, original_invoice_id, adjustment_reason_code, adjustment_reason,
+			 schema_version, subtotal_amount, discount_amount, tax_amount, tax_breakdown,
+			 version, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING *`
+
+	var result models.Invoice
+	err = tx.GetContext(ctx, &result, query,
+		invoice.ID, invoice.InvoiceData, invoice.InvoiceTypeID, invoice.OrganizationID,
+		invoice.ProjectID, invoice.ProviderID, invoice.SeriesID, invoice.SeriesNumber,
+		invoice.DocumentKind, invoice.OriginalInvoiceID, invoice.AdjustmentReasonCode, invoice.AdjustmentReason,
+		invoice.SchemaVersion, invoice.SubtotalAmount, invoice.DiscountAmount, invoice.TaxAmount, invoice.TaxBreakdown,
+		invoice.Version, invoice.CreatedBy, invoice.CreatedAt, invoice.UpdatedAt)
+	if err != nil {
+		return nil, createError(err)
+	}
+
+	if result.LineItems, err = insertLineItems(ctx, tx, result.ID, invoice.LineItems); err != nil {
+		return nil, createError(err)
+	}
+
+	if invoice.OriginalInvoiceID != nil {
+		if err := checkBalance(ctx, tx, *invoice.OriginalInvoiceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, createError(err)
+	}
+
+	return &result, nil
+}
+
+// GetByID retrieves an invoice by ID
+func (r *invoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	result, err := r.repo.FindByID(ctx, id.String())
+	if err != nil {
+		if storex.IsRecordNotFound(err) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+				WithDetail("invoice_id", id.String())
+		}
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+
+	if result.LineItems, err = selectLineItems(ctx, r.db, id); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// Update updates an existing invoice and replaces its line items if it is
+// still at expectedVersion. A concurrent modification fails with a
+// precondition error.
+func (r *invoiceRepository) Update(ctx context.Context, id uuid.UUID, invoice *models.Invoice, expectedVersion int) (*models.Invoice, error) {
+	invoice.ID = id
+
+	updateError := func(err error) error {
+		if mapped := mapConstraintError(err, invoice); mapped != nil {
+			return mapped
+		}
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, updateError(err)
+	}
+	defer tx.Rollback()
+
+	// Notes lock their original first, like Create does. The balance that
+	// must stay covered is the original's, or the invoice's own.
+	balanceOf := id
+	if invoice.OriginalInvoiceID != nil {
+		balanceOf = *invoice.OriginalInvoiceID
+		if err := lockOriginal(ctx, tx, balanceOf); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE invoices
+		SET invoice_data = $3, project_id = $4, provider_id = $5, schema_version = $6,
+			subtotal_amount = $7, discount_amount = $8, tax_amount = $9, tax_breakdown = $10,
+			version = $11, updated_by = $12, updated_at = $13
+		WHERE id = $1 AND version = $2 AND is_deleted = false
+		RETURNING *`
+
+	var result models.Invoice
+	err = tx.GetContext(ctx, &result, query,
+		id, expectedVersion, invoice.InvoiceData, invoice.ProjectID, invoice.ProviderID, invoice.SchemaVersion,
+		invoice.SubtotalAmount, invoice.DiscountAmount, invoice.TaxAmount, invoice.TaxBreakdown,
+		invoice.Version, invoice.UpdatedBy, invoice.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, concurrency.ConcurrencyErrors.New(concurrency.ErrPreconditionFailed).
+				WithDetail("invoice_id", id.String()).
+				WithDetail("expected_version", expectedVersion)
+		}
+		return nil, updateError(err)
+	}
+
+	// Line items are replaced as a whole
+	if _, err := tx.ExecContext(ctx, `DELETE FROM invoice_line_items WHERE invoice_id = $1`, id); err != nil {
+		return nil, updateError(err)
+	}
+	if result.LineItems, err = insertLineItems(ctx, tx, id, invoice.LineItems); err != nil {
+		return nil, updateError(err)
+	}
+
+	if err := checkBalance(ctx, tx, balanceOf); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, updateError(err)
+	}
+
+	return &result, nil
+}
+
+// Delete soft deletes an invoice with the soft_delete_invoice function
+func (r *invoiceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	var deleted bool
+	err := r.db.GetContext(ctx, &deleted, `SELECT soft_delete_invoice($1)`, id)
+	if err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceDeleteFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+	if !deleted {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+			WithDetail("invoice_id", id.String())
+	}
+
+	return nil
+}
+
+// Restore undoes the soft delete of an invoice
+func (r *invoiceRepository) Restore(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	query := `
+		UPDATE invoices
+		SET is_deleted = false, deleted_at = NULL
+		WHERE id = $1 AND is_deleted = true
+		RETURNING *`
+
+	var result models.Invoice
+	err := r.db.GetContext(ctx, &result, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotDeleted).
+				WithDetail("invoice_id", id.String())
+		}
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+
+	if result.LineItems, err = selectLineItems(ctx, r.db, id); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// Purge permanently removes the organization's invoices that were soft
+// deleted before the given time, recording the purge in invoice_purges,
+// and returns them with the storage keys of their attachments. The
+// attachment rows go with the invoices; the stored objects are left for
+// the caller to release once the purge is committed.
+//
+// Invoices submitted to the tax authority are kept, and so are invoices
+// that payments or payment batches refer to, voided ones included, and
+// invoices adjusted by notes that are not purged with them.
+func (r *invoiceRepository) Purge(ctx context.Context, orgID uuid.UUID, deletedBefore time.Time, purgedBy uuid.UUID) ([]uuid.UUID, []string, error) {
+	purgeError := func(err error) error {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoicePurgeFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, purgeError(err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH candidates AS (
+			SELECT id FROM invoices i
+			WHERE organization_id = $1 AND is_deleted = true AND deleted_at < $2
+				AND tax_authority_status IS NULL
+				AND NOT EXISTS (SELECT 1 FROM payment_allocations a WHERE a.invoice_id = i.id)
+				AND NOT EXISTS (SELECT 1 FROM payment_batch_items b WHERE b.invoice_id = i.id)
+		)
+		SELECT id FROM invoices i
+		WHERE id IN (SELECT id FROM candidates)
+			AND NOT EXISTS (
+				SELECT 1 FROM invoices n
+				WHERE n.original_invoice_id = i.id AND n.id NOT IN (SELECT id FROM candidates))
+		ORDER BY deleted_at, id
+		FOR UPDATE`
+
+	ids := []uuid.UUID{}
+	if err := tx.SelectContext(ctx, &ids, query, orgID, deletedBefore); err != nil {
+		return nil, nil, purgeError(err)
+	}
+
+	keys := []string{}
+	err = tx.SelectContext(ctx, &keys,
+		`SELECT DISTINCT storage_key FROM invoice_attachments WHERE invoice_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, nil, purgeError(err)
+	}
+
+	// A note and the invoice it adjusts go in the same statement, which
+	// checks original_invoice_id once both are gone
+	if _, err := tx.ExecContext(ctx, `DELETE FROM invoices WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return nil, nil, purgeError(err)
+	}
+
+	audit := `
+		INSERT INTO invoice_purges (organization_id, deleted_before, purged_count, purged_by)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := tx.ExecContext(ctx, audit, orgID, deletedBefore, len(ids), purgedBy); err != nil {
+		return nil, nil, purgeError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, purgeError(err)
+	}
+
+	return ids, keys, nil
+}
+
+// HardDelete permanently removes an invoice that was never submitted to the
+// tax authority
+func (r *invoiceRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM invoices WHERE id = $1 AND tax_authority_status IS NULL`, id)
+	if err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceDeleteFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceDeleteFailed).
+			WithDetail("invoice_id", id.String()).
+			WithCause(err)
+	}
+	if affected == 0 {
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+			WithDetail("invoice_id", id.String())
+	}
+
+	return nil
+}
+
+// HasOrganizationRole reports whether the user is an active member of the
+// organization with the given role
+func (r *invoiceRepository) HasOrganizationRole(ctx context.Context, orgID, userID uuid.UUID, role string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM organization_memberships
+			WHERE organization_id = $1 AND user_id = $2 AND role_name = $3 AND is_active = true
+		)`
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, query, orgID.String(), userID.String(), role); err != nil {
+		return false, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return exists, nil
+}
+
+// List retrieves invoices with pagination and filtering
+func (r *invoiceRepository) List(ctx context.Context, req *dto.InvoiceListRequest) (*dto.InvoiceListResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+	if !sortableFields[req.SortBy] {
+		req.SortBy = DefaultSortField
+	}
+
+	whereClause, args := buildListFilters(req)
+	argIndex := len(args) + 1
+
+	// Build ORDER BY clause
+	orderDirection := "ASC"
+	if req.SortOrder == "desc" {
+		orderDirection = "DESC"
+	}
+	orderBy := fmt.Sprintf("ORDER BY %s %s NULLS LAST, id", req.SortBy, orderDirection)
+
+	// Calculate offset
+	offset := (req.Page - 1) * req.PageSize
+
+	// Execute count query
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM invoices WHERE %s", whereClause)
+	var total int64
+	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithCause(err)
+	}
+
+	// Execute data query
+	dataQuery := fmt.Sprintf(`
+		SELECT * FROM invoices
+		WHERE %s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, whereClause, orderBy, argIndex, argIndex+1)
+
+	args = append(args, req.PageSize, offset)
+
+	var invoiceList []models.Invoice
+	err = r.db.SelectContext(ctx, &invoiceList, dataQuery, args...)
+	if err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithCause(err)
+	}
+
+	// Convert to response format
+	invoicePtrs := make([]*models.Invoice, len(invoiceList))
+	for i := range invoiceList {
+		invoicePtrs[i] = &invoiceList[i]
+	}
+
+	// Calculate pagination metadata
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
+	}
+
+	return &dto.InvoiceListResponse{
+		Invoices:    invoicePtrs,
+		Total:       total,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		TotalPages:  totalPages,
+		HasNext:     req.Page < totalPages,
+		HasPrevious: req.Page > 1,
+	}, nil
+}
+
+// GetByNumberAndOrganization retrieves a live invoice by its number within
+// an organization
+func (r *invoiceRepository) GetByNumberAndOrganization(ctx context.Context, number string, orgID uuid.UUID) (*models.Invoice, error) {
+	filters := map[string]any{
+		"invoice_number":  number,
+		"organization_id": orgID,
+		"is_deleted":      false,
+	}
+
+	result, err := r.repo.FindOne(ctx, filters)
+	if err != nil {
+		if storex.IsRecordNotFound(err) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+				WithDetail("invoice_number", number).
+				WithDetail("organization_id", orgID.String())
+		}
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_number", number).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// CountByOrganization counts invoices in an organization
+func (r *invoiceRepository) CountByOrganization(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	query := `SELECT COUNT(*) FROM invoices WHERE organization_id = $1 AND is_deleted = false`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, orgID)
+	if err != nil {
+		return 0, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return count, nil
+}
+
+// Transition changes the status stored in invoice_data and records the
+// transition in the same transaction. The update only applies while the
+// invoice still has the status the transition was validated against.
+func (r *invoiceRepository) Transition(ctx context.Context, invoice *models.Invoice, transition *models.StatusTransition) (*models.Invoice, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+	defer tx.Rollback()
+
+	result, err := ApplyTransition(ctx, tx, invoice, transition)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+
+	return result, nil
+}
+
+// ApplyTransition changes the status of an invoice and records the
+// transition inside tx, so other domains can move invoices through their
+// workflow atomically with their own writes (approvals settle the invoice
+// status this way). The caller is responsible for checking the workflow.
+func ApplyTransition(ctx context.Context, tx *sqlx.Tx, invoice *models.Invoice, transition *models.StatusTransition) (*models.Invoice, error) {
+	if transition.ID == uuid.Nil {
+		transition.ID = uuid.New()
+	}
+
+	update := `
+		UPDATE invoices
+		SET invoice_data = jsonb_set(invoice_data, '{status}', to_jsonb($3::text)), updated_by = $4
+		WHERE id = $1 AND is_deleted = false AND status IS NOT DISTINCT FROM $2
+		RETURNING *`
+
+	var result models.Invoice
+	err := tx.GetContext(ctx, &result, update, invoice.ID, transition.FromStatus, transition.ToStatus, transition.TransitionedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrInvalidStatusTransition).
+				WithDetail("invoice_id", invoice.ID.String()).
+				WithDetail("to_status", transition.ToStatus).
+				WithDetail("reason", "status_changed_concurrently")
+		}
+		if mapped := mapConstraintError(err, invoice); mapped != nil {
+			return nil, mapped
+		}
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+
+	insert := `
+		INSERT INTO invoice_status_transitions
+			(id, invoice_id, organization_id, from_status, to_status, comment, transitioned_by, transitioned_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = tx.ExecContext(ctx, insert,
+		transition.ID, transition.InvoiceID, transition.OrganizationID, transition.FromStatus,
+		transition.ToStatus, transition.Comment, transition.TransitionedBy, transition.TransitionedAt)
+	if err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+
+	if result.LineItems, err = selectLineItems(ctx, tx, invoice.ID); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceUpdateFailed).
+			WithDetail("invoice_id", invoice.ID.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// ListTransitions retrieves the status history of an invoice, oldest first
+func (r *invoiceRepository) ListTransitions(ctx context.Context, invoiceID uuid.UUID) ([]*models.StatusTransition, error) {
+	query := `
+		SELECT * FROM invoice_status_transitions
+		WHERE invoice_id = $1
+		ORDER BY transitioned_at, id`
+
+	transitions := []*models.StatusTransition{}
+	if err := r.db.SelectContext(ctx, &transitions, query, invoiceID); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return transitions, nil
+}
+
+// Helper methods
+
+// buildListFilters turns the list request into a WHERE clause and its arguments
+func buildListFilters(req *dto.InvoiceListRequest) (string, []any) {
+	whereConditions := []string{}
+	args := []any{}
+
+	switch req.Deleted {
+	case dto.DeletedInclude:
+		whereConditions = append(whereConditions, "1=1")
+	case dto.DeletedOnly:
+		whereConditions = append(whereConditions, "is_deleted = true")
+	default:
+		whereConditions = append(whereConditions, "is_deleted = false")
+	}
+
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		whereConditions = append(whereConditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if req.OrganizationID != nil {
+		addCondition("organization_id = $%d", *req.OrganizationID)
+	}
+	if req.ProjectID != nil {
+		addCondition("project_id = $%d", *req.ProjectID)
+	}
+	if req.ProviderID != nil {
+		addCondition("provider_id = $%d", *req.ProviderID)
+	}
+	if req.InvoiceTypeID != nil {
+		addCondition("invoice_type_id = $%d", *req.InvoiceTypeID)
+	}
+	if req.Status != nil {
+		addCondition("status = $%d", *req.Status)
+	}
+	if req.CurrencyCode != nil {
+		addCondition("currency_code = $%d", strings.ToUpper(*req.CurrencyCode))
+	}
+	if req.DateFrom != nil {
+		addCondition("invoice_date >= $%d", *req.DateFrom)
+	}
+	if req.DateTo != nil {
+		addCondition("invoice_date <= $%d", *req.DateTo)
+	}
+	if req.DueFrom != nil {
+		addCondition("due_date >= $%d", *req.DueFrom)
+	}
+	if req.DueTo != nil {
+		addCondition("due_date <= $%d", *req.DueTo)
+	}
+	if req.MinAmount != nil {
+		addCondition("total_amount >= $%d", *req.MinAmount)
+	}
+	if req.MaxAmount != nil {
+		addCondition("total_amount <= $%d", *req.MaxAmount)
+	}
+	if req.DataFilter != nil {
+		condition, filterArgs := req.DataFilter.SQL("invoice_data", len(args)+1)
+		whereConditions = append(whereConditions, condition)
+		args = append(args, filterArgs...)
+	}
+
+	return strings.Join(whereConditions, " AND "), args
+}
+
+// mapConstraintError converts known constraint violations into domain errors
+func mapConstraintError(err error, invoice *models.Invoice) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "invoices_number_org_unique"):
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceNumberExists).
+			WithDetail("invoice_number", invoice.InvoiceData[models.FieldInvoiceNumber]).
+			WithDetail("organization_id", invoice.OrganizationID.String()).
+			WithCause(err)
+	case strings.Contains(msg, "violates foreign key constraint"):
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceInvalidReference).
+			WithDetail("organization_id", invoice.OrganizationID.String()).
+			WithDetail("invoice_type_id", invoice.InvoiceTypeID.String()).
+			WithCause(err)
+	case strings.Contains(msg, "violates check constraint"):
+		return invoices.InvoicesErrors.New(invoices.ErrInvoiceValidationFailed).
+			WithDetail("reason", "check_constraint").
+			WithCause(err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/google/uuid"
+)
+
+// InvoiceRepository defines the interface for invoice repository operations
+type InvoiceRepository interface {
+	// Basic CRUD operations
+	Create(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Invoice, error)
+	Update(ctx context.Context, id uuid.UUID, invoice *models.Invoice, expectedVersion int) (*models.Invoice, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	HardDelete(ctx context.Context, id uuid.UUID) error
+
+	// Soft delete lifecycle
+	Restore(ctx context.Context, id uuid.UUID) (*models.Invoice, error)
+	Purge(ctx context.Context, orgID uuid.UUID, deletedBefore time.Time, purgedBy uuid.UUID) ([]uuid.UUID, []string, error)
+	HasOrganizationRole(ctx context.Context, orgID, userID uuid.UUID, role string) (bool, error)
+
+	// Query operations
+	List(ctx context.Context, req *dto.InvoiceListRequest) (*dto.InvoiceListResponse, error)
+	Search(ctx context.Context, req *dto.InvoiceSearchRequest) (*dto.InvoiceSearchResponse, error)
+	GetByNumberAndOrganization(ctx context.Context, number string, orgID uuid.UUID) (*models.Invoice, error)
+
+	// Status lifecycle
+	Transition(ctx context.Context, invoice *models.Invoice, transition *models.StatusTransition) (*models.Invoice, error)
+	ListTransitions(ctx context.Context, invoiceID uuid.UUID) ([]*models.StatusTransition, error)
+
+	// Credit and debit notes
+	GetBalance(ctx context.Context, invoiceID uuid.UUID) (*models.Balance, error)
+	ListAdjustments(ctx context.Context, invoiceID uuid.UUID) ([]*models.Invoice, error)
+
+	// Revision history
+	ListRevisions(ctx context.Context, invoiceID uuid.UUID) ([]*models.Revision, error)
+	GetRevision(ctx context.Context, invoiceID uuid.UUID, version int) (*models.Revision, error)
+
+	// Utility operations
+	CountByOrganization(ctx context.Context, orgID uuid.UUID) (int64, error)
+}
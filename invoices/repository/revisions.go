@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// ListRevisions retrieves every stored version of an invoice, oldest first.
+// Revisions are written by the record_invoice_revision trigger.
+func (r *invoiceRepository) ListRevisions(ctx context.Context, invoiceID uuid.UUID) ([]*models.Revision, error) {
+	query := `
+		SELECT * FROM invoice_revisions
+		WHERE invoice_id = $1
+		ORDER BY version`
+
+	revisions := []*models.Revision{}
+	if err := r.db.SelectContext(ctx, &revisions, query, invoiceID); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves an invoice as it was at the given version
+func (r *invoiceRepository) GetRevision(ctx context.Context, invoiceID uuid.UUID, version int) (*models.Revision, error) {
+	query := `SELECT * FROM invoice_revisions WHERE invoice_id = $1 AND version = $2`
+
+	var revision models.Revision
+	if err := r.db.GetContext(ctx, &revision, query, invoiceID, version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invoices.InvoicesErrors.New(invoices.ErrRevisionNotFound).
+				WithDetail("invoice_id", invoiceID.String()).
+				WithDetail("version", version)
+		}
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	return &revision, nil
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	"github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoices/models"
+)
+
+// searchConfig is the search document column and text search configuration
+// of a search language
+type searchConfig struct {
+	column    string
+	regconfig string
+}
+
+// searchConfigs maps the search languages to their documents (see
+// migration 016)
+var searchConfigs = map[string]searchConfig{
+	models.SearchLanguageSpanish: {column: "document_spanish", regconfig: "spanish"},
+	models.SearchLanguageEnglish: {column: "document_english", regconfig: "english"},
+}
+
+// headlineOptions shapes the snippets returned with search results
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxFragments=3, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`
+
+// escapedContent is the searched text with HTML special characters escaped,
+// so that the <mark> tags added by ts_headline are the only markup in a
+// snippet. The text search parser reads named entities as single tokens and
+// never highlights inside them.
+const escapedContent = `replace(replace(replace(replace(replace(d.content,
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&apos;')`
+
+// Search finds the invoices whose search documents match the query, best
+// ranked first. Snippets are only built for the returned page.
+func (r *invoiceRepository) Search(ctx context.Context, req *dto.InvoiceSearchRequest) (*dto.InvoiceSearchResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+	config, ok := searchConfigs[req.Language]
+	if !ok {
+		req.Language = models.SearchLanguageSpanish
+		config = searchConfigs[req.Language]
+	}
+
+	whereClause, args := buildListFilters(&req.InvoiceListRequest)
+	args = append(args, config.regconfig, req.Query)
+	regconfigArg, queryArg := len(args)-1, len(args)
+
+	match := fmt.Sprintf("d.%s @@ websearch_to_tsquery($%d::regconfig, $%d)", config.column, regconfigArg, queryArg)
+
+	// Execute count query
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*) FROM invoices
+		JOIN invoice_search_documents d ON d.invoice_id = invoices.id
+		WHERE %s AND %s`, match, whereClause)
+	var total int64
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("query", req.Query).
+			WithCause(err)
+	}
+
+	// Execute data query
+	offset := (req.Page - 1) * req.PageSize
+	args = append(args, req.PageSize, offset, headlineOptions)
+	dataQuery := fmt.Sprintf(`
+		SELECT hits.*,
+		       ts_headline($%[2]d::regconfig, %[9]s, websearch_to_tsquery($%[2]d::regconfig, $%[3]d), $%[6]d) AS snippet
+		FROM (
+			SELECT invoices.*,
+			       ts_rank_cd(d.%[1]s, websearch_to_tsquery($%[2]d::regconfig, $%[3]d), 32) AS rank
+			FROM invoices
+			JOIN invoice_search_documents d ON d.invoice_id = invoices.id
+			WHERE %[7]s AND %[8]s
+			ORDER BY rank DESC, invoices.invoice_date DESC NULLS LAST, invoices.id
+			LIMIT $%[4]d OFFSET $%[5]d
+		) hits
+		JOIN invoice_search_documents d ON d.invoice_id = hits.id
+		ORDER BY hits.rank DESC, hits.invoice_date DESC NULLS LAST, hits.id`,
+		config.column, regconfigArg, queryArg, len(args)-2, len(args)-1, len(args), match, whereClause, escapedContent)
+
+	results := []*models.SearchResult{}
+	if err := r.db.SelectContext(ctx, &results, dataQuery, args...); err != nil {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceListFailed).
+			WithDetail("query", req.Query).
+			WithCause(err)
+	}
+
+	// Calculate pagination metadata
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
+	}
+
+	return &dto.InvoiceSearchResponse{
+		Query:       req.Query,
+		Language:    req.Language,
+		Results:     results,
+		Total:       total,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		TotalPages:  totalPages,
+		HasNext:     req.Page < totalPages,
+		HasPrevious: req.Page > 1,
+	}, nil
+}
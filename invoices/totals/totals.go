@@ -0,0 +1,161 @@
+// Package totals derives invoice amounts from line items using decimal
+// arithmetic. Monetary results are rounded half away from zero to cents, the
+// precision of the invoices.total_amount column, and tax is rounded per line
+// so that line taxes always add up to the tax totals.
+package totals
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Scale is the number of decimal places monetary amounts are rounded to
+const Scale = 2
+
+var hundred = decimal.NewFromInt(100)
+
+// Line is the input of a single invoice line. Percentages are expressed in
+// percent (18 means 18%).
+type Line struct {
+	Quantity        decimal.Decimal
+	UnitPrice       decimal.Decimal
+	DiscountPercent decimal.Decimal
+	DiscountAmount  decimal.Decimal
+	TaxCode         string
+	TaxRate         decimal.Decimal
+}
+
+// LineAmounts are the amounts computed for a single line
+type LineAmounts struct {
+	Gross    decimal.Decimal `json:"gross_amount"`
+	Discount decimal.Decimal `json:"discount_amount"`
+	Net      decimal.Decimal `json:"net_amount"`
+	Tax      decimal.Decimal `json:"tax_amount"`
+	Total    decimal.Decimal `json:"line_total"`
+}
+
+// TaxSubtotal aggregates the lines sharing a tax code and rate
+type TaxSubtotal struct {
+	TaxCode       string          `json:"tax_code"`
+	TaxRate       decimal.Decimal `json:"tax_rate"`
+	TaxableAmount decimal.Decimal `json:"taxable_amount"`
+	TaxAmount     decimal.Decimal `json:"tax_amount"`
+}
+
+// Result holds the amounts of every line and of the whole invoice
+type Result struct {
+	Lines         []LineAmounts   `json:"lines"`
+	Subtotal      decimal.Decimal `json:"subtotal"`
+	DiscountTotal decimal.Decimal `json:"discount_total"`
+	TaxTotal      decimal.Decimal `json:"tax_total"`
+	Total         decimal.Decimal `json:"total"`
+	Taxes         []TaxSubtotal   `json:"taxes"`
+}
+
+// Error describes a line whose values cannot be calculated
+type Error struct {
+	Index   int    `json:"index"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d %s: %s", e.Index, e.Field, e.Message)
+}
+
+// Calculate computes line amounts, the subtotal, the tax per code and rate
+// and the grand total:
+//
+//	gross    = quantity × unit_price
+//	discount = discount_amount, or gross × discount_percent / 100
+//	net      = gross − discount
+//	tax      = net × tax_rate / 100
+//	total    = Σ net + Σ tax
+func Calculate(lines []Line) (*Result, error) {
+	result := &Result{
+		Lines:         make([]LineAmounts, 0, len(lines)),
+		Subtotal:      decimal.Zero,
+		DiscountTotal: decimal.Zero,
+		TaxTotal:      decimal.Zero,
+		Taxes:         []TaxSubtotal{},
+	}
+
+	// Tax subtotals keep the order in which their code and rate first appear
+	taxIndex := make(map[string]int)
+
+	for i, line := range lines {
+		if err := validateLine(i, line); err != nil {
+			return nil, err
+		}
+
+		gross := line.Quantity.Mul(line.UnitPrice).Round(Scale)
+
+		discount := line.DiscountAmount.Round(Scale)
+		if !line.DiscountPercent.IsZero() {
+			discount = gross.Mul(line.DiscountPercent).Div(hundred).Round(Scale)
+		}
+		if discount.GreaterThan(gross) {
+			return nil, &Error{Index: i, Field: "discount_amount", Message: "discount exceeds the line amount"}
+		}
+
+		net := gross.Sub(discount)
+		tax := net.Mul(line.TaxRate).Div(hundred).Round(Scale)
+
+		result.Lines = append(result.Lines, LineAmounts{
+			Gross:    gross,
+			Discount: discount,
+			Net:      net,
+			Tax:      tax,
+			Total:    net.Add(tax),
+		})
+
+		result.Subtotal = result.Subtotal.Add(net)
+		result.DiscountTotal = result.DiscountTotal.Add(discount)
+		result.TaxTotal = result.TaxTotal.Add(tax)
+
+		key := line.TaxCode + "|" + line.TaxRate.String()
+		idx, ok := taxIndex[key]
+		if !ok {
+			idx = len(result.Taxes)
+			taxIndex[key] = idx
+			result.Taxes = append(result.Taxes, TaxSubtotal{
+				TaxCode:       line.TaxCode,
+				TaxRate:       line.TaxRate,
+				TaxableAmount: decimal.Zero,
+				TaxAmount:     decimal.Zero,
+			})
+		}
+		result.Taxes[idx].TaxableAmount = result.Taxes[idx].TaxableAmount.Add(net)
+		result.Taxes[idx].TaxAmount = result.Taxes[idx].TaxAmount.Add(tax)
+	}
+
+	result.Total = result.Subtotal.Add(result.TaxTotal)
+
+	return result, nil
+}
+
+func validateLine(i int, line Line) error {
+	fail := func(field, msg string) error {
+		return &Error{Index: i, Field: field, Message: msg}
+	}
+
+	switch {
+	case !line.Quantity.IsPositive():
+		return fail("quantity", "must be greater than zero")
+	case line.UnitPrice.IsNegative():
+		return fail("unit_price", "must not be negative")
+	case line.DiscountPercent.IsNegative() || line.DiscountPercent.GreaterThan(hundred):
+		return fail("discount_percent", "must be between 0 and 100")
+	case line.DiscountAmount.IsNegative():
+		return fail("discount_amount", "must not be negative")
+	case !line.DiscountPercent.IsZero() && !line.DiscountAmount.IsZero():
+		return fail("discount_amount", "cannot be combined with discount_percent")
+	case line.TaxRate.IsNegative() || line.TaxRate.GreaterThan(hundred):
+		return fail("tax_rate", "must be between 0 and 100")
+	case !line.TaxRate.IsZero() && line.TaxCode == "":
+		return fail("tax_code", "is required when tax_rate is set")
+	}
+
+	return nil
+}
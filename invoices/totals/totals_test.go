@@ -0,0 +1,167 @@
+package totals
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func line(quantity, price, rate string) Line {
+	return Line{Quantity: d(quantity), UnitPrice: d(price), TaxCode: "IGV", TaxRate: d(rate)}
+}
+
+func equal(t *testing.T, field string, got decimal.Decimal, want string) {
+	t.Helper()
+	if !got.Equal(d(want)) {
+		t.Errorf("%s = %s; want %s", field, got, want)
+	}
+}
+
+func TestCalculateLines(t *testing.T) {
+	tests := []struct {
+		name string
+		line Line
+		want [5]string // gross, discount, net, tax, total
+	}{
+		{"plain", line("2", "50", "18"), [5]string{"100", "0", "100", "18", "118"}},
+		{"fractional quantity", line("1.5", "3.333", "18"), [5]string{"5", "0", "5", "0.9", "5.9"}},
+		{"gross rounds half away from zero", line("1", "0.125", "0"), [5]string{"0.13", "0", "0.13", "0", "0.13"}},
+		{"tax rounds half away from zero", line("1", "0.25", "10"), [5]string{"0.25", "0", "0.25", "0.03", "0.28"}},
+		{"percent discount", Line{Quantity: d("3"), UnitPrice: d("33.33"), DiscountPercent: d("10"), TaxCode: "IGV", TaxRate: d("18")}, [5]string{"99.99", "10", "89.99", "16.2", "106.19"}},
+		{"amount discount", Line{Quantity: d("1"), UnitPrice: d("100"), DiscountAmount: d("15.005"), TaxCode: "IGV", TaxRate: d("18")}, [5]string{"100", "15.01", "84.99", "15.3", "100.29"}},
+		{"full discount", Line{Quantity: d("1"), UnitPrice: d("40"), DiscountPercent: d("100"), TaxCode: "IGV", TaxRate: d("18")}, [5]string{"40", "40", "0", "0", "0"}},
+		{"zero price", line("4", "0", "18"), [5]string{"0", "0", "0", "0", "0"}},
+		{"untaxed", Line{Quantity: d("1"), UnitPrice: d("10")}, [5]string{"10", "0", "10", "0", "10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Calculate([]Line{tt.line})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := result.Lines[0]
+			equal(t, "gross", got.Gross, tt.want[0])
+			equal(t, "discount", got.Discount, tt.want[1])
+			equal(t, "net", got.Net, tt.want[2])
+			equal(t, "tax", got.Tax, tt.want[3])
+			equal(t, "total", got.Total, tt.want[4])
+		})
+	}
+}
+
+func TestCalculateRoundsTaxPerLine(t *testing.T) {
+	// 0.10 × 18% = 0.018 rounds to 0.02 on each line. Rounding once over the
+	// document would give 0.30 × 18% = 0.054 → 0.05; per-line rounding keeps
+	// the tax totals equal to the sum of the printed line taxes.
+	lines := []Line{line("1", "0.10", "18"), line("1", "0.10", "18"), line("1", "0.10", "18")}
+
+	result, err := Calculate(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal(t, "subtotal", result.Subtotal, "0.30")
+	equal(t, "tax total", result.TaxTotal, "0.06")
+	equal(t, "total", result.Total, "0.36")
+
+	documentTax := result.Subtotal.Mul(d("18")).Div(hundred).Round(Scale)
+	if documentTax.Equal(result.TaxTotal) {
+		t.Fatalf("document rounding gives %s too; pick inputs where the methods differ", documentTax)
+	}
+
+	sum := decimal.Zero
+	for _, amounts := range result.Lines {
+		sum = sum.Add(amounts.Tax)
+	}
+	equal(t, "sum of line taxes", sum, result.TaxTotal.String())
+	equal(t, "IGV subtotal", result.Taxes[0].TaxAmount, result.TaxTotal.String())
+}
+
+func TestCalculateAggregatesTaxes(t *testing.T) {
+	lines := []Line{
+		line("1", "100", "18"),
+		{Quantity: d("2"), UnitPrice: d("10"), TaxCode: "EXO"},
+		{Quantity: d("1"), UnitPrice: d("50"), DiscountAmount: d("5"), TaxCode: "IGV", TaxRate: d("18.00")},
+		line("1", "20", "10"),
+	}
+
+	result, err := Calculate(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ code, rate, taxable, tax string }{
+		{"IGV", "18", "145", "26.1"},
+		{"EXO", "0", "20", "0"},
+		{"IGV", "10", "20", "2"},
+	}
+	if len(result.Taxes) != len(want) {
+		t.Fatalf("got %d tax subtotals; want %d: %+v", len(result.Taxes), len(want), result.Taxes)
+	}
+	for i, w := range want {
+		got := result.Taxes[i]
+		if got.TaxCode != w.code {
+			t.Errorf("taxes[%d].code = %s; want %s", i, got.TaxCode, w.code)
+		}
+		equal(t, "rate", got.TaxRate, w.rate)
+		equal(t, "taxable", got.TaxableAmount, w.taxable)
+		equal(t, "tax", got.TaxAmount, w.tax)
+	}
+
+	equal(t, "subtotal", result.Subtotal, "185")
+	equal(t, "discount total", result.DiscountTotal, "5")
+	equal(t, "tax total", result.TaxTotal, "28.1")
+	equal(t, "total", result.Total, "213.1")
+}
+
+func TestCalculateEmpty(t *testing.T) {
+	for _, lines := range [][]Line{nil, {}} {
+		result, err := Calculate(lines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Lines) != 0 || len(result.Taxes) != 0 {
+			t.Errorf("got lines %v and taxes %v; want none", result.Lines, result.Taxes)
+		}
+		for field, value := range map[string]decimal.Decimal{
+			"subtotal": result.Subtotal, "discount total": result.DiscountTotal,
+			"tax total": result.TaxTotal, "total": result.Total,
+		} {
+			equal(t, field, value, "0")
+		}
+	}
+}
+
+func TestCalculateRejectsInvalidLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  Line
+		field string
+	}{
+		{"zero quantity", line("0", "10", "18"), "quantity"},
+		{"negative quantity", line("-1", "10", "18"), "quantity"},
+		{"negative price", line("1", "-10", "18"), "unit_price"},
+		{"discount percent above 100", Line{Quantity: d("1"), UnitPrice: d("10"), DiscountPercent: d("100.01")}, "discount_percent"},
+		{"negative discount amount", Line{Quantity: d("1"), UnitPrice: d("10"), DiscountAmount: d("-1")}, "discount_amount"},
+		{"both discounts", Line{Quantity: d("1"), UnitPrice: d("10"), DiscountPercent: d("5"), DiscountAmount: d("1")}, "discount_amount"},
+		{"discount above gross", Line{Quantity: d("1"), UnitPrice: d("10"), DiscountAmount: d("10.01")}, "discount_amount"},
+		{"tax rate above 100", line("1", "10", "101"), "tax_rate"},
+		{"rate without code", Line{Quantity: d("1"), UnitPrice: d("10"), TaxRate: d("18")}, "tax_code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Calculate([]Line{line("1", "1", "18"), tt.line})
+			var lineErr *Error
+			if !errors.As(err, &lineErr) {
+				t.Fatalf("err = %v; want a line error", err)
+			}
+			if lineErr.Index != 1 || lineErr.Field != tt.field {
+				t.Errorf("error at line %d field %s; want line 1 field %s", lineErr.Index, lineErr.Field, tt.field)
+			}
+		})
+	}
+}
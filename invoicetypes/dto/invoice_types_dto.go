@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+	"github.com/google/uuid"
+)
+
+// CreateInvoiceTypeRequest represents the request payload for registering an invoice type
+type CreateInvoiceTypeRequest struct {
+	InvoiceType    string                 `json:"invoice_type" validate:"required,min=1,max=255"`
+	OrganizationID uuid.UUID              `json:"organization_id" validate:"required"`
+	ProjectID      *uuid.UUID             `json:"project_id,omitempty"`
+	InvoiceSchema  models.InvoiceSchema   `json:"invoice_schema" validate:"required"`
+	SchemaVersion  *string                `json:"schema_version,omitempty"`
+	StatusWorkflow *models.StatusWorkflow `json:"status_workflow,omitempty"` // defaults to models.DefaultStatusWorkflow
+	DocumentKind   *models.DocumentKind   `json:"document_kind,omitempty"`   // defaults to models.KindInvoice; cannot change later
+	CreatedBy      *uuid.UUID             `json:"created_by,omitempty"`
+}
+
+// UpdateInvoiceTypeRequest represents the request payload for updating an invoice type
+type UpdateInvoiceTypeRequest struct {
+	InvoiceType    *string                `json:"invoice_type" validate:"omitempty,min=1,max=255"`
+	ProjectID      *uuid.UUID             `json:"project_id"`
+	InvoiceSchema  models.InvoiceSchema   `json:"invoice_schema"` // rejected: publish a new schema version instead
+	SchemaVersion  *string                `json:"schema_version"` // rejected: publish a new schema version instead
+	StatusWorkflow *models.StatusWorkflow `json:"status_workflow"`
+	IsActive       *bool                  `json:"is_active"`
+}
+
+// InvoiceTypeListRequest represents query parameters for listing invoice types
+type InvoiceTypeListRequest struct {
+	OrganizationID *uuid.UUID `query:"organization_id"`
+	ProjectID      *uuid.UUID `query:"project_id"`
+	IsActive       *bool      `query:"is_active"`
+	Page           int        `query:"page" validate:"min=1"`
+	PageSize       int        `query:"page_size" validate:"min=1,max=100"`
+}
+
+// ValidateDataRequest represents a dry-run validation of an invoice payload
+type ValidateDataRequest struct {
+	InvoiceData map[string]any `json:"invoice_data" validate:"required"`
+}
+
+// InvoiceTypeResponse represents the response for a single invoice type
+type InvoiceTypeResponse struct {
+	*models.InvoiceType `json:",inline"`
+}
+
+// InvoiceTypeListResponse represents the response for listing invoice types
+type InvoiceTypeListResponse struct {
+	InvoiceTypes []*models.InvoiceType `json:"invoice_types"`
+	Total        int64                 `json:"total"`
+	Page         int                   `json:"page"`
+	PageSize     int                   `json:"page_size"`
+	TotalPages   int                   `json:"total_pages"`
+	HasNext      bool                  `json:"has_next"`
+	HasPrevious  bool                  `json:"has_previous"`
+}
+
+// ValidationResultResponse represents the outcome of validating a payload
+type ValidationResultResponse struct {
+	Valid  bool                `json:"valid"`
+	Errors []schema.FieldError `json:"errors"`
+}
+
+// PublishSchemaVersionRequest represents the request payload for publishing a new schema version
+type PublishSchemaVersionRequest struct {
+	SchemaVersion string               `json:"schema_version" validate:"required"`
+	InvoiceSchema models.InvoiceSchema `json:"invoice_schema" validate:"required"`
+	Transforms    models.Transforms    `json:"transforms"`
+	Migrate       *bool                `json:"migrate,omitempty"` // defaults to true
+	CreatedBy     *uuid.UUID           `json:"created_by,omitempty"`
+}
+
+// StartMigrationRequest represents the request payload for (re)starting a
+// migration of existing invoices to the current schema version
+type StartMigrationRequest struct {
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+}
+
+// SchemaVersionResponse represents the response for a single schema version
+type SchemaVersionResponse struct {
+	*models.SchemaVersion `json:",inline"`
+}
+
+// SchemaVersionListResponse represents the version history of an invoice type
+type SchemaVersionListResponse struct {
+	InvoiceTypeID  uuid.UUID               `json:"invoice_type_id"`
+	CurrentVersion string                  `json:"current_version"`
+	Versions       []*models.SchemaVersion `json:"versions"`
+}
+
+// PublishSchemaVersionResponse represents the outcome of publishing a schema version
+type PublishSchemaVersionResponse struct {
+	Version   *models.SchemaVersion   `json:"version"`
+	Migration *models.SchemaMigration `json:"migration,omitempty"`
+}
+
+// SchemaMigrationResponse represents the response for a single schema migration
+type SchemaMigrationResponse struct {
+	*models.SchemaMigration `json:",inline"`
+}
+
+// SchemaMigrationListResponse represents the migrations of an invoice type
+type SchemaMigrationListResponse struct {
+	Migrations []*models.SchemaMigration `json:"migrations"`
+}
@@ -0,0 +1,171 @@
+package invoicetypes
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// InvoiceTypesErrors is the error registry for invoice types domain
+var InvoiceTypesErrors = errx.NewRegistry("INVOICE_TYPES")
+
+// Invoice type error codes
+var (
+	// Basic CRUD errors
+	ErrInvoiceTypeNotFound = InvoiceTypesErrors.Register(
+		"NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Invoice type not found",
+	)
+
+	ErrInvoiceTypeExists = InvoiceTypesErrors.Register(
+		"TYPE_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Invoice type with this name already exists in the organization",
+	)
+
+	ErrInvoiceTypeCreateFailed = InvoiceTypesErrors.Register(
+		"CREATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to create invoice type",
+	)
+
+	ErrInvoiceTypeUpdateFailed = InvoiceTypesErrors.Register(
+		"UPDATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to update invoice type",
+	)
+
+	ErrInvoiceTypeDeleteFailed = InvoiceTypesErrors.Register(
+		"DELETE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to delete invoice type",
+	)
+
+	// Query errors
+	ErrInvoiceTypeListFailed = InvoiceTypesErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list invoice types",
+	)
+
+	// Validation errors
+	ErrInvoiceTypeValidationFailed = InvoiceTypesErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invoice type validation failed",
+	)
+
+	ErrInvoiceSchemaInvalid = InvoiceTypesErrors.Register(
+		"SCHEMA_INVALID",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invoice schema is not a valid JSON Schema",
+	)
+
+	ErrStatusWorkflowInvalid = InvoiceTypesErrors.Register(
+		"WORKFLOW_INVALID",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invoice status workflow is invalid",
+	)
+
+	ErrInvoiceDataInvalid = InvoiceTypesErrors.Register(
+		"DATA_INVALID",
+		errx.TypeValidation,
+		http.StatusUnprocessableEntity,
+		"Invoice data does not match the invoice type schema",
+	)
+
+	// Business logic errors
+	ErrInvoiceTypeInactive = InvoiceTypesErrors.Register(
+		"INACTIVE",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice type is inactive and cannot be used",
+	)
+
+	ErrInvoiceTypeScopeMismatch = InvoiceTypesErrors.Register(
+		"SCOPE_MISMATCH",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice type does not belong to the invoice's organization or project",
+	)
+
+	ErrInvoiceTypeInUse = InvoiceTypesErrors.Register(
+		"IN_USE",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Invoice type cannot be deleted because invoices reference it",
+	)
+
+	// Schema versioning errors
+	ErrSchemaVersionNotFound = InvoiceTypesErrors.Register(
+		"VERSION_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Schema version not found",
+	)
+
+	ErrSchemaVersionExists = InvoiceTypesErrors.Register(
+		"VERSION_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Schema version already published for this invoice type",
+	)
+
+	ErrSchemaVersionPublishFailed = InvoiceTypesErrors.Register(
+		"VERSION_PUBLISH_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to publish schema version",
+	)
+
+	ErrTransformInvalid = InvoiceTypesErrors.Register(
+		"TRANSFORM_INVALID",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Schema transform is invalid",
+	)
+
+	ErrMigrationNotFound = InvoiceTypesErrors.Register(
+		"MIGRATION_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Schema migration not found",
+	)
+
+	ErrMigrationInProgress = InvoiceTypesErrors.Register(
+		"MIGRATION_IN_PROGRESS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"A schema migration is already running for this invoice type",
+	)
+
+	ErrMigrationFailed = InvoiceTypesErrors.Register(
+		"MIGRATION_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to process schema migration",
+	)
+)
+
+// Helper functions for error checking
+func IsInvoiceTypeNotFound(err error) bool {
+	return errx.IsCode(err, ErrInvoiceTypeNotFound)
+}
+
+func IsInvoiceDataInvalid(err error) bool {
+	return errx.IsCode(err, ErrInvoiceDataInvalid)
+}
+
+func IsSchemaVersionNotFound(err error) bool {
+	return errx.IsCode(err, ErrSchemaVersionNotFound)
+}
@@ -0,0 +1,415 @@
+package invoicetypesapi
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/invoicetypesrv"
+	postgres "github.com/Abraxas-365/fuckturamelo/invoicetypes/repository"
+)
+
+// InvoiceTypesAPI contains the complete API setup for the invoice types domain
+type InvoiceTypesAPI struct {
+	service invoicetypesrv.InvoiceTypeService
+	repo    postgres.InvoiceTypeRepository
+}
+
+// Config contains configuration for the invoice types API
+type Config struct {
+	DB *sqlx.DB
+}
+
+// New creates a new InvoiceTypesAPI instance
+func New(config Config) (*InvoiceTypesAPI, error) {
+	if config.DB == nil {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewInvoiceTypeRepository(config.DB)
+	versions := postgres.NewSchemaVersionRepository(config.DB)
+	svc := invoicetypesrv.NewInvoiceTypeService(repo, versions)
+
+	return &InvoiceTypesAPI{
+		service: svc,
+		repo:    repo,
+	}, nil
+}
+
+// SetupRoutes registers all invoice type routes with the given Fiber router group
+func (api *InvoiceTypesAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Basic CRUD routes
+	router.Post("/", api.createInvoiceType)
+	router.Get("/", api.listInvoiceTypes)
+	router.Get("/:id", api.getInvoiceType)
+	router.Put("/:id", api.updateInvoiceType)
+	router.Delete("/:id", api.deleteInvoiceType)
+
+	// Schema routes
+	router.Post("/:id/validate", api.validateData)
+
+	// Schema versioning routes
+	router.Post("/:id/versions", api.publishSchemaVersion)
+	router.Get("/:id/versions", api.listSchemaVersions)
+	router.Get("/:id/versions/:version", api.getSchemaVersion)
+
+	// Data migration routes
+	router.Post("/:id/migrations", api.startMigration)
+	router.Get("/:id/migrations", api.listMigrations)
+	router.Get("/:id/migrations/:migrationId", api.getMigration)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *InvoiceTypesAPI) GetService() invoicetypesrv.InvoiceTypeService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *InvoiceTypesAPI) GetRepository() postgres.InvoiceTypeRepository {
+	return api.repo
+}
+
+// createInvoiceType handles POST /invoice-types
+func (api *InvoiceTypesAPI) createInvoiceType(c *fiber.Ctx) error {
+	var req dto.CreateInvoiceTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.CreateInvoiceType(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getInvoiceType handles GET /invoice-types/:id
+func (api *InvoiceTypesAPI) getInvoiceType(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetInvoiceType(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// updateInvoiceType handles PUT /invoice-types/:id
+func (api *InvoiceTypesAPI) updateInvoiceType(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateInvoiceTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.UpdateInvoiceType(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteInvoiceType handles DELETE /invoice-types/:id
+func (api *InvoiceTypesAPI) deleteInvoiceType(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteInvoiceType(c.Context(), id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// listInvoiceTypes handles GET /invoice-types
+func (api *InvoiceTypesAPI) listInvoiceTypes(c *fiber.Ctx) error {
+	req, err := api.parseListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListInvoiceTypes(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// validateData handles POST /invoice-types/:id/validate
+func (api *InvoiceTypesAPI) validateData(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.ValidateDataRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.ValidateData(c.Context(), id, req.InvoiceData)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Schema versioning handlers
+
+// publishSchemaVersion handles POST /invoice-types/:id/versions
+func (api *InvoiceTypesAPI) publishSchemaVersion(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.PublishSchemaVersionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.PublishSchemaVersion(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listSchemaVersions handles GET /invoice-types/:id/versions
+func (api *InvoiceTypesAPI) listSchemaVersions(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListSchemaVersions(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getSchemaVersion handles GET /invoice-types/:id/versions/:version
+func (api *InvoiceTypesAPI) getSchemaVersion(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetSchemaVersion(c.Context(), id, c.Params("version"))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Data migration handlers
+
+// startMigration handles POST /invoice-types/:id/migrations
+func (api *InvoiceTypesAPI) startMigration(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.StartMigrationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+				WithDetail("error", "Invalid JSON in request body").
+				WithCause(err)
+		}
+	}
+
+	result, err := api.service.StartMigration(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listMigrations handles GET /invoice-types/:id/migrations
+func (api *InvoiceTypesAPI) listMigrations(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListMigrations(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getMigration handles GET /invoice-types/:id/migrations/:migrationId
+func (api *InvoiceTypesAPI) getMigration(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	migrationID, err := api.parseUUIDParam(c, "migrationId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetMigration(c.Context(), id, migrationID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// healthCheck provides a health check endpoint
+func (api *InvoiceTypesAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "invoice_types",
+	})
+}
+
+// Helper methods
+
+func (api *InvoiceTypesAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *InvoiceTypesAPI) parseListRequest(c *fiber.Ctx) (*dto.InvoiceTypeListRequest, error) {
+	req := &dto.InvoiceTypeListRequest{}
+
+	// Parse organization_id
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		orgID, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+				WithDetail("error", "Invalid organization_id format").
+				WithCause(err)
+		}
+		req.OrganizationID = &orgID
+	}
+
+	// Parse project_id
+	if projectIDStr := c.Query("project_id"); projectIDStr != "" {
+		projectID, err := uuid.Parse(projectIDStr)
+		if err != nil {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+				WithDetail("error", "Invalid project_id format").
+				WithCause(err)
+		}
+		req.ProjectID = &projectID
+	}
+
+	// Parse is_active
+	if isActiveStr := c.Query("is_active"); isActiveStr != "" {
+		isActive, err := strconv.ParseBool(isActiveStr)
+		if err != nil {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+				WithDetail("error", "Invalid is_active format").
+				WithCause(err)
+		}
+		req.IsActive = &isActive
+	}
+
+	// Parse pagination
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	req.Page = page
+
+	pageSize := 20
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
+			pageSize = ps
+		}
+	}
+	req.PageSize = pageSize
+
+	return req, nil
+}
@@ -0,0 +1,270 @@
+package invoicetypesrv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/invoicetypes/repository"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/transform"
+	"github.com/google/uuid"
+)
+
+const (
+	// migrationBatchSize is the number of invoices processed between progress updates
+	migrationBatchSize = 200
+
+	// maxRecordedFailures caps the failure and skip reports stored on a
+	// migration; failed_count and skipped_count keep counting past it
+	maxRecordedFailures = 500
+
+	// migrationLease is how long a migration stays claimed without a renewal.
+	// Any instance may expire a job whose lease ran out.
+	migrationLease = 2 * time.Minute
+
+	// leaseRenewInterval is how often the running instance renews its lease
+	leaseRenewInterval = 20 * time.Second
+)
+
+// Migration failure reasons
+const (
+	failureUnknownVersion   = "unknown_schema_version"
+	failureNewerVersion     = "newer_than_target_version"
+	failureInvalidData      = "invalid_invoice_data"
+	failureTransform        = "transform_failed"
+	failureSchemaValidation = "schema_validation_failed"
+	failureConcurrentUpdate = "concurrent_update"
+	failureUpdate           = "update_failed"
+)
+
+// Reasons invoices are skipped
+const (
+	skipSubmitted       = "submitted_to_tax_authority"
+	skipApprovalPending = "approval_pending"
+)
+
+// migrator runs schema migrations in background goroutines. The database
+// allows one unfinished migration per invoice type; the instance running it
+// keeps renewing its lease until the job finishes.
+type migrator struct {
+	versions postgres.SchemaVersionRepository
+}
+
+func newMigrator(versions postgres.SchemaVersionRepository) *migrator {
+	return &migrator{versions: versions}
+}
+
+// start runs the migration in the background
+func (m *migrator) start(migration models.SchemaMigration) {
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go m.renewLease(ctx, cancel, migration.ID)
+		m.run(ctx, &migration)
+	}()
+}
+
+// renewLease keeps the migration claimed until ctx is done. It cancels the
+// run when the lease was lost, so the job stops once another instance
+// expired it.
+func (m *migrator) renewLease(ctx context.Context, cancel context.CancelFunc, id uuid.UUID) {
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := m.versions.RenewLease(ctx, id)
+			if err != nil {
+				log.Printf("schema migration %s: renewing lease: %v", id, err)
+				continue
+			}
+			if !renewed {
+				log.Printf("schema migration %s: lease lost, stopping", id)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// run upgrades every outdated invoice of the migration's invoice type. Rows
+// that cannot be migrated keep their data and schema version and are listed
+// in the migration's failure report; frozen invoices are listed as skipped.
+func (m *migrator) run(ctx context.Context, migration *models.SchemaMigration) {
+	history, err := m.versions.ListVersions(ctx, migration.InvoiceTypeID)
+	if err != nil {
+		m.abort(ctx, migration, err)
+		return
+	}
+
+	positions := make(map[string]int, len(history))
+	for i, version := range history {
+		positions[version.SchemaVersion] = i
+	}
+	target, ok := positions[migration.ToVersion]
+	if !ok {
+		m.abort(ctx, migration, fmt.Errorf("target schema version %s is not in the version history", migration.ToVersion))
+		return
+	}
+
+	compiled, err := schema.Compile(history[target].InvoiceSchema)
+	if err != nil {
+		m.abort(ctx, migration, err)
+		return
+	}
+
+	total, err := m.versions.CountOutdatedInvoices(ctx, migration.InvoiceTypeID, migration.ToVersion)
+	if err != nil {
+		m.abort(ctx, migration, err)
+		return
+	}
+
+	now := time.Now()
+	migration.Status = models.MigrationRunning
+	migration.TotalCount = total
+	migration.StartedAt = &now
+	if err := m.versions.UpdateMigration(ctx, migration); err != nil {
+		log.Printf("schema migration %s: %v", migration.ID, err)
+		return
+	}
+
+	cursor := uuid.Nil
+	for {
+		batch, err := m.versions.ListOutdatedInvoices(ctx, migration.InvoiceTypeID, migration.ToVersion, cursor, migrationBatchSize)
+		if err != nil {
+			m.abort(ctx, migration, err)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for i := range batch {
+			cursor = batch[i].ID
+			if skipped := skipReason(&batch[i]); skipped != nil {
+				migration.SkippedCount++
+				if len(migration.Skipped) < maxRecordedFailures {
+					migration.Skipped = append(migration.Skipped, *skipped)
+				}
+				continue
+			}
+			if failure := m.migrateInvoice(ctx, &batch[i], history, positions, target, compiled); failure != nil {
+				migration.FailedCount++
+				if len(migration.Failures) < maxRecordedFailures {
+					migration.Failures = append(migration.Failures, *failure)
+				}
+				continue
+			}
+			migration.MigratedCount++
+		}
+
+		if err := m.versions.UpdateMigration(ctx, migration); err != nil {
+			log.Printf("schema migration %s: %v", migration.ID, err)
+			return
+		}
+	}
+
+	completed := time.Now()
+	migration.Status = models.MigrationCompleted
+	migration.CompletedAt = &completed
+	if err := m.versions.UpdateMigration(ctx, migration); err != nil {
+		log.Printf("schema migration %s: %v", migration.ID, err)
+	}
+}
+
+// skipReason reports invoices whose content is frozen: submitted to the tax
+// authority or waiting for approval. Their data is never rewritten.
+func skipReason(invoice *models.MigrationCandidate) *models.MigrationFailure {
+	reason := ""
+	switch {
+	case invoice.TaxAuthorityStatus != nil:
+		reason = skipSubmitted
+	case invoice.ApprovalPending:
+		reason = skipApprovalPending
+	default:
+		return nil
+	}
+	return &models.MigrationFailure{
+		InvoiceID:   invoice.ID,
+		FromVersion: invoice.SchemaVersion,
+		Reason:      reason,
+	}
+}
+
+// migrateInvoice applies the transforms of every version after the invoice's
+// current one, validates the result against the target schema and stores it
+func (m *migrator) migrateInvoice(
+	ctx context.Context,
+	invoice *models.MigrationCandidate,
+	history []*models.SchemaVersion,
+	positions map[string]int,
+	target int,
+	compiled *schema.Schema,
+) *models.MigrationFailure {
+	failure := func(reason string, details any) *models.MigrationFailure {
+		return &models.MigrationFailure{
+			InvoiceID:   invoice.ID,
+			FromVersion: invoice.SchemaVersion,
+			Reason:      reason,
+			Errors:      details,
+		}
+	}
+
+	from, ok := positions[invoice.SchemaVersion]
+	if !ok {
+		return failure(failureUnknownVersion, nil)
+	}
+	if from > target {
+		return failure(failureNewerVersion, nil)
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(invoice.InvoiceData, &data); err != nil {
+		return failure(failureInvalidData, err.Error())
+	}
+
+	for _, version := range history[from+1 : target+1] {
+		transformed, err := transform.Apply(data, version.Transforms)
+		if err != nil {
+			return failure(failureTransform, map[string]any{
+				"schema_version": version.SchemaVersion,
+				"error":          err,
+			})
+		}
+		data = transformed
+	}
+
+	if fieldErrors := compiled.Validate(data); len(fieldErrors) > 0 {
+		return failure(failureSchemaValidation, fieldErrors)
+	}
+
+	updated, err := m.versions.MigrateInvoice(ctx, invoice, data, history[target].SchemaVersion)
+	if err != nil {
+		return failure(failureUpdate, err.Error())
+	}
+	if !updated {
+		return failure(failureConcurrentUpdate, nil)
+	}
+
+	return nil
+}
+
+// abort marks the migration as failed after an unexpected error
+func (m *migrator) abort(ctx context.Context, migration *models.SchemaMigration, cause error) {
+	now := time.Now()
+	reason := cause.Error()
+	migration.Status = models.MigrationFailed
+	migration.Error = &reason
+	migration.CompletedAt = &now
+	if err := m.versions.UpdateMigration(ctx, migration); err != nil {
+		log.Printf("schema migration %s: %v (while recording: %v)", migration.ID, err, cause)
+	}
+}
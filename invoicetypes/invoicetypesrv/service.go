@@ -0,0 +1,610 @@
+package invoicetypesrv
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/invoicetypes/repository"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/transform"
+	"github.com/google/uuid"
+)
+
+// InvoiceTypeService defines the interface for invoice type business logic
+type InvoiceTypeService interface {
+	// Basic CRUD operations
+	CreateInvoiceType(ctx context.Context, req *dto.CreateInvoiceTypeRequest) (*dto.InvoiceTypeResponse, error)
+	GetInvoiceType(ctx context.Context, id uuid.UUID) (*dto.InvoiceTypeResponse, error)
+	UpdateInvoiceType(ctx context.Context, id uuid.UUID, req *dto.UpdateInvoiceTypeRequest) (*dto.InvoiceTypeResponse, error)
+	DeleteInvoiceType(ctx context.Context, id uuid.UUID) error
+
+	// Query operations
+	ListInvoiceTypes(ctx context.Context, req *dto.InvoiceTypeListRequest) (*dto.InvoiceTypeListResponse, error)
+
+	// Schema validation
+	ValidateData(ctx context.Context, id uuid.UUID, data map[string]any) (*dto.ValidationResultResponse, error)
+	ValidateInvoice(ctx context.Context, id, orgID uuid.UUID, projectID *uuid.UUID, data map[string]any) (string, error)
+	GetSchema(ctx context.Context, id uuid.UUID) (*schema.Schema, error)
+
+	// Status lifecycle
+	GetStatusWorkflow(ctx context.Context, id uuid.UUID) (*models.StatusWorkflow, error)
+
+	// Document kind (regular invoice, credit note or debit note)
+	GetDocumentKind(ctx context.Context, id uuid.UUID) (models.DocumentKind, error)
+
+	// Schema versioning
+	PublishSchemaVersion(ctx context.Context, id uuid.UUID, req *dto.PublishSchemaVersionRequest) (*dto.PublishSchemaVersionResponse, error)
+	GetSchemaVersion(ctx context.Context, id uuid.UUID, version string) (*dto.SchemaVersionResponse, error)
+	ListSchemaVersions(ctx context.Context, id uuid.UUID) (*dto.SchemaVersionListResponse, error)
+
+	// Data migrations
+	StartMigration(ctx context.Context, id uuid.UUID, req *dto.StartMigrationRequest) (*dto.SchemaMigrationResponse, error)
+	GetMigration(ctx context.Context, id, migrationID uuid.UUID) (*dto.SchemaMigrationResponse, error)
+	ListMigrations(ctx context.Context, id uuid.UUID) (*dto.SchemaMigrationListResponse, error)
+}
+
+// compiledSchema caches a compiled schema together with the row version it was built from
+type compiledSchema struct {
+	updatedAt time.Time
+	schema    *schema.Schema
+}
+
+// invoiceTypeService implements InvoiceTypeService
+type invoiceTypeService struct {
+	repo     postgres.InvoiceTypeRepository
+	versions postgres.SchemaVersionRepository
+	migrator *migrator
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]compiledSchema
+}
+
+// NewInvoiceTypeService creates a new invoice type service
+func NewInvoiceTypeService(repo postgres.InvoiceTypeRepository, versions postgres.SchemaVersionRepository) InvoiceTypeService {
+	return &invoiceTypeService{
+		repo:     repo,
+		versions: versions,
+		migrator: newMigrator(versions),
+		cache:    make(map[uuid.UUID]compiledSchema),
+	}
+}
+
+// CreateInvoiceType registers a new invoice type with its schema
+func (s *invoiceTypeService) CreateInvoiceType(ctx context.Context, req *dto.CreateInvoiceTypeRequest) (*dto.InvoiceTypeResponse, error) {
+	if err := s.validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.ExistsByNameAndOrganization(ctx, req.InvoiceType, req.OrganizationID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeExists).
+			WithDetail("invoice_type", req.InvoiceType).
+			WithDetail("organization_id", req.OrganizationID.String())
+	}
+
+	if _, err := compileSchema(req.InvoiceSchema); err != nil {
+		return nil, err
+	}
+	req.InvoiceSchema.EnsureFields()
+
+	version := models.DefaultSchemaVersion
+	if req.SchemaVersion != nil && *req.SchemaVersion != "" {
+		version = *req.SchemaVersion
+	}
+
+	workflow := models.DefaultStatusWorkflow()
+	if req.StatusWorkflow != nil {
+		if err := validateWorkflow(req.StatusWorkflow); err != nil {
+			return nil, err
+		}
+		workflow = *req.StatusWorkflow
+	}
+
+	kind := models.KindInvoice
+	if req.DocumentKind != nil {
+		kind = *req.DocumentKind
+	}
+
+	invoiceType := &models.InvoiceType{
+		InvoiceType:    req.InvoiceType,
+		OrganizationID: req.OrganizationID,
+		ProjectID:      req.ProjectID,
+		InvoiceSchema:  req.InvoiceSchema,
+		SchemaVersion:  version,
+		StatusWorkflow: workflow,
+		DocumentKind:   kind,
+		IsActive:       true,
+		CreatedBy:      req.CreatedBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	created, err := s.repo.Create(ctx, invoiceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceTypeResponse{InvoiceType: created}, nil
+}
+
+// GetInvoiceType retrieves an invoice type by ID
+func (s *invoiceTypeService) GetInvoiceType(ctx context.Context, id uuid.UUID) (*dto.InvoiceTypeResponse, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceTypeResponse{InvoiceType: invoiceType}, nil
+}
+
+// UpdateInvoiceType updates an existing invoice type
+func (s *invoiceTypeService) UpdateInvoiceType(ctx context.Context, id uuid.UUID, req *dto.UpdateInvoiceTypeRequest) (*dto.InvoiceTypeResponse, error) {
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schemas change only through published versions so existing data can be migrated
+	if req.InvoiceSchema != nil || req.SchemaVersion != nil {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "invoice_schema").
+			WithDetail("reason", "publish_new_schema_version")
+	}
+
+	if req.InvoiceType != nil && *req.InvoiceType != existing.InvoiceType {
+		if *req.InvoiceType == "" {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+				WithDetail("field", "invoice_type").
+				WithDetail("reason", "required")
+		}
+		exists, err := s.repo.ExistsByNameAndOrganization(ctx, *req.InvoiceType, existing.OrganizationID, &id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeExists).
+				WithDetail("invoice_type", *req.InvoiceType).
+				WithDetail("organization_id", existing.OrganizationID.String())
+		}
+	}
+
+	updated := *existing
+	if req.InvoiceType != nil {
+		updated.InvoiceType = *req.InvoiceType
+	}
+	if req.ProjectID != nil {
+		updated.ProjectID = req.ProjectID
+	}
+	if req.StatusWorkflow != nil {
+		if err := validateWorkflow(req.StatusWorkflow); err != nil {
+			return nil, err
+		}
+		updated.StatusWorkflow = *req.StatusWorkflow
+	}
+	if req.IsActive != nil {
+		updated.IsActive = *req.IsActive
+	}
+	updated.UpdatedAt = time.Now()
+
+	result, err := s.repo.Update(ctx, id, &updated)
+	if err != nil {
+		return nil, err
+	}
+	s.forget(id)
+
+	return &dto.InvoiceTypeResponse{InvoiceType: result}, nil
+}
+
+// DeleteInvoiceType deletes an invoice type that no invoice references
+func (s *invoiceTypeService) DeleteInvoiceType(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountInvoices(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeInUse).
+			WithDetail("invoice_type_id", id.String()).
+			WithDetail("invoice_count", count)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.forget(id)
+
+	return nil
+}
+
+// ListInvoiceTypes lists invoice types with pagination and filtering
+func (s *invoiceTypeService) ListInvoiceTypes(ctx context.Context, req *dto.InvoiceTypeListRequest) (*dto.InvoiceTypeListResponse, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	return s.repo.List(ctx, req)
+}
+
+// ValidateData validates a payload against an invoice type schema without storing it
+func (s *invoiceTypeService) ValidateData(ctx context.Context, id uuid.UUID, data map[string]any) (*dto.ValidationResultResponse, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := s.schemaFor(invoiceType)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldErrors := compiled.Validate(data)
+	if fieldErrors == nil {
+		fieldErrors = []schema.FieldError{}
+	}
+
+	return &dto.ValidationResultResponse{
+		Valid:  len(fieldErrors) == 0,
+		Errors: fieldErrors,
+	}, nil
+}
+
+// ValidateInvoice checks that an invoice payload may be stored under the given
+// invoice type: the type must be active, belong to the invoice's organization
+// (and project, for project-scoped types) and its current schema must accept
+// the data. It returns the schema version the payload was validated against.
+func (s *invoiceTypeService) ValidateInvoice(ctx context.Context, id, orgID uuid.UUID, projectID *uuid.UUID, data map[string]any) (string, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	if !invoiceType.IsActive {
+		return "", invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeInactive).
+			WithDetail("invoice_type_id", id.String())
+	}
+
+	if !invoiceType.AppliesTo(orgID, projectID) {
+		return "", invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeScopeMismatch).
+			WithDetail("invoice_type_id", id.String()).
+			WithDetail("organization_id", orgID.String())
+	}
+
+	compiled, err := s.schemaFor(invoiceType)
+	if err != nil {
+		return "", err
+	}
+
+	if fieldErrors := compiled.Validate(data); len(fieldErrors) > 0 {
+		return "", invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceDataInvalid).
+			WithDetail("invoice_type_id", id.String()).
+			WithDetail("schema_version", invoiceType.SchemaVersion).
+			WithDetail("errors", fieldErrors)
+	}
+
+	return invoiceType.SchemaVersion, nil
+}
+
+// GetSchema returns the compiled current schema of an invoice type
+func (s *invoiceTypeService) GetSchema(ctx context.Context, id uuid.UUID) (*schema.Schema, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.schemaFor(invoiceType)
+}
+
+// GetStatusWorkflow returns the status lifecycle of an invoice type
+func (s *invoiceTypeService) GetStatusWorkflow(ctx context.Context, id uuid.UUID) (*models.StatusWorkflow, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoiceType.StatusWorkflow, nil
+}
+
+// GetDocumentKind returns whether invoices of the type are regular invoices
+// or credit or debit notes
+func (s *invoiceTypeService) GetDocumentKind(ctx context.Context, id uuid.UUID) (models.DocumentKind, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return invoiceType.DocumentKind, nil
+}
+
+// PublishSchemaVersion makes a new schema the current one for an invoice type.
+// Existing invoices are upgraded in the background by applying the transforms
+// unless the request opts out of the migration.
+func (s *invoiceTypeService) PublishSchemaVersion(ctx context.Context, id uuid.UUID, req *dto.PublishSchemaVersionRequest) (*dto.PublishSchemaVersionResponse, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SchemaVersion == "" {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "schema_version").
+			WithDetail("reason", "required")
+	}
+	if len(req.InvoiceSchema) == 0 {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "invoice_schema").
+			WithDetail("reason", "required")
+	}
+	if req.SchemaVersion == invoiceType.SchemaVersion {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrSchemaVersionExists).
+			WithDetail("invoice_type_id", id.String()).
+			WithDetail("schema_version", req.SchemaVersion)
+	}
+
+	if _, err := compileSchema(req.InvoiceSchema); err != nil {
+		return nil, err
+	}
+	req.InvoiceSchema.EnsureFields()
+
+	if err := transform.Validate(req.Transforms); err != nil {
+		xerr := invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrTransformInvalid).
+			WithCause(err)
+		if transformErr, ok := err.(*transform.Error); ok {
+			xerr.WithDetail("index", transformErr.Index).
+				WithDetail("op", transformErr.Op).
+				WithDetail("field", transformErr.Field).
+				WithDetail("reason", transformErr.Message)
+		}
+		return nil, xerr
+	}
+
+	// A running job migrates towards the previous version; let it finish first
+	if err := s.ensureNoActiveMigration(ctx, id); err != nil {
+		return nil, err
+	}
+
+	transforms := req.Transforms
+	if transforms == nil {
+		transforms = models.Transforms{}
+	}
+
+	published, err := s.versions.PublishVersion(ctx, &models.SchemaVersion{
+		InvoiceTypeID: id,
+		SchemaVersion: req.SchemaVersion,
+		InvoiceSchema: req.InvoiceSchema,
+		Transforms:    transforms,
+		CreatedBy:     req.CreatedBy,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.forget(id)
+
+	response := &dto.PublishSchemaVersionResponse{Version: published}
+	if req.Migrate == nil || *req.Migrate {
+		migration, err := s.StartMigration(ctx, id, &dto.StartMigrationRequest{CreatedBy: req.CreatedBy})
+		if err != nil {
+			return nil, err
+		}
+		response.Migration = migration.SchemaMigration
+	}
+
+	return response, nil
+}
+
+// GetSchemaVersion retrieves a published schema version, including superseded ones
+func (s *invoiceTypeService) GetSchemaVersion(ctx context.Context, id uuid.UUID, version string) (*dto.SchemaVersionResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	result, err := s.versions.GetVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SchemaVersionResponse{SchemaVersion: result}, nil
+}
+
+// ListSchemaVersions returns the full version history of an invoice type
+func (s *invoiceTypeService) ListSchemaVersions(ctx context.Context, id uuid.UUID) (*dto.SchemaVersionListResponse, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versions.ListVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SchemaVersionListResponse{
+		InvoiceTypeID:  id,
+		CurrentVersion: invoiceType.SchemaVersion,
+		Versions:       versions,
+	}, nil
+}
+
+// StartMigration queues a background job that upgrades every invoice of the
+// type to its current schema version
+func (s *invoiceTypeService) StartMigration(ctx context.Context, id uuid.UUID, req *dto.StartMigrationRequest) (*dto.SchemaMigrationResponse, error) {
+	invoiceType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureNoActiveMigration(ctx, id); err != nil {
+		return nil, err
+	}
+
+	migration, err := s.versions.CreateMigration(ctx, &models.SchemaMigration{
+		InvoiceTypeID: id,
+		ToVersion:     invoiceType.SchemaVersion,
+		Status:        models.MigrationPending,
+		Failures:      models.MigrationFailures{},
+		Skipped:       models.MigrationFailures{},
+		CreatedBy:     req.CreatedBy,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.migrator.start(*migration)
+
+	return &dto.SchemaMigrationResponse{SchemaMigration: migration}, nil
+}
+
+// GetMigration retrieves a migration job and its failure report
+func (s *invoiceTypeService) GetMigration(ctx context.Context, id, migrationID uuid.UUID) (*dto.SchemaMigrationResponse, error) {
+	migration, err := s.versions.GetMigration(ctx, migrationID)
+	if err != nil {
+		return nil, err
+	}
+	if migration.InvoiceTypeID != id {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationNotFound).
+			WithDetail("migration_id", migrationID.String())
+	}
+
+	return &dto.SchemaMigrationResponse{SchemaMigration: migration}, nil
+}
+
+// ListMigrations lists the migration jobs of an invoice type
+func (s *invoiceTypeService) ListMigrations(ctx context.Context, id uuid.UUID) (*dto.SchemaMigrationListResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	migrations, err := s.versions.ListMigrations(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SchemaMigrationListResponse{Migrations: migrations}, nil
+}
+
+// Helper methods
+
+// schemaFor returns the compiled schema of an invoice type, compiling it at
+// most once per stored revision
+func (s *invoiceTypeService) schemaFor(invoiceType *models.InvoiceType) (*schema.Schema, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[invoiceType.ID]
+	s.mu.RUnlock()
+	if ok && cached.updatedAt.Equal(invoiceType.UpdatedAt) {
+		return cached.schema, nil
+	}
+
+	compiled, err := compileSchema(invoiceType.InvoiceSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[invoiceType.ID] = compiledSchema{updatedAt: invoiceType.UpdatedAt, schema: compiled}
+	s.mu.Unlock()
+
+	return compiled, nil
+}
+
+// ensureNoActiveMigration fails when a migration of the invoice type is still
+// running on any instance. Unfinished jobs whose lease expired were left
+// behind by a process that went away and are marked failed.
+func (s *invoiceTypeService) ensureNoActiveMigration(ctx context.Context, id uuid.UUID) error {
+	active, err := s.versions.FindActiveMigration(ctx, id)
+	if err != nil || active == nil {
+		return err
+	}
+
+	expired, err := s.versions.ExpireMigration(ctx, active.ID, migrationLease)
+	if err != nil {
+		return err
+	}
+	if !expired {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationInProgress).
+			WithDetail("invoice_type_id", id.String()).
+			WithDetail("migration_id", active.ID.String())
+	}
+
+	return nil
+}
+
+func (s *invoiceTypeService) forget(id uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, id)
+	s.mu.Unlock()
+}
+
+func compileSchema(document models.InvoiceSchema) (*schema.Schema, error) {
+	compiled, err := schema.Compile(document)
+	if err != nil {
+		xerr := invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceSchemaInvalid).
+			WithCause(err)
+		if compileErr, ok := err.(*schema.CompileError); ok {
+			xerr.WithDetail("path", compileErr.Path).
+				WithDetail("reason", compileErr.Message)
+		}
+		return nil, xerr
+	}
+	return compiled, nil
+}
+
+// Validation helpers
+
+func validateWorkflow(workflow *models.StatusWorkflow) error {
+	if err := workflow.Validate(); err != nil {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrStatusWorkflowInvalid).
+			WithDetail("field", "status_workflow").
+			WithDetail("reason", err.Error())
+	}
+	return nil
+}
+
+func (s *invoiceTypeService) validateCreateRequest(req *dto.CreateInvoiceTypeRequest) error {
+	if req.OrganizationID == uuid.Nil {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "organization_id").
+			WithDetail("reason", "required")
+	}
+
+	if req.InvoiceType == "" {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "invoice_type").
+			WithDetail("reason", "required")
+	}
+
+	if len(req.InvoiceType) > 255 {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "invoice_type").
+			WithDetail("reason", "too_long").
+			WithDetail("max_length", "255")
+	}
+
+	if len(req.InvoiceSchema) == 0 {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "invoice_schema").
+			WithDetail("reason", "required")
+	}
+
+	if req.DocumentKind != nil && !req.DocumentKind.Valid() {
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeValidationFailed).
+			WithDetail("field", "document_kind").
+			WithDetail("reason", "must be one of invoice, credit_note, debit_note")
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+// DocumentKind tells regular invoices apart from the notes that adjust them
+type DocumentKind string
+
+// Supported document kinds
+const (
+	KindInvoice    DocumentKind = "invoice"
+	KindCreditNote DocumentKind = "credit_note"
+	KindDebitNote  DocumentKind = "debit_note"
+)
+
+// Valid reports whether the kind is supported
+func (k DocumentKind) Valid() bool {
+	switch k {
+	case KindInvoice, KindCreditNote, KindDebitNote:
+		return true
+	}
+	return false
+}
+
+// IsAdjustment reports whether documents of the kind adjust an original invoice
+func (k DocumentKind) IsAdjustment() bool {
+	return k == KindCreditNote || k == KindDebitNote
+}
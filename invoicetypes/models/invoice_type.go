@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSchemaVersion is the version assigned to newly registered schemas
+const DefaultSchemaVersion = "1.0"
+
+// InvoiceType represents an invoice type and the schema its invoices follow
+type InvoiceType struct {
+	ID             uuid.UUID      `db:"id" json:"id"`
+	InvoiceType    string         `db:"invoice_type" json:"invoice_type"`
+	OrganizationID uuid.UUID      `db:"organization_id" json:"organization_id"`
+	ProjectID      *uuid.UUID     `db:"project_id" json:"project_id"`
+	InvoiceSchema  InvoiceSchema  `db:"invoice_schema" json:"invoice_schema"`
+	SchemaVersion  string         `db:"schema_version" json:"schema_version"`
+	StatusWorkflow StatusWorkflow `db:"status_workflow" json:"status_workflow"`
+	DocumentKind   DocumentKind   `db:"document_kind" json:"document_kind"`
+	IsActive       bool           `db:"is_active" json:"is_active"`
+	CreatedBy      *uuid.UUID     `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// InvoiceSchema is a JSON Schema document stored as JSONB.
+//
+// Besides the standard JSON Schema keywords the document carries a "fields"
+// array (required by the invoice_schema_has_required_fields constraint) that
+// lists the top-level property names in display order.
+type InvoiceSchema map[string]any
+
+// Value implements the driver.Valuer interface for database storage
+func (s InvoiceSchema) Value() (driver.Value, error) {
+	if s == nil {
+		return "{}", nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (s *InvoiceSchema) Scan(value any) error {
+	if value == nil {
+		*s = make(InvoiceSchema)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("cannot scan %T into InvoiceSchema", value)
+	}
+}
+
+// EnsureFields fills the "fields" array from the schema properties when the
+// caller did not provide one
+func (s InvoiceSchema) EnsureFields() {
+	if _, ok := s["fields"].([]any); ok {
+		return
+	}
+
+	props, _ := s["properties"].(map[string]any)
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]any, len(names))
+	for i, name := range names {
+		fields[i] = name
+	}
+	s["fields"] = fields
+}
+
+// TableName returns the table name for the InvoiceType model
+func (t InvoiceType) TableName() string {
+	return "invoice_types"
+}
+
+// AppliesTo reports whether invoices of the given organization and project may
+// use this type. Organization-wide types (no project) apply to every project.
+func (t *InvoiceType) AppliesTo(orgID uuid.UUID, projectID *uuid.UUID) bool {
+	if t.OrganizationID != orgID {
+		return false
+	}
+	if t.ProjectID == nil {
+		return true
+	}
+	return projectID != nil && *projectID == *t.ProjectID
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transform operations supported between schema versions
+const (
+	TransformRenameField = "rename_field"
+	TransformSetDefault  = "set_default"
+	TransformDropField   = "drop_field"
+	TransformChangeType  = "change_type"
+)
+
+// Migration statuses
+const (
+	MigrationPending   = "pending"
+	MigrationRunning   = "running"
+	MigrationCompleted = "completed"
+	MigrationFailed    = "failed"
+)
+
+// SchemaVersion is a published, immutable revision of an invoice type schema
+type SchemaVersion struct {
+	ID            uuid.UUID     `db:"id" json:"id"`
+	InvoiceTypeID uuid.UUID     `db:"invoice_type_id" json:"invoice_type_id"`
+	SchemaVersion string        `db:"schema_version" json:"schema_version"`
+	Sequence      int           `db:"sequence" json:"sequence"`
+	InvoiceSchema InvoiceSchema `db:"invoice_schema" json:"invoice_schema"`
+	Transforms    Transforms    `db:"transforms" json:"transforms"`
+	CreatedBy     *uuid.UUID    `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+}
+
+// Transform is a declarative change applied to invoice_data when upgrading
+// from the previous schema version. Field paths use dots for nested objects.
+type Transform struct {
+	Op    string `json:"op"`
+	Field string `json:"field"`
+	To    string `json:"to,omitempty"`    // rename_field: new field path
+	Value any    `json:"value,omitempty"` // set_default: value for missing fields
+	Type  string `json:"type,omitempty"`  // change_type: string, number, integer or boolean
+}
+
+// Transforms is an ordered list of transforms stored as JSONB
+type Transforms []Transform
+
+// Value implements the driver.Valuer interface for database storage
+func (t Transforms) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (t *Transforms) Scan(value any) error {
+	if value == nil {
+		*t = Transforms{}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into Transforms", value)
+	}
+}
+
+// SchemaMigration is a background job that upgrades existing invoices of an
+// invoice type to a target schema version
+type SchemaMigration struct {
+	ID            uuid.UUID         `db:"id" json:"id"`
+	InvoiceTypeID uuid.UUID         `db:"invoice_type_id" json:"invoice_type_id"`
+	ToVersion     string            `db:"to_version" json:"to_version"`
+	Status        string            `db:"status" json:"status"`
+	TotalCount    int               `db:"total_count" json:"total_count"`
+	MigratedCount int               `db:"migrated_count" json:"migrated_count"`
+	FailedCount   int               `db:"failed_count" json:"failed_count"`
+	Failures      MigrationFailures `db:"failures" json:"failures"`
+	SkippedCount  int               `db:"skipped_count" json:"skipped_count"`
+	Skipped       MigrationFailures `db:"skipped" json:"skipped"`
+	Error         *string           `db:"error" json:"error,omitempty"`
+	CreatedBy     *uuid.UUID        `db:"created_by" json:"created_by"`
+	StartedAt     *time.Time        `db:"started_at" json:"started_at"`
+	HeartbeatAt   *time.Time        `db:"heartbeat_at" json:"heartbeat_at"`
+	CompletedAt   *time.Time        `db:"completed_at" json:"completed_at"`
+	CreatedAt     time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// MigrationFailure reports an invoice that could not be migrated, or that
+// was skipped
+type MigrationFailure struct {
+	InvoiceID   uuid.UUID `json:"invoice_id"`
+	FromVersion string    `json:"from_version"`
+	Reason      string    `json:"reason"`
+	Errors      any       `json:"errors,omitempty"`
+}
+
+// MigrationFailures is a list of migration failures stored as JSONB
+type MigrationFailures []MigrationFailure
+
+// Value implements the driver.Valuer interface for database storage
+func (f MigrationFailures) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (f *MigrationFailures) Scan(value any) error {
+	if value == nil {
+		*f = MigrationFailures{}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("cannot scan %T into MigrationFailures", value)
+	}
+}
+
+// TableName returns the table name for the SchemaVersion model
+func (v SchemaVersion) TableName() string {
+	return "invoice_type_schema_versions"
+}
+
+// TableName returns the table name for the SchemaMigration model
+func (m SchemaMigration) TableName() string {
+	return "invoice_schema_migrations"
+}
+
+// IsActive reports whether the migration has not finished yet
+func (m *SchemaMigration) IsActive() bool {
+	return m.Status == MigrationPending || m.Status == MigrationRunning
+}
+
+// MigrationCandidate is an invoice that does not yet conform to the target schema version
+type MigrationCandidate struct {
+	ID                 uuid.UUID       `db:"id"`
+	InvoiceData        json.RawMessage `db:"invoice_data"`
+	SchemaVersion      string          `db:"schema_version"`
+	Version            int             `db:"version"`
+	TaxAuthorityStatus *string         `db:"tax_authority_status"`
+	ApprovalPending    bool            `db:"approval_pending"`
+}
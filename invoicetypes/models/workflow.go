@@ -0,0 +1,111 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// StatusWorkflow defines the statuses an invoice of a type can be in and the
+// transitions allowed between them
+type StatusWorkflow struct {
+	InitialStatus string              `json:"initial_status"`
+	Statuses      []string            `json:"statuses"`
+	Transitions   map[string][]string `json:"transitions"`
+}
+
+// DefaultStatusWorkflow returns the workflow assigned to invoice types that do
+// not define their own: draft → submitted → approved → paid, plus void
+func DefaultStatusWorkflow() StatusWorkflow {
+	return StatusWorkflow{
+		InitialStatus: "draft",
+		Statuses:      []string{"draft", "submitted", "approved", "paid", "void"},
+		Transitions: map[string][]string{
+			"draft":     {"submitted", "void"},
+			"submitted": {"draft", "approved", "void"},
+			"approved":  {"paid", "void"},
+			"paid":      {},
+			"void":      {},
+		},
+	}
+}
+
+// Validate checks that the workflow only references declared statuses
+func (w StatusWorkflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return fmt.Errorf("statuses must not be empty")
+	}
+
+	seen := make(map[string]bool, len(w.Statuses))
+	for _, status := range w.Statuses {
+		if status == "" {
+			return fmt.Errorf("statuses must not contain empty values")
+		}
+		if seen[status] {
+			return fmt.Errorf("status %q is declared more than once", status)
+		}
+		seen[status] = true
+	}
+
+	if !seen[w.InitialStatus] {
+		return fmt.Errorf("initial_status %q is not a declared status", w.InitialStatus)
+	}
+
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transitions reference undeclared status %q", from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("transition %s → %s targets undeclared status", from, to)
+			}
+			if to == from {
+				return fmt.Errorf("status %q cannot transition to itself", from)
+			}
+		}
+	}
+
+	return nil
+}
+
+// HasStatus reports whether the status is declared by the workflow
+func (w StatusWorkflow) HasStatus(status string) bool {
+	return slices.Contains(w.Statuses, status)
+}
+
+// CanTransition reports whether moving from one status to another is allowed
+func (w StatusWorkflow) CanTransition(from, to string) bool {
+	return slices.Contains(w.Transitions[from], to)
+}
+
+// AllowedTransitions returns the statuses reachable from the given one
+func (w StatusWorkflow) AllowedTransitions(from string) []string {
+	targets := w.Transitions[from]
+	if targets == nil {
+		return []string{}
+	}
+	return targets
+}
+
+// Value implements the driver.Valuer interface for database storage
+func (w StatusWorkflow) Value() (driver.Value, error) {
+	return json.Marshal(w)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (w *StatusWorkflow) Scan(value any) error {
+	if value == nil {
+		*w = DefaultStatusWorkflow()
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, w)
+	case string:
+		return json.Unmarshal([]byte(v), w)
+	default:
+		return fmt.Errorf("cannot scan %T into StatusWorkflow", value)
+	}
+}
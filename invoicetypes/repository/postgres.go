@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Abraxas-365/craftable/storex"
+	"github.com/Abraxas-365/craftable/storex/providers/storexpostgres"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 1000
+)
+
+// invoiceTypeRepository implements InvoiceTypeRepository using storex
+type invoiceTypeRepository struct {
+	repo *storexpostgres.PgRepository[models.InvoiceType]
+	db   *sqlx.DB
+}
+
+// NewInvoiceTypeRepository creates a new invoice type repository
+func NewInvoiceTypeRepository(db *sqlx.DB) InvoiceTypeRepository {
+	repo := storexpostgres.NewPgRepository[models.InvoiceType](db, "invoice_types", "id")
+
+	return &invoiceTypeRepository{
+		repo: repo,
+		db:   db,
+	}
+}
+
+// Create creates a new invoice type
+func (r *invoiceTypeRepository) Create(ctx context.Context, invoiceType *models.InvoiceType) (*models.InvoiceType, error) {
+	if invoiceType.ID == uuid.Nil {
+		invoiceType.ID = uuid.New()
+	}
+
+	result, err := r.repo.Create(ctx, *invoiceType)
+	if err != nil {
+		if strings.Contains(err.Error(), "invoice_types_type_org_unique") {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeExists).
+				WithDetail("invoice_type", invoiceType.InvoiceType).
+				WithDetail("organization_id", invoiceType.OrganizationID.String()).
+				WithCause(err)
+		}
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeCreateFailed).
+			WithDetail("invoice_type", invoiceType.InvoiceType).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// GetByID retrieves an invoice type by ID
+func (r *invoiceTypeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.InvoiceType, error) {
+	result, err := r.repo.FindByID(ctx, id.String())
+	if err != nil {
+		if storex.IsRecordNotFound(err) {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeNotFound).
+				WithDetail("invoice_type_id", id.String())
+		}
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeListFailed).
+			WithDetail("invoice_type_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// Update updates an existing invoice type
+func (r *invoiceTypeRepository) Update(ctx context.Context, id uuid.UUID, invoiceType *models.InvoiceType) (*models.InvoiceType, error) {
+	invoiceType.ID = id
+	result, err := r.repo.Update(ctx, id.String(), *invoiceType)
+	if err != nil {
+		if strings.Contains(err.Error(), "invoice_types_type_org_unique") {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeExists).
+				WithDetail("invoice_type", invoiceType.InvoiceType).
+				WithDetail("organization_id", invoiceType.OrganizationID.String()).
+				WithCause(err)
+		}
+		if storex.IsRecordNotFound(err) {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeNotFound).
+				WithDetail("invoice_type_id", id.String())
+		}
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeUpdateFailed).
+			WithDetail("invoice_type_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// Delete deletes an invoice type
+func (r *invoiceTypeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.repo.Delete(ctx, id.String())
+	if err != nil {
+		if storex.IsRecordNotFound(err) {
+			return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeNotFound).
+				WithDetail("invoice_type_id", id.String())
+		}
+		return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeDeleteFailed).
+			WithDetail("invoice_type_id", id.String()).
+			WithCause(err)
+	}
+
+	return nil
+}
+
+// List retrieves invoice types with pagination and filtering
+func (r *invoiceTypeRepository) List(ctx context.Context, req *dto.InvoiceTypeListRequest) (*dto.InvoiceTypeListResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+
+	// Build basic filters for storex
+	filters := make(map[string]any)
+	if req.OrganizationID != nil {
+		filters["organization_id"] = *req.OrganizationID
+	}
+	if req.ProjectID != nil {
+		filters["project_id"] = *req.ProjectID
+	}
+	if req.IsActive != nil {
+		filters["is_active"] = *req.IsActive
+	}
+
+	opts := storex.PaginationOptions{
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		OrderBy:  "invoice_type",
+		Filters:  filters,
+	}
+
+	result, err := r.repo.Paginate(ctx, opts)
+	if err != nil {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeListFailed).
+			WithCause(err)
+	}
+
+	typePtrs := make([]*models.InvoiceType, len(result.Data))
+	for i := range result.Data {
+		typePtrs[i] = &result.Data[i]
+	}
+
+	return &dto.InvoiceTypeListResponse{
+		InvoiceTypes: typePtrs,
+		Total:        int64(result.Page.Total),
+		Page:         result.Page.Number,
+		PageSize:     result.Page.Size,
+		TotalPages:   result.Page.Pages,
+		HasNext:      result.HasNext(),
+		HasPrevious:  result.HasPrevious(),
+	}, nil
+}
+
+// ExistsByNameAndOrganization checks if an invoice type name is taken in an organization
+func (r *invoiceTypeRepository) ExistsByNameAndOrganization(ctx context.Context, name string, orgID uuid.UUID, excludeID *uuid.UUID) (bool, error) {
+	query := `SELECT COUNT(*) FROM invoice_types WHERE invoice_type = $1 AND organization_id = $2`
+	args := []any{name, orgID}
+
+	if excludeID != nil {
+		query += ` AND id != $3`
+		args = append(args, *excludeID)
+	}
+
+	var count int
+	err := r.db.GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return false, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeListFailed).
+			WithDetail("invoice_type", name).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return count > 0, nil
+}
+
+// CountInvoices counts invoices (including soft-deleted ones) that use an invoice type
+func (r *invoiceTypeRepository) CountInvoices(ctx context.Context, id uuid.UUID) (int64, error) {
+	query := `SELECT COUNT(*) FROM invoices WHERE invoice_type_id = $1`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, id)
+	if err != nil {
+		return 0, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeListFailed).
+			WithDetail("invoice_type_id", id.String()).
+			WithCause(err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/dto"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	"github.com/google/uuid"
+)
+
+// InvoiceTypeRepository defines the interface for invoice type repository operations
+type InvoiceTypeRepository interface {
+	// Basic CRUD operations
+	Create(ctx context.Context, invoiceType *models.InvoiceType) (*models.InvoiceType, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.InvoiceType, error)
+	Update(ctx context.Context, id uuid.UUID, invoiceType *models.InvoiceType) (*models.InvoiceType, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Query operations
+	List(ctx context.Context, req *dto.InvoiceTypeListRequest) (*dto.InvoiceTypeListResponse, error)
+	ExistsByNameAndOrganization(ctx context.Context, name string, orgID uuid.UUID, excludeID *uuid.UUID) (bool, error)
+	CountInvoices(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// SchemaVersionRepository defines the interface for schema version history and data migrations
+type SchemaVersionRepository interface {
+	// Version history
+	PublishVersion(ctx context.Context, version *models.SchemaVersion) (*models.SchemaVersion, error)
+	GetVersion(ctx context.Context, invoiceTypeID uuid.UUID, schemaVersion string) (*models.SchemaVersion, error)
+	ListVersions(ctx context.Context, invoiceTypeID uuid.UUID) ([]*models.SchemaVersion, error)
+
+	// Migration jobs
+	CreateMigration(ctx context.Context, migration *models.SchemaMigration) (*models.SchemaMigration, error)
+	GetMigration(ctx context.Context, id uuid.UUID) (*models.SchemaMigration, error)
+	UpdateMigration(ctx context.Context, migration *models.SchemaMigration) error
+	ListMigrations(ctx context.Context, invoiceTypeID uuid.UUID) ([]*models.SchemaMigration, error)
+	FindActiveMigration(ctx context.Context, invoiceTypeID uuid.UUID) (*models.SchemaMigration, error)
+	RenewLease(ctx context.Context, id uuid.UUID) (bool, error)
+	ExpireMigration(ctx context.Context, id uuid.UUID, lease time.Duration) (bool, error)
+
+	// Invoice data
+	CountOutdatedInvoices(ctx context.Context, invoiceTypeID uuid.UUID, schemaVersion string) (int, error)
+	ListOutdatedInvoices(ctx context.Context, invoiceTypeID uuid.UUID, schemaVersion string, afterID uuid.UUID, limit int) ([]models.MigrationCandidate, error)
+	MigrateInvoice(ctx context.Context, invoice *models.MigrationCandidate, data map[string]any, schemaVersion string) (bool, error)
+}
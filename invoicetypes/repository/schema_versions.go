@@ -0,0 +1,351 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Abraxas-365/craftable/storex"
+	"github.com/Abraxas-365/craftable/storex/providers/storexpostgres"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes"
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// schemaVersionRepository implements SchemaVersionRepository
+type schemaVersionRepository struct {
+	migrations *storexpostgres.PgRepository[models.SchemaMigration]
+	db         *sqlx.DB
+}
+
+// NewSchemaVersionRepository creates a new schema version repository
+func NewSchemaVersionRepository(db *sqlx.DB) SchemaVersionRepository {
+	migrations := storexpostgres.NewPgRepository[models.SchemaMigration](db, "invoice_schema_migrations", "id")
+
+	return &schemaVersionRepository{
+		migrations: migrations,
+		db:         db,
+	}
+}
+
+// PublishVersion appends a version to the history of an invoice type and makes
+// it the current schema, in a single transaction
+func (r *schemaVersionRepository) PublishVersion(ctx context.Context, version *models.SchemaVersion) (*models.SchemaVersion, error) {
+	if version.ID == uuid.Nil {
+		version.ID = uuid.New()
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, r.publishError(version, err)
+	}
+	defer tx.Rollback()
+
+	// Lock the invoice type so concurrent publishes get consecutive sequences
+	var exists bool
+	err = tx.GetContext(ctx, &exists, `SELECT true FROM invoice_types WHERE id = $1 FOR UPDATE`, version.InvoiceTypeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeNotFound).
+				WithDetail("invoice_type_id", version.InvoiceTypeID.String())
+		}
+		return nil, r.publishError(version, err)
+	}
+
+	err = tx.GetContext(ctx, &version.Sequence,
+		`SELECT COALESCE(MAX(sequence), 0) + 1 FROM invoice_type_schema_versions WHERE invoice_type_id = $1`,
+		version.InvoiceTypeID)
+	if err != nil {
+		return nil, r.publishError(version, err)
+	}
+
+	insert := `
+		INSERT INTO invoice_type_schema_versions
+			(id, invoice_type_id, schema_version, sequence, invoice_schema, transforms, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *`
+
+	var result models.SchemaVersion
+	err = tx.GetContext(ctx, &result, insert,
+		version.ID, version.InvoiceTypeID, version.SchemaVersion, version.Sequence,
+		version.InvoiceSchema, version.Transforms, version.CreatedBy)
+	if err != nil {
+		if strings.Contains(err.Error(), "schema_versions_type_version_unique") {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrSchemaVersionExists).
+				WithDetail("invoice_type_id", version.InvoiceTypeID.String()).
+				WithDetail("schema_version", version.SchemaVersion).
+				WithCause(err)
+		}
+		return nil, r.publishError(version, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE invoice_types SET invoice_schema = $2, schema_version = $3 WHERE id = $1`,
+		version.InvoiceTypeID, version.InvoiceSchema, version.SchemaVersion)
+	if err != nil {
+		return nil, r.publishError(version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, r.publishError(version, err)
+	}
+
+	return &result, nil
+}
+
+// GetVersion retrieves a published version of an invoice type
+func (r *schemaVersionRepository) GetVersion(ctx context.Context, invoiceTypeID uuid.UUID, schemaVersion string) (*models.SchemaVersion, error) {
+	query := `SELECT * FROM invoice_type_schema_versions WHERE invoice_type_id = $1 AND schema_version = $2`
+
+	var result models.SchemaVersion
+	err := r.db.GetContext(ctx, &result, query, invoiceTypeID, schemaVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrSchemaVersionNotFound).
+				WithDetail("invoice_type_id", invoiceTypeID.String()).
+				WithDetail("schema_version", schemaVersion)
+		}
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeListFailed).
+			WithDetail("invoice_type_id", invoiceTypeID.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// ListVersions retrieves the version history of an invoice type, oldest first
+func (r *schemaVersionRepository) ListVersions(ctx context.Context, invoiceTypeID uuid.UUID) ([]*models.SchemaVersion, error) {
+	query := `SELECT * FROM invoice_type_schema_versions WHERE invoice_type_id = $1 ORDER BY sequence`
+
+	var versions []*models.SchemaVersion
+	if err := r.db.SelectContext(ctx, &versions, query, invoiceTypeID); err != nil {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrInvoiceTypeListFailed).
+			WithDetail("invoice_type_id", invoiceTypeID.String()).
+			WithCause(err)
+	}
+
+	return versions, nil
+}
+
+// CreateMigration creates a new migration job
+func (r *schemaVersionRepository) CreateMigration(ctx context.Context, migration *models.SchemaMigration) (*models.SchemaMigration, error) {
+	if migration.ID == uuid.Nil {
+		migration.ID = uuid.New()
+	}
+
+	result, err := r.migrations.Create(ctx, *migration)
+	if err != nil {
+		if strings.Contains(err.Error(), "idx_schema_migrations_one_active") {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationInProgress).
+				WithDetail("invoice_type_id", migration.InvoiceTypeID.String()).
+				WithCause(err)
+		}
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("invoice_type_id", migration.InvoiceTypeID.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// GetMigration retrieves a migration job by ID
+func (r *schemaVersionRepository) GetMigration(ctx context.Context, id uuid.UUID) (*models.SchemaMigration, error) {
+	result, err := r.migrations.FindByID(ctx, id.String())
+	if err != nil {
+		if storex.IsRecordNotFound(err) {
+			return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationNotFound).
+				WithDetail("migration_id", id.String())
+		}
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("migration_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// UpdateMigration stores the progress of a migration job and renews its
+// lease. Jobs that already finished, or were expired by another instance,
+// are left untouched and reported as an error.
+func (r *schemaVersionRepository) UpdateMigration(ctx context.Context, migration *models.SchemaMigration) error {
+	query := `
+		UPDATE invoice_schema_migrations
+		SET status = $2, total_count = $3, migrated_count = $4, failed_count = $5, failures = $6,
+			skipped_count = $7, skipped = $8, error = $9, started_at = $10, completed_at = $11,
+			heartbeat_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'running')
+		RETURNING *`
+
+	var result models.SchemaMigration
+	err := r.db.GetContext(ctx, &result, query,
+		migration.ID, migration.Status, migration.TotalCount, migration.MigratedCount,
+		migration.FailedCount, migration.Failures, migration.SkippedCount, migration.Skipped,
+		migration.Error, migration.StartedAt, migration.CompletedAt)
+	if err != nil {
+		xerr := invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("migration_id", migration.ID.String())
+		if errors.Is(err, sql.ErrNoRows) {
+			return xerr.WithDetail("reason", "migration is no longer active")
+		}
+		return xerr.WithCause(err)
+	}
+
+	*migration = result
+	return nil
+}
+
+// ListMigrations retrieves the migration jobs of an invoice type, newest first
+func (r *schemaVersionRepository) ListMigrations(ctx context.Context, invoiceTypeID uuid.UUID) ([]*models.SchemaMigration, error) {
+	query := `SELECT * FROM invoice_schema_migrations WHERE invoice_type_id = $1 ORDER BY created_at DESC`
+
+	var migrations []*models.SchemaMigration
+	if err := r.db.SelectContext(ctx, &migrations, query, invoiceTypeID); err != nil {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("invoice_type_id", invoiceTypeID.String()).
+			WithCause(err)
+	}
+
+	return migrations, nil
+}
+
+// FindActiveMigration returns the unfinished migration of an invoice type, or nil
+func (r *schemaVersionRepository) FindActiveMigration(ctx context.Context, invoiceTypeID uuid.UUID) (*models.SchemaMigration, error) {
+	query := `
+		SELECT * FROM invoice_schema_migrations
+		WHERE invoice_type_id = $1 AND status IN ('pending', 'running')
+		LIMIT 1`
+
+	var result models.SchemaMigration
+	err := r.db.GetContext(ctx, &result, query, invoiceTypeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("invoice_type_id", invoiceTypeID.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// RenewLease extends the lease of an unfinished migration job. It reports
+// false once the job finished or was expired by another instance.
+func (r *schemaVersionRepository) RenewLease(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE invoice_schema_migrations SET heartbeat_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'running')`
+
+	renewed, err := r.execAffected(ctx, query, id)
+	if err != nil {
+		return false, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("migration_id", id.String()).
+			WithCause(err)
+	}
+
+	return renewed, nil
+}
+
+// ExpireMigration marks an unfinished migration job failed when its lease was
+// not renewed within the given duration. It reports whether the job expired.
+func (r *schemaVersionRepository) ExpireMigration(ctx context.Context, id uuid.UUID, lease time.Duration) (bool, error) {
+	query := `
+		UPDATE invoice_schema_migrations
+		SET status = 'failed', error = 'interrupted before completion', completed_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'running')
+			AND COALESCE(heartbeat_at, created_at) < NOW() - make_interval(secs => $2)`
+
+	expired, err := r.execAffected(ctx, query, id, lease.Seconds())
+	if err != nil {
+		return false, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("migration_id", id.String()).
+			WithCause(err)
+	}
+
+	return expired, nil
+}
+
+// CountOutdatedInvoices counts invoices whose data does not follow the given schema version
+func (r *schemaVersionRepository) CountOutdatedInvoices(ctx context.Context, invoiceTypeID uuid.UUID, schemaVersion string) (int, error) {
+	query := `SELECT COUNT(*) FROM invoices WHERE invoice_type_id = $1 AND schema_version <> $2`
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, query, invoiceTypeID, schemaVersion); err != nil {
+		return 0, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("invoice_type_id", invoiceTypeID.String()).
+			WithCause(err)
+	}
+
+	return count, nil
+}
+
+// ListOutdatedInvoices returns the next batch of outdated invoices after the
+// given ID, flagging those submitted to the tax authority or under approval
+func (r *schemaVersionRepository) ListOutdatedInvoices(ctx context.Context, invoiceTypeID uuid.UUID, schemaVersion string, afterID uuid.UUID, limit int) ([]models.MigrationCandidate, error) {
+	query := `
+		SELECT i.id, i.invoice_data, i.schema_version, i.version, i.tax_authority_status,
+			EXISTS (
+				SELECT 1 FROM invoice_approvals a WHERE a.invoice_id = i.id AND a.status = 'pending'
+			) AS approval_pending
+		FROM invoices i
+		WHERE i.invoice_type_id = $1 AND i.schema_version <> $2 AND i.id > $3
+		ORDER BY i.id
+		LIMIT $4`
+
+	var candidates []models.MigrationCandidate
+	if err := r.db.SelectContext(ctx, &candidates, query, invoiceTypeID, schemaVersion, afterID, limit); err != nil {
+		return nil, invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrMigrationFailed).
+			WithDetail("invoice_type_id", invoiceTypeID.String()).
+			WithCause(err)
+	}
+
+	return candidates, nil
+}
+
+// MigrateInvoice stores migrated data for an invoice, bumping its version.
+// The revision it produces has no author, marking it as a system change. It
+// reports false when the invoice changed since it was read, or was submitted
+// to the tax authority or for approval in the meantime.
+func (r *schemaVersionRepository) MigrateInvoice(ctx context.Context, invoice *models.MigrationCandidate, data map[string]any, schemaVersion string) (bool, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		UPDATE invoices
+		SET invoice_data = $3, schema_version = $4, version = version + 1, updated_by = NULL
+		WHERE id = $1 AND version = $2 AND tax_authority_status IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM invoice_approvals a WHERE a.invoice_id = invoices.id AND a.status = 'pending'
+			)`
+
+	return r.execAffected(ctx, query, invoice.ID, invoice.Version, payload, schemaVersion)
+}
+
+// execAffected runs a statement and reports whether it changed any row
+func (r *schemaVersionRepository) execAffected(ctx context.Context, query string, args ...any) (bool, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *schemaVersionRepository) publishError(version *models.SchemaVersion, err error) error {
+	return invoicetypes.InvoiceTypesErrors.New(invoicetypes.ErrSchemaVersionPublishFailed).
+		WithDetail("invoice_type_id", version.InvoiceTypeID.String()).
+		WithDetail("schema_version", version.SchemaVersion).
+		WithCause(err)
+}
@@ -0,0 +1,794 @@
+// Package schema implements the subset of JSON Schema (draft 2020-12 / draft-07
+// keywords) used to describe invoice_data payloads per invoice type.
+//
+// Schemas are compiled once and then validated against decoded JSON values
+// (map[string]any, []any, float64, string, bool, nil). Validation never stops
+// at the first problem: every violation is reported as a FieldError so that
+// clients can highlight all offending fields at once.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldError describes a single validation failure
+type FieldError struct {
+	Path     string `json:"path"`               // JSON Pointer to the offending value ("" is the root)
+	Rule     string `json:"rule"`               // Keyword that failed, e.g. "required" or "maximum"
+	Expected any    `json:"expected,omitempty"` // Keyword argument the value was checked against
+	Message  string `json:"message"`
+}
+
+// CompileError reports a problem with the schema document itself
+type CompileError struct {
+	Path    string
+	Message string
+}
+
+func (e *CompileError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Schema is a compiled JSON Schema node
+type Schema struct {
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	noAdditional         bool
+	items                *Schema
+	enum                 []any
+	constValue           any
+	hasConst             bool
+	minimum              *float64
+	maximum              *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	multipleOf           *float64
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	format               string
+	minItems             *int
+	maxItems             *int
+	uniqueItems          bool
+	minProperties        *int
+	maxProperties        *int
+	allOf                []*Schema
+	anyOf                []*Schema
+	oneOf                []*Schema
+	not                  *Schema
+	ref                  string
+
+	root *compiler
+}
+
+// compiler keeps the state shared by every node of one schema document
+type compiler struct {
+	document map[string]any
+	refs     map[string]*Schema
+}
+
+var knownTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// Compile parses a schema document
+func Compile(document map[string]any) (*Schema, error) {
+	if document == nil {
+		return nil, &CompileError{Message: "schema must be an object"}
+	}
+	c := &compiler{document: document, refs: make(map[string]*Schema)}
+	s, err := c.compile(document, "")
+	if err != nil {
+		return nil, err
+	}
+	// Resolve references eagerly so broken $ref pointers fail at compile time.
+	// Resolving may discover further references, so repeat until none is left.
+	for pending := true; pending; {
+		pending = false
+		for ref, compiled := range c.refs {
+			if compiled != nil {
+				continue
+			}
+			if _, err := c.resolve(ref); err != nil {
+				return nil, err
+			}
+			pending = true
+		}
+	}
+	if err := c.checkCycles(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CompileJSON parses a schema document from raw JSON
+func CompileJSON(raw []byte) (*Schema, error) {
+	var document map[string]any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, &CompileError{Message: "schema is not valid JSON: " + err.Error()}
+	}
+	return Compile(document)
+}
+
+func (c *compiler) compile(node any, path string) (*Schema, error) {
+	// Boolean schemas: true accepts everything, false rejects everything
+	if b, ok := node.(bool); ok {
+		if b {
+			return &Schema{root: c}, nil
+		}
+		return &Schema{root: c, not: &Schema{root: c}}, nil
+	}
+
+	obj, ok := node.(map[string]any)
+	if !ok {
+		return nil, &CompileError{Path: path, Message: "schema must be an object or boolean"}
+	}
+
+	s := &Schema{root: c}
+	var err error
+
+	if raw, ok := obj["$ref"]; ok {
+		ref, ok := raw.(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return nil, &CompileError{Path: path + "/$ref", Message: "only local references (#/...) are supported"}
+		}
+		s.ref = ref
+		if _, seen := c.refs[ref]; !seen {
+			c.refs[ref] = nil
+		}
+	}
+
+	if raw, ok := obj["type"]; ok {
+		switch t := raw.(type) {
+		case string:
+			s.types = []string{t}
+		case []any:
+			for _, item := range t {
+				name, ok := item.(string)
+				if !ok {
+					return nil, &CompileError{Path: path + "/type", Message: "type entries must be strings"}
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return nil, &CompileError{Path: path + "/type", Message: "type must be a string or array of strings"}
+		}
+		for _, t := range s.types {
+			if !knownTypes[t] {
+				return nil, &CompileError{Path: path + "/type", Message: "unknown type " + t}
+			}
+		}
+	}
+
+	if raw, ok := obj["properties"]; ok {
+		props, ok := raw.(map[string]any)
+		if !ok {
+			return nil, &CompileError{Path: path + "/properties", Message: "properties must be an object"}
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, sub := range props {
+			if s.properties[name], err = c.compile(sub, path+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if raw, ok := obj["required"]; ok {
+		list, ok := raw.([]any)
+		if !ok {
+			return nil, &CompileError{Path: path + "/required", Message: "required must be an array of strings"}
+		}
+		for _, item := range list {
+			name, ok := item.(string)
+			if !ok {
+				return nil, &CompileError{Path: path + "/required", Message: "required must be an array of strings"}
+			}
+			s.required = append(s.required, name)
+		}
+	}
+
+	if raw, ok := obj["additionalProperties"]; ok {
+		if b, isBool := raw.(bool); isBool {
+			s.noAdditional = !b
+		} else if s.additionalProperties, err = c.compile(raw, path+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+
+	if raw, ok := obj["items"]; ok {
+		if s.items, err = c.compile(raw, path+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	if raw, ok := obj["enum"]; ok {
+		list, ok := raw.([]any)
+		if !ok || len(list) == 0 {
+			return nil, &CompileError{Path: path + "/enum", Message: "enum must be a non-empty array"}
+		}
+		s.enum = list
+	}
+
+	if raw, ok := obj["const"]; ok {
+		s.constValue = raw
+		s.hasConst = true
+	}
+
+	for keyword, target := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	} {
+		if raw, ok := obj[keyword]; ok {
+			n, ok := raw.(float64)
+			if !ok {
+				return nil, &CompileError{Path: path + "/" + keyword, Message: keyword + " must be a number"}
+			}
+			*target = &n
+		}
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, &CompileError{Path: path + "/multipleOf", Message: "multipleOf must be greater than zero"}
+	}
+
+	for keyword, target := range map[string]**int{
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+	} {
+		if raw, ok := obj[keyword]; ok {
+			n, ok := raw.(float64)
+			if !ok || n < 0 || n != math.Trunc(n) {
+				return nil, &CompileError{Path: path + "/" + keyword, Message: keyword + " must be a non-negative integer"}
+			}
+			v := int(n)
+			*target = &v
+		}
+	}
+
+	if raw, ok := obj["pattern"]; ok {
+		expr, ok := raw.(string)
+		if !ok {
+			return nil, &CompileError{Path: path + "/pattern", Message: "pattern must be a string"}
+		}
+		if s.pattern, err = regexp.Compile(expr); err != nil {
+			return nil, &CompileError{Path: path + "/pattern", Message: "invalid pattern: " + err.Error()}
+		}
+	}
+
+	if raw, ok := obj["format"]; ok {
+		format, ok := raw.(string)
+		if !ok {
+			return nil, &CompileError{Path: path + "/format", Message: "format must be a string"}
+		}
+		s.format = format
+	}
+
+	if raw, ok := obj["uniqueItems"]; ok {
+		s.uniqueItems, _ = raw.(bool)
+	}
+
+	for keyword, target := range map[string]*[]*Schema{
+		"allOf": &s.allOf,
+		"anyOf": &s.anyOf,
+		"oneOf": &s.oneOf,
+	} {
+		if raw, ok := obj[keyword]; ok {
+			list, ok := raw.([]any)
+			if !ok || len(list) == 0 {
+				return nil, &CompileError{Path: path + "/" + keyword, Message: keyword + " must be a non-empty array"}
+			}
+			for i, sub := range list {
+				compiled, err := c.compile(sub, fmt.Sprintf("%s/%s/%d", path, keyword, i))
+				if err != nil {
+					return nil, err
+				}
+				*target = append(*target, compiled)
+			}
+		}
+	}
+
+	if raw, ok := obj["not"]; ok {
+		if s.not, err = c.compile(raw, path+"/not"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// resolve returns the compiled schema a local reference points to
+func (c *compiler) resolve(ref string) (*Schema, error) {
+	if compiled := c.refs[ref]; compiled != nil {
+		return compiled, nil
+	}
+
+	var node any = c.document
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			obj, ok := node.(map[string]any)
+			if !ok {
+				return nil, &CompileError{Path: ref, Message: "unresolvable reference"}
+			}
+			if node, ok = obj[unescapePointer(token)]; !ok {
+				return nil, &CompileError{Path: ref, Message: "unresolvable reference"}
+			}
+		}
+	}
+
+	// Register a placeholder first so recursive schemas terminate
+	placeholder := &Schema{root: c}
+	c.refs[ref] = placeholder
+	compiled, err := c.compile(node, ref)
+	if err != nil {
+		return nil, err
+	}
+	*placeholder = *compiled
+	return placeholder, nil
+}
+
+// checkCycles rejects references that lead back to themselves without
+// descending into a property or array item. Such a schema would validate
+// the same value forever.
+func (c *compiler) checkCycles(root *Schema) error {
+	// Collect every node, following all keywords
+	var nodes []*Schema
+	seen := make(map[*Schema]bool)
+	queue := []*Schema{root}
+	for _, compiled := range c.refs {
+		queue = append(queue, compiled)
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s == nil || seen[s] {
+			continue
+		}
+		seen[s] = true
+		nodes = append(nodes, s)
+		for _, sub := range s.properties {
+			queue = append(queue, sub)
+		}
+		queue = append(queue, s.additionalProperties, s.items)
+		queue = append(queue, s.sameValue()...)
+	}
+
+	// Look for a cycle among the keywords that apply to the same value
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make(map[*Schema]int, len(nodes))
+	var visit func(s *Schema, via string) error
+	visit = func(s *Schema, via string) error {
+		switch state[s] {
+		case active:
+			return &CompileError{Path: via, Message: "reference cycle never descends into a property or item"}
+		case done:
+			return nil
+		}
+		state[s] = active
+		if s.ref != "" {
+			if err := visit(c.refs[s.ref], s.ref); err != nil {
+				return err
+			}
+		}
+		for _, sub := range s.sameValue()[1:] {
+			if err := visit(sub, via); err != nil {
+				return err
+			}
+		}
+		state[s] = done
+		return nil
+	}
+	for _, s := range nodes {
+		if err := visit(s, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameValue returns the schemas that validate the same value as s: the $ref
+// target first (nil without one), then allOf, anyOf, oneOf and not
+func (s *Schema) sameValue() []*Schema {
+	var target *Schema
+	if s.ref != "" {
+		target = s.root.refs[s.ref]
+	}
+	out := []*Schema{target}
+	out = append(out, s.allOf...)
+	out = append(out, s.anyOf...)
+	out = append(out, s.oneOf...)
+	if s.not != nil {
+		out = append(out, s.not)
+	}
+	return out
+}
+
+// Property returns the schema of the property at the given path, looking
+// through $ref and the allOf, anyOf and oneOf branches. Properties allowed
+// only by additionalProperties are not found.
+func (s *Schema) Property(path ...string) (*Schema, bool) {
+	current := s.target()
+	for _, name := range path {
+		next, ok := current.property(name)
+		if !ok {
+			return nil, false
+		}
+		current = next.target()
+	}
+	return current, true
+}
+
+// Types returns the JSON types the schema allows; empty allows any type
+func (s *Schema) Types() []string {
+	return s.target().types
+}
+
+// Format returns the format keyword of the schema, if any
+func (s *Schema) Format() string {
+	return s.target().format
+}
+
+func (s *Schema) property(name string) (*Schema, bool) {
+	if sub, ok := s.properties[name]; ok {
+		return sub, true
+	}
+	for _, branches := range [][]*Schema{s.allOf, s.anyOf, s.oneOf} {
+		for _, branch := range branches {
+			if sub, ok := branch.target().property(name); ok {
+				return sub, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// target follows $ref to the schema that describes the value. Chains are
+// bounded so self-referencing schemas terminate.
+func (s *Schema) target() *Schema {
+	for i := 0; s.ref != "" && i < 32; i++ {
+		resolved, err := s.root.resolve(s.ref)
+		if err != nil || resolved == s {
+			break
+		}
+		s = resolved
+	}
+	return s
+}
+
+// Validate checks a decoded JSON value and returns every violation found
+func (s *Schema) Validate(value any) []FieldError {
+	var errs []FieldError
+	s.validate(normalize(value), "", &errs)
+	return errs
+}
+
+func (s *Schema) validate(value any, path string, errs *[]FieldError) {
+	if s.ref != "" {
+		target, err := s.root.resolve(s.ref)
+		if err != nil {
+			*errs = append(*errs, FieldError{Path: path, Rule: "$ref", Expected: s.ref, Message: err.Error()})
+			return
+		}
+		target.validate(value, path, errs)
+	}
+
+	if len(s.types) > 0 && !matchesAnyType(value, s.types) {
+		expected := any(s.types)
+		if len(s.types) == 1 {
+			expected = s.types[0]
+		}
+		*errs = append(*errs, FieldError{
+			Path: path, Rule: "type", Expected: expected,
+			Message: fmt.Sprintf("expected %s but got %s", strings.Join(s.types, " or "), typeOf(value)),
+		})
+		// Further keyword checks would only produce noise for a mistyped value
+		return
+	}
+
+	if s.hasConst && !reflect.DeepEqual(value, normalize(s.constValue)) {
+		*errs = append(*errs, FieldError{Path: path, Rule: "const", Expected: s.constValue, Message: "value does not match the required constant"})
+	}
+
+	if s.enum != nil && !containsValue(s.enum, value) {
+		*errs = append(*errs, FieldError{Path: path, Rule: "enum", Expected: s.enum, Message: "value is not one of the allowed values"})
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		s.validateObject(v, path, errs)
+	case []any:
+		s.validateArray(v, path, errs)
+	case string:
+		s.validateString(v, path, errs)
+	case float64:
+		s.validateNumber(v, path, errs)
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(value, path, errs)
+	}
+
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if len(sub.Validate(value)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*errs = append(*errs, FieldError{Path: path, Rule: "anyOf", Message: "value does not match any of the allowed schemas"})
+		}
+	}
+
+	if len(s.oneOf) > 0 {
+		matches := 0
+		for _, sub := range s.oneOf {
+			if len(sub.Validate(value)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			*errs = append(*errs, FieldError{Path: path, Rule: "oneOf", Expected: 1, Message: fmt.Sprintf("value matches %d schemas, expected exactly one", matches)})
+		}
+	}
+
+	if s.not != nil && len(s.not.Validate(value)) == 0 {
+		*errs = append(*errs, FieldError{Path: path, Rule: "not", Message: "value matches a disallowed schema"})
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]any, path string, errs *[]FieldError) {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, FieldError{
+				Path: path + "/" + escapePointer(name), Rule: "required", Expected: true,
+				Message: name + " is required",
+			})
+		}
+	}
+
+	if s.minProperties != nil && len(obj) < *s.minProperties {
+		*errs = append(*errs, FieldError{Path: path, Rule: "minProperties", Expected: *s.minProperties, Message: fmt.Sprintf("must have at least %d properties", *s.minProperties)})
+	}
+	if s.maxProperties != nil && len(obj) > *s.maxProperties {
+		*errs = append(*errs, FieldError{Path: path, Rule: "maxProperties", Expected: *s.maxProperties, Message: fmt.Sprintf("must have at most %d properties", *s.maxProperties)})
+	}
+
+	// Iterate in a stable order so error lists are deterministic
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path + "/" + escapePointer(key)
+		if sub, ok := s.properties[key]; ok {
+			sub.validate(obj[key], childPath, errs)
+			continue
+		}
+		if s.noAdditional {
+			*errs = append(*errs, FieldError{Path: childPath, Rule: "additionalProperties", Expected: false, Message: key + " is not an allowed property"})
+		} else if s.additionalProperties != nil {
+			s.additionalProperties.validate(obj[key], childPath, errs)
+		}
+	}
+}
+
+func (s *Schema) validateArray(arr []any, path string, errs *[]FieldError) {
+	if s.minItems != nil && len(arr) < *s.minItems {
+		*errs = append(*errs, FieldError{Path: path, Rule: "minItems", Expected: *s.minItems, Message: fmt.Sprintf("must contain at least %d items", *s.minItems)})
+	}
+	if s.maxItems != nil && len(arr) > *s.maxItems {
+		*errs = append(*errs, FieldError{Path: path, Rule: "maxItems", Expected: *s.maxItems, Message: fmt.Sprintf("must contain at most %d items", *s.maxItems)})
+	}
+	if s.uniqueItems {
+		for i := 0; i < len(arr); i++ {
+			for j := i + 1; j < len(arr); j++ {
+				if reflect.DeepEqual(arr[i], arr[j]) {
+					*errs = append(*errs, FieldError{Path: fmt.Sprintf("%s/%d", path, j), Rule: "uniqueItems", Expected: true, Message: fmt.Sprintf("duplicates item %d", i)})
+				}
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range arr {
+			s.items.validate(item, fmt.Sprintf("%s/%d", path, i), errs)
+		}
+	}
+}
+
+func (s *Schema) validateString(str string, path string, errs *[]FieldError) {
+	length := len([]rune(str))
+	if s.minLength != nil && length < *s.minLength {
+		*errs = append(*errs, FieldError{Path: path, Rule: "minLength", Expected: *s.minLength, Message: fmt.Sprintf("must be at least %d characters", *s.minLength)})
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		*errs = append(*errs, FieldError{Path: path, Rule: "maxLength", Expected: *s.maxLength, Message: fmt.Sprintf("must be at most %d characters", *s.maxLength)})
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		*errs = append(*errs, FieldError{Path: path, Rule: "pattern", Expected: s.pattern.String(), Message: "does not match the required pattern"})
+	}
+	if s.format != "" && !checkFormat(s.format, str) {
+		*errs = append(*errs, FieldError{Path: path, Rule: "format", Expected: s.format, Message: "is not a valid " + s.format})
+	}
+}
+
+func (s *Schema) validateNumber(n float64, path string, errs *[]FieldError) {
+	if s.minimum != nil && n < *s.minimum {
+		*errs = append(*errs, FieldError{Path: path, Rule: "minimum", Expected: *s.minimum, Message: fmt.Sprintf("must be >= %v", *s.minimum)})
+	}
+	if s.maximum != nil && n > *s.maximum {
+		*errs = append(*errs, FieldError{Path: path, Rule: "maximum", Expected: *s.maximum, Message: fmt.Sprintf("must be <= %v", *s.maximum)})
+	}
+	if s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum {
+		*errs = append(*errs, FieldError{Path: path, Rule: "exclusiveMinimum", Expected: *s.exclusiveMinimum, Message: fmt.Sprintf("must be > %v", *s.exclusiveMinimum)})
+	}
+	if s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum {
+		*errs = append(*errs, FieldError{Path: path, Rule: "exclusiveMaximum", Expected: *s.exclusiveMaximum, Message: fmt.Sprintf("must be < %v", *s.exclusiveMaximum)})
+	}
+	if s.multipleOf != nil {
+		quotient := n / *s.multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			*errs = append(*errs, FieldError{Path: path, Rule: "multipleOf", Expected: *s.multipleOf, Message: fmt.Sprintf("must be a multiple of %v", *s.multipleOf)})
+		}
+	}
+}
+
+// Helpers
+
+func matchesAnyType(value any, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := value.(float64); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(list []any, value any) bool {
+	for _, candidate := range list {
+		if reflect.DeepEqual(normalize(candidate), value) {
+			return true
+		}
+	}
+	return false
+}
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+func checkFormat(format, value string) bool {
+	switch format {
+	case "date":
+		if !datePattern.MatchString(value) {
+			return false
+		}
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	case "uuid":
+		_, err := uuid.Parse(value)
+		return err == nil
+	default:
+		// Unknown formats are annotations only, as the specification allows
+		return true
+	}
+}
+
+// normalize converts Go values produced outside encoding/json (typed maps,
+// integers) into the generic representation the validator works with
+func normalize(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string, float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+
+	// Maps and slices (including named types such as models.InvoiceData, and
+	// nested Go values inside them) round-trip through JSON
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return value
+	}
+	return generic
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
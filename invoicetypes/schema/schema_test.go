@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func mustCompile(t *testing.T, raw string) *Schema {
+	t.Helper()
+	s, err := CompileJSON([]byte(raw))
+	if err != nil {
+		t.Fatalf("compile %s: %v", raw, err)
+	}
+	return s
+}
+
+func rules(errs []FieldError) []string {
+	var out []string
+	for _, e := range errs {
+		out = append(out, e.Path+" "+e.Rule)
+	}
+	return out
+}
+
+func TestCompileRejectsInvalidSchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantPath string
+	}{
+		{"remote ref", `{"$ref":"https://example.com/schema.json"}`, "/$ref"},
+		{"unknown type", `{"type":"money"}`, "/type"},
+		{"bad pattern", `{"type":"string","pattern":"("}`, "/pattern"},
+		{"negative minLength", `{"minLength":-1}`, "/minLength"},
+		{"zero multipleOf", `{"multipleOf":0}`, "/multipleOf"},
+		{"empty enum", `{"enum":[]}`, "/enum"},
+		{"unresolvable ref", `{"$ref":"#/$defs/missing"}`, "#/$defs/missing"},
+		{"self reference", `{"$ref":"#"}`, "#"},
+		{"self referencing definition", `{"$defs":{"a":{"$ref":"#/$defs/a"}},"$ref":"#/$defs/a"}`, "#/$defs/a"},
+		{"mutual references", `{"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"allOf":[{"$ref":"#/$defs/a"}]}},"$ref":"#/$defs/a"}`, ""},
+		{"cycle through not", `{"$defs":{"a":{"not":{"$ref":"#/$defs/a"}}},"properties":{"x":{"$ref":"#/$defs/a"}}}`, "#/$defs/a"},
+		{"cycle through anyOf", `{"anyOf":[{"type":"string"},{"$ref":"#"}]}`, "#"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileJSON([]byte(tt.raw))
+			var compileErr *CompileError
+			if !errors.As(err, &compileErr) {
+				t.Fatalf("err = %v; want a CompileError", err)
+			}
+			if tt.wantPath != "" && compileErr.Path != tt.wantPath {
+				t.Errorf("path = %q; want %q", compileErr.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestRecursiveSchemaThroughProperties(t *testing.T) {
+	s := mustCompile(t, `{
+		"$defs": {
+			"node": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string"},
+					"children": {"type": "array", "items": {"$ref": "#/$defs/node"}},
+					"parent": {"$ref": "#/$defs/node"}
+				}
+			}
+		},
+		"$ref": "#/$defs/node"
+	}`)
+
+	value := map[string]any{
+		"name": "root",
+		"children": []any{
+			map[string]any{"name": "a"},
+			map[string]any{"children": []any{map[string]any{"name": 1}}},
+		},
+		"parent": map[string]any{"name": "up"},
+	}
+	got := rules(s.Validate(value))
+	want := []string{"/children/1/name required", "/children/1/children/0/name type"}
+	if !slices.Equal(got, want) {
+		t.Errorf("errors = %v; want %v", got, want)
+	}
+}
+
+func TestValidateReportsEveryViolation(t *testing.T) {
+	s := mustCompile(t, `{
+		"type": "object",
+		"required": ["supplier", "total"],
+		"additionalProperties": false,
+		"properties": {
+			"supplier": {"type": "string", "minLength": 3},
+			"total": {"type": "number", "minimum": 0, "multipleOf": 0.01},
+			"issued": {"type": "string", "format": "date"},
+			"tags": {"type": "array", "uniqueItems": true, "items": {"enum": ["a", "b"]}}
+		}
+	}`)
+
+	value := map[string]any{
+		"supplier": "ab",
+		"total":    -1.005,
+		"issued":   "2023-02-30",
+		"tags":     []any{"a", "a", "c"},
+		"extra":    true,
+	}
+	got := rules(s.Validate(value))
+	want := []string{
+		"/extra additionalProperties",
+		"/issued format",
+		"/supplier minLength",
+		"/tags/1 uniqueItems",
+		"/tags/2 enum",
+		"/total minimum",
+		"/total multipleOf",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("errors = %v; want %v", got, want)
+	}
+
+	if errs := s.Validate(map[string]any{"supplier": "ACME", "total": 10}); len(errs) != 0 {
+		t.Errorf("valid value reported %v", errs)
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		value any
+		want  []string
+	}{
+		{"anyOf match", `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, 3, nil},
+		{"anyOf miss", `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, 3.5, []string{" anyOf"}},
+		{"oneOf twice", `{"oneOf":[{"type":"number"},{"type":"integer"}]}`, 3, []string{" oneOf"}},
+		{"not", `{"not":{"const":"x"}}`, "x", []string{" not"}},
+		{"false schema", `{"properties":{"a":false}}`, map[string]any{"a": 1}, []string{"/a not"}},
+		{"shared definition", `{"$defs":{"s":{"type":"string"}},"properties":{"a":{"$ref":"#/$defs/s"},"b":{"$ref":"#/$defs/s"}}}`, map[string]any{"a": 1, "b": 2}, []string{"/a type", "/b type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(mustCompile(t, tt.raw).Validate(tt.value))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("errors = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProperty(t *testing.T) {
+	s := mustCompile(t, `{
+		"$defs": {"money": {"type": "number"}},
+		"properties": {"amounts": {"allOf": [{"properties": {"net": {"$ref": "#/$defs/money"}}}]}}
+	}`)
+	sub, ok := s.Property("amounts", "net")
+	if !ok {
+		t.Fatal("amounts.net not found")
+	}
+	if got := sub.Types(); !slices.Equal(got, []string{"number"}) {
+		t.Errorf("types = %v; want [number]", got)
+	}
+	if _, ok := s.Property("amounts", "gross"); ok {
+		t.Error("amounts.gross found; want missing")
+	}
+}
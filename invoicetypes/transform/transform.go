@@ -0,0 +1,253 @@
+// Package transform applies the declarative data transforms that accompany a
+// new invoice schema version.
+package transform
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+// Error describes a transform that is malformed or cannot be applied to a payload
+type Error struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("transform %d (%s %s): %s", e.Index, e.Op, e.Field, e.Message)
+}
+
+// Validate checks that every transform is well formed
+func Validate(transforms models.Transforms) error {
+	for i, t := range transforms {
+		fail := func(msg string) error {
+			return &Error{Index: i, Op: t.Op, Field: t.Field, Message: msg}
+		}
+
+		if !validPath(t.Field) {
+			return fail("field must be a non-empty dot separated path")
+		}
+
+		switch t.Op {
+		case models.TransformRenameField:
+			if !validPath(t.To) {
+				return fail("to must be a non-empty dot separated path")
+			}
+			if t.To == t.Field {
+				return fail("to must differ from field")
+			}
+		case models.TransformSetDefault:
+			if t.Value == nil {
+				return fail("value is required")
+			}
+		case models.TransformDropField:
+		case models.TransformChangeType:
+			switch t.Type {
+			case "string", "number", "integer", "boolean":
+			default:
+				return fail("type must be one of string, number, integer, boolean")
+			}
+		default:
+			return fail("unknown op")
+		}
+	}
+
+	return nil
+}
+
+// Apply runs the transforms in order against a copy of data and returns the
+// transformed payload. The input map is never modified.
+func Apply(data map[string]any, transforms models.Transforms) (map[string]any, error) {
+	result := deepCopy(data).(map[string]any)
+
+	for i, t := range transforms {
+		fail := func(msg string) error {
+			return &Error{Index: i, Op: t.Op, Field: t.Field, Message: msg}
+		}
+
+		switch t.Op {
+		case models.TransformRenameField:
+			value, ok := lookup(result, t.Field)
+			if !ok {
+				continue
+			}
+			if _, exists := lookup(result, t.To); exists {
+				return nil, fail("target field " + t.To + " already exists")
+			}
+			remove(result, t.Field)
+			if err := set(result, t.To, value); err != nil {
+				return nil, fail(err.Error())
+			}
+
+		case models.TransformSetDefault:
+			if value, ok := lookup(result, t.Field); ok && value != nil {
+				continue
+			}
+			if err := set(result, t.Field, deepCopy(t.Value)); err != nil {
+				return nil, fail(err.Error())
+			}
+
+		case models.TransformDropField:
+			remove(result, t.Field)
+
+		case models.TransformChangeType:
+			value, ok := lookup(result, t.Field)
+			if !ok || value == nil {
+				continue
+			}
+			converted, err := convert(value, t.Type)
+			if err != nil {
+				return nil, fail(err.Error())
+			}
+			if err := set(result, t.Field, converted); err != nil {
+				return nil, fail(err.Error())
+			}
+
+		default:
+			return nil, fail("unknown op")
+		}
+	}
+
+	return result, nil
+}
+
+// Path helpers
+
+func validPath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func lookup(data map[string]any, path string) (any, bool) {
+	parts := strings.Split(path, ".")
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	value, ok := current[parts[len(parts)-1]]
+	return value, ok
+}
+
+func set(data map[string]any, path string, value any) error {
+	parts := strings.Split(path, ".")
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		raw, exists := current[part]
+		if !exists || raw == nil {
+			next := make(map[string]any)
+			current[part] = next
+			current = next
+			continue
+		}
+		next, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is not an object", part)
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+func remove(data map[string]any, path string) {
+	parts := strings.Split(path, ".")
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+}
+
+// convert changes the JSON type of a scalar value
+func convert(value any, target string) (any, error) {
+	switch target {
+	case "string":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+
+	case "number", "integer":
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to %s", v, target)
+			}
+			number = parsed
+		case bool:
+			if v {
+				number = 1
+			}
+		default:
+			return nil, fmt.Errorf("cannot convert %T to %s", value, target)
+		}
+		if target == "integer" && number != math.Trunc(number) {
+			return nil, fmt.Errorf("%v is not an integer", number)
+		}
+		return number, nil
+
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to boolean", v)
+			}
+			return parsed, nil
+		case float64:
+			return v != 0, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert %T to %s", value, target)
+}
+
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = deepCopy(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = deepCopy(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,306 @@
+package transform
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+)
+
+func decode(t *testing.T, document string) map[string]any {
+	t.Helper()
+	var value map[string]any
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		transforms models.Transforms
+		want       string
+	}{
+		{
+			name:       "rename",
+			data:       `{"vendor": "ACME", "total": 10}`,
+			transforms: models.Transforms{{Op: models.TransformRenameField, Field: "vendor", To: "supplier"}},
+			want:       `{"supplier": "ACME", "total": 10}`,
+		},
+		{
+			name:       "rename into a new nested object",
+			data:       `{"vendor": "ACME"}`,
+			transforms: models.Transforms{{Op: models.TransformRenameField, Field: "vendor", To: "supplier.name"}},
+			want:       `{"supplier": {"name": "ACME"}}`,
+		},
+		{
+			name:       "rename out of a nested object",
+			data:       `{"supplier": {"name": "ACME", "id": 1}}`,
+			transforms: models.Transforms{{Op: models.TransformRenameField, Field: "supplier.name", To: "supplier_name"}},
+			want:       `{"supplier": {"id": 1}, "supplier_name": "ACME"}`,
+		},
+		{
+			name:       "rename of a missing field",
+			data:       `{"total": 10}`,
+			transforms: models.Transforms{{Op: models.TransformRenameField, Field: "vendor", To: "supplier"}},
+			want:       `{"total": 10}`,
+		},
+		{
+			name:       "rename keeps null",
+			data:       `{"vendor": null}`,
+			transforms: models.Transforms{{Op: models.TransformRenameField, Field: "vendor", To: "supplier"}},
+			want:       `{"supplier": null}`,
+		},
+		{
+			name:       "default for a missing field",
+			data:       `{"total": 10}`,
+			transforms: models.Transforms{{Op: models.TransformSetDefault, Field: "currency", Value: "PEN"}},
+			want:       `{"total": 10, "currency": "PEN"}`,
+		},
+		{
+			name:       "default for a null field",
+			data:       `{"currency": null}`,
+			transforms: models.Transforms{{Op: models.TransformSetDefault, Field: "currency", Value: "PEN"}},
+			want:       `{"currency": "PEN"}`,
+		},
+		{
+			name:       "default keeps a set field",
+			data:       `{"currency": "USD"}`,
+			transforms: models.Transforms{{Op: models.TransformSetDefault, Field: "currency", Value: "PEN"}},
+			want:       `{"currency": "USD"}`,
+		},
+		{
+			name:       "default in a nested object",
+			data:       `{"payment": null}`,
+			transforms: models.Transforms{{Op: models.TransformSetDefault, Field: "payment.terms", Value: map[string]any{"days": 30.0}}},
+			want:       `{"payment": {"terms": {"days": 30}}}`,
+		},
+		{
+			name:       "drop",
+			data:       `{"legacy": {"a": 1}, "total": 10}`,
+			transforms: models.Transforms{{Op: models.TransformDropField, Field: "legacy"}},
+			want:       `{"total": 10}`,
+		},
+		{
+			name:       "drop of a nested field",
+			data:       `{"supplier": {"name": "ACME", "fax": "123"}}`,
+			transforms: models.Transforms{{Op: models.TransformDropField, Field: "supplier.fax"}},
+			want:       `{"supplier": {"name": "ACME"}}`,
+		},
+		{
+			name:       "drop of a missing or unreachable field",
+			data:       `{"supplier": "ACME"}`,
+			transforms: models.Transforms{{Op: models.TransformDropField, Field: "supplier.fax"}, {Op: models.TransformDropField, Field: "legacy"}},
+			want:       `{"supplier": "ACME"}`,
+		},
+		{
+			name: "change to string",
+			data: `{"a": 12.5, "b": true, "c": "x"}`,
+			transforms: models.Transforms{
+				{Op: models.TransformChangeType, Field: "a", Type: "string"},
+				{Op: models.TransformChangeType, Field: "b", Type: "string"},
+				{Op: models.TransformChangeType, Field: "c", Type: "string"},
+			},
+			want: `{"a": "12.5", "b": "true", "c": "x"}`,
+		},
+		{
+			name: "change to number",
+			data: `{"a": " 12.5 ", "b": true, "c": false, "d": 3}`,
+			transforms: models.Transforms{
+				{Op: models.TransformChangeType, Field: "a", Type: "number"},
+				{Op: models.TransformChangeType, Field: "b", Type: "number"},
+				{Op: models.TransformChangeType, Field: "c", Type: "number"},
+				{Op: models.TransformChangeType, Field: "d", Type: "number"},
+			},
+			want: `{"a": 12.5, "b": 1, "c": 0, "d": 3}`,
+		},
+		{
+			name:       "change to integer",
+			data:       `{"qty": "4"}`,
+			transforms: models.Transforms{{Op: models.TransformChangeType, Field: "qty", Type: "integer"}},
+			want:       `{"qty": 4}`,
+		},
+		{
+			name: "change to boolean",
+			data: `{"a": "true", "b": 0, "c": 2, "d": false}`,
+			transforms: models.Transforms{
+				{Op: models.TransformChangeType, Field: "a", Type: "boolean"},
+				{Op: models.TransformChangeType, Field: "b", Type: "boolean"},
+				{Op: models.TransformChangeType, Field: "c", Type: "boolean"},
+				{Op: models.TransformChangeType, Field: "d", Type: "boolean"},
+			},
+			want: `{"a": true, "b": false, "c": true, "d": false}`,
+		},
+		{
+			name: "change type of a missing or null field",
+			data: `{"a": null}`,
+			transforms: models.Transforms{
+				{Op: models.TransformChangeType, Field: "a", Type: "number"},
+				{Op: models.TransformChangeType, Field: "b", Type: "number"},
+			},
+			want: `{"a": null}`,
+		},
+		{
+			name: "transforms run in order",
+			data: `{"amount": "10"}`,
+			transforms: models.Transforms{
+				{Op: models.TransformRenameField, Field: "amount", To: "total"},
+				{Op: models.TransformChangeType, Field: "total", Type: "number"},
+				{Op: models.TransformSetDefault, Field: "amount", Value: 0.0},
+			},
+			want: `{"total": 10, "amount": 0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.transforms); err != nil {
+				t.Fatalf("transforms are invalid: %v", err)
+			}
+			data := decode(t, tt.data)
+			got, err := Apply(data, tt.transforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("result = %v; want %v", got, want)
+			}
+			if original := decode(t, tt.data); !reflect.DeepEqual(data, original) {
+				t.Errorf("input changed to %v", data)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		transforms models.Transforms
+		wantIndex  int
+		wantError  string
+	}{
+		{
+			name:       "rename onto an existing field",
+			data:       `{"vendor": "ACME", "supplier": "Other"}`,
+			transforms: models.Transforms{{Op: models.TransformRenameField, Field: "vendor", To: "supplier"}},
+			wantError:  "already exists",
+		},
+		{
+			name:       "rename under a scalar",
+			data:       `{"vendor": "ACME", "supplier": "Other"}`,
+			transforms: models.Transforms{{Op: models.TransformRenameField, Field: "vendor", To: "supplier.name"}},
+			wantError:  "supplier is not an object",
+		},
+		{
+			name:       "default under a scalar",
+			data:       `{"payment": 30}`,
+			transforms: models.Transforms{{Op: models.TransformSetDefault, Field: "payment.days", Value: 30.0}},
+			wantError:  "payment is not an object",
+		},
+		{
+			name: "text to number",
+			data: `{"total": "ten"}`,
+			transforms: models.Transforms{
+				{Op: models.TransformDropField, Field: "legacy"},
+				{Op: models.TransformChangeType, Field: "total", Type: "number"},
+			},
+			wantIndex: 1,
+			wantError: `cannot convert "ten" to number`,
+		},
+		{
+			name:       "fraction to integer",
+			data:       `{"qty": 1.5}`,
+			transforms: models.Transforms{{Op: models.TransformChangeType, Field: "qty", Type: "integer"}},
+			wantError:  "1.5 is not an integer",
+		},
+		{
+			name:       "text to boolean",
+			data:       `{"paid": "yes"}`,
+			transforms: models.Transforms{{Op: models.TransformChangeType, Field: "paid", Type: "boolean"}},
+			wantError:  `cannot convert "yes" to boolean`,
+		},
+		{
+			name:       "object to string",
+			data:       `{"supplier": {"name": "ACME"}}`,
+			transforms: models.Transforms{{Op: models.TransformChangeType, Field: "supplier", Type: "string"}},
+			wantError:  "cannot convert map[string]interface {} to string",
+		},
+		{
+			name:       "array to number",
+			data:       `{"lines": [1]}`,
+			transforms: models.Transforms{{Op: models.TransformChangeType, Field: "lines", Type: "number"}},
+			wantError:  "cannot convert []interface {} to number",
+		},
+		{
+			name:       "unknown op",
+			data:       `{}`,
+			transforms: models.Transforms{{Op: "move_field", Field: "a"}},
+			wantError:  "unknown op",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := decode(t, tt.data)
+			_, err := Apply(data, tt.transforms)
+
+			var transformErr *Error
+			if !errors.As(err, &transformErr) {
+				t.Fatalf("err = %v; want a transform error", err)
+			}
+			if transformErr.Index != tt.wantIndex || !strings.Contains(transformErr.Message, tt.wantError) {
+				t.Errorf("err = %v; want transform %d failing with %q", err, tt.wantIndex, tt.wantError)
+			}
+			if original := decode(t, tt.data); !reflect.DeepEqual(data, original) {
+				t.Errorf("input changed to %v", data)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform models.Transform
+		wantError string // empty when valid
+	}{
+		{"rename", models.Transform{Op: models.TransformRenameField, Field: "a.b", To: "c"}, ""},
+		{"rename without target", models.Transform{Op: models.TransformRenameField, Field: "a"}, "to must be"},
+		{"rename onto itself", models.Transform{Op: models.TransformRenameField, Field: "a", To: "a"}, "to must differ"},
+		{"rename to an empty segment", models.Transform{Op: models.TransformRenameField, Field: "a", To: "b..c"}, "to must be"},
+		{"default", models.Transform{Op: models.TransformSetDefault, Field: "a", Value: false}, ""},
+		{"default without value", models.Transform{Op: models.TransformSetDefault, Field: "a"}, "value is required"},
+		{"drop", models.Transform{Op: models.TransformDropField, Field: "a"}, ""},
+		{"change type", models.Transform{Op: models.TransformChangeType, Field: "a", Type: "integer"}, ""},
+		{"change to an unknown type", models.Transform{Op: models.TransformChangeType, Field: "a", Type: "date"}, "type must be"},
+		{"empty field", models.Transform{Op: models.TransformDropField}, "field must be"},
+		{"trailing dot", models.Transform{Op: models.TransformDropField, Field: "a."}, "field must be"},
+		{"unknown op", models.Transform{Op: "copy_field", Field: "a"}, "unknown op"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transforms := models.Transforms{{Op: models.TransformDropField, Field: "legacy"}, tt.transform}
+			err := Validate(transforms)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("err = %v; want nil", err)
+				}
+				return
+			}
+			var transformErr *Error
+			if !errors.As(err, &transformErr) {
+				t.Fatalf("err = %v; want a transform error", err)
+			}
+			if transformErr.Index != 1 || !strings.Contains(transformErr.Message, tt.wantError) {
+				t.Errorf("err = %v; want transform 1 failing with %q", err, tt.wantError)
+			}
+		})
+	}
+}
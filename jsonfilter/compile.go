@@ -0,0 +1,231 @@
+package jsonfilter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// FieldType is the type of a filtered field, which decides the literals and
+// operators it accepts and how its JSON value is compared
+type FieldType string
+
+// Field types
+const (
+	// TypeAny fields are compared according to the literal: as text,
+	// numbers or booleans
+	TypeAny     FieldType = "any"
+	TypeString  FieldType = "string"
+	TypeNumber  FieldType = "number"
+	TypeBoolean FieldType = "boolean"
+
+	// TypeDate fields hold ISO 8601 dates or timestamps, which order as text
+	TypeDate FieldType = "date"
+
+	// TypeStructured fields hold objects or arrays and can only be tested
+	// for null
+	TypeStructured FieldType = "structured"
+)
+
+// Fields resolves a field path to its type, reporting false for fields that
+// do not exist
+type Fields func(path []string) (FieldType, bool)
+
+// Check resolves the type of every field and verifies the literals and
+// operators used with it. Nil fields accept every path as TypeAny, which
+// suits schemaless documents such as metadata.
+func (f *Filter) Check(fields Fields) error {
+	return walk(f.root, func(c *Comparison) error {
+		c.Type = TypeAny
+		if fields != nil {
+			fieldType, ok := fields(c.Field)
+			if !ok {
+				return FilterErrors.New(ErrUnknownField).
+					WithDetail("field", strings.Join(c.Field, ".")).
+					WithDetail("position", c.Pos)
+			}
+			c.Type = fieldType
+		}
+		return checkComparison(c)
+	})
+}
+
+// checkComparison verifies a comparison against the type of its field
+func checkComparison(c *Comparison) error {
+	mismatch := func(reason string) error {
+		return FilterErrors.New(ErrTypeMismatch).
+			WithDetail("field", strings.Join(c.Field, ".")).
+			WithDetail("field_type", c.Type).
+			WithDetail("operator", c.Op).
+			WithDetail("position", c.Pos).
+			WithDetail("reason", reason)
+	}
+
+	if c.Op == OpIsNull || c.Op == OpIsNotNull {
+		return nil
+	}
+	kind := c.Values[0].Kind
+
+	switch c.Type {
+	case TypeStructured:
+		return mismatch("objects and arrays can only be tested with IS NULL or IS NOT NULL")
+	case TypeString:
+		if kind != KindString {
+			return mismatch("expected a string")
+		}
+	case TypeDate:
+		if kind != KindString {
+			return mismatch("expected a date string")
+		}
+		for _, value := range c.Values {
+			if !isDate(value.Text) {
+				return mismatch("expected a date as YYYY-MM-DD or an RFC 3339 timestamp")
+			}
+		}
+	case TypeNumber:
+		if kind != KindNumber {
+			return mismatch("expected a number")
+		}
+	case TypeBoolean:
+		if kind != KindBoolean {
+			return mismatch("expected true or false")
+		}
+	}
+
+	switch {
+	case c.Op == OpContains && c.Type != TypeString && c.Type != TypeAny:
+		return mismatch("CONTAINS only applies to text")
+	case c.Op.ordering() && kind == KindBoolean:
+		return mismatch("booleans cannot be ordered")
+	}
+	return nil
+}
+
+// SQL compiles the filter into a predicate over the JSONB column. Values and
+// field paths become parameters numbered from firstArg, which are returned
+// in order. Comparisons with a missing field are false, except for !=,
+// NOT IN and IS NULL, which hold for it.
+func (f *Filter) SQL(column string, firstArg int) (string, []any) {
+	c := &sqlCompiler{column: column, next: firstArg}
+	return c.compile(f.root), c.args
+}
+
+// sqlCompiler accumulates the parameters of a compiled filter
+type sqlCompiler struct {
+	column string
+	next   int
+	args   []any
+}
+
+// arg adds a parameter and returns its placeholder
+func (c *sqlCompiler) arg(value any) string {
+	c.args = append(c.args, value)
+	placeholder := fmt.Sprintf("$%d", c.next)
+	c.next++
+	return placeholder
+}
+
+func (c *sqlCompiler) compile(node Node) string {
+	switch n := node.(type) {
+	case *Logical:
+		return "(" + c.compile(n.Left) + " " + n.Op + " " + c.compile(n.Right) + ")"
+	case *Not:
+		// NOT of an unknown comparison is true, matching != on missing fields
+		return "((" + c.compile(n.Operand) + ") IS NOT TRUE)"
+	case *Comparison:
+		return c.comparison(n)
+	}
+	panic(fmt.Sprintf("jsonfilter: unexpected node %T", node))
+}
+
+func (c *sqlCompiler) comparison(n *Comparison) string {
+	path := c.arg(pq.StringArray(n.Field))
+	value := fmt.Sprintf("(%s #> %s::text[])", c.column, path)
+	text := fmt.Sprintf("(%s #>> %s::text[])", c.column, path)
+
+	switch n.Op {
+	case OpIsNull:
+		return fmt.Sprintf("(%s IS NULL OR jsonb_typeof(%s) = 'null')", value, value)
+	case OpIsNotNull:
+		return fmt.Sprintf("(%s IS NOT NULL AND jsonb_typeof(%s) <> 'null')", value, value)
+	case OpContains:
+		return fmt.Sprintf("(%s ILIKE %s)", text, c.arg("%"+escapeLike(n.Values[0].Text)+"%"))
+	}
+
+	// Compare as the literal's type; JSON values of another type compare
+	// as NULL. Numbers stored as numeric strings still compare as numbers.
+	var operand, cast string
+	switch n.Values[0].Kind {
+	case KindNumber:
+		operand = fmt.Sprintf(`(CASE WHEN jsonb_typeof(%s) = 'number' OR (jsonb_typeof(%s) = 'string' AND %s ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$') THEN %s::numeric END)`,
+			value, value, text, text)
+		cast = "numeric"
+	case KindBoolean:
+		operand = fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) = 'boolean' THEN %s::boolean END)", value, text)
+		cast = "boolean"
+	default:
+		operand = fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) = 'string' THEN %s END)", value, text)
+		if n.Type == TypeAny || n.Type == TypeString {
+			// Untyped documents may hold the text as a number or boolean
+			operand = fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) IN ('string', 'number', 'boolean') THEN %s END)", value, text)
+		}
+		cast = "text"
+	}
+
+	switch n.Op {
+	case OpIn, OpNotIn:
+		list := make([]string, len(n.Values))
+		for i, v := range n.Values {
+			list[i] = v.Text
+		}
+		match := fmt.Sprintf("(%s = ANY(%s::%s[]))", operand, c.arg(pq.StringArray(list)), cast)
+		if n.Op == OpNotIn {
+			return "(" + match + " IS NOT TRUE)"
+		}
+		return match
+	case OpNe:
+		return fmt.Sprintf("(%s IS DISTINCT FROM %s::%s)", operand, c.arg(literal(n.Values[0])), cast)
+	}
+
+	return fmt.Sprintf("(%s %s %s::%s)", operand, n.Op, c.arg(literal(n.Values[0])), cast)
+}
+
+// literal returns the parameter value of a literal
+func literal(v Value) any {
+	if v.Kind == KindBoolean {
+		return v.Bool
+	}
+	return v.Text
+}
+
+// escapeLike escapes the LIKE wildcards of s for the default \ escape
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// isDate reports whether s is a YYYY-MM-DD date or an RFC 3339 timestamp
+func isDate(s string) bool {
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
+
+// walk calls fn for every comparison of the expression
+func walk(node Node, fn func(*Comparison) error) error {
+	switch n := node.(type) {
+	case *Logical:
+		if err := walk(n.Left, fn); err != nil {
+			return err
+		}
+		return walk(n.Right, fn)
+	case *Not:
+		return walk(n.Operand, fn)
+	case *Comparison:
+		return fn(n)
+	}
+	return nil
+}
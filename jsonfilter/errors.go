@@ -0,0 +1,54 @@
+package jsonfilter
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// FilterErrors is the error registry for filter expressions
+var FilterErrors = errx.NewRegistry("FILTER")
+
+// Filter error codes
+var (
+	ErrInvalidFilter = FilterErrors.Register(
+		"INVALID_FILTER",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Filter expression is malformed",
+	)
+
+	ErrUnknownField = FilterErrors.Register(
+		"UNKNOWN_FIELD",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Filter refers to a field the schema does not define",
+	)
+
+	ErrTypeMismatch = FilterErrors.Register(
+		"TYPE_MISMATCH",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Filter compares a field with a value or operator of the wrong type",
+	)
+
+	ErrFilterTooComplex = FilterErrors.Register(
+		"FILTER_TOO_COMPLEX",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Filter expression exceeds the allowed size",
+	)
+)
+
+// Helper functions for error checking
+func IsInvalidFilter(err error) bool {
+	return errx.IsCode(err, ErrInvalidFilter)
+}
+
+func IsUnknownField(err error) bool {
+	return errx.IsCode(err, ErrUnknownField)
+}
+
+func IsTypeMismatch(err error) bool {
+	return errx.IsCode(err, ErrTypeMismatch)
+}
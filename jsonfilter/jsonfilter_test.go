@@ -0,0 +1,340 @@
+package jsonfilter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/lib/pq"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+)
+
+// render prints a parsed expression with explicit grouping
+func render(node Node) string {
+	switch n := node.(type) {
+	case *Logical:
+		return "(" + render(n.Left) + " " + n.Op + " " + render(n.Right) + ")"
+	case *Not:
+		return "NOT " + render(n.Operand)
+	case *Comparison:
+		values := make([]string, len(n.Values))
+		for i, v := range n.Values {
+			values[i] = string(v.Kind) + ":" + v.Text
+		}
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", strings.Join(n.Field, "."), n.Op, strings.Join(values, ",")))
+	}
+	return fmt.Sprintf("%T", node)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`amount > 1000`, `amount > number:1000`},
+		{`a = 1 OR b = 2 AND c = 3`, `(a = number:1 OR (b = number:2 AND c = number:3))`},
+		{`(a = 1 OR b = 2) AND c = 3`, `((a = number:1 OR b = number:2) AND c = number:3)`},
+		{`not a = true and b == false`, `(NOT a = boolean:TRUE AND b = boolean:FALSE)`},
+		{`a <> "x" OR a != 'y'`, `(a != string:x OR a != string:y)`},
+		{`tags.priority NOT IN ("low", "none")`, `tags.priority NOT IN string:low,string:none`},
+		{`total <= -1.5e3 AND rate >= .5`, `(total <= number:-1.5e3 AND rate >= number:.5)`},
+		{"`cost center`.code = \"OPS\"", `cost center.code = string:OPS`},
+		{`note CONTAINS "say \"hi\"\nbye"`, "note CONTAINS string:say \"hi\"\nbye"},
+		{`x IS NULL OR y is not null`, `(x IS NULL OR y IS NOT NULL)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			f, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := render(f.Root()); got != tt.want {
+				t.Errorf("parsed as %s; want %s", got, tt.want)
+			}
+			if f.String() != tt.input {
+				t.Errorf("String() = %q; want the source", f.String())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		code     errx.Code
+		position int
+	}{
+		{``, ErrInvalidFilter, 0},
+		{`   `, ErrInvalidFilter, 0},
+		{`amount`, ErrInvalidFilter, 6},
+		{`amount >`, ErrInvalidFilter, 8},
+		{`amount ! 1`, ErrInvalidFilter, 7},
+		{`= 1`, ErrInvalidFilter, 0},
+		{`a = "open`, ErrInvalidFilter, 4},
+		{`a = "\x"`, ErrInvalidFilter, 5},
+		{`a = 12abc`, ErrInvalidFilter, 4},
+		{`a = 1e999`, ErrInvalidFilter, 4},
+		{`a = NULL`, ErrInvalidFilter, 4},
+		{`a = 1 b = 2`, ErrInvalidFilter, 6},
+		{`(a = 1`, ErrInvalidFilter, 6},
+		{`a IN 1`, ErrInvalidFilter, 5},
+		{`a IN (1, "x")`, ErrInvalidFilter, 9},
+		{`a IN (1 2)`, ErrInvalidFilter, 8},
+		{`a NOT 1`, ErrInvalidFilter, 6},
+		{`a IS 1`, ErrInvalidFilter, 5},
+		{`a CONTAINS 1`, ErrInvalidFilter, 2},
+		{`a = 1 AND`, ErrInvalidFilter, 9},
+		{"`` = 1", ErrInvalidFilter, 0},
+		{`a = 1 ; DROP TABLE invoices`, ErrInvalidFilter, 6},
+		{`a.b.c.d.e.f.g.h.i = 1`, ErrFilterTooComplex, -1},
+		{strings.Repeat("(", MaxDepth+2) + "a = 1" + strings.Repeat(")", MaxDepth+2), ErrFilterTooComplex, -1},
+		{strings.Repeat("a = 1 OR ", MaxConditions) + "a = 1", ErrFilterTooComplex, -1},
+		{"a IN (" + strings.Repeat("1, ", MaxInValues) + "1)", ErrFilterTooComplex, -1},
+		{"a = \"" + strings.Repeat("x", MaxLength) + "\"", ErrFilterTooComplex, -1},
+	}
+	for _, tt := range tests {
+		name := tt.input
+		if len(name) > 40 {
+			name = name[:40]
+		}
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if !errx.IsCode(err, tt.code) {
+				t.Fatalf("err = %v; want %s", err, tt.code)
+			}
+			if tt.position < 0 {
+				return
+			}
+			if got := err.(*errx.Error).Details["position"]; got != tt.position {
+				t.Errorf("position = %v; want %d", got, tt.position)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	fields := map[string]FieldType{
+		"supplier": TypeString,
+		"amount":   TypeNumber,
+		"paid":     TypeBoolean,
+		"due":      TypeDate,
+		"lines":    TypeStructured,
+		"extra":    TypeAny,
+	}
+	lookup := func(path []string) (FieldType, bool) {
+		fieldType, ok := fields[strings.Join(path, ".")]
+		return fieldType, ok
+	}
+
+	tests := []struct {
+		input string
+		code  errx.Code // empty when the filter is valid
+	}{
+		{`supplier = "ACME" AND amount > 10 AND paid = false`, ""},
+		{`due >= "2024-01-31" AND due < "2024-02-01T00:00:00Z"`, ""},
+		{`due IN ("2024-01-01", "2024-02-01")`, ""},
+		{`lines IS NOT NULL AND supplier IS NULL`, ""},
+		{`supplier CONTAINS "ac" AND extra CONTAINS "x"`, ""},
+		{`extra = 1 OR extra = "1" OR extra = true`, ""},
+		{`missing = 1`, ErrUnknownField},
+		{`supplier = 1`, ErrTypeMismatch},
+		{`amount = "10"`, ErrTypeMismatch},
+		{`amount CONTAINS "1"`, ErrTypeMismatch},
+		{`paid = "yes"`, ErrTypeMismatch},
+		{`paid > false`, ErrTypeMismatch},
+		{`extra < true`, ErrTypeMismatch},
+		{`due = "31/01/2024"`, ErrTypeMismatch},
+		{`due IN ("2024-01-01", "soon")`, ErrTypeMismatch},
+		{`due = 20240131`, ErrTypeMismatch},
+		{`lines = "x"`, ErrTypeMismatch},
+		{`NOT (supplier = "a" OR amount = true)`, ErrTypeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			f, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = f.Check(lookup)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				return
+			}
+			if !errx.IsCode(err, tt.code) {
+				t.Fatalf("err = %v; want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestSchemaFields(t *testing.T) {
+	compiled, err := schema.CompileJSON([]byte(`{
+		"type": "object",
+		"properties": {
+			"supplier": {"type": "string"},
+			"issued": {"type": "string", "format": "date"},
+			"amount": {"type": ["number", "null"]},
+			"count": {"type": "integer"},
+			"paid": {"type": "boolean"},
+			"lines": {"type": "array"},
+			"code": {"type": ["string", "number"]},
+			"tags": {"type": "object", "properties": {"priority": {"type": "string"}}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := SchemaFields(compiled)
+
+	tests := []struct {
+		path string
+		want FieldType
+		ok   bool
+	}{
+		{"supplier", TypeString, true},
+		{"issued", TypeDate, true},
+		{"amount", TypeNumber, true},
+		{"count", TypeNumber, true},
+		{"paid", TypeBoolean, true},
+		{"lines", TypeStructured, true},
+		{"tags", TypeStructured, true},
+		{"code", TypeAny, true},
+		{"tags.priority", TypeString, true},
+		{"tags.missing", "", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := fields(strings.Split(tt.path, "."))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	// Fragments of the predicate over invoice_data for the path parameter $n
+	value := func(n int) string { return fmt.Sprintf("(invoice_data #> $%d::text[])", n) }
+	text := func(n int) string { return fmt.Sprintf("(invoice_data #>> $%d::text[])", n) }
+	number := func(n int) string {
+		return fmt.Sprintf(`(CASE WHEN jsonb_typeof(%s) = 'number' OR (jsonb_typeof(%s) = 'string' AND %s ~ '^\s*-?[0-9]+(\.[0-9]+)?\s*$') THEN %s::numeric END)`,
+			value(n), value(n), text(n), text(n))
+	}
+	anyText := func(n int) string {
+		return fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) IN ('string', 'number', 'boolean') THEN %s END)", value(n), text(n))
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		fields map[string]FieldType
+		want   string
+		args   []any
+	}{
+		{
+			name:  "number",
+			input: `amount > 1000.50`,
+			want:  "(" + number(3) + " > $4::numeric)",
+			args:  []any{pq.StringArray{"amount"}, "1000.50"},
+		},
+		{
+			name:  "boolean",
+			input: `paid = true`,
+			want:  fmt.Sprintf("((CASE WHEN jsonb_typeof(%s) = 'boolean' THEN %s::boolean END) = $4::boolean)", value(3), text(3)),
+			args:  []any{pq.StringArray{"paid"}, true},
+		},
+		{
+			name:  "untyped text",
+			input: `supplier = "ACME"`,
+			want:  "(" + anyText(3) + " = $4::text)",
+			args:  []any{pq.StringArray{"supplier"}, "ACME"},
+		},
+		{
+			name:   "date",
+			input:  `due < "2024-02-01"`,
+			fields: map[string]FieldType{"due": TypeDate},
+			want:   fmt.Sprintf("((CASE WHEN jsonb_typeof(%s) = 'string' THEN %s END) < $4::text)", value(3), text(3)),
+			args:   []any{pq.StringArray{"due"}, "2024-02-01"},
+		},
+		{
+			name:  "not equal",
+			input: `status != "void"`,
+			want:  "(" + anyText(3) + " IS DISTINCT FROM $4::text)",
+			args:  []any{pq.StringArray{"status"}, "void"},
+		},
+		{
+			name:  "in",
+			input: `status IN ("a", "b")`,
+			want:  "(" + anyText(3) + " = ANY($4::text[]))",
+			args:  []any{pq.StringArray{"status"}, pq.StringArray{"a", "b"}},
+		},
+		{
+			name:  "not in",
+			input: `tags.priority NOT IN (1, 2)`,
+			want:  "((" + number(3) + " = ANY($4::numeric[])) IS NOT TRUE)",
+			args:  []any{pq.StringArray{"tags", "priority"}, pq.StringArray{"1", "2"}},
+		},
+		{
+			name:  "contains escapes wildcards",
+			input: `note CONTAINS "50%_off\\"`,
+			want:  "(" + text(3) + " ILIKE $4)",
+			args:  []any{pq.StringArray{"note"}, `%50\%\_off\\%`},
+		},
+		{
+			name:  "is null",
+			input: `x IS NULL`,
+			want:  fmt.Sprintf("(%s IS NULL OR jsonb_typeof(%s) = 'null')", value(3), value(3)),
+			args:  []any{pq.StringArray{"x"}},
+		},
+		{
+			name:  "is not null",
+			input: `x IS NOT NULL`,
+			want:  fmt.Sprintf("(%s IS NOT NULL AND jsonb_typeof(%s) <> 'null')", value(3), value(3)),
+			args:  []any{pq.StringArray{"x"}},
+		},
+		{
+			name:  "logic",
+			input: `NOT a = 1 OR b = "x" AND c IS NULL`,
+			want: "(((" + "(" + number(3) + " = $4::numeric)" + ") IS NOT TRUE) OR (" +
+				"(" + anyText(5) + " = $6::text) AND " +
+				fmt.Sprintf("(%s IS NULL OR jsonb_typeof(%s) = 'null')", value(7), value(7)) + "))",
+			args: []any{pq.StringArray{"a"}, "1", pq.StringArray{"b"}, "x", pq.StringArray{"c"}},
+		},
+		{
+			name:  "injection stays in parameters",
+			input: "`a'); DROP TABLE invoices; --` = \"x' OR '1'='1\"",
+			want:  "(" + anyText(3) + " = $4::text)",
+			args:  []any{pq.StringArray{"a'); DROP TABLE invoices; --"}, "x' OR '1'='1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields Fields
+			if tt.fields != nil {
+				fields = func(path []string) (FieldType, bool) {
+					fieldType, ok := tt.fields[strings.Join(path, ".")]
+					return fieldType, ok
+				}
+			}
+			if err := f.Check(fields); err != nil {
+				t.Fatal(err)
+			}
+
+			sql, args := f.SQL("invoice_data", 3)
+			if sql != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v; want %#v", args, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package jsonfilter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind classifies the tokens of a filter expression
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenDot
+	tokenComma
+	tokenLParen
+	tokenRParen
+)
+
+// token is a lexed piece of an expression; pos is its byte offset
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keywords are matched case-insensitively and stored upper case
+var keywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "IS": true,
+	"NULL": true, "TRUE": true, "FALSE": true, "CONTAINS": true,
+}
+
+// lex splits an expression into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for pos := 0; pos < len(input); {
+		c := input[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: pos})
+			pos++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: pos})
+			pos++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			pos++
+		case c == '.' && !(pos+1 < len(input) && isDigit(input[pos+1])):
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: pos})
+			pos++
+
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op, width := lexOperator(input[pos:])
+			if width == 0 {
+				return nil, syntaxError(pos, "unknown operator")
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			pos += width
+
+		case c == '"' || c == '\'':
+			text, end, err := lexString(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: pos})
+			pos = end
+
+		case c == '`':
+			end := strings.IndexByte(input[pos+1:], '`')
+			if end <= 0 {
+				return nil, syntaxError(pos, "unterminated or empty quoted field name")
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[pos+1 : pos+1+end], pos: pos})
+			pos += end + 2
+
+		case isDigit(c) || c == '-' || c == '.':
+			end := lexNumber(input, pos)
+			if end == pos {
+				return nil, syntaxError(pos, "malformed number")
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[pos:end], pos: pos})
+			pos = end
+
+		case isIdentStart(c):
+			end := pos + 1
+			for end < len(input) && isIdentPart(input[end]) {
+				end++
+			}
+			word := input[pos:end]
+			if upper := strings.ToUpper(word); keywords[upper] {
+				tokens = append(tokens, token{kind: tokenKeyword, text: upper, pos: pos})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: word, pos: pos})
+			}
+			pos = end
+
+		default:
+			r, _ := utf8.DecodeRuneInString(input[pos:])
+			return nil, syntaxError(pos, "unexpected character "+string(r))
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// lexOperator reads a comparison operator and returns it with the number of
+// bytes it spans, or 0 if there is none; == and <> are accepted as spellings
+// of = and !=
+func lexOperator(input string) (string, int) {
+	for _, op := range []string{"==", "!=", "<>", "<=", ">="} {
+		if strings.HasPrefix(input, op) {
+			switch op {
+			case "==":
+				return "=", len(op)
+			case "<>":
+				return "!=", len(op)
+			}
+			return op, len(op)
+		}
+	}
+	switch input[0] {
+	case '=', '<', '>':
+		return input[:1], 1
+	}
+	return "", 0
+}
+
+// lexString reads a quoted string starting at pos. Backslash escapes the
+// quote, the backslash and n, t and r.
+func lexString(input string, pos int) (string, int, error) {
+	quote := input[pos]
+	var b strings.Builder
+
+	for i := pos + 1; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\':
+			if i+1 >= len(input) {
+				return "", 0, syntaxError(i, "unterminated escape")
+			}
+			i++
+			switch input[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '"', '\'':
+				b.WriteByte(input[i])
+			default:
+				return "", 0, syntaxError(i-1, "unknown escape \\"+string(input[i]))
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, syntaxError(pos, "unterminated string")
+}
+
+// lexNumber returns the end of the decimal number starting at pos, or pos
+// if there is none
+func lexNumber(input string, pos int) int {
+	i := pos
+	if i < len(input) && input[i] == '-' {
+		i++
+	}
+	digits := 0
+	for i < len(input) && isDigit(input[i]) {
+		i++
+		digits++
+	}
+	if i < len(input) && input[i] == '.' {
+		i++
+		for i < len(input) && isDigit(input[i]) {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return pos
+	}
+	if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+		j := i + 1
+		if j < len(input) && (input[j] == '+' || input[j] == '-') {
+			j++
+		}
+		if j < len(input) && isDigit(input[j]) {
+			for j < len(input) && isDigit(input[j]) {
+				j++
+			}
+			i = j
+		}
+	}
+	if i < len(input) && isIdentPart(input[i]) {
+		return pos
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,373 @@
+// Package jsonfilter implements a small filter language over JSONB columns,
+// for example
+//
+//	cost_center = "OPS" AND amount > 1000 AND NOT (tags.priority IN ("low", "none"))
+//
+// Expressions are parsed in Go, checked against the types of the fields they
+// name and compiled into parameterized SQL predicates, so user input never
+// reaches the query text. Fields are dotted paths into the JSON document.
+//
+// Comparisons: =, !=, <, <=, >, >=, IN (...), NOT IN (...), CONTAINS
+// (case-insensitive substring), IS NULL and IS NOT NULL, combined with AND,
+// OR, NOT and parentheses. Values are "strings", numbers, true and false.
+package jsonfilter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// Limits that keep expressions cheap to parse and to run
+const (
+	MaxLength      = 2000
+	MaxConditions  = 32
+	MaxDepth       = 16
+	MaxInValues    = 100
+	MaxPathSegment = 8
+)
+
+// Operator is a comparison operator
+type Operator string
+
+// Comparison operators
+const (
+	OpEq        Operator = "="
+	OpNe        Operator = "!="
+	OpLt        Operator = "<"
+	OpLe        Operator = "<="
+	OpGt        Operator = ">"
+	OpGe        Operator = ">="
+	OpIn        Operator = "IN"
+	OpNotIn     Operator = "NOT IN"
+	OpContains  Operator = "CONTAINS"
+	OpIsNull    Operator = "IS NULL"
+	OpIsNotNull Operator = "IS NOT NULL"
+)
+
+// ordering reports whether the operator orders values
+func (o Operator) ordering() bool {
+	return o == OpLt || o == OpLe || o == OpGt || o == OpGe
+}
+
+// ValueKind is the type of a literal
+type ValueKind string
+
+// Literal kinds
+const (
+	KindString  ValueKind = "string"
+	KindNumber  ValueKind = "number"
+	KindBoolean ValueKind = "boolean"
+)
+
+// Value is a literal of an expression. Numbers keep their text so they reach
+// the database without rounding.
+type Value struct {
+	Kind ValueKind
+	Text string
+	Bool bool
+}
+
+// Node is a node of a parsed expression: *Logical, *Not or *Comparison
+type Node interface {
+	node()
+}
+
+// Logical combines two expressions with AND or OR
+type Logical struct {
+	Op          string
+	Left, Right Node
+}
+
+// Not negates an expression
+type Not struct {
+	Operand Node
+}
+
+// Comparison tests one field. Type is set by Filter.Check.
+type Comparison struct {
+	Field  []string
+	Op     Operator
+	Values []Value
+	Pos    int
+	Type   FieldType
+}
+
+func (*Logical) node()    {}
+func (*Not) node()        {}
+func (*Comparison) node() {}
+
+// Filter is a parsed filter expression
+type Filter struct {
+	source string
+	root   Node
+}
+
+// String returns the expression the filter was parsed from
+func (f *Filter) String() string {
+	return f.source
+}
+
+// Root returns the root node of the expression
+func (f *Filter) Root() Node {
+	return f.root
+}
+
+// Parse parses a filter expression
+func Parse(input string) (*Filter, error) {
+	if len(input) > MaxLength {
+		return nil, FilterErrors.New(ErrFilterTooComplex).
+			WithDetail("max_length", MaxLength)
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokenEOF {
+		return nil, syntaxError(0, "empty expression")
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, syntaxError(tok.pos, fmt.Sprintf("unexpected %q", tok.text))
+	}
+
+	return &Filter{source: input, root: root}, nil
+}
+
+// parser is a recursive descent parser over the tokens of an expression:
+//
+//	or         = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" or ")" | comparison
+//	comparison = field ( op value | ["NOT"] "IN" "(" value { "," value } ")"
+//	             | "CONTAINS" string | "IS" ["NOT"] "NULL" )
+//	field      = name { "." name }
+type parser struct {
+	tokens      []token
+	pos         int
+	comparisons int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokenKeyword && tok.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr(depth int) (Node, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (Node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (Node, error) {
+	if depth > MaxDepth {
+		return nil, FilterErrors.New(ErrFilterTooComplex).
+			WithDetail("max_depth", MaxDepth)
+	}
+
+	if p.keyword("NOT") {
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Operand: operand}, nil
+	}
+
+	if tok := p.peek(); tok.kind == tokenLParen {
+		p.next()
+		inner, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokenRParen {
+			return nil, syntaxError(tok.pos, "expected )")
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Node, error) {
+	p.comparisons++
+	if p.comparisons > MaxConditions {
+		return nil, FilterErrors.New(ErrFilterTooComplex).
+			WithDetail("max_conditions", MaxConditions)
+	}
+
+	start := p.peek()
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	comparison := &Comparison{Field: field, Pos: start.pos, Type: TypeAny}
+
+	tok := p.next()
+	switch {
+	case tok.kind == tokenOperator:
+		comparison.Op = Operator(tok.text)
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		comparison.Values = []Value{value}
+
+	case tok.kind == tokenKeyword && tok.text == "CONTAINS":
+		comparison.Op = OpContains
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if value.Kind != KindString {
+			return nil, syntaxError(tok.pos, "CONTAINS takes a string")
+		}
+		comparison.Values = []Value{value}
+
+	case tok.kind == tokenKeyword && tok.text == "IS":
+		comparison.Op = OpIsNull
+		if p.keyword("NOT") {
+			comparison.Op = OpIsNotNull
+		}
+		if !p.keyword("NULL") {
+			return nil, syntaxError(p.peek().pos, "expected NULL")
+		}
+
+	case tok.kind == tokenKeyword && (tok.text == "IN" || tok.text == "NOT"):
+		comparison.Op = OpIn
+		if tok.text == "NOT" {
+			if !p.keyword("IN") {
+				return nil, syntaxError(p.peek().pos, "expected IN")
+			}
+			comparison.Op = OpNotIn
+		}
+		if comparison.Values, err = p.parseList(); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, syntaxError(tok.pos, "expected a comparison after the field name")
+	}
+
+	return comparison, nil
+}
+
+func (p *parser) parseField() ([]string, error) {
+	var field []string
+	for {
+		tok := p.next()
+		if tok.kind != tokenIdent {
+			return nil, syntaxError(tok.pos, "expected a field name")
+		}
+		field = append(field, tok.text)
+		if len(field) > MaxPathSegment {
+			return nil, FilterErrors.New(ErrFilterTooComplex).
+				WithDetail("max_path_segments", MaxPathSegment)
+		}
+		if p.peek().kind != tokenDot {
+			return field, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseList() ([]Value, error) {
+	if tok := p.next(); tok.kind != tokenLParen {
+		return nil, syntaxError(tok.pos, "expected ( after IN")
+	}
+
+	var values []Value
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 0 && value.Kind != values[0].Kind {
+			return nil, syntaxError(p.tokens[p.pos-1].pos, "IN values must all have the same type")
+		}
+		values = append(values, value)
+		if len(values) > MaxInValues {
+			return nil, FilterErrors.New(ErrFilterTooComplex).
+				WithDetail("max_in_values", MaxInValues)
+		}
+
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			return values, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, syntaxError(tok.pos, "expected , or )")
+		}
+	}
+}
+
+func (p *parser) parseValue() (Value, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokenString:
+		return Value{Kind: KindString, Text: tok.text}, nil
+	case tok.kind == tokenNumber:
+		if _, err := strconv.ParseFloat(tok.text, 64); err != nil {
+			return Value{}, syntaxError(tok.pos, "number out of range")
+		}
+		return Value{Kind: KindNumber, Text: tok.text}, nil
+	case tok.kind == tokenKeyword && (tok.text == "TRUE" || tok.text == "FALSE"):
+		return Value{Kind: KindBoolean, Text: tok.text, Bool: tok.text == "TRUE"}, nil
+	case tok.kind == tokenKeyword && tok.text == "NULL":
+		return Value{}, syntaxError(tok.pos, "use IS NULL or IS NOT NULL to test for null")
+	}
+	return Value{}, syntaxError(tok.pos, "expected a string, number, true or false")
+}
+
+// syntaxError reports a malformed expression at a byte offset
+func syntaxError(pos int, reason string) *errx.Error {
+	return FilterErrors.New(ErrInvalidFilter).
+		WithDetail("position", pos).
+		WithDetail("reason", reason)
+}
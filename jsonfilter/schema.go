@@ -0,0 +1,41 @@
+package jsonfilter
+
+import (
+	"slices"
+
+	"github.com/Abraxas-365/fuckturamelo/invoicetypes/schema"
+)
+
+// SchemaFields resolves fields through a JSON Schema: only properties the
+// schema defines may be filtered on, typed after their "type" keyword.
+// Fields allowing several types (other than null) are TypeAny.
+func SchemaFields(s *schema.Schema) Fields {
+	return func(path []string) (FieldType, bool) {
+		property, ok := s.Property(path...)
+		if !ok {
+			return "", false
+		}
+
+		types := slices.DeleteFunc(slices.Clone(property.Types()), func(t string) bool {
+			return t == "null"
+		})
+		if len(types) != 1 {
+			return TypeAny, true
+		}
+
+		switch types[0] {
+		case "string":
+			if format := property.Format(); format == "date" || format == "date-time" {
+				return TypeDate, true
+			}
+			return TypeString, true
+		case "number", "integer":
+			return TypeNumber, true
+		case "boolean":
+			return TypeBoolean, true
+		case "object", "array":
+			return TypeStructured, true
+		}
+		return TypeAny, true
+	}
+}
@@ -0,0 +1,100 @@
+-- Published schema versions per invoice type (kept forever for audit)
+CREATE TABLE invoice_type_schema_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_type_id UUID NOT NULL REFERENCES invoice_types(id) ON DELETE CASCADE,
+    schema_version TEXT NOT NULL,
+    sequence INTEGER NOT NULL,
+
+    -- Schema document and the transforms that upgrade data from the previous version
+    invoice_schema JSONB NOT NULL,
+    transforms JSONB NOT NULL DEFAULT '[]',
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT schema_versions_type_version_unique UNIQUE (invoice_type_id, schema_version),
+    CONSTRAINT schema_versions_type_sequence_unique UNIQUE (invoice_type_id, sequence),
+    CONSTRAINT schema_versions_transforms_array CHECK (jsonb_typeof(transforms) = 'array')
+);
+
+-- Background data migrations between schema versions
+CREATE TABLE invoice_schema_migrations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_type_id UUID NOT NULL REFERENCES invoice_types(id) ON DELETE CASCADE,
+    to_version TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+
+    -- Progress counters and per-row failure report
+    total_count INTEGER NOT NULL DEFAULT 0,
+    migrated_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    failures JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+
+    -- Audit fields
+    created_by UUID,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT schema_migrations_status_valid
+        CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- Schema version each invoice currently conforms to
+ALTER TABLE invoices ADD COLUMN schema_version TEXT NOT NULL DEFAULT '1.0';
+
+UPDATE invoices i
+SET schema_version = it.schema_version
+FROM invoice_types it
+WHERE i.invoice_type_id = it.id;
+
+-- Backfill the current schema of every existing invoice type as its first version
+INSERT INTO invoice_type_schema_versions
+    (invoice_type_id, schema_version, sequence, invoice_schema, created_by, created_at)
+SELECT id, schema_version, 1, invoice_schema, created_by, created_at
+FROM invoice_types;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_schema_versions_type_sequence
+    ON invoice_type_schema_versions(invoice_type_id, sequence);
+
+CREATE INDEX IF NOT EXISTS idx_schema_migrations_type_created
+    ON invoice_schema_migrations(invoice_type_id, created_at DESC);
+
+-- At most one unfinished migration per invoice type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schema_migrations_one_active
+    ON invoice_schema_migrations(invoice_type_id)
+    WHERE status IN ('pending', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_invoices_type_schema_version
+    ON invoices(invoice_type_id, schema_version);
+
+-- Record the initial schema of newly registered invoice types
+CREATE OR REPLACE FUNCTION record_initial_schema_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO invoice_type_schema_versions
+        (invoice_type_id, schema_version, sequence, invoice_schema, created_by)
+    VALUES
+        (NEW.id, NEW.schema_version, 1, NEW.invoice_schema, NEW.created_by);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_invoice_types_initial_version
+    AFTER INSERT ON invoice_types
+    FOR EACH ROW EXECUTE FUNCTION record_initial_schema_version();
+
+CREATE TRIGGER trigger_invoice_schema_migrations_updated_at
+    BEFORE UPDATE ON invoice_schema_migrations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Comments for documentation
+COMMENT ON TABLE invoice_type_schema_versions IS 'Immutable history of invoice type schemas with upgrade transforms';
+COMMENT ON COLUMN invoice_type_schema_versions.transforms IS 'Declarative transforms applied to data of the previous version';
+COMMENT ON TABLE invoice_schema_migrations IS 'Background jobs migrating invoice_data to a newer schema version';
+COMMENT ON COLUMN invoices.schema_version IS 'Invoice type schema version that invoice_data conforms to';
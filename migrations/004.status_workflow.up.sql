@@ -0,0 +1,40 @@
+-- Status lifecycle defined per invoice type
+ALTER TABLE invoice_types ADD COLUMN status_workflow JSONB NOT NULL DEFAULT '{
+    "initial_status": "draft",
+    "statuses": ["draft", "submitted", "approved", "paid", "void"],
+    "transitions": {
+        "draft": ["submitted", "void"],
+        "submitted": ["draft", "approved", "void"],
+        "approved": ["paid", "void"],
+        "paid": [],
+        "void": []
+    }
+}';
+
+ALTER TABLE invoice_types ADD CONSTRAINT invoice_types_status_workflow_valid CHECK (
+    jsonb_typeof(status_workflow) = 'object' AND
+    status_workflow ? 'initial_status' AND
+    jsonb_typeof(status_workflow->'statuses') = 'array' AND
+    jsonb_typeof(status_workflow->'transitions') = 'object'
+);
+
+-- Audit trail of status transitions
+CREATE TABLE invoice_status_transitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    comment TEXT,
+
+    -- Audit fields
+    transitioned_by UUID NOT NULL,
+    transitioned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_status_transitions_invoice
+    ON invoice_status_transitions(invoice_id, transitioned_at);
+
+-- Comments for documentation
+COMMENT ON COLUMN invoice_types.status_workflow IS 'Allowed invoice statuses, the initial status and the transitions between them';
+COMMENT ON TABLE invoice_status_transitions IS 'Who moved an invoice between statuses and when';
@@ -0,0 +1,54 @@
+-- Invoice lines with the amounts computed by the totals engine
+CREATE TABLE invoice_line_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+
+    -- Line input
+    item_code TEXT,
+    description TEXT NOT NULL,
+    quantity NUMERIC(18,6) NOT NULL,
+    unit_code TEXT,
+    unit_price NUMERIC(18,6) NOT NULL,
+    discount_percent NUMERIC(7,4) NOT NULL DEFAULT 0,
+    discount_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    tax_code TEXT,
+    tax_rate NUMERIC(7,4) NOT NULL DEFAULT 0,
+
+    -- Computed amounts
+    gross_amount NUMERIC(15,2) NOT NULL,
+    net_amount NUMERIC(15,2) NOT NULL,
+    tax_amount NUMERIC(15,2) NOT NULL,
+    line_total NUMERIC(15,2) NOT NULL,
+
+    -- Audit fields
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT invoice_line_items_position_unique UNIQUE (invoice_id, position),
+    CONSTRAINT invoice_line_items_quantity_positive CHECK (quantity > 0),
+    CONSTRAINT invoice_line_items_unit_price_valid CHECK (unit_price >= 0),
+    CONSTRAINT invoice_line_items_discount_valid CHECK (
+        discount_percent BETWEEN 0 AND 100 AND discount_amount >= 0
+    ),
+    CONSTRAINT invoice_line_items_tax_rate_valid CHECK (tax_rate BETWEEN 0 AND 100)
+);
+
+-- Totals derived from the line items (NULL for invoices without lines)
+ALTER TABLE invoices
+    ADD COLUMN subtotal_amount DECIMAL(15,2),
+    ADD COLUMN discount_amount DECIMAL(15,2),
+    ADD COLUMN tax_amount DECIMAL(15,2),
+    ADD COLUMN tax_breakdown JSONB;
+
+ALTER TABLE invoices ADD CONSTRAINT invoices_tax_breakdown_array CHECK (
+    tax_breakdown IS NULL OR jsonb_typeof(tax_breakdown) = 'array'
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_invoice_line_items_tax_code
+    ON invoice_line_items(tax_code) WHERE tax_code IS NOT NULL;
+
+-- Comments for documentation
+COMMENT ON TABLE invoice_line_items IS 'Invoice lines; amounts are computed server-side with decimal arithmetic';
+COMMENT ON COLUMN invoice_line_items.tax_rate IS 'Tax rate in percent (18 means 18%)';
+COMMENT ON COLUMN invoices.tax_breakdown IS 'Taxable and tax amounts per tax code and rate';
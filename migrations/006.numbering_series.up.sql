@@ -0,0 +1,52 @@
+-- Gapless numbering series per organization and document type
+CREATE TABLE invoice_number_series (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    document_type TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    padding INTEGER NOT NULL DEFAULT 8,
+
+    -- Sequence state; next_number only moves inside the transaction that
+    -- stores the numbered document
+    start_number BIGINT NOT NULL DEFAULT 1,
+    next_number BIGINT NOT NULL DEFAULT 1,
+
+    -- Lifecycle
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    retired_at TIMESTAMPTZ,
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT number_series_prefix_org_unique UNIQUE (organization_id, prefix),
+    CONSTRAINT number_series_prefix_valid CHECK (prefix ~ '^[A-Za-z0-9]{1,20}$'),
+    CONSTRAINT number_series_padding_valid CHECK (padding BETWEEN 1 AND 18),
+    CONSTRAINT number_series_numbers_valid CHECK (start_number >= 1 AND next_number >= start_number),
+    CONSTRAINT number_series_retired_consistency CHECK (is_active OR retired_at IS NOT NULL)
+);
+
+-- Series and sequence that numbered each invoice
+ALTER TABLE invoices
+    ADD COLUMN series_id UUID REFERENCES invoice_number_series(id) ON DELETE RESTRICT,
+    ADD COLUMN series_number BIGINT;
+
+ALTER TABLE invoices ADD CONSTRAINT invoices_series_number_unique UNIQUE (series_id, series_number);
+
+ALTER TABLE invoices ADD CONSTRAINT invoices_series_consistency CHECK (
+    (series_id IS NULL) = (series_number IS NULL)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_number_series_org_document_type
+    ON invoice_number_series(organization_id, document_type) WHERE is_active = true;
+
+CREATE TRIGGER trigger_invoice_number_series_updated_at
+    BEFORE UPDATE ON invoice_number_series
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Comments for documentation
+COMMENT ON TABLE invoice_number_series IS 'Gapless numbering series such as F001-00000123, assigned atomically on invoice creation';
+COMMENT ON COLUMN invoice_number_series.next_number IS 'Sequence the series assigns next';
+COMMENT ON COLUMN invoices.series_number IS 'Sequence assigned by the numbering series';
@@ -0,0 +1,21 @@
+-- Audit trail of hard purges of soft deleted invoices
+CREATE TABLE invoice_purges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    deleted_before TIMESTAMPTZ NOT NULL,
+    purged_count INTEGER NOT NULL,
+
+    -- Audit fields
+    purged_by UUID NOT NULL,
+    purged_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_invoices_org_deleted_at
+    ON invoices(organization_id, deleted_at) WHERE is_deleted = true;
+
+CREATE INDEX IF NOT EXISTS idx_invoice_purges_org_purged_at
+    ON invoice_purges(organization_id, purged_at DESC);
+
+-- Comments for documentation
+COMMENT ON TABLE invoice_purges IS 'Who permanently removed soft deleted invoices, and up to which deletion time';
@@ -0,0 +1,38 @@
+-- One row per live invoice with the dimensions analytics can slice by
+CREATE VIEW invoice_analytics_facts AS
+SELECT
+    id AS invoice_id,
+    organization_id,
+    project_id,
+    provider_id,
+    invoice_type_id,
+    status,
+    currency_code,
+    invoice_date,
+    to_char(invoice_date, 'YYYY-MM') AS month,
+    total_amount
+FROM invoices
+WHERE is_deleted = false;
+
+-- The per-status summary keeps its columns and now reads from the facts view
+CREATE OR REPLACE VIEW invoice_analytics AS
+SELECT
+    organization_id,
+    status,
+    currency_code,
+    COUNT(*) as invoice_count,
+    SUM(total_amount) as total_amount,
+    AVG(total_amount) as avg_amount,
+    MIN(invoice_date) as earliest_invoice,
+    MAX(invoice_date) as latest_invoice
+FROM invoice_analytics_facts
+WHERE status IS NOT NULL
+GROUP BY organization_id, status, currency_code;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_invoices_org_date_live
+    ON invoices(organization_id, invoice_date) WHERE is_deleted = false;
+
+-- Comments for documentation
+COMMENT ON VIEW invoice_analytics_facts IS 'Live invoices with their analytics dimensions; aggregated by the analytics API';
+COMMENT ON VIEW invoice_analytics IS 'Invoice counts and amounts per organization, status and currency';
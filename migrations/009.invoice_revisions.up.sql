@@ -0,0 +1,59 @@
+-- Who made the latest change to an invoice
+ALTER TABLE invoices ADD COLUMN updated_by UUID;
+
+-- Every version of an invoice's payload
+CREATE TABLE invoice_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+
+    -- Snapshot of the versioned state
+    invoice_data JSONB NOT NULL,
+    project_id UUID,
+    provider_id UUID,
+    schema_version TEXT NOT NULL,
+
+    -- Audit fields
+    changed_by UUID,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT invoice_revisions_version_unique UNIQUE (invoice_id, version)
+);
+
+-- Record a revision whenever an invoice is created or its version changes
+CREATE OR REPLACE FUNCTION record_invoice_revision()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.version IS NOT DISTINCT FROM NEW.version THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO invoice_revisions
+        (invoice_id, organization_id, version, invoice_data, project_id, provider_id,
+         schema_version, changed_by, changed_at)
+    VALUES
+        (NEW.id, NEW.organization_id, NEW.version, NEW.invoice_data, NEW.project_id, NEW.provider_id,
+         NEW.schema_version,
+         CASE WHEN TG_OP = 'INSERT' THEN NEW.created_by ELSE NEW.updated_by END,
+         NEW.updated_at);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_invoices_record_revision
+    AFTER INSERT OR UPDATE ON invoices
+    FOR EACH ROW EXECUTE FUNCTION record_invoice_revision();
+
+-- Existing invoices start their history at their current version
+INSERT INTO invoice_revisions
+    (invoice_id, organization_id, version, invoice_data, project_id, provider_id,
+     schema_version, changed_by, changed_at)
+SELECT id, organization_id, version, invoice_data, project_id, provider_id,
+       schema_version, created_by, updated_at
+FROM invoices;
+
+-- Comments for documentation
+COMMENT ON COLUMN invoices.updated_by IS 'User who made the change that produced the current version';
+COMMENT ON TABLE invoice_revisions IS 'Immutable history of invoice payloads, one row per version';
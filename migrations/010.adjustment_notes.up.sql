@@ -0,0 +1,49 @@
+-- Invoice types can describe credit and debit notes instead of invoices
+ALTER TABLE invoice_types ADD COLUMN document_kind TEXT NOT NULL DEFAULT 'invoice';
+
+ALTER TABLE invoice_types ADD CONSTRAINT invoice_types_document_kind_valid CHECK (
+    document_kind IN ('invoice', 'credit_note', 'debit_note')
+);
+
+-- Notes reference the invoice they adjust and say why
+ALTER TABLE invoices
+    ADD COLUMN document_kind TEXT NOT NULL DEFAULT 'invoice',
+    ADD COLUMN original_invoice_id UUID REFERENCES invoices(id),
+    ADD COLUMN adjustment_reason_code TEXT,
+    ADD COLUMN adjustment_reason TEXT;
+
+ALTER TABLE invoices ADD CONSTRAINT invoices_document_kind_valid CHECK (
+    document_kind IN ('invoice', 'credit_note', 'debit_note')
+);
+
+ALTER TABLE invoices ADD CONSTRAINT invoices_adjustment_reference CHECK (
+    (document_kind = 'invoice' AND original_invoice_id IS NULL AND adjustment_reason_code IS NULL) OR
+    (document_kind <> 'invoice' AND original_invoice_id IS NOT NULL AND adjustment_reason_code IS NOT NULL
+        AND original_invoice_id <> id)
+);
+
+-- Analytics can tell invoices and notes apart (new columns go last)
+CREATE OR REPLACE VIEW invoice_analytics_facts AS
+SELECT
+    id AS invoice_id,
+    organization_id,
+    project_id,
+    provider_id,
+    invoice_type_id,
+    status,
+    currency_code,
+    invoice_date,
+    to_char(invoice_date, 'YYYY-MM') AS month,
+    total_amount,
+    document_kind
+FROM invoices
+WHERE is_deleted = false;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_invoices_original_invoice
+    ON invoices(original_invoice_id) WHERE original_invoice_id IS NOT NULL;
+
+-- Comments for documentation
+COMMENT ON COLUMN invoice_types.document_kind IS 'invoice, credit_note or debit_note; fixed when the type is registered';
+COMMENT ON COLUMN invoices.original_invoice_id IS 'Invoice adjusted by this credit or debit note';
+COMMENT ON COLUMN invoices.adjustment_reason_code IS 'Why the note was issued, e.g. a SUNAT catalog 09/10 code';
@@ -0,0 +1,108 @@
+-- Payments made to providers
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    provider_id UUID REFERENCES providers(id) ON DELETE SET NULL,
+
+    method TEXT NOT NULL,
+    payment_date DATE NOT NULL,
+    amount NUMERIC(15,2) NOT NULL,
+    currency_code CHAR(3) NOT NULL,
+    reference TEXT,
+    notes TEXT,
+
+    -- Voided payments are kept for the audit trail but settle nothing
+    voided_at TIMESTAMPTZ,
+    voided_by UUID,
+    void_reason TEXT,
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT payments_method_valid CHECK (
+        method IN ('bank_transfer', 'card', 'cash', 'check', 'direct_debit', 'other')
+    ),
+    CONSTRAINT payments_amount_positive CHECK (amount > 0),
+    CONSTRAINT payments_currency_valid CHECK (length(currency_code) = 3)
+);
+
+-- Portions of a payment applied to invoices
+CREATE TABLE payment_allocations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    amount NUMERIC(15,2) NOT NULL,
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT payment_allocations_invoice_unique UNIQUE (payment_id, invoice_id),
+    CONSTRAINT payment_allocations_amount_positive CHECK (amount > 0)
+);
+
+-- Outstanding amount and payment state of every invoice: its total, minus
+-- live credit notes, plus live debit notes, minus allocations of payments
+-- that are not voided
+CREATE VIEW invoice_balances AS
+SELECT
+    i.id AS invoice_id,
+    i.organization_id,
+    i.currency_code,
+    COALESCE(i.total_amount, 0) AS total_amount,
+    adj.credited_amount,
+    adj.debited_amount,
+    adj.credit_note_count,
+    adj.debit_note_count,
+    pay.paid_amount,
+    pay.payment_count,
+    COALESCE(i.total_amount, 0) - adj.credited_amount + adj.debited_amount - pay.paid_amount AS outstanding_amount,
+    CASE
+        WHEN COALESCE(i.total_amount, 0) - adj.credited_amount + adj.debited_amount - pay.paid_amount <= 0
+             AND (pay.paid_amount > 0 OR adj.credited_amount > 0) THEN 'paid'
+        WHEN pay.paid_amount > 0 THEN 'partially_paid'
+        ELSE 'unpaid'
+    END AS payment_state
+FROM invoices i
+CROSS JOIN LATERAL (
+    SELECT
+        COALESCE(SUM(n.total_amount) FILTER (WHERE n.document_kind = 'credit_note'), 0) AS credited_amount,
+        COALESCE(SUM(n.total_amount) FILTER (WHERE n.document_kind = 'debit_note'), 0) AS debited_amount,
+        COUNT(*) FILTER (WHERE n.document_kind = 'credit_note') AS credit_note_count,
+        COUNT(*) FILTER (WHERE n.document_kind = 'debit_note') AS debit_note_count
+    FROM invoices n
+    WHERE n.original_invoice_id = i.id
+      AND n.is_deleted = false
+      AND n.status IS DISTINCT FROM 'void'
+) adj
+CROSS JOIN LATERAL (
+    SELECT
+        COALESCE(SUM(a.amount), 0) AS paid_amount,
+        COUNT(*) AS payment_count
+    FROM payment_allocations a
+    JOIN payments p ON p.id = a.payment_id
+    WHERE a.invoice_id = i.id
+      AND p.voided_at IS NULL
+) pay;
+
+-- Triggers
+CREATE TRIGGER trigger_payments_updated_at
+    BEFORE UPDATE ON payments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_payments_org_date
+    ON payments(organization_id, payment_date);
+
+CREATE INDEX IF NOT EXISTS idx_payments_provider
+    ON payments(provider_id) WHERE provider_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_invoice
+    ON payment_allocations(invoice_id);
+
+-- Comments for documentation
+COMMENT ON TABLE payments IS 'Money paid to providers; allocated to invoices through payment_allocations';
+COMMENT ON TABLE payment_allocations IS 'How much of a payment settles each invoice';
+COMMENT ON VIEW invoice_balances IS 'Outstanding amount and paid, partially_paid or unpaid state derived per invoice';
@@ -0,0 +1,112 @@
+-- Tax identification number of providers (RUC, RFC, VAT ID...), used to
+-- recognise them in bank remittance text
+ALTER TABLE providers ADD COLUMN tax_id TEXT;
+
+-- Imported bank statements; one file may hold several
+CREATE TABLE bank_statements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+
+    format TEXT NOT NULL,
+    account_identifier TEXT NOT NULL,
+    currency_code CHAR(3) NOT NULL,
+    statement_reference TEXT,
+    opening_balance NUMERIC(15,2),
+    opening_date DATE,
+    closing_balance NUMERIC(15,2),
+    closing_date DATE,
+    period_start DATE,
+    period_end DATE,
+
+    -- Source file; the same file cannot be imported twice
+    file_name TEXT,
+    file_sha256 CHAR(64) NOT NULL,
+    file_index INTEGER NOT NULL DEFAULT 0,
+
+    -- Audit fields
+    imported_by UUID,
+    imported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT bank_statements_format_valid CHECK (format IN ('camt053', 'mt940', 'ofx')),
+    CONSTRAINT bank_statements_file_unique UNIQUE (organization_id, file_sha256, file_index)
+);
+
+-- Booked movements of a statement
+CREATE TABLE bank_statement_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    statement_id UUID NOT NULL REFERENCES bank_statements(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+
+    booking_date DATE NOT NULL,
+    value_date DATE,
+    amount NUMERIC(15,2) NOT NULL,
+    direction TEXT NOT NULL,
+    currency_code CHAR(3) NOT NULL,
+    bank_reference TEXT,
+    end_to_end_id TEXT,
+    counterparty_name TEXT,
+    counterparty_account TEXT,
+    remittance_info TEXT,
+
+    -- Reconciliation state; payment_id is set once a suggestion is accepted
+    status TEXT NOT NULL DEFAULT 'unmatched',
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT bank_statement_lines_number_unique UNIQUE (statement_id, line_number),
+    CONSTRAINT bank_statement_lines_amount_valid CHECK (amount >= 0),
+    CONSTRAINT bank_statement_lines_direction_valid CHECK (direction IN ('credit', 'debit')),
+    CONSTRAINT bank_statement_lines_status_valid CHECK (status IN ('unmatched', 'suggested', 'matched'))
+);
+
+-- Proposed links between statement lines and open invoices
+CREATE TABLE bank_reconciliation_suggestions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    line_id UUID NOT NULL REFERENCES bank_statement_lines(id) ON DELETE CASCADE,
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    provider_id UUID REFERENCES providers(id) ON DELETE SET NULL,
+
+    score INTEGER NOT NULL,
+    amount NUMERIC(15,2) NOT NULL,
+    reasons TEXT[] NOT NULL DEFAULT '{}',
+
+    -- Review
+    status TEXT NOT NULL DEFAULT 'pending',
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT bank_reconciliation_suggestions_unique UNIQUE (line_id, invoice_id),
+    CONSTRAINT bank_reconciliation_suggestions_amount_positive CHECK (amount > 0),
+    CONSTRAINT bank_reconciliation_suggestions_status_valid CHECK (
+        status IN ('pending', 'accepted', 'rejected')
+    )
+);
+
+-- Triggers
+CREATE TRIGGER trigger_bank_statement_lines_updated_at
+    BEFORE UPDATE ON bank_statement_lines
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_providers_tax_id
+    ON providers(organization_id, tax_id) WHERE tax_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bank_statements_org_period
+    ON bank_statements(organization_id, period_end);
+
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_statement_status
+    ON bank_statement_lines(statement_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_bank_reconciliation_suggestions_invoice
+    ON bank_reconciliation_suggestions(invoice_id) WHERE status = 'pending';
+
+-- Comments for documentation
+COMMENT ON COLUMN providers.tax_id IS 'Tax identification number, matched against bank remittance text';
+COMMENT ON TABLE bank_statements IS 'Bank statements imported from CAMT.053, MT940 or OFX files';
+COMMENT ON TABLE bank_statement_lines IS 'Booked statement movements; amounts are positive and direction tells credit from debit';
+COMMENT ON TABLE bank_reconciliation_suggestions IS 'Invoices a statement line probably pays; accepting one records the payment';
@@ -0,0 +1,197 @@
+-- Accounts providers are paid into; identified by IBAN or, for domestic
+-- schemes, by a local account number
+CREATE TABLE provider_bank_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider_id UUID NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+
+    account_holder TEXT NOT NULL,
+    iban TEXT,
+    account_number TEXT,
+    bic TEXT,
+    bank_name TEXT,
+    currency_code CHAR(3),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Audit fields
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT provider_bank_accounts_identifier_required CHECK (
+        iban IS NOT NULL OR account_number IS NOT NULL
+    ),
+    CONSTRAINT provider_bank_accounts_iban_unique UNIQUE (provider_id, iban),
+    CONSTRAINT provider_bank_accounts_number_unique UNIQUE (provider_id, account_number)
+);
+
+-- Column layouts of the CSV and fixed-width files banks accept for bulk
+-- payments
+CREATE TABLE bank_file_layouts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    definition JSONB NOT NULL,
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT bank_file_layouts_name_unique UNIQUE (organization_id, name),
+    CONSTRAINT bank_file_layouts_kind_valid CHECK (kind IN ('csv', 'fixed_width')),
+    CONSTRAINT bank_file_layouts_definition_object CHECK (jsonb_typeof(definition) = 'object')
+);
+
+-- Outbound payment files: generated → sent → completed, or cancelled before
+-- completion
+CREATE TABLE payment_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+
+    format TEXT NOT NULL,
+    layout_id UUID REFERENCES bank_file_layouts(id),
+    status TEXT NOT NULL DEFAULT 'generated',
+    currency_code CHAR(3) NOT NULL,
+    execution_date DATE NOT NULL,
+
+    -- Account the batch is paid from
+    debtor_name TEXT NOT NULL,
+    debtor_iban TEXT,
+    debtor_account_number TEXT,
+    debtor_bic TEXT,
+
+    item_count INTEGER NOT NULL,
+    total_amount NUMERIC(15,2) NOT NULL,
+
+    -- Generated file, kept so it can be downloaded again
+    file_name TEXT NOT NULL,
+    file_content BYTEA NOT NULL,
+    file_sha256 CHAR(64) NOT NULL,
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status_changed_by UUID,
+    status_changed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT payment_batches_format_valid CHECK (format IN ('pain001', 'layout')),
+    CONSTRAINT payment_batches_layout_required CHECK (format <> 'layout' OR layout_id IS NOT NULL),
+    CONSTRAINT payment_batches_status_valid CHECK (
+        status IN ('generated', 'sent', 'completed', 'cancelled')
+    ),
+    CONSTRAINT payment_batches_debtor_account_required CHECK (
+        debtor_iban IS NOT NULL OR debtor_account_number IS NOT NULL
+    ),
+    CONSTRAINT payment_batches_total_positive CHECK (total_amount > 0 AND item_count > 0)
+);
+
+-- One credit transfer per invoice; creditor details are copied so the file
+-- can be explained after the provider's accounts change
+CREATE TABLE payment_batch_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    batch_id UUID NOT NULL REFERENCES payment_batches(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    provider_id UUID REFERENCES providers(id) ON DELETE SET NULL,
+    bank_account_id UUID REFERENCES provider_bank_accounts(id) ON DELETE SET NULL,
+
+    end_to_end_id TEXT NOT NULL,
+    amount NUMERIC(15,2) NOT NULL,
+    currency_code CHAR(3) NOT NULL,
+    creditor_name TEXT NOT NULL,
+    creditor_iban TEXT,
+    creditor_account_number TEXT,
+    creditor_bic TEXT,
+    remittance_info TEXT,
+
+    -- Payment recorded when the batch is completed
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+
+    CONSTRAINT payment_batch_items_sequence_unique UNIQUE (batch_id, sequence),
+    CONSTRAINT payment_batch_items_invoice_unique UNIQUE (batch_id, invoice_id),
+    CONSTRAINT payment_batch_items_amount_positive CHECK (amount > 0)
+);
+
+-- Invoice balances also report the amount scheduled in a live batch
+CREATE OR REPLACE VIEW invoice_balances AS
+SELECT
+    i.id AS invoice_id,
+    i.organization_id,
+    i.currency_code,
+    COALESCE(i.total_amount, 0) AS total_amount,
+    adj.credited_amount,
+    adj.debited_amount,
+    adj.credit_note_count,
+    adj.debit_note_count,
+    pay.paid_amount,
+    pay.payment_count,
+    COALESCE(i.total_amount, 0) - adj.credited_amount + adj.debited_amount - pay.paid_amount AS outstanding_amount,
+    CASE
+        WHEN COALESCE(i.total_amount, 0) - adj.credited_amount + adj.debited_amount - pay.paid_amount <= 0
+             AND (pay.paid_amount > 0 OR adj.credited_amount > 0) THEN 'paid'
+        WHEN pay.paid_amount > 0 THEN 'partially_paid'
+        ELSE 'unpaid'
+    END AS payment_state,
+    COALESCE(sched.scheduled_amount, 0) AS scheduled_amount,
+    sched.payment_batch_id
+FROM invoices i
+CROSS JOIN LATERAL (
+    SELECT
+        COALESCE(SUM(n.total_amount) FILTER (WHERE n.document_kind = 'credit_note'), 0) AS credited_amount,
+        COALESCE(SUM(n.total_amount) FILTER (WHERE n.document_kind = 'debit_note'), 0) AS debited_amount,
+        COUNT(*) FILTER (WHERE n.document_kind = 'credit_note') AS credit_note_count,
+        COUNT(*) FILTER (WHERE n.document_kind = 'debit_note') AS debit_note_count
+    FROM invoices n
+    WHERE n.original_invoice_id = i.id
+      AND n.is_deleted = false
+      AND n.status IS DISTINCT FROM 'void'
+) adj
+CROSS JOIN LATERAL (
+    SELECT
+        COALESCE(SUM(a.amount), 0) AS paid_amount,
+        COUNT(*) AS payment_count
+    FROM payment_allocations a
+    JOIN payments p ON p.id = a.payment_id
+    WHERE a.invoice_id = i.id
+      AND p.voided_at IS NULL
+) pay
+LEFT JOIN LATERAL (
+    SELECT bi.amount AS scheduled_amount, bi.batch_id AS payment_batch_id
+    FROM payment_batch_items bi
+    JOIN payment_batches b ON b.id = bi.batch_id
+    WHERE bi.invoice_id = i.id
+      AND b.status IN ('generated', 'sent')
+    ORDER BY b.created_at DESC
+    LIMIT 1
+) sched ON TRUE;
+
+-- Triggers
+CREATE TRIGGER trigger_provider_bank_accounts_updated_at
+    BEFORE UPDATE ON provider_bank_accounts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+CREATE TRIGGER trigger_bank_file_layouts_updated_at
+    BEFORE UPDATE ON bank_file_layouts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+CREATE TRIGGER trigger_payment_batches_updated_at
+    BEFORE UPDATE ON payment_batches
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_bank_accounts_default
+    ON provider_bank_accounts(provider_id) WHERE is_default;
+
+CREATE INDEX IF NOT EXISTS idx_payment_batches_org_status
+    ON payment_batches(organization_id, status, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_payment_batch_items_invoice
+    ON payment_batch_items(invoice_id);
+
+-- Comments for documentation
+COMMENT ON TABLE provider_bank_accounts IS 'Bank accounts providers are paid into; one default per provider';
+COMMENT ON TABLE bank_file_layouts IS 'Configurable CSV and fixed-width bank payment file layouts';
+COMMENT ON TABLE payment_batches IS 'Generated payment files (pain.001 or bank layout) and their status';
+COMMENT ON TABLE payment_batch_items IS 'Invoices scheduled for payment in a batch with the creditor details used';
+COMMENT ON COLUMN invoice_balances.scheduled_amount IS 'Amount scheduled in a generated or sent payment batch';
@@ -0,0 +1,133 @@
+-- Approval chains invoices of a project or invoice type must pass before
+-- they are approved. Steps are stored as JSONB: steps sharing a stage run in
+-- parallel and stages run in ascending order.
+CREATE TABLE approval_chains (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+
+    -- Scope; a chain without project and invoice type covers the whole organization
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    invoice_type_id UUID REFERENCES invoice_types(id) ON DELETE CASCADE,
+
+    steps JSONB NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT approval_chains_name_unique UNIQUE (organization_id, name),
+    CONSTRAINT approval_chains_steps_array CHECK (
+        jsonb_typeof(steps) = 'array' AND jsonb_array_length(steps) > 0
+    )
+);
+
+-- Only one active chain per scope
+CREATE UNIQUE INDEX IF NOT EXISTS approval_chains_scope_unique
+    ON approval_chains (
+        organization_id,
+        COALESCE(project_id, '00000000-0000-0000-0000-000000000000'),
+        COALESCE(invoice_type_id, '00000000-0000-0000-0000-000000000000')
+    )
+    WHERE is_active;
+
+-- An invoice going through a chain. The chain's steps are copied when the
+-- approval starts, so later edits of the chain do not affect it.
+CREATE TABLE invoice_approvals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    chain_id UUID REFERENCES approval_chains(id) ON DELETE SET NULL,
+    chain_name TEXT NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending',
+    current_stage INTEGER,
+
+    -- Invoice as it was submitted
+    invoice_version INTEGER NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    currency_code CHAR(3),
+
+    -- Audit fields
+    requested_by UUID NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    comment TEXT,
+    completed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT invoice_approvals_status_valid CHECK (
+        status IN ('pending', 'approved', 'rejected', 'changes_requested', 'cancelled')
+    ),
+    CONSTRAINT invoice_approvals_stage_valid CHECK (
+        (status = 'pending') = (current_stage IS NOT NULL)
+    )
+);
+
+-- Only one approval in progress per invoice
+CREATE UNIQUE INDEX IF NOT EXISTS invoice_approvals_pending_unique
+    ON invoice_approvals(invoice_id) WHERE status = 'pending';
+
+-- Steps of an approval; waiting steps belong to a later stage
+CREATE TABLE invoice_approval_steps (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    approval_id UUID NOT NULL REFERENCES invoice_approvals(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    stage INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    rule TEXT NOT NULL,
+    status TEXT NOT NULL,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT invoice_approval_steps_position_unique UNIQUE (approval_id, position),
+    CONSTRAINT invoice_approval_steps_rule_valid CHECK (rule IN ('any', 'all')),
+    CONSTRAINT invoice_approval_steps_status_valid CHECK (
+        status IN ('waiting', 'pending', 'approved', 'rejected', 'changes_requested', 'skipped')
+    )
+);
+
+-- Members a step was resolved to and their decisions
+CREATE TABLE invoice_approval_assignees (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    step_id UUID NOT NULL REFERENCES invoice_approval_steps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    decision TEXT,
+    comment TEXT,
+    decided_at TIMESTAMPTZ,
+
+    CONSTRAINT invoice_approval_assignees_unique UNIQUE (step_id, user_id),
+    CONSTRAINT invoice_approval_assignees_decision_valid CHECK (
+        decision IS NULL OR decision IN ('approve', 'reject', 'request_changes')
+    ),
+    CONSTRAINT invoice_approval_assignees_decided CHECK (
+        (decision IS NULL) = (decided_at IS NULL)
+    )
+);
+
+-- Triggers
+CREATE TRIGGER trigger_approval_chains_updated_at
+    BEFORE UPDATE ON approval_chains
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+CREATE TRIGGER trigger_invoice_approvals_updated_at
+    BEFORE UPDATE ON invoice_approvals
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_approval_chains_organization
+    ON approval_chains(organization_id) WHERE is_active;
+CREATE INDEX IF NOT EXISTS idx_invoice_approvals_invoice
+    ON invoice_approvals(invoice_id, requested_at);
+CREATE INDEX IF NOT EXISTS idx_invoice_approval_steps_pending
+    ON invoice_approval_steps(approval_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_invoice_approval_assignees_open
+    ON invoice_approval_assignees(user_id) WHERE decision IS NULL;
+
+-- Comments for documentation
+COMMENT ON TABLE approval_chains IS 'Approval steps invoices of a project or invoice type go through before they are approved';
+COMMENT ON COLUMN approval_chains.steps IS 'Steps with their stage, rule (any or all), optional min_amount and approvers (user, role or permission)';
+COMMENT ON TABLE invoice_approvals IS 'An invoice going through an approval chain; approval moves it to approved';
+COMMENT ON COLUMN invoice_approvals.current_stage IS 'Stage whose steps are waiting for decisions; NULL once the approval is decided';
+COMMENT ON TABLE invoice_approval_assignees IS 'Organization members resolved from the step approvers when the approval started';
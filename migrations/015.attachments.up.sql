@@ -0,0 +1,33 @@
+-- Original documents (PDF, XML, images) attached to invoices. File contents
+-- live in the blob store under storage_key; identical files of an
+-- organization share one stored object.
+CREATE TABLE invoice_attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    storage_key TEXT NOT NULL,
+
+    -- Audit fields
+    uploaded_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT invoice_attachments_file_unique UNIQUE (invoice_id, sha256),
+    CONSTRAINT invoice_attachments_size_positive CHECK (size_bytes > 0),
+    CONSTRAINT invoice_attachments_sha256_hex CHECK (sha256 ~ '^[0-9a-f]{64}$')
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_invoice_attachments_invoice
+    ON invoice_attachments(invoice_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_invoice_attachments_storage_key
+    ON invoice_attachments(storage_key);
+
+-- Comments for documentation
+COMMENT ON TABLE invoice_attachments IS 'Files attached to invoices; the content is kept in the blob store';
+COMMENT ON COLUMN invoice_attachments.sha256 IS 'Hex SHA-256 of the content, used to share stored objects between identical files';
+COMMENT ON COLUMN invoice_attachments.storage_key IS 'Key of the object in the blob store; removed with its last attachment, also when invoices are purged';
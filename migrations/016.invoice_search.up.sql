@@ -0,0 +1,149 @@
+-- Full-text search documents of invoices: the invoice number, the provider
+-- name, the line descriptions and the notes, indexed with the Spanish and
+-- English configurations. Kept in their own table so invoices keep their
+-- columns; the triggers below refresh a document whenever its sources change.
+CREATE TABLE invoice_search_documents (
+    invoice_id UUID PRIMARY KEY REFERENCES invoices(id) ON DELETE CASCADE,
+
+    -- Plain text the documents were built from, used for highlighting
+    content TEXT NOT NULL,
+
+    document_spanish TSVECTOR NOT NULL,
+    document_english TSVECTOR NOT NULL,
+
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Notes may be a string or a list of strings
+CREATE OR REPLACE FUNCTION invoice_data_text(value JSONB)
+RETURNS TEXT AS $$
+    SELECT CASE jsonb_typeof(value)
+        WHEN 'array' THEN (SELECT string_agg(item, ' ') FROM jsonb_array_elements_text(value) AS item)
+        WHEN 'string' THEN value #>> '{}'
+    END;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Rebuild the search document of an invoice. Invoice numbers use the simple
+-- configuration in both languages so they are matched as written.
+CREATE OR REPLACE FUNCTION refresh_invoice_search_document(p_invoice_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_number TEXT;
+    v_provider TEXT;
+    v_lines TEXT;
+    v_notes TEXT;
+BEGIN
+    SELECT coalesce(i.invoice_number, ''),
+           concat_ws(' ', pr.name, i.invoice_data->>'provider_name', i.invoice_data#>>'{provider,name}'),
+           concat_ws(' ',
+               (SELECT string_agg(li.description, ' ' ORDER BY li.position)
+                FROM invoice_line_items li WHERE li.invoice_id = i.id),
+               CASE WHEN jsonb_typeof(i.invoice_data->'line_items') = 'array' THEN
+                   (SELECT string_agg(line->>'description', ' ')
+                    FROM jsonb_array_elements(i.invoice_data->'line_items') AS line)
+               END),
+           coalesce(invoice_data_text(i.invoice_data->'notes'), '')
+    INTO v_number, v_provider, v_lines, v_notes
+    FROM invoices i
+    LEFT JOIN providers pr ON pr.id = i.provider_id
+    WHERE i.id = p_invoice_id;
+
+    IF NOT FOUND THEN
+        DELETE FROM invoice_search_documents WHERE invoice_id = p_invoice_id;
+        RETURN;
+    END IF;
+
+    INSERT INTO invoice_search_documents (invoice_id, content, document_spanish, document_english, refreshed_at)
+    VALUES (
+        p_invoice_id,
+        concat_ws(' · ', nullif(v_number, ''), nullif(v_provider, ''), nullif(v_lines, ''), nullif(v_notes, '')),
+        setweight(to_tsvector('simple', v_number), 'A') ||
+            setweight(to_tsvector('spanish', v_provider), 'B') ||
+            setweight(to_tsvector('spanish', v_lines), 'C') ||
+            setweight(to_tsvector('spanish', v_notes), 'D'),
+        setweight(to_tsvector('simple', v_number), 'A') ||
+            setweight(to_tsvector('english', v_provider), 'B') ||
+            setweight(to_tsvector('english', v_lines), 'C') ||
+            setweight(to_tsvector('english', v_notes), 'D'),
+        NOW()
+    )
+    ON CONFLICT (invoice_id) DO UPDATE SET
+        content = EXCLUDED.content,
+        document_spanish = EXCLUDED.document_spanish,
+        document_english = EXCLUDED.document_english,
+        refreshed_at = EXCLUDED.refreshed_at;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Refresh when the payload or the provider of an invoice changes
+CREATE OR REPLACE FUNCTION invoices_refresh_search_document()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_invoice_search_document(NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_invoices_search_document
+    AFTER INSERT OR UPDATE OF invoice_data, provider_id ON invoices
+    FOR EACH ROW EXECUTE FUNCTION invoices_refresh_search_document();
+
+-- Refresh once per statement for the invoices whose lines changed; lines are
+-- replaced as a whole on every update
+CREATE OR REPLACE FUNCTION line_items_refresh_search_documents()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_invoice_search_document(changed.invoice_id)
+        FROM (SELECT DISTINCT invoice_id FROM old_lines) AS changed;
+    ELSE
+        PERFORM refresh_invoice_search_document(changed.invoice_id)
+        FROM (SELECT DISTINCT invoice_id FROM new_lines) AS changed;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_line_items_search_insert
+    AFTER INSERT ON invoice_line_items
+    REFERENCING NEW TABLE AS new_lines
+    FOR EACH STATEMENT EXECUTE FUNCTION line_items_refresh_search_documents();
+
+CREATE TRIGGER trigger_line_items_search_update
+    AFTER UPDATE ON invoice_line_items
+    REFERENCING NEW TABLE AS new_lines
+    FOR EACH STATEMENT EXECUTE FUNCTION line_items_refresh_search_documents();
+
+CREATE TRIGGER trigger_line_items_search_delete
+    AFTER DELETE ON invoice_line_items
+    REFERENCING OLD TABLE AS old_lines
+    FOR EACH STATEMENT EXECUTE FUNCTION line_items_refresh_search_documents();
+
+-- Refresh the invoices of a provider when it is renamed
+CREATE OR REPLACE FUNCTION providers_refresh_search_documents()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_invoice_search_document(i.id)
+    FROM invoices i WHERE i.provider_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_providers_search_documents
+    AFTER UPDATE OF name ON providers
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION providers_refresh_search_documents();
+
+-- Existing invoices get their documents now
+SELECT refresh_invoice_search_document(id) FROM invoices;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_invoice_search_documents_spanish
+    ON invoice_search_documents USING GIN (document_spanish);
+CREATE INDEX IF NOT EXISTS idx_invoice_search_documents_english
+    ON invoice_search_documents USING GIN (document_english);
+
+-- Comments for documentation
+COMMENT ON TABLE invoice_search_documents IS 'Full-text search documents of invoices, maintained by triggers';
+COMMENT ON COLUMN invoice_search_documents.content IS 'Searched text (number, provider, lines, notes) used for highlighted snippets';
+COMMENT ON COLUMN invoice_search_documents.document_spanish IS 'Weighted document: A number, B provider, C line descriptions, D notes';
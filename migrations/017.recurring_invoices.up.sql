@@ -0,0 +1,85 @@
+-- Recurring invoice templates. The scheduler in the server process creates
+-- an invoice for every occurrence of the template's rule, catching up on
+-- occurrences missed while the server was down.
+CREATE TABLE recurring_invoice_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+
+    -- What the invoices are for
+    invoice_type_id UUID NOT NULL REFERENCES invoice_types(id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    provider_id UUID REFERENCES providers(id) ON DELETE CASCADE,
+    series_id UUID REFERENCES invoice_number_series(id) ON DELETE SET NULL,
+
+    -- Invoice contents copied into every invoice
+    invoice_data JSONB NOT NULL DEFAULT '{}',
+    line_items JSONB NOT NULL DEFAULT '[]',
+    due_days INTEGER,
+
+    -- Schedule: a cron expression or calendar rule with its end conditions
+    rule JSONB NOT NULL,
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ,
+    max_occurrences INTEGER,
+    status TEXT NOT NULL DEFAULT 'active',
+
+    -- Progress, maintained by the scheduler
+    occurrence_count INTEGER NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    locked_until TIMESTAMPTZ,
+
+    -- Audit fields
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT recurring_invoice_templates_name_unique UNIQUE (organization_id, name),
+    CONSTRAINT recurring_invoice_templates_status_valid CHECK (
+        status IN ('active', 'paused', 'completed')
+    ),
+    CONSTRAINT recurring_invoice_templates_next_run_valid CHECK (
+        (status = 'active') = (next_run_at IS NOT NULL)
+    ),
+    CONSTRAINT recurring_invoice_templates_line_items_array CHECK (jsonb_typeof(line_items) = 'array'),
+    CONSTRAINT recurring_invoice_templates_due_days_positive CHECK (due_days IS NULL OR due_days >= 0),
+    CONSTRAINT recurring_invoice_templates_max_occurrences_positive CHECK (max_occurrences IS NULL OR max_occurrences > 0),
+    CONSTRAINT recurring_invoice_templates_end_after_start CHECK (end_at IS NULL OR end_at >= start_at)
+);
+
+-- What each occurrence of a template produced. The unique key makes
+-- occurrences idempotent: an occurrence is recorded once, whatever happens.
+CREATE TABLE recurring_invoice_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id UUID NOT NULL REFERENCES recurring_invoice_templates(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL,
+    invoice_id UUID REFERENCES invoices(id) ON DELETE SET NULL,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT recurring_invoice_runs_occurrence_unique UNIQUE (template_id, scheduled_for),
+    CONSTRAINT recurring_invoice_runs_status_valid CHECK (status IN ('created', 'failed'))
+);
+
+-- Triggers
+CREATE TRIGGER trigger_recurring_invoice_templates_updated_at
+    BEFORE UPDATE ON recurring_invoice_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_recurring_invoice_templates_due
+    ON recurring_invoice_templates(next_run_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_recurring_invoice_templates_organization
+    ON recurring_invoice_templates(organization_id, name);
+CREATE INDEX IF NOT EXISTS idx_recurring_invoice_runs_invoice
+    ON recurring_invoice_runs(invoice_id) WHERE invoice_id IS NOT NULL;
+
+-- Comments for documentation
+COMMENT ON TABLE recurring_invoice_templates IS 'Invoices created on a schedule, such as rent, subscriptions and retainers';
+COMMENT ON COLUMN recurring_invoice_templates.rule IS 'Five-field cron expression or calendar rule (frequency, interval, weekdays, day_of_month, month, time) with a timezone';
+COMMENT ON COLUMN recurring_invoice_templates.next_run_at IS 'Next occurrence to materialize; NULL unless the template is active';
+COMMENT ON COLUMN recurring_invoice_templates.locked_until IS 'Lease taken by a scheduler while it materializes the template';
+COMMENT ON TABLE recurring_invoice_runs IS 'Outcome of each occurrence of a recurring invoice template';
@@ -0,0 +1,41 @@
+-- Tax identity of an organization as the issuer of electronic invoices.
+-- Invoices exported to the tax authority are issued by the organization to
+-- the invoice's provider.
+CREATE TABLE organization_tax_profiles (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+
+    -- Identity
+    tax_id TEXT NOT NULL,
+    tax_id_type TEXT NOT NULL DEFAULT '6',
+    legal_name TEXT NOT NULL,
+    trade_name TEXT,
+
+    -- Registered address of the issuing establishment
+    address_line TEXT,
+    ubigeo CHAR(6),
+    district TEXT,
+    province TEXT,
+    department TEXT,
+    country_code CHAR(2) NOT NULL DEFAULT 'PE',
+    establishment_code CHAR(4) NOT NULL DEFAULT '0000',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT organization_tax_profiles_ruc_valid CHECK (
+        tax_id_type <> '6' OR tax_id ~ '^[0-9]{11}$'
+    ),
+    CONSTRAINT organization_tax_profiles_ubigeo_valid CHECK (ubigeo ~ '^[0-9]{6}$'),
+    CONSTRAINT organization_tax_profiles_establishment_valid CHECK (establishment_code ~ '^[0-9]{4}$')
+);
+
+-- Triggers
+CREATE TRIGGER trigger_organization_tax_profiles_updated_at
+    BEFORE UPDATE ON organization_tax_profiles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Comments for documentation
+COMMENT ON TABLE organization_tax_profiles IS 'Tax identity organizations issue electronic invoices under';
+COMMENT ON COLUMN organization_tax_profiles.tax_id_type IS 'SUNAT catalog 06 identity document type; 6 is RUC';
+COMMENT ON COLUMN organization_tax_profiles.ubigeo IS 'INEI geographic code of the district of the address';
+COMMENT ON COLUMN organization_tax_profiles.establishment_code IS 'SUNAT annex code of the issuing establishment; 0000 is the tax domicile';
@@ -0,0 +1,39 @@
+-- Certificates organizations sign electronic invoices with. The PKCS#12
+-- bundle and its password are sealed with the application's master key;
+-- the remaining columns describe the certificate without opening it.
+CREATE TABLE organization_certificates (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+
+    -- AES-256-GCM sealed bundle and password, bound to the organization
+    sealed_pkcs12 BYTEA NOT NULL,
+
+    -- Certificate details
+    subject TEXT NOT NULL,
+    issuer TEXT NOT NULL,
+    serial_number TEXT NOT NULL,
+    fingerprint_sha256 CHAR(64) NOT NULL,
+    not_before TIMESTAMPTZ NOT NULL,
+    not_after TIMESTAMPTZ NOT NULL,
+
+    -- Audit fields
+    uploaded_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT organization_certificates_validity CHECK (not_after > not_before),
+    CONSTRAINT organization_certificates_fingerprint_hex CHECK (fingerprint_sha256 ~ '^[0-9a-f]{64}$')
+);
+
+-- Triggers
+CREATE TRIGGER trigger_organization_certificates_updated_at
+    BEFORE UPDATE ON organization_certificates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_organization_certificates_expiry
+    ON organization_certificates(not_after);
+
+-- Comments for documentation
+COMMENT ON TABLE organization_certificates IS 'PKCS#12 signing certificates of organizations, stored encrypted';
+COMMENT ON COLUMN organization_certificates.sealed_pkcs12 IS 'Bundle and password sealed with the master key; the organization ID is authenticated with them';
+COMMENT ON COLUMN organization_certificates.fingerprint_sha256 IS 'Hex SHA-256 of the DER certificate';
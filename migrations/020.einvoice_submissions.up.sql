@@ -0,0 +1,90 @@
+-- SOL credentials organizations submit electronic documents to SUNAT with.
+-- The password is sealed with the application's master key.
+CREATE TABLE organization_sunat_credentials (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+
+    -- RUC followed by the SOL user, e.g. 20123456789MODDATOS
+    username TEXT NOT NULL,
+    sealed_password BYTEA NOT NULL,
+
+    -- Audit fields
+    updated_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT organization_sunat_credentials_username_format CHECK (username ~ '^[0-9]{11}[A-Za-z0-9]+$')
+);
+
+-- Submissions of signed electronic documents to SUNAT. A pending submission
+-- is sent by the server until SUNAT answers or the attempts run out; the
+-- zipped document is kept so every attempt sends the same signature.
+CREATE TABLE einvoice_submissions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+
+    -- Document sent
+    file_name TEXT NOT NULL,
+    archive BYTEA NOT NULL,
+
+    -- Progress, maintained by the submitter
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+
+    -- SUNAT's answer
+    response_code TEXT,
+    response_description TEXT,
+    observations TEXT[] NOT NULL DEFAULT '{}',
+    cdr_attachment_id UUID REFERENCES invoice_attachments(id) ON DELETE SET NULL,
+
+    -- Audit fields
+    submitted_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT einvoice_submissions_status_valid CHECK (
+        status IN ('pending', 'accepted', 'observed', 'rejected', 'failed')
+    ),
+    CONSTRAINT einvoice_submissions_next_attempt_valid CHECK (
+        (status = 'pending') = (next_attempt_at IS NOT NULL)
+    ),
+    CONSTRAINT einvoice_submissions_attempts_positive CHECK (attempts >= 0)
+);
+
+-- Outcome of the latest submission of each invoice
+ALTER TABLE invoices ADD COLUMN tax_authority_status TEXT;
+ALTER TABLE invoices ADD CONSTRAINT invoices_tax_authority_status_valid CHECK (
+    tax_authority_status IN ('pending', 'accepted', 'observed', 'rejected', 'failed')
+);
+
+-- Triggers
+CREATE TRIGGER trigger_organization_sunat_credentials_updated_at
+    BEFORE UPDATE ON organization_sunat_credentials
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+CREATE TRIGGER trigger_einvoice_submissions_updated_at
+    BEFORE UPDATE ON einvoice_submissions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Indexes
+-- An invoice is in flight or accepted at most once; rejected and failed
+-- submissions may be followed by another
+CREATE UNIQUE INDEX IF NOT EXISTS idx_einvoice_submissions_open
+    ON einvoice_submissions(invoice_id) WHERE status IN ('pending', 'accepted', 'observed');
+CREATE INDEX IF NOT EXISTS idx_einvoice_submissions_due
+    ON einvoice_submissions(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_einvoice_submissions_invoice
+    ON einvoice_submissions(invoice_id, created_at);
+
+-- Comments for documentation
+COMMENT ON TABLE organization_sunat_credentials IS 'SOL credentials of organizations for the SUNAT web service, password stored encrypted';
+COMMENT ON COLUMN organization_sunat_credentials.sealed_password IS 'Password sealed with the master key; the organization ID is authenticated with it';
+COMMENT ON TABLE einvoice_submissions IS 'Electronic documents sent to SUNAT and the CDR (Constancia de Recepción) they got';
+COMMENT ON COLUMN einvoice_submissions.archive IS 'Zipped signed document, sent unchanged on every attempt';
+COMMENT ON COLUMN einvoice_submissions.locked_until IS 'Lease taken by a submitter while it sends the document';
+COMMENT ON COLUMN einvoice_submissions.observations IS 'Observations of an accepted document, each starting with its SUNAT code';
+COMMENT ON COLUMN invoices.tax_authority_status IS 'Outcome of the latest submission to the tax authority; NULL if never submitted';
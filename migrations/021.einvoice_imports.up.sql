@@ -0,0 +1,44 @@
+-- Electronic documents received from providers and imported as invoices.
+-- A document is identified by its issuer, type and series-correlative
+-- number, so the same XML is not imported twice into an organization.
+CREATE TABLE einvoice_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    provider_id UUID REFERENCES providers(id) ON DELETE SET NULL,
+    attachment_id UUID REFERENCES invoice_attachments(id) ON DELETE SET NULL,
+
+    -- Document imported
+    format TEXT NOT NULL DEFAULT 'ubl',
+    issuer_tax_id TEXT NOT NULL,
+    document_type_code TEXT NOT NULL,
+    document_id TEXT NOT NULL,
+    issue_date DATE NOT NULL,
+
+    -- Signature, verified on import
+    signer_subject TEXT NOT NULL,
+    signer_serial_number TEXT NOT NULL,
+    signature_algorithm TEXT NOT NULL,
+
+    -- Differences found on import that did not stop it
+    warnings TEXT[] NOT NULL DEFAULT '{}',
+
+    -- Audit fields
+    imported_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT einvoice_imports_format_valid CHECK (format IN ('ubl')),
+    CONSTRAINT einvoice_imports_document_unique UNIQUE (organization_id, issuer_tax_id, document_type_code, document_id)
+);
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_einvoice_imports_invoice
+    ON einvoice_imports(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_einvoice_imports_organization
+    ON einvoice_imports(organization_id, created_at DESC);
+
+-- Comments for documentation
+COMMENT ON TABLE einvoice_imports IS 'Electronic documents issued by providers and imported as invoices';
+COMMENT ON COLUMN einvoice_imports.attachment_id IS 'Original XML as received, kept as an invoice attachment';
+COMMENT ON COLUMN einvoice_imports.document_id IS 'Series-correlative number given by the issuer, e.g. F001-123';
+COMMENT ON COLUMN einvoice_imports.signer_subject IS 'Subject of the certificate the document was signed with; not checked against trusted roots';
@@ -0,0 +1,46 @@
+-- Branding organizations print their invoices with. Organizations without
+-- a row print with the default template.
+CREATE TABLE organization_pdf_templates (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+
+    -- Arrangement
+    layout TEXT NOT NULL DEFAULT 'classic',
+    page_size TEXT NOT NULL DEFAULT 'A4',
+    language TEXT NOT NULL DEFAULT 'es',
+
+    -- Branding
+    primary_color CHAR(7) NOT NULL DEFAULT '#1F3A5F',
+    accent_color CHAR(7) NOT NULL DEFAULT '#4A6FA5',
+    font_family TEXT NOT NULL DEFAULT 'helvetica',
+    show_item_codes BOOLEAN NOT NULL DEFAULT FALSE,
+    footer_text TEXT,
+    logo BYTEA,
+    logo_content_type TEXT,
+
+    -- Audit fields
+    updated_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT organization_pdf_templates_layout_valid CHECK (layout IN ('classic', 'banner')),
+    CONSTRAINT organization_pdf_templates_page_size_valid CHECK (page_size IN ('A4', 'Letter')),
+    CONSTRAINT organization_pdf_templates_language_valid CHECK (language IN ('es', 'en')),
+    CONSTRAINT organization_pdf_templates_font_valid CHECK (font_family IN ('helvetica', 'times', 'courier')),
+    CONSTRAINT organization_pdf_templates_colors_valid CHECK (
+        primary_color ~ '^#[0-9A-Fa-f]{6}$' AND accent_color ~ '^#[0-9A-Fa-f]{6}$'
+    ),
+    CONSTRAINT organization_pdf_templates_logo_valid CHECK (
+        (logo IS NULL) = (logo_content_type IS NULL)
+        AND (logo_content_type IS NULL OR logo_content_type IN ('image/png', 'image/jpeg'))
+    )
+);
+
+-- Triggers
+CREATE TRIGGER trigger_organization_pdf_templates_updated_at
+    BEFORE UPDATE ON organization_pdf_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Comments for documentation
+COMMENT ON TABLE organization_pdf_templates IS 'Layout and branding of the PDFs of an organization''s invoices';
+COMMENT ON COLUMN organization_pdf_templates.layout IS 'classic boxes the title at the top right; banner draws the header on a band of the primary color';
+COMMENT ON COLUMN organization_pdf_templates.logo IS 'PNG or JPEG printed at the top left of every document';
@@ -0,0 +1,11 @@
+-- Schema migrations hold a lease renewed by the instance running them, so any
+-- instance can tell a live job from one whose process went away
+ALTER TABLE invoice_schema_migrations ADD COLUMN heartbeat_at TIMESTAMPTZ;
+
+-- Invoices a migration left alone because their content is frozen
+ALTER TABLE invoice_schema_migrations ADD COLUMN skipped_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE invoice_schema_migrations ADD COLUMN skipped JSONB NOT NULL DEFAULT '[]';
+
+-- Comments for documentation
+COMMENT ON COLUMN invoice_schema_migrations.heartbeat_at IS 'Last lease renewal by the running instance; jobs with an expired lease were interrupted';
+COMMENT ON COLUMN invoice_schema_migrations.skipped IS 'Invoices not migrated because they were submitted to the tax authority or are under approval';
@@ -0,0 +1,8 @@
+-- Signers of imported documents are checked against the configured trusted
+-- certification authorities; documents whose signer does not chain to one
+-- are still imported, with a warning
+ALTER TABLE einvoice_imports ADD COLUMN signer_trusted BOOLEAN NOT NULL DEFAULT false;
+
+-- Comments for documentation
+COMMENT ON COLUMN einvoice_imports.signer_subject IS 'Subject of the certificate the document was signed with';
+COMMENT ON COLUMN einvoice_imports.signer_trusted IS 'Whether the signing certificate chained to a trusted certification authority on import';
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"github.com/Abraxas-365/fuckturamelo/numbering/models"
+	"github.com/google/uuid"
+)
+
+// CreateSeriesRequest represents the request payload for creating a numbering series
+type CreateSeriesRequest struct {
+	OrganizationID uuid.UUID  `json:"organization_id" validate:"required"`
+	DocumentType   string     `json:"document_type" validate:"required"`
+	Prefix         string     `json:"prefix" validate:"required"`
+	Padding        *int       `json:"padding,omitempty"`      // defaults to models.DefaultPadding
+	StartNumber    *int64     `json:"start_number,omitempty"` // defaults to 1
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
+}
+
+// SetStartNumberRequest represents the request payload for changing the
+// starting number of a series that has not assigned any number yet
+type SetStartNumberRequest struct {
+	StartNumber int64 `json:"start_number" validate:"required,min=1"`
+}
+
+// SeriesListRequest represents query parameters for listing numbering series
+type SeriesListRequest struct {
+	OrganizationID *uuid.UUID `query:"organization_id"`
+	DocumentType   *string    `query:"document_type"`
+	IsActive       *bool      `query:"is_active"`
+}
+
+// SeriesResponse represents the response for a single numbering series
+type SeriesResponse struct {
+	*models.Series `json:",inline"`
+	NextFormatted  string `json:"next_formatted"`
+	IssuedCount    int64  `json:"issued_count"`
+}
+
+// SeriesListResponse represents the response for listing numbering series
+type SeriesListResponse struct {
+	Series []*SeriesResponse `json:"series"`
+	Total  int               `json:"total"`
+}
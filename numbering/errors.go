@@ -0,0 +1,111 @@
+package numbering
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// NumberingErrors is the error registry for numbering series domain
+var NumberingErrors = errx.NewRegistry("NUMBERING")
+
+// Numbering series error codes
+var (
+	// Basic CRUD errors
+	ErrSeriesNotFound = NumberingErrors.Register(
+		"NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Numbering series not found",
+	)
+
+	ErrSeriesPrefixExists = NumberingErrors.Register(
+		"PREFIX_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Numbering series with this prefix already exists in the organization",
+	)
+
+	ErrSeriesCreateFailed = NumberingErrors.Register(
+		"CREATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to create numbering series",
+	)
+
+	ErrSeriesUpdateFailed = NumberingErrors.Register(
+		"UPDATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to update numbering series",
+	)
+
+	// Query errors
+	ErrSeriesListFailed = NumberingErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list numbering series",
+	)
+
+	// Validation errors
+	ErrSeriesValidationFailed = NumberingErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Numbering series validation failed",
+	)
+
+	// Assignment errors
+	ErrSeriesRetired = NumberingErrors.Register(
+		"SERIES_RETIRED",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Numbering series is retired and no longer assigns numbers",
+	)
+
+	ErrSeriesInUse = NumberingErrors.Register(
+		"SERIES_IN_USE",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Numbering series has already assigned numbers; its starting number cannot change",
+	)
+
+	ErrSeriesExhausted = NumberingErrors.Register(
+		"SERIES_EXHAUSTED",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Numbering series has no numbers left for its padding",
+	)
+
+	ErrSeriesTypeMismatch = NumberingErrors.Register(
+		"DOCUMENT_TYPE_MISMATCH",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Numbering series numbers another document type",
+	)
+
+	ErrNumberAssignFailed = NumberingErrors.Register(
+		"ASSIGN_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to assign the next number of the series",
+	)
+)
+
+// Helper functions for error checking
+func IsSeriesNotFound(err error) bool {
+	return errx.IsCode(err, ErrSeriesNotFound)
+}
+
+func IsSeriesRetired(err error) bool {
+	return errx.IsCode(err, ErrSeriesRetired)
+}
+
+func IsSeriesExhausted(err error) bool {
+	return errx.IsCode(err, ErrSeriesExhausted)
+}
+
+func IsSeriesTypeMismatch(err error) bool {
+	return errx.IsCode(err, ErrSeriesTypeMismatch)
+}
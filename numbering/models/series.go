@@ -0,0 +1,66 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPadding is the number of digits of the sequential part of a number
+// when a series does not define its own ("F001-00000123")
+const DefaultPadding = 8
+
+// Series is a gapless numbering series of an organization for one document
+// type. Numbers are formatted as prefix, a dash and the zero padded sequence.
+type Series struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organization_id"`
+	DocumentType   string     `db:"document_type" json:"document_type"`
+	Prefix         string     `db:"prefix" json:"prefix"`
+	Padding        int        `db:"padding" json:"padding"`
+	StartNumber    int64      `db:"start_number" json:"start_number"`
+	NextNumber     int64      `db:"next_number" json:"next_number"`
+	IsActive       bool       `db:"is_active" json:"is_active"`
+	RetiredAt      *time.Time `db:"retired_at" json:"retired_at"`
+	CreatedBy      *uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the Series model
+func (s Series) TableName() string {
+	return "invoice_number_series"
+}
+
+// Format renders a sequence number of the series
+func (s Series) Format(sequence int64) string {
+	return fmt.Sprintf("%s-%0*d", s.Prefix, s.Padding, sequence)
+}
+
+// MaxSequence returns the last sequence the padding of the series can hold
+func (s Series) MaxSequence() int64 {
+	limit := int64(1)
+	for range s.Padding {
+		limit *= 10
+	}
+	return limit - 1
+}
+
+// Exhausted reports whether the series has assigned every sequence its
+// padding can hold
+func (s Series) Exhausted() bool {
+	return s.NextNumber > s.MaxSequence()
+}
+
+// IssuedCount returns how many numbers the series has assigned
+func (s Series) IssuedCount() int64 {
+	return s.NextNumber - s.StartNumber
+}
+
+// Assignment is a number taken from a series
+type Assignment struct {
+	SeriesID uuid.UUID `json:"series_id"`
+	Sequence int64     `json:"sequence"`
+	Number   string    `json:"number"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		padding  int
+		sequence int64
+		want     string
+	}{
+		{"F001", DefaultPadding, 123, "F001-00000123"},
+		{"B002", 3, 7, "B002-007"},
+		{"FC01", 1, 9, "FC01-9"},
+		{"F001", 3, 999, "F001-999"},
+		// Numbers past the padding are not cut, they only stop being
+		// assigned
+		{"F001", 3, 1000, "F001-1000"},
+		{"F001", 18, 999999999999999999, "F001-999999999999999999"},
+	}
+
+	for _, tt := range tests {
+		series := Series{Prefix: tt.prefix, Padding: tt.padding}
+		if got := series.Format(tt.sequence); got != tt.want {
+			t.Errorf("Format(%d) with padding %d = %q; want %q", tt.sequence, tt.padding, got, tt.want)
+		}
+	}
+}
+
+func TestExhausted(t *testing.T) {
+	tests := []struct {
+		padding    int
+		nextNumber int64
+		want       bool
+	}{
+		{1, 1, false},
+		{1, 9, false},
+		{1, 10, true},
+		{3, 999, false},
+		{3, 1000, true},
+		{DefaultPadding, 99999999, false},
+		{DefaultPadding, 100000000, true},
+		{18, 999999999999999999, false},
+		{18, 1000000000000000000, true},
+	}
+
+	for _, tt := range tests {
+		series := Series{Padding: tt.padding, NextNumber: tt.nextNumber}
+		if got := series.Exhausted(); got != tt.want {
+			t.Errorf("padding %d at %d: exhausted = %v; want %v", tt.padding, tt.nextNumber, got, tt.want)
+		}
+	}
+}
+
+func TestIssuedCount(t *testing.T) {
+	series := Series{StartNumber: 100, NextNumber: 142}
+	if got := series.IssuedCount(); got != 42 {
+		t.Errorf("issued count = %d; want 42", got)
+	}
+}
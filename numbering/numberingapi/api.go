@@ -0,0 +1,223 @@
+package numberingapi
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/numbering"
+	"github.com/Abraxas-365/fuckturamelo/numbering/dto"
+	"github.com/Abraxas-365/fuckturamelo/numbering/numberingsrv"
+	postgres "github.com/Abraxas-365/fuckturamelo/numbering/repository"
+)
+
+// NumberingAPI contains the complete API setup for the numbering series domain
+type NumberingAPI struct {
+	service numberingsrv.SeriesService
+	repo    postgres.SeriesRepository
+}
+
+// Config contains configuration for the numbering API
+type Config struct {
+	DB *sqlx.DB
+}
+
+// New creates a new NumberingAPI instance
+func New(config Config) (*NumberingAPI, error) {
+	if config.DB == nil {
+		return nil, numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewSeriesRepository(config.DB)
+	svc := numberingsrv.NewSeriesService(repo)
+
+	return &NumberingAPI{
+		service: svc,
+		repo:    repo,
+	}, nil
+}
+
+// SetupRoutes registers all numbering series routes with the given Fiber router group
+func (api *NumberingAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Basic routes
+	router.Post("/", api.createSeries)
+	router.Get("/", api.listSeries)
+	router.Get("/:id", api.getSeries)
+
+	// Lifecycle routes
+	router.Put("/:id/start-number", api.setStartNumber)
+	router.Post("/:id/retire", api.retireSeries)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *NumberingAPI) GetService() numberingsrv.SeriesService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *NumberingAPI) GetRepository() postgres.SeriesRepository {
+	return api.repo
+}
+
+// createSeries handles POST /numbering-series
+func (api *NumberingAPI) createSeries(c *fiber.Ctx) error {
+	var req dto.CreateSeriesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.CreateSeries(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getSeries handles GET /numbering-series/:id
+func (api *NumberingAPI) getSeries(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetSeries(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listSeries handles GET /numbering-series
+func (api *NumberingAPI) listSeries(c *fiber.Ctx) error {
+	req, err := api.parseListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListSeries(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// setStartNumber handles PUT /numbering-series/:id/start-number
+func (api *NumberingAPI) setStartNumber(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.SetStartNumberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.SetStartNumber(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// retireSeries handles POST /numbering-series/:id/retire
+func (api *NumberingAPI) retireSeries(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.RetireSeries(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// healthCheck provides a health check endpoint
+func (api *NumberingAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "numbering",
+	})
+}
+
+// Helper methods
+
+func (api *NumberingAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *NumberingAPI) parseListRequest(c *fiber.Ctx) (*dto.SeriesListRequest, error) {
+	req := &dto.SeriesListRequest{}
+
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		orgID, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			return nil, numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+				WithDetail("error", "Invalid organization_id format").
+				WithCause(err)
+		}
+		req.OrganizationID = &orgID
+	}
+
+	if documentType := c.Query("document_type"); documentType != "" {
+		req.DocumentType = &documentType
+	}
+
+	if isActiveStr := c.Query("is_active"); isActiveStr != "" {
+		isActive, err := strconv.ParseBool(isActiveStr)
+		if err != nil {
+			return nil, numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+				WithDetail("error", "Invalid is_active format").
+				WithCause(err)
+		}
+		req.IsActive = &isActive
+	}
+
+	return req, nil
+}
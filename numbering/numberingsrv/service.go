@@ -0,0 +1,235 @@
+package numberingsrv
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	"github.com/Abraxas-365/fuckturamelo/numbering"
+	"github.com/Abraxas-365/fuckturamelo/numbering/dto"
+	"github.com/Abraxas-365/fuckturamelo/numbering/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/numbering/repository"
+	"github.com/google/uuid"
+)
+
+// maxPadding keeps every formatted sequence within a BIGINT
+const maxPadding = 18
+
+// prefixPattern restricts prefixes to the characters fiscal authorities
+// accept in series codes ("F001", "B002", "FC01")
+var prefixPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,20}$`)
+
+// SeriesService defines the interface for numbering series business logic
+type SeriesService interface {
+	// Basic operations
+	CreateSeries(ctx context.Context, req *dto.CreateSeriesRequest) (*dto.SeriesResponse, error)
+	GetSeries(ctx context.Context, id uuid.UUID) (*dto.SeriesResponse, error)
+	ListSeries(ctx context.Context, req *dto.SeriesListRequest) (*dto.SeriesListResponse, error)
+
+	// Lifecycle operations
+	SetStartNumber(ctx context.Context, id uuid.UUID, req *dto.SetStartNumberRequest) (*dto.SeriesResponse, error)
+	RetireSeries(ctx context.Context, id uuid.UUID) (*dto.SeriesResponse, error)
+
+	// PreviewNumber returns the number the series would assign next, checking
+	// that it belongs to the organization and can still assign numbers
+	PreviewNumber(ctx context.Context, id, orgID uuid.UUID) (string, error)
+}
+
+// seriesService implements SeriesService
+type seriesService struct {
+	repo postgres.SeriesRepository
+}
+
+// NewSeriesService creates a new numbering series service
+func NewSeriesService(repo postgres.SeriesRepository) SeriesService {
+	return &seriesService{
+		repo: repo,
+	}
+}
+
+// CreateSeries creates a new numbering series
+func (s *seriesService) CreateSeries(ctx context.Context, req *dto.CreateSeriesRequest) (*dto.SeriesResponse, error) {
+	if err := s.validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+
+	padding := models.DefaultPadding
+	if req.Padding != nil {
+		padding = *req.Padding
+	}
+	startNumber := int64(1)
+	if req.StartNumber != nil {
+		startNumber = *req.StartNumber
+	}
+
+	series := &models.Series{
+		OrganizationID: req.OrganizationID,
+		DocumentType:   req.DocumentType,
+		Prefix:         req.Prefix,
+		Padding:        padding,
+		StartNumber:    startNumber,
+		NextNumber:     startNumber,
+		IsActive:       true,
+		CreatedBy:      req.CreatedBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	created, err := s.repo.Create(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponse(created), nil
+}
+
+// GetSeries retrieves a numbering series by ID
+func (s *seriesService) GetSeries(ctx context.Context, id uuid.UUID) (*dto.SeriesResponse, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponse(series), nil
+}
+
+// ListSeries lists numbering series
+func (s *seriesService) ListSeries(ctx context.Context, req *dto.SeriesListRequest) (*dto.SeriesListResponse, error) {
+	series, err := s.repo.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.SeriesResponse, len(series))
+	for i, item := range series {
+		responses[i] = toResponse(item)
+	}
+
+	return &dto.SeriesListResponse{
+		Series: responses,
+		Total:  len(responses),
+	}, nil
+}
+
+// SetStartNumber changes where an unused series starts counting
+func (s *seriesService) SetStartNumber(ctx context.Context, id uuid.UUID, req *dto.SetStartNumberRequest) (*dto.SeriesResponse, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateStartNumber(req.StartNumber, series.Padding); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.SetStartNumber(ctx, id, req.StartNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponse(updated), nil
+}
+
+// RetireSeries stops a series from assigning numbers. Numbers already
+// assigned stay valid.
+func (s *seriesService) RetireSeries(ctx context.Context, id uuid.UUID) (*dto.SeriesResponse, error) {
+	retired, err := s.repo.Retire(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponse(retired), nil
+}
+
+// PreviewNumber returns the next number of the series without assigning it
+func (s *seriesService) PreviewNumber(ctx context.Context, id, orgID uuid.UUID) (string, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if series.OrganizationID != orgID {
+		return "", numbering.NumberingErrors.New(numbering.ErrSeriesNotFound).
+			WithDetail("series_id", id.String())
+	}
+	if !series.IsActive {
+		return "", numbering.NumberingErrors.New(numbering.ErrSeriesRetired).
+			WithDetail("series_id", id.String())
+	}
+	if series.Exhausted() {
+		return "", numbering.NumberingErrors.New(numbering.ErrSeriesExhausted).
+			WithDetail("series_id", id.String()).
+			WithDetail("padding", series.Padding)
+	}
+
+	return series.Format(series.NextNumber), nil
+}
+
+// Validation helpers
+
+func (s *seriesService) validateCreateRequest(req *dto.CreateSeriesRequest) error {
+	if req.OrganizationID == uuid.Nil {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("field", "organization_id").
+			WithDetail("reason", "required")
+	}
+
+	if req.DocumentType == "" {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("field", "document_type").
+			WithDetail("reason", "required")
+	}
+	// Series number the invoices of one document kind
+	if !typemodels.DocumentKind(req.DocumentType).Valid() {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("field", "document_type").
+			WithDetail("reason", "unsupported").
+			WithDetail("allowed", []typemodels.DocumentKind{typemodels.KindInvoice, typemodels.KindCreditNote, typemodels.KindDebitNote})
+	}
+
+	if !prefixPattern.MatchString(req.Prefix) {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("field", "prefix").
+			WithDetail("reason", "invalid_format").
+			WithDetail("expected", prefixPattern.String())
+	}
+
+	padding := models.DefaultPadding
+	if req.Padding != nil {
+		padding = *req.Padding
+		if padding < 1 || padding > maxPadding {
+			return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+				WithDetail("field", "padding").
+				WithDetail("reason", "out_of_range").
+				WithDetail("min", 1).
+				WithDetail("max", maxPadding)
+		}
+	}
+
+	if req.StartNumber != nil {
+		return validateStartNumber(*req.StartNumber, padding)
+	}
+
+	return nil
+}
+
+// validateStartNumber checks that the starting number fits the padding
+func validateStartNumber(startNumber int64, padding int) error {
+	maxSequence := models.Series{Padding: padding}.MaxSequence()
+	if startNumber < 1 || startNumber > maxSequence {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+			WithDetail("field", "start_number").
+			WithDetail("reason", "out_of_range").
+			WithDetail("min", 1).
+			WithDetail("max", maxSequence)
+	}
+
+	return nil
+}
+
+func toResponse(series *models.Series) *dto.SeriesResponse {
+	return &dto.SeriesResponse{
+		Series:        series,
+		NextFormatted: series.Format(series.NextNumber),
+		IssuedCount:   series.IssuedCount(),
+	}
+}
@@ -0,0 +1,146 @@
+package numberingsrv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/numbering"
+	"github.com/Abraxas-365/fuckturamelo/numbering/dto"
+	"github.com/Abraxas-365/fuckturamelo/numbering/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/numbering/repository"
+)
+
+// seriesRepo holds one series; the other methods are not used by the
+// tests
+type seriesRepo struct {
+	postgres.SeriesRepository
+
+	series  *models.Series
+	created *models.Series
+}
+
+func (r *seriesRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Series, error) {
+	if r.series == nil || r.series.ID != id {
+		return nil, numbering.NumberingErrors.New(numbering.ErrSeriesNotFound)
+	}
+	return r.series, nil
+}
+
+func (r *seriesRepo) Create(ctx context.Context, series *models.Series) (*models.Series, error) {
+	r.created = series
+	return series, nil
+}
+
+func TestPreviewNumber(t *testing.T) {
+	orgID := uuid.New()
+
+	tests := []struct {
+		name    string
+		series  models.Series
+		orgID   uuid.UUID
+		want    string
+		wantErr errx.Code
+	}{
+		{
+			name:   "next number",
+			series: models.Series{Prefix: "F001", Padding: models.DefaultPadding, NextNumber: 124, IsActive: true},
+			want:   "F001-00000124",
+		},
+		{
+			name:   "last number the padding holds",
+			series: models.Series{Prefix: "F001", Padding: 2, NextNumber: 99, IsActive: true},
+			want:   "F001-99",
+		},
+		{
+			name:    "padding exhausted",
+			series:  models.Series{Prefix: "F001", Padding: 2, NextNumber: 100, IsActive: true},
+			wantErr: numbering.ErrSeriesExhausted,
+		},
+		{
+			name:    "retired",
+			series:  models.Series{Prefix: "F001", Padding: 2, NextNumber: 5},
+			wantErr: numbering.ErrSeriesRetired,
+		},
+		{
+			name:    "series of another organization",
+			series:  models.Series{Prefix: "F001", Padding: 2, NextNumber: 5, IsActive: true},
+			orgID:   uuid.New(),
+			wantErr: numbering.ErrSeriesNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := tt.series
+			series.ID = uuid.New()
+			series.OrganizationID = orgID
+			service := NewSeriesService(&seriesRepo{series: &series})
+
+			requester := orgID
+			if tt.orgID != uuid.Nil {
+				requester = tt.orgID
+			}
+			got, err := service.PreviewNumber(context.Background(), series.ID, requester)
+			if tt.wantErr != "" {
+				if !errx.IsCode(err, tt.wantErr) {
+					t.Fatalf("err = %v; want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("number = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSeriesValidation(t *testing.T) {
+	padding := func(n int) *int { return &n }
+	start := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name      string
+		req       dto.CreateSeriesRequest
+		wantField string // empty when the series is created
+	}{
+		{"defaults", dto.CreateSeriesRequest{DocumentType: "invoice", Prefix: "F001"}, ""},
+		{"start at the last number of the padding", dto.CreateSeriesRequest{DocumentType: "invoice", Prefix: "F001", Padding: padding(3), StartNumber: start(999)}, ""},
+		{"start past the padding", dto.CreateSeriesRequest{DocumentType: "invoice", Prefix: "F001", Padding: padding(3), StartNumber: start(1000)}, "start_number"},
+		{"start at zero", dto.CreateSeriesRequest{DocumentType: "invoice", Prefix: "F001", StartNumber: start(0)}, "start_number"},
+		{"padding zero", dto.CreateSeriesRequest{DocumentType: "invoice", Prefix: "F001", Padding: padding(0)}, "padding"},
+		{"padding past a BIGINT", dto.CreateSeriesRequest{DocumentType: "invoice", Prefix: "F001", Padding: padding(maxPadding + 1)}, "padding"},
+		{"unknown document type", dto.CreateSeriesRequest{DocumentType: "receipt", Prefix: "F001"}, "document_type"},
+		{"prefix with a dash", dto.CreateSeriesRequest{DocumentType: "invoice", Prefix: "F-01"}, "prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &seriesRepo{}
+			req := tt.req
+			req.OrganizationID = uuid.New()
+
+			_, err := NewSeriesService(repo).CreateSeries(context.Background(), &req)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if repo.created.NextNumber != repo.created.StartNumber {
+					t.Errorf("next number = %d; want the start number %d", repo.created.NextNumber, repo.created.StartNumber)
+				}
+				return
+			}
+			if !errx.IsCode(err, numbering.ErrSeriesValidationFailed) {
+				t.Fatalf("err = %v; want %s", err, numbering.ErrSeriesValidationFailed)
+			}
+			if field := err.(*errx.Error).Details["field"]; field != tt.wantField {
+				t.Errorf("field = %v; want %s", field, tt.wantField)
+			}
+		})
+	}
+}
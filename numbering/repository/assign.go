@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/numbering"
+	"github.com/Abraxas-365/fuckturamelo/numbering/models"
+)
+
+// AssignNext takes the next number of a series inside tx. The series row stays
+// locked until tx ends, so concurrent documents are numbered one after the
+// other, and a rolled back transaction gives its number back: the sequence
+// has neither gaps nor duplicates. The series must number documentType, so
+// every document type keeps its own gapless sequence.
+func AssignNext(ctx context.Context, tx *sqlx.Tx, seriesID, organizationID uuid.UUID, documentType string) (*models.Assignment, error) {
+	query := `
+		UPDATE invoice_number_series
+		SET next_number = next_number + 1
+		WHERE id = $1 AND organization_id = $2 AND document_type = $3 AND is_active = true
+			AND next_number < power(10::numeric, padding)
+		RETURNING *`
+
+	var series models.Series
+	err := tx.GetContext(ctx, &series, query, seriesID, organizationID, documentType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, explainAssignRejection(ctx, tx, seriesID, organizationID, documentType)
+		}
+		return nil, numbering.NumberingErrors.New(numbering.ErrNumberAssignFailed).
+			WithDetail("series_id", seriesID.String()).
+			WithCause(err)
+	}
+
+	sequence := series.NextNumber - 1
+	return &models.Assignment{
+		SeriesID: series.ID,
+		Sequence: sequence,
+		Number:   series.Format(sequence),
+	}, nil
+}
+
+// explainAssignRejection reports why a series could not assign a number
+func explainAssignRejection(ctx context.Context, tx *sqlx.Tx, seriesID, organizationID uuid.UUID, documentType string) error {
+	var series models.Series
+	err := tx.GetContext(ctx, &series, `SELECT * FROM invoice_number_series WHERE id = $1`, seriesID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return numbering.NumberingErrors.New(numbering.ErrSeriesNotFound).
+				WithDetail("series_id", seriesID.String())
+		}
+		return numbering.NumberingErrors.New(numbering.ErrNumberAssignFailed).
+			WithDetail("series_id", seriesID.String()).
+			WithCause(err)
+	}
+
+	switch {
+	case series.OrganizationID != organizationID:
+		// Series of other organizations are reported as missing
+		return numbering.NumberingErrors.New(numbering.ErrSeriesNotFound).
+			WithDetail("series_id", seriesID.String())
+	case series.DocumentType != documentType:
+		return numbering.NumberingErrors.New(numbering.ErrSeriesTypeMismatch).
+			WithDetail("series_id", seriesID.String()).
+			WithDetail("series_document_type", series.DocumentType).
+			WithDetail("document_type", documentType)
+	case !series.IsActive:
+		return numbering.NumberingErrors.New(numbering.ErrSeriesRetired).
+			WithDetail("series_id", seriesID.String())
+	case series.Exhausted():
+		return numbering.NumberingErrors.New(numbering.ErrSeriesExhausted).
+			WithDetail("series_id", seriesID.String()).
+			WithDetail("padding", series.Padding)
+	default:
+		// The series changed between the update and this read
+		return numbering.NumberingErrors.New(numbering.ErrNumberAssignFailed).
+			WithDetail("series_id", seriesID.String())
+	}
+}
@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/Abraxas-365/fuckturamelo/numbering"
+	"github.com/Abraxas-365/fuckturamelo/numbering/models"
+)
+
+// seriesDB is a database holding an empty invoice_number_series in a
+// schema of the test's own
+type seriesDB struct {
+	*sqlx.DB
+	schema string
+}
+
+// newSeriesDB connects to TEST_DATABASE_URL, a database with the
+// migrations applied, and copies invoice_number_series, without its
+// foreign keys, into a schema dropped when the test ends. Tests needing a
+// database are skipped without the variable.
+func newSeriesDB(t *testing.T) *seriesDB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sqlx.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema := "numbering_test_" + uuid.New().String()[:8]
+	statements := []string{
+		"CREATE SCHEMA " + pq.QuoteIdentifier(schema),
+		fmt.Sprintf("CREATE TABLE %s.invoice_number_series (LIKE public.invoice_number_series INCLUDING ALL)", pq.QuoteIdentifier(schema)),
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec("DROP SCHEMA " + pq.QuoteIdentifier(schema) + " CASCADE"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return &seriesDB{DB: db, schema: schema}
+}
+
+// begin starts a transaction on the test's schema
+func (db *seriesDB) begin() (*sqlx.Tx, error) {
+	tx, err := db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("SET LOCAL search_path TO " + pq.QuoteIdentifier(db.schema)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// mustBegin is begin for the test's goroutine
+func (db *seriesDB) mustBegin(t *testing.T) *sqlx.Tx {
+	t.Helper()
+	tx, err := db.begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// insert stores an active invoice series with the given padding, next at
+// nextNumber
+func (db *seriesDB) insert(t *testing.T, padding int, nextNumber int64) *models.Series {
+	t.Helper()
+
+	series := &models.Series{
+		ID:             uuid.New(),
+		OrganizationID: uuid.New(),
+		DocumentType:   "invoice",
+		Prefix:         "F001",
+		Padding:        padding,
+		StartNumber:    1,
+		NextNumber:     nextNumber,
+		IsActive:       true,
+	}
+	tx := db.mustBegin(t)
+	_, err := tx.Exec(`
+		INSERT INTO invoice_number_series
+			(id, organization_id, document_type, prefix, padding, start_number, next_number, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		series.ID, series.OrganizationID, series.DocumentType, series.Prefix, series.Padding,
+		series.StartNumber, series.NextNumber, series.IsActive)
+	if err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return series
+}
+
+func TestAssignNextConcurrent(t *testing.T) {
+	db := newSeriesDB(t)
+	series := db.insert(t, 3, 1)
+
+	const documents = 20
+	sequences := make([]int64, documents)
+	var wg sync.WaitGroup
+	for i := range documents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := db.begin()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			assignment, err := AssignNext(context.Background(), tx, series.ID, series.OrganizationID, series.DocumentType)
+			if err != nil {
+				tx.Rollback()
+				t.Error(err)
+				return
+			}
+			// Every third document fails after taking its number
+			if i%3 == 0 {
+				tx.Rollback()
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				t.Error(err)
+				return
+			}
+			sequences[i] = assignment.Sequence
+		}()
+	}
+	wg.Wait()
+
+	committed := slices.DeleteFunc(sequences, func(sequence int64) bool { return sequence == 0 })
+	slices.Sort(committed)
+	for i, sequence := range committed {
+		if sequence != int64(i+1) {
+			t.Fatalf("committed sequences = %v; want 1 to %d without gaps or duplicates", committed, len(committed))
+		}
+	}
+
+	var next int64
+	if err := db.Get(&next, fmt.Sprintf("SELECT next_number FROM %s.invoice_number_series WHERE id = $1", pq.QuoteIdentifier(db.schema)), series.ID); err != nil {
+		t.Fatal(err)
+	}
+	if next != int64(len(committed)+1) {
+		t.Errorf("next number = %d; want %d", next, len(committed)+1)
+	}
+}
+
+func TestAssignNextRollback(t *testing.T) {
+	db := newSeriesDB(t)
+	series := db.insert(t, models.DefaultPadding, 1)
+	ctx := context.Background()
+
+	first := db.mustBegin(t)
+	taken, err := AssignNext(ctx, first, series.ID, series.OrganizationID, series.DocumentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The series stays locked until the first document is stored or not
+	assigned := make(chan *models.Assignment, 1)
+	go func() {
+		second, err := db.begin()
+		if err != nil {
+			t.Error(err)
+			close(assigned)
+			return
+		}
+		assignment, err := AssignNext(ctx, second, series.ID, series.OrganizationID, series.DocumentType)
+		if err != nil {
+			second.Rollback()
+			t.Error(err)
+			close(assigned)
+			return
+		}
+		if err := second.Commit(); err != nil {
+			t.Error(err)
+		}
+		assigned <- assignment
+	}()
+
+	select {
+	case assignment := <-assigned:
+		if assignment != nil {
+			t.Fatal("second document numbered while the first one's transaction is open")
+		}
+	case <-time.After(200 * time.Millisecond):
+	}
+	if err := first.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	second := <-assigned
+	if second == nil {
+		t.FailNow()
+	}
+	if second.Sequence != taken.Sequence || second.Number != "F001-00000001" {
+		t.Errorf("after the rollback got %s; want the number given back, %s", second.Number, taken.Number)
+	}
+}
+
+func TestAssignNextRejections(t *testing.T) {
+	db := newSeriesDB(t)
+	ctx := context.Background()
+
+	last := db.insert(t, 1, 9)
+	tx := db.mustBegin(t)
+	assignment, err := AssignNext(ctx, tx, last.ID, last.OrganizationID, last.DocumentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if assignment.Number != "F001-9" {
+		t.Errorf("last number = %s; want F001-9", assignment.Number)
+	}
+
+	retired := db.insert(t, 3, 1)
+	if _, err := db.Exec(fmt.Sprintf("UPDATE %s.invoice_number_series SET is_active = false, retired_at = NOW() WHERE id = $1", pq.QuoteIdentifier(db.schema)), retired.ID); err != nil {
+		t.Fatal(err)
+	}
+	open := db.insert(t, 3, 1)
+
+	tests := []struct {
+		name         string
+		series       *models.Series
+		orgID        uuid.UUID
+		documentType string
+		want         errx.Code
+	}{
+		{"padding exhausted", last, last.OrganizationID, "invoice", numbering.ErrSeriesExhausted},
+		{"retired", retired, retired.OrganizationID, "invoice", numbering.ErrSeriesRetired},
+		{"another document type", open, open.OrganizationID, "credit_note", numbering.ErrSeriesTypeMismatch},
+		{"another organization", open, uuid.New(), "invoice", numbering.ErrSeriesNotFound},
+		{"unknown series", &models.Series{ID: uuid.New()}, open.OrganizationID, "invoice", numbering.ErrSeriesNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := db.mustBegin(t)
+			defer tx.Rollback()
+
+			_, err := AssignNext(ctx, tx, tt.series.ID, tt.orgID, tt.documentType)
+			if !errx.IsCode(err, tt.want) {
+				t.Errorf("err = %v; want %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Abraxas-365/craftable/storex"
+	"github.com/Abraxas-365/craftable/storex/providers/storexpostgres"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/numbering"
+	"github.com/Abraxas-365/fuckturamelo/numbering/dto"
+	"github.com/Abraxas-365/fuckturamelo/numbering/models"
+)
+
+// seriesRepository implements SeriesRepository using storex
+type seriesRepository struct {
+	repo *storexpostgres.PgRepository[models.Series]
+	db   *sqlx.DB
+}
+
+// NewSeriesRepository creates a new numbering series repository
+func NewSeriesRepository(db *sqlx.DB) SeriesRepository {
+	repo := storexpostgres.NewPgRepository[models.Series](db, "invoice_number_series", "id")
+
+	return &seriesRepository{
+		repo: repo,
+		db:   db,
+	}
+}
+
+// Create creates a new numbering series
+func (r *seriesRepository) Create(ctx context.Context, series *models.Series) (*models.Series, error) {
+	if series.ID == uuid.Nil {
+		series.ID = uuid.New()
+	}
+
+	result, err := r.repo.Create(ctx, *series)
+	if err != nil {
+		if strings.Contains(err.Error(), "number_series_prefix_org_unique") {
+			return nil, numbering.NumberingErrors.New(numbering.ErrSeriesPrefixExists).
+				WithDetail("prefix", series.Prefix).
+				WithDetail("organization_id", series.OrganizationID.String()).
+				WithCause(err)
+		}
+		if strings.Contains(err.Error(), "violates check constraint") {
+			return nil, numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+				WithDetail("reason", "check_constraint").
+				WithCause(err)
+		}
+		return nil, numbering.NumberingErrors.New(numbering.ErrSeriesCreateFailed).
+			WithDetail("organization_id", series.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// GetByID retrieves a numbering series by ID
+func (r *seriesRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Series, error) {
+	result, err := r.repo.FindByID(ctx, id.String())
+	if err != nil {
+		if storex.IsRecordNotFound(err) {
+			return nil, numbering.NumberingErrors.New(numbering.ErrSeriesNotFound).
+				WithDetail("series_id", id.String())
+		}
+		return nil, numbering.NumberingErrors.New(numbering.ErrSeriesListFailed).
+			WithDetail("series_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// List lists numbering series matching the given filters, ordered by prefix
+func (r *seriesRepository) List(ctx context.Context, req *dto.SeriesListRequest) ([]*models.Series, error) {
+	conditions := []string{"1=1"}
+	args := []any{}
+
+	if req.OrganizationID != nil {
+		args = append(args, *req.OrganizationID)
+		conditions = append(conditions, fmt.Sprintf("organization_id = $%d", len(args)))
+	}
+	if req.DocumentType != nil {
+		args = append(args, *req.DocumentType)
+		conditions = append(conditions, fmt.Sprintf("document_type = $%d", len(args)))
+	}
+	if req.IsActive != nil {
+		args = append(args, *req.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+
+	query := fmt.Sprintf(`SELECT * FROM invoice_number_series WHERE %s ORDER BY document_type, prefix`,
+		strings.Join(conditions, " AND "))
+
+	series := []*models.Series{}
+	if err := r.db.SelectContext(ctx, &series, query, args...); err != nil {
+		return nil, numbering.NumberingErrors.New(numbering.ErrSeriesListFailed).
+			WithCause(err)
+	}
+
+	return series, nil
+}
+
+// SetStartNumber restarts an unused series at the given number
+func (r *seriesRepository) SetStartNumber(ctx context.Context, id uuid.UUID, startNumber int64) (*models.Series, error) {
+	query := `
+		UPDATE invoice_number_series
+		SET start_number = $2, next_number = $2
+		WHERE id = $1 AND is_active = true AND next_number = start_number
+		RETURNING *`
+
+	var result models.Series
+	err := r.db.GetContext(ctx, &result, query, id, startNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.explainRejection(ctx, id)
+		}
+		if strings.Contains(err.Error(), "violates check constraint") {
+			return nil, numbering.NumberingErrors.New(numbering.ErrSeriesValidationFailed).
+				WithDetail("field", "start_number").
+				WithDetail("reason", "check_constraint").
+				WithCause(err)
+		}
+		return nil, numbering.NumberingErrors.New(numbering.ErrSeriesUpdateFailed).
+			WithDetail("series_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// Retire stops a series from assigning further numbers
+func (r *seriesRepository) Retire(ctx context.Context, id uuid.UUID) (*models.Series, error) {
+	query := `
+		UPDATE invoice_number_series
+		SET is_active = false, retired_at = NOW()
+		WHERE id = $1 AND is_active = true
+		RETURNING *`
+
+	var result models.Series
+	err := r.db.GetContext(ctx, &result, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.explainRejection(ctx, id)
+		}
+		return nil, numbering.NumberingErrors.New(numbering.ErrSeriesUpdateFailed).
+			WithDetail("series_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// explainRejection reports why a conditional update matched no series
+func (r *seriesRepository) explainRejection(ctx context.Context, id uuid.UUID) error {
+	series, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !series.IsActive {
+		return numbering.NumberingErrors.New(numbering.ErrSeriesRetired).
+			WithDetail("series_id", id.String())
+	}
+	return numbering.NumberingErrors.New(numbering.ErrSeriesInUse).
+		WithDetail("series_id", id.String()).
+		WithDetail("issued_count", series.IssuedCount())
+}
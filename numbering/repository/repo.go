@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abraxas-365/fuckturamelo/numbering/dto"
+	"github.com/Abraxas-365/fuckturamelo/numbering/models"
+	"github.com/google/uuid"
+)
+
+// SeriesRepository defines the interface for numbering series repository operations.
+// Numbers are assigned with AssignNext inside the transaction that stores the
+// numbered document.
+type SeriesRepository interface {
+	// Basic CRUD operations
+	Create(ctx context.Context, series *models.Series) (*models.Series, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Series, error)
+
+	// Query operations
+	List(ctx context.Context, req *dto.SeriesListRequest) ([]*models.Series, error)
+
+	// Lifecycle operations
+	SetStartNumber(ctx context.Context, id uuid.UUID, startNumber int64) (*models.Series, error)
+	Retire(ctx context.Context, id uuid.UUID) (*models.Series, error)
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CreatePaymentRequest represents the request payload for recording a payment.
+// Allocations may be given now or added later.
+type CreatePaymentRequest struct {
+	OrganizationID uuid.UUID           `json:"organization_id" validate:"required"`
+	ProviderID     *uuid.UUID          `json:"provider_id,omitempty"`
+	Method         models.Method       `json:"method" validate:"required"`
+	PaymentDate    string              `json:"payment_date" validate:"required"` // YYYY-MM-DD
+	Amount         decimal.Decimal     `json:"amount" validate:"required"`
+	CurrencyCode   string              `json:"currency_code" validate:"required,len=3"`
+	Reference      *string             `json:"reference,omitempty"`
+	Notes          *string             `json:"notes,omitempty"`
+	Allocations    []AllocationRequest `json:"allocations,omitempty"`
+	CreatedBy      *uuid.UUID          `json:"created_by,omitempty"`
+}
+
+// AllocationRequest applies part of a payment to an invoice
+type AllocationRequest struct {
+	InvoiceID uuid.UUID       `json:"invoice_id" validate:"required"`
+	Amount    decimal.Decimal `json:"amount" validate:"required"`
+}
+
+// AllocatePaymentRequest represents the request payload for applying more of
+// a payment to invoices. Amounts add up with existing allocations.
+type AllocatePaymentRequest struct {
+	Allocations []AllocationRequest `json:"allocations" validate:"required,min=1"`
+	CreatedBy   *uuid.UUID          `json:"created_by,omitempty"`
+}
+
+// VoidPaymentRequest represents the request payload for voiding a payment
+type VoidPaymentRequest struct {
+	VoidedBy uuid.UUID `json:"voided_by" validate:"required"`
+	Reason   *string   `json:"reason,omitempty"`
+}
+
+// PaymentListRequest represents query parameters for listing payments
+type PaymentListRequest struct {
+	OrganizationID *uuid.UUID     `query:"organization_id"`
+	ProviderID     *uuid.UUID     `query:"provider_id"`
+	InvoiceID      *uuid.UUID     `query:"invoice_id"`
+	Method         *models.Method `query:"method"`
+	CurrencyCode   *string        `query:"currency_code"`
+	DateFrom       *time.Time     `query:"date_from"`
+	DateTo         *time.Time     `query:"date_to"`
+	IncludeVoided  bool           `query:"include_voided"`
+	Page           int            `query:"page" validate:"min=1"`
+	PageSize       int            `query:"page_size" validate:"min=1,max=100"`
+}
+
+// PaymentResponse represents the response for a single payment
+type PaymentResponse struct {
+	*models.Payment   `json:",inline"`
+	AllocatedAmount   decimal.Decimal `json:"allocated_amount"`
+	UnallocatedAmount decimal.Decimal `json:"unallocated_amount"`
+}
+
+// PaymentListResponse represents the response for listing payments
+type PaymentListResponse struct {
+	Payments    []*PaymentResponse `json:"payments"`
+	Total       int64              `json:"total"`
+	Page        int                `json:"page"`
+	PageSize    int                `json:"page_size"`
+	TotalPages  int                `json:"total_pages"`
+	HasNext     bool               `json:"has_next"`
+	HasPrevious bool               `json:"has_previous"`
+}
@@ -0,0 +1,112 @@
+package payments
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// PaymentsErrors is the error registry for payments domain
+var PaymentsErrors = errx.NewRegistry("PAYMENTS")
+
+// Payment error codes
+var (
+	// Basic CRUD errors
+	ErrPaymentNotFound = PaymentsErrors.Register(
+		"NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Payment not found",
+	)
+
+	ErrPaymentCreateFailed = PaymentsErrors.Register(
+		"CREATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to create payment",
+	)
+
+	ErrPaymentUpdateFailed = PaymentsErrors.Register(
+		"UPDATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to update payment",
+	)
+
+	// Query errors
+	ErrPaymentListFailed = PaymentsErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list payments",
+	)
+
+	// Validation errors
+	ErrPaymentValidationFailed = PaymentsErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Payment validation failed",
+	)
+
+	ErrPaymentInvalidReference = PaymentsErrors.Register(
+		"INVALID_REFERENCE",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Payment references an unknown organization or provider",
+	)
+
+	// Allocation errors
+	ErrInvoiceNotPayable = PaymentsErrors.Register(
+		"INVOICE_NOT_PAYABLE",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Invoice cannot be settled by this payment",
+	)
+
+	ErrPaymentOverAllocated = PaymentsErrors.Register(
+		"OVER_ALLOCATED",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Allocations exceed the payment amount",
+	)
+
+	ErrAllocationExceedsOutstanding = PaymentsErrors.Register(
+		"EXCEEDS_OUTSTANDING",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Allocation exceeds the outstanding amount of the invoice",
+	)
+
+	ErrAllocationNotFound = PaymentsErrors.Register(
+		"ALLOCATION_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Payment is not allocated to this invoice",
+	)
+
+	// Lifecycle errors
+	ErrPaymentVoided = PaymentsErrors.Register(
+		"PAYMENT_VOIDED",
+		errx.TypeBusiness,
+		http.StatusConflict,
+		"Payment is voided and can no longer change",
+	)
+)
+
+// Helper functions for error checking
+func IsPaymentNotFound(err error) bool {
+	return errx.IsCode(err, ErrPaymentNotFound)
+}
+
+func IsPaymentOverAllocated(err error) bool {
+	return errx.IsCode(err, ErrPaymentOverAllocated)
+}
+
+func IsAllocationExceedsOutstanding(err error) bool {
+	return errx.IsCode(err, ErrAllocationExceedsOutstanding)
+}
+
+func IsPaymentVoided(err error) bool {
+	return errx.IsCode(err, ErrPaymentVoided)
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Method is how a payment was made
+type Method string
+
+// Supported payment methods
+const (
+	MethodBankTransfer Method = "bank_transfer"
+	MethodCard         Method = "card"
+	MethodCash         Method = "cash"
+	MethodCheck        Method = "check"
+	MethodDirectDebit  Method = "direct_debit"
+	MethodOther        Method = "other"
+)
+
+// Methods returns the supported payment methods
+func Methods() []Method {
+	return []Method{MethodBankTransfer, MethodCard, MethodCash, MethodCheck, MethodDirectDebit, MethodOther}
+}
+
+// Valid reports whether the method is supported
+func (m Method) Valid() bool {
+	return slices.Contains(Methods(), m)
+}
+
+// Payment represents money paid to a provider. Its amount is spread over
+// invoices by allocations; voided payments settle nothing.
+type Payment struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	OrganizationID uuid.UUID       `db:"organization_id" json:"organization_id"`
+	ProviderID     *uuid.UUID      `db:"provider_id" json:"provider_id"`
+	Method         Method          `db:"method" json:"method"`
+	PaymentDate    time.Time       `db:"payment_date" json:"payment_date"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	CurrencyCode   string          `db:"currency_code" json:"currency_code"`
+	Reference      *string         `db:"reference" json:"reference"`
+	Notes          *string         `db:"notes" json:"notes"`
+
+	VoidedAt   *time.Time `db:"voided_at" json:"voided_at"`
+	VoidedBy   *uuid.UUID `db:"voided_by" json:"voided_by"`
+	VoidReason *string    `db:"void_reason" json:"void_reason"`
+
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+
+	// Allocations are stored in payment_allocations
+	Allocations []Allocation `db:"-" json:"allocations"`
+}
+
+// TableName returns the table name for the Payment model
+func (p Payment) TableName() string {
+	return "payments"
+}
+
+// IsVoided reports whether the payment was voided
+func (p Payment) IsVoided() bool {
+	return p.VoidedAt != nil
+}
+
+// AllocatedAmount adds up the allocations of the payment
+func (p Payment) AllocatedAmount() decimal.Decimal {
+	total := decimal.Zero
+	for _, allocation := range p.Allocations {
+		total = total.Add(allocation.Amount)
+	}
+	return total
+}
+
+// UnallocatedAmount is the part of the payment not yet applied to invoices
+func (p Payment) UnallocatedAmount() decimal.Decimal {
+	return p.Amount.Sub(p.AllocatedAmount())
+}
+
+// Allocation applies part of a payment to an invoice
+type Allocation struct {
+	ID        uuid.UUID       `db:"id" json:"id"`
+	PaymentID uuid.UUID       `db:"payment_id" json:"payment_id"`
+	InvoiceID uuid.UUID       `db:"invoice_id" json:"invoice_id"`
+	Amount    decimal.Decimal `db:"amount" json:"amount"`
+	CreatedBy *uuid.UUID      `db:"created_by" json:"created_by"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the table name for the Allocation model
+func (a Allocation) TableName() string {
+	return "payment_allocations"
+}
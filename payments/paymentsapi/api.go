@@ -0,0 +1,322 @@
+package paymentsapi
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/payments"
+	"github.com/Abraxas-365/fuckturamelo/payments/dto"
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+	"github.com/Abraxas-365/fuckturamelo/payments/paymentsrv"
+	postgres "github.com/Abraxas-365/fuckturamelo/payments/repository"
+)
+
+// PaymentsAPI contains the complete API setup for the payments domain
+type PaymentsAPI struct {
+	service paymentsrv.PaymentService
+	repo    postgres.PaymentRepository
+}
+
+// Config contains configuration for the payments API
+type Config struct {
+	DB *sqlx.DB
+}
+
+// New creates a new PaymentsAPI instance
+func New(config Config) (*PaymentsAPI, error) {
+	if config.DB == nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewPaymentRepository(config.DB)
+	svc := paymentsrv.NewPaymentService(repo)
+
+	return &PaymentsAPI{
+		service: svc,
+		repo:    repo,
+	}, nil
+}
+
+// SetupRoutes registers all payment routes with the given Fiber router group
+func (api *PaymentsAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Basic routes
+	router.Post("/", api.createPayment)
+	router.Get("/", api.listPayments)
+	router.Get("/:id", api.getPayment)
+
+	// Allocation routes
+	router.Post("/:id/allocations", api.allocatePayment)
+	router.Delete("/:id/allocations/:invoiceId", api.unallocatePayment)
+
+	// Lifecycle routes
+	router.Post("/:id/void", api.voidPayment)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *PaymentsAPI) GetService() paymentsrv.PaymentService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *PaymentsAPI) GetRepository() postgres.PaymentRepository {
+	return api.repo
+}
+
+// Basic handlers
+
+// createPayment handles POST /payments
+func (api *PaymentsAPI) createPayment(c *fiber.Ctx) error {
+	var req dto.CreatePaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.CreatePayment(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getPayment handles GET /payments/:id
+func (api *PaymentsAPI) getPayment(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetPayment(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listPayments handles GET /payments
+func (api *PaymentsAPI) listPayments(c *fiber.Ctx) error {
+	req, err := api.parseListRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListPayments(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Allocation handlers
+
+// allocatePayment handles POST /payments/:id/allocations
+func (api *PaymentsAPI) allocatePayment(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.AllocatePaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.AllocatePayment(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// unallocatePayment handles DELETE /payments/:id/allocations/:invoiceId
+func (api *PaymentsAPI) unallocatePayment(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	invoiceID, err := api.parseUUIDParam(c, "invoiceId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.UnallocatePayment(c.Context(), id, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Lifecycle handlers
+
+// voidPayment handles POST /payments/:id/void
+func (api *PaymentsAPI) voidPayment(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.VoidPaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.VoidPayment(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// healthCheck provides a health check endpoint
+func (api *PaymentsAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "payments",
+	})
+}
+
+// Helper methods
+
+func (api *PaymentsAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
+
+func (api *PaymentsAPI) parseUUIDQuery(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Invalid "+name+" format").
+			WithCause(err)
+	}
+
+	return &id, nil
+}
+
+func (api *PaymentsAPI) parseDateQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(invoicemodels.DateLayout, value)
+	if err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("error", "Invalid "+name+" format (expected YYYY-MM-DD)").
+			WithCause(err)
+	}
+
+	return &date, nil
+}
+
+func (api *PaymentsAPI) parseListRequest(c *fiber.Ctx) (*dto.PaymentListRequest, error) {
+	req := &dto.PaymentListRequest{}
+	var err error
+
+	// Parse reference filters
+	if req.OrganizationID, err = api.parseUUIDQuery(c, "organization_id"); err != nil {
+		return nil, err
+	}
+	if req.ProviderID, err = api.parseUUIDQuery(c, "provider_id"); err != nil {
+		return nil, err
+	}
+	if req.InvoiceID, err = api.parseUUIDQuery(c, "invoice_id"); err != nil {
+		return nil, err
+	}
+
+	// Parse method and currency
+	if method := c.Query("method"); method != "" {
+		m := models.Method(method)
+		req.Method = &m
+	}
+	if currency := c.Query("currency_code"); currency != "" {
+		req.CurrencyCode = &currency
+	}
+
+	// Parse date range
+	if req.DateFrom, err = api.parseDateQuery(c, "date_from"); err != nil {
+		return nil, err
+	}
+	if req.DateTo, err = api.parseDateQuery(c, "date_to"); err != nil {
+		return nil, err
+	}
+
+	// Parse voided payment visibility
+	req.IncludeVoided = c.QueryBool("include_voided", false)
+
+	// Parse pagination
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	req.Page = page
+
+	pageSize := postgres.DefaultPageSize
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= postgres.MaxPageSize {
+			pageSize = ps
+		}
+	}
+	req.PageSize = pageSize
+
+	return req, nil
+}
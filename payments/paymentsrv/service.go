@@ -0,0 +1,238 @@
+package paymentsrv
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/payments"
+	"github.com/Abraxas-365/fuckturamelo/payments/dto"
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/payments/repository"
+)
+
+// maxAllocations bounds the invoices a single request may settle
+const maxAllocations = 500
+
+// PaymentService defines the interface for payment business logic
+type PaymentService interface {
+	// Basic operations
+	CreatePayment(ctx context.Context, req *dto.CreatePaymentRequest) (*dto.PaymentResponse, error)
+	GetPayment(ctx context.Context, id uuid.UUID) (*dto.PaymentResponse, error)
+	ListPayments(ctx context.Context, req *dto.PaymentListRequest) (*dto.PaymentListResponse, error)
+
+	// Allocation operations
+	AllocatePayment(ctx context.Context, id uuid.UUID, req *dto.AllocatePaymentRequest) (*dto.PaymentResponse, error)
+	UnallocatePayment(ctx context.Context, id, invoiceID uuid.UUID) (*dto.PaymentResponse, error)
+
+	// Lifecycle operations
+	VoidPayment(ctx context.Context, id uuid.UUID, req *dto.VoidPaymentRequest) (*dto.PaymentResponse, error)
+}
+
+// paymentService implements PaymentService
+type paymentService struct {
+	repo postgres.PaymentRepository
+}
+
+// NewPaymentService creates a new payment service
+func NewPaymentService(repo postgres.PaymentRepository) PaymentService {
+	return &paymentService{
+		repo: repo,
+	}
+}
+
+// CreatePayment records a payment and applies it to the given invoices
+func (s *paymentService) CreatePayment(ctx context.Context, req *dto.CreatePaymentRequest) (*dto.PaymentResponse, error) {
+	paymentDate, err := s.validateCreateRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := allocationsFromRequest(req.Allocations, req.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &models.Payment{
+		OrganizationID: req.OrganizationID,
+		ProviderID:     req.ProviderID,
+		Method:         req.Method,
+		PaymentDate:    paymentDate,
+		Amount:         req.Amount,
+		CurrencyCode:   req.CurrencyCode,
+		Reference:      req.Reference,
+		Notes:          req.Notes,
+		CreatedBy:      req.CreatedBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		Allocations:    allocations,
+	}
+
+	created, err := s.repo.Create(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentResponse(created), nil
+}
+
+// GetPayment retrieves a payment with its allocations
+func (s *paymentService) GetPayment(ctx context.Context, id uuid.UUID) (*dto.PaymentResponse, error) {
+	payment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentResponse(payment), nil
+}
+
+// ListPayments lists payments with filtering and pagination
+func (s *paymentService) ListPayments(ctx context.Context, req *dto.PaymentListRequest) (*dto.PaymentListResponse, error) {
+	if req.Method != nil && !req.Method.Valid() {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("field", "method").
+			WithDetail("reason", "unsupported").
+			WithDetail("supported", models.Methods())
+	}
+
+	return s.repo.List(ctx, req)
+}
+
+// AllocatePayment applies more of a payment to invoices
+func (s *paymentService) AllocatePayment(ctx context.Context, id uuid.UUID, req *dto.AllocatePaymentRequest) (*dto.PaymentResponse, error) {
+	if len(req.Allocations) == 0 {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("field", "allocations").
+			WithDetail("reason", "required")
+	}
+
+	allocations, err := allocationsFromRequest(req.Allocations, req.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.repo.Allocate(ctx, id, allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentResponse(payment), nil
+}
+
+// UnallocatePayment takes a payment back from an invoice
+func (s *paymentService) UnallocatePayment(ctx context.Context, id, invoiceID uuid.UUID) (*dto.PaymentResponse, error) {
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.IsVoided() {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentVoided).
+			WithDetail("payment_id", id.String())
+	}
+
+	payment, err := s.repo.Unallocate(ctx, id, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentResponse(payment), nil
+}
+
+// VoidPayment cancels a payment; the invoices it settled become outstanding again
+func (s *paymentService) VoidPayment(ctx context.Context, id uuid.UUID, req *dto.VoidPaymentRequest) (*dto.PaymentResponse, error) {
+	if req.VoidedBy == uuid.Nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("field", "voided_by").
+			WithDetail("reason", "required")
+	}
+
+	payment, err := s.repo.Void(ctx, id, req.VoidedBy, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentResponse(payment), nil
+}
+
+// Helper methods
+
+func (s *paymentService) validateCreateRequest(req *dto.CreatePaymentRequest) (time.Time, error) {
+	fail := func(field, reason string) (time.Time, error) {
+		return time.Time{}, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("field", field).
+			WithDetail("reason", reason)
+	}
+
+	if req.OrganizationID == uuid.Nil {
+		return fail("organization_id", "required")
+	}
+	if !req.Method.Valid() {
+		return fail("method", "must be one of bank_transfer, card, cash, check, direct_debit, other")
+	}
+	if !req.Amount.IsPositive() {
+		return fail("amount", "must be greater than zero")
+	}
+	if !req.Amount.Equal(req.Amount.Round(2)) {
+		return fail("amount", "must not have more than two decimals")
+	}
+	if len(req.CurrencyCode) != 3 {
+		return fail("currency_code", "invalid_currency_code")
+	}
+
+	paymentDate, err := time.Parse(invoicemodels.DateLayout, req.PaymentDate)
+	if err != nil {
+		return fail("payment_date", "invalid_date")
+	}
+
+	return paymentDate, nil
+}
+
+func allocationsFromRequest(reqs []dto.AllocationRequest, createdBy *uuid.UUID) ([]models.Allocation, error) {
+	if len(reqs) > maxAllocations {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+			WithDetail("field", "allocations").
+			WithDetail("reason", "too_many").
+			WithDetail("max", maxAllocations)
+	}
+
+	allocations := make([]models.Allocation, 0, len(reqs))
+	seen := make(map[uuid.UUID]bool, len(reqs))
+	for i, req := range reqs {
+		fail := func(field, reason string) error {
+			return payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+				WithDetail("index", i).
+				WithDetail("field", field).
+				WithDetail("reason", reason)
+		}
+
+		switch {
+		case req.InvoiceID == uuid.Nil:
+			return nil, fail("invoice_id", "required")
+		case seen[req.InvoiceID]:
+			return nil, fail("invoice_id", "allocated more than once")
+		case !req.Amount.IsPositive():
+			return nil, fail("amount", "must be greater than zero")
+		case !req.Amount.Equal(req.Amount.Round(2)):
+			return nil, fail("amount", "must not have more than two decimals")
+		}
+		seen[req.InvoiceID] = true
+
+		allocations = append(allocations, models.Allocation{
+			InvoiceID: req.InvoiceID,
+			Amount:    req.Amount,
+			CreatedBy: createdBy,
+		})
+	}
+
+	return allocations, nil
+}
+
+func paymentResponse(payment *models.Payment) *dto.PaymentResponse {
+	return &dto.PaymentResponse{
+		Payment:           payment,
+		AllocatedAmount:   payment.AllocatedAmount(),
+		UnallocatedAmount: payment.UnallocatedAmount(),
+	}
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/payments"
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+)
+
+// payableInvoice is what an allocation needs to know about its invoice
+type payableInvoice struct {
+	OrganizationID    uuid.UUID       `db:"organization_id"`
+	CurrencyCode      *string         `db:"currency_code"`
+	DocumentKind      string          `db:"document_kind"`
+	Status            *string         `db:"status"`
+	IsDeleted         bool            `db:"is_deleted"`
+	OutstandingAmount decimal.Decimal `db:"outstanding_amount"`
+}
+
+// allocate applies parts of a locked payment to invoices. Invoices are
+// locked in ID order, the same lock credit and debit notes take, so that
+// concurrent payments and notes cannot settle more than is outstanding.
+func allocate(ctx context.Context, tx *sqlx.Tx, payment *models.Payment, allocations []models.Allocation) error {
+	if len(allocations) == 0 {
+		return nil
+	}
+	if payment.IsVoided() {
+		return payments.PaymentsErrors.New(payments.ErrPaymentVoided).
+			WithDetail("payment_id", payment.ID.String())
+	}
+
+	allocateError := func(err error) error {
+		return payments.PaymentsErrors.New(payments.ErrPaymentUpdateFailed).
+			WithDetail("payment_id", payment.ID.String()).
+			WithCause(err)
+	}
+
+	var allocated decimal.Decimal
+	err := tx.GetContext(ctx, &allocated,
+		`SELECT COALESCE(SUM(amount), 0) FROM payment_allocations WHERE payment_id = $1`, payment.ID)
+	if err != nil {
+		return allocateError(err)
+	}
+
+	sorted := append([]models.Allocation(nil), allocations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].InvoiceID.String() < sorted[j].InvoiceID.String()
+	})
+
+	for _, allocation := range sorted {
+		invoice, err := lockInvoice(ctx, tx, allocation.InvoiceID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return payments.PaymentsErrors.New(payments.ErrInvoiceNotPayable).
+					WithDetail("invoice_id", allocation.InvoiceID.String()).
+					WithDetail("reason", "invoice not found")
+			}
+			return allocateError(err)
+		}
+
+		if err := checkPayable(payment, allocation, invoice); err != nil {
+			return err
+		}
+
+		insert := `
+			INSERT INTO payment_allocations (id, payment_id, invoice_id, amount, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())
+			ON CONFLICT (payment_id, invoice_id)
+			DO UPDATE SET amount = payment_allocations.amount + EXCLUDED.amount`
+
+		_, err = tx.ExecContext(ctx, insert,
+			uuid.New(), payment.ID, allocation.InvoiceID, allocation.Amount, allocation.CreatedBy)
+		if err != nil {
+			return allocateError(err)
+		}
+
+		allocated = allocated.Add(allocation.Amount)
+	}
+
+	if allocated.GreaterThan(payment.Amount) {
+		return payments.PaymentsErrors.New(payments.ErrPaymentOverAllocated).
+			WithDetail("payment_id", payment.ID.String()).
+			WithDetail("amount", payment.Amount.String()).
+			WithDetail("allocated_amount", allocated.String())
+	}
+
+	return nil
+}
+
+// checkPayable requires the invoice to be a live, non-void regular invoice of
+// the payment's organization and currency with enough left outstanding
+func checkPayable(payment *models.Payment, allocation models.Allocation, invoice *payableInvoice) error {
+	notPayable := func(reason string) error {
+		return payments.PaymentsErrors.New(payments.ErrInvoiceNotPayable).
+			WithDetail("invoice_id", allocation.InvoiceID.String()).
+			WithDetail("reason", reason)
+	}
+
+	switch {
+	case invoice.OrganizationID != payment.OrganizationID:
+		return notPayable("invoice belongs to another organization")
+	case invoice.IsDeleted:
+		return notPayable("invoice is deleted")
+	case invoice.Status != nil && *invoice.Status == invoicemodels.VoidStatus:
+		return notPayable("invoice is void")
+	case invoice.DocumentKind != "invoice":
+		return notPayable("credit and debit notes are settled through their original invoice")
+	case invoice.CurrencyCode == nil || *invoice.CurrencyCode != payment.CurrencyCode:
+		return notPayable("invoice currency differs from the payment currency")
+	}
+
+	if allocation.Amount.GreaterThan(invoice.OutstandingAmount) {
+		return payments.PaymentsErrors.New(payments.ErrAllocationExceedsOutstanding).
+			WithDetail("invoice_id", allocation.InvoiceID.String()).
+			WithDetail("amount", allocation.Amount.String()).
+			WithDetail("outstanding_amount", invoice.OutstandingAmount.String())
+	}
+
+	return nil
+}
+
+// lockInvoice locks an invoice row and reads its outstanding amount
+func lockInvoice(ctx context.Context, tx *sqlx.Tx, invoiceID uuid.UUID) (*payableInvoice, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT i.organization_id, i.currency_code, i.document_kind, i.status, i.is_deleted, b.outstanding_amount
+		FROM invoices i
+		JOIN invoice_balances b ON b.invoice_id = i.id
+		WHERE i.id = $1`
+
+	var invoice payableInvoice
+	if err := tx.GetContext(ctx, &invoice, query, invoiceID); err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// lockPayment locks a payment row for a change of its allocations
+func lockPayment(ctx context.Context, tx *sqlx.Tx, paymentID uuid.UUID) (*models.Payment, error) {
+	var payment models.Payment
+	err := tx.GetContext(ctx, &payment, `SELECT * FROM payments WHERE id = $1 FOR UPDATE`, paymentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, payments.PaymentsErrors.New(payments.ErrPaymentNotFound).
+				WithDetail("payment_id", paymentID.String())
+		}
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentUpdateFailed).
+			WithDetail("payment_id", paymentID.String()).
+			WithCause(err)
+	}
+
+	return &payment, nil
+}
+
+// selectAllocations retrieves the allocations of a payment, oldest first
+func selectAllocations(ctx context.Context, q sqlx.QueryerContext, paymentID uuid.UUID) ([]models.Allocation, error) {
+	allocations := []models.Allocation{}
+	query := `SELECT * FROM payment_allocations WHERE payment_id = $1 ORDER BY created_at, id`
+	if err := sqlx.SelectContext(ctx, q, &allocations, query, paymentID); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
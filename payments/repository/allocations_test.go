@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/payments"
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+)
+
+func TestCheckPayable(t *testing.T) {
+	organizationID := uuid.New()
+	payment := &models.Payment{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		Amount:         decimal.RequireFromString("500"),
+		CurrencyCode:   "PEN",
+	}
+
+	payable := func(change func(*payableInvoice)) *payableInvoice {
+		currency, status := "PEN", "approved"
+		invoice := &payableInvoice{
+			OrganizationID:    organizationID,
+			CurrencyCode:      &currency,
+			DocumentKind:      "invoice",
+			Status:            &status,
+			OutstandingAmount: decimal.RequireFromString("300"),
+		}
+		if change != nil {
+			change(invoice)
+		}
+		return invoice
+	}
+	text := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		invoice *payableInvoice
+		amount  string
+		code    errx.Code // empty when the allocation is allowed
+		reason  string
+	}{
+		{"payable", payable(nil), "300", "", ""},
+		{"partial", payable(nil), "120.50", "", ""},
+		{"without status", payable(func(i *payableInvoice) { i.Status = nil }), "100", "", ""},
+		{"other organization", payable(func(i *payableInvoice) { i.OrganizationID = uuid.New() }), "100", payments.ErrInvoiceNotPayable, "invoice belongs to another organization"},
+		{"deleted", payable(func(i *payableInvoice) { i.IsDeleted = true }), "100", payments.ErrInvoiceNotPayable, "invoice is deleted"},
+		{"void", payable(func(i *payableInvoice) { i.Status = text("void") }), "100", payments.ErrInvoiceNotPayable, "invoice is void"},
+		{"credit note", payable(func(i *payableInvoice) { i.DocumentKind = "credit_note" }), "100", payments.ErrInvoiceNotPayable, "credit and debit notes are settled through their original invoice"},
+		{"no currency", payable(func(i *payableInvoice) { i.CurrencyCode = nil }), "100", payments.ErrInvoiceNotPayable, "invoice currency differs from the payment currency"},
+		{"other currency", payable(func(i *payableInvoice) { i.CurrencyCode = text("USD") }), "100", payments.ErrInvoiceNotPayable, "invoice currency differs from the payment currency"},
+		{"over outstanding", payable(nil), "300.01", payments.ErrAllocationExceedsOutstanding, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocation := models.Allocation{InvoiceID: uuid.New(), Amount: decimal.RequireFromString(tt.amount)}
+			err := checkPayable(payment, allocation, tt.invoice)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("checkPayable: %v", err)
+				}
+				return
+			}
+			if !errx.IsCode(err, tt.code) {
+				t.Fatalf("err = %v; want %s", err, tt.code)
+			}
+			if tt.reason != "" {
+				if got := err.(*errx.Error).Details["reason"]; got != tt.reason {
+					t.Errorf("reason = %v; want %q", got, tt.reason)
+				}
+			}
+		})
+	}
+}
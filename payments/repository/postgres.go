@@ -0,0 +1,310 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/Abraxas-365/fuckturamelo/payments"
+	"github.com/Abraxas-365/fuckturamelo/payments/dto"
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// paymentRepository implements PaymentRepository with sqlx
+type paymentRepository struct {
+	db *sqlx.DB
+}
+
+// NewPaymentRepository creates a new payment repository
+func NewPaymentRepository(db *sqlx.DB) PaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+// Create records a payment together with its initial allocations
+func (r *paymentRepository) Create(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentCreateFailed).
+			WithDetail("organization_id", payment.OrganizationID.String()).
+			WithCause(err)
+	}
+	defer tx.Rollback()
+
+	result, err := RecordPayment(ctx, tx, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentCreateFailed).
+			WithDetail("organization_id", payment.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	return result, nil
+}
+
+// GetByID retrieves a payment with its allocations
+func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	var result models.Payment
+	err := r.db.GetContext(ctx, &result, `SELECT * FROM payments WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, payments.PaymentsErrors.New(payments.ErrPaymentNotFound).
+				WithDetail("payment_id", id.String())
+		}
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentListFailed).
+			WithDetail("payment_id", id.String()).
+			WithCause(err)
+	}
+
+	if result.Allocations, err = selectAllocations(ctx, r.db, id); err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentListFailed).
+			WithDetail("payment_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// List lists payments matching the given filters, newest first
+func (r *paymentRepository) List(ctx context.Context, req *dto.PaymentListRequest) (*dto.PaymentListResponse, error) {
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = DefaultPageSize
+	}
+	if req.PageSize > MaxPageSize {
+		req.PageSize = MaxPageSize
+	}
+
+	conditions := []string{"1=1"}
+	args := []any{}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if req.OrganizationID != nil {
+		add("organization_id = $%d", *req.OrganizationID)
+	}
+	if req.ProviderID != nil {
+		add("provider_id = $%d", *req.ProviderID)
+	}
+	if req.InvoiceID != nil {
+		add("id IN (SELECT payment_id FROM payment_allocations WHERE invoice_id = $%d)", *req.InvoiceID)
+	}
+	if req.Method != nil {
+		add("method = $%d", *req.Method)
+	}
+	if req.CurrencyCode != nil {
+		add("currency_code = $%d", *req.CurrencyCode)
+	}
+	if req.DateFrom != nil {
+		add("payment_date >= $%d", *req.DateFrom)
+	}
+	if req.DateTo != nil {
+		add("payment_date <= $%d", *req.DateTo)
+	}
+	if !req.IncludeVoided {
+		conditions = append(conditions, "voided_at IS NULL")
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM payments WHERE "+whereClause, args...); err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentListFailed).
+			WithCause(err)
+	}
+
+	dataQuery := fmt.Sprintf(`
+		SELECT * FROM payments
+		WHERE %s
+		ORDER BY payment_date DESC, created_at DESC, id
+		LIMIT $%d OFFSET $%d`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, req.PageSize, (req.Page-1)*req.PageSize)
+
+	paymentList := []*models.Payment{}
+	if err := r.db.SelectContext(ctx, &paymentList, dataQuery, args...); err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentListFailed).
+			WithCause(err)
+	}
+
+	if err := r.loadAllocations(ctx, paymentList); err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentListFailed).
+			WithCause(err)
+	}
+
+	responses := make([]*dto.PaymentResponse, len(paymentList))
+	for i, payment := range paymentList {
+		responses[i] = &dto.PaymentResponse{
+			Payment:           payment,
+			AllocatedAmount:   payment.AllocatedAmount(),
+			UnallocatedAmount: payment.UnallocatedAmount(),
+		}
+	}
+
+	// Calculate pagination metadata
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
+	}
+
+	return &dto.PaymentListResponse{
+		Payments:    responses,
+		Total:       total,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		TotalPages:  totalPages,
+		HasNext:     req.Page < totalPages,
+		HasPrevious: req.Page > 1,
+	}, nil
+}
+
+// Allocate applies more of a payment to invoices
+func (r *paymentRepository) Allocate(ctx context.Context, paymentID uuid.UUID, allocations []models.Allocation) (*models.Payment, error) {
+	allocateError := func(err error) error {
+		return payments.PaymentsErrors.New(payments.ErrPaymentUpdateFailed).
+			WithDetail("payment_id", paymentID.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, allocateError(err)
+	}
+	defer tx.Rollback()
+
+	payment, err := AddAllocations(ctx, tx, paymentID, allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, allocateError(err)
+	}
+
+	return payment, nil
+}
+
+// Unallocate removes the part of a payment applied to an invoice
+func (r *paymentRepository) Unallocate(ctx context.Context, paymentID, invoiceID uuid.UUID) (*models.Payment, error) {
+	unallocateError := func(err error) error {
+		return payments.PaymentsErrors.New(payments.ErrPaymentUpdateFailed).
+			WithDetail("payment_id", paymentID.String()).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, unallocateError(err)
+	}
+	defer tx.Rollback()
+
+	payment, err := lockPayment(ctx, tx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM payment_allocations WHERE payment_id = $1 AND invoice_id = $2`, paymentID, invoiceID)
+	if err != nil {
+		return nil, unallocateError(err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return nil, unallocateError(err)
+	} else if rows == 0 {
+		return nil, payments.PaymentsErrors.New(payments.ErrAllocationNotFound).
+			WithDetail("payment_id", paymentID.String()).
+			WithDetail("invoice_id", invoiceID.String())
+	}
+
+	if payment.Allocations, err = selectAllocations(ctx, tx, paymentID); err != nil {
+		return nil, unallocateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, unallocateError(err)
+	}
+
+	return payment, nil
+}
+
+// Void marks a payment as voided. Its allocations are kept for the record
+// but no longer settle the invoices.
+func (r *paymentRepository) Void(ctx context.Context, id, voidedBy uuid.UUID, reason *string) (*models.Payment, error) {
+	query := `
+		UPDATE payments
+		SET voided_at = NOW(), voided_by = $2, void_reason = $3
+		WHERE id = $1 AND voided_at IS NULL
+		RETURNING *`
+
+	var result models.Payment
+	err := r.db.GetContext(ctx, &result, query, id, voidedBy, reason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Tell a missing payment apart from one that is already voided
+			if _, getErr := r.GetByID(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, payments.PaymentsErrors.New(payments.ErrPaymentVoided).
+				WithDetail("payment_id", id.String())
+		}
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentUpdateFailed).
+			WithDetail("payment_id", id.String()).
+			WithCause(err)
+	}
+
+	if result.Allocations, err = selectAllocations(ctx, r.db, id); err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentListFailed).
+			WithDetail("payment_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
+// loadAllocations fills in the allocations of several payments at once
+func (r *paymentRepository) loadAllocations(ctx context.Context, paymentList []*models.Payment) error {
+	if len(paymentList) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(paymentList))
+	byID := make(map[uuid.UUID]*models.Payment, len(paymentList))
+	for i, payment := range paymentList {
+		ids[i] = payment.ID.String()
+		byID[payment.ID] = payment
+		payment.Allocations = []models.Allocation{}
+	}
+
+	var allocations []models.Allocation
+	query := `SELECT * FROM payment_allocations WHERE payment_id = ANY($1::uuid[]) ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &allocations, query, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	for _, allocation := range allocations {
+		payment := byID[allocation.PaymentID]
+		payment.Allocations = append(payment.Allocations, allocation)
+	}
+
+	return nil
+}
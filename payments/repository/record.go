@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/payments"
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+)
+
+// RecordPayment inserts a payment and applies its allocations inside tx, so
+// other domains can settle invoices atomically with their own writes (bank
+// reconciliation records the payment behind an accepted statement line this
+// way). The payment is only visible once tx commits.
+func RecordPayment(ctx context.Context, tx *sqlx.Tx, payment *models.Payment) (*models.Payment, error) {
+	if payment.ID == uuid.Nil {
+		payment.ID = uuid.New()
+	}
+
+	createError := func(err error) error {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			return payments.PaymentsErrors.New(payments.ErrPaymentInvalidReference).
+				WithDetail("organization_id", payment.OrganizationID.String()).
+				WithCause(err)
+		}
+		if strings.Contains(err.Error(), "violates check constraint") {
+			return payments.PaymentsErrors.New(payments.ErrPaymentValidationFailed).
+				WithDetail("reason", "check_constraint").
+				WithCause(err)
+		}
+		return payments.PaymentsErrors.New(payments.ErrPaymentCreateFailed).
+			WithDetail("organization_id", payment.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	query := `
+		INSERT INTO payments
+			(id, organization_id, provider_id, method, payment_date, amount, currency_code,
+			 reference, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING *`
+
+	var result models.Payment
+	err := tx.GetContext(ctx, &result, query,
+		payment.ID, payment.OrganizationID, payment.ProviderID, payment.Method, payment.PaymentDate,
+		payment.Amount, payment.CurrencyCode, payment.Reference, payment.Notes,
+		payment.CreatedBy, payment.CreatedAt, payment.UpdatedAt)
+	if err != nil {
+		return nil, createError(err)
+	}
+
+	if err := allocate(ctx, tx, &result, payment.Allocations); err != nil {
+		return nil, err
+	}
+
+	if result.Allocations, err = selectAllocations(ctx, tx, result.ID); err != nil {
+		return nil, createError(err)
+	}
+
+	return &result, nil
+}
+
+// AddAllocations locks a payment inside tx and applies more of it to invoices
+func AddAllocations(ctx context.Context, tx *sqlx.Tx, paymentID uuid.UUID, allocations []models.Allocation) (*models.Payment, error) {
+	payment, err := lockPayment(ctx, tx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := allocate(ctx, tx, payment, allocations); err != nil {
+		return nil, err
+	}
+
+	if payment.Allocations, err = selectAllocations(ctx, tx, paymentID); err != nil {
+		return nil, payments.PaymentsErrors.New(payments.ErrPaymentUpdateFailed).
+			WithDetail("payment_id", paymentID.String()).
+			WithCause(err)
+	}
+
+	return payment, nil
+}
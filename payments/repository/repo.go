@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Abraxas-365/fuckturamelo/payments/dto"
+	"github.com/Abraxas-365/fuckturamelo/payments/models"
+	"github.com/google/uuid"
+)
+
+// PaymentRepository defines the interface for payment repository operations.
+// Allocations are checked against the payment amount and the outstanding
+// amount of each invoice while both are locked.
+type PaymentRepository interface {
+	// Basic CRUD operations
+	Create(ctx context.Context, payment *models.Payment) (*models.Payment, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error)
+
+	// Query operations
+	List(ctx context.Context, req *dto.PaymentListRequest) (*dto.PaymentListResponse, error)
+
+	// Allocation operations
+	Allocate(ctx context.Context, paymentID uuid.UUID, allocations []models.Allocation) (*models.Payment, error)
+	Unallocate(ctx context.Context, paymentID, invoiceID uuid.UUID) (*models.Payment, error)
+
+	// Lifecycle operations
+	Void(ctx context.Context, id, voidedBy uuid.UUID, reason *string) (*models.Payment, error)
+}
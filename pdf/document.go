@@ -0,0 +1,102 @@
+// Package pdf renders invoices as printable PDF documents. The caller maps
+// its invoice and parties into a Document and picks a Template; Render lays
+// them out with the core PDF fonts, so no font files are needed. Rendering
+// is deterministic: the same document and template always produce the same
+// bytes.
+package pdf
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Kind is the kind of document printed, which gives it its title
+type Kind string
+
+// Document kinds
+const (
+	KindInvoice    Kind = "invoice"
+	KindReceipt    Kind = "receipt"
+	KindCreditNote Kind = "credit_note"
+	KindDebitNote  Kind = "debit_note"
+)
+
+// Document is an invoice, receipt or note ready to be printed. Amounts are
+// taken as given; Render does not recompute them.
+type Document struct {
+	Kind Kind
+
+	// Electronic marks documents issued to the tax authority; their title
+	// says so and the QR code is captioned as their printed representation
+	Electronic bool
+
+	// Number is the series and correlative number, e.g. F001-123
+	Number    string
+	IssueDate time.Time
+	DueDate   *time.Time
+	Currency  string
+
+	Issuer   Party
+	Customer Party
+	Lines    []Line
+
+	// Taxes is the tax breakdown by code and rate
+	Taxes []Tax
+
+	// Subtotal excludes tax and is net of Discount, which is informative
+	Subtotal decimal.Decimal
+	Discount decimal.Decimal
+	TaxTotal decimal.Decimal
+	Total    decimal.Decimal
+
+	// AmountInWords spells the total, e.g. CIENTO DIECIOCHO CON 50/100 SOLES
+	AmountInWords string
+
+	// Reference is the document a credit or debit note adjusts
+	Reference *Reference
+
+	Notes []string
+
+	// QRCode is the content of the QR code; none is drawn when empty
+	QRCode string
+}
+
+// Party is the issuer or customer of a document
+type Party struct {
+	Name      string
+	TradeName string
+
+	// TaxIDLabel names the kind of TaxID, e.g. RUC or DNI
+	TaxIDLabel string
+	TaxID      string
+
+	// Address is printed one entry per line
+	Address []string
+}
+
+// Line is a line of a document. Amounts exclude tax; Amount is net of
+// Discount.
+type Line struct {
+	ItemCode    string
+	Description string
+	Quantity    decimal.Decimal
+	UnitCode    string
+	UnitPrice   decimal.Decimal
+	Discount    decimal.Decimal
+	Amount      decimal.Decimal
+}
+
+// Tax is the tax of the lines sharing a tax code and rate
+type Tax struct {
+	Code          string
+	Rate          decimal.Decimal
+	TaxableAmount decimal.Decimal
+	TaxAmount     decimal.Decimal
+}
+
+// Reference is the document a credit or debit note adjusts and why
+type Reference struct {
+	Number string
+	Reason string
+}
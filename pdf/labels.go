@@ -0,0 +1,181 @@
+package pdf
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// labels are the printed texts of a language
+type labels struct {
+	titles           map[Kind]string
+	electronicTitles map[Kind]string
+
+	customer      string
+	issueDate     string
+	dueDate       string
+	currency      string
+	reference     string
+	reason        string
+	code          string
+	description   string
+	quantity      string
+	unit          string
+	unitPrice     string
+	discount      string
+	amount        string
+	tax           string
+	rate          string
+	taxableAmount string
+	taxAmount     string
+	discounts     string
+	subtotal      string
+	total         string
+	inWords       string
+	notes         string
+
+	// printedRepresentation captions the QR code of electronic documents
+	printedRepresentation string
+
+	// page numbers the pages, e.g. Página 1 de 2
+	page string
+
+	dateLayout string
+}
+
+var languageLabels = map[string]labels{
+	"es": {
+		titles: map[Kind]string{
+			KindInvoice:    "FACTURA",
+			KindReceipt:    "BOLETA DE VENTA",
+			KindCreditNote: "NOTA DE CRÉDITO",
+			KindDebitNote:  "NOTA DE DÉBITO",
+		},
+		electronicTitles: map[Kind]string{
+			KindInvoice:    "FACTURA ELECTRÓNICA",
+			KindReceipt:    "BOLETA DE VENTA ELECTRÓNICA",
+			KindCreditNote: "NOTA DE CRÉDITO ELECTRÓNICA",
+			KindDebitNote:  "NOTA DE DÉBITO ELECTRÓNICA",
+		},
+		customer:              "CLIENTE",
+		issueDate:             "Fecha de emisión",
+		dueDate:               "Fecha de vencimiento",
+		currency:              "Moneda",
+		reference:             "Documento que modifica",
+		reason:                "Motivo",
+		code:                  "Código",
+		description:           "Descripción",
+		quantity:              "Cant.",
+		unit:                  "Unidad",
+		unitPrice:             "P. unitario",
+		discount:              "Descuento",
+		amount:                "Importe",
+		tax:                   "Tributo",
+		rate:                  "Tasa",
+		taxableAmount:         "Base imponible",
+		taxAmount:             "Monto",
+		discounts:             "Descuentos",
+		subtotal:              "Subtotal",
+		total:                 "TOTAL",
+		inWords:               "SON:",
+		notes:                 "Observaciones",
+		printedRepresentation: "Representación impresa de la %s",
+		page:                  "Página %d de {nb}",
+		dateLayout:            "02/01/2006",
+	},
+	"en": {
+		titles: map[Kind]string{
+			KindInvoice:    "INVOICE",
+			KindReceipt:    "SALES RECEIPT",
+			KindCreditNote: "CREDIT NOTE",
+			KindDebitNote:  "DEBIT NOTE",
+		},
+		electronicTitles: map[Kind]string{
+			KindInvoice:    "ELECTRONIC INVOICE",
+			KindReceipt:    "ELECTRONIC SALES RECEIPT",
+			KindCreditNote: "ELECTRONIC CREDIT NOTE",
+			KindDebitNote:  "ELECTRONIC DEBIT NOTE",
+		},
+		customer:              "BILL TO",
+		issueDate:             "Issue date",
+		dueDate:               "Due date",
+		currency:              "Currency",
+		reference:             "Adjusted document",
+		reason:                "Reason",
+		code:                  "Code",
+		description:           "Description",
+		quantity:              "Qty",
+		unit:                  "Unit",
+		unitPrice:             "Unit price",
+		discount:              "Discount",
+		amount:                "Amount",
+		tax:                   "Tax",
+		rate:                  "Rate",
+		taxableAmount:         "Taxable amount",
+		taxAmount:             "Tax amount",
+		discounts:             "Discounts",
+		subtotal:              "Subtotal",
+		total:                 "TOTAL",
+		inWords:               "AMOUNT IN WORDS:",
+		notes:                 "Notes",
+		printedRepresentation: "Printed representation of the %s",
+		page:                  "Page %d of {nb}",
+		dateLayout:            "2006-01-02",
+	},
+}
+
+// title returns the title of a document kind, invoices by default
+func (l labels) title(kind Kind, electronic bool) string {
+	titles := l.titles
+	if electronic {
+		titles = l.electronicTitles
+	}
+	if title, ok := titles[kind]; ok {
+		return title
+	}
+	return titles[KindInvoice]
+}
+
+func (l labels) date(t time.Time) string {
+	return t.Format(l.dateLayout)
+}
+
+// currencySymbols prefix the totals of common currencies
+var currencySymbols = map[string]string{
+	"PEN": "S/",
+	"USD": "US$",
+	"EUR": "€",
+}
+
+func currencySymbol(currency string) string {
+	if symbol, ok := currencySymbols[strings.ToUpper(currency)]; ok {
+		return symbol
+	}
+	return strings.ToUpper(currency)
+}
+
+// formatAmount prints an amount to cents with thousands separators, e.g.
+// 1,234.50
+func formatAmount(d decimal.Decimal) string {
+	s := d.StringFixed(2)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String() + "." + fraction
+}
+
+// formatRate prints a tax rate in percent, e.g. 18%
+func formatRate(d decimal.Decimal) string {
+	return d.String() + "%"
+}
@@ -0,0 +1,711 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/shopspring/decimal"
+	"github.com/skip2/go-qrcode"
+)
+
+// Page geometry, in millimetres
+const (
+	margin      = 15.0
+	footerSpace = 16.0
+	lineHeight  = 4.5
+	boxWidth    = 72.0
+	qrSize      = 28.0
+	logoHeight  = 20.0
+	logoWidth   = 42.0
+)
+
+// rgb is a color of the page
+type rgb struct{ r, g, b int }
+
+var (
+	black = rgb{0x22, 0x22, 0x22}
+	gray  = rgb{0x66, 0x66, 0x66}
+	rule  = rgb{0xCC, 0xCC, 0xCC}
+	white = rgb{0xFF, 0xFF, 0xFF}
+)
+
+// parseColor reads a #RRGGBB color already checked by Template.Validate
+func parseColor(hex string) rgb {
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+	return rgb{int(v >> 16 & 0xFF), int(v >> 8 & 0xFF), int(v & 0xFF)}
+}
+
+// tint mixes the color with white: 0 keeps it, 1 turns it white
+func (c rgb) tint(amount float64) rgb {
+	mix := func(v int) int { return v + int(float64(0xFF-v)*amount) }
+	return rgb{mix(c.r), mix(c.g), mix(c.b)}
+}
+
+// renderer lays out a document on the pages of a PDF
+type renderer struct {
+	f  *fpdf.Fpdf
+	d  *Document
+	t  Template
+	l  labels
+	tr func(string) string
+
+	primary, accent rgb
+	title           string
+
+	// Content area: left and right edges, width and lowest y content may
+	// reach before the footer
+	left, right, width, bottom float64
+}
+
+// Render lays out a document with a template and returns the PDF
+func Render(d *Document, t Template) ([]byte, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	f := fpdf.New("P", "mm", t.PageSize, "")
+	pageWidth, pageHeight := f.GetPageSize()
+	r := &renderer{
+		f:       f,
+		d:       d,
+		t:       t,
+		l:       languageLabels[t.Language],
+		tr:      f.UnicodeTranslatorFromDescriptor(""),
+		primary: parseColor(t.PrimaryColor),
+		accent:  parseColor(t.AccentColor),
+		left:    margin,
+		right:   pageWidth - margin,
+		width:   pageWidth - 2*margin,
+		bottom:  pageHeight - margin - footerSpace,
+	}
+	r.title = r.l.title(d.Kind, d.Electronic)
+
+	// Fixed dates and sorted catalogs keep the output reproducible
+	stamp := d.IssueDate
+	if stamp.IsZero() {
+		stamp = time.Unix(0, 0).UTC()
+	}
+	f.SetCreationDate(stamp)
+	f.SetModificationDate(stamp)
+	f.SetCatalogSort(true)
+
+	f.SetTitle(r.title+" "+d.Number, true)
+	f.SetAuthor(d.Issuer.Name, true)
+	f.SetMargins(margin, margin, margin)
+	f.SetAutoPageBreak(false, 0)
+	f.AliasNbPages("{nb}")
+	f.SetFooterFunc(r.footer)
+
+	if len(t.Logo) > 0 {
+		f.RegisterImageOptionsReader("logo", logoOptions(http.DetectContentType(t.Logo)), bytes.NewReader(t.Logo))
+	}
+
+	f.AddPage()
+	r.header()
+	r.parties()
+	r.lines()
+	if err := r.summary(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := f.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// header prints the issuer and the title and number of the document
+func (r *renderer) header() {
+	if r.t.Layout == LayoutBanner {
+		r.banner()
+		return
+	}
+
+	top := r.f.GetY()
+
+	// Tax ID, title and number boxed at the right
+	boxX := r.right - boxWidth
+	var rows []string
+	if r.d.Issuer.TaxID != "" {
+		rows = append(rows, taxIDLine(r.d.Issuer))
+	}
+	r.font("B", 11, r.primary)
+	titleLines := r.split(r.title, boxWidth)
+	boxHeight := float64(len(rows)+len(titleLines)+1)*6.5 + 6
+
+	r.setDraw(r.primary)
+	r.f.SetLineWidth(0.6)
+	r.f.Rect(boxX, top, boxWidth, boxHeight, "D")
+
+	y := top + 3
+	for _, row := range rows {
+		r.f.SetXY(boxX, y)
+		r.font("B", 11, black)
+		r.cell(boxWidth, 6.5, row, "C", false)
+		y += 6.5
+	}
+	r.font("B", 11, r.primary)
+	for _, line := range titleLines {
+		r.f.SetXY(boxX, y)
+		r.f.CellFormat(boxWidth, 6.5, string(line), "", 0, "C", false, 0, "")
+		y += 6.5
+	}
+	r.f.SetXY(boxX, y)
+	r.font("B", 12, black)
+	r.cell(boxWidth, 6.5, r.d.Number, "C", false)
+
+	// Logo and issuer at the left
+	x, width := r.left, boxX-6-r.left
+	bottom := top + boxHeight
+	if w, h := r.logo(x, top); w > 0 {
+		x, width = x+w+4, width-w-4
+		bottom = max(bottom, top+h)
+	}
+	bottom = max(bottom, r.issuer(x, top, width))
+
+	r.f.SetY(bottom + 6)
+}
+
+// banner prints the header on a band of the primary color
+func (r *renderer) banner() {
+	top := r.f.GetY()
+	pageWidth, _ := r.f.GetPageSize()
+	bandBottom := top + logoHeight + 4
+
+	r.setFill(r.primary)
+	r.f.Rect(0, 0, pageWidth, bandBottom, "F")
+
+	// Title, number and tax ID at the right
+	y := top
+	r.f.SetXY(r.right-boxWidth, y)
+	r.font("B", 12, white)
+	r.cell(boxWidth, 6.5, r.title, "R", false)
+	y += 6.5
+	r.f.SetXY(r.right-boxWidth, y)
+	r.cell(boxWidth, 6.5, r.d.Number, "R", false)
+	y += 6.5
+	if r.d.Issuer.TaxID != "" {
+		r.f.SetXY(r.right-boxWidth, y)
+		r.font("", 9, white)
+		r.cell(boxWidth, 5, taxIDLine(r.d.Issuer), "R", false)
+	}
+
+	// Logo and issuer name at the left
+	x := r.left
+	if w, _ := r.logo(x, top); w > 0 {
+		x += w + 4
+	}
+	r.f.SetXY(x, top)
+	r.font("B", 14, white)
+	name := r.split(r.d.Issuer.Name, r.right-boxWidth-4-x)
+	if len(name) > 2 {
+		name = name[:2]
+	}
+	for _, line := range name {
+		r.f.SetX(x)
+		r.f.CellFormat(r.right-boxWidth-4-x, 7, string(line), "", 2, "L", false, 0, "")
+	}
+	if r.d.Issuer.TradeName != "" {
+		r.f.SetX(x)
+		r.font("", 9, white)
+		r.cell(r.right-boxWidth-4-x, 5, r.d.Issuer.TradeName, "L", false)
+	}
+
+	// Address below the band
+	y = bandBottom + 3
+	r.font("", 8, gray)
+	for _, line := range r.d.Issuer.Address {
+		for _, wrapped := range r.split(line, r.width) {
+			r.f.SetXY(r.left, y)
+			r.f.CellFormat(r.width, 4, string(wrapped), "", 0, "L", false, 0, "")
+			y += 4
+		}
+	}
+
+	r.f.SetY(y + 4)
+}
+
+// issuer prints the issuer's name, trade name and address in a column
+// and returns the y below them
+func (r *renderer) issuer(x, y, width float64) float64 {
+	r.font("B", 12, r.primary)
+	for _, line := range r.split(r.d.Issuer.Name, width) {
+		r.f.SetXY(x, y)
+		r.f.CellFormat(width, 6, string(line), "", 0, "L", false, 0, "")
+		y += 6
+	}
+	if r.d.Issuer.TradeName != "" {
+		r.font("", 9, black)
+		r.f.SetXY(x, y)
+		r.cell(width, 5, r.d.Issuer.TradeName, "L", false)
+		y += 5
+	}
+	r.font("", 8, gray)
+	for _, line := range r.d.Issuer.Address {
+		for _, wrapped := range r.split(line, width) {
+			r.f.SetXY(x, y)
+			r.f.CellFormat(width, 4, string(wrapped), "", 0, "L", false, 0, "")
+			y += 4
+		}
+	}
+	return y
+}
+
+// logo draws the template's logo with its top left corner at x, y and
+// returns its size; nothing is drawn without a logo
+func (r *renderer) logo(x, y float64) (float64, float64) {
+	if len(r.t.Logo) == 0 {
+		return 0, 0
+	}
+
+	info := r.f.GetImageInfo("logo")
+	ratio := info.Width() / info.Height()
+	w, h := logoHeight*ratio, logoHeight
+	if w > logoWidth {
+		w, h = logoWidth, logoWidth/ratio
+	}
+	r.f.ImageOptions("logo", x, y, w, h, false, fpdf.ImageOptions{}, 0, "")
+	return w, h
+}
+
+// parties prints the customer at the left and the dates, currency and
+// adjusted document at the right
+func (r *renderer) parties() {
+	top := r.f.GetY()
+	customerWidth := r.width * 0.56
+
+	r.f.SetXY(r.left, top)
+	r.font("B", 8, r.accent)
+	r.cell(customerWidth, 5, r.l.customer, "L", false)
+
+	y := top + 5
+	customer := r.d.Customer
+	r.font("B", 10, black)
+	for _, line := range r.split(customer.Name, customerWidth) {
+		r.f.SetXY(r.left, y)
+		r.f.CellFormat(customerWidth, 5, string(line), "", 0, "L", false, 0, "")
+		y += 5
+	}
+	if customer.TaxID != "" {
+		r.f.SetXY(r.left, y)
+		r.font("", 9, black)
+		r.cell(customerWidth, 5, taxIDLine(customer), "L", false)
+		y += 5
+	}
+	r.font("", 8, gray)
+	for _, line := range customer.Address {
+		for _, wrapped := range r.split(line, customerWidth) {
+			r.f.SetXY(r.left, y)
+			r.f.CellFormat(customerWidth, 4, string(wrapped), "", 0, "L", false, 0, "")
+			y += 4
+		}
+	}
+	bottom := y
+
+	// Details at the right
+	x := r.left + customerWidth + 6
+	labelWidth := 38.0
+	valueWidth := r.right - x - labelWidth
+	details := [][2]string{{r.l.issueDate, ""}}
+	if !r.d.IssueDate.IsZero() {
+		details[0][1] = r.l.date(r.d.IssueDate)
+	}
+	if r.d.DueDate != nil {
+		details = append(details, [2]string{r.l.dueDate, r.l.date(*r.d.DueDate)})
+	}
+	if r.d.Currency != "" {
+		details = append(details, [2]string{r.l.currency, r.d.Currency})
+	}
+	if ref := r.d.Reference; ref != nil {
+		details = append(details, [2]string{r.l.reference, ref.Number})
+		if ref.Reason != "" {
+			details = append(details, [2]string{r.l.reason, ref.Reason})
+		}
+	}
+
+	y = top
+	for _, detail := range details {
+		r.f.SetXY(x, y)
+		r.font("B", 8, gray)
+		r.cell(labelWidth, 5, detail[0], "L", false)
+		r.font("", 8, black)
+		values := r.split(detail[1], valueWidth)
+		if len(values) == 0 {
+			values = [][]byte{nil}
+		}
+		for _, value := range values {
+			r.f.SetXY(x+labelWidth, y)
+			r.f.CellFormat(valueWidth, 5, string(value), "", 0, "L", false, 0, "")
+			y += 5
+		}
+	}
+	bottom = max(bottom, y)
+
+	r.setDraw(rule)
+	r.f.SetLineWidth(0.2)
+	r.f.Line(r.left, bottom+3, r.right, bottom+3)
+	r.f.SetY(bottom + 7)
+}
+
+// column is a column of the line items table
+type column struct {
+	label string
+	width float64
+	align string
+
+	// value prints the column of a line; the description, which wraps,
+	// has none
+	value func(Line) string
+}
+
+func (r *renderer) columns() []column {
+	fixed := []column{
+		{label: r.l.quantity, width: 16, align: "R", value: func(l Line) string { return l.Quantity.String() }},
+		{label: r.l.unit, width: 14, align: "C", value: func(l Line) string { return l.UnitCode }},
+		{label: r.l.unitPrice, width: 24, align: "R", value: func(l Line) string { return formatAmount(l.UnitPrice) }},
+	}
+	// Discounts only take a column when some line has one
+	for _, line := range r.d.Lines {
+		if !line.Discount.IsZero() {
+			fixed = append(fixed, column{label: r.l.discount, width: 20, align: "R",
+				value: func(l Line) string { return formatAmount(l.Discount) }})
+			break
+		}
+	}
+	fixed = append(fixed, column{label: r.l.amount, width: 26, align: "R",
+		value: func(l Line) string { return formatAmount(l.Amount) }})
+
+	used := 0.0
+	for _, c := range fixed {
+		used += c.width
+	}
+
+	var columns []column
+	if r.t.ShowItemCodes {
+		columns = append(columns, column{label: r.l.code, width: 22, align: "L",
+			value: func(l Line) string { return l.ItemCode }})
+		used += 22
+	}
+	columns = append(columns, column{label: r.l.description, width: r.width - used, align: "L"})
+	return append(columns, fixed...)
+}
+
+// lines prints the line items table, repeating its header on every page
+// it spans
+func (r *renderer) lines() {
+	if len(r.d.Lines) == 0 {
+		return
+	}
+
+	columns := r.columns()
+	r.tableHeader(columns)
+
+	stripe := r.accent.tint(0.9)
+	for i, line := range r.d.Lines {
+		r.font("", 8, black)
+		var description [][]byte
+		for _, c := range columns {
+			if c.value == nil {
+				description = r.split(line.Description, c.width)
+			}
+		}
+		h := float64(max(1, len(description)))*lineHeight + 2
+
+		if r.f.GetY()+h > r.bottom {
+			r.f.AddPage()
+			r.tableHeader(columns)
+			r.font("", 8, black)
+		}
+
+		y := r.f.GetY()
+		if i%2 == 1 {
+			r.setFill(stripe)
+			r.f.Rect(r.left, y, r.width, h, "F")
+		}
+
+		x := r.left
+		for _, c := range columns {
+			if c.value == nil {
+				for j, text := range description {
+					r.f.SetXY(x, y+1+float64(j)*lineHeight)
+					r.f.CellFormat(c.width, lineHeight, string(text), "", 0, c.align, false, 0, "")
+				}
+			} else {
+				r.f.SetXY(x, y+1)
+				r.cell(c.width, lineHeight, c.value(line), c.align, false)
+			}
+			x += c.width
+		}
+		r.f.SetY(y + h)
+	}
+
+	r.setDraw(r.accent)
+	r.f.SetLineWidth(0.4)
+	r.f.Line(r.left, r.f.GetY(), r.right, r.f.GetY())
+	r.f.SetY(r.f.GetY() + 4)
+}
+
+func (r *renderer) tableHeader(columns []column) {
+	r.setFill(r.accent)
+	r.font("B", 8, white)
+	r.f.SetX(r.left)
+	for _, c := range columns {
+		r.cell(c.width, 7, c.label, c.align, true)
+	}
+	r.f.Ln(7)
+}
+
+// summary prints the tax breakdown, the totals, the amount in words, the
+// notes and the QR code
+func (r *renderer) summary() error {
+	// Rows above the grand total
+	var totals [][2]string
+	symbol := currencySymbol(r.d.Currency)
+	money := func(d decimal.Decimal) string {
+		if symbol == "" {
+			return formatAmount(d)
+		}
+		return symbol + " " + formatAmount(d)
+	}
+	if !r.d.Discount.IsZero() {
+		totals = append(totals, [2]string{r.l.discounts, money(r.d.Discount)})
+	}
+	totals = append(totals, [2]string{r.l.subtotal, money(r.d.Subtotal)})
+	for _, tax := range r.d.Taxes {
+		if tax.TaxAmount.IsZero() {
+			continue
+		}
+		totals = append(totals, [2]string{taxLabel(tax, r.l), money(tax.TaxAmount)})
+	}
+	if len(r.d.Taxes) == 0 && !r.d.TaxTotal.IsZero() {
+		totals = append(totals, [2]string{r.l.tax, money(r.d.TaxTotal)})
+	}
+
+	// Keep the totals and the tax breakdown together
+	height := max(float64(len(totals))*6+8, float64(len(r.d.Taxes)+1)*5)
+	if r.f.GetY()+height > r.bottom {
+		r.f.AddPage()
+	}
+	top := r.f.GetY()
+
+	// Totals at the right
+	x := r.right - boxWidth
+	y := top
+	for _, row := range totals {
+		r.f.SetXY(x, y)
+		r.font("", 9, black)
+		r.cell(boxWidth-34, 6, row[0], "L", false)
+		r.cell(34, 6, row[1], "R", false)
+		y += 6
+	}
+	r.f.SetXY(x, y+1)
+	r.setFill(r.primary)
+	r.font("B", 10, white)
+	r.cell(boxWidth-34, 8, r.l.total, "L", true)
+	r.cell(34, 8, money(r.d.Total), "R", true)
+	bottom := y + 9
+
+	// Tax breakdown at the left
+	if len(r.d.Taxes) > 0 {
+		width := r.width - boxWidth - 8
+		widths := []float64{width * 0.28, width * 0.16, width * 0.3, width * 0.26}
+		r.f.SetXY(r.left, top)
+		r.setFill(r.accent.tint(0.8))
+		r.font("B", 7.5, black)
+		for i, label := range []string{r.l.tax, r.l.rate, r.l.taxableAmount, r.l.taxAmount} {
+			align := "R"
+			if i == 0 {
+				align = "L"
+			}
+			r.cell(widths[i], 5, label, align, true)
+		}
+		y = top + 5
+		r.font("", 7.5, black)
+		for _, tax := range r.d.Taxes {
+			r.f.SetXY(r.left, y)
+			code := tax.Code
+			if code == "" {
+				code = "-"
+			}
+			r.cell(widths[0], 5, code, "L", false)
+			r.cell(widths[1], 5, formatRate(tax.Rate), "R", false)
+			r.cell(widths[2], 5, formatAmount(tax.TaxableAmount), "R", false)
+			r.cell(widths[3], 5, formatAmount(tax.TaxAmount), "R", false)
+			y += 5
+		}
+		bottom = max(bottom, y)
+	}
+	r.f.SetY(bottom + 5)
+
+	// Amount in words across the page
+	if r.d.AmountInWords != "" {
+		r.font("B", 8.5, black)
+		label := r.l.inWords + " "
+		labelWidth := r.f.GetStringWidth(r.tr(label)) + 2
+		words := r.split(r.d.AmountInWords, r.width-labelWidth)
+		if r.f.GetY()+float64(len(words))*5 > r.bottom {
+			r.f.AddPage()
+		}
+		y := r.f.GetY()
+		r.f.SetXY(r.left, y)
+		r.cell(labelWidth, 5, label, "L", false)
+		r.font("", 8.5, black)
+		for _, line := range words {
+			r.f.SetXY(r.left+labelWidth, y)
+			r.f.CellFormat(r.width-labelWidth, 5, string(line), "", 0, "L", false, 0, "")
+			y += 5
+		}
+		r.f.SetY(y + 3)
+	}
+
+	// Notes
+	if len(r.d.Notes) > 0 {
+		r.font("B", 8, gray)
+		r.block([]string{r.l.notes}, 5)
+		r.font("", 8, black)
+		r.block(r.d.Notes, 4.5)
+		r.f.SetY(r.f.GetY() + 3)
+	}
+
+	if r.d.QRCode == "" {
+		return nil
+	}
+	return r.qrCode()
+}
+
+// block prints paragraphs across the page, wrapping them and breaking
+// pages as needed
+func (r *renderer) block(paragraphs []string, h float64) {
+	for _, paragraph := range paragraphs {
+		for _, line := range r.split(paragraph, r.width) {
+			if r.f.GetY()+h > r.bottom {
+				r.f.AddPage()
+			}
+			r.f.SetX(r.left)
+			r.f.CellFormat(r.width, h, string(line), "", 1, "L", false, 0, "")
+		}
+	}
+}
+
+// qrCode draws the QR code at the left, captioned for electronic documents.
+// Modules are drawn as rectangles so the code stays sharp at any zoom.
+func (r *renderer) qrCode() error {
+	code, err := qrcode.New(r.d.QRCode, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("render QR code: %w", err)
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	if r.f.GetY()+qrSize > r.bottom {
+		r.f.AddPage()
+	}
+	x, y := r.left, r.f.GetY()
+	module := qrSize / float64(len(bitmap))
+
+	r.setFill(rgb{})
+	for row, cells := range bitmap {
+		// One rectangle per run of dark modules
+		for col := 0; col < len(cells); col++ {
+			if !cells[col] {
+				continue
+			}
+			start := col
+			for col < len(cells) && cells[col] {
+				col++
+			}
+			r.f.Rect(x+float64(start)*module, y+float64(row)*module, float64(col-start)*module, module, "F")
+		}
+	}
+
+	if r.d.Electronic {
+		r.font("", 8, gray)
+		r.f.SetXY(x+qrSize+4, y+qrSize/2-2.5)
+		r.cell(r.width-qrSize-4, 5, fmt.Sprintf(r.l.printedRepresentation, r.title), "L", false)
+	}
+	r.f.SetY(y + qrSize + 3)
+	return nil
+}
+
+// footer prints the template's footer text and the page number at the
+// bottom of every page
+func (r *renderer) footer() {
+	_, pageHeight := r.f.GetPageSize()
+	y := pageHeight - margin - footerSpace + 4
+
+	r.setDraw(r.accent)
+	r.f.SetLineWidth(0.3)
+	r.f.Line(r.left, y, r.right, y)
+
+	r.font("", 7.5, gray)
+	page := fmt.Sprintf(r.l.page, r.f.PageNo())
+	pageWidth := r.f.GetStringWidth(r.tr(page)) + 4
+
+	if r.t.FooterText != "" {
+		lines := r.split(r.t.FooterText, r.width-pageWidth-4)
+		if len(lines) > 3 {
+			lines = lines[:3]
+		}
+		for i, line := range lines {
+			r.f.SetXY(r.left, y+1.5+float64(i)*3.5)
+			r.f.CellFormat(r.width-pageWidth-4, 3.5, string(line), "", 0, "L", false, 0, "")
+		}
+	}
+
+	r.f.SetXY(r.right-pageWidth, y+1.5)
+	r.cell(pageWidth, 3.5, page, "R", false)
+}
+
+// Helper methods
+
+func (r *renderer) font(style string, size float64, c rgb) {
+	r.f.SetFont(r.t.FontFamily, style, size)
+	r.f.SetTextColor(c.r, c.g, c.b)
+}
+
+func (r *renderer) setFill(c rgb) {
+	r.f.SetFillColor(c.r, c.g, c.b)
+}
+
+func (r *renderer) setDraw(c rgb) {
+	r.f.SetDrawColor(c.r, c.g, c.b)
+}
+
+// cell prints UTF-8 text on a single line at the current position
+func (r *renderer) cell(w, h float64, text, align string, fill bool) {
+	r.f.CellFormat(w, h, r.tr(text), "", 0, align, fill, 0, "")
+}
+
+// split wraps UTF-8 text to width w in the current font. The lines are
+// already translated to the font's code page.
+func (r *renderer) split(text string, w float64) [][]byte {
+	if text == "" {
+		return nil
+	}
+	return r.f.SplitLines([]byte(r.tr(text)), w)
+}
+
+// taxIDLine prints a party's tax ID after its label, e.g. RUC 20123456789
+func taxIDLine(p Party) string {
+	if p.TaxIDLabel == "" {
+		return p.TaxID
+	}
+	return p.TaxIDLabel + " " + p.TaxID
+}
+
+// taxLabel names a tax in the totals, e.g. IGV 18%
+func taxLabel(tax Tax, l labels) string {
+	code := tax.Code
+	if code == "" {
+		code = l.tax
+	}
+	if tax.Rate.IsZero() {
+		return code
+	}
+	return code + " " + formatRate(tax.Rate)
+}
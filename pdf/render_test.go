@@ -0,0 +1,219 @@
+package pdf
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// update rewrites the golden files: go test ./pdf -update
+var update = flag.Bool("update", false, "rewrite the golden PDFs in testdata")
+
+func amount(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+// testDocument is an electronic invoice with every section filled. The issue
+// date fixes the creation date of the PDF.
+func testDocument() *Document {
+	due := time.Date(2023, 3, 3, 0, 0, 0, 0, time.UTC)
+	return &Document{
+		Kind:       KindInvoice,
+		Electronic: true,
+		Number:     "F001-00000123",
+		IssueDate:  time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		DueDate:    &due,
+		Currency:   "PEN",
+		Issuer: Party{
+			Name:       "ACME INGENIERÍA Y SERVICIOS S.A.C.",
+			TradeName:  "ACME",
+			TaxIDLabel: "RUC",
+			TaxID:      "20123456789",
+			Address:    []string{"Av. Arequipa 1234, Of. 501", "Miraflores - Lima - Lima"},
+		},
+		Customer: Party{
+			Name:       "Compañía Minera del Sur S.A.",
+			TaxIDLabel: "RUC",
+			TaxID:      "20987654321",
+			Address:    []string{"Calle Los Álamos 45, Arequipa"},
+		},
+		Lines: []Line{
+			{
+				ItemCode:    "SRV-01",
+				Description: "Servicio de consultoría en ingeniería de procesos, febrero 2023",
+				Quantity:    amount("1"),
+				UnitCode:    "ZZ",
+				UnitPrice:   amount("1000.00"),
+				Amount:      amount("1000.00"),
+			},
+			{
+				ItemCode:    "MAT-07",
+				Description: "Válvula de compuerta 4\"",
+				Quantity:    amount("3"),
+				UnitCode:    "NIU",
+				UnitPrice:   amount("150.00"),
+				Discount:    amount("45.00"),
+				Amount:      amount("405.00"),
+			},
+		},
+		Taxes: []Tax{
+			{Code: "IGV", Rate: amount("18"), TaxableAmount: amount("1405.00"), TaxAmount: amount("252.90")},
+		},
+		Subtotal:      amount("1405.00"),
+		Discount:      amount("45.00"),
+		TaxTotal:      amount("252.90"),
+		Total:         amount("1657.90"),
+		AmountInWords: "MIL SEISCIENTOS CINCUENTA Y SIETE CON 90/100 SOLES",
+		Notes:         []string{"Orden de compra OC-2023-0456"},
+		QRCode:        "20123456789|01|F001|00000123|252.90|1657.90|2023-02-01|6|20987654321|",
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	logo, err := os.ReadFile(filepath.Join("testdata", "logo.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		document func() *Document
+		template func() Template
+	}{
+		{
+			name:     "invoice",
+			document: testDocument,
+			template: DefaultTemplate,
+		},
+		{
+			name:     "invoice_banner",
+			document: testDocument,
+			template: func() Template {
+				t := DefaultTemplate()
+				t.Layout = LayoutBanner
+				t.PageSize = "Letter"
+				t.Language = "en"
+				t.PrimaryColor = "#7A1F1F"
+				t.AccentColor = "#B35C1E"
+				t.FontFamily = "times"
+				t.ShowItemCodes = true
+				t.Logo = logo
+				t.FooterText = "Representación impresa de la factura electrónica. Consulte su documento en sunat.gob.pe"
+				return t
+			},
+		},
+		{
+			name: "credit_note",
+			document: func() *Document {
+				d := testDocument()
+				d.Kind = KindCreditNote
+				d.Number = "FC01-00000007"
+				d.DueDate = nil
+				d.Reference = &Reference{Number: "F001-00000123", Reason: "Anulación de la operación"}
+				d.Notes = nil
+				return d
+			},
+			template: func() Template {
+				t := DefaultTemplate()
+				t.Logo = logo
+				return t
+			},
+		},
+		{
+			// The table breaks over pages and repeats its header
+			name: "receipt_pages",
+			document: func() *Document {
+				d := testDocument()
+				d.Kind = KindReceipt
+				d.Electronic = false
+				d.Number = "B001-00000042"
+				d.Customer = Party{Name: "Juan Pérez", TaxIDLabel: "DNI", TaxID: "12345678"}
+				d.Lines = nil
+				for i := 1; i <= 70; i++ {
+					d.Lines = append(d.Lines, Line{
+						Description: fmt.Sprintf("Artículo %d", i),
+						Quantity:    amount("1"),
+						UnitCode:    "NIU",
+						UnitPrice:   amount("10.00"),
+						Amount:      amount("10.00"),
+					})
+				}
+				d.Taxes = nil
+				d.Subtotal, d.Discount, d.TaxTotal, d.Total = amount("700.00"), decimal.Zero, decimal.Zero, amount("700.00")
+				d.AmountInWords = "SETECIENTOS CON 00/100 SOLES"
+				d.QRCode = ""
+				return d
+			},
+			template: DefaultTemplate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.document(), tt.template())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The same input renders the same bytes
+			again, err := Render(tt.document(), tt.template())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, again) {
+				t.Fatal("rendering twice gives different bytes")
+			}
+
+			golden := filepath.Join("testdata", tt.name+".pdf")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v; run go test ./pdf -update to create it", err)
+			}
+			if !bytes.Equal(got, want) {
+				out := filepath.Join(t.TempDir(), tt.name+".pdf")
+				os.WriteFile(out, got, 0o644)
+				t.Errorf("output differs from %s; got %s", golden, out)
+			}
+		})
+	}
+}
+
+func TestRenderCreationDate(t *testing.T) {
+	d := testDocument()
+	got, err := Render(d, DefaultTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(got, []byte("/CreationDate (D:20230201000000")) {
+		t.Error("creation date is not the issue date")
+	}
+
+	// Without an issue date the epoch is used, never the current time
+	d.IssueDate = time.Time{}
+	got, err = Render(d, DefaultTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(got, []byte("/CreationDate (D:19700101000000")) {
+		t.Error("creation date of an undated document is not the epoch")
+	}
+}
+
+func TestRenderInvalidTemplate(t *testing.T) {
+	template := DefaultTemplate()
+	template.PageSize = "A3"
+	if _, err := Render(testDocument(), template); err == nil {
+		t.Error("rendered with an unsupported page size")
+	}
+}
@@ -0,0 +1,135 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"regexp"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Layout is the arrangement of the header of a document
+type Layout string
+
+// Layouts
+const (
+	// LayoutClassic boxes the tax ID, title and number at the top right,
+	// as printed representations of Peruvian electronic invoices do
+	LayoutClassic Layout = "classic"
+
+	// LayoutBanner draws the issuer, title and number on a band of the
+	// primary color across the top of the page
+	LayoutBanner Layout = "banner"
+)
+
+// Layouts lists the supported layouts
+var Layouts = map[Layout]bool{LayoutClassic: true, LayoutBanner: true}
+
+// PageSizes lists the supported page sizes
+var PageSizes = map[string]bool{"A4": true, "Letter": true}
+
+// Fonts lists the core PDF font families documents can be set in
+var Fonts = map[string]bool{"helvetica": true, "times": true, "courier": true}
+
+// Languages lists the languages of the printed labels
+var Languages = map[string]bool{"es": true, "en": true}
+
+// Logo content types
+const (
+	LogoPNG  = "image/png"
+	LogoJPEG = "image/jpeg"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Template is the branding and arrangement documents are rendered with
+type Template struct {
+	Layout   Layout
+	PageSize string
+	Language string
+
+	// PrimaryColor and AccentColor are #RRGGBB colors. The primary color
+	// draws the title and total, the accent color the table headers.
+	PrimaryColor string
+	AccentColor  string
+
+	FontFamily    string
+	ShowItemCodes bool
+
+	// Logo is a PNG or JPEG image printed at the top left
+	Logo []byte
+
+	// FooterText is printed at the bottom of every page
+	FooterText string
+}
+
+// DefaultTemplate is the template of organizations that have not configured
+// their own
+func DefaultTemplate() Template {
+	return Template{
+		Layout:       LayoutClassic,
+		PageSize:     "A4",
+		Language:     "es",
+		PrimaryColor: "#1F3A5F",
+		AccentColor:  "#4A6FA5",
+		FontFamily:   "helvetica",
+	}
+}
+
+// Validate checks that the template can be rendered with
+func (t *Template) Validate() error {
+	switch {
+	case !Layouts[t.Layout]:
+		return fmt.Errorf("layout %q is not supported", t.Layout)
+	case !PageSizes[t.PageSize]:
+		return fmt.Errorf("page size %q is not supported", t.PageSize)
+	case !Languages[t.Language]:
+		return fmt.Errorf("language %q is not supported", t.Language)
+	case !Fonts[t.FontFamily]:
+		return fmt.Errorf("font family %q is not supported", t.FontFamily)
+	case !colorPattern.MatchString(t.PrimaryColor):
+		return fmt.Errorf("primary color %q is not a #RRGGBB color", t.PrimaryColor)
+	case !colorPattern.MatchString(t.AccentColor):
+		return fmt.Errorf("accent color %q is not a #RRGGBB color", t.AccentColor)
+	}
+	if len(t.Logo) > 0 {
+		if _, err := CheckLogo(t.Logo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckLogo checks that an image can be printed as a logo and returns its
+// content type
+func CheckLogo(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if contentType != LogoPNG && contentType != LogoJPEG {
+		return "", errors.New("logo must be a PNG or JPEG image")
+	}
+
+	// The PDF library panics on some truncated images, so the whole image
+	// is decoded first
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("logo cannot be printed: %w", err)
+	}
+
+	f := fpdf.New("P", "mm", "A4", "")
+	f.RegisterImageOptionsReader("logo", logoOptions(contentType), bytes.NewReader(data))
+	if err := f.Error(); err != nil {
+		return "", fmt.Errorf("logo cannot be printed: %w", err)
+	}
+	return contentType, nil
+}
+
+func logoOptions(contentType string) fpdf.ImageOptions {
+	if contentType == LogoJPEG {
+		return fpdf.ImageOptions{ImageType: "JPG"}
+	}
+	return fpdf.ImageOptions{ImageType: "PNG"}
+}
@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *Template)
+	}{
+		{"layout", func(t *Template) { t.Layout = "sidebar" }},
+		{"page size", func(t *Template) { t.PageSize = "A3" }},
+		{"language", func(t *Template) { t.Language = "pt" }},
+		{"font", func(t *Template) { t.FontFamily = "arial" }},
+		{"primary color", func(t *Template) { t.PrimaryColor = "navy" }},
+		{"accent color", func(t *Template) { t.AccentColor = "#12345" }},
+		{"logo", func(t *Template) { t.Logo = []byte("GIF89a") }},
+	}
+
+	template := DefaultTemplate()
+	if err := template.Validate(); err != nil {
+		t.Fatalf("default template: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := DefaultTemplate()
+			tt.change(&template)
+			if err := template.Validate(); err == nil {
+				t.Error("invalid template accepted")
+			}
+		})
+	}
+}
+
+func TestCheckLogo(t *testing.T) {
+	logo, err := os.ReadFile(filepath.Join("testdata", "logo.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType, err := CheckLogo(logo); err != nil || contentType != LogoPNG {
+		t.Errorf("got %q, %v; want %s", contentType, err, LogoPNG)
+	}
+
+	// A PNG signature over garbage cannot be printed
+	if _, err := CheckLogo(append(logo[:16:16], "garbage"...)); err == nil {
+		t.Error("truncated PNG accepted")
+	}
+}
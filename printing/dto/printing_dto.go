@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/pdf"
+)
+
+// SaveTemplateRequest creates or replaces the PDF template of an
+// organization; empty fields take the defaults. The logo is kept.
+type SaveTemplateRequest struct {
+	Layout        pdf.Layout `json:"layout,omitempty"`
+	PageSize      string     `json:"page_size,omitempty"`
+	Language      string     `json:"language,omitempty"`
+	PrimaryColor  string     `json:"primary_color,omitempty"`
+	AccentColor   string     `json:"accent_color,omitempty"`
+	FontFamily    string     `json:"font_family,omitempty"`
+	ShowItemCodes bool       `json:"show_item_codes"`
+	FooterText    *string    `json:"footer_text,omitempty"`
+	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty"`
+}
+
+// UploadLogoRequest carries the PNG or JPEG logo of an organization
+type UploadLogoRequest struct {
+	Data      []byte     `json:"-"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+}
+
+// Document is a rendered PDF
+type Document struct {
+	// FileName is named after the invoice, e.g. F001-123.pdf
+	FileName string
+	Content  []byte
+}
+
+// Logo is the logo of a template as uploaded
+type Logo struct {
+	ContentType string
+	Content     []byte
+}
@@ -0,0 +1,64 @@
+package printing
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// PrintingErrors is the error registry for printing domain
+var PrintingErrors = errx.NewRegistry("PRINTING")
+
+// Printing error codes
+var (
+	// Template errors
+	ErrTemplateNotFound = PrintingErrors.Register(
+		"TEMPLATE_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Organization has no PDF template",
+	)
+
+	ErrInvalidLogo = PrintingErrors.Register(
+		"INVALID_LOGO",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Logo cannot be printed",
+	)
+
+	ErrTemplateSaveFailed = PrintingErrors.Register(
+		"TEMPLATE_SAVE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save PDF template",
+	)
+
+	// Rendering errors
+	ErrRenderFailed = PrintingErrors.Register(
+		"RENDER_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to render PDF",
+	)
+
+	// Query errors
+	ErrPrintingLoadFailed = PrintingErrors.Register(
+		"LOAD_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to load printing data",
+	)
+
+	// Validation errors
+	ErrPrintingValidationFailed = PrintingErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Printing validation failed",
+	)
+)
+
+// Helper functions for error checking
+func IsTemplateNotFound(err error) bool {
+	return errx.IsCode(err, ErrTemplateNotFound)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/pdf"
+)
+
+// Template is the branding an organization's invoices are printed with. The
+// logo is served on its own and never returned with the template.
+type Template struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+
+	// Arrangement
+	Layout   pdf.Layout `db:"layout" json:"layout"`
+	PageSize string     `db:"page_size" json:"page_size"`
+	Language string     `db:"language" json:"language"`
+
+	// Branding
+	PrimaryColor    string  `db:"primary_color" json:"primary_color"`
+	AccentColor     string  `db:"accent_color" json:"accent_color"`
+	FontFamily      string  `db:"font_family" json:"font_family"`
+	ShowItemCodes   bool    `db:"show_item_codes" json:"show_item_codes"`
+	FooterText      *string `db:"footer_text" json:"footer_text,omitempty"`
+	Logo            []byte  `db:"logo" json:"-"`
+	LogoContentType *string `db:"logo_content_type" json:"logo_content_type,omitempty"`
+
+	UpdatedBy *uuid.UUID `db:"updated_by" json:"updated_by,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the Template model
+func (t Template) TableName() string {
+	return "organization_pdf_templates"
+}
+
+// DefaultTemplate is the template of an organization that has not saved
+// its own
+func DefaultTemplate(orgID uuid.UUID) *Template {
+	defaults := pdf.DefaultTemplate()
+	return &Template{
+		OrganizationID: orgID,
+		Layout:         defaults.Layout,
+		PageSize:       defaults.PageSize,
+		Language:       defaults.Language,
+		PrimaryColor:   defaults.PrimaryColor,
+		AccentColor:    defaults.AccentColor,
+		FontFamily:     defaults.FontFamily,
+		ShowItemCodes:  defaults.ShowItemCodes,
+	}
+}
+
+// HasLogo reports whether the template has a logo
+func (t *Template) HasLogo() bool {
+	return len(t.Logo) > 0
+}
+
+// PDF returns the template as the renderer takes it
+func (t *Template) PDF() pdf.Template {
+	template := pdf.Template{
+		Layout:        t.Layout,
+		PageSize:      t.PageSize,
+		Language:      t.Language,
+		PrimaryColor:  t.PrimaryColor,
+		AccentColor:   t.AccentColor,
+		FontFamily:    t.FontFamily,
+		ShowItemCodes: t.ShowItemCodes,
+		Logo:          t.Logo,
+	}
+	if t.FooterText != nil {
+		template.FooterText = *t.FooterText
+	}
+	return template
+}
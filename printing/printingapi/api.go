@@ -0,0 +1,266 @@
+package printingapi
+
+import (
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	einvoicepg "github.com/Abraxas-365/fuckturamelo/einvoice/repository"
+	invoicespg "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	"github.com/Abraxas-365/fuckturamelo/printing"
+	"github.com/Abraxas-365/fuckturamelo/printing/dto"
+	"github.com/Abraxas-365/fuckturamelo/printing/printingsrv"
+	postgres "github.com/Abraxas-365/fuckturamelo/printing/repository"
+	providerspg "github.com/Abraxas-365/fuckturamelo/providers/repository"
+)
+
+// PrintingAPI contains the complete API setup for the printing domain
+type PrintingAPI struct {
+	service printingsrv.PrintingService
+	repo    postgres.PrintingRepository
+}
+
+// Config contains configuration for the printing API
+type Config struct {
+	DB *sqlx.DB
+}
+
+// New creates a new PrintingAPI instance
+func New(config Config) (*PrintingAPI, error) {
+	if config.DB == nil {
+		return nil, printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewPrintingRepository(config.DB)
+	svc := printingsrv.NewPrintingService(repo,
+		invoicespg.NewInvoiceRepository(config.DB),
+		providerspg.NewProviderRepository(config.DB),
+		einvoicepg.NewEInvoiceRepository(config.DB))
+
+	return &PrintingAPI{
+		service: svc,
+		repo:    repo,
+	}, nil
+}
+
+// SetupInvoiceRoutes registers the PDF route of an invoice with the
+// invoices router group
+func (api *PrintingAPI) SetupInvoiceRoutes(router fiber.Router) {
+	router.Get("/:id/pdf", api.getPDF)
+}
+
+// SetupOrganizationRoutes registers the PDF template routes with the given
+// Fiber router group, which is expected to carry the :orgId parameter
+func (api *PrintingAPI) SetupOrganizationRoutes(router fiber.Router) {
+	// Template routes
+	router.Get("/pdf-template", api.getTemplate)
+	router.Put("/pdf-template", api.saveTemplate)
+	router.Delete("/pdf-template", api.deleteTemplate)
+
+	// Logo routes
+	router.Get("/pdf-template/logo", api.getLogo)
+	router.Put("/pdf-template/logo", api.uploadLogo)
+	router.Delete("/pdf-template/logo", api.deleteLogo)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *PrintingAPI) GetService() printingsrv.PrintingService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *PrintingAPI) GetRepository() postgres.PrintingRepository {
+	return api.repo
+}
+
+// getPDF handles GET /invoices/:id/pdf?download=true; the PDF is shown
+// inline unless downloaded
+func (api *PrintingAPI) getPDF(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	document, err := api.service.RenderInvoice(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	disposition := "inline"
+	if c.QueryBool("download") {
+		disposition = "attachment"
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, disposition+`; filename="`+document.FileName+`"`)
+	return c.Status(fiber.StatusOK).Send(document.Content)
+}
+
+// getTemplate handles GET /organizations/:orgId/pdf-template
+func (api *PrintingAPI) getTemplate(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetTemplate(c.Context(), orgID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// saveTemplate handles PUT /organizations/:orgId/pdf-template
+func (api *PrintingAPI) saveTemplate(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.SaveTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.SaveTemplate(c.Context(), orgID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteTemplate handles DELETE /organizations/:orgId/pdf-template
+func (api *PrintingAPI) deleteTemplate(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteTemplate(c.Context(), orgID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// getLogo handles GET /organizations/:orgId/pdf-template/logo
+func (api *PrintingAPI) getLogo(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	logo, err := api.service.GetLogo(c.Context(), orgID)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, logo.ContentType)
+	return c.Status(fiber.StatusOK).Send(logo.Content)
+}
+
+// uploadLogo handles PUT /organizations/:orgId/pdf-template/logo as a
+// multipart upload with a "file" part holding the image and an optional
+// updated_by field
+func (api *PrintingAPI) uploadLogo(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.UploadLogoRequest
+	if value := c.FormValue("updated_by"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+				WithDetail("error", "Invalid updated_by format").
+				WithCause(err)
+		}
+		req.UpdatedBy = &id
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", "Missing logo in multipart field: file").
+			WithCause(err)
+	}
+	if header.Size > printingsrv.MaxLogoSize {
+		return printing.PrintingErrors.New(printing.ErrInvalidLogo).
+			WithDetail("reason", "logo is too large").
+			WithDetail("max_bytes", printingsrv.MaxLogoSize)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", "Logo could not be read").
+			WithCause(err)
+	}
+	defer file.Close()
+
+	if req.Data, err = io.ReadAll(file); err != nil {
+		return printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", "Logo could not be read").
+			WithCause(err)
+	}
+
+	result, err := api.service.UploadLogo(c.Context(), orgID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteLogo handles DELETE /organizations/:orgId/pdf-template/logo
+func (api *PrintingAPI) deleteLogo(c *fiber.Ctx) error {
+	orgID, err := api.parseUUIDParam(c, "orgId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.DeleteLogo(c.Context(), orgID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Helper methods
+
+func (api *PrintingAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
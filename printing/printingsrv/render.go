@@ -0,0 +1,326 @@
+package printingsrv
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/einvoice"
+	"github.com/Abraxas-365/fuckturamelo/einvoice/einvoicesrv"
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	typemodels "github.com/Abraxas-365/fuckturamelo/invoicetypes/models"
+	"github.com/Abraxas-365/fuckturamelo/pdf"
+	"github.com/Abraxas-365/fuckturamelo/printing"
+	"github.com/Abraxas-365/fuckturamelo/printing/dto"
+	providermodels "github.com/Abraxas-365/fuckturamelo/providers/models"
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+)
+
+// FieldNotes is free text in invoice_data printed below the totals
+const FieldNotes = "notes"
+
+// unsafeFileName matches the characters replaced in PDF file names
+var unsafeFileName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RenderInvoice maps an invoice onto a printable document and renders it
+// with the organization's template
+func (s *printingService) RenderInvoice(ctx context.Context, invoiceID uuid.UUID) (*dto.Document, error) {
+	invoice, err := s.getInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.GetTemplate(ctx, invoice.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(ctx, invoice, template.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := pdf.Render(doc, template.PDF())
+	if err != nil {
+		return nil, printing.PrintingErrors.New(printing.ErrRenderFailed).
+			WithDetail("invoice_id", invoiceID.String()).
+			WithCause(err)
+	}
+
+	name := invoice.ID.String()
+	if invoice.InvoiceNumber != nil && *invoice.InvoiceNumber != "" {
+		name = unsafeFileName.ReplaceAllString(*invoice.InvoiceNumber, "_")
+	}
+	return &dto.Document{
+		FileName: name + ".pdf",
+		Content:  content,
+	}, nil
+}
+
+// document maps an invoice onto a printable document. The organization
+// issues its invoices to their provider, except for invoices imported from
+// documents the provider issued.
+func (s *printingService) document(ctx context.Context, invoice *invoicemodels.Invoice, language string) (*pdf.Document, error) {
+	doc := &pdf.Document{
+		Number:   stringValue(invoice.InvoiceNumber),
+		DueDate:  invoice.DueDate,
+		Currency: stringValue(invoice.CurrencyCode),
+	}
+	if invoice.InvoiceDate != nil {
+		doc.IssueDate = *invoice.InvoiceDate
+	}
+
+	organization, organizationType, err := s.organizationParty(ctx, invoice.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	var provider pdf.Party
+	var providerType string
+	if invoice.ProviderID != nil {
+		p, err := s.providers.GetByID(ctx, *invoice.ProviderID)
+		if err != nil {
+			return nil, err
+		}
+		provider, providerType = providerParty(p)
+	}
+
+	imported := true
+	if _, err := s.einvoices.GetImportByInvoice(ctx, invoice.ID); err != nil {
+		if !einvoice.IsImportNotFound(err) {
+			return nil, err
+		}
+		imported = false
+	}
+	doc.Issuer, doc.Customer = organization, provider
+	customerType := providerType
+	if imported {
+		doc.Issuer, doc.Customer, customerType = provider, organization, organizationType
+	}
+
+	// Imported documents and those SUNAT took are electronic documents
+	if status := stringValue(invoice.TaxAuthorityStatus); imported || status == "accepted" || status == "observed" {
+		doc.Electronic = true
+	}
+
+	doc.Kind = documentKind(invoice, doc.Electronic)
+	if invoice.DocumentKind.IsAdjustment() {
+		if doc.Reference, err = s.reference(ctx, invoice); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range invoice.LineItems {
+		line := pdf.Line{
+			ItemCode:    stringValue(item.ItemCode),
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitCode:    stringValue(item.UnitCode),
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.GrossAmount.Sub(item.NetAmount),
+			Amount:      item.NetAmount,
+		}
+		doc.Lines = append(doc.Lines, line)
+	}
+	addAmounts(doc, invoice)
+	doc.AmountInWords = amountInWords(doc.Total, doc.Currency, language)
+
+	if notes, ok := invoice.InvoiceData[FieldNotes].(string); ok && strings.TrimSpace(notes) != "" {
+		doc.Notes = strings.Split(strings.TrimSpace(notes), "\n")
+	}
+	doc.QRCode = qrCode(doc, invoice, customerType)
+
+	return doc, nil
+}
+
+// addAmounts copies the stored amounts of an invoice. Invoices without line
+// items only have a total.
+func addAmounts(doc *pdf.Document, invoice *invoicemodels.Invoice) {
+	if invoice.TotalAmount != nil {
+		doc.Total = decimal.NewFromFloat(*invoice.TotalAmount).Round(2)
+	}
+	doc.Subtotal = doc.Total
+	if invoice.SubtotalAmount != nil {
+		doc.Subtotal = *invoice.SubtotalAmount
+	}
+	if invoice.DiscountAmount != nil {
+		doc.Discount = *invoice.DiscountAmount
+	}
+	if invoice.TaxAmount != nil {
+		doc.TaxTotal = *invoice.TaxAmount
+	}
+	if invoice.TotalAmount == nil {
+		doc.Total = doc.Subtotal.Add(doc.TaxTotal)
+	}
+
+	for _, tax := range invoice.TaxBreakdown {
+		doc.Taxes = append(doc.Taxes, pdf.Tax{
+			Code:          tax.TaxCode,
+			Rate:          tax.TaxRate,
+			TaxableAmount: tax.TaxableAmount,
+			TaxAmount:     tax.TaxAmount,
+		})
+	}
+}
+
+// reference describes the invoice a note adjusts and why
+func (s *printingService) reference(ctx context.Context, invoice *invoicemodels.Invoice) (*pdf.Reference, error) {
+	reference := &pdf.Reference{Reason: stringValue(invoice.AdjustmentReason)}
+	if reference.Reason == "" && invoice.AdjustmentReasonCode != nil {
+		reasons := ubl.CreditNoteReasons
+		if invoice.DocumentKind == typemodels.KindDebitNote {
+			reasons = ubl.DebitNoteReasons
+		}
+		reference.Reason = reasons[*invoice.AdjustmentReasonCode]
+	}
+
+	if invoice.OriginalInvoiceID == nil {
+		return reference, nil
+	}
+	original, err := s.invoices.GetByID(ctx, *invoice.OriginalInvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	reference.Number = stringValue(original.InvoiceNumber)
+	return reference, nil
+}
+
+// organizationParty describes an organization by its tax profile, or by its
+// name if it has none, and returns the catalog 06 type of its tax ID
+func (s *printingService) organizationParty(ctx context.Context, orgID uuid.UUID) (pdf.Party, string, error) {
+	profile, err := s.einvoices.GetTaxProfile(ctx, orgID)
+	if err != nil {
+		if !einvoice.IsTaxProfileNotFound(err) {
+			return pdf.Party{}, "", err
+		}
+		name, err := s.repo.GetOrganizationName(ctx, orgID)
+		if err != nil {
+			return pdf.Party{}, "", err
+		}
+		return pdf.Party{Name: name}, "", nil
+	}
+
+	party := pdf.Party{
+		Name:       profile.LegalName,
+		TradeName:  stringValue(profile.TradeName),
+		TaxIDLabel: identityLabel(profile.TaxIDType),
+		TaxID:      profile.TaxID,
+	}
+	if profile.AddressLine != nil {
+		party.Address = append(party.Address, *profile.AddressLine)
+	}
+	var places []string
+	for _, place := range []*string{profile.District, profile.Province, profile.Department} {
+		if place != nil && *place != "" {
+			places = append(places, *place)
+		}
+	}
+	if len(places) > 0 {
+		party.Address = append(party.Address, strings.Join(places, " - "))
+	}
+	return party, profile.TaxIDType, nil
+}
+
+// Helper methods
+
+func (s *printingService) getInvoice(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error) {
+	invoice, err := s.invoices.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.IsDeleted {
+		return nil, invoices.InvoicesErrors.New(invoices.ErrInvoiceNotFound).
+			WithDetail("invoice_id", id.String())
+	}
+	return invoice, nil
+}
+
+// providerParty describes a provider and returns the catalog 06 type of its
+// tax ID. The type and address may be given in the provider's metadata as
+// for electronic invoices.
+func providerParty(provider *providermodels.Provider) (pdf.Party, string) {
+	party := pdf.Party{
+		Name:  provider.Name,
+		TaxID: stringValue(provider.TaxID),
+	}
+	if address, ok := provider.Metadata[einvoicesrv.MetadataAddress].(string); ok && address != "" {
+		party.Address = []string{address}
+	}
+	if party.TaxID == "" {
+		return party, ""
+	}
+
+	identityType, ok := provider.Metadata[einvoicesrv.MetadataTaxIDType].(string)
+	if !ok || identityType == "" {
+		identityType = ubl.IdentityType(party.TaxID)
+	}
+	party.TaxIDLabel = identityLabel(identityType)
+	return party, identityType
+}
+
+// identityLabel names a catalog 06 identity type; other documents print
+// without a label
+func identityLabel(identityType string) string {
+	if identityType == ubl.IdentityOther {
+		return ""
+	}
+	return ubl.IdentityTypes[identityType]
+}
+
+// documentKind titles notes as such and electronic documents whose series
+// start with B as boletas
+func documentKind(invoice *invoicemodels.Invoice, electronic bool) pdf.Kind {
+	switch {
+	case invoice.DocumentKind == typemodels.KindCreditNote:
+		return pdf.KindCreditNote
+	case invoice.DocumentKind == typemodels.KindDebitNote:
+		return pdf.KindDebitNote
+	case electronic && strings.HasPrefix(stringValue(invoice.InvoiceNumber), "B"):
+		return pdf.KindReceipt
+	}
+	return pdf.KindInvoice
+}
+
+// qrCode returns the QR code content SUNAT requires on printed
+// representations: issuer RUC, document type, series, number, IGV, total,
+// issue date and customer identity type and number, separated by pipes
+func qrCode(doc *pdf.Document, invoice *invoicemodels.Invoice, customerType string) string {
+	documentType := ubl.DocumentFactura
+	switch doc.Kind {
+	case pdf.KindReceipt:
+		documentType = ubl.DocumentBoleta
+	case pdf.KindCreditNote:
+		documentType = ubl.DocumentCreditNote
+	case pdf.KindDebitNote:
+		documentType = ubl.DocumentDebitNote
+	}
+	series, number, _ := strings.Cut(doc.Number, "-")
+
+	igv := decimal.Zero
+	for _, tax := range invoice.TaxBreakdown {
+		if category, ok := ubl.ParseTaxCategory(tax.TaxCode, tax.TaxRate); ok && category == ubl.TaxIGV {
+			igv = igv.Add(tax.TaxAmount)
+		}
+	}
+
+	issueDate := ""
+	if !doc.IssueDate.IsZero() {
+		issueDate = doc.IssueDate.Format(invoicemodels.DateLayout)
+	}
+	fields := []string{
+		doc.Issuer.TaxID, documentType, series, number,
+		igv.StringFixed(2), doc.Total.StringFixed(2), issueDate,
+		customerType, doc.Customer.TaxID,
+	}
+	return strings.Join(fields, "|") + "|"
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,170 @@
+package printingsrv
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	einvoicemodels "github.com/Abraxas-365/fuckturamelo/einvoice/models"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/pdf"
+	"github.com/Abraxas-365/fuckturamelo/printing"
+	"github.com/Abraxas-365/fuckturamelo/printing/dto"
+	"github.com/Abraxas-365/fuckturamelo/printing/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/printing/repository"
+	providermodels "github.com/Abraxas-365/fuckturamelo/providers/models"
+)
+
+// MaxLogoSize bounds uploaded logos
+const MaxLogoSize = 512 << 10
+
+// PrintingService defines the interface for printing business logic
+type PrintingService interface {
+	// GetTemplate returns the organization's template, or the default
+	// template if it has not saved one
+	GetTemplate(ctx context.Context, orgID uuid.UUID) (*models.Template, error)
+	SaveTemplate(ctx context.Context, orgID uuid.UUID, req *dto.SaveTemplateRequest) (*models.Template, error)
+	DeleteTemplate(ctx context.Context, orgID uuid.UUID) error
+
+	GetLogo(ctx context.Context, orgID uuid.UUID) (*dto.Logo, error)
+	UploadLogo(ctx context.Context, orgID uuid.UUID, req *dto.UploadLogoRequest) (*models.Template, error)
+	DeleteLogo(ctx context.Context, orgID uuid.UUID) (*models.Template, error)
+
+	// RenderInvoice prints an invoice as a PDF with its organization's
+	// template
+	RenderInvoice(ctx context.Context, invoiceID uuid.UUID) (*dto.Document, error)
+}
+
+// Invoices reads the invoices being printed (implemented by
+// invoices/repository.InvoiceRepository)
+type Invoices interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error)
+}
+
+// Providers reads the providers of printed invoices (implemented by
+// providers/repository.ProviderRepository)
+type Providers interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*providermodels.Provider, error)
+}
+
+// EInvoices reads the tax identity of organizations and tells imported
+// invoices apart (implemented by einvoice/repository.EInvoiceRepository)
+type EInvoices interface {
+	GetTaxProfile(ctx context.Context, orgID uuid.UUID) (*einvoicemodels.TaxProfile, error)
+	GetImportByInvoice(ctx context.Context, invoiceID uuid.UUID) (*einvoicemodels.Import, error)
+}
+
+// printingService implements PrintingService
+type printingService struct {
+	repo      postgres.PrintingRepository
+	invoices  Invoices
+	providers Providers
+	einvoices EInvoices
+}
+
+// NewPrintingService creates a new printing service
+func NewPrintingService(repo postgres.PrintingRepository, invoices Invoices, providers Providers, einvoices EInvoices) PrintingService {
+	return &printingService{
+		repo:      repo,
+		invoices:  invoices,
+		providers: providers,
+		einvoices: einvoices,
+	}
+}
+
+// GetTemplate returns the saved template or the default one
+func (s *printingService) GetTemplate(ctx context.Context, orgID uuid.UUID) (*models.Template, error) {
+	template, err := s.repo.GetTemplate(ctx, orgID)
+	if printing.IsTemplateNotFound(err) {
+		return models.DefaultTemplate(orgID), nil
+	}
+	return template, err
+}
+
+// SaveTemplate validates and stores the template of an organization
+func (s *printingService) SaveTemplate(ctx context.Context, orgID uuid.UUID, req *dto.SaveTemplateRequest) (*models.Template, error) {
+	template := models.DefaultTemplate(orgID)
+	if req.Layout != "" {
+		template.Layout = req.Layout
+	}
+	if req.PageSize != "" {
+		template.PageSize = req.PageSize
+	}
+	if req.Language != "" {
+		template.Language = strings.ToLower(req.Language)
+	}
+	if req.PrimaryColor != "" {
+		template.PrimaryColor = strings.ToUpper(req.PrimaryColor)
+	}
+	if req.AccentColor != "" {
+		template.AccentColor = strings.ToUpper(req.AccentColor)
+	}
+	if req.FontFamily != "" {
+		template.FontFamily = strings.ToLower(req.FontFamily)
+	}
+	template.ShowItemCodes = req.ShowItemCodes
+	if req.FooterText != nil && strings.TrimSpace(*req.FooterText) != "" {
+		footer := strings.TrimSpace(*req.FooterText)
+		template.FooterText = &footer
+	}
+	template.UpdatedBy = req.UpdatedBy
+
+	settings := template.PDF()
+	if err := settings.Validate(); err != nil {
+		return nil, printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("error", err.Error())
+	}
+
+	return s.repo.SaveTemplate(ctx, template)
+}
+
+// DeleteTemplate reverts an organization to the default template
+func (s *printingService) DeleteTemplate(ctx context.Context, orgID uuid.UUID) error {
+	return s.repo.DeleteTemplate(ctx, orgID)
+}
+
+// GetLogo returns the logo of an organization's template
+func (s *printingService) GetLogo(ctx context.Context, orgID uuid.UUID) (*dto.Logo, error) {
+	template, err := s.repo.GetTemplate(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !template.HasLogo() || template.LogoContentType == nil {
+		return nil, printing.PrintingErrors.New(printing.ErrTemplateNotFound).
+			WithDetail("organization_id", orgID.String()).
+			WithDetail("reason", "template has no logo")
+	}
+
+	return &dto.Logo{
+		ContentType: *template.LogoContentType,
+		Content:     template.Logo,
+	}, nil
+}
+
+// UploadLogo checks that an image can be printed and sets it as the logo
+// of the organization's template
+func (s *printingService) UploadLogo(ctx context.Context, orgID uuid.UUID, req *dto.UploadLogoRequest) (*models.Template, error) {
+	if len(req.Data) == 0 {
+		return nil, printing.PrintingErrors.New(printing.ErrInvalidLogo).
+			WithDetail("reason", "logo is empty")
+	}
+	if len(req.Data) > MaxLogoSize {
+		return nil, printing.PrintingErrors.New(printing.ErrInvalidLogo).
+			WithDetail("reason", "logo is too large").
+			WithDetail("max_bytes", MaxLogoSize)
+	}
+
+	contentType, err := pdf.CheckLogo(req.Data)
+	if err != nil {
+		return nil, printing.PrintingErrors.New(printing.ErrInvalidLogo).
+			WithDetail("reason", err.Error())
+	}
+
+	return s.repo.SaveLogo(ctx, orgID, req.Data, contentType, req.UpdatedBy)
+}
+
+// DeleteLogo removes the logo of an organization's template
+func (s *printingService) DeleteLogo(ctx context.Context, orgID uuid.UUID) (*models.Template, error) {
+	return s.repo.DeleteLogo(ctx, orgID)
+}
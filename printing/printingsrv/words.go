@@ -0,0 +1,76 @@
+package printingsrv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+)
+
+// englishCurrencyNames are the plural English names of common currencies
+var englishCurrencyNames = map[string]string{
+	"PEN": "SOLES",
+	"USD": "US DOLLARS",
+	"EUR": "EUROS",
+}
+
+// amountInWords spells the total of a document in the template's language:
+// the SUNAT legend in Spanish, e.g. CIENTO DIECIOCHO CON 50/100 SOLES, and
+// its English counterpart
+func amountInWords(amount decimal.Decimal, currency, language string) string {
+	if language != "en" {
+		return ubl.AmountInWords(amount, currency)
+	}
+
+	rounded := amount.Abs().Round(2)
+	integer := rounded.IntPart()
+	cents := rounded.Sub(decimal.NewFromInt(integer)).Shift(2).IntPart()
+
+	name, ok := englishCurrencyNames[strings.ToUpper(currency)]
+	if !ok {
+		name = strings.ToUpper(currency)
+	}
+	return fmt.Sprintf("%s AND %02d/100 %s", spellEnglish(integer), cents, name)
+}
+
+var (
+	englishUnits = [...]string{"ZERO", "ONE", "TWO", "THREE", "FOUR", "FIVE", "SIX", "SEVEN", "EIGHT", "NINE",
+		"TEN", "ELEVEN", "TWELVE", "THIRTEEN", "FOURTEEN", "FIFTEEN", "SIXTEEN", "SEVENTEEN", "EIGHTEEN", "NINETEEN"}
+	englishTens   = [...]string{"", "", "TWENTY", "THIRTY", "FORTY", "FIFTY", "SIXTY", "SEVENTY", "EIGHTY", "NINETY"}
+	englishScales = []struct {
+		value int64
+		name  string
+	}{{1_000_000_000_000, "TRILLION"}, {1_000_000_000, "BILLION"}, {1_000_000, "MILLION"}, {1_000, "THOUSAND"}}
+)
+
+// spellEnglish spells a non-negative integer in English, e.g. ONE HUNDRED
+// EIGHTEEN
+func spellEnglish(n int64) string {
+	for _, scale := range englishScales {
+		if n >= scale.value {
+			words := spellEnglish(n/scale.value) + " " + scale.name
+			if rest := n % scale.value; rest > 0 {
+				words += " " + spellEnglish(rest)
+			}
+			return words
+		}
+	}
+
+	switch {
+	case n >= 100:
+		words := englishUnits[n/100] + " HUNDRED"
+		if rest := n % 100; rest > 0 {
+			words += " " + spellEnglish(rest)
+		}
+		return words
+	case n >= 20:
+		words := englishTens[n/10]
+		if rest := n % 10; rest > 0 {
+			words += "-" + englishUnits[rest]
+		}
+		return words
+	}
+	return englishUnits[n]
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/printing"
+	"github.com/Abraxas-365/fuckturamelo/printing/models"
+)
+
+// printingRepository implements PrintingRepository using sqlx
+type printingRepository struct {
+	db *sqlx.DB
+}
+
+// NewPrintingRepository creates a new printing repository
+func NewPrintingRepository(db *sqlx.DB) PrintingRepository {
+	return &printingRepository{
+		db: db,
+	}
+}
+
+// GetTemplate retrieves the PDF template of an organization
+func (r *printingRepository) GetTemplate(ctx context.Context, orgID uuid.UUID) (*models.Template, error) {
+	var template models.Template
+	err := r.db.GetContext(ctx, &template,
+		`SELECT * FROM organization_pdf_templates WHERE organization_id = $1`, orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, printing.PrintingErrors.New(printing.ErrTemplateNotFound).
+				WithDetail("organization_id", orgID.String())
+		}
+		return nil, printing.PrintingErrors.New(printing.ErrPrintingLoadFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return &template, nil
+}
+
+// SaveTemplate inserts the template or replaces the existing one, leaving
+// its logo untouched
+func (r *printingRepository) SaveTemplate(ctx context.Context, template *models.Template) (*models.Template, error) {
+	var saved models.Template
+	err := r.db.GetContext(ctx, &saved, `
+		INSERT INTO organization_pdf_templates
+			(organization_id, layout, page_size, language, primary_color, accent_color, font_family,
+			 show_item_codes, footer_text, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			layout = EXCLUDED.layout,
+			page_size = EXCLUDED.page_size,
+			language = EXCLUDED.language,
+			primary_color = EXCLUDED.primary_color,
+			accent_color = EXCLUDED.accent_color,
+			font_family = EXCLUDED.font_family,
+			show_item_codes = EXCLUDED.show_item_codes,
+			footer_text = EXCLUDED.footer_text,
+			updated_by = EXCLUDED.updated_by
+		RETURNING *`,
+		template.OrganizationID, template.Layout, template.PageSize, template.Language,
+		template.PrimaryColor, template.AccentColor, template.FontFamily, template.ShowItemCodes,
+		template.FooterText, template.UpdatedBy)
+	if err != nil {
+		return nil, templateError(template.OrganizationID, err)
+	}
+
+	return &saved, nil
+}
+
+// DeleteTemplate removes the template of an organization, which prints
+// with the default template afterwards
+func (r *printingRepository) DeleteTemplate(ctx context.Context, orgID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM organization_pdf_templates WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return printing.PrintingErrors.New(printing.ErrTemplateSaveFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return printing.PrintingErrors.New(printing.ErrTemplateSaveFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+	if rows == 0 {
+		return printing.PrintingErrors.New(printing.ErrTemplateNotFound).
+			WithDetail("organization_id", orgID.String())
+	}
+
+	return nil
+}
+
+// SaveLogo sets the logo of the template, inserting a default template if
+// the organization has none
+func (r *printingRepository) SaveLogo(ctx context.Context, orgID uuid.UUID, logo []byte, contentType string, updatedBy *uuid.UUID) (*models.Template, error) {
+	var saved models.Template
+	err := r.db.GetContext(ctx, &saved, `
+		INSERT INTO organization_pdf_templates (organization_id, logo, logo_content_type, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			logo = EXCLUDED.logo,
+			logo_content_type = EXCLUDED.logo_content_type,
+			updated_by = EXCLUDED.updated_by
+		RETURNING *`,
+		orgID, logo, contentType, updatedBy)
+	if err != nil {
+		return nil, templateError(orgID, err)
+	}
+
+	return &saved, nil
+}
+
+// DeleteLogo removes the logo of the template
+func (r *printingRepository) DeleteLogo(ctx context.Context, orgID uuid.UUID) (*models.Template, error) {
+	var saved models.Template
+	err := r.db.GetContext(ctx, &saved, `
+		UPDATE organization_pdf_templates
+		SET logo = NULL, logo_content_type = NULL
+		WHERE organization_id = $1
+		RETURNING *`, orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, printing.PrintingErrors.New(printing.ErrTemplateNotFound).
+				WithDetail("organization_id", orgID.String())
+		}
+		return nil, templateError(orgID, err)
+	}
+
+	return &saved, nil
+}
+
+// GetOrganizationName retrieves the name of an organization
+func (r *printingRepository) GetOrganizationName(ctx context.Context, orgID uuid.UUID) (string, error) {
+	var name string
+	err := r.db.GetContext(ctx, &name,
+		`SELECT name FROM organizations WHERE id = $1`, orgID.String())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+				WithDetail("organization_id", orgID.String()).
+				WithDetail("reason", "organization does not exist")
+		}
+		return "", printing.PrintingErrors.New(printing.ErrPrintingLoadFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return name, nil
+}
+
+// templateError maps a failed template write to the domain error
+func templateError(orgID uuid.UUID, err error) error {
+	if strings.Contains(err.Error(), "violates foreign key constraint") {
+		return printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithDetail("reason", "organization does not exist").
+			WithCause(err)
+	}
+	if strings.Contains(err.Error(), "violates check constraint") {
+		return printing.PrintingErrors.New(printing.ErrPrintingValidationFailed).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+	return printing.PrintingErrors.New(printing.ErrTemplateSaveFailed).
+		WithDetail("organization_id", orgID.String()).
+		WithCause(err)
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/printing/models"
+)
+
+// PrintingRepository defines the interface for printing repository
+// operations
+type PrintingRepository interface {
+	GetTemplate(ctx context.Context, orgID uuid.UUID) (*models.Template, error)
+
+	// SaveTemplate creates or replaces the template of the organization,
+	// keeping its logo
+	SaveTemplate(ctx context.Context, template *models.Template) (*models.Template, error)
+	DeleteTemplate(ctx context.Context, orgID uuid.UUID) error
+
+	// SaveLogo sets the logo of the organization's template, creating the
+	// template with the defaults if it has none
+	SaveLogo(ctx context.Context, orgID uuid.UUID, logo []byte, contentType string, updatedBy *uuid.UUID) (*models.Template, error)
+	DeleteLogo(ctx context.Context, orgID uuid.UUID) (*models.Template, error)
+
+	// GetOrganizationName returns the name of an organization, printed
+	// when it has no tax profile
+	GetOrganizationName(ctx context.Context, orgID uuid.UUID) (string, error)
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"github.com/Abraxas-365/fuckturamelo/jsonfilter"
 	"github.com/Abraxas-365/fuckturamelo/projects/models"
 	"github.com/google/uuid"
 )
@@ -22,15 +23,20 @@ type UpdateProjectRequest struct {
 	Metadata    models.Metadata `json:"metadata"`
 }
 
-// ProjectListRequest represents query parameters for listing projects
+// ProjectListRequest represents query parameters for listing projects.
+// Filter is a jsonfilter expression over metadata, compiled into
+// MetadataFilter by the service.
 type ProjectListRequest struct {
 	OrganizationID *uuid.UUID `query:"organization_id"`
 	IsActive       *bool      `query:"is_active"`
 	Search         *string    `query:"search"`
+	Filter         *string    `query:"filter"`
 	Page           int        `query:"page" validate:"min=1"`
 	PageSize       int        `query:"page_size" validate:"min=1,max=100"`
 	SortBy         string     `query:"sort_by"`
 	SortOrder      string     `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+
+	MetadataFilter *jsonfilter.Filter `query:"-"`
 }
 
 // AddProviderRequest represents the request to add a provider to a project
@@ -2,11 +2,13 @@ package projectsapi
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
 	"github.com/Abraxas-365/fuckturamelo/projects"
 	"github.com/Abraxas-365/fuckturamelo/projects/dto"
 	"github.com/Abraxas-365/fuckturamelo/projects/projectsrv"
@@ -98,6 +100,7 @@ func (api *ProjectsAPI) createProject(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	api.setETag(c, result.UpdatedAt)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
@@ -116,6 +119,7 @@ func (api *ProjectsAPI) getProject(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	api.setETag(c, result.UpdatedAt)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -141,13 +145,19 @@ func (api *ProjectsAPI) getProjectWithProviders(c *fiber.Ctx) error {
 	})
 }
 
-// updateProject handles PUT /projects/:id
+// updateProject handles PUT /projects/:id. An If-Match header, when sent,
+// must match the project's current ETag.
 func (api *ProjectsAPI) updateProject(c *fiber.Ctx) error {
 	id, err := api.parseUUIDParam(c, "id")
 	if err != nil {
 		return err
 	}
 
+	precondition, err := concurrency.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return err
+	}
+
 	var req dto.UpdateProjectRequest
 	if err := c.BodyParser(&req); err != nil {
 		return projects.ProjectsErrors.New(projects.ErrProjectValidationFailed).
@@ -155,10 +165,11 @@ func (api *ProjectsAPI) updateProject(c *fiber.Ctx) error {
 			WithCause(err)
 	}
 
-	result, err := api.service.UpdateProject(c.Context(), id, &req)
+	result, err := api.service.UpdateProject(c.Context(), id, &req, precondition)
 	if err != nil {
 		return err
 	}
+	api.setETag(c, result.UpdatedAt)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -494,6 +505,11 @@ func (api *ProjectsAPI) healthCheck(c *fiber.Ctx) error {
 
 // Helper methods
 
+// setETag exposes the project's last modification time as its ETag
+func (api *ProjectsAPI) setETag(c *fiber.Ctx, updatedAt time.Time) {
+	c.Set(fiber.HeaderETag, concurrency.TimestampETag(updatedAt))
+}
+
 func (api *ProjectsAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
 	paramValue := c.Params(paramName)
 	if paramValue == "" {
@@ -536,6 +552,11 @@ func (api *ProjectsAPI) parseListRequest(c *fiber.Ctx) (*dto.ProjectListRequest,
 		req.Search = &search
 	}
 
+	// Parse metadata filter expression
+	if filter := c.Query("filter"); filter != "" {
+		req.Filter = &filter
+	}
+
 	// Parse pagination
 	page := 1
 	if pageStr := c.Query("page"); pageStr != "" {
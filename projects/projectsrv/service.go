@@ -2,8 +2,11 @@ package projectsrv
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
+	"github.com/Abraxas-365/fuckturamelo/jsonfilter"
 	"github.com/Abraxas-365/fuckturamelo/projects"
 	"github.com/Abraxas-365/fuckturamelo/projects/dto"
 	"github.com/Abraxas-365/fuckturamelo/projects/models"
@@ -17,7 +20,7 @@ type ProjectService interface {
 	CreateProject(ctx context.Context, req *dto.CreateProjectRequest) (*dto.ProjectResponse, error)
 	GetProject(ctx context.Context, id uuid.UUID) (*dto.ProjectResponse, error)
 	GetProjectWithProviders(ctx context.Context, id uuid.UUID) (*dto.ProjectWithProvidersResponse, error)
-	UpdateProject(ctx context.Context, id uuid.UUID, req *dto.UpdateProjectRequest) (*dto.ProjectResponse, error)
+	UpdateProject(ctx context.Context, id uuid.UUID, req *dto.UpdateProjectRequest, precondition *concurrency.Precondition) (*dto.ProjectResponse, error)
 	DeleteProject(ctx context.Context, id uuid.UUID) error
 
 	// Query operations
@@ -132,13 +135,17 @@ func (s *projectService) GetProjectWithProviders(ctx context.Context, id uuid.UU
 	return &dto.ProjectWithProvidersResponse{ProjectWithProviders: project}, nil
 }
 
-// UpdateProject updates an existing project
-func (s *projectService) UpdateProject(ctx context.Context, id uuid.UUID, req *dto.UpdateProjectRequest) (*dto.ProjectResponse, error) {
+// UpdateProject updates an existing project. A non-nil precondition must
+// match the project's current ETag.
+func (s *projectService) UpdateProject(ctx context.Context, id uuid.UUID, req *dto.UpdateProjectRequest, precondition *concurrency.Precondition) (*dto.ProjectResponse, error) {
 	// Get existing project
 	existingProject, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := precondition.Check(concurrency.TimestampETag(existingProject.UpdatedAt)); err != nil {
+		return nil, err
+	}
 
 	// Check name uniqueness if name is being changed
 	if req.Name != nil && *req.Name != existingProject.Name {
@@ -169,8 +176,8 @@ func (s *projectService) UpdateProject(ctx context.Context, id uuid.UUID, req *d
 	}
 	updatedProject.UpdatedAt = time.Now()
 
-	// Update project
-	result, err := s.repo.Update(ctx, id, &updatedProject)
+	// Update project unless it changed since it was read
+	result, err := s.repo.UpdateIfUnmodified(ctx, id, &updatedProject, existingProject.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -209,6 +216,19 @@ func (s *projectService) ListProjects(ctx context.Context, req *dto.ProjectListR
 		req.SortOrder = "desc"
 	}
 
+	// Metadata has no schema, so any field may be filtered on
+	req.MetadataFilter = nil
+	if req.Filter != nil && strings.TrimSpace(*req.Filter) != "" {
+		filter, err := jsonfilter.Parse(*req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if err := filter.Check(nil); err != nil {
+			return nil, err
+		}
+		req.MetadataFilter = filter
+	}
+
 	return s.repo.List(ctx, req)
 }
 
@@ -247,7 +267,7 @@ func (s *projectService) ActivateProject(ctx context.Context, id uuid.UUID) (*dt
 	req := &dto.UpdateProjectRequest{
 		IsActive: &[]bool{true}[0],
 	}
-	return s.UpdateProject(ctx, id, req)
+	return s.UpdateProject(ctx, id, req, nil)
 }
 
 // DeactivateProject deactivates a project
@@ -255,7 +275,7 @@ func (s *projectService) DeactivateProject(ctx context.Context, id uuid.UUID) (*
 	req := &dto.UpdateProjectRequest{
 		IsActive: &[]bool{false}[0],
 	}
-	return s.UpdateProject(ctx, id, req)
+	return s.UpdateProject(ctx, id, req, nil)
 }
 
 // DuplicateProject duplicates an existing project
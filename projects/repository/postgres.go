@@ -3,14 +3,17 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Abraxas-365/craftable/storex"
 	"github.com/Abraxas-365/craftable/storex/providers/storexpostgres"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
 	"github.com/Abraxas-365/fuckturamelo/projects"
 	"github.com/Abraxas-365/fuckturamelo/projects/dto"
 	"github.com/Abraxas-365/fuckturamelo/projects/models"
@@ -132,6 +135,40 @@ func (r *projectRepository) Update(ctx context.Context, id uuid.UUID, project *m
 	return &result, nil
 }
 
+// UpdateIfUnmodified updates a project only if its updated_at still equals
+// lastUpdatedAt, failing with a precondition error otherwise
+func (r *projectRepository) UpdateIfUnmodified(ctx context.Context, id uuid.UUID, project *models.Project, lastUpdatedAt time.Time) (*models.Project, error) {
+	project.ID = id
+
+	query := `
+		UPDATE projects
+		SET name = $3, description = $4, is_active = $5, metadata = $6, updated_at = $7
+		WHERE id = $1 AND updated_at = $2
+		RETURNING *`
+
+	var result models.Project
+	err := r.db.GetContext(ctx, &result, query,
+		id, lastUpdatedAt, project.Name, project.Description, project.IsActive,
+		project.Metadata, project.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, concurrency.ConcurrencyErrors.New(concurrency.ErrPreconditionFailed).
+				WithDetail("project_id", id.String())
+		}
+		if strings.Contains(err.Error(), "projects_name_org_unique") {
+			return nil, projects.ProjectsErrors.New(projects.ErrProjectNameExists).
+				WithDetail("name", project.Name).
+				WithDetail("organization_id", project.OrganizationID.String()).
+				WithCause(err)
+		}
+		return nil, projects.ProjectsErrors.New(projects.ErrProjectUpdateFailed).
+			WithDetail("project_id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
 // Delete deletes a project
 func (r *projectRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	err := r.repo.Delete(ctx, id.String())
@@ -164,8 +201,8 @@ func (r *projectRepository) List(ctx context.Context, req *dto.ProjectListReques
 		req.SortBy = DefaultSortField
 	}
 
-	// Handle search separately if provided (PostgreSQL-specific implementation)
-	if req.Search != nil && *req.Search != "" {
+	// Handle search and metadata filters separately if provided (PostgreSQL-specific implementation)
+	if (req.Search != nil && *req.Search != "") || req.MetadataFilter != nil {
 		return r.searchProjects(ctx, req)
 	}
 
@@ -195,14 +232,28 @@ func (r *projectRepository) List(ctx context.Context, req *dto.ProjectListReques
 	return r.buildListResponse(result), nil
 }
 
-// searchProjects handles search functionality with raw SQL for better performance
+// searchProjects handles search and metadata filters with raw SQL for better performance
 func (r *projectRepository) searchProjects(ctx context.Context, req *dto.ProjectListRequest) (*dto.ProjectListResponse, error) {
-	searchQuery := fmt.Sprintf("%%%s%%", strings.TrimSpace(*req.Search))
-
 	// Build base WHERE clause
-	whereConditions := []string{"(name ILIKE $1 OR description ILIKE $1)"}
-	args := []any{searchQuery}
-	argIndex := 2
+	whereConditions := []string{"1=1"}
+	args := []any{}
+	argIndex := 1
+
+	search := ""
+	if req.Search != nil {
+		search = strings.TrimSpace(*req.Search)
+	}
+	if search != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("(name ILIKE $%d OR description ILIKE $%d)", argIndex, argIndex))
+		args = append(args, fmt.Sprintf("%%%s%%", search))
+		argIndex++
+	}
+	if req.MetadataFilter != nil {
+		condition, filterArgs := req.MetadataFilter.SQL("metadata", argIndex)
+		whereConditions = append(whereConditions, condition)
+		args = append(args, filterArgs...)
+		argIndex += len(filterArgs)
+	}
 
 	// Add additional filters
 	if req.OrganizationID != nil {
@@ -234,7 +285,7 @@ func (r *projectRepository) searchProjects(ctx context.Context, req *dto.Project
 	err := r.db.GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, projects.ProjectsErrors.New(projects.ErrProjectListFailed).
-			WithDetail("search", *req.Search).
+			WithDetail("search", search).
 			WithCause(err)
 	}
 
@@ -252,7 +303,7 @@ func (r *projectRepository) searchProjects(ctx context.Context, req *dto.Project
 	err = r.db.SelectContext(ctx, &projectList, dataQuery, args...)
 	if err != nil {
 		return nil, projects.ProjectsErrors.New(projects.ErrProjectListFailed).
-			WithDetail("search", *req.Search).
+			WithDetail("search", search).
 			WithCause(err)
 	}
 
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/Abraxas-365/fuckturamelo/projects/dto"
 	"github.com/Abraxas-365/fuckturamelo/projects/models"
@@ -15,6 +16,7 @@ type ProjectRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error)
 	GetByIDWithProviders(ctx context.Context, id uuid.UUID) (*models.ProjectWithProviders, error)
 	Update(ctx context.Context, id uuid.UUID, project *models.Project) (*models.Project, error)
+	UpdateIfUnmodified(ctx context.Context, id uuid.UUID, project *models.Project, lastUpdatedAt time.Time) (*models.Project, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 
 	// Query operations
@@ -5,6 +5,8 @@ import (
 
 	"github.com/Abraxas-365/craftable/validatex"
 	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/jsonfilter"
 )
 
 // CreateProviderRequest represents the request to create a new provider
@@ -13,6 +15,7 @@ type CreateProviderRequest struct {
 	OrganizationID uuid.UUID      `json:"organization_id" validate:"required,uuid"`
 	Name           string         `json:"name" validate:"required,min=1,max=255"`
 	ProviderCode   *string        `json:"provider_code,omitempty" validate:"omitempty,max=50"`
+	TaxID          *string        `json:"tax_id,omitempty" validate:"omitempty,max=20"`
 	IsActive       *bool          `json:"is_active,omitempty"`
 	Metadata       map[string]any `json:"metadata,omitempty"`
 }
@@ -22,6 +25,7 @@ type UpdateProviderRequest struct {
 	UserID       *uuid.UUID     `json:"user_id,omitempty" validate:"omitempty,uuid"`
 	Name         *string        `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
 	ProviderCode *string        `json:"provider_code,omitempty" validate:"omitempty,max=50"`
+	TaxID        *string        `json:"tax_id,omitempty" validate:"omitempty,max=20"`
 	IsActive     *bool          `json:"is_active,omitempty"`
 	Metadata     map[string]any `json:"metadata,omitempty"`
 }
@@ -33,21 +37,27 @@ type ProviderResponse struct {
 	OrganizationID uuid.UUID      `json:"organization_id"`
 	Name           string         `json:"name"`
 	ProviderCode   *string        `json:"provider_code,omitempty"`
+	TaxID          *string        `json:"tax_id,omitempty"`
 	IsActive       bool           `json:"is_active"`
 	Metadata       map[string]any `json:"metadata"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 }
 
-// ProviderListRequest represents the request for listing providers with filters
+// ProviderListRequest represents the request for listing providers with filters.
+// Filter is a jsonfilter expression over metadata, compiled into
+// MetadataFilter by the service.
 type ProviderListRequest struct {
 	OrganizationID *uuid.UUID `json:"organization_id,omitempty" validate:"omitempty,uuid"`
 	IsActive       *bool      `json:"is_active,omitempty"`
 	Search         *string    `json:"search,omitempty" validate:"omitempty,max=255"`
+	Filter         *string    `json:"filter,omitempty" validate:"omitempty,max=2000"`
 	Page           int        `json:"page,omitempty" validate:"omitempty,min=1"`
 	PageSize       int        `json:"page_size,omitempty" validate:"omitempty,min=1,max=100"`
 	OrderBy        *string    `json:"order_by,omitempty" validate:"omitempty,oneof=name created_at updated_at"`
 	Desc           bool       `json:"desc,omitempty"`
+
+	MetadataFilter *jsonfilter.Filter `json:"-"`
 }
 
 // ProviderListResponse represents the paginated response for providers
@@ -75,3 +85,20 @@ func (r *UpdateProviderRequest) Validate() error {
 func (r *ProviderListRequest) Validate() error {
 	return validatex.Validate(r)
 }
+
+// CreateBankAccountRequest represents the request to add a bank account to a
+// provider. Either iban or account_number is required.
+type CreateBankAccountRequest struct {
+	AccountHolder string  `json:"account_holder" validate:"required,min=1,max=70"`
+	IBAN          *string `json:"iban,omitempty" validate:"omitempty,max=42"`
+	AccountNumber *string `json:"account_number,omitempty" validate:"omitempty,max=34"`
+	BIC           *string `json:"bic,omitempty" validate:"omitempty,max=11"`
+	BankName      *string `json:"bank_name,omitempty" validate:"omitempty,max=255"`
+	CurrencyCode  *string `json:"currency_code,omitempty" validate:"omitempty,len=3"`
+	IsDefault     bool    `json:"is_default,omitempty"`
+}
+
+// Validate validates the CreateBankAccountRequest
+func (r *CreateBankAccountRequest) Validate() error {
+	return validatex.Validate(r)
+}
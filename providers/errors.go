@@ -85,6 +85,28 @@ var (
 		"Provider is inactive and cannot be used",
 	)
 
+	// Bank account errors
+	ErrBankAccountNotFound = ProvidersErrors.Register(
+		"BANK_ACCOUNT_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Provider bank account not found",
+	)
+
+	ErrBankAccountExists = ProvidersErrors.Register(
+		"BANK_ACCOUNT_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Provider already has this bank account",
+	)
+
+	ErrBankAccountFailed = ProvidersErrors.Register(
+		"BANK_ACCOUNT_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to store provider bank account",
+	)
+
 	// Bulk operation errors
 	ErrProviderBulkCreateFailed = ProvidersErrors.Register(
 		"BULK_CREATE_FAILED",
@@ -120,3 +142,7 @@ func IsProviderNameExists(err error) bool {
 func IsProviderValidationFailed(err error) bool {
 	return errx.IsCode(err, ErrProviderValidationFailed)
 }
+
+func IsBankAccountNotFound(err error) bool {
+	return errx.IsCode(err, ErrBankAccountNotFound)
+}
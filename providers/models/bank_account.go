@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BankAccount is an account a provider is paid into. Accounts are identified
+// by IBAN or, for domestic schemes without IBANs, by a local account number.
+type BankAccount struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	ProviderID    uuid.UUID `json:"provider_id" db:"provider_id"`
+	AccountHolder string    `json:"account_holder" db:"account_holder"`
+	IBAN          *string   `json:"iban,omitempty" db:"iban"`
+	AccountNumber *string   `json:"account_number,omitempty" db:"account_number"`
+	BIC           *string   `json:"bic,omitempty" db:"bic"`
+	BankName      *string   `json:"bank_name,omitempty" db:"bank_name"`
+	CurrencyCode  *string   `json:"currency_code,omitempty" db:"currency_code"`
+	IsDefault     bool      `json:"is_default" db:"is_default"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the BankAccount model
+func (a BankAccount) TableName() string {
+	return "provider_bank_accounts"
+}
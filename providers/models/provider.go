@@ -13,6 +13,7 @@ type Provider struct {
 	OrganizationID uuid.UUID      `json:"organization_id" db:"organization_id"`
 	Name           string         `json:"name" db:"name"`
 	ProviderCode   *string        `json:"provider_code,omitempty" db:"provider_code"`
+	TaxID          *string        `json:"tax_id,omitempty" db:"tax_id"`
 	IsActive       bool           `json:"is_active" db:"is_active"`
 	Metadata       map[string]any `json:"metadata" db:"metadata"`
 	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
@@ -2,11 +2,13 @@ package providersapi
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
 	"github.com/Abraxas-365/fuckturamelo/providers"
 	"github.com/Abraxas-365/fuckturamelo/providers/dto"
 	postgres "github.com/Abraxas-365/fuckturamelo/providers/repository"
@@ -33,7 +35,8 @@ func New(config Config) (*ProvidersAPI, error) {
 
 	// Initialize layers from bottom up
 	repo := postgres.NewProviderRepository(config.DB)
-	svc := service.NewProviderService(repo)
+	accounts := postgres.NewBankAccountRepository(config.DB)
+	svc := service.NewProviderService(repo, accounts)
 
 	return &ProvidersAPI{
 		service: svc,
@@ -55,6 +58,12 @@ func (api *ProvidersAPI) SetupRoutes(router fiber.Router) {
 	router.Post("/:id/deactivate", api.deactivateProvider)
 	router.Post("/:id/duplicate", api.duplicateProvider)
 
+	// Bank account routes
+	router.Get("/:id/bank-accounts", api.listBankAccounts)
+	router.Post("/:id/bank-accounts", api.addBankAccount)
+	router.Post("/:id/bank-accounts/:accountId/default", api.setDefaultBankAccount)
+	router.Delete("/:id/bank-accounts/:accountId", api.deleteBankAccount)
+
 	// Query routes
 	router.Get("/search", api.searchProviders)
 	router.Get("/organization/:orgId", api.getProvidersByOrganization)
@@ -86,6 +95,7 @@ func (api *ProvidersAPI) createProvider(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	api.setETag(c, result.UpdatedAt)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
@@ -104,6 +114,7 @@ func (api *ProvidersAPI) getProvider(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	api.setETag(c, result.UpdatedAt)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -111,13 +122,19 @@ func (api *ProvidersAPI) getProvider(c *fiber.Ctx) error {
 	})
 }
 
-// updateProvider handles PUT /providers/:id
+// updateProvider handles PUT /providers/:id. An If-Match header, when sent,
+// must match the provider's current ETag.
 func (api *ProvidersAPI) updateProvider(c *fiber.Ctx) error {
 	id, err := api.parseUUIDParam(c, "id")
 	if err != nil {
 		return err
 	}
 
+	precondition, err := concurrency.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return err
+	}
+
 	var req dto.UpdateProviderRequest
 	if err := c.BodyParser(&req); err != nil {
 		return providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
@@ -125,10 +142,11 @@ func (api *ProvidersAPI) updateProvider(c *fiber.Ctx) error {
 			WithCause(err)
 	}
 
-	result, err := api.service.UpdateProvider(c.Context(), id, &req)
+	result, err := api.service.UpdateProvider(c.Context(), id, &req, precondition)
 	if err != nil {
 		return err
 	}
+	api.setETag(c, result.UpdatedAt)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -287,6 +305,89 @@ func (api *ProvidersAPI) duplicateProvider(c *fiber.Ctx) error {
 	})
 }
 
+// listBankAccounts handles GET /providers/:id/bank-accounts
+func (api *ProvidersAPI) listBankAccounts(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListBankAccounts(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// addBankAccount handles POST /providers/:id/bank-accounts
+func (api *ProvidersAPI) addBankAccount(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.CreateBankAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.AddBankAccount(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// setDefaultBankAccount handles POST /providers/:id/bank-accounts/:accountId/default
+func (api *ProvidersAPI) setDefaultBankAccount(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+	accountID, err := api.parseUUIDParam(c, "accountId")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.SetDefaultBankAccount(c.Context(), id, accountID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteBankAccount handles DELETE /providers/:id/bank-accounts/:accountId
+func (api *ProvidersAPI) deleteBankAccount(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+	accountID, err := api.parseUUIDParam(c, "accountId")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteBankAccount(c.Context(), id, accountID); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 // healthCheck provides a health check endpoint
 func (api *ProvidersAPI) healthCheck(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -295,6 +396,11 @@ func (api *ProvidersAPI) healthCheck(c *fiber.Ctx) error {
 	})
 }
 
+// setETag exposes the provider's last modification time as its ETag
+func (api *ProvidersAPI) setETag(c *fiber.Ctx, updatedAt time.Time) {
+	c.Set(fiber.HeaderETag, concurrency.TimestampETag(updatedAt))
+}
+
 func (api *ProvidersAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
 	paramValue := c.Params(paramName)
 	if paramValue == "" {
@@ -342,6 +448,11 @@ func (api *ProvidersAPI) parseListRequest(c *fiber.Ctx) (*dto.ProviderListReques
 		req.Search = &search
 	}
 
+	// Parse metadata filter expression
+	if filter := c.Query("filter"); filter != "" {
+		req.Filter = &filter
+	}
+
 	// Parse page
 	if pageStr := c.Query("page"); pageStr != "" {
 		page, err := strconv.Atoi(pageStr)
@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/providers"
+	"github.com/Abraxas-365/fuckturamelo/providers/models"
+)
+
+// BankAccountRepository stores the bank accounts providers are paid into.
+// A provider has at most one default account.
+type BankAccountRepository interface {
+	Create(ctx context.Context, account *models.BankAccount) (*models.BankAccount, error)
+	GetByID(ctx context.Context, providerID, id uuid.UUID) (*models.BankAccount, error)
+	ListByProvider(ctx context.Context, providerID uuid.UUID) ([]models.BankAccount, error)
+	SetDefault(ctx context.Context, providerID, id uuid.UUID) (*models.BankAccount, error)
+	Delete(ctx context.Context, providerID, id uuid.UUID) error
+}
+
+// bankAccountRepository implements BankAccountRepository using sqlx
+type bankAccountRepository struct {
+	db *sqlx.DB
+}
+
+// NewBankAccountRepository creates a new provider bank account repository
+func NewBankAccountRepository(db *sqlx.DB) BankAccountRepository {
+	return &bankAccountRepository{db: db}
+}
+
+const bankAccountColumns = `id, provider_id, account_holder, iban, account_number, bic,
+	bank_name, currency_code, is_default, created_at, updated_at`
+
+// Create adds an account. The first account of a provider becomes its
+// default, and a new default replaces the previous one.
+func (r *bankAccountRepository) Create(ctx context.Context, account *models.BankAccount) (*models.BankAccount, error) {
+	if account.ID == uuid.Nil {
+		account.ID = uuid.New()
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+	defer tx.Rollback()
+
+	// Serialize account changes per provider so the default stays unique
+	if err := lockProvider(ctx, tx, account.ProviderID); err != nil {
+		return nil, err
+	}
+
+	var existing int
+	if err := tx.GetContext(ctx, &existing,
+		`SELECT COUNT(*) FROM provider_bank_accounts WHERE provider_id = $1`, account.ProviderID); err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+	if existing == 0 {
+		account.IsDefault = true
+	}
+	if account.IsDefault {
+		if err := clearDefault(ctx, tx, account.ProviderID); err != nil {
+			return nil, err
+		}
+	}
+
+	var created models.BankAccount
+	err = tx.GetContext(ctx, &created, `
+		INSERT INTO provider_bank_accounts (
+			id, provider_id, account_holder, iban, account_number, bic,
+			bank_name, currency_code, is_default
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+bankAccountColumns,
+		account.ID, account.ProviderID, account.AccountHolder, account.IBAN, account.AccountNumber,
+		account.BIC, account.BankName, account.CurrencyCode, account.IsDefault,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "provider_bank_accounts_iban_unique") ||
+			strings.Contains(err.Error(), "provider_bank_accounts_number_unique") {
+			return nil, providers.ProvidersErrors.New(providers.ErrBankAccountExists).
+				WithDetail("provider_id", account.ProviderID.String()).
+				WithCause(err)
+		}
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+
+	return &created, nil
+}
+
+// GetByID retrieves an account of a provider
+func (r *bankAccountRepository) GetByID(ctx context.Context, providerID, id uuid.UUID) (*models.BankAccount, error) {
+	var account models.BankAccount
+	err := r.db.GetContext(ctx, &account,
+		`SELECT `+bankAccountColumns+` FROM provider_bank_accounts WHERE id = $1 AND provider_id = $2`,
+		id, providerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, providers.ProvidersErrors.New(providers.ErrBankAccountNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+
+	return &account, nil
+}
+
+// ListByProvider returns the accounts of a provider, default first
+func (r *bankAccountRepository) ListByProvider(ctx context.Context, providerID uuid.UUID) ([]models.BankAccount, error) {
+	accounts := []models.BankAccount{}
+	err := r.db.SelectContext(ctx, &accounts, `
+		SELECT `+bankAccountColumns+` FROM provider_bank_accounts
+		WHERE provider_id = $1
+		ORDER BY is_default DESC, created_at`, providerID)
+	if err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrProviderListFailed).
+			WithDetail("provider_id", providerID.String()).
+			WithCause(err)
+	}
+
+	return accounts, nil
+}
+
+// SetDefault makes the account the one payments go to
+func (r *bankAccountRepository) SetDefault(ctx context.Context, providerID, id uuid.UUID) (*models.BankAccount, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+	defer tx.Rollback()
+
+	if err := lockProvider(ctx, tx, providerID); err != nil {
+		return nil, err
+	}
+	if err := clearDefault(ctx, tx, providerID); err != nil {
+		return nil, err
+	}
+
+	var account models.BankAccount
+	err = tx.GetContext(ctx, &account, `
+		UPDATE provider_bank_accounts SET is_default = TRUE
+		WHERE id = $1 AND provider_id = $2
+		RETURNING `+bankAccountColumns, id, providerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, providers.ProvidersErrors.New(providers.ErrBankAccountNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+
+	return &account, nil
+}
+
+// Delete removes an account. When it was the default, the oldest remaining
+// account takes its place.
+func (r *bankAccountRepository) Delete(ctx context.Context, providerID, id uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+	defer tx.Rollback()
+
+	if err := lockProvider(ctx, tx, providerID); err != nil {
+		return err
+	}
+
+	var wasDefault bool
+	err = tx.GetContext(ctx, &wasDefault, `
+		DELETE FROM provider_bank_accounts WHERE id = $1 AND provider_id = $2
+		RETURNING is_default`, id, providerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return providers.ProvidersErrors.New(providers.ErrBankAccountNotFound).
+				WithDetail("id", id.String())
+		}
+		return providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+
+	if wasDefault {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE provider_bank_accounts SET is_default = TRUE
+			WHERE id = (
+				SELECT id FROM provider_bank_accounts
+				WHERE provider_id = $1
+				ORDER BY created_at
+				LIMIT 1
+			)`, providerID)
+		if err != nil {
+			return providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+
+	return nil
+}
+
+func lockProvider(ctx context.Context, tx *sqlx.Tx, providerID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.GetContext(ctx, &id, `SELECT id FROM providers WHERE id = $1 FOR UPDATE`, providerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return providers.ProvidersErrors.New(providers.ErrProviderNotFound).
+				WithDetail("id", providerID.String())
+		}
+		return providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+	return nil
+}
+
+func clearDefault(ctx context.Context, tx *sqlx.Tx, providerID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE provider_bank_accounts SET is_default = FALSE WHERE provider_id = $1 AND is_default`,
+		providerID)
+	if err != nil {
+		return providers.ProvidersErrors.New(providers.ErrBankAccountFailed).WithCause(err)
+	}
+	return nil
+}
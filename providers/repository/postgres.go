@@ -2,14 +2,20 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Abraxas-365/craftable/storex"
 	"github.com/Abraxas-365/craftable/storex/providers/storexpostgres"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
+	"github.com/Abraxas-365/fuckturamelo/jsonfilter"
 	"github.com/Abraxas-365/fuckturamelo/providers"
 	"github.com/Abraxas-365/fuckturamelo/providers/dto"
 	"github.com/Abraxas-365/fuckturamelo/providers/models"
@@ -21,12 +27,14 @@ type ProviderRepository interface {
 	Create(ctx context.Context, provider *models.Provider) (*models.Provider, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Provider, error)
 	Update(ctx context.Context, id uuid.UUID, provider *models.Provider) (*models.Provider, error)
+	UpdateIfUnmodified(ctx context.Context, id uuid.UUID, provider *models.Provider, lastUpdatedAt time.Time) (*models.Provider, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 
 	// Query operations
 	List(ctx context.Context, req *dto.ProviderListRequest) (*dto.ProviderListResponse, error)
 	GetByOrganization(ctx context.Context, orgID uuid.UUID) ([]*models.Provider, error)
 	GetByNameAndOrganization(ctx context.Context, name string, orgID uuid.UUID) (*models.Provider, error)
+	GetByTaxIDAndOrganization(ctx context.Context, taxID string, orgID uuid.UUID) (*models.Provider, error)
 	Search(ctx context.Context, query string, orgID uuid.UUID) ([]*models.Provider, error)
 
 	// Bulk operations
@@ -117,6 +125,47 @@ func (r *providerRepository) Update(ctx context.Context, id uuid.UUID, provider
 	return &result, nil
 }
 
+// UpdateIfUnmodified updates a provider only if its updated_at still equals
+// lastUpdatedAt, failing with a precondition error otherwise
+func (r *providerRepository) UpdateIfUnmodified(ctx context.Context, id uuid.UUID, provider *models.Provider, lastUpdatedAt time.Time) (*models.Provider, error) {
+	provider.ID = id
+
+	metadata, err := json.Marshal(provider.Metadata)
+	if err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
+			WithDetail("field", "metadata").
+			WithCause(err)
+	}
+
+	query := `
+		UPDATE providers
+		SET user_id = $3, name = $4, provider_code = $5, tax_id = $6, is_active = $7, metadata = $8, updated_at = $9
+		WHERE id = $1 AND updated_at = $2
+		RETURNING *`
+
+	var result models.Provider
+	err = r.db.GetContext(ctx, &result, query,
+		id, lastUpdatedAt, provider.UserID, provider.Name, provider.ProviderCode, provider.TaxID,
+		provider.IsActive, metadata, provider.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, concurrency.ConcurrencyErrors.New(concurrency.ErrPreconditionFailed).
+				WithDetail("provider_id", id.String())
+		}
+		if strings.Contains(err.Error(), "providers_name_org_unique") {
+			return nil, providers.ProvidersErrors.New(providers.ErrProviderNameExists).
+				WithDetail("name", provider.Name).
+				WithDetail("organization_id", provider.OrganizationID.String()).
+				WithCause(err)
+		}
+		return nil, providers.ProvidersErrors.New(providers.ErrProviderUpdateFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
 // Delete deletes a provider by ID
 func (r *providerRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	err := r.repo.Delete(ctx, id.String())
@@ -165,9 +214,13 @@ func (r *providerRepository) List(ctx context.Context, req *dto.ProviderListRequ
 		Desc:     req.Desc,
 	}
 
-	// Handle search if provided
-	if req.Search != nil && *req.Search != "" {
-		return r.searchProviders(ctx, *req.Search, opts)
+	// Handle search and metadata filters if provided
+	if (req.Search != nil && *req.Search != "") || req.MetadataFilter != nil {
+		search := ""
+		if req.Search != nil {
+			search = *req.Search
+		}
+		return r.searchProviders(ctx, search, req.MetadataFilter, opts)
 	}
 
 	result, err := r.repo.Paginate(ctx, opts)
@@ -226,6 +279,29 @@ func (r *providerRepository) GetByNameAndOrganization(ctx context.Context, name
 	return &result, nil
 }
 
+// GetByTaxIDAndOrganization retrieves a provider by tax ID and organization
+func (r *providerRepository) GetByTaxIDAndOrganization(ctx context.Context, taxID string, orgID uuid.UUID) (*models.Provider, error) {
+	filters := map[string]any{
+		"tax_id":          taxID,
+		"organization_id": orgID,
+	}
+
+	result, err := r.repo.FindOne(ctx, filters)
+	if err != nil {
+		if storex.IsRecordNotFound(err) {
+			return nil, providers.ProvidersErrors.New(providers.ErrProviderNotFound).
+				WithDetail("tax_id", taxID).
+				WithDetail("organization_id", orgID.String())
+		}
+		return nil, providers.ProvidersErrors.New(providers.ErrProviderListFailed).
+			WithDetail("tax_id", taxID).
+			WithDetail("organization_id", orgID.String()).
+			WithCause(err)
+	}
+
+	return &result, nil
+}
+
 // Search performs a text search on providers
 func (r *providerRepository) Search(ctx context.Context, query string, orgID uuid.UUID) ([]*models.Provider, error) {
 	opts := storex.SearchOptions{
@@ -351,68 +427,63 @@ func (r *providerRepository) DeleteBulk(ctx context.Context, ids []uuid.UUID) er
 
 // Helper methods
 
-func (r *providerRepository) searchProviders(ctx context.Context, query string, opts storex.PaginationOptions) (*dto.ProviderListResponse, error) {
-	// Build custom search query that respects filters
-	searchSQL := `
-		SELECT p.* FROM providers p
-		WHERE (p.name ILIKE $1 OR p.provider_code ILIKE $1)
-	`
-	args := []any{"%" + query + "%"}
-	argIndex := 2
+// searchProviders lists providers matching a name or code search and a
+// metadata filter; either may be empty
+func (r *providerRepository) searchProviders(ctx context.Context, query string, metadata *jsonfilter.Filter, opts storex.PaginationOptions) (*dto.ProviderListResponse, error) {
+	// Build a WHERE clause shared by the search and count queries
+	whereConditions := []string{"1=1"}
+	args := []any{}
+	argIndex := 1
+
+	if query != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("(p.name ILIKE $%d OR p.provider_code ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+query+"%")
+		argIndex++
+	}
+	if metadata != nil {
+		condition, filterArgs := metadata.SQL("p.metadata", argIndex)
+		whereConditions = append(whereConditions, condition)
+		args = append(args, filterArgs...)
+		argIndex += len(filterArgs)
+	}
 
 	// Add filters
 	if orgID, ok := opts.Filters["organization_id"]; ok {
-		searchSQL += fmt.Sprintf(" AND p.organization_id = $%d", argIndex)
+		whereConditions = append(whereConditions, fmt.Sprintf("p.organization_id = $%d", argIndex))
 		args = append(args, orgID)
 		argIndex++
 	}
 	if isActive, ok := opts.Filters["is_active"]; ok {
-		searchSQL += fmt.Sprintf(" AND p.is_active = $%d", argIndex)
+		whereConditions = append(whereConditions, fmt.Sprintf("p.is_active = $%d", argIndex))
 		args = append(args, isActive)
 		argIndex++
 	}
+	whereClause := strings.Join(whereConditions, " AND ")
+
+	// Count total results
+	var total int
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM providers p WHERE "+whereClause, args...)
+	if err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrProviderSearchFailed).
+			WithDetail("query", query).
+			WithCause(err)
+	}
 
 	// Add ordering
 	direction := "ASC"
 	if opts.Desc {
 		direction = "DESC"
 	}
-	searchSQL += fmt.Sprintf(" ORDER BY p.%s %s", opts.OrderBy, direction)
 
 	// Add pagination
 	offset := (opts.Page - 1) * opts.PageSize
-	searchSQL += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	searchSQL := fmt.Sprintf("SELECT p.* FROM providers p WHERE %s ORDER BY p.%s %s LIMIT $%d OFFSET $%d",
+		whereClause, opts.OrderBy, direction, argIndex, argIndex+1)
 	args = append(args, opts.PageSize, offset)
 
 	// Execute search query
 	var providersData []models.Provider
-	err := r.db.SelectContext(ctx, &providersData, searchSQL, args...)
-	if err != nil {
-		return nil, providers.ProvidersErrors.New(providers.ErrProviderSearchFailed).
-			WithDetail("query", query).
-			WithCause(err)
-	}
-
-	// Count total results
-	countSQL := `
-		SELECT COUNT(*) FROM providers p
-		WHERE (p.name ILIKE $1 OR p.provider_code ILIKE $1)
-	`
-	countArgs := []any{"%" + query + "%"}
-	countIndex := 2
-
-	if orgID, ok := opts.Filters["organization_id"]; ok {
-		countSQL += fmt.Sprintf(" AND p.organization_id = $%d", countIndex)
-		countArgs = append(countArgs, orgID)
-		countIndex++
-	}
-	if isActive, ok := opts.Filters["is_active"]; ok {
-		countSQL += fmt.Sprintf(" AND p.is_active = $%d", countIndex)
-		countArgs = append(countArgs, isActive)
-	}
-
-	var total int
-	err = r.db.GetContext(ctx, &total, countSQL, countArgs...)
+	err = r.db.SelectContext(ctx, &providersData, searchSQL, args...)
 	if err != nil {
 		return nil, providers.ProvidersErrors.New(providers.ErrProviderSearchFailed).
 			WithDetail("query", query).
@@ -438,6 +509,7 @@ func (r *providerRepository) buildListResponse(result storex.Paginated[models.Pr
 			OrganizationID: p.OrganizationID,
 			Name:           p.Name,
 			ProviderCode:   p.ProviderCode,
+			TaxID:          p.TaxID,
 			IsActive:       p.IsActive,
 			Metadata:       p.Metadata,
 			CreatedAt:      p.CreatedAt,
@@ -474,6 +546,7 @@ func (r *providerRepository) buildListResponseWithPagination(result storex.Pagin
 			OrganizationID: p.OrganizationID,
 			Name:           p.Name,
 			ProviderCode:   p.ProviderCode,
+			TaxID:          p.TaxID,
 			IsActive:       p.IsActive,
 			Metadata:       p.Metadata,
 			CreatedAt:      p.CreatedAt,
@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/Abraxas-365/fuckturamelo/banking/iban"
+	"github.com/Abraxas-365/fuckturamelo/concurrency"
+	"github.com/Abraxas-365/fuckturamelo/jsonfilter"
 	"github.com/Abraxas-365/fuckturamelo/providers"
 	"github.com/Abraxas-365/fuckturamelo/providers/dto"
 	"github.com/Abraxas-365/fuckturamelo/providers/models"
@@ -17,7 +21,7 @@ type ProviderService interface {
 	// Basic CRUD operations
 	CreateProvider(ctx context.Context, req *dto.CreateProviderRequest) (*dto.ProviderResponse, error)
 	GetProvider(ctx context.Context, id uuid.UUID) (*dto.ProviderResponse, error)
-	UpdateProvider(ctx context.Context, id uuid.UUID, req *dto.UpdateProviderRequest) (*dto.ProviderResponse, error)
+	UpdateProvider(ctx context.Context, id uuid.UUID, req *dto.UpdateProviderRequest, precondition *concurrency.Precondition) (*dto.ProviderResponse, error)
 	DeleteProvider(ctx context.Context, id uuid.UUID) error
 
 	// Query operations
@@ -29,17 +33,25 @@ type ProviderService interface {
 	ActivateProvider(ctx context.Context, id uuid.UUID) (*dto.ProviderResponse, error)
 	DeactivateProvider(ctx context.Context, id uuid.UUID) (*dto.ProviderResponse, error)
 	DuplicateProvider(ctx context.Context, id uuid.UUID, newName string) (*dto.ProviderResponse, error)
+
+	// Bank account operations
+	AddBankAccount(ctx context.Context, providerID uuid.UUID, req *dto.CreateBankAccountRequest) (*models.BankAccount, error)
+	ListBankAccounts(ctx context.Context, providerID uuid.UUID) ([]models.BankAccount, error)
+	SetDefaultBankAccount(ctx context.Context, providerID, accountID uuid.UUID) (*models.BankAccount, error)
+	DeleteBankAccount(ctx context.Context, providerID, accountID uuid.UUID) error
 }
 
 // providerService implements ProviderService
 type providerService struct {
-	repo postgres.ProviderRepository
+	repo     postgres.ProviderRepository
+	accounts postgres.BankAccountRepository
 }
 
 // NewProviderService creates a new provider service
-func NewProviderService(repo postgres.ProviderRepository) ProviderService {
+func NewProviderService(repo postgres.ProviderRepository, accounts postgres.BankAccountRepository) ProviderService {
 	return &providerService{
-		repo: repo,
+		repo:     repo,
+		accounts: accounts,
 	}
 }
 
@@ -69,6 +81,7 @@ func (s *providerService) CreateProvider(ctx context.Context, req *dto.CreatePro
 		OrganizationID: req.OrganizationID,
 		Name:           req.Name,
 		ProviderCode:   req.ProviderCode,
+		TaxID:          req.TaxID,
 		IsActive:       true, // Default to active
 		Metadata:       req.Metadata,
 		CreatedAt:      time.Now(),
@@ -104,8 +117,9 @@ func (s *providerService) GetProvider(ctx context.Context, id uuid.UUID) (*dto.P
 	return s.modelToResponse(provider), nil
 }
 
-// UpdateProvider updates an existing provider
-func (s *providerService) UpdateProvider(ctx context.Context, id uuid.UUID, req *dto.UpdateProviderRequest) (*dto.ProviderResponse, error) {
+// UpdateProvider updates an existing provider. A non-nil precondition must
+// match the provider's current ETag.
+func (s *providerService) UpdateProvider(ctx context.Context, id uuid.UUID, req *dto.UpdateProviderRequest, precondition *concurrency.Precondition) (*dto.ProviderResponse, error) {
 	// Validate request
 	if err := req.Validate(); err != nil {
 		return nil, providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
@@ -117,6 +131,9 @@ func (s *providerService) UpdateProvider(ctx context.Context, id uuid.UUID, req
 	if err != nil {
 		return nil, err
 	}
+	if err := precondition.Check(concurrency.TimestampETag(existing.UpdatedAt)); err != nil {
+		return nil, err
+	}
 
 	// Check if name is being changed and conflicts
 	if req.Name != nil && *req.Name != existing.Name {
@@ -143,6 +160,9 @@ func (s *providerService) UpdateProvider(ctx context.Context, id uuid.UUID, req
 	if req.ProviderCode != nil {
 		updated.ProviderCode = req.ProviderCode
 	}
+	if req.TaxID != nil {
+		updated.TaxID = req.TaxID
+	}
 	if req.IsActive != nil {
 		updated.IsActive = *req.IsActive
 	}
@@ -152,8 +172,8 @@ func (s *providerService) UpdateProvider(ctx context.Context, id uuid.UUID, req
 
 	updated.UpdatedAt = time.Now()
 
-	// Update provider
-	result, err := s.repo.Update(ctx, id, &updated)
+	// Update provider unless it changed since it was read
+	result, err := s.repo.UpdateIfUnmodified(ctx, id, &updated, existing.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -183,6 +203,19 @@ func (s *providerService) ListProviders(ctx context.Context, req *dto.ProviderLi
 			WithCause(err)
 	}
 
+	// Metadata has no schema, so any field may be filtered on
+	req.MetadataFilter = nil
+	if req.Filter != nil && strings.TrimSpace(*req.Filter) != "" {
+		filter, err := jsonfilter.Parse(*req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if err := filter.Check(nil); err != nil {
+			return nil, err
+		}
+		req.MetadataFilter = filter
+	}
+
 	return s.repo.List(ctx, req)
 }
 
@@ -226,7 +259,7 @@ func (s *providerService) ActivateProvider(ctx context.Context, id uuid.UUID) (*
 		IsActive: &[]bool{true}[0],
 	}
 
-	return s.UpdateProvider(ctx, id, req)
+	return s.UpdateProvider(ctx, id, req, nil)
 }
 
 // DeactivateProvider deactivates a provider
@@ -235,7 +268,7 @@ func (s *providerService) DeactivateProvider(ctx context.Context, id uuid.UUID)
 		IsActive: &[]bool{false}[0],
 	}
 
-	return s.UpdateProvider(ctx, id, req)
+	return s.UpdateProvider(ctx, id, req, nil)
 }
 
 // DuplicateProvider creates a copy of an existing provider with a new name
@@ -252,6 +285,7 @@ func (s *providerService) DuplicateProvider(ctx context.Context, id uuid.UUID, n
 		OrganizationID: original.OrganizationID,
 		Name:           newName,
 		ProviderCode:   original.ProviderCode,
+		TaxID:          original.TaxID,
 		IsActive:       &original.IsActive,
 		Metadata:       original.Metadata,
 	}
@@ -259,6 +293,77 @@ func (s *providerService) DuplicateProvider(ctx context.Context, id uuid.UUID, n
 	return s.CreateProvider(ctx, req)
 }
 
+// AddBankAccount adds an account the provider can be paid into. IBANs and
+// BICs are stored normalized and must pass their check digit and format
+// checks.
+func (s *providerService) AddBankAccount(ctx context.Context, providerID uuid.UUID, req *dto.CreateBankAccountRequest) (*models.BankAccount, error) {
+	if err := req.Validate(); err != nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
+			WithCause(err)
+	}
+
+	account := &models.BankAccount{
+		ID:            uuid.New(),
+		ProviderID:    providerID,
+		AccountHolder: strings.TrimSpace(req.AccountHolder),
+		BankName:      req.BankName,
+		IsDefault:     req.IsDefault,
+	}
+
+	if req.IBAN != nil && *req.IBAN != "" {
+		normalized := iban.Normalize(*req.IBAN)
+		if !iban.Valid(normalized) {
+			return nil, providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
+				WithDetail("field", "iban").
+				WithDetail("reason", "IBAN is malformed or its check digits do not match")
+		}
+		account.IBAN = &normalized
+	}
+	if req.AccountNumber != nil && strings.TrimSpace(*req.AccountNumber) != "" {
+		number := strings.TrimSpace(*req.AccountNumber)
+		account.AccountNumber = &number
+	}
+	if account.IBAN == nil && account.AccountNumber == nil {
+		return nil, providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
+			WithDetail("field", "iban").
+			WithDetail("reason", "either iban or account_number is required")
+	}
+	if req.BIC != nil && *req.BIC != "" {
+		bic := iban.Normalize(*req.BIC)
+		if !iban.ValidBIC(bic) {
+			return nil, providers.ProvidersErrors.New(providers.ErrProviderValidationFailed).
+				WithDetail("field", "bic").
+				WithDetail("reason", "BIC must have 8 or 11 characters")
+		}
+		account.BIC = &bic
+	}
+	if req.CurrencyCode != nil && *req.CurrencyCode != "" {
+		currency := strings.ToUpper(*req.CurrencyCode)
+		account.CurrencyCode = &currency
+	}
+
+	return s.accounts.Create(ctx, account)
+}
+
+// ListBankAccounts lists the accounts of a provider, default first
+func (s *providerService) ListBankAccounts(ctx context.Context, providerID uuid.UUID) ([]models.BankAccount, error) {
+	if _, err := s.repo.GetByID(ctx, providerID); err != nil {
+		return nil, err
+	}
+
+	return s.accounts.ListByProvider(ctx, providerID)
+}
+
+// SetDefaultBankAccount makes the account the one payment batches pay into
+func (s *providerService) SetDefaultBankAccount(ctx context.Context, providerID, accountID uuid.UUID) (*models.BankAccount, error) {
+	return s.accounts.SetDefault(ctx, providerID, accountID)
+}
+
+// DeleteBankAccount removes an account from a provider
+func (s *providerService) DeleteBankAccount(ctx context.Context, providerID, accountID uuid.UUID) error {
+	return s.accounts.Delete(ctx, providerID, accountID)
+}
+
 // Helper methods
 
 func (s *providerService) modelToResponse(provider *models.Provider) *dto.ProviderResponse {
@@ -268,6 +373,7 @@ func (s *providerService) modelToResponse(provider *models.Provider) *dto.Provid
 		OrganizationID: provider.OrganizationID,
 		Name:           provider.Name,
 		ProviderCode:   provider.ProviderCode,
+		TaxID:          provider.TaxID,
 		IsActive:       provider.IsActive,
 		Metadata:       provider.Metadata,
 		CreatedAt:      provider.CreatedAt,
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/recurring/models"
+	"github.com/Abraxas-365/fuckturamelo/recurring/schedule"
+)
+
+// CreateTemplateRequest represents the request payload for creating a
+// recurring invoice template. StartAt defaults to now; a start in the past
+// creates the invoices of the occurrences since then.
+type CreateTemplateRequest struct {
+	OrganizationID uuid.UUID                 `json:"organization_id" validate:"required"`
+	Name           string                    `json:"name" validate:"required,min=1,max=255"`
+	Description    *string                   `json:"description,omitempty"`
+	InvoiceTypeID  uuid.UUID                 `json:"invoice_type_id" validate:"required"`
+	ProjectID      *uuid.UUID                `json:"project_id,omitempty"`
+	ProviderID     *uuid.UUID                `json:"provider_id,omitempty"`
+	SeriesID       *uuid.UUID                `json:"series_id,omitempty"`
+	InvoiceData    invoicemodels.InvoiceData `json:"invoice_data"`
+	LineItems      models.LineItems          `json:"line_items,omitempty"`
+	DueDays        *int                      `json:"due_days,omitempty" validate:"omitempty,min=0"`
+	Rule           schedule.Rule             `json:"rule" validate:"required"`
+	StartAt        *time.Time                `json:"start_at,omitempty"`
+	EndAt          *time.Time                `json:"end_at,omitempty"`
+	MaxOccurrences *int                      `json:"max_occurrences,omitempty" validate:"omitempty,min=1"`
+	CreatedBy      *uuid.UUID                `json:"created_by,omitempty"`
+}
+
+// UpdateTemplateRequest represents the request payload for updating a
+// recurring invoice template. Changes apply to invoices not created yet;
+// changing the schedule never creates invoices for past occurrences.
+type UpdateTemplateRequest struct {
+	Name           *string                   `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description    *string                   `json:"description,omitempty"`
+	ProjectID      *uuid.UUID                `json:"project_id,omitempty"`
+	ProviderID     *uuid.UUID                `json:"provider_id,omitempty"`
+	SeriesID       *uuid.UUID                `json:"series_id,omitempty"`
+	InvoiceData    invoicemodels.InvoiceData `json:"invoice_data,omitempty"`
+	LineItems      models.LineItems          `json:"line_items,omitempty"`
+	DueDays        *int                      `json:"due_days,omitempty" validate:"omitempty,min=0"`
+	Rule           *schedule.Rule            `json:"rule,omitempty"`
+	EndAt          *time.Time                `json:"end_at,omitempty"`
+	MaxOccurrences *int                      `json:"max_occurrences,omitempty" validate:"omitempty,min=1"`
+}
+
+// TemplateListRequest represents query parameters for listing the templates
+// of an organization
+type TemplateListRequest struct {
+	OrganizationID uuid.UUID              `query:"organization_id" validate:"required"`
+	Status         *models.TemplateStatus `query:"status"`
+}
+
+// TemplateListResponse represents the response for listing templates
+type TemplateListResponse struct {
+	Templates []models.Template `json:"templates"`
+}
+
+// TemplateResponse returns a template with its upcoming occurrences
+type TemplateResponse struct {
+	*models.Template `json:",inline"`
+	Upcoming         []time.Time `json:"upcoming"`
+}
+
+// RunListResponse represents the runs of a template, latest first
+type RunListResponse struct {
+	TemplateID uuid.UUID    `json:"template_id"`
+	Runs       []models.Run `json:"runs"`
+}
@@ -0,0 +1,90 @@
+package recurring
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// RecurringErrors is the error registry for recurring invoices domain
+var RecurringErrors = errx.NewRegistry("RECURRING")
+
+// Recurring invoice error codes
+var (
+	// Template errors
+	ErrTemplateNotFound = RecurringErrors.Register(
+		"TEMPLATE_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Recurring invoice template not found",
+	)
+
+	ErrTemplateNameExists = RecurringErrors.Register(
+		"TEMPLATE_NAME_EXISTS",
+		errx.TypeConflict,
+		http.StatusConflict,
+		"Recurring invoice template with this name already exists in the organization",
+	)
+
+	ErrTemplateFailed = RecurringErrors.Register(
+		"TEMPLATE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to save recurring invoice template",
+	)
+
+	ErrInvalidRule = RecurringErrors.Register(
+		"INVALID_RULE",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Recurrence rule is invalid",
+	)
+
+	ErrInvalidStatusChange = RecurringErrors.Register(
+		"INVALID_STATUS_CHANGE",
+		errx.TypeBusiness,
+		http.StatusUnprocessableEntity,
+		"Recurring invoice template cannot change to the requested status",
+	)
+
+	// Run errors
+	ErrRunFailed = RecurringErrors.Register(
+		"RUN_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to record recurring invoice run",
+	)
+
+	// Query errors
+	ErrRecurringListFailed = RecurringErrors.Register(
+		"LIST_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to list recurring invoice templates",
+	)
+
+	// Validation errors
+	ErrRecurringValidationFailed = RecurringErrors.Register(
+		"VALIDATION_FAILED",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Recurring invoice template validation failed",
+	)
+
+	// Reference errors
+	ErrRecurringInvalidReference = RecurringErrors.Register(
+		"INVALID_REFERENCE",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Referenced invoice type, project, provider or numbering series does not exist",
+	)
+)
+
+// Helper functions for error checking
+func IsTemplateNotFound(err error) bool {
+	return errx.IsCode(err, ErrTemplateNotFound)
+}
+
+func IsInvalidRule(err error) bool {
+	return errx.IsCode(err, ErrInvalidRule)
+}
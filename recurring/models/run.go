@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunStatus is the outcome of an occurrence of a template
+type RunStatus string
+
+// Run statuses. Failed runs are not retried; the schedule moves on.
+const (
+	RunCreated RunStatus = "created"
+	RunFailed  RunStatus = "failed"
+)
+
+// Run records what an occurrence of a template produced. There is at most
+// one run per template and occurrence.
+type Run struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	TemplateID   uuid.UUID  `db:"template_id" json:"template_id"`
+	ScheduledFor time.Time  `db:"scheduled_for" json:"scheduled_for"`
+	Status       RunStatus  `db:"status" json:"status"`
+	InvoiceID    *uuid.UUID `db:"invoice_id" json:"invoice_id,omitempty"`
+	Error        *string    `db:"error" json:"error,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TableName returns the table name for the Run model
+func (r Run) TableName() string {
+	return "recurring_invoice_runs"
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	invoicedto "github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/recurring/schedule"
+)
+
+// TemplateStatus is where a recurring invoice template is in its lifecycle
+type TemplateStatus string
+
+// Template statuses. Only active templates have a next run; completed
+// templates reached their end date or occurrence limit.
+const (
+	TemplateActive    TemplateStatus = "active"
+	TemplatePaused    TemplateStatus = "paused"
+	TemplateCompleted TemplateStatus = "completed"
+)
+
+// LineItems are the invoice lines copied into every invoice of a template,
+// stored as JSONB
+type LineItems []invoicedto.LineItemRequest
+
+// Value implements the driver.Valuer interface for database storage
+func (l LineItems) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (l *LineItems) Scan(value any) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into LineItems", value)
+	}
+}
+
+// Template produces an invoice of an invoice type, project and provider at
+// every occurrence of its rule, from StartAt until EndAt or MaxOccurrences
+// invoices. The invoice_date of each invoice is the occurrence's date in the
+// rule's timezone, and due_date follows DueDays later when set.
+type Template struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID uuid.UUID  `db:"organization_id" json:"organization_id"`
+	Name           string     `db:"name" json:"name"`
+	Description    *string    `db:"description" json:"description,omitempty"`
+	InvoiceTypeID  uuid.UUID  `db:"invoice_type_id" json:"invoice_type_id"`
+	ProjectID      *uuid.UUID `db:"project_id" json:"project_id"`
+	ProviderID     *uuid.UUID `db:"provider_id" json:"provider_id"`
+	SeriesID       *uuid.UUID `db:"series_id" json:"series_id"`
+
+	// Invoice contents
+	InvoiceData invoicemodels.InvoiceData `db:"invoice_data" json:"invoice_data"`
+	LineItems   LineItems                 `db:"line_items" json:"line_items"`
+	DueDays     *int                      `db:"due_days" json:"due_days,omitempty"`
+
+	// Schedule
+	Rule           schedule.Rule  `db:"rule" json:"rule"`
+	StartAt        time.Time      `db:"start_at" json:"start_at"`
+	EndAt          *time.Time     `db:"end_at" json:"end_at,omitempty"`
+	MaxOccurrences *int           `db:"max_occurrences" json:"max_occurrences,omitempty"`
+	Status         TemplateStatus `db:"status" json:"status"`
+
+	// Progress, maintained by the scheduler
+	OccurrenceCount int        `db:"occurrence_count" json:"occurrence_count"`
+	NextRunAt       *time.Time `db:"next_run_at" json:"next_run_at"`
+	LastRunAt       *time.Time `db:"last_run_at" json:"last_run_at,omitempty"`
+	LockedUntil     *time.Time `db:"locked_until" json:"-"`
+
+	// Audit fields
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name for the Template model
+func (t Template) TableName() string {
+	return "recurring_invoice_templates"
+}
+
+// Schedule compiles the template's rule anchored at its start
+func (t *Template) Schedule() (*schedule.Schedule, error) {
+	return t.Rule.Compile(t.StartAt)
+}
+
+// NextOccurrence returns the first occurrence after the given time, or nil
+// once the template's end date or occurrence limit is reached
+func (t *Template) NextOccurrence(s *schedule.Schedule, after time.Time) *time.Time {
+	if t.MaxOccurrences != nil && t.OccurrenceCount >= *t.MaxOccurrences {
+		return nil
+	}
+	next := s.Next(after)
+	if next.IsZero() || (t.EndAt != nil && next.After(*t.EndAt)) {
+		return nil
+	}
+	return &next
+}
+
+// InvoiceID returns the ID of the invoice the template produces for an
+// occurrence. It is derived from the template and the occurrence, so an
+// occurrence retried after a crash finds the invoice it already created.
+func (t *Template) InvoiceID(scheduledFor time.Time) uuid.UUID {
+	return uuid.NewSHA1(t.ID, []byte(scheduledFor.UTC().Format(time.RFC3339)))
+}
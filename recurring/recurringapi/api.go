@@ -0,0 +1,277 @@
+package recurringapi
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices/invoicesrv"
+	invoicespg "github.com/Abraxas-365/fuckturamelo/invoices/repository"
+	"github.com/Abraxas-365/fuckturamelo/recurring"
+	"github.com/Abraxas-365/fuckturamelo/recurring/dto"
+	"github.com/Abraxas-365/fuckturamelo/recurring/models"
+	"github.com/Abraxas-365/fuckturamelo/recurring/recurringsrv"
+	postgres "github.com/Abraxas-365/fuckturamelo/recurring/repository"
+)
+
+// RecurringAPI contains the complete API setup for the recurring invoices
+// domain
+type RecurringAPI struct {
+	service   recurringsrv.RecurringService
+	repo      postgres.RecurringRepository
+	scheduler *recurringsrv.Scheduler
+}
+
+// Config contains configuration for the recurring invoices API
+type Config struct {
+	DB *sqlx.DB
+
+	// Invoices creates the invoices of templates
+	Invoices invoicesrv.InvoiceService
+
+	// Scheduler tunes the background scheduler; zero values take defaults
+	Scheduler recurringsrv.SchedulerOptions
+}
+
+// New creates a new RecurringAPI instance. The scheduler is not started.
+func New(config Config) (*RecurringAPI, error) {
+	if config.DB == nil {
+		return nil, recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("error", "Database connection is required")
+	}
+	if config.Invoices == nil {
+		return nil, recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("error", "Invoice service is required")
+	}
+
+	// Initialize layers from bottom up
+	repo := postgres.NewRecurringRepository(config.DB)
+	svc := recurringsrv.NewRecurringService(repo)
+	scheduler := recurringsrv.NewScheduler(repo, config.Invoices, invoicespg.NewInvoiceRepository(config.DB), config.Scheduler)
+
+	return &RecurringAPI{
+		service:   svc,
+		repo:      repo,
+		scheduler: scheduler,
+	}, nil
+}
+
+// SetupRoutes registers all recurring invoice template routes with the
+// given Fiber router group
+func (api *RecurringAPI) SetupRoutes(router fiber.Router) {
+	// Health check route
+	router.Get("/health", api.healthCheck)
+
+	// Basic CRUD routes
+	router.Post("/", api.createTemplate)
+	router.Get("/", api.listTemplates)
+	router.Get("/:id", api.getTemplate)
+	router.Put("/:id", api.updateTemplate)
+	router.Delete("/:id", api.deleteTemplate)
+
+	// Lifecycle routes
+	router.Post("/:id/pause", api.pauseTemplate)
+	router.Post("/:id/resume", api.resumeTemplate)
+
+	// Run routes
+	router.Get("/:id/runs", api.listRuns)
+}
+
+// GetService returns the service layer for dependency injection
+func (api *RecurringAPI) GetService() recurringsrv.RecurringService {
+	return api.service
+}
+
+// GetRepository returns the repository layer for dependency injection
+func (api *RecurringAPI) GetRepository() postgres.RecurringRepository {
+	return api.repo
+}
+
+// GetScheduler returns the scheduler that materializes due templates
+func (api *RecurringAPI) GetScheduler() *recurringsrv.Scheduler {
+	return api.scheduler
+}
+
+// createTemplate handles POST /recurring-invoices
+func (api *RecurringAPI) createTemplate(c *fiber.Ctx) error {
+	var req dto.CreateTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.CreateTemplate(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listTemplates handles GET /recurring-invoices?organization_id=&status=
+func (api *RecurringAPI) listTemplates(c *fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Query("organization_id"))
+	if err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("error", "Organization ID parameter is required and must be a UUID").
+			WithCause(err)
+	}
+
+	req := dto.TemplateListRequest{OrganizationID: orgID}
+	if status := c.Query("status"); status != "" {
+		templateStatus := models.TemplateStatus(status)
+		req.Status = &templateStatus
+	}
+
+	result, err := api.service.ListTemplates(c.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// getTemplate handles GET /recurring-invoices/:id
+func (api *RecurringAPI) getTemplate(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.GetTemplate(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// updateTemplate handles PUT /recurring-invoices/:id
+func (api *RecurringAPI) updateTemplate(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("error", "Invalid JSON in request body").
+			WithCause(err)
+	}
+
+	result, err := api.service.UpdateTemplate(c.Context(), id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// deleteTemplate handles DELETE /recurring-invoices/:id
+func (api *RecurringAPI) deleteTemplate(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := api.service.DeleteTemplate(c.Context(), id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// pauseTemplate handles POST /recurring-invoices/:id/pause
+func (api *RecurringAPI) pauseTemplate(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.PauseTemplate(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// resumeTemplate handles POST /recurring-invoices/:id/resume
+func (api *RecurringAPI) resumeTemplate(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ResumeTemplate(c.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// listRuns handles GET /recurring-invoices/:id/runs?limit=
+func (api *RecurringAPI) listRuns(c *fiber.Ctx) error {
+	id, err := api.parseUUIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	result, err := api.service.ListRuns(c.Context(), id, c.QueryInt("limit", recurringsrv.DefaultRunListLimit))
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// healthCheck provides a health check endpoint
+func (api *RecurringAPI) healthCheck(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "healthy",
+		"service": "recurring-invoices",
+	})
+}
+
+// Helper methods
+
+func (api *RecurringAPI) parseUUIDParam(c *fiber.Ctx, paramName string) (uuid.UUID, error) {
+	paramValue := c.Params(paramName)
+	if paramValue == "" {
+		return uuid.Nil, recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("error", "Missing required parameter: "+paramName)
+	}
+
+	id, err := uuid.Parse(paramValue)
+	if err != nil {
+		return uuid.Nil, recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("error", "Invalid UUID format for parameter: "+paramName).
+			WithCause(err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,298 @@
+package recurringsrv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Abraxas-365/craftable/errx"
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices"
+	invoicedto "github.com/Abraxas-365/fuckturamelo/invoices/dto"
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/recurring/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/recurring/repository"
+	"github.com/Abraxas-365/fuckturamelo/recurring/schedule"
+)
+
+// Scheduler defaults
+const (
+	DefaultPollInterval = time.Minute
+	DefaultLease        = 5 * time.Minute
+	DefaultBatchSize    = 20
+	DefaultMaxCatchUp   = 100
+)
+
+// SchedulerOptions configures the recurring invoice scheduler
+type SchedulerOptions struct {
+	// PollInterval is the time between looks for due templates
+	PollInterval time.Duration
+
+	// Lease is how long a claimed template is reserved for this process;
+	// the templates of a crashed process are picked up once it expires
+	Lease time.Duration
+
+	// BatchSize is the number of templates claimed per poll
+	BatchSize int
+
+	// MaxCatchUp is the number of occurrences of a template materialized
+	// per poll, so a long backlog does not hold up other templates
+	MaxCatchUp int
+}
+
+// InvoiceCreator creates the invoices of templates (implemented by
+// invoicesrv.InvoiceService)
+type InvoiceCreator interface {
+	CreateInvoice(ctx context.Context, req *invoicedto.CreateInvoiceRequest) (*invoicedto.InvoiceResponse, error)
+}
+
+// InvoiceLookup finds invoices, soft deleted ones included (implemented by
+// invoices/repository.InvoiceRepository)
+type InvoiceLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*invoicemodels.Invoice, error)
+}
+
+// Scheduler materializes the due occurrences of active templates in a
+// background goroutine. Occurrences missed while the server was down are
+// created on the next polls, oldest first.
+//
+// An occurrence creates its invoice with an ID derived from the template and
+// the occurrence, and then records a run. If the process stops in between,
+// the retried occurrence finds the invoice instead of creating another.
+type Scheduler struct {
+	repo     postgres.RecurringRepository
+	invoices InvoiceCreator
+	lookup   InvoiceLookup
+	options  SchedulerOptions
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScheduler creates a scheduler; zero options take the defaults
+func NewScheduler(repo postgres.RecurringRepository, invoices InvoiceCreator, lookup InvoiceLookup, options SchedulerOptions) *Scheduler {
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	if options.Lease <= 0 {
+		options.Lease = DefaultLease
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	if options.MaxCatchUp <= 0 {
+		options.MaxCatchUp = DefaultMaxCatchUp
+	}
+
+	return &Scheduler{
+		repo:     repo,
+		invoices: invoices,
+		lookup:   lookup,
+		options:  options,
+	}
+}
+
+// Start polls for due templates until Stop is called. The first poll runs
+// right away to catch up after downtime.
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.options.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.poll(ctx, time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for the current poll to finish
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// poll claims a batch of due templates and materializes their occurrences
+// up to now
+func (s *Scheduler) poll(ctx context.Context, now time.Time) {
+	templates, err := s.repo.ClaimDue(ctx, now, s.options.Lease, s.options.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("recurring scheduler: %v", err)
+		}
+		return
+	}
+
+	for i := range templates {
+		s.process(ctx, &templates[i], now)
+	}
+}
+
+// process materializes the due occurrences of a claimed template, recording
+// each before moving to the next, and releases the template
+func (s *Scheduler) process(ctx context.Context, template *models.Template, now time.Time) {
+	defer func() {
+		if err := s.repo.Release(context.WithoutCancel(ctx), template.ID); err != nil {
+			log.Printf("recurring template %s: %v", template.ID, err)
+		}
+	}()
+
+	sched, err := template.Schedule()
+	if err != nil {
+		log.Printf("recurring template %s: invalid rule: %v", template.ID, err)
+		return
+	}
+
+	for range s.options.MaxCatchUp {
+		if ctx.Err() != nil || template.NextRunAt == nil || template.NextRunAt.After(now) {
+			return
+		}
+		scheduledFor := *template.NextRunAt
+
+		run, counted, err := s.materialize(ctx, template, sched, scheduledFor)
+		if err != nil {
+			// Transient failures leave the occurrence due for the next poll
+			if ctx.Err() == nil {
+				log.Printf("recurring template %s: occurrence %s: %v", template.ID, scheduledFor.Format(time.RFC3339), err)
+			}
+			return
+		}
+
+		if counted {
+			template.OccurrenceCount++
+		}
+		template.LastRunAt = &scheduledFor
+		template.NextRunAt = template.NextOccurrence(sched, scheduledFor)
+		if template.NextRunAt == nil {
+			template.Status = models.TemplateCompleted
+		}
+
+		advanced, err := s.repo.RecordRun(ctx, run, template, scheduledFor)
+		if err != nil {
+			log.Printf("recurring template %s: occurrence %s: %v", template.ID, scheduledFor.Format(time.RFC3339), err)
+			return
+		}
+		if !advanced {
+			// The template was paused or rescheduled meanwhile
+			return
+		}
+	}
+}
+
+// materialize creates the invoice of an occurrence unless it already
+// exists. Invoices the invoice service rejects give a failed run; counted
+// is false for occurrences that already had a run.
+func (s *Scheduler) materialize(ctx context.Context, template *models.Template, sched *schedule.Schedule, scheduledFor time.Time) (*models.Run, bool, error) {
+	existing, err := s.repo.GetRun(ctx, template.ID, scheduledFor)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	run := &models.Run{
+		ID:           uuid.New(),
+		TemplateID:   template.ID,
+		ScheduledFor: scheduledFor,
+		Status:       models.RunCreated,
+		CreatedAt:    time.Now(),
+	}
+
+	invoiceID := template.InvoiceID(scheduledFor)
+	invoice, err := s.lookup.GetByID(ctx, invoiceID)
+	switch {
+	case err == nil:
+		run.InvoiceID = &invoice.ID
+		return run, true, nil
+	case !invoices.IsInvoiceNotFound(err):
+		return nil, false, err
+	}
+
+	fail := func(err error) (*models.Run, bool, error) {
+		reason := err.Error()
+		run.Status = models.RunFailed
+		run.Error = &reason
+		return run, true, nil
+	}
+
+	req, err := invoiceRequest(template, invoiceID, scheduledFor.In(sched.Location()))
+	if err != nil {
+		return fail(err)
+	}
+	created, err := s.invoices.CreateInvoice(ctx, req)
+	if err != nil {
+		if transient(err) {
+			return nil, false, err
+		}
+		return fail(err)
+	}
+
+	run.InvoiceID = &created.Invoice.ID
+	return run, true, nil
+}
+
+// invoiceRequest builds the invoice of an occurrence, dated on the
+// occurrence's day in the rule's timezone
+func invoiceRequest(template *models.Template, invoiceID uuid.UUID, occurrence time.Time) (*invoicedto.CreateInvoiceRequest, error) {
+	// The invoice service fills in the payload, so every invoice gets a copy
+	raw, err := json.Marshal(template.InvoiceData)
+	if err != nil {
+		return nil, err
+	}
+	var data invoicemodels.InvoiceData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = make(invoicemodels.InvoiceData)
+	}
+
+	data[invoicemodels.FieldInvoiceDate] = occurrence.Format("2006-01-02")
+	if template.DueDays != nil {
+		data[invoicemodels.FieldDueDate] = occurrence.AddDate(0, 0, *template.DueDays).Format("2006-01-02")
+	}
+
+	return &invoicedto.CreateInvoiceRequest{
+		ID:             invoiceID,
+		InvoiceTypeID:  template.InvoiceTypeID,
+		OrganizationID: template.OrganizationID,
+		ProjectID:      template.ProjectID,
+		ProviderID:     template.ProviderID,
+		InvoiceData:    data,
+		LineItems:      append([]invoicedto.LineItemRequest(nil), template.LineItems...),
+		SeriesID:       template.SeriesID,
+		CreatedBy:      template.CreatedBy,
+	}, nil
+}
+
+// transient reports whether an error may go away on retry, as opposed to
+// the invoice being rejected
+func transient(err error) bool {
+	var e *errx.Error
+	if !errors.As(err, &e) {
+		return true
+	}
+	switch e.Type {
+	case errx.TypeInternal, errx.TypeSystem, errx.TypeTimeout, errx.TypeUnavailable, errx.TypeExternal:
+		return true
+	}
+	return false
+}
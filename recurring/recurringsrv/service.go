@@ -0,0 +1,372 @@
+package recurringsrv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	invoicemodels "github.com/Abraxas-365/fuckturamelo/invoices/models"
+	"github.com/Abraxas-365/fuckturamelo/recurring"
+	"github.com/Abraxas-365/fuckturamelo/recurring/dto"
+	"github.com/Abraxas-365/fuckturamelo/recurring/models"
+	postgres "github.com/Abraxas-365/fuckturamelo/recurring/repository"
+)
+
+const (
+	// UpcomingCount is the number of upcoming occurrences returned with a
+	// template
+	UpcomingCount = 5
+
+	// DefaultRunListLimit and MaxRunListLimit bound the runs listed per
+	// request
+	DefaultRunListLimit = 50
+	MaxRunListLimit     = 500
+)
+
+// RecurringService defines the interface for recurring invoice template
+// business logic
+type RecurringService interface {
+	// Template operations
+	CreateTemplate(ctx context.Context, req *dto.CreateTemplateRequest) (*dto.TemplateResponse, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (*dto.TemplateResponse, error)
+	ListTemplates(ctx context.Context, req *dto.TemplateListRequest) (*dto.TemplateListResponse, error)
+	UpdateTemplate(ctx context.Context, id uuid.UUID, req *dto.UpdateTemplateRequest) (*dto.TemplateResponse, error)
+	DeleteTemplate(ctx context.Context, id uuid.UUID) error
+
+	// Lifecycle operations
+	PauseTemplate(ctx context.Context, id uuid.UUID) (*dto.TemplateResponse, error)
+	ResumeTemplate(ctx context.Context, id uuid.UUID) (*dto.TemplateResponse, error)
+
+	// Run operations
+	ListRuns(ctx context.Context, id uuid.UUID, limit int) (*dto.RunListResponse, error)
+}
+
+// recurringService implements RecurringService
+type recurringService struct {
+	repo postgres.RecurringRepository
+}
+
+// NewRecurringService creates a new recurring invoice template service
+func NewRecurringService(repo postgres.RecurringRepository) RecurringService {
+	return &recurringService{
+		repo: repo,
+	}
+}
+
+// Template operations
+
+// CreateTemplate creates an active template. Its first occurrence is the
+// first one at or after its start.
+func (s *recurringService) CreateTemplate(ctx context.Context, req *dto.CreateTemplateRequest) (*dto.TemplateResponse, error) {
+	if req.OrganizationID == uuid.Nil {
+		return nil, validationError("organization_id", "required")
+	}
+	if req.Name == "" {
+		return nil, validationError("name", "required")
+	}
+	if req.InvoiceTypeID == uuid.Nil {
+		return nil, validationError("invoice_type_id", "required")
+	}
+
+	now := time.Now()
+	template := &models.Template{
+		ID:             uuid.New(),
+		OrganizationID: req.OrganizationID,
+		Name:           req.Name,
+		Description:    req.Description,
+		InvoiceTypeID:  req.InvoiceTypeID,
+		ProjectID:      req.ProjectID,
+		ProviderID:     req.ProviderID,
+		SeriesID:       req.SeriesID,
+		InvoiceData:    req.InvoiceData,
+		LineItems:      req.LineItems,
+		DueDays:        req.DueDays,
+		Rule:           req.Rule,
+		StartAt:        now,
+		EndAt:          req.EndAt,
+		MaxOccurrences: req.MaxOccurrences,
+		CreatedBy:      req.CreatedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if req.StartAt != nil {
+		template.StartAt = *req.StartAt
+	}
+	if template.InvoiceData == nil {
+		template.InvoiceData = make(invoicemodels.InvoiceData)
+	}
+
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+	if err := scheduleNext(template, time.Time{}); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateResponse(created), nil
+}
+
+// GetTemplate retrieves a template with its upcoming occurrences
+func (s *recurringService) GetTemplate(ctx context.Context, id uuid.UUID) (*dto.TemplateResponse, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateResponse(template), nil
+}
+
+// ListTemplates lists the templates of an organization
+func (s *recurringService) ListTemplates(ctx context.Context, req *dto.TemplateListRequest) (*dto.TemplateListResponse, error) {
+	if req.OrganizationID == uuid.Nil {
+		return nil, validationError("organization_id", "required")
+	}
+	if req.Status != nil {
+		switch *req.Status {
+		case models.TemplateActive, models.TemplatePaused, models.TemplateCompleted:
+		default:
+			return nil, validationError("status", "must be active, paused or completed")
+		}
+	}
+
+	templates, err := s.repo.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TemplateListResponse{Templates: templates}, nil
+}
+
+// UpdateTemplate changes a template. A new rule or end condition takes
+// effect from now: occurrences already due are not created under it.
+func (s *recurringService) UpdateTemplate(ctx context.Context, id uuid.UUID, req *dto.UpdateTemplateRequest) (*dto.TemplateResponse, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, validationError("name", "required")
+		}
+		template.Name = *req.Name
+	}
+	if req.Description != nil {
+		template.Description = req.Description
+	}
+	if req.ProjectID != nil {
+		template.ProjectID = req.ProjectID
+	}
+	if req.ProviderID != nil {
+		template.ProviderID = req.ProviderID
+	}
+	if req.SeriesID != nil {
+		template.SeriesID = req.SeriesID
+	}
+	if req.InvoiceData != nil {
+		template.InvoiceData = req.InvoiceData
+	}
+	if req.LineItems != nil {
+		template.LineItems = req.LineItems
+	}
+	if req.DueDays != nil {
+		template.DueDays = req.DueDays
+	}
+
+	rescheduled := req.Rule != nil || req.EndAt != nil || req.MaxOccurrences != nil
+	if req.Rule != nil {
+		template.Rule = *req.Rule
+	}
+	if req.EndAt != nil {
+		template.EndAt = req.EndAt
+	}
+	if req.MaxOccurrences != nil {
+		template.MaxOccurrences = req.MaxOccurrences
+	}
+
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+	if rescheduled && template.Status != models.TemplatePaused {
+		if err := scheduleNext(template, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.Update(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateResponse(updated), nil
+}
+
+// DeleteTemplate removes a template; the invoices it created are kept
+func (s *recurringService) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Lifecycle operations
+
+// PauseTemplate stops an active template from creating invoices
+func (s *recurringService) PauseTemplate(ctx context.Context, id uuid.UUID) (*dto.TemplateResponse, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Status != models.TemplateActive {
+		return nil, statusChangeError(template, models.TemplatePaused)
+	}
+
+	template.Status = models.TemplatePaused
+	template.NextRunAt = nil
+
+	updated, err := s.repo.Update(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateResponse(updated), nil
+}
+
+// ResumeTemplate reactivates a paused template. Occurrences that fell in
+// the pause are skipped; the template completes instead if none are left.
+func (s *recurringService) ResumeTemplate(ctx context.Context, id uuid.UUID) (*dto.TemplateResponse, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Status != models.TemplatePaused {
+		return nil, statusChangeError(template, models.TemplateActive)
+	}
+
+	if err := scheduleNext(template, time.Now()); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateResponse(updated), nil
+}
+
+// Run operations
+
+// ListRuns lists the latest runs of a template
+func (s *recurringService) ListRuns(ctx context.Context, id uuid.UUID, limit int) (*dto.RunListResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultRunListLimit
+	}
+	if limit > MaxRunListLimit {
+		limit = MaxRunListLimit
+	}
+
+	runs, err := s.repo.ListRuns(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RunListResponse{TemplateID: id, Runs: runs}, nil
+}
+
+// Helper functions
+
+// validateTemplate checks the contents and schedule of a template
+func validateTemplate(template *models.Template) error {
+	// Every invoice needs its own number, which only a series can give
+	if raw, ok := template.InvoiceData[invoicemodels.FieldInvoiceNumber]; ok && raw != nil {
+		return validationError("invoice_data."+invoicemodels.FieldInvoiceNumber, "numbers of recurring invoices come from series_id")
+	}
+
+	for i, line := range template.LineItems {
+		if line.Description == "" {
+			return validationError("line_items", fmt.Sprintf("line %d: description is required", i))
+		}
+		if !line.Quantity.IsPositive() {
+			return validationError("line_items", fmt.Sprintf("line %d: quantity must be positive", i))
+		}
+	}
+
+	if template.DueDays != nil && *template.DueDays < 0 {
+		return validationError("due_days", "must not be negative")
+	}
+	if template.MaxOccurrences != nil && *template.MaxOccurrences < 1 {
+		return validationError("max_occurrences", "must be at least 1")
+	}
+	if template.EndAt != nil && template.EndAt.Before(template.StartAt) {
+		return validationError("end_at", "must not be before start_at")
+	}
+
+	if _, err := template.Schedule(); err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrInvalidRule).
+			WithDetail("reason", err.Error())
+	}
+
+	return nil
+}
+
+// scheduleNext sets the next run of a template to its first occurrence
+// after the given time, completing the template when none is left
+func scheduleNext(template *models.Template, after time.Time) error {
+	sched, err := template.Schedule()
+	if err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrInvalidRule).
+			WithDetail("reason", err.Error())
+	}
+
+	template.NextRunAt = template.NextOccurrence(sched, after)
+	template.Status = models.TemplateActive
+	if template.NextRunAt == nil {
+		template.Status = models.TemplateCompleted
+	}
+	return nil
+}
+
+// templateResponse returns a template with its upcoming occurrences
+func templateResponse(template *models.Template) *dto.TemplateResponse {
+	response := &dto.TemplateResponse{Template: template, Upcoming: []time.Time{}}
+	if template.NextRunAt == nil {
+		return response
+	}
+
+	sched, err := template.Schedule()
+	if err != nil {
+		return response
+	}
+
+	// Follow the template's end conditions from its next run on
+	upcoming := *template
+	next := template.NextRunAt
+	for next != nil && len(response.Upcoming) < UpcomingCount {
+		response.Upcoming = append(response.Upcoming, *next)
+		upcoming.OccurrenceCount++
+		next = upcoming.NextOccurrence(sched, *next)
+	}
+	return response
+}
+
+func validationError(field, reason string) error {
+	return recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+		WithDetail("field", field).
+		WithDetail("reason", reason)
+}
+
+func statusChangeError(template *models.Template, to models.TemplateStatus) error {
+	return recurring.RecurringErrors.New(recurring.ErrInvalidStatusChange).
+		WithDetail("id", template.ID.String()).
+		WithDetail("from_status", template.Status).
+		WithDetail("to_status", to)
+}
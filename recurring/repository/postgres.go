@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/Abraxas-365/fuckturamelo/recurring"
+	"github.com/Abraxas-365/fuckturamelo/recurring/dto"
+	"github.com/Abraxas-365/fuckturamelo/recurring/models"
+)
+
+// recurringRepository implements RecurringRepository using sqlx
+type recurringRepository struct {
+	db *sqlx.DB
+}
+
+// NewRecurringRepository creates a new recurring invoice template repository
+func NewRecurringRepository(db *sqlx.DB) RecurringRepository {
+	return &recurringRepository{
+		db: db,
+	}
+}
+
+// Create saves a recurring invoice template
+func (r *recurringRepository) Create(ctx context.Context, template *models.Template) (*models.Template, error) {
+	var created models.Template
+	err := r.db.GetContext(ctx, &created, `
+		INSERT INTO recurring_invoice_templates
+			(id, organization_id, name, description, invoice_type_id, project_id, provider_id, series_id,
+			 invoice_data, line_items, due_days, rule, start_at, end_at, max_occurrences, status,
+			 next_run_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING *`,
+		template.ID, template.OrganizationID, template.Name, template.Description, template.InvoiceTypeID,
+		template.ProjectID, template.ProviderID, template.SeriesID, template.InvoiceData, template.LineItems,
+		template.DueDays, template.Rule, template.StartAt, template.EndAt, template.MaxOccurrences,
+		template.Status, template.NextRunAt, template.CreatedBy)
+	if err != nil {
+		return nil, templateError(err, template)
+	}
+
+	return &created, nil
+}
+
+// GetByID retrieves a template by ID
+func (r *recurringRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Template, error) {
+	var template models.Template
+	err := r.db.GetContext(ctx, &template, `SELECT * FROM recurring_invoice_templates WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, recurring.RecurringErrors.New(recurring.ErrTemplateNotFound).
+				WithDetail("id", id.String())
+		}
+		return nil, recurring.RecurringErrors.New(recurring.ErrRecurringListFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	return &template, nil
+}
+
+// List lists the templates of an organization by name
+func (r *recurringRepository) List(ctx context.Context, req *dto.TemplateListRequest) ([]models.Template, error) {
+	templates := []models.Template{}
+	err := r.db.SelectContext(ctx, &templates, `
+		SELECT * FROM recurring_invoice_templates
+		WHERE organization_id = $1 AND ($2::text IS NULL OR status = $2)
+		ORDER BY name`,
+		req.OrganizationID, req.Status)
+	if err != nil {
+		return nil, recurring.RecurringErrors.New(recurring.ErrRecurringListFailed).
+			WithDetail("organization_id", req.OrganizationID.String()).
+			WithCause(err)
+	}
+
+	return templates, nil
+}
+
+// Update stores the editable fields, status and next run of a template. The
+// scheduler's progress is left alone.
+func (r *recurringRepository) Update(ctx context.Context, template *models.Template) (*models.Template, error) {
+	var updated models.Template
+	err := r.db.GetContext(ctx, &updated, `
+		UPDATE recurring_invoice_templates
+		SET name = $2, description = $3, project_id = $4, provider_id = $5, series_id = $6,
+			invoice_data = $7, line_items = $8, due_days = $9, rule = $10, end_at = $11,
+			max_occurrences = $12, status = $13, next_run_at = $14
+		WHERE id = $1
+		RETURNING *`,
+		template.ID, template.Name, template.Description, template.ProjectID, template.ProviderID,
+		template.SeriesID, template.InvoiceData, template.LineItems, template.DueDays, template.Rule,
+		template.EndAt, template.MaxOccurrences, template.Status, template.NextRunAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, recurring.RecurringErrors.New(recurring.ErrTemplateNotFound).
+				WithDetail("id", template.ID.String())
+		}
+		return nil, templateError(err, template)
+	}
+
+	return &updated, nil
+}
+
+// Delete removes a template with its runs. Invoices it created are kept.
+func (r *recurringRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM recurring_invoice_templates WHERE id = $1`, id)
+	if err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrTemplateFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrTemplateFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+	if affected == 0 {
+		return recurring.RecurringErrors.New(recurring.ErrTemplateNotFound).
+			WithDetail("id", id.String())
+	}
+
+	return nil
+}
+
+// ListRuns lists the latest runs of a template
+func (r *recurringRepository) ListRuns(ctx context.Context, templateID uuid.UUID, limit int) ([]models.Run, error) {
+	runs := []models.Run{}
+	err := r.db.SelectContext(ctx, &runs, `
+		SELECT * FROM recurring_invoice_runs
+		WHERE template_id = $1
+		ORDER BY scheduled_for DESC
+		LIMIT $2`,
+		templateID, limit)
+	if err != nil {
+		return nil, recurring.RecurringErrors.New(recurring.ErrRecurringListFailed).
+			WithDetail("template_id", templateID.String()).
+			WithCause(err)
+	}
+
+	return runs, nil
+}
+
+// GetRun returns the run of an occurrence, or nil if it has not run
+func (r *recurringRepository) GetRun(ctx context.Context, templateID uuid.UUID, scheduledFor time.Time) (*models.Run, error) {
+	var run models.Run
+	err := r.db.GetContext(ctx, &run, `
+		SELECT * FROM recurring_invoice_runs WHERE template_id = $1 AND scheduled_for = $2`,
+		templateID, scheduledFor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, recurring.RecurringErrors.New(recurring.ErrRecurringListFailed).
+			WithDetail("template_id", templateID.String()).
+			WithCause(err)
+	}
+
+	return &run, nil
+}
+
+// ClaimDue leases up to limit active templates whose next run is due, so
+// concurrent schedulers never process the same template. Leases of crashed
+// schedulers expire after the lease duration.
+func (r *recurringRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Template, error) {
+	templates := []models.Template{}
+	err := r.db.SelectContext(ctx, &templates, `
+		UPDATE recurring_invoice_templates
+		SET locked_until = $2
+		WHERE id IN (
+			SELECT id FROM recurring_invoice_templates
+			WHERE status = 'active' AND next_run_at <= $1
+				AND (locked_until IS NULL OR locked_until <= $1)
+			ORDER BY next_run_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, recurring.RecurringErrors.New(recurring.ErrRecurringListFailed).
+			WithDetail("reason", "claim_due_templates").
+			WithCause(err)
+	}
+
+	return templates, nil
+}
+
+// RecordRun records the outcome of an occurrence and moves the template to
+// its next run, occurrence count and status. The template only advances if
+// its next run is still the recorded occurrence; false reports that it was
+// changed meanwhile. An occurrence that already has a run keeps it.
+func (r *recurringRepository) RecordRun(ctx context.Context, run *models.Run, template *models.Template, scheduledFor time.Time) (bool, error) {
+	runError := func(err error) error {
+		return recurring.RecurringErrors.New(recurring.ErrRunFailed).
+			WithDetail("template_id", template.ID.String()).
+			WithDetail("scheduled_for", scheduledFor).
+			WithCause(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, runError(err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO recurring_invoice_runs (id, template_id, scheduled_for, status, invoice_id, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT ON CONSTRAINT recurring_invoice_runs_occurrence_unique DO NOTHING`,
+		run.ID, run.TemplateID, run.ScheduledFor, run.Status, run.InvoiceID, run.Error)
+	if err != nil {
+		return false, runError(err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE recurring_invoice_templates
+		SET occurrence_count = $3, next_run_at = $4, last_run_at = $5, status = $6
+		WHERE id = $1 AND status = 'active' AND next_run_at = $2`,
+		template.ID, scheduledFor, template.OccurrenceCount, template.NextRunAt, template.LastRunAt, template.Status)
+	if err != nil {
+		return false, runError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, runError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, runError(err)
+	}
+
+	return affected > 0, nil
+}
+
+// Release gives up the scheduler's lease on a template
+func (r *recurringRepository) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE recurring_invoice_templates SET locked_until = NULL WHERE id = $1`, id)
+	if err != nil {
+		return recurring.RecurringErrors.New(recurring.ErrTemplateFailed).
+			WithDetail("id", id.String()).
+			WithCause(err)
+	}
+
+	return nil
+}
+
+// templateError maps constraint violations of template writes
+func templateError(err error, template *models.Template) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "recurring_invoice_templates_name_unique"):
+		return recurring.RecurringErrors.New(recurring.ErrTemplateNameExists).
+			WithDetail("name", template.Name).
+			WithDetail("organization_id", template.OrganizationID.String()).
+			WithCause(err)
+	case strings.Contains(msg, "violates foreign key constraint"):
+		return recurring.RecurringErrors.New(recurring.ErrRecurringInvalidReference).
+			WithDetail("organization_id", template.OrganizationID.String()).
+			WithDetail("invoice_type_id", template.InvoiceTypeID.String()).
+			WithCause(err)
+	case strings.Contains(msg, "violates check constraint"):
+		return recurring.RecurringErrors.New(recurring.ErrRecurringValidationFailed).
+			WithDetail("reason", "check_constraint").
+			WithCause(err)
+	}
+	return recurring.RecurringErrors.New(recurring.ErrTemplateFailed).
+		WithDetail("organization_id", template.OrganizationID.String()).
+		WithCause(err)
+}
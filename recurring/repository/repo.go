@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Abraxas-365/fuckturamelo/recurring/dto"
+	"github.com/Abraxas-365/fuckturamelo/recurring/models"
+)
+
+// RecurringRepository defines the interface for recurring invoice template
+// repository operations
+type RecurringRepository interface {
+	// Template operations
+	Create(ctx context.Context, template *models.Template) (*models.Template, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Template, error)
+	List(ctx context.Context, req *dto.TemplateListRequest) ([]models.Template, error)
+	Update(ctx context.Context, template *models.Template) (*models.Template, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Run operations
+	ListRuns(ctx context.Context, templateID uuid.UUID, limit int) ([]models.Run, error)
+	GetRun(ctx context.Context, templateID uuid.UUID, scheduledFor time.Time) (*models.Run, error)
+
+	// Scheduler operations
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Template, error)
+	RecordRun(ctx context.Context, run *models.Run, template *models.Template, scheduledFor time.Time) (bool, error)
+	Release(ctx context.Context, id uuid.UUID) error
+}
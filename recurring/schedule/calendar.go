@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// calendar is a compiled calendar rule. Periods are numbered from the one
+// holding the start; occurrences fall in every interval-th period.
+type calendar struct {
+	frequency Frequency
+	interval  int
+	weekdays  [7]bool
+	day       int
+	month     time.Month
+	hour      int
+	minute    int
+	location  *time.Location
+
+	// anchor is the start's date in the rule's timezone
+	anchor time.Time
+}
+
+func (r Rule) compileCalendar(start time.Time) (*calendar, error) {
+	c := &calendar{
+		frequency: r.Frequency,
+		interval:  r.Interval,
+		day:       r.DayOfMonth,
+		month:     time.Month(r.Month),
+		location:  start.Location(),
+		anchor:    date(start.Year(), start.Month(), start.Day()),
+	}
+
+	switch r.Frequency {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return nil, fmt.Errorf("frequency must be daily, weekly, monthly or yearly")
+	}
+
+	if c.interval == 0 {
+		c.interval = 1
+	}
+	if c.interval < 1 || c.interval > MaxInterval {
+		return nil, fmt.Errorf("interval must be between 1 and %d", MaxInterval)
+	}
+
+	if len(r.Weekdays) > 0 && r.Frequency != Weekly {
+		return nil, fmt.Errorf("weekdays only apply to weekly rules")
+	}
+	if r.DayOfMonth != 0 && r.Frequency != Monthly && r.Frequency != Yearly {
+		return nil, fmt.Errorf("day_of_month only applies to monthly and yearly rules")
+	}
+	if r.Month != 0 && r.Frequency != Yearly {
+		return nil, fmt.Errorf("month only applies to yearly rules")
+	}
+
+	for _, name := range r.Weekdays {
+		weekday, ok := parseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("weekdays: unknown weekday %q", name)
+		}
+		c.weekdays[weekday] = true
+	}
+	if r.Frequency == Weekly && len(r.Weekdays) == 0 {
+		c.weekdays[start.Weekday()] = true
+	}
+
+	if c.day == 0 {
+		c.day = start.Day()
+	}
+	if c.day != LastDayOfMonth && (c.day < 1 || c.day > 31) {
+		return nil, fmt.Errorf("day_of_month must be between 1 and 31, or -1 for the last day")
+	}
+	if c.month == 0 {
+		c.month = start.Month()
+	}
+	if c.month < time.January || c.month > time.December {
+		return nil, fmt.Errorf("month must be between 1 and 12")
+	}
+
+	if r.Time != "" {
+		t, err := time.Parse("15:04", r.Time)
+		if err != nil {
+			return nil, fmt.Errorf("time must be HH:MM")
+		}
+		c.hour, c.minute = t.Hour(), t.Minute()
+	}
+
+	return c, nil
+}
+
+// next returns the first occurrence after the given local time
+func (c *calendar) next(after time.Time) time.Time {
+	period := c.period(after)
+	if period < 0 {
+		period = 0
+	}
+	period -= period % c.interval
+
+	// A later period only holds later occurrences, so at most the current
+	// and the following scheduled period need to be searched
+	for range 3 {
+		for _, occurrence := range c.occurrences(period) {
+			if occurrence.After(after) {
+				return occurrence
+			}
+		}
+		period += c.interval
+	}
+	return time.Time{}
+}
+
+// period returns the number of the period holding the local time
+func (c *calendar) period(t time.Time) int {
+	switch c.frequency {
+	case Daily:
+		return days(date(t.Year(), t.Month(), t.Day())) - days(c.anchor)
+	case Weekly:
+		return (days(monday(date(t.Year(), t.Month(), t.Day()))) - days(monday(c.anchor))) / 7
+	case Monthly:
+		return (t.Year()*12 + int(t.Month())) - (c.anchor.Year()*12 + int(c.anchor.Month()))
+	default:
+		return t.Year() - c.anchor.Year()
+	}
+}
+
+// occurrences returns the occurrences of a period in order
+func (c *calendar) occurrences(period int) []time.Time {
+	switch c.frequency {
+	case Daily:
+		return []time.Time{c.at(c.anchor.AddDate(0, 0, period))}
+	case Weekly:
+		first := monday(c.anchor).AddDate(0, 0, 7*period)
+		var occurrences []time.Time
+		for offset := range 7 {
+			day := first.AddDate(0, 0, offset)
+			if c.weekdays[day.Weekday()] {
+				occurrences = append(occurrences, c.at(day))
+			}
+		}
+		return occurrences
+	case Monthly:
+		month := date(c.anchor.Year(), c.anchor.Month(), 1).AddDate(0, period, 0)
+		return []time.Time{c.at(c.dayIn(month.Year(), month.Month()))}
+	default:
+		return []time.Time{c.at(c.dayIn(c.anchor.Year()+period, c.month))}
+	}
+}
+
+// dayIn returns the rule's day in a month, clamped to its last day
+func (c *calendar) dayIn(year int, month time.Month) time.Time {
+	last := date(year, month+1, 0).Day()
+	day := c.day
+	if day == LastDayOfMonth || day > last {
+		day = last
+	}
+	return date(year, month, day)
+}
+
+// at returns the rule's time of day on a date, in the rule's timezone
+func (c *calendar) at(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.hour, c.minute, 0, 0, c.location)
+}
+
+// date returns a calendar date as midnight UTC, normalizing overflows
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// days returns the day number of a date
+func days(d time.Time) int {
+	return int(d.Unix() / 86400)
+}
+
+// monday returns the Monday of the week holding the date
+func monday(d time.Time) time.Time {
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
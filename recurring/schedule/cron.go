@@ -0,0 +1,188 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronYears bounds the search for the next occurrence of expressions
+// that rarely or never match, such as 0 0 30 2 *
+const maxCronYears = 5
+
+// cronField is the set of values a field of a cron expression matches
+type cronField uint64
+
+func (f cronField) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+// cronExpr is a parsed five-field cron expression. As in Vixie cron, when
+// both day-of-month and day-of-week are restricted a day matching either
+// one matches.
+type cronExpr struct {
+	minute, hour, dom, month, dow cronField
+	domAny, dowAny                bool
+}
+
+// cronBounds are the ranges of the fields of a cron expression
+var cronBounds = [5]struct {
+	name     string
+	min, max int
+	names    map[string]int
+}{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// cronMacros are the accepted shorthands for common expressions
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a five-field expression. Fields accept *, values, ranges
+// (1-5), lists (1,15), steps (*/15, 10-50/10) and month and weekday names;
+// day of week 7 is Sunday.
+func parseCron(expression string) (*cronExpr, error) {
+	if macro, ok := cronMacros[strings.ToLower(strings.TrimSpace(expression))]; ok {
+		expression = macro
+	}
+
+	parts := strings.Fields(expression)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(parts))
+	}
+
+	var fields [5]cronField
+	for i, part := range parts {
+		field, err := parseCronField(part, i)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+
+	// Sunday is both 0 and 7
+	if fields[4].has(7) {
+		fields[4] |= 1
+	}
+
+	return &cronExpr{
+		minute: fields[0],
+		hour:   fields[1],
+		dom:    fields[2],
+		month:  fields[3],
+		dow:    fields[4],
+		domAny: strings.HasPrefix(parts[2], "*"),
+		dowAny: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseCronField(text string, index int) (cronField, error) {
+	bounds := cronBounds[index]
+	var field cronField
+
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%s: invalid step %q", bounds.name, stepText)
+			}
+			step = n
+		}
+
+		low, high := bounds.min, bounds.max
+		if rangeText != "*" {
+			lowText, highText, isRange := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = parseCronValue(lowText, index); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseCronValue(highText, index); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = bounds.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s: range %q is reversed", bounds.name, rangeText)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			field |= 1 << uint(v)
+		}
+	}
+
+	return field, nil
+}
+
+func parseCronValue(text string, index int) (int, error) {
+	bounds := cronBounds[index]
+	if v, ok := bounds.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < bounds.min || v > bounds.max {
+		return 0, fmt.Errorf("%s: %q is not between %d and %d", bounds.name, text, bounds.min, bounds.max)
+	}
+	return v, nil
+}
+
+// next returns the first matching minute after the given local time
+func (e *cronExpr) next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Year() + maxCronYears
+
+	for t.Year() <= limit {
+		if !e.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !e.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (e *cronExpr) matchesDay(t time.Time) bool {
+	dom := e.dom.has(t.Day())
+	dow := e.dow.has(int(t.Weekday()))
+	switch {
+	case e.domAny:
+		return dow
+	case e.dowAny:
+		return dom
+	}
+	return dom || dow
+}
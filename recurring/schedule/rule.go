@@ -0,0 +1,189 @@
+// Package schedule computes the occurrences of recurring invoice templates.
+// A rule is either a five-field cron expression, for example
+//
+//	{"cron": "0 9 1 * *", "timezone": "America/Lima"}
+//
+// or a calendar rule repeating every interval days, weeks, months or years
+// from the template's start:
+//
+//	{"frequency": "monthly", "day_of_month": -1, "time": "08:00"}
+//
+// Occurrences are computed in the rule's timezone, UTC by default.
+package schedule
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Frequency is the period a calendar rule repeats over
+type Frequency string
+
+// Calendar frequencies
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+	Yearly  Frequency = "yearly"
+)
+
+// LastDayOfMonth is the day_of_month of rules that run on the last day of
+// every month
+const LastDayOfMonth = -1
+
+// MaxInterval bounds the interval of calendar rules
+const MaxInterval = 1000
+
+// Rule describes when a template produces invoices. Exactly one of Cron and
+// Frequency is set.
+type Rule struct {
+	// Cron is a five-field expression: minute hour day-of-month month
+	// day-of-week
+	Cron string `json:"cron,omitempty"`
+
+	// Frequency and Interval repeat the rule every Interval periods
+	// counted from the template's start; Interval defaults to 1
+	Frequency Frequency `json:"frequency,omitempty"`
+	Interval  int       `json:"interval,omitempty"`
+
+	// Weekdays of weekly rules (mon … sun); defaults to the start's weekday
+	Weekdays []string `json:"weekdays,omitempty"`
+
+	// DayOfMonth of monthly and yearly rules, 1-31 or -1 for the last day;
+	// days past the end of a month run on its last day. Defaults to the
+	// start's day.
+	DayOfMonth int `json:"day_of_month,omitempty"`
+
+	// Month of yearly rules, 1-12; defaults to the start's month
+	Month int `json:"month,omitempty"`
+
+	// Time of day of calendar rules as HH:MM; defaults to 00:00
+	Time string `json:"time,omitempty"`
+
+	// Timezone is an IANA zone name; defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Value implements the driver.Valuer interface for database storage
+func (r Rule) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval
+func (r *Rule) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("cannot scan %T into Rule", value)
+	}
+}
+
+// Schedule is a compiled rule anchored at the template's start
+type Schedule struct {
+	start    time.Time
+	location *time.Location
+	cron     *cronExpr
+	calendar *calendar
+}
+
+// Compile checks the rule and anchors it at start. Occurrences before start
+// are never produced.
+func (r Rule) Compile(start time.Time) (*Schedule, error) {
+	location := time.UTC
+	if r.Timezone != "" {
+		loc, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("timezone: unknown zone %q", r.Timezone)
+		}
+		location = loc
+	}
+	s := &Schedule{start: start, location: location}
+
+	switch {
+	case r.Cron != "" && r.Frequency != "":
+		return nil, fmt.Errorf("set either cron or frequency, not both")
+	case r.Cron != "":
+		if r.Interval != 0 || len(r.Weekdays) > 0 || r.DayOfMonth != 0 || r.Month != 0 || r.Time != "" {
+			return nil, fmt.Errorf("cron rules only accept a timezone besides the expression")
+		}
+		expr, err := parseCron(r.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("cron: %w", err)
+		}
+		s.cron = expr
+	case r.Frequency != "":
+		cal, err := r.compileCalendar(start.In(location))
+		if err != nil {
+			return nil, err
+		}
+		s.calendar = cal
+	default:
+		return nil, fmt.Errorf("either cron or frequency is required")
+	}
+
+	return s, nil
+}
+
+// Location returns the timezone occurrences are computed in
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+// Next returns the first occurrence strictly after the given time, or the
+// zero time when the rule never runs again
+func (s *Schedule) Next(after time.Time) time.Time {
+	// The start itself is an occurrence when it matches the rule
+	if after.Before(s.start) {
+		after = s.start.Add(-time.Nanosecond)
+	}
+
+	var next time.Time
+	if s.cron != nil {
+		next = s.cron.next(after.In(s.location))
+	} else {
+		next = s.calendar.next(after.In(s.location))
+	}
+	if next.IsZero() {
+		return next
+	}
+	return next.UTC()
+}
+
+// Upcoming returns up to n occurrences after the given time
+func (s *Schedule) Upcoming(after time.Time, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	for len(occurrences) < n {
+		next := s.Next(after)
+		if next.IsZero() {
+			break
+		}
+		occurrences = append(occurrences, next)
+		after = next
+	}
+	return occurrences
+}
+
+// weekdayNames maps the weekday names accepted by rules
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseWeekday accepts three-letter and full English weekday names
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return 0, false
+	}
+	day, ok := weekdayNames[name[:3]]
+	if ok && len(name) > 3 && !strings.EqualFold(day.String(), name) {
+		return 0, false
+	}
+	return day, ok
+}
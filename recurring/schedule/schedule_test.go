@@ -0,0 +1,216 @@
+package schedule
+
+import (
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func utc(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t.UTC()
+}
+
+func formatAll(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, t := range times {
+		out[i] = t.Format(time.RFC3339)
+	}
+	return out
+}
+
+func TestUpcoming(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		start string
+		after string // defaults to just before the start
+		want  []string
+	}{
+		{
+			name:  "day 31 clamps to the end of shorter months",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: 31},
+			start: "2024-01-31T00:00:00Z",
+			want:  []string{"2024-01-31T00:00:00Z", "2024-02-29T00:00:00Z", "2024-03-31T00:00:00Z", "2024-04-30T00:00:00Z", "2024-05-31T00:00:00Z"},
+		},
+		{
+			name:  "day defaults to the start's day",
+			rule:  Rule{Frequency: Monthly},
+			start: "2023-01-30T00:00:00Z",
+			want:  []string{"2023-01-30T00:00:00Z", "2023-02-28T00:00:00Z", "2023-03-30T00:00:00Z"},
+		},
+		{
+			name:  "last day of month",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: LastDayOfMonth, Time: "18:00"},
+			start: "2023-01-15T00:00:00Z",
+			want:  []string{"2023-01-31T18:00:00Z", "2023-02-28T18:00:00Z", "2023-03-31T18:00:00Z", "2023-04-30T18:00:00Z"},
+		},
+		{
+			name:  "quarterly on day 31",
+			rule:  Rule{Frequency: Monthly, Interval: 3, DayOfMonth: 31},
+			start: "2024-01-31T00:00:00Z",
+			want:  []string{"2024-01-31T00:00:00Z", "2024-04-30T00:00:00Z", "2024-07-31T00:00:00Z", "2024-10-31T00:00:00Z"},
+		},
+		{
+			name:  "February 29 in common years",
+			rule:  Rule{Frequency: Yearly},
+			start: "2024-02-29T00:00:00Z",
+			want:  []string{"2024-02-29T00:00:00Z", "2025-02-28T00:00:00Z", "2026-02-28T00:00:00Z", "2027-02-28T00:00:00Z", "2028-02-29T00:00:00Z"},
+		},
+		{
+			name:  "start after the day of the first month",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: 5},
+			start: "2024-01-20T00:00:00Z",
+			want:  []string{"2024-02-05T00:00:00Z", "2024-03-05T00:00:00Z"},
+		},
+		{
+			name:  "catch up after a pause",
+			rule:  Rule{Frequency: Monthly, Interval: 2, DayOfMonth: 10},
+			start: "2024-01-10T00:00:00Z",
+			after: "2024-06-15T00:00:00Z",
+			want:  []string{"2024-07-10T00:00:00Z", "2024-09-10T00:00:00Z"},
+		},
+		{
+			name:  "weekly on several days",
+			rule:  Rule{Frequency: Weekly, Weekdays: []string{"mon", "Wednesday", "fri"}, Time: "09:00"},
+			start: "2024-03-06T10:00:00Z", // a Wednesday, after 09:00
+			want:  []string{"2024-03-08T09:00:00Z", "2024-03-11T09:00:00Z", "2024-03-13T09:00:00Z", "2024-03-15T09:00:00Z"},
+		},
+		{
+			name:  "every other week",
+			rule:  Rule{Frequency: Weekly, Interval: 2},
+			start: "2024-03-05T00:00:00Z", // a Tuesday
+			want:  []string{"2024-03-05T00:00:00Z", "2024-03-19T00:00:00Z", "2024-04-02T00:00:00Z"},
+		},
+		{
+			name:  "local day in a timezone behind UTC",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: 1, Time: "08:00", Timezone: "America/Lima"},
+			start: "2024-01-01T00:00:00Z", // still December 31 in Lima
+			want:  []string{"2024-01-01T13:00:00Z", "2024-02-01T13:00:00Z"},
+		},
+		{
+			name:  "local time is kept across daylight saving changes",
+			rule:  Rule{Frequency: Daily, Time: "09:30", Timezone: "Europe/Madrid"},
+			start: "2024-03-30T00:00:00Z",
+			want:  []string{"2024-03-30T08:30:00Z", "2024-03-31T07:30:00Z", "2024-04-01T07:30:00Z"},
+		},
+		{
+			name:  "month end in a timezone ahead of UTC",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: LastDayOfMonth, Timezone: "Asia/Tokyo"},
+			start: "2024-02-01T00:00:00Z",
+			want:  []string{"2024-02-28T15:00:00Z", "2024-03-30T15:00:00Z"},
+		},
+		{
+			name:  "cron in a timezone",
+			rule:  Rule{Cron: "0 9 1 * *", Timezone: "America/Lima"},
+			start: "2024-01-01T00:00:00Z",
+			after: "2024-01-15T00:00:00Z",
+			want:  []string{"2024-02-01T14:00:00Z", "2024-03-01T14:00:00Z"},
+		},
+		{
+			name:  "cron day 31 skips shorter months",
+			rule:  Rule{Cron: "0 0 31 * *"},
+			start: "2024-01-01T00:00:00Z",
+			want:  []string{"2024-01-31T00:00:00Z", "2024-03-31T00:00:00Z", "2024-05-31T00:00:00Z"},
+		},
+		{
+			name:  "cron February 29",
+			rule:  Rule{Cron: "0 0 29 feb *"},
+			start: "2023-01-01T00:00:00Z",
+			want:  []string{"2024-02-29T00:00:00Z", "2028-02-29T00:00:00Z"},
+		},
+		{
+			name:  "cron that never matches",
+			rule:  Rule{Cron: "0 0 30 2 *"},
+			start: "2024-01-01T00:00:00Z",
+			want:  []string{},
+		},
+		{
+			name:  "cron day of month or day of week",
+			rule:  Rule{Cron: "0 12 13 * fri"},
+			start: "2024-09-09T00:00:00Z",
+			want:  []string{"2024-09-13T12:00:00Z", "2024-09-20T12:00:00Z", "2024-09-27T12:00:00Z", "2024-10-04T12:00:00Z"},
+		},
+		{
+			name:  "cron steps and Sunday as 7",
+			rule:  Rule{Cron: "*/20 6-7 * * 7"},
+			start: "2024-03-09T00:00:00Z",
+			want:  []string{"2024-03-10T06:00:00Z", "2024-03-10T06:20:00Z", "2024-03-10T06:40:00Z", "2024-03-10T07:00:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := utc(tt.start)
+			s, err := tt.rule.Compile(start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after := start.Add(-time.Nanosecond)
+			if tt.after != "" {
+				after = utc(tt.after)
+			}
+			if len(tt.want) == 0 {
+				if next := s.Next(after); !next.IsZero() {
+					t.Errorf("next = %s; want none", next)
+				}
+				return
+			}
+			if got := formatAll(s.Upcoming(after, len(tt.want))); !slices.Equal(got, tt.want) {
+				t.Errorf("occurrences = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextNeverPrecedesStart(t *testing.T) {
+	start := utc("2024-05-15T12:00:00Z")
+	s, err := Rule{Frequency: Daily, Time: "08:00"}.Compile(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(utc("2020-01-01T00:00:00Z")); !got.Equal(utc("2024-05-16T08:00:00Z")) {
+		t.Errorf("next = %s; want the first occurrence after the start", got)
+	}
+	if got := s.Next(utc("2024-05-16T08:00:00Z")); !got.Equal(utc("2024-05-17T08:00:00Z")) {
+		t.Errorf("next = %s; want strictly after the given time", got)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"empty", Rule{}},
+		{"both kinds", Rule{Cron: "@daily", Frequency: Daily}},
+		{"cron with calendar fields", Rule{Cron: "@daily", Time: "08:00"}},
+		{"cron field count", Rule{Cron: "0 0 * *"}},
+		{"cron out of range", Rule{Cron: "0 24 * * *"}},
+		{"cron reversed range", Rule{Cron: "0 0 10-5 * *"}},
+		{"cron bad step", Rule{Cron: "*/0 * * * *"}},
+		{"unknown frequency", Rule{Frequency: "hourly"}},
+		{"interval too large", Rule{Frequency: Daily, Interval: MaxInterval + 1}},
+		{"negative interval", Rule{Frequency: Daily, Interval: -1}},
+		{"weekdays on monthly", Rule{Frequency: Monthly, Weekdays: []string{"mon"}}},
+		{"unknown weekday", Rule{Frequency: Weekly, Weekdays: []string{"mo"}}},
+		{"misspelled weekday", Rule{Frequency: Weekly, Weekdays: []string{"monkey"}}},
+		{"day on weekly", Rule{Frequency: Weekly, DayOfMonth: 3}},
+		{"day 32", Rule{Frequency: Monthly, DayOfMonth: 32}},
+		{"day -2", Rule{Frequency: Monthly, DayOfMonth: -2}},
+		{"month on monthly", Rule{Frequency: Monthly, Month: 2}},
+		{"month 13", Rule{Frequency: Yearly, Month: 13}},
+		{"bad time", Rule{Frequency: Daily, Time: "8am"}},
+		{"unknown timezone", Rule{Frequency: Daily, Timezone: "Mars/Olympus"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.rule.Compile(utc("2024-01-01T00:00:00Z")); err == nil {
+				t.Error("compiled; want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"net/http"
+
+	"github.com/Abraxas-365/craftable/errx"
+)
+
+// StorageErrors is the error registry for blob storage
+var StorageErrors = errx.NewRegistry("STORAGE")
+
+// Storage error codes
+var (
+	ErrObjectNotFound = StorageErrors.Register(
+		"OBJECT_NOT_FOUND",
+		errx.TypeNotFound,
+		http.StatusNotFound,
+		"Stored file not found",
+	)
+
+	ErrStorageFailed = StorageErrors.Register(
+		"STORAGE_FAILED",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"Failed to access file storage",
+	)
+
+	ErrInvalidKey = StorageErrors.Register(
+		"INVALID_KEY",
+		errx.TypeValidation,
+		http.StatusBadRequest,
+		"Invalid storage key",
+	)
+
+	ErrInvalidConfig = StorageErrors.Register(
+		"INVALID_CONFIG",
+		errx.TypeInternal,
+		http.StatusInternalServerError,
+		"File storage is not configured correctly",
+	)
+
+	// Signed URL errors
+	ErrInvalidSignature = StorageErrors.Register(
+		"INVALID_SIGNATURE",
+		errx.TypeAuthorization,
+		http.StatusForbidden,
+		"Download link signature is invalid",
+	)
+
+	ErrURLExpired = StorageErrors.Register(
+		"URL_EXPIRED",
+		errx.TypeAuthorization,
+		http.StatusForbidden,
+		"Download link has expired",
+	)
+)
+
+// Helper functions for error checking
+func IsObjectNotFound(err error) bool {
+	return errx.IsCode(err, ErrObjectNotFound)
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalConfig configures the filesystem backend. Signed URLs point at
+// BaseURL, where the application serves the files after checking the
+// signature with Secret (see LocalStore.Verify).
+type LocalConfig struct {
+	Root    string `json:"root"`
+	BaseURL string `json:"base_url"`
+	Secret  string `json:"secret"`
+}
+
+// LocalStore keeps objects as files below a root directory
+type LocalStore struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocalStore creates a filesystem store, creating the root directory if
+// needed
+func NewLocalStore(config LocalConfig) (*LocalStore, error) {
+	if config.Root == "" || config.BaseURL == "" || config.Secret == "" {
+		return nil, StorageErrors.New(ErrInvalidConfig).
+			WithDetail("backend", string(BackendLocal)).
+			WithDetail("reason", "root, base_url and secret are required")
+	}
+
+	if err := os.MkdirAll(config.Root, 0o750); err != nil {
+		return nil, StorageErrors.New(ErrInvalidConfig).
+			WithDetail("backend", string(BackendLocal)).
+			WithCause(err)
+	}
+
+	return &LocalStore{
+		root:    config.Root,
+		baseURL: strings.TrimRight(config.BaseURL, "/"),
+		secret:  []byte(config.Secret),
+	}, nil
+}
+
+// Put writes the object to a temporary file and renames it into place, so
+// readers never see a partial file
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	storageError := func(err error) error {
+		return StorageErrors.New(ErrStorageFailed).
+			WithDetail("key", key).
+			WithCause(err)
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return storageError(err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return storageError(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, body)
+	if err == nil && size >= 0 && written != size {
+		err = errors.New("size mismatch: wrote " + strconv.FormatInt(written, 10) + " bytes")
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return storageError(err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// Get opens the file of an object
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, StorageErrors.New(ErrObjectNotFound).
+				WithDetail("key", key)
+		}
+		return nil, StorageErrors.New(ErrStorageFailed).
+			WithDetail("key", key).
+			WithCause(err)
+	}
+
+	return file, nil
+}
+
+// Exists reports whether the file of an object exists
+func (s *LocalStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+
+	if _, err := os.Stat(s.path(key)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, StorageErrors.New(ErrStorageFailed).
+			WithDetail("key", key).
+			WithCause(err)
+	}
+
+	return true, nil
+}
+
+// Delete removes the file of an object
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return StorageErrors.New(ErrStorageFailed).
+			WithDetail("key", key).
+			WithCause(err)
+	}
+
+	return nil
+}
+
+// SignedURL returns BaseURL/<key> with the expiry, the download parameters
+// and an HMAC-SHA256 signature over all of them in the query
+func (s *LocalStore) SignedURL(ctx context.Context, key string, expiry time.Duration, download Download) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	if download.FileName != "" {
+		query.Set("name", download.FileName)
+	}
+	if download.ContentType != "" {
+		query.Set("type", download.ContentType)
+	}
+	query.Set("signature", s.sign(key, expires, download))
+
+	return s.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// Verify checks the query of a signed URL for key and returns how the
+// object is to be served
+func (s *LocalStore) Verify(key string, query url.Values, now time.Time) (*Download, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	download := Download{
+		FileName:    query.Get("name"),
+		ContentType: query.Get("type"),
+	}
+
+	expires := query.Get("expires")
+	expected := s.sign(key, expires, download)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return nil, StorageErrors.New(ErrInvalidSignature).
+			WithDetail("key", key)
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return nil, StorageErrors.New(ErrURLExpired).
+			WithDetail("key", key)
+	}
+
+	return &download, nil
+}
+
+func (s *LocalStore) sign(key, expires string, download Download) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires + "\n" + download.FileName + "\n" + download.ContentType))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
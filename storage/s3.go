@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config configures the S3-compatible backend. MinIO and most other
+// self-hosted services need PathStyle.
+type S3Config struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	UseSSL    bool   `json:"use_ssl"`
+	PathStyle bool   `json:"path_style"`
+}
+
+// S3Store keeps objects in a bucket of an S3-compatible service
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store creates an S3 store. The bucket must already exist.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, StorageErrors.New(ErrInvalidConfig).
+			WithDetail("backend", string(BackendS3)).
+			WithDetail("reason", "endpoint and bucket are required")
+	}
+
+	lookup := minio.BucketLookupAuto
+	if config.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure:       config.UseSSL,
+		Region:       config.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, StorageErrors.New(ErrInvalidConfig).
+			WithDetail("backend", string(BackendS3)).
+			WithCause(err)
+	}
+
+	return &S3Store{
+		client: client,
+		bucket: config.Bucket,
+	}, nil
+}
+
+// Put uploads an object
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return StorageErrors.New(ErrStorageFailed).
+			WithDetail("key", key).
+			WithCause(err)
+	}
+
+	return nil
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err == nil {
+		// GetObject is lazy; Stat surfaces a missing object
+		if _, err = object.Stat(); err != nil {
+			object.Close()
+		}
+	}
+	if err != nil {
+		return nil, s.objectError(key, err)
+	}
+
+	return object, nil
+}
+
+// Exists reports whether an object is stored under key
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+
+	if _, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{}); err != nil {
+		err = s.objectError(key, err)
+		if IsObjectNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Delete removes an object
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		err = s.objectError(key, err)
+		if IsObjectNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// SignedURL presigns a GET request for the object that overrides the
+// response content type and disposition
+func (s *S3Store) SignedURL(ctx context.Context, key string, expiry time.Duration, download Download) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	if download.FileName != "" {
+		params.Set("response-content-disposition",
+			mime.FormatMediaType("attachment", map[string]string{"filename": download.FileName}))
+	}
+	if download.ContentType != "" {
+		params.Set("response-content-type", download.ContentType)
+	}
+
+	signed, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, params)
+	if err != nil {
+		return "", StorageErrors.New(ErrStorageFailed).
+			WithDetail("key", key).
+			WithCause(err)
+	}
+
+	return signed.String(), nil
+}
+
+func (s *S3Store) objectError(key string, err error) error {
+	response := minio.ToErrorResponse(err)
+	if response.StatusCode == http.StatusNotFound || response.Code == "NoSuchKey" {
+		return StorageErrors.New(ErrObjectNotFound).
+			WithDetail("key", key)
+	}
+	return StorageErrors.New(ErrStorageFailed).
+		WithDetail("key", key).
+		WithCause(err)
+}
@@ -0,0 +1,103 @@
+// Package storage keeps binary files such as invoice attachments in a
+// pluggable blob backend: the local filesystem or an S3-compatible service
+// (AWS S3, MinIO). Files are addressed by slash separated keys and handed
+// out through short-lived signed download URLs.
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Store is a blob backend
+type Store interface {
+	// Put stores size bytes read from body under key, replacing any object
+	// stored there
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+
+	// Get opens the object stored under key. The caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Exists reports whether an object is stored under key
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// Delete removes the object stored under key; a missing object is not
+	// an error
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL that downloads the object until expiry has
+	// passed, without further authentication
+	SignedURL(ctx context.Context, key string, expiry time.Duration, download Download) (string, error)
+}
+
+// Download describes how a signed URL serves an object
+type Download struct {
+	FileName    string
+	ContentType string
+}
+
+// Backend names a Store implementation
+type Backend string
+
+// Supported backends
+const (
+	BackendLocal Backend = "local"
+	BackendS3    Backend = "s3"
+)
+
+// Config selects and configures the backend
+type Config struct {
+	Backend Backend     `json:"backend"`
+	Local   LocalConfig `json:"local"`
+	S3      S3Config    `json:"s3"`
+}
+
+// New creates the store selected by the configuration. An empty backend
+// uses the local filesystem.
+func New(config Config) (Store, error) {
+	switch config.Backend {
+	case BackendLocal, "":
+		return NewLocalStore(config.Local)
+	case BackendS3:
+		return NewS3Store(config.S3)
+	}
+
+	return nil, StorageErrors.New(ErrInvalidConfig).
+		WithDetail("backend", string(config.Backend))
+}
+
+// ValidKey reports whether key is safe to use with every backend: relative,
+// without empty, "." or ".." segments, and limited to letters, digits and
+// "-", "_", "." and "/"
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 512 {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == '/':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkKey(key string) error {
+	if !ValidKey(key) {
+		return StorageErrors.New(ErrInvalidKey).
+			WithDetail("key", key)
+	}
+	return nil
+}
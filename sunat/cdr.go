@@ -0,0 +1,156 @@
+package sunat
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDocumentSize bounds the documents unzipped from archives
+const maxDocumentSize = 10 << 20
+
+// Status is the outcome of a document recorded in its CDR
+type Status string
+
+// CDR outcomes. Observed documents are valid: SUNAT accepted them with
+// observations the issuer should correct in later documents.
+const (
+	StatusAccepted Status = "accepted"
+	StatusObserved Status = "observed"
+	StatusRejected Status = "rejected"
+)
+
+// CDR is the Constancia de Recepción, the UBL ApplicationResponse SUNAT
+// issues for a document
+type CDR struct {
+	// ID identifies the CDR itself
+	ID string
+
+	// ReferenceID is the series and number of the document, e.g. F001-123
+	ReferenceID string
+
+	// ResponseCode is 0 for accepted documents, 2000-3999 for rejections
+	// and 4000 or above for observations
+	ResponseCode string
+	Description  string
+
+	// Notes are the observations, each starting with its code
+	Notes []string
+}
+
+// Status maps the response code and notes onto an outcome
+func (c *CDR) Status() Status {
+	code, err := strconv.Atoi(strings.TrimSpace(c.ResponseCode))
+	switch {
+	case err != nil:
+		return StatusRejected
+	case code == 0 && len(c.Notes) == 0:
+		return StatusAccepted
+	case code == 0 || code >= 4000:
+		return StatusObserved
+	}
+	return StatusRejected
+}
+
+// applicationResponse is the part of a CDR the client reads; elements match
+// by local name
+type applicationResponse struct {
+	XMLName          xml.Name `xml:"ApplicationResponse"`
+	ID               string   `xml:"ID"`
+	Notes            []string `xml:"Note"`
+	DocumentResponse struct {
+		Response struct {
+			ReferenceID  string `xml:"ReferenceID"`
+			ResponseCode string `xml:"ResponseCode"`
+			Description  string `xml:"Description"`
+		} `xml:"Response"`
+	} `xml:"DocumentResponse"`
+}
+
+// ParseCDR reads the CDR in the zip SUNAT answers with
+func ParseCDR(archive []byte) (*CDR, error) {
+	name, data, err := Unzip(archive)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedCDR, err)
+	}
+
+	var response applicationResponse
+	if err := xml.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrMalformedCDR, name, err)
+	}
+	result := response.DocumentResponse.Response
+	if strings.TrimSpace(result.ResponseCode) == "" {
+		return nil, fmt.Errorf("%w: %s has no response code", ErrMalformedCDR, name)
+	}
+
+	cdr := &CDR{
+		ID:           strings.TrimSpace(response.ID),
+		ReferenceID:  strings.TrimSpace(result.ReferenceID),
+		ResponseCode: strings.TrimSpace(result.ResponseCode),
+		Description:  strings.TrimSpace(result.Description),
+	}
+	for _, note := range response.Notes {
+		if note = strings.TrimSpace(note); note != "" {
+			cdr.Notes = append(cdr.Notes, note)
+		}
+	}
+	return cdr, nil
+}
+
+// Zip packs a document in a zip archive, as the web service expects it
+func Zip(name string, content []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	f, err := w.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Unzip returns the first XML document of a zip archive; SUNAT archives may
+// also hold directory entries
+func Unzip(archive []byte) (string, []byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".xml") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(io.LimitReader(rc, maxDocumentSize+1))
+		if err != nil {
+			return "", nil, err
+		}
+		if len(data) > maxDocumentSize {
+			return "", nil, fmt.Errorf("%s exceeds %d bytes", f.Name, maxDocumentSize)
+		}
+		return f.Name, data, nil
+	}
+	return "", nil, errors.New("archive holds no XML document")
+}
@@ -0,0 +1,152 @@
+package sunat
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// cdrXML renders an ApplicationResponse as SUNAT issues it
+func cdrXML(referenceID, responseCode, description string, notes ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<ar:ApplicationResponse xmlns:ar="urn:oasis:names:specification:ubl:schema:xsd:ApplicationResponse-2" xmlns:cac="urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2" xmlns:cbc="urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2">
+  <cbc:UBLVersionID>2.0</cbc:UBLVersionID>
+  <cbc:ID>1675260000000</cbc:ID>
+`)
+	for _, note := range notes {
+		fmt.Fprintf(&b, "  <cbc:Note>%s</cbc:Note>\n", note)
+	}
+	fmt.Fprintf(&b, `  <cac:DocumentResponse>
+    <cac:Response>
+      <cbc:ReferenceID>%s</cbc:ReferenceID>
+      <cbc:ResponseCode>%s</cbc:ResponseCode>
+      <cbc:Description>%s</cbc:Description>
+    </cac:Response>
+  </cac:DocumentResponse>
+</ar:ApplicationResponse>`, referenceID, responseCode, description)
+	return b.String()
+}
+
+// cdrArchive zips a CDR the way SUNAT does, after a directory entry
+func cdrArchive(t *testing.T, name, cdr string) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	if _, err := w.Create("dummy/"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := w.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(cdr)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestParseCDR(t *testing.T) {
+	tests := []struct {
+		name       string
+		cdr        string
+		wantCode   string
+		wantNotes  int
+		wantStatus Status
+	}{
+		{
+			name:       "accepted",
+			cdr:        cdrXML("F001-123", "0", "La Factura numero F001-123, ha sido aceptada"),
+			wantCode:   "0",
+			wantStatus: StatusAccepted,
+		},
+		{
+			name:       "accepted with observations",
+			cdr:        cdrXML("F001-123", "0", "La Factura numero F001-123, ha sido aceptada", "4252 - El dato ingresado como atributo @listName es incorrecto.", "  "),
+			wantCode:   "0",
+			wantNotes:  1,
+			wantStatus: StatusObserved,
+		},
+		{
+			name:       "observation code",
+			cdr:        cdrXML("F001-123", "4000", "El documento fue aceptado con observaciones"),
+			wantCode:   "4000",
+			wantStatus: StatusObserved,
+		},
+		{
+			name:       "rejected",
+			cdr:        cdrXML("F001-123", "2335", "El documento electronico ingresado ha sido alterado"),
+			wantCode:   "2335",
+			wantStatus: StatusRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cdr, err := ParseCDR(cdrArchive(t, "R-20123456789-01-F001-123.xml", tt.cdr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cdr.ID != "1675260000000" || cdr.ReferenceID != "F001-123" {
+				t.Errorf("CDR %q for %q; want 1675260000000 for F001-123", cdr.ID, cdr.ReferenceID)
+			}
+			if cdr.ResponseCode != tt.wantCode {
+				t.Errorf("response code = %q; want %q", cdr.ResponseCode, tt.wantCode)
+			}
+			if cdr.Description == "" {
+				t.Error("description missing")
+			}
+			if len(cdr.Notes) != tt.wantNotes {
+				t.Errorf("got %d notes; want %d", len(cdr.Notes), tt.wantNotes)
+			}
+			if status := cdr.Status(); status != tt.wantStatus {
+				t.Errorf("status = %s; want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestParseCDRMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive func(t *testing.T) []byte
+	}{
+		{"not a zip", func(t *testing.T) []byte { return []byte("<html>error</html>") }},
+		{"no XML document", func(t *testing.T) []byte { return cdrArchive(t, "R-F001-123.txt", "text") }},
+		{"not XML", func(t *testing.T) []byte { return cdrArchive(t, "R-F001-123.xml", "<ar:ApplicationResponse") }},
+		{"no response code", func(t *testing.T) []byte { return cdrArchive(t, "R-F001-123.xml", cdrXML("F001-123", "", "")) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCDR(tt.archive(t)); !errors.Is(err, ErrMalformedCDR) {
+				t.Errorf("got %v; want ErrMalformedCDR", err)
+			}
+		})
+	}
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	name, archive, err := Pack("20123456789-01-F001-123.xml", []byte("<Invoice/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "20123456789-01-F001-123.zip" {
+		t.Errorf("archive name = %q; want 20123456789-01-F001-123.zip", name)
+	}
+
+	entry, content, err := Unzip(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry != "20123456789-01-F001-123.xml" || string(content) != "<Invoice/>" {
+		t.Errorf("unzipped %s holding %q", entry, content)
+	}
+}
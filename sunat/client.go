@@ -0,0 +1,168 @@
+package sunat
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SOAP namespaces of the billService
+const (
+	namespaceSOAP    = "http://schemas.xmlsoap.org/soap/envelope/"
+	namespaceService = "http://service.sunat.gob.pe"
+	namespaceWSSE    = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+)
+
+// maxResponseSize bounds the responses read from the service
+const maxResponseSize = 10 << 20
+
+// Response is SUNAT's answer to a document
+type Response struct {
+	// ArchiveName is the file name of the CDR zip, R-<document>.zip
+	ArchiveName string
+
+	// Archive is the CDR zip as received
+	Archive []byte
+
+	CDR *CDR
+}
+
+// Client calls the billService
+type Client struct {
+	endpoint string
+	http     *http.Client
+}
+
+// NewClient creates a client; zero config values take the defaults
+func NewClient(config Config) *Client {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = EndpointBeta
+	}
+	timeout := DefaultTimeout
+	if config.TimeoutSeconds > 0 {
+		timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		http:     &http.Client{Timeout: timeout},
+	}
+}
+
+// Pack zips a signed document named after SUNAT's convention, e.g.
+// 20123456789-01-F001-123.xml, into the archive SendBill sends
+func Pack(fileName string, content []byte) (string, []byte, error) {
+	base := strings.TrimSuffix(fileName, ".xml")
+	archive, err := Zip(base+".xml", content)
+	if err != nil {
+		return "", nil, err
+	}
+	return base + ".zip", archive, nil
+}
+
+// SendBill sends an archive made by Pack and returns the CDR. Sending the
+// same archive again is safe while SUNAT has not answered. Faults come back
+// as *Fault; failures to reach the service wrap ErrUnavailable.
+func (c *Client) SendBill(ctx context.Context, credentials Credentials, archiveName string, archive []byte) (*Response, error) {
+	body, err := xml.Marshal(sendBillEnvelope{
+		SOAP:     namespaceSOAP,
+		Service:  namespaceService,
+		WSSE:     namespaceWSSE,
+		Username: credentials.Username,
+		Password: credentials.Password,
+		FileName: archiveName,
+		Content:  base64.StdEncoding.EncodeToString(archive),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(append([]byte(xml.Header), body...)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("SOAPAction", "urn:sendBill")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	var envelope responseEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, fmt.Errorf("%w: HTTP %d", ErrUnavailable, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%w: HTTP %d: %v", ErrMalformedResponse, resp.StatusCode, err)
+	}
+	if fault := envelope.Body.Fault; fault != nil {
+		return nil, newFault(fault.Code, fault.String)
+	}
+	if envelope.Body.SendBillResponse == nil {
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, fmt.Errorf("%w: HTTP %d", ErrUnavailable, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%w: HTTP %d without sendBillResponse", ErrMalformedResponse, resp.StatusCode)
+	}
+
+	cdrArchive, err := decodeBase64(envelope.Body.SendBillResponse.ApplicationResponse)
+	if err != nil {
+		return nil, fmt.Errorf("%w: applicationResponse: %v", ErrMalformedResponse, err)
+	}
+	cdr, err := ParseCDR(cdrArchive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		ArchiveName: "R-" + archiveName,
+		Archive:     cdrArchive,
+		CDR:         cdr,
+	}, nil
+}
+
+// sendBillEnvelope is the sendBill request, authenticated with a WS-Security
+// username token
+type sendBillEnvelope struct {
+	XMLName  xml.Name `xml:"soapenv:Envelope"`
+	SOAP     string   `xml:"xmlns:soapenv,attr"`
+	Service  string   `xml:"xmlns:ser,attr"`
+	WSSE     string   `xml:"xmlns:wsse,attr"`
+	Username string   `xml:"soapenv:Header>wsse:Security>wsse:UsernameToken>wsse:Username"`
+	Password string   `xml:"soapenv:Header>wsse:Security>wsse:UsernameToken>wsse:Password"`
+	FileName string   `xml:"soapenv:Body>ser:sendBill>fileName"`
+	Content  string   `xml:"soapenv:Body>ser:sendBill>contentFile"`
+}
+
+// responseEnvelope holds either the sendBill response or a fault; elements
+// match by local name whatever prefixes the service uses
+type responseEnvelope struct {
+	Body struct {
+		SendBillResponse *struct {
+			ApplicationResponse string `xml:"applicationResponse"`
+		} `xml:"sendBillResponse"`
+		Fault *struct {
+			Code   string `xml:"faultcode"`
+			String string `xml:"faultstring"`
+		} `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// decodeBase64 decodes base64 text that may be wrapped over several lines
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
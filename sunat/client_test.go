@@ -0,0 +1,279 @@
+package sunat
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testCredentials = Credentials{Username: "20123456789MODDATOS", Password: "moddatos"}
+
+// receivedSendBill is a sendBill request as the stub reads it
+type receivedSendBill struct {
+	Username string `xml:"Header>Security>UsernameToken>Username"`
+	Password string `xml:"Header>Security>UsernameToken>Password"`
+	FileName string `xml:"Body>sendBill>fileName"`
+	Content  string `xml:"Body>sendBill>contentFile"`
+}
+
+// stubService starts a billService stub; respond answers each sendBill
+func stubService(t *testing.T, respond func(w http.ResponseWriter, r *http.Request, req receivedSendBill)) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request: %v", err)
+			return
+		}
+		var req receivedSendBill
+		if err := xml.Unmarshal(body, &req); err != nil {
+			t.Errorf("request is not a SOAP envelope: %v", err)
+		}
+		respond(w, r, req)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeCDR answers with a zipped CDR, its base64 wrapped over lines
+func writeCDR(w http.ResponseWriter, archive []byte) {
+	encoded := base64.StdEncoding.EncodeToString(archive)
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/"><soap-env:Header/><soap-env:Body><br:sendBillResponse xmlns:br="http://service.sunat.gob.pe"><applicationResponse>%s</applicationResponse></br:sendBillResponse></soap-env:Body></soap-env:Envelope>`, wrapped.String())
+}
+
+// writeFault answers with a SOAP fault
+func writeFault(w http.ResponseWriter, faultCode, faultString string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/"><soap-env:Body><soap-env:Fault><faultcode>%s</faultcode><faultstring>%s</faultstring></soap-env:Fault></soap-env:Body></soap-env:Envelope>`, faultCode, faultString)
+}
+
+func packInvoice(t *testing.T) (string, []byte) {
+	t.Helper()
+	name, archive, err := Pack("20123456789-01-F001-123.xml", []byte("<Invoice/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return name, archive
+}
+
+func TestSendBill(t *testing.T) {
+	tests := []struct {
+		name       string
+		cdr        string
+		wantStatus Status
+	}{
+		{"accepted", cdrXML("F001-123", "0", "La Factura numero F001-123, ha sido aceptada"), StatusAccepted},
+		{"observed", cdrXML("F001-123", "0", "La Factura numero F001-123, ha sido aceptada", "4252 - El dato ingresado como atributo @listName es incorrecto."), StatusObserved},
+		{"rejected", cdrXML("F001-123", "2800", "El dato ingresado en el tipo de documento de identidad del receptor no esta permitido."), StatusRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, archive := packInvoice(t)
+			cdr := cdrArchive(t, "R-20123456789-01-F001-123.xml", tt.cdr)
+
+			server := stubService(t, func(w http.ResponseWriter, r *http.Request, req receivedSendBill) {
+				if action := r.Header.Get("SOAPAction"); action != "urn:sendBill" {
+					t.Errorf("SOAPAction = %q; want urn:sendBill", action)
+				}
+				if req.Username != testCredentials.Username || req.Password != testCredentials.Password {
+					t.Errorf("credentials = %s/%s; want the SOL credentials", req.Username, req.Password)
+				}
+				if req.FileName != name {
+					t.Errorf("fileName = %q; want %q", req.FileName, name)
+				}
+				sent, err := base64.StdEncoding.DecodeString(req.Content)
+				if err != nil {
+					t.Errorf("contentFile: %v", err)
+				}
+				if _, document, err := Unzip(sent); err != nil || string(document) != "<Invoice/>" {
+					t.Errorf("contentFile holds %q (%v); want the document", document, err)
+				}
+				writeCDR(w, cdr)
+			})
+
+			client := NewClient(Config{Endpoint: server.URL})
+			response, err := client.SendBill(context.Background(), testCredentials, name, archive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.ArchiveName != "R-"+name {
+				t.Errorf("archive name = %q; want R-%s", response.ArchiveName, name)
+			}
+			if string(response.Archive) != string(cdr) {
+				t.Error("archive differs from the CDR sent")
+			}
+			if status := response.CDR.Status(); status != tt.wantStatus {
+				t.Errorf("status = %s; want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSendBillFault(t *testing.T) {
+	tests := []struct {
+		name          string
+		faultCode     string
+		faultString   string
+		wantCode      string
+		wantRejected  bool
+		wantTransient bool
+	}{
+		{
+			name:         "rejection",
+			faultCode:    "soap-env:Client.2335",
+			faultString:  "El documento electronico ingresado ha sido alterado",
+			wantCode:     "2335",
+			wantRejected: true,
+		},
+		{
+			name:          "exception in the fault string",
+			faultCode:     "soap-env:Server",
+			faultString:   "0109",
+			wantCode:      "0109",
+			wantTransient: true,
+		},
+		{
+			name:          "service busy",
+			faultCode:     "soap-env:Client.0130",
+			faultString:   "El sistema no puede responder su solicitud",
+			wantCode:      "0130",
+			wantTransient: true,
+		},
+		{
+			name:        "wrong credentials",
+			faultCode:   "soap-env:Client.0102",
+			faultString: "Usuario o contrasena incorrectos",
+			wantCode:    "0102",
+		},
+		{
+			name:          "server fault without code",
+			faultCode:     "soap-env:Server",
+			faultString:   "Internal Error",
+			wantTransient: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := stubService(t, func(w http.ResponseWriter, r *http.Request, req receivedSendBill) {
+				writeFault(w, tt.faultCode, tt.faultString)
+			})
+
+			name, archive := packInvoice(t)
+			_, err := NewClient(Config{Endpoint: server.URL}).SendBill(context.Background(), testCredentials, name, archive)
+
+			var fault *Fault
+			if !errors.As(err, &fault) {
+				t.Fatalf("got %v; want a *Fault", err)
+			}
+			if fault.Code != tt.wantCode {
+				t.Errorf("code = %q; want %q", fault.Code, tt.wantCode)
+			}
+			if fault.Rejected() != tt.wantRejected {
+				t.Errorf("rejected = %v; want %v", fault.Rejected(), tt.wantRejected)
+			}
+			if Transient(err) != tt.wantTransient {
+				t.Errorf("transient = %v; want %v", Transient(err), tt.wantTransient)
+			}
+		})
+	}
+}
+
+func TestSendBillUnavailable(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		want          error
+		wantTransient bool
+	}{
+		{"gateway error page", http.StatusServiceUnavailable, "<html>Service Unavailable</html>", ErrUnavailable, true},
+		{"throttled", http.StatusTooManyRequests, "slow down", ErrUnavailable, true},
+		{"server error without answer", http.StatusInternalServerError, `<Envelope><Body/></Envelope>`, ErrUnavailable, true},
+		{"not SOAP", http.StatusOK, "<html>Login</html", ErrMalformedResponse, false},
+		{"no answer", http.StatusOK, `<Envelope><Body/></Envelope>`, ErrMalformedResponse, false},
+		{"CDR not zipped", http.StatusOK, `<Envelope><Body><sendBillResponse><applicationResponse>PGh0bWw+</applicationResponse></sendBillResponse></Body></Envelope>`, ErrMalformedCDR, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := stubService(t, func(w http.ResponseWriter, r *http.Request, req receivedSendBill) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+
+			name, archive := packInvoice(t)
+			_, err := NewClient(Config{Endpoint: server.URL}).SendBill(context.Background(), testCredentials, name, archive)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v; want %v", err, tt.want)
+			}
+			if Transient(err) != tt.wantTransient {
+				t.Errorf("transient = %v; want %v", Transient(err), tt.wantTransient)
+			}
+		})
+	}
+}
+
+func TestSendBillTimeout(t *testing.T) {
+	// The stub holds every request until the client gives up
+	server := stubService(t, func(w http.ResponseWriter, r *http.Request, req receivedSendBill) {
+		<-r.Context().Done()
+	})
+	name, archive := packInvoice(t)
+
+	client := NewClient(Config{Endpoint: server.URL})
+	client.http.Timeout = 50 * time.Millisecond
+	_, err := client.SendBill(context.Background(), testCredentials, name, archive)
+	if !errors.Is(err, ErrUnavailable) || !Transient(err) {
+		t.Errorf("client timeout: got %v; want a transient ErrUnavailable", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = NewClient(Config{Endpoint: server.URL}).SendBill(ctx, testCredentials, name, archive)
+	if !errors.Is(err, ErrUnavailable) || !Transient(err) {
+		t.Errorf("context deadline: got %v; want a transient ErrUnavailable", err)
+	}
+
+	server.Close()
+	_, err = NewClient(Config{Endpoint: server.URL}).SendBill(context.Background(), testCredentials, name, archive)
+	if !errors.Is(err, ErrUnavailable) || !Transient(err) {
+		t.Errorf("service down: got %v; want a transient ErrUnavailable", err)
+	}
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient(Config{})
+	if client.endpoint != EndpointBeta {
+		t.Errorf("endpoint = %s; want the beta endpoint", client.endpoint)
+	}
+	if client.http.Timeout != DefaultTimeout {
+		t.Errorf("timeout = %s; want %s", client.http.Timeout, DefaultTimeout)
+	}
+
+	client = NewClient(Config{Endpoint: EndpointProduction, TimeoutSeconds: 5})
+	if client.endpoint != EndpointProduction || client.http.Timeout != 5*time.Second {
+		t.Errorf("got %s with %s; want the configured endpoint and timeout", client.endpoint, client.http.Timeout)
+	}
+}
@@ -0,0 +1,124 @@
+// Package sunat is a client of SUNAT's electronic invoicing web service
+// (billService). Pack zips a signed UBL document and SendBill sends it with
+// the issuer's SOL credentials, returning the CDR (Constancia de Recepción)
+// SUNAT answers with; ParseCDR reads the outcome recorded in a CDR.
+package sunat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// billService endpoints
+const (
+	EndpointBeta       = "https://e-beta.sunat.gob.pe/ol-ti-itcpfegem-beta/billService"
+	EndpointProduction = "https://e-factura.sunat.gob.pe/ol-ti-itcpfegem/billService"
+)
+
+// DefaultTimeout bounds a call to the web service
+const DefaultTimeout = 60 * time.Second
+
+// Config configures the client
+type Config struct {
+	// Endpoint is the billService URL; defaults to EndpointBeta
+	Endpoint string `json:"endpoint"`
+
+	// TimeoutSeconds bounds each call; defaults to DefaultTimeout
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+// Credentials are the SOL credentials of the issuer. The username is the
+// issuer's RUC followed by its SOL user, e.g. 20123456789MODDATOS.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Errors returned by the package; they are wrapped with details
+var (
+	ErrUnavailable       = errors.New("sunat: service unavailable")
+	ErrMalformedResponse = errors.New("sunat: malformed response")
+	ErrMalformedCDR      = errors.New("sunat: malformed CDR")
+)
+
+// Fault is a SOAP fault returned by SUNAT. Its code is the SUNAT error
+// code: 0100-1999 are exceptions, where SUNAT did not process the document,
+// and 2000-3999 rejections of the document.
+type Fault struct {
+	// FaultCode is the SOAP fault code as sent, e.g. soap-env:Client.2335
+	FaultCode string
+
+	// Code is the SUNAT error code taken from the fault, if any
+	Code    string
+	Message string
+}
+
+// Error implements error
+func (f *Fault) Error() string {
+	if f.Code == "" {
+		return fmt.Sprintf("sunat: fault %s: %s", f.FaultCode, f.Message)
+	}
+	return fmt.Sprintf("sunat: error %s: %s", f.Code, f.Message)
+}
+
+// Rejected reports whether SUNAT rejected the document itself
+func (f *Fault) Rejected() bool {
+	n, ok := f.number()
+	return ok && n >= 2000 && n < 4000
+}
+
+// Transient reports whether SUNAT could not process the request at the
+// moment, so the same document may be sent again
+func (f *Fault) Transient() bool {
+	n, ok := f.number()
+	if !ok {
+		return strings.Contains(f.FaultCode, "Server")
+	}
+	switch {
+	case n == 100, n >= 109 && n <= 111, n >= 130 && n <= 138:
+		return true
+	}
+	return false
+}
+
+func (f *Fault) number() (int, bool) {
+	n, err := strconv.Atoi(f.Code)
+	return n, err == nil
+}
+
+// faultNumber matches a four-digit SUNAT error code
+var faultNumber = regexp.MustCompile(`^\d{4}$`)
+
+// newFault extracts the SUNAT error code of a SOAP fault
+func newFault(faultCode, faultString string) *Fault {
+	fault := &Fault{
+		FaultCode: strings.TrimSpace(faultCode),
+		Message:   strings.TrimSpace(faultString),
+	}
+	if i := strings.LastIndexByte(fault.FaultCode, '.'); i >= 0 && faultNumber.MatchString(fault.FaultCode[i+1:]) {
+		fault.Code = fault.FaultCode[i+1:]
+	} else if faultNumber.MatchString(fault.Message) {
+		fault.Code = fault.Message
+	}
+	return fault
+}
+
+// Transient reports whether an error of SendBill may go away when the
+// document is sent again: the service could not be reached, timed out or
+// answered with an exception meaning it could not process the request
+func Transient(err error) bool {
+	var fault *Fault
+	if errors.As(err, &fault) {
+		return fault.Transient()
+	}
+	var netErr net.Error
+	return errors.Is(err, ErrUnavailable) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netErr)
+}
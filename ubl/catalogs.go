@@ -0,0 +1,134 @@
+package ubl
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Catalog 01: document types
+const (
+	DocumentFactura    = "01"
+	DocumentBoleta     = "03"
+	DocumentCreditNote = "07"
+	DocumentDebitNote  = "08"
+)
+
+// Catalog 51: operation types of invoices
+const (
+	OperationInternalSale = "0101"
+	OperationExport       = "0200"
+)
+
+// Catalog 06: identity document types
+const (
+	IdentityOther     = "0"
+	IdentityDNI       = "1"
+	IdentityForeigner = "4"
+	IdentityRUC       = "6"
+	IdentityPassport  = "7"
+)
+
+// IdentityTypes lists the catalog 06 codes accepted for parties
+var IdentityTypes = map[string]string{
+	IdentityOther:     "Doc. trib. no dom. sin RUC",
+	IdentityDNI:       "DNI",
+	IdentityForeigner: "Carnet de extranjeria",
+	IdentityRUC:       "RUC",
+	IdentityPassport:  "Pasaporte",
+	"A":               "Ced. Diplomatica de identidad",
+}
+
+// IdentityType guesses the catalog 06 type of a tax ID: 11 digits are a
+// RUC, 8 digits a DNI, anything else another document
+func IdentityType(taxID string) string {
+	digits := strings.Trim(taxID, "0123456789") == ""
+	switch {
+	case digits && len(taxID) == 11:
+		return IdentityRUC
+	case digits && len(taxID) == 8:
+		return IdentityDNI
+	}
+	return IdentityOther
+}
+
+// CreditNoteReasons is catalog 09, the reasons for credit notes
+var CreditNoteReasons = map[string]string{
+	"01": "Anulacion de la operacion",
+	"02": "Anulacion por error en el RUC",
+	"03": "Correccion por error en la descripcion",
+	"04": "Descuento global",
+	"05": "Descuento por item",
+	"06": "Devolucion total",
+	"07": "Devolucion por item",
+	"08": "Bonificacion",
+	"09": "Disminucion en el valor",
+	"10": "Otros conceptos",
+	"11": "Ajustes de operaciones de exportacion",
+	"12": "Ajustes afectos al IVAP",
+	"13": "Ajustes - montos y/o fechas de pago",
+}
+
+// DebitNoteReasons is catalog 10, the reasons for debit notes
+var DebitNoteReasons = map[string]string{
+	"01": "Intereses por mora",
+	"02": "Aumento en el valor",
+	"03": "Penalidades/ otros conceptos",
+	"11": "Ajustes de operaciones de exportacion",
+	"12": "Ajustes afectos al IVAP",
+}
+
+// TaxCategory is how a line is taxed: the tax of catalog 05 together with
+// the IGV affectation of catalog 07
+type TaxCategory string
+
+// Tax categories
+const (
+	TaxIGV        TaxCategory = "IGV"
+	TaxIVAP       TaxCategory = "IVAP"
+	TaxExport     TaxCategory = "EXP"
+	TaxExempt     TaxCategory = "EXO"
+	TaxUnaffected TaxCategory = "INA"
+)
+
+// taxScheme is the catalog 05 tax and catalog 07 affectation of a category
+type taxScheme struct {
+	id          string
+	name        string
+	typeCode    string
+	affectation string
+}
+
+var taxSchemes = map[TaxCategory]taxScheme{
+	TaxIGV:        {id: "1000", name: "IGV", typeCode: "VAT", affectation: "10"},
+	TaxIVAP:       {id: "1016", name: "IVAP", typeCode: "VAT", affectation: "17"},
+	TaxExport:     {id: "9995", name: "EXP", typeCode: "FRE", affectation: "40"},
+	TaxExempt:     {id: "9997", name: "EXO", typeCode: "VAT", affectation: "20"},
+	TaxUnaffected: {id: "9998", name: "INA", typeCode: "FRE", affectation: "30"},
+}
+
+// taxCategoryOrder is the order tax subtotals are rendered in
+var taxCategoryOrder = []TaxCategory{TaxIGV, TaxIVAP, TaxExport, TaxExempt, TaxUnaffected}
+
+// taxCategoryAliases maps tax codes used on invoice lines to categories:
+// category names, catalog 05 codes and UN/ECE 5305 codes
+var taxCategoryAliases = map[string]TaxCategory{
+	"IGV": TaxIGV, "1000": TaxIGV, "VAT": TaxIGV, "S": TaxIGV,
+	"IVAP": TaxIVAP, "1016": TaxIVAP,
+	"EXP": TaxExport, "9995": TaxExport, "G": TaxExport,
+	"EXO": TaxExempt, "9997": TaxExempt, "E": TaxExempt,
+	"INA": TaxUnaffected, "9998": TaxUnaffected, "O": TaxUnaffected,
+}
+
+// ParseTaxCategory maps the tax code of an invoice line to its category.
+// Lines without a code are IGV when taxed and unaffected otherwise.
+func ParseTaxCategory(code string, rate decimal.Decimal) (TaxCategory, bool) {
+	if code == "" {
+		if rate.IsPositive() {
+			return TaxIGV, true
+		}
+		return TaxUnaffected, true
+	}
+	category, ok := taxCategoryAliases[strings.ToUpper(strings.TrimSpace(code))]
+	return category, ok
+}
@@ -0,0 +1,207 @@
+// Package ubl builds UBL 2.1 Invoice, CreditNote and DebitNote XML following
+// the SUNAT (Peru) electronic invoicing customization 2.0. The caller maps
+// its invoice and parties into a Document; Marshal computes the tax totals
+// and legal monetary totals from the lines and renders the XML, leaving an
+// empty UBLExtension for the digital signature.
+package ubl
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DocumentType is the UBL document a Document renders as
+type DocumentType string
+
+// Document types
+const (
+	TypeInvoice    DocumentType = "Invoice"
+	TypeCreditNote DocumentType = "CreditNote"
+	TypeDebitNote  DocumentType = "DebitNote"
+)
+
+// Document is an electronic invoice, credit note or debit note
+type Document struct {
+	Type DocumentType
+
+	// DocumentTypeCode is the SUNAT catalog 01 code: 01 factura, 03 boleta,
+	// 07 credit note, 08 debit note
+	DocumentTypeCode string
+
+	// OperationType is the catalog 51 code of invoices; defaults to 0101
+	// (internal sale)
+	OperationType string
+
+	// ID is the series and correlative number, e.g. F001-123
+	ID        string
+	IssueDate time.Time
+	IssueTime *time.Time
+	DueDate   *time.Time
+	Currency  string
+
+	Supplier Party
+	Customer Party
+	Lines    []Line
+
+	// Notes are free text notes; the amount in words legend is added
+	Notes []string
+
+	// Reference and Discrepancy are required for credit and debit notes
+	Reference   *Reference
+	Discrepancy *Discrepancy
+}
+
+// Party is the supplier or customer of a document
+type Party struct {
+	// IdentityType is the catalog 06 code of TaxID: 6 RUC, 1 DNI, 4 foreigner
+	// card, 7 passport, 0 other
+	IdentityType     string
+	TaxID            string
+	RegistrationName string
+	TradeName        string
+	Address          *Address
+}
+
+// Address is a party's registered address
+type Address struct {
+	// Ubigeo is the INEI geographic code of the district
+	Ubigeo string
+
+	// EstablishmentCode is the SUNAT annex code of the issuing
+	// establishment; 0000 is the tax domicile
+	EstablishmentCode string
+
+	Line        string
+	District    string
+	Province    string
+	Department  string
+	CountryCode string
+}
+
+// Line is a line of a document. Amounts exclude tax; discounts are
+// deducted from quantity × unit price.
+type Line struct {
+	ItemCode    string
+	Description string
+	Quantity    decimal.Decimal
+	UnitCode    string
+	UnitPrice   decimal.Decimal
+	Discount    decimal.Decimal
+
+	// TaxCategory and TaxRate tell how the line is taxed; the rate is in
+	// percent
+	TaxCategory TaxCategory
+	TaxRate     decimal.Decimal
+}
+
+// Reference is the document a credit or debit note adjusts
+type Reference struct {
+	ID               string
+	DocumentTypeCode string
+}
+
+// Discrepancy is why a credit or debit note was issued
+type Discrepancy struct {
+	// Code is a catalog 09 (credit notes) or 10 (debit notes) code
+	Code        string
+	Description string
+}
+
+// documentID matches SUNAT series-correlative numbers of electronic
+// documents, e.g. F001-123 or B001-00004567
+var documentID = regexp.MustCompile(`^[FB][A-Z0-9]{3}-[0-9]{1,8}$`)
+
+// Validate checks that the document has what SUNAT requires
+func (d *Document) Validate() error {
+	switch d.Type {
+	case TypeInvoice:
+		if d.DocumentTypeCode != DocumentFactura && d.DocumentTypeCode != DocumentBoleta {
+			return fmt.Errorf("document_type_code: invoices are 01 (factura) or 03 (boleta), got %q", d.DocumentTypeCode)
+		}
+	case TypeCreditNote, TypeDebitNote:
+		if d.Reference == nil || d.Reference.ID == "" {
+			return fmt.Errorf("reference: notes must reference the document they adjust")
+		}
+		if d.Discrepancy == nil || d.Discrepancy.Code == "" {
+			return fmt.Errorf("discrepancy: notes need a reason code")
+		}
+		catalog := CreditNoteReasons
+		if d.Type == TypeDebitNote {
+			catalog = DebitNoteReasons
+		}
+		if _, ok := catalog[d.Discrepancy.Code]; !ok {
+			return fmt.Errorf("discrepancy: unknown reason code %q", d.Discrepancy.Code)
+		}
+	default:
+		return fmt.Errorf("type: unknown document type %q", d.Type)
+	}
+
+	if !documentID.MatchString(d.ID) {
+		return fmt.Errorf("id: %q is not a series-correlative number such as F001-123", d.ID)
+	}
+	if d.IssueDate.IsZero() {
+		return fmt.Errorf("issue_date is required")
+	}
+	if len(d.Currency) != 3 {
+		return fmt.Errorf("currency: expected an ISO 4217 code, got %q", d.Currency)
+	}
+
+	if d.Supplier.IdentityType != IdentityRUC || len(d.Supplier.TaxID) != 11 {
+		return fmt.Errorf("supplier: the issuer must be identified by its 11-digit RUC")
+	}
+	if d.Supplier.RegistrationName == "" {
+		return fmt.Errorf("supplier: registration name is required")
+	}
+	if d.Supplier.Address == nil || d.Supplier.Address.EstablishmentCode == "" {
+		return fmt.Errorf("supplier: the establishment code of the address is required")
+	}
+	if d.Customer.TaxID == "" || d.Customer.RegistrationName == "" {
+		return fmt.Errorf("customer: tax ID and registration name are required")
+	}
+	if _, ok := IdentityTypes[d.Customer.IdentityType]; !ok {
+		return fmt.Errorf("customer: unknown identity type %q", d.Customer.IdentityType)
+	}
+	if d.DocumentTypeCode == DocumentFactura && d.Customer.IdentityType != IdentityRUC && d.operationType() == OperationInternalSale {
+		return fmt.Errorf("customer: facturas of internal sales are issued to a RUC")
+	}
+
+	if len(d.Lines) == 0 {
+		return fmt.Errorf("lines: at least one line is required")
+	}
+	for i, line := range d.Lines {
+		if line.Description == "" {
+			return fmt.Errorf("lines[%d]: description is required", i)
+		}
+		if !line.Quantity.IsPositive() {
+			return fmt.Errorf("lines[%d]: quantity must be positive", i)
+		}
+		if line.UnitPrice.IsNegative() || line.Discount.IsNegative() {
+			return fmt.Errorf("lines[%d]: unit price and discount must not be negative", i)
+		}
+		if _, ok := taxSchemes[line.TaxCategory]; !ok {
+			return fmt.Errorf("lines[%d]: unknown tax category %q", i, line.TaxCategory)
+		}
+		if line.TaxCategory != TaxIGV && line.TaxCategory != TaxIVAP && !line.TaxRate.IsZero() {
+			return fmt.Errorf("lines[%d]: %s lines are not taxed, got rate %s", i, line.TaxCategory, line.TaxRate)
+		}
+	}
+
+	return nil
+}
+
+// operationType returns the operation type of the document
+func (d *Document) operationType() string {
+	if d.OperationType == "" {
+		return OperationInternalSale
+	}
+	return d.OperationType
+}
+
+// Filename returns the SUNAT file name of the document without extension:
+// RUC-type-series-number
+func (d *Document) Filename() string {
+	return d.Supplier.TaxID + "-" + d.DocumentTypeCode + "-" + d.ID
+}
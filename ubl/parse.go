@@ -0,0 +1,377 @@
+package ubl
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices/totals"
+)
+
+// ErrNotUBL is returned by Unmarshal for XML that is not a UBL 2.1 Invoice,
+// CreditNote or DebitNote
+var ErrNotUBL = errors.New("not a UBL 2.1 invoice, credit note or debit note")
+
+// Received is a document read from XML issued by someone else. The totals
+// are the ones the issuer declared, to be compared with the ones computed
+// from the lines.
+type Received struct {
+	Document
+
+	TaxAmount     decimal.Decimal
+	PayableAmount decimal.Decimal
+}
+
+// Unmarshal reads a UBL 2.1 Invoice, CreditNote or DebitNote. Elements
+// match by local name whatever prefixes the issuer uses. Lines keep the
+// first tax subtotal of a known category; other taxes, such as ISC or
+// ICBPER, and charges are not read, so the declared totals then differ from
+// the computed ones. The document is not validated against SUNAT's rules,
+// which documents of other issuers may not follow to the letter.
+func Unmarshal(data []byte) (*Received, error) {
+	var root receivedDocument
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotUBL, err)
+	}
+
+	documentType := DocumentType(root.XMLName.Local)
+	if namespace, ok := namespaces[documentType]; !ok || root.XMLName.Space != namespace {
+		return nil, fmt.Errorf("%w: root element is {%s}%s", ErrNotUBL, root.XMLName.Space, root.XMLName.Local)
+	}
+
+	received := &Received{Document: Document{
+		Type:     documentType,
+		ID:       strings.TrimSpace(root.ID),
+		Currency: strings.TrimSpace(root.DocumentCurrencyCode),
+		Supplier: root.Supplier.party(),
+		Customer: root.Customer.party(),
+	}}
+	d := &received.Document
+
+	if d.ID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	issueDate, err := time.Parse("2006-01-02", strings.TrimSpace(root.IssueDate))
+	if err != nil {
+		return nil, fmt.Errorf("issue_date: %v", err)
+	}
+	d.IssueDate = issueDate
+	if issueTime, ok := parseTime(root.IssueTime); ok {
+		d.IssueTime = &issueTime
+	}
+	if due, ok := root.dueDate(); ok {
+		d.DueDate = &due
+	}
+	if d.Supplier.TaxID == "" {
+		return nil, fmt.Errorf("supplier: tax ID is required")
+	}
+
+	for _, n := range root.Notes {
+		// Legends such as the amount in words carry a locale code
+		if n.LanguageLocaleID == "" && strings.TrimSpace(n.Value) != "" {
+			d.Notes = append(d.Notes, strings.TrimSpace(n.Value))
+		}
+	}
+
+	var lines []receivedLine
+	switch documentType {
+	case TypeInvoice:
+		d.DocumentTypeCode = strings.TrimSpace(root.InvoiceTypeCode.Value)
+		d.OperationType = strings.TrimSpace(root.InvoiceTypeCode.ListID)
+		if d.OperationType == "" {
+			d.OperationType = strings.TrimSpace(root.ProfileID)
+		}
+		lines = root.InvoiceLines
+	case TypeCreditNote:
+		d.DocumentTypeCode = DocumentCreditNote
+		lines = root.CreditNoteLines
+	case TypeDebitNote:
+		d.DocumentTypeCode = DocumentDebitNote
+		lines = root.DebitNoteLines
+	}
+
+	if documentType != TypeInvoice {
+		if r := root.BillingReference; r != nil && strings.TrimSpace(r.ID) != "" {
+			d.Reference = &Reference{
+				ID:               strings.TrimSpace(r.ID),
+				DocumentTypeCode: strings.TrimSpace(r.DocumentTypeCode),
+			}
+		}
+		if r := root.Discrepancy; r != nil {
+			d.Discrepancy = &Discrepancy{
+				Code:        strings.TrimSpace(r.ResponseCode),
+				Description: strings.TrimSpace(r.Description),
+			}
+			if d.Reference == nil && strings.TrimSpace(r.ReferenceID) != "" {
+				d.Reference = &Reference{ID: strings.TrimSpace(r.ReferenceID)}
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("lines: at least one line is required")
+	}
+	for i, line := range lines {
+		parsed, err := line.line()
+		if err != nil {
+			return nil, fmt.Errorf("lines[%d]: %v", i, err)
+		}
+		d.Lines = append(d.Lines, parsed)
+	}
+
+	for _, total := range root.TaxTotals {
+		amount, err := parseAmount(total.TaxAmount)
+		if err != nil {
+			return nil, fmt.Errorf("tax_total: %v", err)
+		}
+		received.TaxAmount = received.TaxAmount.Add(amount)
+	}
+	monetary := root.LegalMonetaryTotal
+	if monetary == nil {
+		monetary = root.RequestedMonetaryTotal
+	}
+	if monetary != nil {
+		payable, err := parseAmount(monetary.PayableAmount)
+		if err != nil {
+			return nil, fmt.Errorf("payable_amount: %v", err)
+		}
+		received.PayableAmount = payable
+	}
+
+	return received, nil
+}
+
+// parseTime reads an xs:time, with or without fractions and zone
+func parseTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"15:04:05", "15:04:05Z07:00", "15:04:05.999999999", "15:04:05.999999999Z07:00"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseAmount reads a monetary amount or quantity; empty values are zero
+func parseAmount(value string) (decimal.Decimal, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(value)
+}
+
+// XML structure read. Tags carry no namespace so they match by local name.
+
+type receivedDocument struct {
+	XMLName              xml.Name
+	ProfileID            string               `xml:"ProfileID"`
+	ID                   string               `xml:"ID"`
+	IssueDate            string               `xml:"IssueDate"`
+	IssueTime            string               `xml:"IssueTime"`
+	DueDate              string               `xml:"DueDate"`
+	InvoiceTypeCode      receivedCode         `xml:"InvoiceTypeCode"`
+	Notes                []receivedNote       `xml:"Note"`
+	DocumentCurrencyCode string               `xml:"DocumentCurrencyCode"`
+	Discrepancy          *receivedDiscrepancy `xml:"DiscrepancyResponse"`
+	BillingReference     *receivedReference   `xml:"BillingReference>InvoiceDocumentReference"`
+	Supplier             receivedParty        `xml:"AccountingSupplierParty>Party"`
+	Customer             receivedParty        `xml:"AccountingCustomerParty>Party"`
+	PaymentDueDates      []string             `xml:"PaymentTerms>PaymentDueDate"`
+	TaxTotals            []struct {
+		TaxAmount string `xml:"TaxAmount"`
+	} `xml:"TaxTotal"`
+
+	LegalMonetaryTotal     *receivedTotal `xml:"LegalMonetaryTotal"`
+	RequestedMonetaryTotal *receivedTotal `xml:"RequestedMonetaryTotal"`
+
+	InvoiceLines    []receivedLine `xml:"InvoiceLine"`
+	CreditNoteLines []receivedLine `xml:"CreditNoteLine"`
+	DebitNoteLines  []receivedLine `xml:"DebitNoteLine"`
+}
+
+// dueDate is the invoice's due date or, for invoices on credit, the date
+// of the last instalment
+func (r *receivedDocument) dueDate() (time.Time, bool) {
+	candidates := append([]string{r.DueDate}, r.PaymentDueDates...)
+	var due time.Time
+	for _, value := range candidates {
+		t, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+		if err == nil && t.After(due) {
+			due = t
+		}
+	}
+	return due, !due.IsZero()
+}
+
+type receivedCode struct {
+	Value  string `xml:",chardata"`
+	ListID string `xml:"listID,attr"`
+}
+
+type receivedNote struct {
+	Value            string `xml:",chardata"`
+	LanguageLocaleID string `xml:"languageLocaleID,attr"`
+}
+
+type receivedDiscrepancy struct {
+	ReferenceID  string `xml:"ReferenceID"`
+	ResponseCode string `xml:"ResponseCode"`
+	Description  string `xml:"Description"`
+}
+
+type receivedReference struct {
+	ID               string `xml:"ID"`
+	DocumentTypeCode string `xml:"DocumentTypeCode"`
+}
+
+type receivedParty struct {
+	Identifications []struct {
+		Value    string `xml:",chardata"`
+		SchemeID string `xml:"schemeID,attr"`
+	} `xml:"PartyIdentification>ID"`
+	TradeName        string           `xml:"PartyName>Name"`
+	RegistrationName string           `xml:"PartyLegalEntity>RegistrationName"`
+	Address          *receivedAddress `xml:"PartyLegalEntity>RegistrationAddress"`
+}
+
+// party maps the first identification of the party; without a scheme the
+// identity type is guessed from the tax ID
+func (p *receivedParty) party() Party {
+	party := Party{
+		RegistrationName: strings.TrimSpace(p.RegistrationName),
+		TradeName:        strings.TrimSpace(p.TradeName),
+	}
+	if len(p.Identifications) > 0 {
+		party.TaxID = strings.TrimSpace(p.Identifications[0].Value)
+		party.IdentityType = strings.TrimSpace(p.Identifications[0].SchemeID)
+	}
+	if party.IdentityType == "" {
+		party.IdentityType = IdentityType(party.TaxID)
+	}
+	if a := p.Address; a != nil {
+		party.Address = &Address{
+			Ubigeo:            strings.TrimSpace(a.ID),
+			EstablishmentCode: strings.TrimSpace(a.AddressTypeCode),
+			Line:              strings.TrimSpace(a.Line),
+			District:          strings.TrimSpace(a.District),
+			Province:          strings.TrimSpace(a.CityName),
+			Department:        strings.TrimSpace(a.CountrySubentity),
+			CountryCode:       strings.TrimSpace(a.CountryCode),
+		}
+	}
+	return party
+}
+
+type receivedAddress struct {
+	ID               string `xml:"ID"`
+	AddressTypeCode  string `xml:"AddressTypeCode"`
+	CityName         string `xml:"CityName"`
+	CountrySubentity string `xml:"CountrySubentity"`
+	District         string `xml:"District"`
+	Line             string `xml:"AddressLine>Line"`
+	CountryCode      string `xml:"Country>IdentificationCode"`
+}
+
+type receivedTotal struct {
+	PayableAmount string `xml:"PayableAmount"`
+}
+
+type receivedQuantity struct {
+	Value    string `xml:",chardata"`
+	UnitCode string `xml:"unitCode,attr"`
+}
+
+type receivedLine struct {
+	InvoicedQuantity *receivedQuantity `xml:"InvoicedQuantity"`
+	CreditedQuantity *receivedQuantity `xml:"CreditedQuantity"`
+	DebitedQuantity  *receivedQuantity `xml:"DebitedQuantity"`
+	LineExtension    string            `xml:"LineExtensionAmount"`
+	AllowanceCharges []struct {
+		ChargeIndicator string `xml:"ChargeIndicator"`
+		Amount          string `xml:"Amount"`
+	} `xml:"AllowanceCharge"`
+	TaxSubtotals []struct {
+		Percent  string `xml:"TaxCategory>Percent"`
+		SchemeID string `xml:"TaxCategory>TaxScheme>ID"`
+	} `xml:"TaxTotal>TaxSubtotal"`
+	Descriptions []string `xml:"Item>Description"`
+	ItemCode     string   `xml:"Item>SellersItemIdentification>ID"`
+	PriceAmount  string   `xml:"Price>PriceAmount"`
+}
+
+// line maps a document line; allowances are discounts, the rate is kept
+// only for taxed categories. Lines without allowances, such as those of
+// notes, are discounted by what their amount falls short of quantity ×
+// price.
+func (l *receivedLine) line() (Line, error) {
+	q := l.InvoicedQuantity
+	if q == nil {
+		q = l.CreditedQuantity
+	}
+	if q == nil {
+		q = l.DebitedQuantity
+	}
+	if q == nil {
+		return Line{}, fmt.Errorf("quantity is required")
+	}
+	quantity, err := parseAmount(q.Value)
+	if err != nil {
+		return Line{}, fmt.Errorf("quantity: %v", err)
+	}
+	unitPrice, err := parseAmount(l.PriceAmount)
+	if err != nil {
+		return Line{}, fmt.Errorf("price: %v", err)
+	}
+
+	line := Line{
+		ItemCode:    strings.TrimSpace(l.ItemCode),
+		Description: strings.TrimSpace(strings.Join(l.Descriptions, " ")),
+		Quantity:    quantity,
+		UnitCode:    strings.TrimSpace(q.UnitCode),
+		UnitPrice:   unitPrice,
+		TaxCategory: TaxUnaffected,
+	}
+
+	for _, allowance := range l.AllowanceCharges {
+		if strings.TrimSpace(allowance.ChargeIndicator) != "false" {
+			continue
+		}
+		amount, err := parseAmount(allowance.Amount)
+		if err != nil {
+			return Line{}, fmt.Errorf("allowance: %v", err)
+		}
+		line.Discount = line.Discount.Add(amount)
+	}
+	if len(l.AllowanceCharges) == 0 {
+		net, err := parseAmount(l.LineExtension)
+		if err != nil {
+			return Line{}, fmt.Errorf("line extension amount: %v", err)
+		}
+		if gross := quantity.Mul(unitPrice).Round(totals.Scale); net.IsPositive() && gross.GreaterThan(net) {
+			line.Discount = gross.Sub(net)
+		}
+	}
+
+	for _, subtotal := range l.TaxSubtotals {
+		category, ok := taxCategoryAliases[strings.TrimSpace(subtotal.SchemeID)]
+		if !ok {
+			continue
+		}
+		line.TaxCategory = category
+		if category == TaxIGV || category == TaxIVAP {
+			rate, err := parseAmount(subtotal.Percent)
+			if err != nil {
+				return Line{}, fmt.Errorf("tax percent: %v", err)
+			}
+			line.TaxRate = rate
+		}
+		break
+	}
+
+	return line, nil
+}
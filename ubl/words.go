@@ -0,0 +1,91 @@
+package ubl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// currencyNames are the plural Spanish names of common currencies
+var currencyNames = map[string]string{
+	"PEN": "SOLES",
+	"USD": "DÓLARES AMERICANOS",
+	"EUR": "EUROS",
+}
+
+// AmountInWords spells an amount in Spanish as SUNAT legend 1000 expects,
+// e.g. CIENTO DIECIOCHO CON 50/100 SOLES
+func AmountInWords(amount decimal.Decimal, currency string) string {
+	rounded := amount.Abs().Round(2)
+	integer := rounded.IntPart()
+	cents := rounded.Sub(decimal.NewFromInt(integer)).Shift(2).IntPart()
+
+	name, ok := currencyNames[strings.ToUpper(currency)]
+	if !ok {
+		name = strings.ToUpper(currency)
+	}
+	return fmt.Sprintf("%s CON %02d/100 %s", spellNumber(integer, false), cents, name)
+}
+
+var (
+	spanishUnits = [...]string{"CERO", "UNO", "DOS", "TRES", "CUATRO", "CINCO", "SEIS", "SIETE", "OCHO", "NUEVE",
+		"DIEZ", "ONCE", "DOCE", "TRECE", "CATORCE", "QUINCE", "DIECISÉIS", "DIECISIETE", "DIECIOCHO", "DIECINUEVE",
+		"VEINTE", "VEINTIUNO", "VEINTIDÓS", "VEINTITRÉS", "VEINTICUATRO", "VEINTICINCO", "VEINTISÉIS", "VEINTISIETE",
+		"VEINTIOCHO", "VEINTINUEVE"}
+	spanishTens     = [...]string{"", "", "", "TREINTA", "CUARENTA", "CINCUENTA", "SESENTA", "SETENTA", "OCHENTA", "NOVENTA"}
+	spanishHundreds = [...]string{"", "CIENTO", "DOSCIENTOS", "TRESCIENTOS", "CUATROCIENTOS", "QUINIENTOS",
+		"SEISCIENTOS", "SETECIENTOS", "OCHOCIENTOS", "NOVECIENTOS"}
+)
+
+// spellNumber spells a non-negative integer in Spanish. Apocope shortens a
+// final UNO to UN, as before MIL and MILLONES.
+func spellNumber(n int64, apocope bool) string {
+	switch {
+	case n >= 1_000_000:
+		millions, rest := n/1_000_000, n%1_000_000
+		words := "UN MILLÓN"
+		if millions > 1 {
+			words = spellNumber(millions, true) + " MILLONES"
+		}
+		if rest > 0 {
+			words += " " + spellNumber(rest, apocope)
+		}
+		return words
+	case n >= 1000:
+		thousands, rest := n/1000, n%1000
+		words := "MIL"
+		if thousands > 1 {
+			words = spellNumber(thousands, true) + " MIL"
+		}
+		if rest > 0 {
+			words += " " + spellNumber(rest, apocope)
+		}
+		return words
+	case n >= 100:
+		hundreds, rest := n/100, n%100
+		if rest == 0 {
+			if hundreds == 1 {
+				return "CIEN"
+			}
+			return spanishHundreds[hundreds]
+		}
+		return spanishHundreds[hundreds] + " " + spellNumber(rest, apocope)
+	case n >= 30:
+		tens, rest := n/10, n%10
+		if rest == 0 {
+			return spanishTens[tens]
+		}
+		return spanishTens[tens] + " Y " + spellNumber(rest, apocope)
+	}
+
+	if apocope {
+		switch n {
+		case 1:
+			return "UN"
+		case 21:
+			return "VEINTIÚN"
+		}
+	}
+	return spanishUnits[n]
+}
@@ -0,0 +1,611 @@
+package ubl
+
+import (
+	"encoding/xml"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/Abraxas-365/fuckturamelo/invoices/totals"
+)
+
+// XML namespaces of the documents
+const (
+	NamespaceInvoice    = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	NamespaceCreditNote = "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2"
+	NamespaceDebitNote  = "urn:oasis:names:specification:ubl:schema:xsd:DebitNote-2"
+	NamespaceCAC        = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	NamespaceCBC        = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+	NamespaceEXT        = "urn:oasis:names:specification:ubl:schema:xsd:CommonExtensionComponents-2"
+	NamespaceDS         = "http://www.w3.org/2000/09/xmldsig#"
+)
+
+// SignatureID is the Id of the XMLDSig signature the document's
+// cac:Signature refers to
+const SignatureID = "SignatureSP"
+
+// Code list and scheme attributes
+const (
+	agencySUNAT  = "PE:SUNAT"
+	agencyUNECE  = "United Nations Economic Commission for Europe"
+	catalogURI   = "urn:pe:gob:sunat:cpe:see:gem:catalogos:catalogo"
+	legendAmount = "1000"
+)
+
+// namespaces of each document type
+var namespaces = map[DocumentType]string{
+	TypeInvoice:    NamespaceInvoice,
+	TypeCreditNote: NamespaceCreditNote,
+	TypeDebitNote:  NamespaceDebitNote,
+}
+
+// Marshal validates the document and renders it as UBL 2.1 XML
+func Marshal(d *Document) ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	root, err := build(d)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// build maps the document onto its XML structure
+func build(d *Document) (*document, error) {
+	input := make([]totals.Line, len(d.Lines))
+	for i, line := range d.Lines {
+		input[i] = totals.Line{
+			Quantity:       line.Quantity,
+			UnitPrice:      line.UnitPrice,
+			DiscountAmount: line.Discount,
+			TaxCode:        string(line.TaxCategory),
+			TaxRate:        line.TaxRate,
+		}
+	}
+	amounts, err := totals.Calculate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &document{
+		XMLName:         xml.Name{Local: string(d.Type)},
+		Xmlns:           namespaces[d.Type],
+		XmlnsCAC:        NamespaceCAC,
+		XmlnsCBC:        NamespaceCBC,
+		XmlnsEXT:        NamespaceEXT,
+		XmlnsDS:         NamespaceDS,
+		UBLVersionID:    "2.1",
+		CustomizationID: identifier{Value: "2.0", SchemeAgencyName: agencySUNAT},
+		ID:              d.ID,
+		IssueDate:       d.IssueDate.Format("2006-01-02"),
+		Notes: []note{{
+			Value:            AmountInWords(amounts.Total, d.Currency),
+			LanguageLocaleID: legendAmount,
+		}},
+		DocumentCurrencyCode: code{
+			Value:          d.Currency,
+			ListID:         "ISO 4217 Alpha",
+			ListName:       "Currency",
+			ListAgencyName: agencyUNECE,
+		},
+		LineCountNumeric: len(d.Lines),
+		Signature: signature{
+			ID: d.Supplier.TaxID,
+			SignatoryParty: signatoryParty{
+				PartyIdentification: partyIdentification{ID: identifier{Value: d.Supplier.TaxID}},
+				PartyName:           &partyName{Name: d.Supplier.RegistrationName},
+			},
+			Attachment: signatureAttachment{URI: "#" + SignatureID},
+		},
+		Supplier: partyWrapper{Party: buildParty(d.Supplier)},
+		Customer: partyWrapper{Party: buildParty(d.Customer)},
+	}
+	if d.IssueTime != nil {
+		root.IssueTime = d.IssueTime.Format("15:04:05")
+	}
+	for _, text := range d.Notes {
+		root.Notes = append(root.Notes, note{Value: text})
+	}
+
+	if d.Type == TypeInvoice {
+		root.ProfileID = &identifier{
+			Value:            d.operationType(),
+			SchemeName:       "Tipo de Operacion",
+			SchemeAgencyName: agencySUNAT,
+			SchemeURI:        catalogURI + "51",
+		}
+		root.InvoiceTypeCode = &code{
+			Value:          d.DocumentTypeCode,
+			ListID:         d.operationType(),
+			ListAgencyName: agencySUNAT,
+			ListName:       "Tipo de Documento",
+			ListURI:        catalogURI + "01",
+			Name:           "Tipo de Operacion",
+		}
+		if d.DueDate != nil {
+			root.DueDate = d.DueDate.Format("2006-01-02")
+		}
+		root.PaymentTerms = paymentTermsFor(d, amounts.Total)
+	} else {
+		listName, catalog := "Tipo de nota de credito", "09"
+		if d.Type == TypeDebitNote {
+			listName, catalog = "Tipo de nota de debito", "10"
+		}
+		root.Discrepancy = &discrepancyResponse{
+			ReferenceID: d.Reference.ID,
+			ResponseCode: code{
+				Value:          d.Discrepancy.Code,
+				ListAgencyName: agencySUNAT,
+				ListName:       listName,
+				ListURI:        catalogURI + catalog,
+			},
+			Description: d.Discrepancy.Description,
+		}
+		root.BillingReference = &billingReference{
+			InvoiceDocumentReference: documentReference{
+				ID: d.Reference.ID,
+				DocumentTypeCode: code{
+					Value:          d.Reference.DocumentTypeCode,
+					ListAgencyName: agencySUNAT,
+					ListName:       "Tipo de Documento",
+					ListURI:        catalogURI + "01",
+				},
+			},
+		}
+	}
+
+	// Tax subtotals per category, in catalog order
+	taxable := make(map[TaxCategory]decimal.Decimal)
+	taxed := make(map[TaxCategory]decimal.Decimal)
+	for i, line := range d.Lines {
+		taxable[line.TaxCategory] = taxable[line.TaxCategory].Add(amounts.Lines[i].Net)
+		taxed[line.TaxCategory] = taxed[line.TaxCategory].Add(amounts.Lines[i].Tax)
+	}
+	root.TaxTotal = taxTotal{TaxAmount: money(amounts.TaxTotal, d.Currency)}
+	for _, category := range taxCategoryOrder {
+		if _, ok := taxable[category]; !ok {
+			continue
+		}
+		root.TaxTotal.Subtotals = append(root.TaxTotal.Subtotals, taxSubtotal{
+			TaxableAmount: money(taxable[category], d.Currency),
+			TaxAmount:     money(taxed[category], d.Currency),
+			TaxCategory:   taxCategoryFor(category, nil),
+		})
+	}
+
+	total := &monetaryTotal{
+		LineExtensionAmount: money(amounts.Subtotal, d.Currency),
+		TaxInclusiveAmount:  money(amounts.Total, d.Currency),
+		PayableAmount:       money(amounts.Total, d.Currency),
+	}
+	if d.Type == TypeDebitNote {
+		root.RequestedMonetaryTotal = total
+	} else {
+		root.LegalMonetaryTotal = total
+	}
+
+	for i, line := range d.Lines {
+		built := buildLine(d, i, line, amounts.Lines[i])
+		switch d.Type {
+		case TypeInvoice:
+			root.InvoiceLines = append(root.InvoiceLines, built)
+		case TypeCreditNote:
+			root.CreditNoteLines = append(root.CreditNoteLines, built)
+		case TypeDebitNote:
+			root.DebitNoteLines = append(root.DebitNoteLines, built)
+		}
+	}
+
+	return root, nil
+}
+
+// paymentTermsFor returns the SUNAT payment form of an invoice: cash, or
+// credit with a single instalment due on the due date
+func paymentTermsFor(d *Document, total decimal.Decimal) []paymentTerms {
+	if d.DueDate == nil || !d.DueDate.After(d.IssueDate) {
+		return []paymentTerms{{ID: "FormaPago", PaymentMeansID: "Contado"}}
+	}
+
+	amount := money(total, d.Currency)
+	return []paymentTerms{
+		{ID: "FormaPago", PaymentMeansID: "Credito", Amount: &amount},
+		{ID: "FormaPago", PaymentMeansID: "Cuota001", Amount: &amount, PaymentDueDate: d.DueDate.Format("2006-01-02")},
+	}
+}
+
+func buildParty(p Party) party {
+	built := party{
+		PartyIdentification: partyIdentification{ID: identifier{
+			Value:            p.TaxID,
+			SchemeID:         p.IdentityType,
+			SchemeName:       "Documento de Identidad",
+			SchemeAgencyName: agencySUNAT,
+			SchemeURI:        catalogURI + "06",
+		}},
+		LegalEntity: partyLegalEntity{RegistrationName: p.RegistrationName},
+	}
+	if p.TradeName != "" {
+		built.PartyName = &partyName{Name: p.TradeName}
+	}
+
+	if a := p.Address; a != nil {
+		country := a.CountryCode
+		if country == "" {
+			country = "PE"
+		}
+		address := &registrationAddress{
+			CityName:         a.Province,
+			CountrySubentity: a.Department,
+			District:         a.District,
+			Country: countryXML{IdentificationCode: code{
+				Value:          country,
+				ListID:         "ISO 3166-1",
+				ListAgencyName: agencyUNECE,
+				ListName:       "Country",
+			}},
+		}
+		if a.Ubigeo != "" {
+			address.ID = &identifier{Value: a.Ubigeo, SchemeName: "Ubigeos", SchemeAgencyName: "PE:INEI"}
+		}
+		if a.EstablishmentCode != "" {
+			address.AddressTypeCode = &code{
+				Value:          a.EstablishmentCode,
+				ListAgencyName: agencySUNAT,
+				ListName:       "Establecimientos anexos",
+			}
+		}
+		if a.Line != "" {
+			address.AddressLine = &addressLine{Line: a.Line}
+		}
+		built.LegalEntity.RegistrationAddress = address
+	}
+
+	return built
+}
+
+func buildLine(d *Document, i int, line Line, amounts totals.LineAmounts) documentLine {
+	quantity := &quantity{
+		Value:                  line.Quantity.String(),
+		UnitCode:               unitCode(line.UnitCode),
+		UnitCodeListID:         "UN/ECE rec 20",
+		UnitCodeListAgencyName: agencyUNECE,
+	}
+
+	// Unit price with tax, which SUNAT calls the sale price
+	salePrice := line.UnitPrice.Mul(decimal.NewFromInt(100).Add(line.TaxRate)).Div(decimal.NewFromInt(100)).Round(totals.Scale)
+
+	built := documentLine{
+		ID:                  i + 1,
+		LineExtensionAmount: money(amounts.Net, d.Currency),
+		PricingReference: &pricingReference{AlternativeConditionPrice: alternativePrice{
+			PriceAmount: money(salePrice, d.Currency),
+			PriceTypeCode: code{
+				Value:          "01",
+				ListName:       "Tipo de Precio",
+				ListAgencyName: agencySUNAT,
+				ListURI:        catalogURI + "16",
+			},
+		}},
+		TaxTotal: taxTotal{
+			TaxAmount: money(amounts.Tax, d.Currency),
+			Subtotals: []taxSubtotal{{
+				TaxableAmount: money(amounts.Net, d.Currency),
+				TaxAmount:     money(amounts.Tax, d.Currency),
+				TaxCategory:   taxCategoryFor(line.TaxCategory, &line.TaxRate),
+			}},
+		},
+		Item:  item{Description: line.Description},
+		Price: price{PriceAmount: unitAmount(line.UnitPrice, d.Currency)},
+	}
+	if line.ItemCode != "" {
+		built.Item.SellersItemIdentification = &itemIdentification{ID: line.ItemCode}
+	}
+
+	switch d.Type {
+	case TypeInvoice:
+		built.InvoicedQuantity = quantity
+		// Only invoice lines carry allowances in UBL 2.1
+		if amounts.Discount.IsPositive() {
+			built.AllowanceCharge = &allowanceCharge{
+				ChargeIndicator: false,
+				ReasonCode: code{
+					Value:          "00",
+					ListAgencyName: agencySUNAT,
+					ListName:       "Cargo/descuento",
+					ListURI:        catalogURI + "53",
+				},
+				MultiplierFactorNumeric: amounts.Discount.Div(amounts.Gross).StringFixed(5),
+				Amount:                  money(amounts.Discount, d.Currency),
+				BaseAmount:              money(amounts.Gross, d.Currency),
+			}
+		}
+	case TypeCreditNote:
+		built.CreditedQuantity = quantity
+	case TypeDebitNote:
+		built.DebitedQuantity = quantity
+	}
+
+	return built
+}
+
+// taxCategoryFor renders a tax category; lines also carry the rate and
+// the IGV affectation
+func taxCategoryFor(category TaxCategory, rate *decimal.Decimal) taxCategory {
+	scheme := taxSchemes[category]
+	built := taxCategory{TaxScheme: taxSchemeXML{
+		ID: identifier{
+			Value:            scheme.id,
+			SchemeName:       "Codigo de tributos",
+			SchemeAgencyName: agencySUNAT,
+			SchemeURI:        catalogURI + "05",
+		},
+		Name:        scheme.name,
+		TaxTypeCode: scheme.typeCode,
+	}}
+	if rate != nil {
+		built.Percent = rate.String()
+		built.TaxExemptionReasonCode = &code{
+			Value:          scheme.affectation,
+			ListAgencyName: agencySUNAT,
+			ListName:       "Afectacion del IGV",
+			ListURI:        catalogURI + "07",
+		}
+	}
+	return built
+}
+
+// unitCode defaults lines without a unit to NIU (unit of goods)
+func unitCode(code string) string {
+	if code == "" {
+		return "NIU"
+	}
+	return code
+}
+
+func money(value decimal.Decimal, currency string) amount {
+	return amount{Value: value.StringFixed(totals.Scale), CurrencyID: currency}
+}
+
+// unitAmount renders a unit price, which may have more decimals than a
+// monetary amount
+func unitAmount(value decimal.Decimal, currency string) amount {
+	if -value.Exponent() > totals.Scale {
+		return amount{Value: value.String(), CurrencyID: currency}
+	}
+	return money(value, currency)
+}
+
+// XML structure. Element order follows the UBL 2.1 schemas.
+
+type document struct {
+	XMLName  xml.Name
+	Xmlns    string `xml:"xmlns,attr"`
+	XmlnsCAC string `xml:"xmlns:cac,attr"`
+	XmlnsCBC string `xml:"xmlns:cbc,attr"`
+	XmlnsEXT string `xml:"xmlns:ext,attr"`
+	XmlnsDS  string `xml:"xmlns:ds,attr"`
+
+	Extensions           extensions           `xml:"ext:UBLExtensions"`
+	UBLVersionID         string               `xml:"cbc:UBLVersionID"`
+	CustomizationID      identifier           `xml:"cbc:CustomizationID"`
+	ProfileID            *identifier          `xml:"cbc:ProfileID,omitempty"`
+	ID                   string               `xml:"cbc:ID"`
+	IssueDate            string               `xml:"cbc:IssueDate"`
+	IssueTime            string               `xml:"cbc:IssueTime,omitempty"`
+	DueDate              string               `xml:"cbc:DueDate,omitempty"`
+	InvoiceTypeCode      *code                `xml:"cbc:InvoiceTypeCode,omitempty"`
+	Notes                []note               `xml:"cbc:Note"`
+	DocumentCurrencyCode code                 `xml:"cbc:DocumentCurrencyCode"`
+	LineCountNumeric     int                  `xml:"cbc:LineCountNumeric"`
+	Discrepancy          *discrepancyResponse `xml:"cac:DiscrepancyResponse,omitempty"`
+	BillingReference     *billingReference    `xml:"cac:BillingReference,omitempty"`
+	Signature            signature            `xml:"cac:Signature"`
+	Supplier             partyWrapper         `xml:"cac:AccountingSupplierParty"`
+	Customer             partyWrapper         `xml:"cac:AccountingCustomerParty"`
+	PaymentTerms         []paymentTerms       `xml:"cac:PaymentTerms"`
+	TaxTotal             taxTotal             `xml:"cac:TaxTotal"`
+
+	LegalMonetaryTotal     *monetaryTotal `xml:"cac:LegalMonetaryTotal,omitempty"`
+	RequestedMonetaryTotal *monetaryTotal `xml:"cac:RequestedMonetaryTotal,omitempty"`
+
+	InvoiceLines    []documentLine `xml:"cac:InvoiceLine"`
+	CreditNoteLines []documentLine `xml:"cac:CreditNoteLine"`
+	DebitNoteLines  []documentLine `xml:"cac:DebitNoteLine"`
+}
+
+// extensions holds the empty extension the signature goes into
+type extensions struct {
+	Extension struct {
+		Content string `xml:"ext:ExtensionContent"`
+	} `xml:"ext:UBLExtension"`
+}
+
+type identifier struct {
+	Value            string `xml:",chardata"`
+	SchemeID         string `xml:"schemeID,attr,omitempty"`
+	SchemeName       string `xml:"schemeName,attr,omitempty"`
+	SchemeAgencyName string `xml:"schemeAgencyName,attr,omitempty"`
+	SchemeURI        string `xml:"schemeURI,attr,omitempty"`
+}
+
+type code struct {
+	Value          string `xml:",chardata"`
+	ListID         string `xml:"listID,attr,omitempty"`
+	ListAgencyName string `xml:"listAgencyName,attr,omitempty"`
+	ListName       string `xml:"listName,attr,omitempty"`
+	ListURI        string `xml:"listURI,attr,omitempty"`
+	Name           string `xml:"name,attr,omitempty"`
+}
+
+type note struct {
+	Value            string `xml:",chardata"`
+	LanguageLocaleID string `xml:"languageLocaleID,attr,omitempty"`
+}
+
+type amount struct {
+	Value      string `xml:",chardata"`
+	CurrencyID string `xml:"currencyID,attr"`
+}
+
+type quantity struct {
+	Value                  string `xml:",chardata"`
+	UnitCode               string `xml:"unitCode,attr"`
+	UnitCodeListID         string `xml:"unitCodeListID,attr,omitempty"`
+	UnitCodeListAgencyName string `xml:"unitCodeListAgencyName,attr,omitempty"`
+}
+
+type discrepancyResponse struct {
+	ReferenceID  string `xml:"cbc:ReferenceID"`
+	ResponseCode code   `xml:"cbc:ResponseCode"`
+	Description  string `xml:"cbc:Description"`
+}
+
+type billingReference struct {
+	InvoiceDocumentReference documentReference `xml:"cac:InvoiceDocumentReference"`
+}
+
+type documentReference struct {
+	ID               string `xml:"cbc:ID"`
+	DocumentTypeCode code   `xml:"cbc:DocumentTypeCode"`
+}
+
+type signature struct {
+	ID             string              `xml:"cbc:ID"`
+	SignatoryParty signatoryParty      `xml:"cac:SignatoryParty"`
+	Attachment     signatureAttachment `xml:"cac:DigitalSignatureAttachment"`
+}
+
+type signatoryParty struct {
+	PartyIdentification partyIdentification `xml:"cac:PartyIdentification"`
+	PartyName           *partyName          `xml:"cac:PartyName,omitempty"`
+}
+
+type signatureAttachment struct {
+	URI string `xml:"cac:ExternalReference>cbc:URI"`
+}
+
+type partyWrapper struct {
+	Party party `xml:"cac:Party"`
+}
+
+type party struct {
+	PartyIdentification partyIdentification `xml:"cac:PartyIdentification"`
+	PartyName           *partyName          `xml:"cac:PartyName,omitempty"`
+	LegalEntity         partyLegalEntity    `xml:"cac:PartyLegalEntity"`
+}
+
+type partyIdentification struct {
+	ID identifier `xml:"cbc:ID"`
+}
+
+type partyName struct {
+	Name string `xml:"cbc:Name"`
+}
+
+type partyLegalEntity struct {
+	RegistrationName    string               `xml:"cbc:RegistrationName"`
+	RegistrationAddress *registrationAddress `xml:"cac:RegistrationAddress,omitempty"`
+}
+
+type registrationAddress struct {
+	ID               *identifier  `xml:"cbc:ID,omitempty"`
+	AddressTypeCode  *code        `xml:"cbc:AddressTypeCode,omitempty"`
+	CityName         string       `xml:"cbc:CityName,omitempty"`
+	CountrySubentity string       `xml:"cbc:CountrySubentity,omitempty"`
+	District         string       `xml:"cbc:District,omitempty"`
+	AddressLine      *addressLine `xml:"cac:AddressLine,omitempty"`
+	Country          countryXML   `xml:"cac:Country"`
+}
+
+type addressLine struct {
+	Line string `xml:"cbc:Line"`
+}
+
+type countryXML struct {
+	IdentificationCode code `xml:"cbc:IdentificationCode"`
+}
+
+type paymentTerms struct {
+	ID             string  `xml:"cbc:ID"`
+	PaymentMeansID string  `xml:"cbc:PaymentMeansID"`
+	Amount         *amount `xml:"cbc:Amount,omitempty"`
+	PaymentDueDate string  `xml:"cbc:PaymentDueDate,omitempty"`
+}
+
+type taxTotal struct {
+	TaxAmount amount        `xml:"cbc:TaxAmount"`
+	Subtotals []taxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type taxSubtotal struct {
+	TaxableAmount amount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     amount      `xml:"cbc:TaxAmount"`
+	TaxCategory   taxCategory `xml:"cac:TaxCategory"`
+}
+
+type taxCategory struct {
+	Percent                string       `xml:"cbc:Percent,omitempty"`
+	TaxExemptionReasonCode *code        `xml:"cbc:TaxExemptionReasonCode,omitempty"`
+	TaxScheme              taxSchemeXML `xml:"cac:TaxScheme"`
+}
+
+type taxSchemeXML struct {
+	ID          identifier `xml:"cbc:ID"`
+	Name        string     `xml:"cbc:Name"`
+	TaxTypeCode string     `xml:"cbc:TaxTypeCode"`
+}
+
+type monetaryTotal struct {
+	LineExtensionAmount amount `xml:"cbc:LineExtensionAmount"`
+	TaxInclusiveAmount  amount `xml:"cbc:TaxInclusiveAmount"`
+	PayableAmount       amount `xml:"cbc:PayableAmount"`
+}
+
+type documentLine struct {
+	ID                  int               `xml:"cbc:ID"`
+	InvoicedQuantity    *quantity         `xml:"cbc:InvoicedQuantity,omitempty"`
+	CreditedQuantity    *quantity         `xml:"cbc:CreditedQuantity,omitempty"`
+	DebitedQuantity     *quantity         `xml:"cbc:DebitedQuantity,omitempty"`
+	LineExtensionAmount amount            `xml:"cbc:LineExtensionAmount"`
+	PricingReference    *pricingReference `xml:"cac:PricingReference,omitempty"`
+	AllowanceCharge     *allowanceCharge  `xml:"cac:AllowanceCharge,omitempty"`
+	TaxTotal            taxTotal          `xml:"cac:TaxTotal"`
+	Item                item              `xml:"cac:Item"`
+	Price               price             `xml:"cac:Price"`
+}
+
+type pricingReference struct {
+	AlternativeConditionPrice alternativePrice `xml:"cac:AlternativeConditionPrice"`
+}
+
+type alternativePrice struct {
+	PriceAmount   amount `xml:"cbc:PriceAmount"`
+	PriceTypeCode code   `xml:"cbc:PriceTypeCode"`
+}
+
+type allowanceCharge struct {
+	ChargeIndicator         bool   `xml:"cbc:ChargeIndicator"`
+	ReasonCode              code   `xml:"cbc:AllowanceChargeReasonCode"`
+	MultiplierFactorNumeric string `xml:"cbc:MultiplierFactorNumeric"`
+	Amount                  amount `xml:"cbc:Amount"`
+	BaseAmount              amount `xml:"cbc:BaseAmount"`
+}
+
+type item struct {
+	Description               string              `xml:"cbc:Description"`
+	SellersItemIdentification *itemIdentification `xml:"cac:SellersItemIdentification,omitempty"`
+}
+
+type itemIdentification struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type price struct {
+	PriceAmount amount `xml:"cbc:PriceAmount"`
+}
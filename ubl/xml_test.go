@@ -0,0 +1,159 @@
+package ubl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// schemaDir holds the UBL 2.1 maindoc and common schemas, laid out as in the
+// OASIS distribution
+var schemaDir = filepath.Join("testdata", "xsd")
+
+func testDocument(documentType DocumentType) *Document {
+	issued := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+	issueTime := time.Date(2023, 2, 1, 10, 30, 0, 0, time.UTC)
+
+	d := &Document{
+		Type:             documentType,
+		DocumentTypeCode: DocumentFactura,
+		ID:               "F001-123",
+		IssueDate:        issued,
+		IssueTime:        &issueTime,
+		Currency:         "PEN",
+		Supplier: Party{
+			IdentityType:     IdentityRUC,
+			TaxID:            "20123456789",
+			RegistrationName: "ACME S.A.C.",
+			TradeName:        "ACME",
+			Address: &Address{
+				Ubigeo:            "150101",
+				EstablishmentCode: "0000",
+				Line:              "Av. Arequipa 123",
+				District:          "Lima",
+				Province:          "Lima",
+				Department:        "Lima",
+			},
+		},
+		Customer: Party{
+			IdentityType:     IdentityRUC,
+			TaxID:            "20987654321",
+			RegistrationName: "Cliente S.A.",
+		},
+		Lines: []Line{
+			{
+				ItemCode:    "SKU-1",
+				Description: "Servicio de consultoria",
+				Quantity:    decimal.NewFromInt(2),
+				UnitCode:    "ZZ",
+				UnitPrice:   decimal.RequireFromString("50.00"),
+				Discount:    decimal.RequireFromString("10.00"),
+				TaxCategory: TaxIGV,
+				TaxRate:     decimal.NewFromInt(18),
+			},
+			{
+				Description: "Libros",
+				Quantity:    decimal.NewFromInt(1),
+				UnitPrice:   decimal.RequireFromString("30.00"),
+				TaxCategory: TaxExempt,
+			},
+		},
+		Notes: []string{"Orden de compra 456"},
+	}
+
+	switch documentType {
+	case TypeInvoice:
+		due := issued.AddDate(0, 0, 30)
+		d.DueDate = &due
+	case TypeCreditNote:
+		d.DocumentTypeCode = DocumentCreditNote
+		d.ID = "F001-124"
+		d.Reference = &Reference{ID: "F001-123", DocumentTypeCode: DocumentFactura}
+		d.Discrepancy = &Discrepancy{Code: "01", Description: "Anulacion de la operacion"}
+	case TypeDebitNote:
+		d.DocumentTypeCode = DocumentDebitNote
+		d.ID = "F001-125"
+		d.Reference = &Reference{ID: "F001-123", DocumentTypeCode: DocumentFactura}
+		d.Discrepancy = &Discrepancy{Code: "01", Description: "Intereses por mora"}
+	}
+	return d
+}
+
+func TestMarshal(t *testing.T) {
+	// 2 × 50 - 10 = 90 taxed at 18% plus 30 exempt
+	wantTax := decimal.RequireFromString("16.20")
+	wantPayable := decimal.RequireFromString("136.20")
+
+	for _, documentType := range []DocumentType{TypeInvoice, TypeCreditNote, TypeDebitNote} {
+		t.Run(string(documentType), func(t *testing.T) {
+			d := testDocument(documentType)
+			data, err := Marshal(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			received, err := Unmarshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if received.Type != documentType || received.ID != d.ID || received.DocumentTypeCode != d.DocumentTypeCode {
+				t.Errorf("read back %s %s (%s); want %s %s (%s)", received.Type, received.ID, received.DocumentTypeCode, documentType, d.ID, d.DocumentTypeCode)
+			}
+			if len(received.Lines) != len(d.Lines) {
+				t.Errorf("read back %d lines; want %d", len(received.Lines), len(d.Lines))
+			}
+			if !received.TaxAmount.Equal(wantTax) || !received.PayableAmount.Equal(wantPayable) {
+				t.Errorf("totals = %s tax, %s payable; want %s, %s", received.TaxAmount, received.PayableAmount, wantTax, wantPayable)
+			}
+			if documentType != TypeInvoice && (received.Reference == nil || received.Reference.ID != d.Reference.ID) {
+				t.Errorf("reference = %v; want %s", received.Reference, d.Reference.ID)
+			}
+
+			validateSchema(t, documentType, data)
+		})
+	}
+}
+
+func TestMarshalInvalid(t *testing.T) {
+	d := testDocument(TypeCreditNote)
+	d.Discrepancy = nil
+	if _, err := Marshal(d); err == nil {
+		t.Error("marshalled a credit note without a reason")
+	}
+}
+
+// validateSchema validates a document against the UBL 2.1 schema of its type
+// with xmllint. The schemas are part of the repository, so a missing schema
+// or xmllint fails the test instead of leaving the documents unchecked.
+func validateSchema(t *testing.T, documentType DocumentType, data []byte) {
+	t.Helper()
+
+	schema := filepath.Join(schemaDir, "maindoc", "UBL-"+string(documentType)+"-2.1.xsd")
+	if _, err := os.Stat(schema); errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("%s not found; see %s", schema, filepath.Join(schemaDir, "README.md"))
+	}
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Fatal("xmllint not installed; it is needed to validate against the UBL schemas")
+	}
+
+	// The schema wants content in every extension; the signature goes there
+	data = bytes.Replace(data,
+		[]byte("<ext:ExtensionContent></ext:ExtensionContent>"),
+		[]byte(`<ext:ExtensionContent><sig:Signature xmlns:sig="urn:test:signature"></sig:Signature></ext:ExtensionContent>`), 1)
+
+	path := filepath.Join(t.TempDir(), string(documentType)+".xml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(xmllint, "--noout", "--nonet", "--schema", schema, path).CombinedOutput()
+	if err != nil {
+		t.Errorf("%s does not validate against %s:\n%s", documentType, schema, output)
+	}
+}
@@ -0,0 +1,265 @@
+package xmlsig
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// Canonicalization algorithms
+const (
+	AlgorithmC14N                = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	AlgorithmC14NWithComments    = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315#WithComments"
+	AlgorithmExcC14N             = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	AlgorithmExcC14NWithComments = "http://www.w3.org/2001/10/xml-exc-c14n#WithComments"
+)
+
+// canonicalizer renders a subtree in Canonical XML 1.0 or Exclusive XML
+// Canonicalization 1.0
+type canonicalizer struct {
+	exclusive bool
+	comments  bool
+
+	// prefixes is the InclusiveNamespaces PrefixList of exclusive
+	// canonicalization, "#default" standing for the default namespace
+	prefixes map[string]bool
+
+	// exclude is left out with its subtree, as the enveloped signature
+	// transform does
+	exclude *element
+}
+
+// newCanonicalizer returns the canonicalizer of an algorithm
+func newCanonicalizer(algorithm string, prefixList string) (*canonicalizer, bool) {
+	c := &canonicalizer{}
+	switch algorithm {
+	case AlgorithmC14N:
+	case AlgorithmC14NWithComments:
+		c.comments = true
+	case AlgorithmExcC14N:
+		c.exclusive = true
+	case AlgorithmExcC14NWithComments:
+		c.exclusive, c.comments = true, true
+	default:
+		return nil, false
+	}
+
+	if c.exclusive && prefixList != "" {
+		c.prefixes = make(map[string]bool)
+		for _, prefix := range strings.Fields(prefixList) {
+			if prefix == "#default" {
+				prefix = ""
+			}
+			c.prefixes[prefix] = true
+		}
+	}
+	return c, true
+}
+
+// document canonicalizes the whole document, including the comments and
+// processing instructions around the root element
+func (c *canonicalizer) document(doc *document) []byte {
+	var b bytes.Buffer
+	seenRoot := false
+	for _, n := range doc.children {
+		switch v := n.(type) {
+		case *element:
+			c.element(&b, v, nil, true)
+			seenRoot = true
+		case comment, procInst:
+			if _, ok := v.(comment); ok && !c.comments {
+				continue
+			}
+			if seenRoot {
+				b.WriteByte('\n')
+			}
+			writeNode(&b, v)
+			if !seenRoot {
+				b.WriteByte('\n')
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+// subtree canonicalizes an element with its descendants as a document
+// subset: the namespaces and xml attributes it inherits are rendered on it
+func (c *canonicalizer) subtree(e *element) []byte {
+	var b bytes.Buffer
+	c.element(&b, e, nil, true)
+	return b.Bytes()
+}
+
+// element renders e given the namespaces rendered by its output ancestors
+func (c *canonicalizer) element(b *bytes.Buffer, e *element, rendered map[string]string, apex bool) {
+	if e == c.exclude {
+		return
+	}
+
+	scope := e.inScope()
+	var candidates []string
+	if c.exclusive {
+		utilized := map[string]bool{e.prefix: true}
+		for _, a := range e.attrs {
+			if a.prefix != "" && a.prefix != "xml" {
+				utilized[a.prefix] = true
+			}
+		}
+		for prefix := range c.prefixes {
+			if _, ok := scope[prefix]; ok {
+				utilized[prefix] = true
+			}
+		}
+		for prefix := range utilized {
+			candidates = append(candidates, prefix)
+		}
+	} else {
+		for prefix := range scope {
+			candidates = append(candidates, prefix)
+		}
+		if _, ok := scope[""]; !ok {
+			candidates = append(candidates, "")
+		}
+	}
+
+	// Namespace declarations that differ from the rendered ones
+	var namespaces []attribute
+	next := rendered
+	for _, prefix := range candidates {
+		value := scope[prefix]
+		if value == rendered[prefix] || (prefix != "" && value == "") {
+			continue
+		}
+		namespaces = append(namespaces, attribute{prefix: "xmlns", local: prefix, value: value})
+		if len(namespaces) == 1 {
+			next = copyScope(rendered)
+		}
+		next[prefix] = value
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].local < namespaces[j].local })
+
+	// Attributes sorted by namespace URI and local name; the apex of an
+	// inclusive subset inherits the xml attributes of its ancestors
+	type sortable struct {
+		attribute
+		uri string
+	}
+	var attrs []sortable
+	for _, a := range e.attrs {
+		uri, _ := e.lookup(a.prefix)
+		if a.prefix == "" {
+			uri = ""
+		}
+		attrs = append(attrs, sortable{a, uri})
+	}
+	if apex && !c.exclusive {
+		for parent := e.parent; parent != nil; parent = parent.parent {
+			for _, a := range parent.attrs {
+				if a.prefix != "xml" {
+					continue
+				}
+				inherited := false
+				for _, existing := range attrs {
+					if existing.prefix == "xml" && existing.local == a.local {
+						inherited = true
+					}
+				}
+				if !inherited {
+					attrs = append(attrs, sortable{a, namespaceXML})
+				}
+			}
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].uri != attrs[j].uri {
+			return attrs[i].uri < attrs[j].uri
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	name := qualified(e.prefix, e.local)
+	b.WriteByte('<')
+	b.WriteString(name)
+	for _, ns := range namespaces {
+		b.WriteByte(' ')
+		b.WriteString(qualified(ns.prefix, ns.local))
+		b.WriteString(`="`)
+		escapeAttr(b, ns.value)
+		b.WriteByte('"')
+	}
+	for _, a := range attrs {
+		b.WriteByte(' ')
+		b.WriteString(qualified(a.prefix, a.local))
+		b.WriteString(`="`)
+		escapeAttr(b, a.value)
+		b.WriteByte('"')
+	}
+	b.WriteByte('>')
+
+	for _, child := range e.children {
+		switch v := child.(type) {
+		case *element:
+			c.element(b, v, next, false)
+		case text:
+			escapeText(b, string(v))
+		case comment:
+			if c.comments {
+				writeNode(b, v)
+			}
+		case procInst:
+			writeNode(b, v)
+		}
+	}
+
+	b.WriteString("</")
+	b.WriteString(name)
+	b.WriteByte('>')
+}
+
+func copyScope(scope map[string]string) map[string]string {
+	copied := make(map[string]string, len(scope)+1)
+	for prefix, value := range scope {
+		copied[prefix] = value
+	}
+	return copied
+}
+
+// escapeText escapes character data as canonical XML requires
+func escapeText(b *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '\r':
+			b.WriteString("&#xD;")
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// escapeAttr escapes an attribute value as canonical XML requires
+func escapeAttr(b *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '"':
+			b.WriteString("&quot;")
+		case '\t':
+			b.WriteString("&#x9;")
+		case '\n':
+			b.WriteString("&#xA;")
+		case '\r':
+			b.WriteString("&#xD;")
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
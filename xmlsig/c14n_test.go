@@ -0,0 +1,168 @@
+package xmlsig
+
+import "testing"
+
+// The vectors come from the examples of the Canonical XML 1.0 and Exclusive
+// XML Canonicalization 1.0 recommendations
+
+// startEndTags is example 3.3 of Canonical XML 1.0
+const startEndTags = `<doc>
+   <e1   />
+   <e2   ></e2>
+   <e3   name = "elem3"   id="elem3"   />
+   <e4   name="elem4"   id="elem4"   ></e4>
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`
+
+// nestedPrefixes is the example of section 2.2 of Exclusive XML
+// Canonicalization 1.0
+const nestedPrefixes = `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+  <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"/>
+  </n1:elem2>
+</n0:local>`
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		algorithm  string
+		prefixList string
+
+		// apex is the local name of the subtree to canonicalize; empty
+		// canonicalizes the document
+		apex string
+		want string
+	}{
+		{
+			// Empty elements get end tags, attributes are sorted with the
+			// unqualified ones first and then by namespace URI, and
+			// declarations already in scope are dropped
+			name:      "start and end tags",
+			input:     startEndTags,
+			algorithm: AlgorithmC14N,
+			want: `<doc>
+   <e1></e1>
+   <e2></e2>
+   <e3 id="elem3" name="elem3"></e3>
+   <e4 id="elem4" name="elem4"></e4>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org" attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6 xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9 xmlns:a="http://www.ietf.org"></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+		},
+		{
+			name:      "comments",
+			input:     `<?xml version="1.0"?><!-- before --><doc><!-- inside -->text</doc><!-- after -->`,
+			algorithm: AlgorithmC14NWithComments,
+			want:      "<!-- before -->\n<doc><!-- inside -->text</doc>\n<!-- after -->",
+		},
+		{
+			name:      "comments dropped",
+			input:     `<!-- before --><doc><!-- inside -->text</doc>`,
+			algorithm: AlgorithmC14N,
+			want:      `<doc>text</doc>`,
+		},
+		{
+			name:      "character escapes",
+			input:     `<doc attr="&lt;&quot;&#x9;&#xA;&amp;&gt;">&lt;&amp;&gt;"'</doc>`,
+			algorithm: AlgorithmC14N,
+			want:      `<doc attr="&lt;&quot;&#x9;&#xA;&amp;>">&lt;&amp;&gt;"'</doc>`,
+		},
+		{
+			// An inclusive subset inherits the namespaces and xml
+			// attributes in scope
+			name:      "inclusive subtree",
+			input:     nestedPrefixes,
+			algorithm: AlgorithmC14N,
+			apex:      "elem2",
+			want: `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xmlns:n3="ftp://example.org" xml:lang="en">
+    <n3:stuff></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			// An exclusive subset renders only the visibly utilized
+			// namespaces, where they are used
+			name:      "exclusive subtree",
+			input:     nestedPrefixes,
+			algorithm: AlgorithmExcC14N,
+			apex:      "elem2",
+			want: `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			name:       "exclusive subtree with inclusive prefixes",
+			input:      nestedPrefixes,
+			algorithm:  AlgorithmExcC14N,
+			prefixList: "n0 n3",
+			apex:       "elem2",
+			want: `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xmlns:n3="ftp://example.org" xml:lang="en">
+    <n3:stuff></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			name:       "exclusive default namespace",
+			input:      `<root xmlns="urn:d" xmlns:u="urn:unused"><child><u:leaf/></child></root>`,
+			algorithm:  AlgorithmExcC14N,
+			prefixList: "#default",
+			apex:       "child",
+			want:       `<child xmlns="urn:d"><u:leaf xmlns:u="urn:unused"></u:leaf></child>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parse([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, ok := newCanonicalizer(tt.algorithm, tt.prefixList)
+			if !ok {
+				t.Fatalf("unknown algorithm %s", tt.algorithm)
+			}
+
+			var got []byte
+			if tt.apex == "" {
+				got = c.document(doc)
+			} else {
+				apex := doc.root.find(func(e *element) bool { return e.local == tt.apex })
+				if apex == nil {
+					t.Fatalf("no %s element", tt.apex)
+				}
+				got = c.subtree(apex)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeExcludesSignature(t *testing.T) {
+	doc, err := parse([]byte(`<doc><a>1</a><ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:SignedInfo/></ds:Signature></doc>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := newCanonicalizer(AlgorithmC14N, "")
+	c.exclude = doc.root.find(func(e *element) bool { return e.is(NamespaceDS, "Signature") })
+
+	if got := string(c.document(doc)); got != `<doc><a>1</a></doc>` {
+		t.Errorf("got %s; want the document without its signature", got)
+	}
+}
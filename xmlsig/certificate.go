@@ -0,0 +1,143 @@
+package xmlsig
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Certificate is a signing certificate with its private key
+type Certificate struct {
+	Key  crypto.Signer
+	Leaf *x509.Certificate
+
+	// Chain holds the intermediate certificates bundled with the leaf
+	Chain []*x509.Certificate
+}
+
+// LoadPKCS12 decodes a PKCS#12 (.pfx, .p12) bundle holding an RSA or ECDSA
+// private key and its certificate. Both the legacy encryption of older
+// tools and the AES encryption of OpenSSL 3 are supported.
+func LoadPKCS12(data []byte, password string) (*Certificate, error) {
+	key, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported private key %T", ErrInvalidCertificate, key)
+	}
+	switch signer.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		return nil, fmt.Errorf("%w: unsupported private key %T", ErrInvalidCertificate, key)
+	}
+
+	return &Certificate{Key: signer, Leaf: leaf, Chain: chain}, nil
+}
+
+// Fingerprint returns the hex SHA-256 of the leaf certificate
+func (c *Certificate) Fingerprint() string {
+	sum := sha256.Sum256(c.Leaf.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidAt reports whether the leaf certificate is valid at t
+func (c *Certificate) ValidAt(t time.Time) bool {
+	return !t.Before(c.Leaf.NotBefore) && !t.After(c.Leaf.NotAfter)
+}
+
+// Keystore seals PKCS#12 bundles together with their passwords, and other
+// secrets, under a 32-byte master key (AES-256-GCM) so they can be stored at
+// rest. The owner of a bundle, such as an organization ID, is authenticated
+// with it: a sealed bundle only opens for the owner it was sealed for.
+type Keystore struct {
+	aead cipher.AEAD
+}
+
+// NewKeystore creates a keystore with a 32-byte master key
+func NewKeystore(masterKey []byte) (*Keystore, error) {
+	if len(masterKey) != 32 {
+		return nil, fmt.Errorf("xmlsig: keystore master key must be 32 bytes, got %d", len(masterKey))
+	}
+
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Keystore{aead: aead}, nil
+}
+
+// Seal checks that the bundle opens with the password and encrypts both
+func (k *Keystore) Seal(owner string, data []byte, password string) ([]byte, *Certificate, error) {
+	certificate, err := LoadPKCS12(data, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Plaintext: password length, password, bundle
+	plaintext := binary.BigEndian.AppendUint32(nil, uint32(len(password)))
+	plaintext = append(plaintext, password...)
+	plaintext = append(plaintext, data...)
+
+	sealed, err := k.SealSecret(owner, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sealed, certificate, nil
+}
+
+// Open decrypts a sealed bundle and loads its certificate
+func (k *Keystore) Open(owner string, sealed []byte) (*Certificate, error) {
+	plaintext, err := k.OpenSecret(owner, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sealed bundle does not open with this key and owner", ErrInvalidCertificate)
+	}
+	if len(plaintext) < 4 || uint64(len(plaintext)-4) < uint64(binary.BigEndian.Uint32(plaintext)) {
+		return nil, fmt.Errorf("%w: sealed bundle is malformed", ErrInvalidCertificate)
+	}
+
+	passwordLength := int(binary.BigEndian.Uint32(plaintext))
+	password := string(plaintext[4 : 4+passwordLength])
+	return LoadPKCS12(plaintext[4+passwordLength:], password)
+}
+
+// SealSecret encrypts a secret, such as a web service password, for an owner
+func (k *Keystore) SealSecret(owner string, secret []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, secret, []byte(owner)), nil
+}
+
+// OpenSecret decrypts a secret sealed for the owner
+func (k *Keystore) OpenSecret(owner string, sealed []byte) ([]byte, error) {
+	size := k.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("xmlsig: sealed data is truncated")
+	}
+
+	plaintext, err := k.aead.Open(nil, sealed[:size], sealed[size:], []byte(owner))
+	if err != nil {
+		return nil, errors.New("xmlsig: sealed data does not open with this key and owner")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,164 @@
+package xmlsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const testPassword = "s3cret"
+
+// newPKCS12 issues a self-signed certificate for key and bundles both, with
+// the legacy encryption for RSA and the OpenSSL 3 one for ECDSA
+func newPKCS12(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "20123456789 ACME S.A.C.", Country: []string{"PE"}},
+		NotBefore:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2033, 1, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder := pkcs12.Modern
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		encoder = pkcs12.Legacy
+	}
+	data, err := encoder.Encode(key, leaf, nil, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// testKeys returns an RSA and an ECDSA key
+func testKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"rsa": rsaKey, "ecdsa": ecKey}
+}
+
+func TestLoadPKCS12(t *testing.T) {
+	for name, key := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			data := newPKCS12(t, key)
+
+			certificate, err := LoadPKCS12(data, testPassword)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !certificate.Leaf.PublicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.Public()) {
+				t.Error("leaf certificate does not hold the bundled key")
+			}
+			if !certificate.ValidAt(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Error("certificate is not valid inside its period")
+			}
+			if certificate.ValidAt(time.Date(2034, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Error("certificate is valid after it expired")
+			}
+
+			if _, err := LoadPKCS12(data, "wrong"); !errors.Is(err, ErrInvalidCertificate) {
+				t.Errorf("wrong password: got %v; want ErrInvalidCertificate", err)
+			}
+		})
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	masterKey := bytes.Repeat([]byte{7}, 32)
+	keystore, err := NewKeystore(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := newPKCS12(t, testKeys(t)["ecdsa"])
+
+	sealed, sealedCertificate, err := keystore.Seal("org-1", data, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte(testPassword)) {
+		t.Error("sealed bundle holds the password in clear")
+	}
+
+	opened, err := keystore.Open("org-1", sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.Fingerprint() != sealedCertificate.Fingerprint() {
+		t.Error("opened certificate differs from the sealed one")
+	}
+
+	// The owner is authenticated with the bundle
+	if _, err := keystore.Open("org-2", sealed); !errors.Is(err, ErrInvalidCertificate) {
+		t.Errorf("open for another owner: got %v; want ErrInvalidCertificate", err)
+	}
+
+	other, err := NewKeystore(bytes.Repeat([]byte{8}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Open("org-1", sealed); !errors.Is(err, ErrInvalidCertificate) {
+		t.Errorf("open with another master key: got %v; want ErrInvalidCertificate", err)
+	}
+
+	if _, _, err := keystore.Seal("org-1", data, "wrong"); !errors.Is(err, ErrInvalidCertificate) {
+		t.Errorf("seal with a wrong password: got %v; want ErrInvalidCertificate", err)
+	}
+	if _, err := NewKeystore(masterKey[:16]); err == nil {
+		t.Error("accepted a 16-byte master key")
+	}
+}
+
+func TestKeystoreSecret(t *testing.T) {
+	keystore, err := NewKeystore(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := keystore.SealSecret("org-1", []byte("sol-password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := keystore.OpenSecret("org-1", sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret) != "sol-password" {
+		t.Errorf("secret = %q; want sol-password", secret)
+	}
+
+	if _, err := keystore.OpenSecret("org-2", sealed); err == nil {
+		t.Error("secret opened for another owner")
+	}
+	if _, err := keystore.OpenSecret("org-1", sealed[:4]); err == nil {
+		t.Error("truncated secret opened")
+	}
+}
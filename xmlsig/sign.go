@@ -0,0 +1,173 @@
+package xmlsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+)
+
+// Options tunes the signature
+type Options struct {
+	// SignatureID is the Id of the signature element; defaults to
+	// ubl.SignatureID, which the document's cac:Signature refers to
+	SignatureID string
+
+	// XAdES adds XAdES-BES signed properties: the signing time and the
+	// digest of the signing certificate
+	XAdES bool
+
+	// SigningTime is recorded in the XAdES properties; defaults to now
+	SigningTime time.Time
+}
+
+// Sign embeds an enveloped XML Signature in the first empty
+// ext:ExtensionContent of a UBL document. The document is canonicalized
+// with Canonical XML 1.0 and signed with SHA-256.
+func Sign(data []byte, certificate *Certificate, options Options) ([]byte, error) {
+	if certificate == nil || certificate.Key == nil || certificate.Leaf == nil {
+		return nil, fmt.Errorf("%w: a certificate with its private key is required", ErrInvalidCertificate)
+	}
+	if options.SignatureID == "" {
+		options.SignatureID = ubl.SignatureID
+	}
+	if options.SigningTime.IsZero() {
+		options.SigningTime = time.Now()
+	}
+
+	var method string
+	switch certificate.Key.(type) {
+	case *ecdsa.PrivateKey:
+		method = AlgorithmECDSASHA256
+	default:
+		method = AlgorithmRSASHA256
+	}
+
+	doc, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	slot := doc.root.find(func(e *element) bool {
+		if !e.is(ubl.NamespaceEXT, "ExtensionContent") {
+			return false
+		}
+		for _, c := range e.children {
+			if _, ok := c.(*element); ok {
+				return false
+			}
+		}
+		return true
+	})
+	if slot == nil {
+		return nil, ErrNoSignatureSlot
+	}
+
+	// Build the signature with empty digests, place it, then fill the
+	// digests and the signature value
+	signature := newElement("ds", "Signature", "Id", options.SignatureID)
+	signature.namespaces = []attribute{{prefix: "xmlns", local: "ds", value: NamespaceDS}}
+
+	signedInfo := signature.appendChild(newElement("ds", "SignedInfo"))
+	signedInfo.appendChild(newElement("ds", "CanonicalizationMethod", "Algorithm", AlgorithmC14N))
+	signedInfo.appendChild(newElement("ds", "SignatureMethod", "Algorithm", method))
+
+	reference := signedInfo.appendChild(newElement("ds", "Reference", "URI", ""))
+	transforms := reference.appendChild(newElement("ds", "Transforms"))
+	transforms.appendChild(newElement("ds", "Transform", "Algorithm", TransformEnveloped))
+	reference.appendChild(newElement("ds", "DigestMethod", "Algorithm", AlgorithmSHA256))
+	reference.appendChild(newTextElement("ds", "DigestValue", ""))
+
+	signatureValue := signature.appendChild(newTextElement("ds", "SignatureValue", ""))
+
+	keyInfo := signature.appendChild(newElement("ds", "KeyInfo"))
+	x509Data := keyInfo.appendChild(newElement("ds", "X509Data"))
+	x509Data.appendChild(newTextElement("ds", "X509Certificate", base64.StdEncoding.EncodeToString(certificate.Leaf.Raw)))
+
+	if options.XAdES {
+		propertiesID := options.SignatureID + "-SignedProperties"
+		properties := signedInfo.appendChild(newElement("ds", "Reference", "Type", typeSignedProperties, "URI", "#"+propertiesID))
+		properties.appendChild(newElement("ds", "DigestMethod", "Algorithm", AlgorithmSHA256))
+		properties.appendChild(newTextElement("ds", "DigestValue", ""))
+
+		object := signature.appendChild(newElement("ds", "Object"))
+		object.appendChild(qualifyingProperties(certificate, options, propertiesID))
+	}
+
+	slot.appendChild(signature)
+
+	for _, ref := range signedInfo.childElements(NamespaceDS, "Reference") {
+		digest, err := digestReference(doc, signature, ref, crypto.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		ref.child(NamespaceDS, "DigestValue").setText(base64.StdEncoding.EncodeToString(digest))
+	}
+
+	canonical, err := canonicalizeSignedInfo(signedInfo)
+	if err != nil {
+		return nil, err
+	}
+	value, err := signDigest(certificate.Key, canonical)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+	signatureValue.setText(base64.StdEncoding.EncodeToString(value))
+
+	return doc.serialize(), nil
+}
+
+// qualifyingProperties builds the XAdES-BES signed properties
+func qualifyingProperties(certificate *Certificate, options Options, id string) *element {
+	certDigest := sha256.Sum256(certificate.Leaf.Raw)
+
+	qualifying := newElement("xades", "QualifyingProperties", "Target", "#"+options.SignatureID)
+	qualifying.namespaces = []attribute{{prefix: "xmlns", local: "xades", value: NamespaceXAdES}}
+
+	signed := qualifying.appendChild(newElement("xades", "SignedProperties", "Id", id))
+	signatureProperties := signed.appendChild(newElement("xades", "SignedSignatureProperties"))
+	signatureProperties.appendChild(newTextElement("xades", "SigningTime", options.SigningTime.Format(time.RFC3339)))
+
+	cert := signatureProperties.
+		appendChild(newElement("xades", "SigningCertificate")).
+		appendChild(newElement("xades", "Cert"))
+	digest := cert.appendChild(newElement("xades", "CertDigest"))
+	digest.appendChild(newElement("ds", "DigestMethod", "Algorithm", AlgorithmSHA256))
+	digest.appendChild(newTextElement("ds", "DigestValue", base64.StdEncoding.EncodeToString(certDigest[:])))
+	issuerSerial := cert.appendChild(newElement("xades", "IssuerSerial"))
+	issuerSerial.appendChild(newTextElement("ds", "X509IssuerName", certificate.Leaf.Issuer.String()))
+	issuerSerial.appendChild(newTextElement("ds", "X509SerialNumber", certificate.Leaf.SerialNumber.String()))
+
+	return qualifying
+}
+
+// signDigest signs the SHA-256 of data. ECDSA signatures are converted
+// from ASN.1 to the r||s form XML Signature uses.
+func signDigest(key crypto.Signer, data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return signature, nil
+	}
+	var parsed struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+		return nil, err
+	}
+	size := (ecKey.Curve.Params().BitSize + 7) / 8
+	raw := make([]byte, 2*size)
+	parsed.R.FillBytes(raw[:size])
+	parsed.S.FillBytes(raw[size:])
+	return raw, nil
+}
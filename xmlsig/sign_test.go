@@ -0,0 +1,107 @@
+package xmlsig
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+)
+
+// unsignedInvoice reads a UBL invoice with an empty signature slot
+func unsignedInvoice(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/invoice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// sign signs the test invoice with a new self-signed certificate
+func sign(t *testing.T, key string, options Options) ([]byte, *Certificate) {
+	t.Helper()
+
+	certificate, err := LoadPKCS12(newPKCS12(t, testKeys(t)[key]), testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := Sign(unsignedInvoice(t), certificate, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed, certificate
+}
+
+func TestSignVerify(t *testing.T) {
+	tests := []struct {
+		key           string
+		xades         bool
+		wantAlgorithm string
+	}{
+		{"rsa", false, AlgorithmRSASHA256},
+		{"rsa", true, AlgorithmRSASHA256},
+		{"ecdsa", false, AlgorithmECDSASHA256},
+		{"ecdsa", true, AlgorithmECDSASHA256},
+	}
+
+	for _, tt := range tests {
+		name := tt.key
+		if tt.xades {
+			name += "/xades"
+		}
+		t.Run(name, func(t *testing.T) {
+			signed, certificate := sign(t, tt.key, Options{
+				XAdES:       tt.xades,
+				SigningTime: time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC),
+			})
+
+			verified, err := Verify(signed, VerifyOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verified.SignatureID != ubl.SignatureID {
+				t.Errorf("signature ID = %q; want %q", verified.SignatureID, ubl.SignatureID)
+			}
+			if verified.Algorithm != tt.wantAlgorithm {
+				t.Errorf("algorithm = %s; want %s", verified.Algorithm, tt.wantAlgorithm)
+			}
+			if len(verified.WeakAlgorithms) != 0 {
+				t.Errorf("weak algorithms = %v; want none", verified.WeakAlgorithms)
+			}
+			if !verified.Certificate.Equal(certificate.Leaf) {
+				t.Error("verified certificate is not the signer's")
+			}
+
+			doc, err := parse(signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			references := doc.root.find(func(e *element) bool { return e.is(NamespaceDS, "SignedInfo") }).
+				childElements(NamespaceDS, "Reference")
+			wantReferences := 1
+			if tt.xades {
+				wantReferences = 2
+			}
+			if len(references) != wantReferences {
+				t.Errorf("got %d references; want %d", len(references), wantReferences)
+			}
+		})
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	signed, certificate := sign(t, "ecdsa", Options{})
+
+	// The only slot is taken by the first signature
+	if _, err := Sign(signed, certificate, Options{}); !errors.Is(err, ErrNoSignatureSlot) {
+		t.Errorf("signing twice: got %v; want ErrNoSignatureSlot", err)
+	}
+	if _, err := Sign(unsignedInvoice(t), &Certificate{}, Options{}); !errors.Is(err, ErrInvalidCertificate) {
+		t.Errorf("signing without a key: got %v; want ErrInvalidCertificate", err)
+	}
+	if _, err := Sign([]byte("<Invoice>"), certificate, Options{}); !errors.Is(err, ErrMalformedXML) {
+		t.Errorf("signing malformed XML: got %v; want ErrMalformedXML", err)
+	}
+}
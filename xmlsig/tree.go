@@ -0,0 +1,367 @@
+package xmlsig
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// namespaceXML is bound to the xml prefix without being declared
+const namespaceXML = "http://www.w3.org/XML/1998/namespace"
+
+// The XML is kept as a tree that remembers prefixes and namespace
+// declarations as written, which canonicalization needs and encoding/xml
+// does not preserve.
+
+type node interface{}
+
+// element is an XML element. Namespace declarations are kept apart from
+// the other attributes; a declaration of the default namespace has an
+// empty local name.
+type element struct {
+	prefix     string
+	local      string
+	namespaces []attribute
+	attrs      []attribute
+	children   []node
+	parent     *element
+}
+
+type attribute struct {
+	prefix string
+	local  string
+	value  string
+}
+
+type text string
+
+type comment string
+
+type procInst struct {
+	target string
+	inst   string
+}
+
+// document is a parsed XML document: the root element with the comments
+// and processing instructions around it
+type document struct {
+	root     *element
+	children []node
+}
+
+// parse reads an XML document. Document type declarations are rejected.
+func parse(data []byte) (*document, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	doc := &document{}
+
+	var current *element
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedXML, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			e := &element{prefix: t.Name.Space, local: t.Name.Local, parent: current}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					e.namespaces = append(e.namespaces, attribute{prefix: "xmlns", value: a.Value})
+				case a.Name.Space == "xmlns":
+					e.namespaces = append(e.namespaces, attribute{prefix: "xmlns", local: a.Name.Local, value: a.Value})
+				default:
+					e.attrs = append(e.attrs, attribute{prefix: a.Name.Space, local: a.Name.Local, value: a.Value})
+				}
+			}
+			if current == nil {
+				if doc.root != nil {
+					return nil, fmt.Errorf("%w: more than one root element", ErrMalformedXML)
+				}
+				doc.root = e
+				doc.children = append(doc.children, e)
+			} else {
+				current.children = append(current.children, e)
+			}
+			current = e
+
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.local != t.Name.Local {
+				return nil, fmt.Errorf("%w: unexpected end element %s", ErrMalformedXML, t.Name.Local)
+			}
+			current = current.parent
+
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, text(t))
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("%w: text outside the root element", ErrMalformedXML)
+			}
+
+		case xml.Comment:
+			if current != nil {
+				current.children = append(current.children, comment(t))
+			} else {
+				doc.children = append(doc.children, comment(t))
+			}
+
+		case xml.ProcInst:
+			if t.Target == "xml" {
+				continue
+			}
+			pi := procInst{target: t.Target, inst: string(t.Inst)}
+			if current != nil {
+				current.children = append(current.children, pi)
+			} else {
+				doc.children = append(doc.children, pi)
+			}
+
+		case xml.Directive:
+			return nil, fmt.Errorf("%w: document type declarations are not supported", ErrMalformedXML)
+		}
+	}
+
+	if doc.root == nil || current != nil {
+		return nil, fmt.Errorf("%w: incomplete document", ErrMalformedXML)
+	}
+
+	// Every prefix used must be declared
+	var check func(e *element) error
+	check = func(e *element) error {
+		if _, ok := e.lookup(e.prefix); !ok && e.prefix != "" {
+			return fmt.Errorf("%w: undeclared prefix %s", ErrMalformedXML, e.prefix)
+		}
+		for _, a := range e.attrs {
+			if _, ok := e.lookup(a.prefix); !ok && a.prefix != "" {
+				return fmt.Errorf("%w: undeclared prefix %s", ErrMalformedXML, a.prefix)
+			}
+		}
+		for _, child := range e.children {
+			if c, ok := child.(*element); ok {
+				if err := check(c); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := check(doc.root); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// lookup resolves a prefix, "" being the default namespace
+func (e *element) lookup(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return namespaceXML, true
+	}
+	for el := e; el != nil; el = el.parent {
+		for _, ns := range el.namespaces {
+			if ns.local == prefix {
+				return ns.value, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+// namespace returns the namespace URI of the element
+func (e *element) namespace() string {
+	uri, _ := e.lookup(e.prefix)
+	return uri
+}
+
+// is reports whether the element has the given namespace and local name
+func (e *element) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// inScope returns the namespaces in scope of the element by prefix,
+// without the xml prefix. An undeclared default namespace is absent.
+func (e *element) inScope() map[string]string {
+	scope := make(map[string]string)
+	var chain []*element
+	for el := e; el != nil; el = el.parent {
+		chain = append(chain, el)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, ns := range chain[i].namespaces {
+			scope[ns.local] = ns.value
+		}
+	}
+	if scope[""] == "" {
+		delete(scope, "")
+	}
+	return scope
+}
+
+// attr returns the value of an attribute without prefix
+func (e *element) attr(local string) (string, bool) {
+	for _, a := range e.attrs {
+		if a.prefix == "" && a.local == local {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// child returns the first child element with the given namespace and local
+// name
+func (e *element) child(namespace, local string) *element {
+	for _, c := range e.children {
+		if el, ok := c.(*element); ok && el.is(namespace, local) {
+			return el
+		}
+	}
+	return nil
+}
+
+// childElements returns the child elements with the given namespace and
+// local name
+func (e *element) childElements(namespace, local string) []*element {
+	var list []*element
+	for _, c := range e.children {
+		if el, ok := c.(*element); ok && el.is(namespace, local) {
+			list = append(list, el)
+		}
+	}
+	return list
+}
+
+// text returns the concatenated text content of the element
+func (e *element) text() string {
+	var b strings.Builder
+	var walk func(el *element)
+	walk = func(el *element) {
+		for _, c := range el.children {
+			switch v := c.(type) {
+			case text:
+				b.WriteString(string(v))
+			case *element:
+				walk(v)
+			}
+		}
+	}
+	walk(e)
+	return b.String()
+}
+
+// find returns the first element of the subtree, in document order, for
+// which match is true
+func (e *element) find(match func(*element) bool) *element {
+	if match(e) {
+		return e
+	}
+	for _, c := range e.children {
+		if el, ok := c.(*element); ok {
+			if found := el.find(match); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// appendChild adds a child element
+func (e *element) appendChild(child *element) *element {
+	child.parent = e
+	e.children = append(e.children, child)
+	return child
+}
+
+// newElement creates an element with unprefixed attributes given as name
+// and value pairs
+func newElement(prefix, local string, attrs ...string) *element {
+	e := &element{prefix: prefix, local: local}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.attrs = append(e.attrs, attribute{local: attrs[i], value: attrs[i+1]})
+	}
+	return e
+}
+
+// newTextElement creates an element holding text
+func newTextElement(prefix, local, value string, attrs ...string) *element {
+	e := newElement(prefix, local, attrs...)
+	e.children = []node{text(value)}
+	return e
+}
+
+// setText replaces the content of the element with text
+func (e *element) setText(value string) {
+	e.children = []node{text(value)}
+}
+
+// serialize writes the document back as XML with an XML declaration
+func (doc *document) serialize() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	for i, c := range doc.children {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		writeNode(&b, c)
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func writeNode(b *bytes.Buffer, n node) {
+	switch v := n.(type) {
+	case *element:
+		b.WriteByte('<')
+		b.WriteString(qualified(v.prefix, v.local))
+		for _, ns := range v.namespaces {
+			b.WriteByte(' ')
+			b.WriteString(qualified(ns.prefix, ns.local))
+			b.WriteString(`="`)
+			escapeAttr(b, ns.value)
+			b.WriteByte('"')
+		}
+		for _, a := range v.attrs {
+			b.WriteByte(' ')
+			b.WriteString(qualified(a.prefix, a.local))
+			b.WriteString(`="`)
+			escapeAttr(b, a.value)
+			b.WriteByte('"')
+		}
+		b.WriteByte('>')
+		for _, c := range v.children {
+			writeNode(b, c)
+		}
+		b.WriteString("</")
+		b.WriteString(qualified(v.prefix, v.local))
+		b.WriteByte('>')
+	case text:
+		escapeText(b, string(v))
+	case comment:
+		b.WriteString("<!--")
+		b.WriteString(string(v))
+		b.WriteString("-->")
+	case procInst:
+		b.WriteString("<?")
+		b.WriteString(v.target)
+		if v.inst != "" {
+			b.WriteByte(' ')
+			b.WriteString(v.inst)
+		}
+		b.WriteString("?>")
+	}
+}
+
+func qualified(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	if local == "" {
+		return prefix
+	}
+	return prefix + ":" + local
+}
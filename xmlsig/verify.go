@@ -0,0 +1,325 @@
+package xmlsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// Verified describes a valid signature
+type Verified struct {
+	// SignatureID is the Id of the signature element, if any
+	SignatureID string
+
+	// Certificate is the signer's certificate taken from the KeyInfo. It
+	// is not checked against trusted roots nor its validity period.
+	Certificate *x509.Certificate
+
+	// Intermediates are the other certificates of the KeyInfo, which may
+	// chain the signer's certificate to a trusted root
+	Intermediates []*x509.Certificate
+
+	// Algorithm is the signature algorithm
+	Algorithm string
+
+	// WeakAlgorithms lists the SHA-1 signature and digest methods the
+	// signature was accepted with, when VerifyOptions allow them
+	WeakAlgorithms []string
+}
+
+// VerifyOptions tunes the verification
+type VerifyOptions struct {
+	// AllowSHA1 accepts RSA-SHA1 signatures and SHA-1 digests, which
+	// older signing software still produces, and reports them in
+	// Verified.WeakAlgorithms instead of failing with ErrWeakAlgorithm
+	AllowSHA1 bool
+}
+
+// Verify checks the first XML Signature of a document. The signature must
+// cover the whole document through an enveloped signature reference, every
+// reference digest must match and the signature value must verify with the
+// public key of a certificate in the KeyInfo. SHA-1 signature and digest
+// methods are rejected with ErrWeakAlgorithm unless the options allow them.
+func Verify(data []byte, options VerifyOptions) (*Verified, error) {
+	doc, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := doc.root.find(func(e *element) bool { return e.is(NamespaceDS, "Signature") })
+	if signature == nil {
+		return nil, ErrNoSignature
+	}
+	signedInfo := signature.child(NamespaceDS, "SignedInfo")
+	if signedInfo == nil {
+		return nil, fmt.Errorf("%w: missing SignedInfo", ErrInvalidSignature)
+	}
+
+	// References
+	references := signedInfo.childElements(NamespaceDS, "Reference")
+	if len(references) == 0 {
+		return nil, fmt.Errorf("%w: no references", ErrInvalidSignature)
+	}
+	var weak []string
+	coversDocument := false
+	for _, reference := range references {
+		uri, _ := reference.attr("URI")
+		if uri == "" {
+			coversDocument = true
+		}
+
+		digestMethod := reference.child(NamespaceDS, "DigestMethod")
+		digestValue := reference.child(NamespaceDS, "DigestValue")
+		if digestMethod == nil || digestValue == nil {
+			return nil, fmt.Errorf("%w: reference %q lacks its digest", ErrInvalidSignature, uri)
+		}
+		algorithm, _ := digestMethod.attr("Algorithm")
+		expected, err := decodeBase64(digestValue.text())
+		if err != nil {
+			return nil, fmt.Errorf("%w: reference %q: %v", ErrInvalidSignature, uri, err)
+		}
+
+		hash, err := digestHash(algorithm, options)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := weakAlgorithms[algorithm]; ok && !slices.Contains(weak, algorithm) {
+			weak = append(weak, algorithm)
+		}
+		actual, err := digestReference(doc, signature, reference, hash)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(expected, actual) {
+			return nil, fmt.Errorf("%w: reference %q", ErrDigestMismatch, uri)
+		}
+	}
+	if !coversDocument {
+		return nil, fmt.Errorf("%w: signature does not cover the whole document", ErrInvalidSignature)
+	}
+
+	// Signature value
+	method := signedInfo.child(NamespaceDS, "SignatureMethod")
+	if method == nil {
+		return nil, fmt.Errorf("%w: missing SignatureMethod", ErrInvalidSignature)
+	}
+	algorithm, _ := method.attr("Algorithm")
+	hash, ok := signatureMethods[algorithm]
+	if weakHash, weakMethod := weakAlgorithms[algorithm]; weakMethod {
+		if !options.AllowSHA1 {
+			return nil, fmt.Errorf("%w: signature method %s", ErrWeakAlgorithm, algorithm)
+		}
+		hash, ok = weakHash, true
+		weak = append(weak, algorithm)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: signature method %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	canonical, err := canonicalizeSignedInfo(signedInfo)
+	if err != nil {
+		return nil, err
+	}
+	signatureValue := signature.child(NamespaceDS, "SignatureValue")
+	if signatureValue == nil {
+		return nil, fmt.Errorf("%w: missing SignatureValue", ErrInvalidSignature)
+	}
+	value, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return nil, fmt.Errorf("%w: SignatureValue: %v", ErrInvalidSignature, err)
+	}
+
+	certificates, err := keyInfoCertificates(signature)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(canonical)
+	digest := h.Sum(nil)
+	for i, certificate := range certificates {
+		if verifyDigest(certificate.PublicKey, hash, digest, value) {
+			id, _ := signature.attr("Id")
+			intermediates := slices.Delete(slices.Clone(certificates), i, i+1)
+			return &Verified{SignatureID: id, Certificate: certificate, Intermediates: intermediates, Algorithm: algorithm, WeakAlgorithms: weak}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: signature value does not verify with the KeyInfo certificate", ErrInvalidSignature)
+}
+
+// digestHash returns the hash of a digest algorithm
+func digestHash(algorithm string, options VerifyOptions) (crypto.Hash, error) {
+	if hash, ok := weakAlgorithms[algorithm]; ok {
+		if !options.AllowSHA1 {
+			return 0, fmt.Errorf("%w: digest method %s", ErrWeakAlgorithm, algorithm)
+		}
+		return hash, nil
+	}
+	hash, ok := digestMethods[algorithm]
+	if !ok {
+		return 0, fmt.Errorf("%w: digest method %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	return hash, nil
+}
+
+// digestReference dereferences a same-document reference, applies its
+// transforms and digests the canonical octets with the hash. Comments are
+// always excluded, as same-document references require.
+func digestReference(doc *document, signature, reference *element, hash crypto.Hash) ([]byte, error) {
+	uri, _ := reference.attr("URI")
+	var apex *element
+	switch {
+	case uri == "":
+	case strings.HasPrefix(uri, "#"):
+		var err error
+		if apex, err = elementByID(doc, uri[1:]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: reference URI %q", ErrUnsupportedAlgorithm, uri)
+	}
+
+	c, _ := newCanonicalizer(AlgorithmC14N, "")
+	enveloped := false
+	if transforms := reference.child(NamespaceDS, "Transforms"); transforms != nil {
+		for _, transform := range transforms.childElements(NamespaceDS, "Transform") {
+			algorithm, _ := transform.attr("Algorithm")
+			if algorithm == TransformEnveloped {
+				enveloped = true
+				continue
+			}
+			next, ok := newCanonicalizer(algorithm, inclusivePrefixes(transform))
+			if !ok {
+				return nil, fmt.Errorf("%w: transform %s", ErrUnsupportedAlgorithm, algorithm)
+			}
+			c = next
+		}
+	}
+	c.comments = false
+	if enveloped {
+		c.exclude = signature
+	}
+
+	var octets []byte
+	if apex == nil {
+		octets = c.document(doc)
+	} else {
+		octets = c.subtree(apex)
+	}
+
+	h := hash.New()
+	h.Write(octets)
+	return h.Sum(nil), nil
+}
+
+// canonicalizeSignedInfo renders SignedInfo with its canonicalization method
+func canonicalizeSignedInfo(signedInfo *element) ([]byte, error) {
+	method := signedInfo.child(NamespaceDS, "CanonicalizationMethod")
+	if method == nil {
+		return nil, fmt.Errorf("%w: missing CanonicalizationMethod", ErrInvalidSignature)
+	}
+	algorithm, _ := method.attr("Algorithm")
+	c, ok := newCanonicalizer(algorithm, inclusivePrefixes(method))
+	if !ok {
+		return nil, fmt.Errorf("%w: canonicalization method %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	return c.subtree(signedInfo), nil
+}
+
+// inclusivePrefixes returns the PrefixList of an exclusive canonicalization
+// transform
+func inclusivePrefixes(transform *element) string {
+	if list := transform.child(namespaceExcC14N, "InclusiveNamespaces"); list != nil {
+		prefixes, _ := list.attr("PrefixList")
+		return prefixes
+	}
+	return ""
+}
+
+// elementByID finds the single element with an Id, ID or id attribute.
+// Duplicated IDs are rejected so a reference cannot be redirected.
+func elementByID(doc *document, id string) (*element, error) {
+	var found []*element
+	var walk func(e *element)
+	walk = func(e *element) {
+		for _, name := range []string{"Id", "ID", "id"} {
+			if value, ok := e.attr(name); ok && value == id {
+				found = append(found, e)
+				break
+			}
+		}
+		for _, c := range e.children {
+			if child, ok := c.(*element); ok {
+				walk(child)
+			}
+		}
+	}
+	walk(doc.root)
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: no element with Id %q", ErrInvalidSignature, id)
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf("%w: more than one element with Id %q", ErrInvalidSignature, id)
+}
+
+// keyInfoCertificates returns the X.509 certificates of the KeyInfo
+func keyInfoCertificates(signature *element) ([]*x509.Certificate, error) {
+	keyInfo := signature.child(NamespaceDS, "KeyInfo")
+	if keyInfo == nil {
+		return nil, fmt.Errorf("%w: KeyInfo has no certificate", ErrInvalidSignature)
+	}
+
+	var certificates []*x509.Certificate
+	for _, data := range keyInfo.childElements(NamespaceDS, "X509Data") {
+		for _, encoded := range data.childElements(NamespaceDS, "X509Certificate") {
+			der, err := decodeBase64(encoded.text())
+			if err != nil {
+				return nil, fmt.Errorf("%w: X509Certificate: %v", ErrInvalidCertificate, err)
+			}
+			certificate, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+			}
+			certificates = append(certificates, certificate)
+		}
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("%w: KeyInfo has no certificate", ErrInvalidSignature)
+	}
+	return certificates, nil
+}
+
+// verifyDigest checks an RSA PKCS #1 v1.5 or raw r||s ECDSA signature
+func verifyDigest(publicKey crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		if len(signature)%2 != 0 {
+			return false
+		}
+		half := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:half])
+		s := new(big.Int).SetBytes(signature[half:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
+
+// decodeBase64 decodes base64 text that may be wrapped over several lines
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
@@ -0,0 +1,162 @@
+package xmlsig
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Abraxas-365/fuckturamelo/ubl"
+)
+
+// tamper parses a signed document, changes it and serializes it again
+func tamper(t *testing.T, signed []byte, change func(doc *document)) []byte {
+	t.Helper()
+	doc, err := parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change(doc)
+	return doc.serialize()
+}
+
+// findElement returns the first element with the namespace and local name
+func findElement(doc *document, namespace, local string) *element {
+	return doc.root.find(func(e *element) bool { return e.is(namespace, local) })
+}
+
+// setAttr replaces the value of an attribute without prefix
+func setAttr(e *element, local, value string) {
+	for i := range e.attrs {
+		if e.attrs[i].prefix == "" && e.attrs[i].local == local {
+			e.attrs[i].value = value
+		}
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	signed, _ := sign(t, "rsa", Options{XAdES: true, SigningTime: time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC)})
+	otherSigned, _ := sign(t, "ecdsa", Options{})
+
+	tests := []struct {
+		name   string
+		change func(doc *document)
+		want   error
+	}{
+		{
+			name: "amount changed",
+			change: func(doc *document) {
+				findElement(doc, ubl.NamespaceCBC, "PayableAmount").setText("1.00")
+			},
+			want: ErrDigestMismatch,
+		},
+		{
+			name: "attribute changed",
+			change: func(doc *document) {
+				setAttr(findElement(doc, ubl.NamespaceCBC, "Note"), "languageLocaleID", "2000")
+			},
+			want: ErrDigestMismatch,
+		},
+		{
+			name: "signing time changed",
+			change: func(doc *document) {
+				findElement(doc, NamespaceXAdES, "SigningTime").setText("2023-03-01T10:00:00Z")
+			},
+			want: ErrDigestMismatch,
+		},
+		{
+			name: "signature value changed",
+			change: func(doc *document) {
+				value := findElement(doc, NamespaceDS, "SignatureValue")
+				raw, _ := base64.StdEncoding.DecodeString(value.text())
+				raw[0] ^= 0xff
+				value.setText(base64.StdEncoding.EncodeToString(raw))
+			},
+			want: ErrInvalidSignature,
+		},
+		{
+			// The digests still match but SignedInfo is not what was signed
+			name: "signed info changed",
+			change: func(doc *document) {
+				setAttr(findElement(doc, NamespaceDS, "CanonicalizationMethod"), "Algorithm", AlgorithmExcC14N)
+			},
+			want: ErrInvalidSignature,
+		},
+		{
+			name: "certificate replaced",
+			change: func(doc *document) {
+				other, err := parse(otherSigned)
+				if err != nil {
+					t.Fatal(err)
+				}
+				findElement(doc, NamespaceDS, "X509Certificate").setText(findElement(other, NamespaceDS, "X509Certificate").text())
+			},
+			want: ErrInvalidSignature,
+		},
+		{
+			name: "weak signature method",
+			change: func(doc *document) {
+				setAttr(findElement(doc, NamespaceDS, "SignatureMethod"), "Algorithm", AlgorithmRSASHA1)
+			},
+			want: ErrWeakAlgorithm,
+		},
+		{
+			name: "weak digest method",
+			change: func(doc *document) {
+				setAttr(findElement(doc, NamespaceDS, "DigestMethod"), "Algorithm", AlgorithmSHA1)
+			},
+			want: ErrWeakAlgorithm,
+		},
+	}
+
+	if _, err := Verify(signed, VerifyOptions{}); err != nil {
+		t.Fatalf("untouched document: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(tamper(t, signed, tt.change), VerifyOptions{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v; want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyUnsigned(t *testing.T) {
+	if _, err := Verify(unsignedInvoice(t), VerifyOptions{}); !errors.Is(err, ErrNoSignature) {
+		t.Errorf("got %v; want ErrNoSignature", err)
+	}
+}
+
+func TestVerifySHA1(t *testing.T) {
+	// invoice-sha1.xml is signed with RSA-SHA1 over a SHA-1 digest, as
+	// older signing software does
+	signed, err := os.ReadFile("testdata/invoice-sha1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Verify(signed, VerifyOptions{}); !errors.Is(err, ErrWeakAlgorithm) {
+		t.Errorf("got %v; want ErrWeakAlgorithm", err)
+	}
+
+	verified, err := Verify(signed, VerifyOptions{AllowSHA1: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.Algorithm != AlgorithmRSASHA1 {
+		t.Errorf("algorithm = %s; want %s", verified.Algorithm, AlgorithmRSASHA1)
+	}
+	if want := []string{AlgorithmSHA1, AlgorithmRSASHA1}; !slices.Equal(verified.WeakAlgorithms, want) {
+		t.Errorf("weak algorithms = %v; want %v", verified.WeakAlgorithms, want)
+	}
+
+	tampered := tamper(t, signed, func(doc *document) {
+		findElement(doc, ubl.NamespaceCBC, "PayableAmount").setText("1.00")
+	})
+	if _, err := Verify(tampered, VerifyOptions{AllowSHA1: true}); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("tampered: got %v; want ErrDigestMismatch", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package xmlsig signs and verifies XML documents with enveloped XML
+// Signatures (XMLDSig), as SUNAT requires of electronic invoices. Sign
+// canonicalizes a UBL document, signs it with an organization's PKCS#12
+// certificate and embeds the signature in the document's empty
+// UBLExtension, optionally with XAdES-BES signed properties. Verify checks
+// the enveloped signature of a document and returns the signer's
+// certificate.
+package xmlsig
+
+import (
+	"crypto"
+	"errors"
+)
+
+// XML namespaces
+const (
+	NamespaceDS    = "http://www.w3.org/2000/09/xmldsig#"
+	NamespaceXAdES = "http://uri.etsi.org/01903/v1.3.2#"
+
+	namespaceExcC14N = "http://www.w3.org/2001/10/xml-exc-c14n#"
+)
+
+// Signature algorithms. RSA-SHA1 is only verified when VerifyOptions allow
+// SHA-1.
+const (
+	AlgorithmRSASHA1     = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	AlgorithmRSASHA256   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	AlgorithmRSASHA512   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	AlgorithmECDSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	AlgorithmECDSASHA384 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384"
+)
+
+// Digest algorithms. SHA-1 is only verified when VerifyOptions allow it.
+const (
+	AlgorithmSHA1   = "http://www.w3.org/2000/09/xmldsig#sha1"
+	AlgorithmSHA256 = "http://www.w3.org/2001/04/xmlenc#sha256"
+	AlgorithmSHA384 = "http://www.w3.org/2001/04/xmldsig-more#sha384"
+	AlgorithmSHA512 = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// Transforms
+const (
+	TransformEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+
+	// typeSignedProperties is the reference type of XAdES signed properties
+	typeSignedProperties = "http://uri.etsi.org/01903#SignedProperties"
+)
+
+// Errors returned by the package; they are wrapped with details
+var (
+	ErrMalformedXML         = errors.New("xmlsig: malformed XML")
+	ErrInvalidCertificate   = errors.New("xmlsig: invalid certificate")
+	ErrNoSignatureSlot      = errors.New("xmlsig: document has no empty UBLExtension to hold the signature")
+	ErrNoSignature          = errors.New("xmlsig: document is not signed")
+	ErrUnsupportedAlgorithm = errors.New("xmlsig: unsupported algorithm")
+	ErrWeakAlgorithm        = errors.New("xmlsig: algorithm is too weak to trust")
+	ErrDigestMismatch       = errors.New("xmlsig: digest does not match the signed content")
+	ErrInvalidSignature     = errors.New("xmlsig: invalid signature")
+)
+
+// signatureMethods maps signature algorithms to their hashes
+var signatureMethods = map[string]crypto.Hash{
+	AlgorithmRSASHA256:   crypto.SHA256,
+	AlgorithmRSASHA512:   crypto.SHA512,
+	AlgorithmECDSASHA256: crypto.SHA256,
+	AlgorithmECDSASHA384: crypto.SHA384,
+}
+
+// weakAlgorithms maps the SHA-1 signature and digest methods to their
+// hash. Verify rejects them unless told otherwise: SHA-1 collisions let a
+// signature be carried over to other content.
+var weakAlgorithms = map[string]crypto.Hash{
+	AlgorithmRSASHA1: crypto.SHA1,
+	AlgorithmSHA1:    crypto.SHA1,
+}
+
+// digestMethods maps digest algorithms to their hashes
+var digestMethods = map[string]crypto.Hash{
+	AlgorithmSHA256: crypto.SHA256,
+	AlgorithmSHA384: crypto.SHA384,
+	AlgorithmSHA512: crypto.SHA512,
+}